@@ -47,6 +47,7 @@ import {PurchasesListComponent} from './purchases/purchases-list.component';
 import {SubscriptionsListComponent} from './subscriptions/subscriptions-list.component';
 import {MfaSetupComponent} from './mfa-setup/mfa-setup.component';
 import {NotificationsListComponent} from './notifications/notifications-list.component';
+import {SymbolsComponent} from './symbols/symbols.component';
 import {NotificationsComponent, NotificationsResolver} from './account/notifications/notifications.component';
 
 const routes: Routes = [
@@ -101,6 +102,7 @@ const routes: Routes = [
       {path: 'notifications', component: NotificationsListComponent, resolve: [NotificationsResolver]},
       {path: 'purchases', component: PurchasesListComponent, resolve: [PurchasesResolver]},
       {path: 'subscriptions', component: SubscriptionsListComponent, resolve: [SubscriptionsResolver]},
+      {path: 'symbols', component: SymbolsComponent, resolve: []},
       {path: 'settings/mfa', component: MfaSetupComponent, resolve: []}
     ]},
   {
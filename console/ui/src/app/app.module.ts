@@ -65,6 +65,7 @@ import {DeleteConfirmDialogComponent} from './shared/delete-confirm-dialog/delet
 import {SubscriptionsListComponent} from './subscriptions/subscriptions-list.component';
 import {NotificationsComponent} from './account/notifications/notifications.component';
 import {NotificationsListComponent} from './notifications/notifications-list.component';
+import {SymbolsComponent} from './symbols/symbols.component';
 import {MfaSetupComponent} from './mfa-setup/mfa-setup.component';
 import {QRCodeModule} from 'angularx-qrcode';
 
@@ -105,7 +106,8 @@ import {QRCodeModule} from 'angularx-qrcode';
     SubscriptionsListComponent,
     MfaSetupComponent,
     NotificationsComponent,
-    NotificationsListComponent
+    NotificationsListComponent,
+    SymbolsComponent
   ],
   imports: [
     NgxFileDropModule,
@@ -8,5 +8,6 @@ export class Globals {
     ['config', UserRole.USER_ROLE_DEVELOPER],
     ['modules', UserRole.USER_ROLE_DEVELOPER],
     ['apiexplorer', UserRole.USER_ROLE_DEVELOPER],
+    ['symbols', UserRole.USER_ROLE_DEVELOPER],
   ]);
 }
@@ -0,0 +1,93 @@
+// Copyright 2020 The Nakama Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import {Component, OnInit} from '@angular/core';
+import {HttpClient} from '@angular/common/http';
+import {UntypedFormBuilder, UntypedFormGroup} from '@angular/forms';
+import {ConfigParams} from '../console.service';
+
+export interface ObservedSymbol {
+  hash: string;
+  count: number;
+  first_seen: string;
+  last_seen: string;
+}
+
+export interface ResolvedSymbol {
+  hash: string;
+  token: string;
+  type?: string;
+}
+
+@Component({
+  templateUrl: './symbols.component.html',
+  styleUrls: ['./symbols.component.scss']
+})
+export class SymbolsComponent implements OnInit {
+  public error = '';
+  public symbols: ObservedSymbol[] = [];
+  public accepted: ResolvedSymbol[] = [];
+  public submitted = false;
+  public resolving = false;
+  public resolveForm: UntypedFormGroup;
+
+  private readonly headers = {
+    Authorization: 'Bearer ',
+  };
+
+  constructor(
+    private readonly config: ConfigParams,
+    private readonly httpClient: HttpClient,
+    private readonly formBuilder: UntypedFormBuilder,
+  ) {}
+
+  ngOnInit(): void {
+    this.resolveForm = this.formBuilder.group({
+      candidates: [''],
+    });
+    this.refresh();
+  }
+
+  public refresh(): void {
+    this.httpClient.get<{symbols: ObservedSymbol[]}>(this.config.host + '/v2/console/evr/symbols', {headers: this.headers}).subscribe(res => {
+      this.error = '';
+      this.symbols = res.symbols || [];
+    }, err => {
+      this.error = err;
+    });
+  }
+
+  public resolve(): void {
+    const candidates = (this.f.candidates.value as string).split('\n').map(c => c.trim()).filter(c => c !== '');
+    if (candidates.length === 0) {
+      return;
+    }
+    this.resolving = true;
+    this.httpClient.post<{accepted: ResolvedSymbol[]}>(this.config.host + '/v2/console/evr/symbols/resolve', {candidates}, {headers: this.headers}).subscribe(res => {
+      this.resolving = false;
+      this.submitted = true;
+      this.error = '';
+      this.accepted = res.accepted || [];
+      this.resolveForm.reset({candidates: ''});
+      this.refresh();
+    }, err => {
+      this.resolving = false;
+      this.error = err;
+    });
+  }
+
+  get f(): any {
+    return this.resolveForm.controls;
+  }
+}
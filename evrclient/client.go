@@ -0,0 +1,151 @@
+// Package evrclient is a typed client for the EVR RPCs.
+//
+// The request and response types are declared in the evrrpc package, which is generated from
+// the declarations in server.EvrRPCs; they are also published as an OpenAPI document at
+// /apievr/openapi.json.
+package evrclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the EVR RPCs over the Nakama HTTP API.
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	sessionToken string
+	httpKey      string
+}
+
+type Option func(*Client)
+
+// WithSessionToken authenticates requests as the session's user.
+func WithSessionToken(token string) Option {
+	return func(c *Client) { c.sessionToken = token }
+}
+
+// WithHTTPKey authenticates requests with the server's runtime HTTP key.
+func WithHTTPKey(key string) Option {
+	return func(c *Client) { c.httpKey = key }
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// New returns a client for the server at baseURL (e.g. "https://nakama.example.com:7350").
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetSessionToken replaces the session token, e.g. after a refresh.
+func (c *Client) SetSessionToken(token string) {
+	c.sessionToken = token
+}
+
+// Error is an error returned by an RPC.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"` // gRPC status code
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error (http %d, code %d): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Call invokes the RPC with the request as the JSON payload, and decodes the JSON response.
+// A nil request sends an empty payload; a nil response discards the result.
+func (c *Client) Call(ctx context.Context, id string, query url.Values, request, response any) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	data, err := c.do(ctx, id, query, body)
+	if err != nil {
+		return err
+	}
+	if response == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// CallText invokes an RPC that responds with plain text.
+func (c *Client) CallText(ctx context.Context, id string, query url.Values, request any) (string, error) {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	data, err := c.do(ctx, id, query, body)
+	return string(data), err
+}
+
+func (c *Client) do(ctx context.Context, id string, query url.Values, body io.Reader) ([]byte, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("unwrap", "")
+	if c.httpKey != "" {
+		q.Set("http_key", c.httpKey)
+	}
+
+	method := http.MethodPost
+	if body == nil {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/v2/rpc/"+id+"?"+q.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.sessionToken != "" && c.httpKey == "" {
+		req.Header.Set("Authorization", "Bearer "+c.sessionToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		rpcErr := &Error{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(data, rpcErr); err != nil || rpcErr.Message == "" {
+			rpcErr.Message = strings.TrimSpace(string(data))
+		}
+		return nil, rpcErr
+	}
+	return data, nil
+}
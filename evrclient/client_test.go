@@ -0,0 +1,69 @@
+package evrclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/evrclient/evrrpc"
+)
+
+func TestClient_Call(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["unwrap"]; !ok {
+			t.Errorf("expected unwrap query parameter")
+		}
+		switch r.URL.Path {
+		case "/v2/rpc/account/search":
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("unexpected authorization %q", got)
+			}
+			request := evrrpc.AccountSearchRequest{}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatal(err)
+			}
+			_ = json.NewEncoder(w).Encode(evrrpc.AccountSearchResponse{
+				DisplayNameMatchList: []evrrpc.DisplayNameMatchItem{{DisplayName: request.DisplayNamePattern}},
+			})
+		case "/v2/rpc/guildgroup":
+			if r.Method != http.MethodGet {
+				t.Errorf("expected GET, got %s", r.Method)
+			}
+			if got := r.URL.Query().Get("ids"); got != "a,b" {
+				t.Errorf("unexpected ids %q", got)
+			}
+			_, _ = w.Write([]byte(`{"guild_groups":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{},"message":"RPC function not found","code":5}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithSessionToken("token"))
+	ctx := context.Background()
+
+	search, err := c.AccountSearch(ctx, &evrrpc.AccountSearchRequest{DisplayNamePattern: "player"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(search.DisplayNameMatchList) != 1 || search.DisplayNameMatchList[0].DisplayName != "player" {
+		t.Errorf("unexpected response %+v", search)
+	}
+
+	if _, err := c.GuildGroups(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.MatchmakerState(ctx)
+	rpcErr := &Error{}
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected rpc error, got %v", err)
+	}
+	if rpcErr.StatusCode != http.StatusNotFound || rpcErr.Code != 5 || rpcErr.Message != "RPC function not found" {
+		t.Errorf("unexpected error %+v", rpcErr)
+	}
+}
@@ -0,0 +1,8 @@
+// Package evrrpc declares the request and response types of the EVR RPCs.
+//
+// The types are generated from the declarations in server.EvrRPCs, so that clients can use
+// them without importing the server. Types with a custom encoding, such as match IDs,
+// are declared as strings, or as raw JSON.
+package evrrpc
+
+//go:generate go run ./internal/gen
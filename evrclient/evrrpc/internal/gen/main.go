@@ -0,0 +1,23 @@
+// Command gen writes the types of the evrrpc package from the server's RPC declarations.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/heroiclabs/nakama/v3/server"
+)
+
+func main() {
+	// Responses that the RPC schemas don't cover.
+	extra := []any{
+		server.TournamentBracketListResponse{},
+	}
+	src, err := server.EvrRPCGoTypes(server.EvrRPCs(nil, nil), extra, "evrrpc", "evrclient/evrrpc/internal/gen")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("types.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,1411 @@
+// Code generated by evrclient/evrrpc/internal/gen. DO NOT EDIT.
+
+package evrrpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/echotools/nevr-common/v4/gen/go/rtapi"
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+type AccountLookupRPCResponse struct {
+	ID          uuid.UUID     `json:"id"`
+	DiscordID   string        `json:"discord_id"`
+	Username    string        `json:"username"`
+	DisplayName string        `json:"display_name"`
+	AvatarURL   string        `json:"avatar_url"`
+	IPQSData    *IPQSResponse `json:"ipqs_data,omitempty"`
+}
+
+type AccountLookupRequest struct {
+	Username    string    `json:"username"`
+	UserID      uuid.UUID `json:"user_id"`
+	DiscordID   string    `json:"discord_id"`
+	XPID        string    `json:"xp_id"`
+	DisplayName string    `json:"display_name"`
+}
+
+type AccountSearchRequest struct {
+	DisplayNamePattern string `json:"display_name"`
+	Limit              int    `json:"limit"`
+}
+
+type AccountSearchResponse struct {
+	Cursor               *string                `json:"cursor,omitempty"`
+	DisplayNameMatchList []DisplayNameMatchItem `json:"display_name_matches"`
+}
+
+type AlternateReview struct {
+	UserIDs      [2]string               `json:"user_ids"`
+	Status       AlternateReviewStatus   `json:"status"`
+	Score        float64                 `json:"score"`
+	Reasons      []*AlternateScoreReason `json:"reasons"`
+	CreateTime   time.Time               `json:"create_time"`
+	UpdateTime   time.Time               `json:"update_time"`
+	ReviewerID   string                  `json:"reviewer_id,omitempty"`
+	ReviewTime   time.Time               `json:"review_time,omitempty"`
+	Notes        string                  `json:"notes,omitempty"`
+	Verdict      AlternateReviewStatus   `json:"verdict,omitempty"`
+	VerdictItems []string                `json:"verdict_items,omitempty"`
+}
+
+type AlternateReviewRequest struct {
+	UserID      string                `json:"user_id"`
+	OtherUserID string                `json:"other_user_id"`
+	Verdict     AlternateReviewStatus `json:"verdict"`
+	Notes       string                `json:"notes,omitempty"`
+}
+
+type AlternateReviewResponse struct {
+	Review *AlternateReview `json:"review"`
+}
+
+type AlternateReviewStatus string
+
+type AlternateReviewsRequest struct {
+	Status AlternateReviewStatus `json:"status,omitempty"`
+	Limit  int                   `json:"limit,omitempty"`
+	Cursor string                `json:"cursor,omitempty"`
+}
+
+type AlternateReviewsResponse struct {
+	Reviews []*AlternateReview `json:"reviews"`
+	Cursor  string             `json:"cursor,omitempty"`
+}
+
+type AlternateScore struct {
+	OtherUserID string                  `json:"other_user_id"`
+	Score       float64                 `json:"score"`
+	Evidence    float64                 `json:"evidence"`
+	Verdict     AlternateReviewStatus   `json:"verdict,omitempty"`
+	Reasons     []*AlternateScoreReason `json:"reasons"`
+}
+
+type AlternateScoreReason struct {
+	Signal AlternateSignal `json:"signal"`
+	Item   string          `json:"item"`
+	SeenAt time.Time       `json:"seen_at,omitempty"`
+	Weight float64         `json:"weight"`
+	Notes  []string        `json:"notes,omitempty"`
+}
+
+type AlternateScoresRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type AlternateScoresResponse struct {
+	UserID string            `json:"user_id"`
+	Scores []*AlternateScore `json:"scores"`
+}
+
+type AlternateSignal string
+
+type AuthenticatePasswordRequest struct {
+	UserID       string `json:"user_id"`
+	DiscordID    string `json:"discord_id"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	RefreshToken string `json:"refresh_token"`
+	IntentStr    string `json:"intents"`
+}
+
+type BracketFormat string
+
+type BracketMatch struct {
+	ID          string         `json:"id"`
+	Bracket     string         `json:"bracket"`
+	Round       int            `json:"round"`
+	Blue        string         `json:"blue,omitempty"`
+	Orange      string         `json:"orange,omitempty"`
+	BlueFrom    *BracketSource `json:"blue_from,omitempty"`
+	OrangeFrom  *BracketSource `json:"orange_from,omitempty"`
+	Status      string         `json:"status"`
+	MatchID     string         `json:"match_id,omitempty"`
+	BlueScore   int            `json:"blue_score"`
+	OrangeScore int            `json:"orange_score"`
+	Winner      string         `json:"winner,omitempty"`
+	Loser       string         `json:"loser,omitempty"`
+	Bye         bool           `json:"bye,omitempty"`
+	CompletedAt time.Time      `json:"completed_at,omitempty"`
+}
+
+type BracketMatchRef struct {
+	BracketID string `json:"bracket_id"`
+	MatchID   string `json:"match_id"`
+}
+
+type BracketSource struct {
+	MatchID string `json:"match_id"`
+	Loser   bool   `json:"loser,omitempty"`
+}
+
+type BracketStanding struct {
+	TeamID        string `json:"team_id"`
+	Name          string `json:"name"`
+	Wins          int    `json:"wins"`
+	Losses        int    `json:"losses"`
+	PointsFor     int    `json:"points_for"`
+	PointsAgainst int    `json:"points_against"`
+	Eliminated    bool   `json:"eliminated,omitempty"`
+}
+
+type BracketTeam struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Seed      int      `json:"seed"`
+	PlayerIDs []string `json:"player_ids"`
+}
+
+type BuildMatchRequest struct {
+	Entries []*MatchmakerEntry `json:"entries"`
+}
+
+type BuildMatchResponse struct {
+	Label json.RawMessage `json:"label"`
+}
+
+type CheckForceUserRequest struct {
+	LoginSessionID string `json:"login_session_id"`
+}
+
+type ConfigResourceHistory struct {
+	Type     string               `json:"type"`
+	Versions []*ConfigResourceSet `json:"versions"`
+}
+
+type ConfigResourceRequest struct {
+	Type string `json:"type"`
+}
+
+type ConfigResourceResolveRequest struct {
+	Type        string `json:"type"`
+	UserID      string `json:"user_id,omitempty"`
+	GroupID     string `json:"group_id,omitempty"`
+	BuildNumber int64  `json:"build_number,omitempty"`
+	DeviceType  string `json:"device_type,omitempty"`
+}
+
+type ConfigResourceResolveResponse struct {
+	Type     string          `json:"type"`
+	Version  int             `json:"version"`
+	Variant  string          `json:"variant,omitempty"`
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+type ConfigResourceRollbackRequest struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+type ConfigResourceSet struct {
+	Type      string                  `json:"type"`
+	Version   int                     `json:"version"`
+	Default   json.RawMessage         `json:"default,omitempty"`
+	Variants  []ConfigResourceVariant `json:"variants,omitempty"`
+	Note      string                  `json:"note,omitempty"`
+	UpdatedBy string                  `json:"updated_by,omitempty"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+type ConfigResourceSetRequest struct {
+	Type     string                  `json:"type"`
+	Default  json.RawMessage         `json:"default,omitempty"`
+	Variants []ConfigResourceVariant `json:"variants,omitempty"`
+	Note     string                  `json:"note,omitempty"`
+}
+
+type ConfigResourceTarget struct {
+	GroupIDs       []string        `json:"group_ids,omitempty"`
+	MinBuildNumber evr.BuildNumber `json:"min_build_number,omitempty"`
+	MaxBuildNumber evr.BuildNumber `json:"max_build_number,omitempty"`
+	DeviceTypes    []string        `json:"device_types,omitempty"`
+	RolloutPercent int             `json:"rollout_percent,omitempty"`
+}
+
+type ConfigResourceVariant struct {
+	Name     string               `json:"name"`
+	Target   ConfigResourceTarget `json:"target"`
+	Resource json.RawMessage      `json:"resource"`
+}
+
+type DerivedStatisticValue struct {
+	Name          string            `json:"name"`
+	Mode          string            `json:"mode"`
+	ResetSchedule evr.ResetSchedule `json:"reset_schedule"`
+	Value         float64           `json:"value"`
+}
+
+type DisabledMap struct {
+	Level  string    `json:"level"`
+	Until  time.Time `json:"until,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+type DiscordSignInRpcRequest struct {
+	Code             string `json:"code"`
+	OAuthRedirectUrl string `json:"oauth_redirect_url"`
+}
+
+type DiscordSignInRpcResponse struct {
+	SessionToken    string `json:"sessionToken"`
+	DiscordUsername string `json:"discordUsername"`
+}
+
+type DisplayNameMatchItem struct {
+	DisplayName string    `json:"display_name"`
+	UserID      string    `json:"user_id"`
+	GroupID     string    `json:"group_id"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DocumentRequest struct {
+	Type     string `json:"type"`
+	Language string `json:"lang"`
+}
+
+type DocumentResponse struct {
+	Document *GameDocument `json:"document"`
+	Pages    []string      `json:"pages"`
+	Changed  bool          `json:"changed,omitempty"`
+}
+
+type DocumentSetRequest struct {
+	Type          string `json:"type"`
+	Language      string `json:"lang"`
+	Title         string `json:"title,omitempty"`
+	Text          string `json:"text"`
+	TextGameAdmin string `json:"text_ga,omitempty"`
+	Link          string `json:"link,omitempty"`
+}
+
+type EnforcementAppealRequest struct {
+	GroupID   string `json:"group_id"`
+	RecordID  string `json:"record_id,omitempty"`
+	Statement string `json:"statement"`
+}
+
+type EnforcementAppealResponse struct {
+	Appeal GuildEnforcementAppeal `json:"appeal"`
+	Record GuildEnforcementRecord `json:"record"`
+}
+
+type EnforcementAppealReviewRequest struct {
+	GroupID       string                       `json:"group_id"`
+	UserID        string                       `json:"user_id"`
+	RecordID      string                       `json:"record_id"`
+	Outcome       GuildEnforcementAppealStatus `json:"outcome"`
+	Notes         string                       `json:"notes,omitempty"`
+	ReducedExpiry time.Time                    `json:"reduced_expiry,omitempty"`
+}
+
+type EnforcementEscalation struct {
+	Category               string        `json:"category"`
+	PriorOffenses          int           `json:"prior_offenses"`
+	PriorRecordIDs         []string      `json:"prior_record_ids"`
+	Step                   int           `json:"step"`
+	Duration               time.Duration `json:"duration"`
+	DurationText           string        `json:"duration_text"`
+	RequireCommunityValues bool          `json:"require_community_values"`
+	AllowPrivateLobbies    bool          `json:"allow_private_lobbies"`
+}
+
+type EnforcementEscalationEvaluateRequest struct {
+	GroupID  string `json:"group_id"`
+	UserID   string `json:"user_id"`
+	Category string `json:"category"`
+}
+
+type EnforcementEscalationPolicy struct {
+	LookbackDays int                          `json:"lookback_days,omitempty"`
+	Categories   []EnforcementOffenseCategory `json:"categories"`
+}
+
+type EnforcementEscalationRequest struct {
+	GroupID string `json:"group_id"`
+}
+
+type EnforcementEscalationResponse struct {
+	GroupID string                       `json:"group_id"`
+	Policy  *EnforcementEscalationPolicy `json:"policy"`
+}
+
+type EnforcementEscalationSetRequest struct {
+	GroupID string                       `json:"group_id"`
+	Policy  *EnforcementEscalationPolicy `json:"policy"`
+}
+
+type EnforcementOffenseCategory struct {
+	Name                   string   `json:"name"`
+	Description            string   `json:"description,omitempty"`
+	Durations              []string `json:"durations"`
+	LookbackDays           int      `json:"lookback_days,omitempty"`
+	RequireCommunityValues bool     `json:"require_community_values,omitempty"`
+	AllowPrivateLobbies    bool     `json:"allow_private_lobbies,omitempty"`
+}
+
+type EvrRecorder struct {
+	ID         string      `json:"id"`
+	Label      string      `json:"label"`
+	Path       string      `json:"path"`
+	MatchID    string      `json:"match_id,omitempty"`
+	SessionIDs []uuid.UUID `json:"session_ids"`
+	StartTime  time.Time   `json:"start_time"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+}
+
+type GameDocument struct {
+	Type             string    `json:"type"`
+	Lang             string    `json:"lang"`
+	Version          int64     `json:"version"`
+	VersionGameAdmin int64     `json:"version_ga,omitempty"`
+	Title            string    `json:"title,omitempty"`
+	Text             string    `json:"text"`
+	TextGameAdmin    string    `json:"text_ga,omitempty"`
+	Link             string    `json:"link,omitempty"`
+	UpdatedBy        string    `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+}
+
+type GameServerEvent struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Node      string    `json:"node"`
+	SessionID uuid.UUID `json:"session_id"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+type GameServerFleetRequest struct {
+	OperatorID string `json:"operator_id"`
+	Region     string `json:"region"`
+	Health     string `json:"health"`
+	Node       string `json:"node"`
+}
+
+type GameServerFleetResponse struct {
+	Servers []*GameServerRecord `json:"servers"`
+}
+
+type GameServerHealth string
+
+type GameServerPresence struct {
+	Node            string      `json:"node,omitempty"`
+	Username        string      `json:"username,omitempty"`
+	SessionID       uuid.UUID   `json:"sid,omitempty"`
+	OperatorID      uuid.UUID   `json:"oper,omitempty"`
+	GroupIDs        []uuid.UUID `json:"group_ids,omitempty"`
+	Endpoint        string      `json:"endpoint,omitempty"`
+	VersionLock     string      `json:"version_lock,omitempty"`
+	AppID           string      `json:"app_id,omitempty"`
+	DefaultRegion   string      `json:"default_region,omitempty"`
+	RegionCodes     []string    `json:"region_codes,omitempty"`
+	ServerID        uint64      `json:"server_id,omitempty"`
+	Features        []string    `json:"features,omitempty"`
+	Tags            []string    `json:"tags,omitempty"`
+	DesignatedModes []string    `json:"designated_modes,omitempty"`
+	TimeStepUsecs   uint32      `json:"time_step_usecs,omitempty"`
+	NativeSupport   bool        `json:"native,omitempty"`
+	NativeVersion   string      `json:"native_version,omitempty"`
+	City            string      `json:"city,omitempty"`
+	Region          string      `json:"region,omitempty"`
+	CountryCode     string      `json:"country_code,omitempty"`
+	GeoHash         string      `json:"geohash,omitempty"`
+	Latitude        float64     `json:"latitude,omitempty"`
+	Longitude       float64     `json:"longitude,omitempty"`
+	ASNumber        int         `json:"asn,omitempty"`
+	Capacity        int         `json:"capacity,omitempty"`
+}
+
+type GameServerRTT struct {
+	Mean    float64 `json:"mean"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Samples int     `json:"samples"`
+}
+
+type GameServerRecord struct {
+	ID                   string            `json:"id"`
+	SessionID            uuid.UUID         `json:"session_id"`
+	ServerID             uint64            `json:"server_id"`
+	OperatorID           uuid.UUID         `json:"operator_id"`
+	Username             string            `json:"username"`
+	Node                 string            `json:"node"`
+	Endpoint             string            `json:"endpoint"`
+	DefaultRegion        string            `json:"default_region,omitempty"`
+	RegionCodes          []string          `json:"region_codes,omitempty"`
+	VersionLock          string            `json:"version_lock,omitempty"`
+	NativeVersion        string            `json:"native_version,omitempty"`
+	Features             []string          `json:"features,omitempty"`
+	Tags                 []string          `json:"tags,omitempty"`
+	Capacity             int               `json:"capacity"`
+	InMatch              bool              `json:"in_match"`
+	Health               GameServerHealth  `json:"health"`
+	RTT                  GameServerRTT     `json:"rtt"`
+	Checks               int64             `json:"checks"`
+	Failures             int64             `json:"failures"`
+	ConsecutiveFailures  int               `json:"consecutive_failures"`
+	ConsecutiveSuccesses int               `json:"consecutive_successes"`
+	LastCheck            time.Time         `json:"last_check,omitempty"`
+	LastError            string            `json:"last_error,omitempty"`
+	RegisteredAt         time.Time         `json:"registered_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+	History              []GameServerEvent `json:"history"`
+}
+
+type GameState struct {
+	BlueScore              int                     `json:"blue_score"`
+	OrangeScore            int                     `json:"orange_score"`
+	SessionScoreboard      *SessionScoreboard      `json:"session_scoreboard,omitempty"`
+	MatchOver              bool                    `json:"match_over,omitempty"`
+	EquilibriumCoefficient float64                 `json:"equilibrium_coefficient,omitempty"`
+	Teams                  map[string]TeamMetadata `json:"teams,omitempty"`
+}
+
+type GroupMetadata struct {
+	GuildID                              string                       `json:"guild_id"`
+	Provider                             string                       `json:"provider,omitempty"`
+	OwnerID                              string                       `json:"owner_id"`
+	MinimumAccountAgeDays                int                          `json:"minimum_account_age_days"`
+	EnableMembersOnlyMatchmaking         bool                         `json:"members_only_matchmaking"`
+	DisableCreateCommand                 bool                         `json:"disable_create_command"`
+	LogAlternateAccounts                 bool                         `json:"log_alternate_accounts"`
+	EnforcersHaveGoldNames               bool                         `json:"moderators_have_gold_names"`
+	RoleMap                              GuildGroupRoles              `json:"roles"`
+	MatchmakingChannelIDs                map[string]string            `json:"matchmaking_channel_ids"`
+	EnforcementNoticeChannelID           string                       `json:"enforcement_notice_channel_id"`
+	AuditChannelID                       string                       `json:"audit_channel_id"`
+	ErrorChannelID                       string                       `json:"error_channel_id"`
+	CommandChannelID                     string                       `json:"command_channel_id"`
+	ServerReportsChannelID               string                       `json:"server_reports_channel_id"`
+	BlockVPNUsers                        bool                         `json:"block_vpn_users"`
+	FraudScoreThreshold                  int                          `json:"fraud_score_threshold"`
+	AllowedFeatures                      []string                     `json:"allowed_features"`
+	AlternateAccountNotificationExpiry   time.Time                    `json:"alt_notification_threshold"`
+	EnableEnforcementCountInNames        bool                         `json:"enable_enforcement_count_in_names"`
+	NegatedEnforcerIDs                   []string                     `json:"negated_enforcer_ids"`
+	RejectPlayersWithSuspendedAlternates bool                         `json:"reject_players_with_suspended_alternates"`
+	SuspensionInheritanceGroupIDs        []string                     `json:"suspension_inheritence_group_ids"`
+	DisplayNameForceNickToIGN            bool                         `json:"force_nick_to_ign"`
+	DisplayNameInUseNotifications        bool                         `json:"display_name_in_use_notifications"`
+	EnableGlobalPingForServers           bool                         `json:"enable_global_ping_for_servers"`
+	MapRotation                          *MapRotationPolicy           `json:"map_rotation,omitempty"`
+	EnforcementEscalation                *EnforcementEscalationPolicy `json:"enforcement_escalation,omitempty"`
+}
+
+type GuildEnforcementAppeal struct {
+	GroupID           string                       `json:"group_id"`
+	RecordID          string                       `json:"record_id"`
+	Status            GuildEnforcementAppealStatus `json:"status"`
+	Statement         string                       `json:"statement"`
+	SubmittedAt       time.Time                    `json:"submitted_at"`
+	ReviewerUserID    string                       `json:"reviewer_user_id,omitempty"`
+	ReviewerDiscordID string                       `json:"reviewer_discord_id,omitempty"`
+	AssignedAt        time.Time                    `json:"assigned_at,omitempty"`
+	ResolvedAt        time.Time                    `json:"resolved_at,omitempty"`
+	ReviewerNotes     string                       `json:"reviewer_notes,omitempty"`
+	OriginalExpiry    time.Time                    `json:"original_expiry,omitempty"`
+}
+
+type GuildEnforcementAppealStatus string
+
+type GuildEnforcementRecord struct {
+	ID                      string    `json:"id"`
+	UserID                  string    `json:"user_id"`
+	GroupID                 string    `json:"group_id"`
+	EnforcerUserID          string    `json:"enforcer_user_id"`
+	EnforcerDiscordID       string    `json:"enforcer_discord_id"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+	UserNoticeText          string    `json:"suspension_notice"`
+	Expiry                  time.Time `json:"suspension_expiry"`
+	CommunityValuesRequired bool      `json:"community_values_required"`
+	AuditorNotes            string    `json:"notes"`
+	AllowPrivateLobbies     bool      `json:"allow_private_lobbies"`
+	OffenseCategory         string    `json:"offense_category,omitempty"`
+}
+
+type GuildGroup struct {
+	GroupMetadata
+	State *GuildGroupState `json:"state,omitempty"`
+	Group *api.Group       `json:"group,omitempty"`
+}
+
+type GuildGroupRequest struct {
+	IDs string `json:"ids"`
+}
+
+type GuildGroupResponse struct {
+	Groups []*GuildGroup `json:"guild_groups,omitempty"`
+}
+
+type GuildGroupRoles struct {
+	Member           string `json:"member"`
+	Enforcer         string `json:"moderator"`
+	Auditor          string `json:"auditor"`
+	ServerHost       string `json:"server_host"`
+	Allocator        string `json:"allocator"`
+	Suspended        string `json:"suspended"`
+	APIAccess        string `json:"api_access"`
+	AccountAgeBypass string `json:"account_age_bypass"`
+	VPNBypass        string `json:"vpn_bypass"`
+	AccountLinked    string `json:"headset_linked"`
+	UsernameOnly     string `json:"username_only"`
+}
+
+type GuildGroupState struct {
+	GroupID        string                     `json:"group_id"`
+	RoleCache      map[string]map[string]bool `json:"role_cache"`
+	SuspendedXPIDs map[string]string          `json:"suspended_devices"`
+	RulesText      string                     `json:"rules_text"`
+}
+
+type IPQSResponse struct {
+	Message            string                 `json:"message,omitempty"`
+	Success            bool                   `json:"success,omitempty"`
+	Proxy              bool                   `json:"proxy,omitempty"`
+	ISP                string                 `json:"ISP,omitempty"`
+	Organization       string                 `json:"organization,omitempty"`
+	ASN                int                    `json:"ASN,omitempty"`
+	Host               string                 `json:"host,omitempty"`
+	CountryCode        string                 `json:"country_code,omitempty"`
+	City               string                 `json:"city,omitempty"`
+	Region             string                 `json:"region,omitempty"`
+	IsCrawler          bool                   `json:"is_crawler,omitempty"`
+	ConnectionType     string                 `json:"connection_type,omitempty"`
+	Latitude           float64                `json:"latitude,omitempty"`
+	Longitude          float64                `json:"longitude,omitempty"`
+	ZipCode            string                 `json:"zip_code,omitempty"`
+	Timezone           string                 `json:"timezone,omitempty"`
+	VPN                bool                   `json:"vpn,omitempty"`
+	Tor                bool                   `json:"tor,omitempty"`
+	ActiveVPN          bool                   `json:"active_vpn,omitempty"`
+	ActiveTor          bool                   `json:"active_tor,omitempty"`
+	RecentAbuse        bool                   `json:"recent_abuse,omitempty"`
+	FrequentAbuser     bool                   `json:"frequent_abuser,omitempty"`
+	HighRiskAttacks    bool                   `json:"high_risk_attacks,omitempty"`
+	AbuseVelocity      string                 `json:"abuse_velocity,omitempty"`
+	BotStatus          bool                   `json:"bot_status,omitempty"`
+	SharedConnection   bool                   `json:"shared_connection,omitempty"`
+	DynamicConnection  bool                   `json:"dynamic_connection,omitempty"`
+	SecurityScanner    bool                   `json:"security_scanner,omitempty"`
+	TrustedNetwork     bool                   `json:"trusted_network,omitempty"`
+	Mobile             bool                   `json:"mobile,omitempty"`
+	FraudScore         int                    `json:"fraud_score,omitempty"`
+	OperatingSystem    string                 `json:"operating_system,omitempty"`
+	Browser            string                 `json:"browser,omitempty"`
+	DeviceModel        string                 `json:"device_model,omitempty"`
+	DeviceBrand        string                 `json:"device_brand,omitempty"`
+	TransactionDetails IPQSTransactionDetails `json:"transaction_details,omitempty"`
+	RequestID          string                 `json:"request_id,omitempty"`
+}
+
+type IPQSTransactionDetails struct {
+	ValidBillingAddress       bool     `json:"valid_billing_address,omitempty"`
+	ValidShippingAddress      bool     `json:"valid_shipping_address,omitempty"`
+	ValidBillingEmail         bool     `json:"valid_billing_email,omitempty"`
+	ValidShippingEmail        bool     `json:"valid_shipping_email,omitempty"`
+	RiskyBillingPhone         bool     `json:"risky_billing_phone,omitempty"`
+	RiskyShippingPhone        bool     `json:"risky_shipping_phone,omitempty"`
+	BillingPhoneCarrier       string   `json:"billing_phone_carrier,omitempty"`
+	ShippingPhoneCarrier      string   `json:"shipping_phone_carrier,omitempty"`
+	BillingPhoneLineType      string   `json:"billing_phone_line_type,omitempty"`
+	ShippingPhoneLineType     string   `json:"shipping_phone_line_type,omitempty"`
+	BillingPhoneCountry       string   `json:"billing_phone_country,omitempty"`
+	BillingPhoneCountryCode   string   `json:"billing_phone_country_code,omitempty"`
+	ShippingPhoneCountry      string   `json:"shipping_phone_country,omitempty"`
+	ShippingPhoneCountryCode  string   `json:"shipping_phone_country_code,omitempty"`
+	FraudulentBehavior        bool     `json:"fraudulent_behavior,omitempty"`
+	BinCountry                string   `json:"bin_country,omitempty"`
+	BinType                   string   `json:"bin_type,omitempty"`
+	BinBankName               string   `json:"bin_bank_name,omitempty"`
+	RiskScore                 int      `json:"risk_score,omitempty"`
+	RiskFactors               []string `json:"risk_factors,omitempty"`
+	IsPrepaidCard             bool     `json:"is_prepaid_card,omitempty"`
+	RiskyUsername             bool     `json:"risky_username,omitempty"`
+	ValidBillingPhone         bool     `json:"valid_billing_phone,omitempty"`
+	ValidShippingPhone        bool     `json:"valid_shipping_phone,omitempty"`
+	LeakedBillingEmail        bool     `json:"leaked_billing_email,omitempty"`
+	LeakedShippingEmail       bool     `json:"leaked_shipping_email,omitempty"`
+	LeakedUserData            bool     `json:"leaked_user_data,omitempty"`
+	UserActivity              string   `json:"user_activity,omitempty"`
+	PhoneNameIdentityMatch    string   `json:"phone_name_identity_match,omitempty"`
+	PhoneEmailIdentityMatch   string   `json:"phone_email_identity_match,omitempty"`
+	PhoneAddressIdentityMatch string   `json:"phone_address_identity_match,omitempty"`
+	EmailNameIdentityMatch    string   `json:"email_name_identity_match,omitempty"`
+	NameAddressIdentityMatch  string   `json:"name_address_identity_match,omitempty"`
+	AddressEmailIdentityMatch string   `json:"address_email_identity_match,omitempty"`
+}
+
+type ImportLoadoutRpcRequest struct {
+	Loadouts []evr.CosmeticLoadout `json:"loadouts"`
+}
+
+type ImportLoadoutRpcResponse struct {
+	LoadoutIDs []string `json:"loadout_ids"`
+}
+
+type JoinMatchStreamRequest struct {
+	MatchUUID string `json:"match_id"`
+}
+
+type KickPlayerRPCRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type LadderRank struct {
+	Tier     string `json:"tier"`
+	Division int    `json:"division,omitempty"`
+}
+
+type LeaderboardHaystackRecord struct {
+	DisplayName string          `json:"display_name"`
+	OwnerID     string          `json:"owner_id"`
+	Rank        int64           `json:"rank,omitempty"`
+	Score       int64           `json:"score"`
+	Subscore    int64           `json:"subscore,omitempty"`
+	NumScore    int32           `json:"num_score"`
+	CreateTime  int64           `json:"create_time"`
+	UpdateTime  int64           `json:"update_time"`
+	ExpiryTime  int64           `json:"expire_time,omitempty"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+type LeaderboardHaystackRequest struct {
+	OwnerID       string            `json:"owner_id"`
+	DiscordID     string            `json:"owner_discord_id"`
+	LeaderboardID string            `json:"leaderboard_id"`
+	GuildID       string            `json:"guild_id"`
+	GroupID       string            `json:"group_id"`
+	Mode          string            `json:"game_mode"`
+	ResetSchedule evr.ResetSchedule `json:"reset_schedule"`
+	StatName      string            `json:"stat_name"`
+	Limit         int               `json:"limit"`
+	Cursor        string            `json:"cursor"`
+}
+
+type LeaderboardHaystackResponse struct {
+	PrevCursor   string                      `json:"prev_cursor"`
+	NextCursor   string                      `json:"next_cursor"`
+	RankCount    int64                       `json:"rank_count,omitempty"`
+	OwnerRecords []LeaderboardHaystackRecord `json:"owner_records"`
+	Records      []LeaderboardHaystackRecord `json:"records"`
+}
+
+type LeaderboardMeta struct {
+	GroupID       string
+	Mode          string
+	StatName      string
+	Operator      LeaderboardOperator
+	ResetSchedule evr.ResetSchedule
+}
+
+type LeaderboardOperator string
+
+type LeaderboardRecordsListItem struct {
+	*api.LeaderboardRecord
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type LeaderboardRecordsListRequest struct {
+	LeaderboardID string            `json:"leaderboard_id"`
+	GuildID       string            `json:"guild_id"`
+	GroupID       string            `json:"group_id"`
+	Mode          string            `json:"game_mode"`
+	StatName      string            `json:"stat_name"`
+	ResetSchedule evr.ResetSchedule `json:"reset_schedule"`
+	FromRank      int64             `json:"from_rank"`
+	Limit         int               `json:"limit"`
+	Cursor        string            `json:"cursor"`
+}
+
+type LeaderboardRecordsListResponse struct {
+	LeaderboardID string                        `json:"leaderboard_id"`
+	NextCursor    string                        `json:"next_cursor"`
+	PrevCursor    string                        `json:"prev_cursor"`
+	Records       []*LeaderboardRecordsListItem `json:"records"`
+}
+
+type LeaveMatchStreamRequest struct {
+	MatchUUID string `json:"match_id"`
+}
+
+type LinkDeviceRpcRequest struct {
+	SessionToken string `json:"sessionToken"`
+	LinkCode     string `json:"linkCode"`
+}
+
+type LinkUserIdDeviceRpcRequest struct {
+	UserID   string `json:"userId"`
+	LinkCode string `json:"code"`
+}
+
+type LiveScoreboardFeedRequest struct {
+	MatchID   string `json:"match_id"`
+	DelaySecs int    `json:"delay_s,omitempty"`
+	TTLHours  int    `json:"ttl_hours,omitempty"`
+	Revoke    bool   `json:"revoke,omitempty"`
+}
+
+type LiveScoreboardFeedResponse struct {
+	MatchID    string    `json:"match_id"`
+	Token      string    `json:"token,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	DelaySecs  int       `json:"delay_s"`
+	ExpiryTime time.Time `json:"expiry_time,omitempty"`
+	Revoked    bool      `json:"revoked,omitempty"`
+}
+
+type LobbySessionParameters struct {
+	Node                         string           `json:"node"`
+	UserID                       uuid.UUID        `json:"user_id"`
+	SessionID                    uuid.UUID        `json:"session_id"`
+	DiscordID                    string           `json:"discord_id"`
+	VersionLock                  string           `json:"version_lock"`
+	AppID                        string           `json:"app_id"`
+	GroupID                      uuid.UUID        `json:"group_id"`
+	RegionCode                   string           `json:"region_code"`
+	Mode                         string           `json:"mode"`
+	Level                        string           `json:"level"`
+	SupportedFeatures            []string         `json:"supported_features"`
+	RequiredFeatures             []string         `json:"required_features"`
+	CurrentMatchID               string           `json:"current_match_id"`
+	NextMatchID                  string           `json:"next_match_id"`
+	Role                         int              `json:"role"`
+	PartySize                    *json.RawMessage `json:"party_size"`
+	PartyID                      uuid.UUID        `json:"party_id"`
+	PartyGroupName               string           `json:"party_group_name"`
+	DisableArenaBackfill         bool             `json:"disable_arena_backfill"`
+	BackfillQueryAddon           string           `json:"backfill_query_addon"`
+	MatchmakingQueryAddon        string           `json:"matchmaking_query_addon"`
+	CreateQueryAddon             string           `json:"create_query_addon"`
+	Verbose                      bool             `json:"verbose"`
+	BlockedIDs                   []string         `json:"blocked_ids"`
+	MatchmakingRating            *json.RawMessage `json:"matchmaking_rating"`
+	EarlyQuitPenaltyLevel        int              `json:"early_quit_penalty_level"`
+	EarlyQuitMatchmakingTier     int32            `json:"early_quit_matchmaking_tier"`
+	EnableSBMM                   bool             `json:"disable_sbmm"`
+	EnableOrdinalRange           bool             `json:"enable_ordinal_range"`
+	EnableDivisions              bool             `json:"enable_divisions"`
+	MatchmakingRatingRange       float64          `json:"rating_range"`
+	MatchmakingDivisions         []string         `json:"divisions"`
+	MatchmakingExcludedDivisions []string         `json:"excluded_divisions"`
+	MaxServerRTT                 int              `json:"max_server_rtt"`
+	MatchmakingTimestamp         time.Time        `json:"matchmaking_timestamp"`
+	MatchmakingTimeout           time.Duration    `json:"matchmaking_timeout"`
+	FailsafeTimeout              time.Duration    `json:"failsafe_timeout"`
+	FallbackTimeout              time.Duration    `json:"fallback_timeout"`
+	DisplayName                  string           `json:"display_name"`
+}
+
+type MapPlaylist struct {
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start,omitempty"`
+	End        time.Time              `json:"end,omitempty"`
+	DailyStart string                 `json:"daily_start,omitempty"`
+	DailyEnd   string                 `json:"daily_end,omitempty"`
+	Pools      map[string][]MapWeight `json:"pools"`
+}
+
+type MapRotationPolicy struct {
+	Pools     map[string][]MapWeight `json:"pools,omitempty"`
+	NoRepeat  int                    `json:"no_repeat,omitempty"`
+	Disabled  []DisabledMap          `json:"disabled,omitempty"`
+	Playlists []MapPlaylist          `json:"playlists,omitempty"`
+}
+
+type MapRotationRequest struct {
+	GroupID string `json:"group_id"`
+}
+
+type MapRotationResponse struct {
+	GroupID string                 `json:"group_id"`
+	Source  string                 `json:"source"`
+	Policy  *MapRotationPolicy     `json:"policy"`
+	Recent  map[string][]string    `json:"recent"`
+	Pools   map[string][]MapWeight `json:"pools"`
+	Active  map[string]string      `json:"active"`
+}
+
+type MapRotationSelection struct {
+	Source   string `json:"source"`
+	Playlist string `json:"playlist,omitempty"`
+}
+
+type MapRotationSetRequest struct {
+	GroupID string             `json:"group_id"`
+	Policy  *MapRotationPolicy `json:"policy"`
+}
+
+type MapWeight struct {
+	Level  string  `json:"level"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+type MatchHistoryPlayer struct {
+	UserID      string    `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	EvrID       string    `json:"evr_id"`
+	Team        string    `json:"team"`
+	PartyID     string    `json:"party_id,omitempty"`
+	JoinTime    time.Time `json:"join_time"`
+	LeaveTime   time.Time `json:"leave_time"`
+	EarlyQuit   bool      `json:"early_quit,omitempty"`
+}
+
+type MatchHistoryRecord struct {
+	MatchID      string                `json:"match_id"`
+	GroupID      string                `json:"group_id"`
+	LobbyType    string                `json:"lobby_type"`
+	Mode         string                `json:"mode"`
+	Level        string                `json:"level"`
+	Server       *MatchHistoryServer   `json:"server,omitempty"`
+	StartTime    time.Time             `json:"start_time"`
+	EndTime      time.Time             `json:"end_time"`
+	DurationSecs float64               `json:"duration_secs"`
+	BlueScore    int                   `json:"blue_score"`
+	OrangeScore  int                   `json:"orange_score"`
+	WinningTeam  string                `json:"winning_team"`
+	Players      []*MatchHistoryPlayer `json:"players"`
+	Goals        []*evr.MatchGoal      `json:"goals,omitempty"`
+}
+
+type MatchHistoryRequest struct {
+	UserID    string `json:"user_id"`
+	DiscordID string `json:"discord_id"`
+	GroupID   string `json:"group_id"`
+	GuildID   string `json:"guild_id"`
+	Limit     int    `json:"limit"`
+	Cursor    string `json:"cursor"`
+}
+
+type MatchHistoryResponse struct {
+	Records []*MatchHistoryRecord `json:"records"`
+	Cursor  string                `json:"cursor,omitempty"`
+}
+
+type MatchHistoryServer struct {
+	OperatorID  string `json:"operator_id"`
+	Endpoint    string `json:"endpoint"`
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+type MatchLabel struct {
+	ID               string                `json:"id"`
+	Open             bool                  `json:"open"`
+	LockedAt         *time.Time            `json:"locked_at,omitempty"`
+	LobbyType        string                `json:"lobby_type"`
+	Mode             string                `json:"mode,omitempty"`
+	Level            string                `json:"level,omitempty"`
+	Size             int                   `json:"size"`
+	PlayerCount      int                   `json:"player_count"`
+	Players          []PlayerInfo          `json:"players,omitempty"`
+	RatingMu         float64               `json:"rating_mu"`
+	GameState        *GameState            `json:"game_state,omitempty"`
+	TeamSize         int                   `json:"team_size,omitempty"`
+	MaxSize          int                   `json:"limit,omitempty"`
+	PlayerLimit      int                   `json:"player_limit,omitempty"`
+	RequiredFeatures []string              `json:"features,omitempty"`
+	GroupID          *uuid.UUID            `json:"group_id,omitempty"`
+	SpawnedBy        string                `json:"spawned_by,omitempty"`
+	StartTime        time.Time             `json:"start_time,omitempty"`
+	CreatedAt        time.Time             `json:"created_at,omitempty"`
+	GameServer       *GameServerPresence   `json:"broadcaster,omitempty"`
+	SessionSettings  *json.RawMessage      `json:"session_settings,omitempty"`
+	TeamAlignments   map[string]int        `json:"team_alignments,omitempty"`
+	MapRotation      *MapRotationSelection `json:"map_rotation,omitempty"`
+	Bracket          *BracketMatchRef      `json:"bracket,omitempty"`
+}
+
+type MatchListPublicRPCResponse struct {
+	Uptime                      int64                     `json:"uptime_mins"`
+	UpdateTime                  string                    `json:"update_time"`
+	LobbySessionCount           int                       `json:"lobby_session_count"`
+	GameServerCount             int                       `json:"gameserver_count"`
+	PlayerCount                 int                       `json:"player_count"`
+	MatchmakingTicketsByGroupID map[string]map[string]int `json:"active_matchmaking_counts"`
+	Labels                      []*MatchLabel             `json:"labels"`
+	GameServers                 []*GameServerPresence     `json:"gameservers"`
+}
+
+type MatchRpcRequest struct {
+	MatchIDs []string `json:"match_ids"`
+	Query    string   `json:"query"`
+}
+
+type MatchRpcResponse struct {
+	SystemStartTime string            `json:"system_start_time"`
+	Timestamp       string            `json:"timestamp"`
+	Labels          []json.RawMessage `json:"labels"`
+}
+
+type MatchmakerCandidatesRPCResponse struct {
+	Candidates [][]any `json:"candidates"`
+	Matches    [][]any `json:"matches"`
+}
+
+type MatchmakerDistribution struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+type MatchmakerEntry struct {
+	Ticket     string              `json:"ticket"`
+	Presence   *MatchmakerPresence `json:"presence"`
+	Properties map[string]any      `json:"properties"`
+	PartyId    string              `json:"party_id"`
+	CreateTime int64               `json:"create_time"`
+}
+
+type MatchmakerExtract struct {
+	Presences         []*MatchmakerPresence
+	SessionID         string
+	PartyId           string
+	Query             string
+	MinCount          int
+	MaxCount          int
+	CountMultiple     int
+	StringProperties  map[string]string
+	NumericProperties map[string]float64
+	Ticket            string
+	Count             int
+	Intervals         int
+	CreatedAt         int64
+	Node              string
+}
+
+type MatchmakerPresence struct {
+	UserId    string `json:"user_id"`
+	SessionId string `json:"session_id"`
+	Username  string `json:"username"`
+	Node      string `json:"node"`
+}
+
+type MatchmakerSimulationRequest struct {
+	Snapshots []string                      `json:"snapshots,omitempty"`
+	Limit     int                           `json:"limit,omitempty"`
+	Variants  []MatchmakerSimulationVariant `json:"variants"`
+}
+
+type MatchmakerSimulationResponse struct {
+	Snapshots []MatchmakerSnapshotInfo      `json:"snapshots"`
+	Results   []*MatchmakerSimulationResult `json:"results"`
+}
+
+type MatchmakerSimulationResult struct {
+	Variant          string                 `json:"variant"`
+	Snapshots        int                    `json:"snapshots"`
+	Matches          int                    `json:"matches"`
+	Players          int                    `json:"players"`
+	MatchedPlayers   int                    `json:"matched_players"`
+	DrawProbability  MatchmakerDistribution `json:"draw_probability"`
+	WaitTimeSecs     MatchmakerDistribution `json:"wait_time_secs"`
+	RTTMs            MatchmakerDistribution `json:"rtt_ms"`
+	ProcessingTimeMs MatchmakerDistribution `json:"processing_time_ms"`
+	Errors           []string               `json:"errors,omitempty"`
+}
+
+type MatchmakerSimulationVariant struct {
+	Name     string          `json:"name"`
+	Settings json.RawMessage `json:"settings,omitempty"`
+	MaxRTT   float64         `json:"max_rtt,omitempty"`
+}
+
+type MatchmakerSnapshotIndex struct {
+	Snapshots []MatchmakerSnapshotInfo `json:"snapshots"`
+}
+
+type MatchmakerSnapshotInfo struct {
+	Key        string    `json:"key"`
+	Time       time.Time `json:"time"`
+	GroupID    string    `json:"group_id"`
+	Mode       string    `json:"mode"`
+	Entries    int       `json:"entries"`
+	Candidates int       `json:"candidates"`
+}
+
+type MatchmakerStateResponse struct {
+	Stats *api.MatchmakerStats `json:"stats"`
+	Index []*MatchmakerExtract `json:"index"`
+}
+
+type MatchmakerStreamRequest struct {
+	GroupID string `json:"group_id"`
+}
+
+type MatchmakerStreamResponse struct {
+	Success   bool                  `json:"success"`
+	Presences []MatchmakingPresence `json:"presences"`
+}
+
+type MatchmakingPresence struct {
+	UserID     string                  `json:"user_id"`
+	SessionID  string                  `json:"session_id"`
+	Username   string                  `json:"username"`
+	Parameters *LobbySessionParameters `json:"parameters"`
+}
+
+type PlayerInfo struct {
+	DisplayName   string     `json:"display_name,omitempty"`
+	PartyID       string     `json:"party_id,omitempty"`
+	IsReservation bool       `json:"is_reservation,omitempty"`
+	Team          string     `json:"team"`
+	JoinTime      int64      `json:"join_time_ns,omitempty"`
+	RatingMu      float64    `json:"rating_mu,omitempty"`
+	RatingSigma   float64    `json:"rating_sigma,omitempty"`
+	Username      string     `json:"username,omitempty"`
+	DiscordID     string     `json:"discord_id,omitempty"`
+	UserID        string     `json:"user_id,omitempty"`
+	EvrID         string     `json:"evr_id,omitempty"`
+	ClientIP      string     `json:"client_ip,omitempty"`
+	SessionID     string     `json:"session_id,omitempty"`
+	GeoHash       string     `json:"geohash,omitempty"`
+	PingMillis    int        `json:"ping_ms,omitempty"`
+	MatchmakingAt *time.Time `json:"matchmaking_at,omitempty"`
+}
+
+type PlayerStatisticsRequest struct {
+	UserID    string `json:"user_id"`
+	GroupID   string `json:"group_id"`
+	GuildID   string `json:"guild_id"`
+	DiscordID string `json:"discord_id"`
+	Mode      string `json:"mode"`
+}
+
+type PlayerStatisticsResponse struct {
+	Stats   json.RawMessage          `json:"stats"`
+	Derived []*DerivedStatisticValue `json:"derived,omitempty"`
+	Ranked  []*RankedLadderState     `json:"ranked,omitempty"`
+}
+
+type PrepareMatchRPCRequest struct {
+	MatchID          string            `json:"id"`
+	Mode             evr.SymbolToken   `json:"mode"`
+	Level            evr.SymbolToken   `json:"level,omitempty"`
+	RequiredFeatures []string          `json:"required_features,omitempty"`
+	TeamSize         int               `json:"team_size,omitempty"`
+	Alignments       map[string]string `json:"role_alignments,omitempty"`
+	GuildID          string            `json:"guild_id,omitempty"`
+	StartTime        time.Time         `json:"start_time,omitempty"`
+	SpawnedBy        string            `json:"spawned_by,omitempty"`
+	MatchLabel       *MatchLabel       `json:"label,omitempty"`
+}
+
+type Presence struct {
+	ID     PresenceID
+	Stream PresenceStream
+	UserID uuid.UUID
+	Meta   PresenceMeta
+}
+
+type PresenceID struct {
+	Node      string
+	SessionID uuid.UUID
+}
+
+type PresenceMeta struct {
+	Format      SessionFormat
+	Hidden      bool
+	Persistence bool
+	Username    string
+	Status      string
+	Reason      uint32
+}
+
+type PresenceStream struct {
+	Mode       uint8
+	Subject    uuid.UUID
+	Subcontext uuid.UUID
+	Label      string
+}
+
+type RPCSuccessResponse struct {
+	Success bool `json:"success"`
+}
+
+type RankedLadderSeasonResult struct {
+	Season time.Time  `json:"season"`
+	Rank   LadderRank `json:"rank"`
+	Peak   LadderRank `json:"peak"`
+}
+
+type RankedLadderSeries struct {
+	Promotion bool       `json:"promotion"`
+	Target    LadderRank `json:"target"`
+	Wins      int        `json:"wins"`
+	Losses    int        `json:"losses"`
+}
+
+type RankedLadderState struct {
+	GroupID          string                     `json:"group_id"`
+	Mode             string                     `json:"mode"`
+	Season           time.Time                  `json:"season"`
+	Placed           bool                       `json:"placed"`
+	PlacementMatches int                        `json:"placement_matches"`
+	PlacementWins    int                        `json:"placement_wins"`
+	Rank             LadderRank                 `json:"rank"`
+	Peak             LadderRank                 `json:"peak"`
+	Series           *RankedLadderSeries        `json:"series,omitempty"`
+	Wins             int                        `json:"wins"`
+	Losses           int                        `json:"losses"`
+	Ordinal          float64                    `json:"ordinal"`
+	PreviousSeasons  []RankedLadderSeasonResult `json:"previous_seasons,omitempty"`
+	UpdateTime       time.Time                  `json:"update_time"`
+}
+
+type RecordingListResponse struct {
+	Recordings []*EvrRecorder `json:"recordings"`
+}
+
+type RecordingStartRequest struct {
+	SessionID    string `json:"session_id,omitempty"`
+	MatchID      string `json:"match_id,omitempty"`
+	DurationSecs int    `json:"duration_secs,omitempty"`
+}
+
+type RecordingStopRequest struct {
+	ID string `json:"id"`
+}
+
+type ServerScoreRPCRequest struct {
+	PlayerRTTs   []float64 `json:"rtts"`
+	MinRTT       int       `json:"min_rtt"`
+	MaxRTT       int       `json:"max_rtt"`
+	ThresholdRTT int       `json:"threshold_rtt"`
+}
+
+type ServerScoreRPCResponse struct {
+	Score float64 `json:"score"`
+}
+
+type ServerScoresRPCRequest struct {
+	DiscordIDs   []string `json:"discord_ids"`
+	MinRTT       int      `json:"min_rtt"`
+	MaxRTT       int      `json:"max_rtt"`
+	ThresholdRTT int      `json:"threshold_rtt"`
+}
+
+type ServerScoresRPCResponse struct {
+	Scores map[string]float64 `json:"scores"`
+}
+
+type ServiceStatusService struct {
+	ServiceID string `json:"serviceid"`
+	Available bool   `json:"available"`
+	Message   string `json:"message"`
+}
+
+type SessionEvent struct {
+	MatchID string                        `json:"match_id"`
+	UserID  string                        `json:"user_id,omitempty"`
+	Data    *rtapi.LobbySessionStateFrame `json:"data,omitempty"`
+}
+
+type SessionEventStoreResponse struct {
+	Success bool   `json:"success"`
+	MatchID string `json:"match_id"`
+}
+
+type SessionEventsRequest struct {
+	MatchUUID string `json:"match_id"`
+}
+
+type SessionEventsResponse struct {
+	MatchUUID string          `json:"match_id"`
+	Count     int             `json:"count"`
+	Events    []*SessionEvent `json:"events"`
+}
+
+type SessionFormat uint8
+
+type SessionScoreboard struct {
+	GameTime      time.Duration `json:"game_time_ns"`
+	RoundDuration time.Duration `json:"round_duration_ns"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	PausedAt      *time.Time    `json:"paused_at,omitempty"`
+	PauseDuration time.Duration `json:"pause_duration_ns,omitempty"`
+}
+
+type SetNextMatchRPCRequest struct {
+	TargetDiscordID string `json:"discord_id"`
+	TargetUserID    string `json:"user_id"`
+	MatchID         string `json:"match_id"`
+	HostDiscordID   string `json:"host_discord_id"`
+	Role            string `json:"role"`
+}
+
+type SetNextMatchRPCResponse struct {
+	UserID  string `json:"user_id"`
+	MatchID string `json:"match_id"`
+}
+
+type ShutdownMatchRequest struct {
+	MatchID      string `json:"match_id"`
+	GraceSeconds int    `json:"grace_seconds,omitempty"`
+}
+
+type ShutdownMatchResponse struct {
+	Success  bool   `json:"success"`
+	Response string `json:"response"`
+}
+
+type StatisticsDeadLetter struct {
+	ID            int64                 `json:"id"`
+	LeaderboardID string                `json:"leaderboard_id"`
+	UserID        string                `json:"user_id"`
+	Entry         *StatisticsQueueEntry `json:"entry"`
+	Attempts      int                   `json:"attempts"`
+	LastError     string                `json:"last_error"`
+	CreateTime    time.Time             `json:"create_time"`
+	DeadTime      time.Time             `json:"dead_time"`
+}
+
+type StatisticsDeadLetterRequest struct {
+	LeaderboardID string `json:"leaderboard_id,omitempty"`
+	UserID        string `json:"user_id,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+type StatisticsDeadLetterRequeueRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type StatisticsDeadLetterRequeueResponse struct {
+	Requeued int64 `json:"requeued"`
+}
+
+type StatisticsDeadLetterResponse struct {
+	DeadLetters []*StatisticsDeadLetter `json:"dead_letters"`
+}
+
+type StatisticsQueueEntry struct {
+	BoardMeta   LeaderboardMeta
+	UserID      string
+	DisplayName string
+	Score       int64
+	Subscore    int64
+	Metadata    map[string]string
+}
+
+type StreamJoinRequest struct {
+	Mode        uint8  `json:"mode"`
+	Subject     string `json:"subject"`
+	Subcontext  string `json:"subcontext"`
+	UserID      string `json:"user_id"`
+	SessionID   string `json:"session_id"`
+	Label       string `json:"label"`
+	Hidden      bool   `json:"hidden"`
+	Persistance bool   `json:"persistance"`
+	Status      string `json:"status"`
+}
+
+type StreamJoinResponse struct {
+	Success   bool        `json:"success"`
+	Presences []*Presence `json:"presences"`
+}
+
+type StreamResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+type SymbolResolveRequest struct {
+	Candidates []string `json:"candidates"`
+}
+
+type SymbolResolveResponse struct {
+	Accepted []evr.SymbolInfo `json:"accepted"`
+}
+
+type SymbolUnknownResponse struct {
+	Symbols []evr.ObservedSymbol `json:"symbols"`
+}
+
+type TeamMetadata struct {
+	Strength   float64 `json:"strength,omitempty"`
+	PredictWin float64 `json:"predict_win,omitempty"`
+}
+
+type TournamentBracket struct {
+	ID          string          `json:"id"`
+	GroupID     string          `json:"group_id"`
+	Name        string          `json:"name"`
+	Format      BracketFormat   `json:"format"`
+	Mode        string          `json:"mode"`
+	Level       string          `json:"level"`
+	TeamSize    int             `json:"team_size"`
+	Region      string          `json:"region,omitempty"`
+	SwissRounds int             `json:"swiss_rounds,omitempty"`
+	Teams       []*BracketTeam  `json:"teams"`
+	Matches     []*BracketMatch `json:"matches"`
+	Status      string          `json:"status"`
+	Champion    string          `json:"champion,omitempty"`
+	CreatedBy   string          `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type TournamentBracketAdvanceRequest struct {
+	BracketID string `json:"bracket_id"`
+}
+
+type TournamentBracketCreateRequest struct {
+	GroupID  string                         `json:"group_id"`
+	Name     string                         `json:"name"`
+	Format   BracketFormat                  `json:"format"`
+	Level    string                         `json:"level,omitempty"`
+	TeamSize int                            `json:"team_size,omitempty"`
+	Region   string                         `json:"region,omitempty"`
+	Teams    []TournamentBracketTeamRequest `json:"teams"`
+	Allocate bool                           `json:"allocate,omitempty"`
+}
+
+type TournamentBracketListResponse struct {
+	Brackets []*TournamentBracket `json:"brackets"`
+}
+
+type TournamentBracketReportRequest struct {
+	BracketID   string `json:"bracket_id"`
+	MatchID     string `json:"match_id"`
+	BlueScore   int    `json:"blue_score"`
+	OrangeScore int    `json:"orange_score"`
+}
+
+type TournamentBracketRequest struct {
+	BracketID string `json:"bracket_id,omitempty"`
+	GroupID   string `json:"group_id,omitempty"`
+}
+
+type TournamentBracketResponse struct {
+	Bracket   *TournamentBracket `json:"bracket"`
+	Standings []*BracketStanding `json:"standings"`
+	Allocated []*BracketMatch    `json:"allocated,omitempty"`
+}
+
+type TournamentBracketTeamRequest struct {
+	Name      string   `json:"name"`
+	Seed      int      `json:"seed,omitempty"`
+	PlayerIDs []string `json:"player_ids"`
+}
+
+type UserServerProfileRPCRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
+	XPID      string    `json:"xp_id"`
+	DiscordID string    `json:"discord_id"`
+	GuildID   string    `json:"guild_id"`
+	GroupID   uuid.UUID `json:"group_id"`
+}
+
+type VRMLRedirectRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
@@ -0,0 +1,330 @@
+package evrclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	nevrapi "github.com/echotools/nevr-common/v3/api"
+	"github.com/echotools/nevr-common/v4/gen/go/rtapi"
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama/v3/evrclient/evrrpc"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func call[T any](ctx context.Context, c *Client, id string, query url.Values, request any) (*T, error) {
+	response := new(T)
+	if err := c.Call(ctx, id, query, request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) AccountSearch(ctx context.Context, request *evrrpc.AccountSearchRequest) (*evrrpc.AccountSearchResponse, error) {
+	return call[evrrpc.AccountSearchResponse](ctx, c, "account/search", nil, request)
+}
+
+func (c *Client) AccountLookup(ctx context.Context, request *evrrpc.AccountLookupRequest) (*evrrpc.AccountLookupRPCResponse, error) {
+	return call[evrrpc.AccountLookupRPCResponse](ctx, c, "account/lookup", nil, request)
+}
+
+// AuthenticatePassword returns a session. Use SetSessionToken to authenticate subsequent calls with it.
+func (c *Client) AuthenticatePassword(ctx context.Context, request *evrrpc.AuthenticatePasswordRequest) (*api.Session, error) {
+	return call[api.Session](ctx, c, "account/authenticate/password", nil, request)
+}
+
+func (c *Client) LeaderboardHaystack(ctx context.Context, request *evrrpc.LeaderboardHaystackRequest) (*evrrpc.LeaderboardHaystackResponse, error) {
+	return call[evrrpc.LeaderboardHaystackResponse](ctx, c, "leaderboard/haystack", nil, request)
+}
+
+func (c *Client) LeaderboardRecords(ctx context.Context, request *evrrpc.LeaderboardRecordsListRequest) (*evrrpc.LeaderboardRecordsListResponse, error) {
+	return call[evrrpc.LeaderboardRecordsListResponse](ctx, c, "leaderboard/records", nil, request)
+}
+
+func (c *Client) LinkDevice(ctx context.Context, request *evrrpc.LinkDeviceRpcRequest) error {
+	return c.Call(ctx, "link/device", nil, request, nil)
+}
+
+func (c *Client) LinkUsernameDevice(ctx context.Context, request *evrrpc.LinkUserIdDeviceRpcRequest) (*evrrpc.RPCSuccessResponse, error) {
+	return call[evrrpc.RPCSuccessResponse](ctx, c, "link/usernamedevice", nil, request)
+}
+
+func (c *Client) SignInDiscord(ctx context.Context, request *evrrpc.DiscordSignInRpcRequest) (*evrrpc.DiscordSignInRpcResponse, error) {
+	return call[evrrpc.DiscordSignInRpcResponse](ctx, c, "signin/discord", nil, request)
+}
+
+func (c *Client) MatchListPublic(ctx context.Context) (*evrrpc.MatchListPublicRPCResponse, error) {
+	return call[evrrpc.MatchListPublicRPCResponse](ctx, c, "match/public", nil, nil)
+}
+
+// MatchHistory lists completed matches, most recent first. Pass the response cursor to get the next page.
+func (c *Client) MatchHistory(ctx context.Context, request *evrrpc.MatchHistoryRequest) (*evrrpc.MatchHistoryResponse, error) {
+	return call[evrrpc.MatchHistoryResponse](ctx, c, "match/history", nil, request)
+}
+
+func (c *Client) Match(ctx context.Context, request *evrrpc.MatchRpcRequest) (*evrrpc.MatchRpcResponse, error) {
+	return call[evrrpc.MatchRpcResponse](ctx, c, "match", nil, request)
+}
+
+func (c *Client) MatchPrepare(ctx context.Context, request *evrrpc.PrepareMatchRPCRequest) (*evrrpc.MatchLabel, error) {
+	return call[evrrpc.MatchLabel](ctx, c, "match/prepare", nil, request)
+}
+
+func (c *Client) MatchAllocate(ctx context.Context, request *nevrapi.PrepareMatchRequest) (*evrrpc.MatchLabel, error) {
+	return call[evrrpc.MatchLabel](ctx, c, "match/allocate", nil, request)
+}
+
+func (c *Client) MatchTerminate(ctx context.Context, request *evrrpc.ShutdownMatchRequest) (*evrrpc.ShutdownMatchResponse, error) {
+	return call[evrrpc.ShutdownMatchResponse](ctx, c, "match/terminate", nil, request)
+}
+
+func (c *Client) MatchBuild(ctx context.Context, request *evrrpc.BuildMatchRequest) (*evrrpc.BuildMatchResponse, error) {
+	return call[evrrpc.BuildMatchResponse](ctx, c, "match/build", nil, request)
+}
+
+func (c *Client) PlayerSetNextMatch(ctx context.Context, request *evrrpc.SetNextMatchRPCRequest) (*evrrpc.SetNextMatchRPCResponse, error) {
+	return call[evrrpc.SetNextMatchRPCResponse](ctx, c, "player/setnextmatch", nil, request)
+}
+
+func (c *Client) PlayerStatistics(ctx context.Context, request *evrrpc.PlayerStatisticsRequest) (*evrrpc.PlayerStatisticsResponse, error) {
+	return call[evrrpc.PlayerStatisticsResponse](ctx, c, "player/statistics", nil, request)
+}
+
+func (c *Client) PlayerKick(ctx context.Context, request *evrrpc.KickPlayerRPCRequest) (*evrrpc.RPCSuccessResponse, error) {
+	return call[evrrpc.RPCSuccessResponse](ctx, c, "player/kick", nil, request)
+}
+
+func (c *Client) EnforcementAppeal(ctx context.Context, request *evrrpc.EnforcementAppealRequest) (*evrrpc.EnforcementAppealResponse, error) {
+	return call[evrrpc.EnforcementAppealResponse](ctx, c, "enforcement/appeal", nil, request)
+}
+
+func (c *Client) EnforcementAppealReview(ctx context.Context, request *evrrpc.EnforcementAppealReviewRequest) (*evrrpc.EnforcementAppealResponse, error) {
+	return call[evrrpc.EnforcementAppealResponse](ctx, c, "enforcement/appeal/review", nil, request)
+}
+
+// EnforcementEscalation returns the enforcement escalation policy of a guild.
+func (c *Client) EnforcementEscalation(ctx context.Context, groupID string) (*evrrpc.EnforcementEscalationResponse, error) {
+	return call[evrrpc.EnforcementEscalationResponse](ctx, c, "enforcement/escalation", url.Values{"group_id": {groupID}}, nil)
+}
+
+// EnforcementEscalationSet sets the enforcement escalation policy of a guild; a nil policy removes it.
+func (c *Client) EnforcementEscalationSet(ctx context.Context, request *evrrpc.EnforcementEscalationSetRequest) (*evrrpc.EnforcementEscalationResponse, error) {
+	return call[evrrpc.EnforcementEscalationResponse](ctx, c, "enforcement/escalation/set", nil, request)
+}
+
+// EnforcementEscalationEvaluate suggests the suspension for a player's next offense of the category.
+func (c *Client) EnforcementEscalationEvaluate(ctx context.Context, groupID, userID, category string) (*evrrpc.EnforcementEscalation, error) {
+	return call[evrrpc.EnforcementEscalation](ctx, c, "enforcement/escalation/evaluate", url.Values{"group_id": {groupID}, "user_id": {userID}, "category": {category}}, nil)
+}
+
+func (c *Client) PlayerProfile(ctx context.Context, request *evrrpc.UserServerProfileRPCRequest) (*evr.ServerProfile, error) {
+	return call[evr.ServerProfile](ctx, c, "player/profile", nil, request)
+}
+
+func (c *Client) ServiceStatus(ctx context.Context) ([]evrrpc.ServiceStatusService, error) {
+	response, err := call[[]evrrpc.ServiceStatusService](ctx, c, "evr/servicestatus", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return *response, nil
+}
+
+func (c *Client) ImportLoadouts(ctx context.Context, request *evrrpc.ImportLoadoutRpcRequest) (*evrrpc.ImportLoadoutRpcResponse, error) {
+	return call[evrrpc.ImportLoadoutRpcResponse](ctx, c, "importloadouts", nil, request)
+}
+
+func (c *Client) MatchmakerStream(ctx context.Context, request *evrrpc.MatchmakerStreamRequest) (*evrrpc.MatchmakerStreamResponse, error) {
+	return call[evrrpc.MatchmakerStreamResponse](ctx, c, "matchmaker/stream", nil, request)
+}
+
+func (c *Client) MatchmakerState(ctx context.Context) (*evrrpc.MatchmakerStateResponse, error) {
+	return call[evrrpc.MatchmakerStateResponse](ctx, c, "matchmaker/state", nil, nil)
+}
+
+// MatchmakerCandidates returns the latest candidates and matches, as raw matchmaker entries.
+func (c *Client) MatchmakerCandidates(ctx context.Context) (*MatchmakerCandidatesResponse, error) {
+	return call[MatchmakerCandidatesResponse](ctx, c, "matchmaker/candidates", nil, nil)
+}
+
+// MatchmakerSnapshots lists the stored matchmaker candidate snapshots, newest first.
+func (c *Client) MatchmakerSnapshots(ctx context.Context) (*evrrpc.MatchmakerSnapshotIndex, error) {
+	return call[evrrpc.MatchmakerSnapshotIndex](ctx, c, "matchmaker/snapshots", nil, nil)
+}
+
+// MatchmakerSimulate replays stored candidate snapshots with alternative matchmaking settings.
+func (c *Client) MatchmakerSimulate(ctx context.Context, request *evrrpc.MatchmakerSimulationRequest) (*evrrpc.MatchmakerSimulationResponse, error) {
+	return call[evrrpc.MatchmakerSimulationResponse](ctx, c, "matchmaker/simulate", nil, request)
+}
+
+// MatchmakerCandidatesResponse is the decodable form of evrrpc.MatchmakerCandidatesRPCResponse.
+type MatchmakerCandidatesResponse struct {
+	Candidates [][]*evrrpc.MatchmakerEntry `json:"candidates"`
+	Matches    [][]*evrrpc.MatchmakerEntry `json:"matches"`
+}
+
+func (c *Client) StreamJoin(ctx context.Context, request *evrrpc.StreamJoinRequest) (*evrrpc.StreamJoinResponse, error) {
+	return call[evrrpc.StreamJoinResponse](ctx, c, "stream/join", nil, request)
+}
+
+func (c *Client) ServerScore(ctx context.Context, request *evrrpc.ServerScoreRPCRequest) (*evrrpc.ServerScoreRPCResponse, error) {
+	return call[evrrpc.ServerScoreRPCResponse](ctx, c, "server/score", nil, request)
+}
+
+func (c *Client) ServerScores(ctx context.Context, request *evrrpc.ServerScoresRPCRequest) (*evrrpc.ServerScoresRPCResponse, error) {
+	return call[evrrpc.ServerScoresRPCResponse](ctx, c, "server/scores", nil, request)
+}
+
+// ForceCheck returns the force code if the user of the login session is a Force User.
+func (c *Client) ForceCheck(ctx context.Context, request *evrrpc.CheckForceUserRequest) (string, error) {
+	return c.CallText(ctx, "forcecheck", url.Values{"login_session_id": {request.LoginSessionID}}, nil)
+}
+
+func (c *Client) GuildGroups(ctx context.Context, groupIDs ...string) (*evrrpc.GuildGroupResponse, error) {
+	return call[evrrpc.GuildGroupResponse](ctx, c, "guildgroup", url.Values{"ids": {strings.Join(groupIDs, ",")}}, nil)
+}
+
+// RecordingStart starts recording the EVR traffic of a session or match, on the node that handles the call.
+func (c *Client) RecordingStart(ctx context.Context, request *evrrpc.RecordingStartRequest) (*evrrpc.EvrRecorder, error) {
+	return call[evrrpc.EvrRecorder](ctx, c, "recording/start", nil, request)
+}
+
+func (c *Client) RecordingStop(ctx context.Context, request *evrrpc.RecordingStopRequest) (*evrrpc.EvrRecorder, error) {
+	return call[evrrpc.EvrRecorder](ctx, c, "recording/stop", nil, request)
+}
+
+func (c *Client) RecordingList(ctx context.Context) (*evrrpc.RecordingListResponse, error) {
+	return call[evrrpc.RecordingListResponse](ctx, c, "recording/list", nil, nil)
+}
+
+// SymbolResolve submits candidate strings for the unknown symbols seen in traffic, and returns those accepted.
+func (c *Client) SymbolResolve(ctx context.Context, candidates ...string) (*evrrpc.SymbolResolveResponse, error) {
+	return call[evrrpc.SymbolResolveResponse](ctx, c, "symbol/resolve", nil, &evrrpc.SymbolResolveRequest{Candidates: candidates})
+}
+
+func (c *Client) SymbolUnknown(ctx context.Context) (*evrrpc.SymbolUnknownResponse, error) {
+	return call[evrrpc.SymbolUnknownResponse](ctx, c, "symbol/unknown", nil, nil)
+}
+
+// ConfigResource returns the stored versions of a config resource type, oldest first.
+func (c *Client) ConfigResource(ctx context.Context, _type string) (*evrrpc.ConfigResourceHistory, error) {
+	return call[evrrpc.ConfigResourceHistory](ctx, c, "config/resource", url.Values{"type": {_type}}, nil)
+}
+
+// ConfigResourceSet stores a new version of a config resource type.
+func (c *Client) ConfigResourceSet(ctx context.Context, request *evrrpc.ConfigResourceSetRequest) (*evrrpc.ConfigResourceSet, error) {
+	return call[evrrpc.ConfigResourceSet](ctx, c, "config/resource/set", nil, request)
+}
+
+// ConfigResourceRollback restores an earlier version of a config resource type, as the next version.
+func (c *Client) ConfigResourceRollback(ctx context.Context, _type string, version int) (*evrrpc.ConfigResourceSet, error) {
+	return call[evrrpc.ConfigResourceSet](ctx, c, "config/resource/rollback", nil, &evrrpc.ConfigResourceRollbackRequest{Type: _type, Version: version})
+}
+
+// ConfigResourceResolve returns the config resource that a client would be served.
+func (c *Client) ConfigResourceResolve(ctx context.Context, request *evrrpc.ConfigResourceResolveRequest) (*evrrpc.ConfigResourceResolveResponse, error) {
+	return call[evrrpc.ConfigResourceResolveResponse](ctx, c, "config/resource/resolve", nil, request)
+}
+
+// Document returns a game document in the language, or its nearest fallback, with its pages.
+func (c *Client) Document(ctx context.Context, _type, language string) (*evrrpc.DocumentResponse, error) {
+	return call[evrrpc.DocumentResponse](ctx, c, "document", url.Values{"type": {_type}, "lang": {language}}, nil)
+}
+
+// DocumentSet stores a game document in a language.
+func (c *Client) DocumentSet(ctx context.Context, request *evrrpc.DocumentSetRequest) (*evrrpc.DocumentResponse, error) {
+	return call[evrrpc.DocumentResponse](ctx, c, "document/set", nil, request)
+}
+
+// DocumentPreview returns the pages of a game document's text, without storing it.
+func (c *Client) DocumentPreview(ctx context.Context, request *evrrpc.DocumentSetRequest) (*evrrpc.DocumentResponse, error) {
+	return call[evrrpc.DocumentResponse](ctx, c, "document/preview", nil, request)
+}
+
+// TournamentBracket returns a tournament bracket with its standings.
+func (c *Client) TournamentBracket(ctx context.Context, bracketID string) (*evrrpc.TournamentBracketResponse, error) {
+	return call[evrrpc.TournamentBracketResponse](ctx, c, "tournament/bracket", url.Values{"bracket_id": {bracketID}}, nil)
+}
+
+// TournamentBrackets lists a guild's tournament brackets, most recent first.
+func (c *Client) TournamentBrackets(ctx context.Context, groupID string) (*evrrpc.TournamentBracketListResponse, error) {
+	return call[evrrpc.TournamentBracketListResponse](ctx, c, "tournament/bracket", url.Values{"group_id": {groupID}}, nil)
+}
+
+// TournamentBracketCreate creates a tournament bracket for a guild.
+func (c *Client) TournamentBracketCreate(ctx context.Context, request *evrrpc.TournamentBracketCreateRequest) (*evrrpc.TournamentBracketResponse, error) {
+	return call[evrrpc.TournamentBracketResponse](ctx, c, "tournament/bracket/create", nil, request)
+}
+
+// TournamentBracketAdvance allocates the ready matches of a tournament bracket.
+func (c *Client) TournamentBracketAdvance(ctx context.Context, bracketID string) (*evrrpc.TournamentBracketResponse, error) {
+	return call[evrrpc.TournamentBracketResponse](ctx, c, "tournament/bracket/advance", nil, &evrrpc.TournamentBracketAdvanceRequest{BracketID: bracketID})
+}
+
+// TournamentBracketReport records or corrects the result of a bracket match.
+func (c *Client) TournamentBracketReport(ctx context.Context, request *evrrpc.TournamentBracketReportRequest) (*evrrpc.TournamentBracketResponse, error) {
+	return call[evrrpc.TournamentBracketResponse](ctx, c, "tournament/bracket/report", nil, request)
+}
+
+// StatisticsDeadLetters lists the statistics entries that failed too many times to be written.
+func (c *Client) StatisticsDeadLetters(ctx context.Context, leaderboardID, userID string, limit int) (*evrrpc.StatisticsDeadLetterResponse, error) {
+	query := url.Values{"leaderboard_id": {leaderboardID}, "user_id": {userID}, "limit": {strconv.Itoa(limit)}}
+	return call[evrrpc.StatisticsDeadLetterResponse](ctx, c, "statistics/deadletter", query, nil)
+}
+
+// StatisticsDeadLetterRequeue moves statistics dead letters back to the queue.
+func (c *Client) StatisticsDeadLetterRequeue(ctx context.Context, ids []int64) (*evrrpc.StatisticsDeadLetterRequeueResponse, error) {
+	return call[evrrpc.StatisticsDeadLetterRequeueResponse](ctx, c, "statistics/deadletter/requeue", nil, &evrrpc.StatisticsDeadLetterRequeueRequest{IDs: ids})
+}
+
+// LiveScoreboardFeed opts a match in to the public live scoreboard feed and issues its token, or revokes it.
+func (c *Client) LiveScoreboardFeed(ctx context.Context, request *evrrpc.LiveScoreboardFeedRequest) (*evrrpc.LiveScoreboardFeedResponse, error) {
+	return call[evrrpc.LiveScoreboardFeedResponse](ctx, c, "match/scoreboard/feed", nil, request)
+}
+
+// AlternateScores explains the confidence scores of a player's alternate accounts.
+func (c *Client) AlternateScores(ctx context.Context, userID string) (*evrrpc.AlternateScoresResponse, error) {
+	return call[evrrpc.AlternateScoresResponse](ctx, c, "alternates/scores", url.Values{"user_id": {userID}}, nil)
+}
+
+// AlternateReviews lists the review queue of alternate account links; status defaults to pending.
+func (c *Client) AlternateReviews(ctx context.Context, status evrrpc.AlternateReviewStatus, limit int, cursor string) (*evrrpc.AlternateReviewsResponse, error) {
+	query := url.Values{"status": {string(status)}, "limit": {strconv.Itoa(limit)}, "cursor": {cursor}}
+	return call[evrrpc.AlternateReviewsResponse](ctx, c, "alternates/reviews", query, nil)
+}
+
+// AlternateReview confirms or dismisses the link between two accounts.
+func (c *Client) AlternateReview(ctx context.Context, request *evrrpc.AlternateReviewRequest) (*evrrpc.AlternateReviewResponse, error) {
+	return call[evrrpc.AlternateReviewResponse](ctx, c, "alternates/review", nil, request)
+}
+
+// GameServerFleet lists the registered game servers on all nodes, with their health and registration history.
+func (c *Client) GameServerFleet(ctx context.Context, request *evrrpc.GameServerFleetRequest) (*evrrpc.GameServerFleetResponse, error) {
+	return call[evrrpc.GameServerFleetResponse](ctx, c, "gameserver/fleet", nil, request)
+}
+
+// MapRotation returns the map rotation policy in effect for a guild, and its recent levels.
+func (c *Client) MapRotation(ctx context.Context, groupID string) (*evrrpc.MapRotationResponse, error) {
+	return call[evrrpc.MapRotationResponse](ctx, c, "guildgroup/maprotation", url.Values{"group_id": {groupID}}, nil)
+}
+
+// MapRotationSet sets the map rotation policy of a guild; a nil policy uses the service default.
+func (c *Client) MapRotationSet(ctx context.Context, request *evrrpc.MapRotationSetRequest) (*evrrpc.MapRotationResponse, error) {
+	return call[evrrpc.MapRotationResponse](ctx, c, "guildgroup/maprotation/set", nil, request)
+}
+
+// SessionEvents lists the stored session events of a match.
+func (c *Client) SessionEvents(ctx context.Context, matchID string) (*evrrpc.SessionEventsResponse, error) {
+	return call[evrrpc.SessionEventsResponse](ctx, c, "session_events/get", nil, &evrrpc.SessionEventsRequest{MatchUUID: matchID})
+}
+
+// SessionEventStore stores a session event of a match. The frame is sent in its protobuf JSON encoding.
+func (c *Client) SessionEventStore(ctx context.Context, frame *rtapi.LobbySessionStateFrame) (*evrrpc.SessionEventStoreResponse, error) {
+	data, err := protojson.Marshal(frame)
+	if err != nil {
+		return nil, err
+	}
+	return call[evrrpc.SessionEventStoreResponse](ctx, c, "session_events/store", nil, json.RawMessage(data))
+}
@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
@@ -118,6 +119,14 @@ func main() {
 				os.Exit(1)
 			}
 			return
+		case "evr":
+			if err := server.RunEvrCommand(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				if !errors.Is(err, flag.ErrHelp) {
+					fmt.Fprintln(os.Stderr, err)
+				}
+				os.Exit(1)
+			}
+			return
 		case "healthcheck":
 			port := "7350"
 			if len(os.Args) > 2 {
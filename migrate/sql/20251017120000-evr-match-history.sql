@@ -0,0 +1,48 @@
+/*
+ * Copyright 2025 The Nakama Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS evr_match_history (
+    PRIMARY KEY (match_id),
+
+    match_id    VARCHAR(128) NOT NULL,
+    group_id    UUID         NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    lobby_type  VARCHAR(32)  NOT NULL DEFAULT '',
+    mode        VARCHAR(64)  NOT NULL DEFAULT '',
+    data        JSONB        NOT NULL DEFAULT '{}',
+    start_time  TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    end_time    TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    create_time TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS evr_match_history_group_id_end_time_idx ON evr_match_history (group_id, end_time DESC, match_id);
+
+CREATE TABLE IF NOT EXISTS evr_match_history_player (
+    PRIMARY KEY (user_id, match_id),
+    FOREIGN KEY (match_id) REFERENCES evr_match_history (match_id) ON DELETE CASCADE,
+
+    user_id    UUID         NOT NULL,
+    match_id   VARCHAR(128) NOT NULL,
+    group_id   UUID         NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    lobby_type VARCHAR(32)  NOT NULL DEFAULT '',
+    end_time   TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS evr_match_history_player_user_id_end_time_idx ON evr_match_history_player (user_id, end_time DESC, match_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS evr_match_history_player;
+DROP TABLE IF EXISTS evr_match_history;
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2025 The Nakama Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS evr_statistics_queue (
+    PRIMARY KEY (id),
+
+    id                BIGSERIAL    NOT NULL,
+    leaderboard_id    VARCHAR(128) NOT NULL,
+    user_id           UUID         NOT NULL,
+    data              JSONB        NOT NULL DEFAULT '{}',
+    attempts          INTEGER      NOT NULL DEFAULT 0,
+    last_error        TEXT         NOT NULL DEFAULT '',
+    next_attempt_time TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    create_time       TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS evr_statistics_queue_next_attempt_time_idx ON evr_statistics_queue (next_attempt_time, id);
+
+CREATE TABLE IF NOT EXISTS evr_statistics_dead_letter (
+    PRIMARY KEY (id),
+
+    id             BIGINT       NOT NULL,
+    leaderboard_id VARCHAR(128) NOT NULL,
+    user_id        UUID         NOT NULL,
+    data           JSONB        NOT NULL DEFAULT '{}',
+    attempts       INTEGER      NOT NULL DEFAULT 0,
+    last_error     TEXT         NOT NULL DEFAULT '',
+    create_time    TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    dead_time      TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS evr_statistics_dead_letter_dead_time_idx ON evr_statistics_dead_letter (dead_time DESC, id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS evr_statistics_dead_letter;
+DROP TABLE IF EXISTS evr_statistics_queue;
@@ -277,6 +277,9 @@ func StartConsoleServer(logger *zap.Logger, startupLogger *zap.Logger, db *sql.D
 
 	grpcGatewayRouter := mux.NewRouter()
 	grpcGatewayRouter.HandleFunc("/v2/console/storage/import", s.importStorage)
+	grpcGatewayRouter.HandleFunc("/v2/console/evr/match/history", s.evrMatchHistory).Methods(http.MethodGet)
+	grpcGatewayRouter.HandleFunc("/v2/console/evr/symbols", s.evrSymbolsUnknown).Methods(http.MethodGet)
+	grpcGatewayRouter.HandleFunc("/v2/console/evr/symbols/resolve", s.evrSymbolsResolve).Methods(http.MethodPost)
 
 	// Register public subscription callback endpoints
 	if config.GetIAP().Apple.NotificationsEndpointId != "" {
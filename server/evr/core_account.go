@@ -95,7 +95,19 @@ type ServerProfile struct {
 	EquippedCosmetics EquippedCosmetics          `json:"loadout"`         // Equipped cosmetics
 	Social            ServerSocial               `json:"social"`          // Social settings
 	// If DeveloperFeatures is not null, the player will have a gold name
-	DeveloperFeatures *DeveloperFeatures `json:"dev,omitempty"` // Developer features
+	DeveloperFeatures *DeveloperFeatures    `json:"dev,omitempty"`   // Developer features
+	Ranks             map[string]ServerRank `json:"ranks,omitempty"` // Ranked ladder standings, by mode
+}
+
+// ServerRank is a player's standing on the ranked ladder of a mode.
+type ServerRank struct {
+	Rank                string `json:"rank"`                           // e.g. "Gold II", or "Unranked"
+	Tier                string `json:"tier,omitempty"`                 // e.g. "gold"; empty until placed
+	Division            int    `json:"division,omitempty"`             // 1 is the highest division of the tier
+	PlacementsRemaining int    `json:"placements_remaining,omitempty"` // Placement matches left this season
+	Series              string `json:"series,omitempty"`               // "promotion" or "demotion", during a series
+	SeriesWins          int    `json:"series_wins,omitempty"`
+	SeriesLosses        int    `json:"series_losses,omitempty"`
 }
 
 func (s ServerProfile) IsUnlocked(id string) bool {
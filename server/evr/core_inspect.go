@@ -0,0 +1,130 @@
+package evr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const messageHeaderLength = 24 // Marker + Symbol + Data Length
+
+// InspectedMessage is a message found in a dump, with its framing.
+type InspectedMessage struct {
+	Offset  int     `json:"offset"`         // The offset of the message marker in the dump.
+	Hash    string  `json:"hash"`           // The type symbol, as hex.
+	Symbol  Symbol  `json:"symbol"`         // The type symbol, as a token if it is known.
+	Type    string  `json:"type,omitempty"` // The Go type; empty if the symbol has no message type.
+	Length  int     `json:"length"`         // The length of the message data.
+	Message Message `json:"message,omitempty"`
+	Data    string  `json:"data,omitempty"` // The message data as hex, if it was not decoded.
+	Error   string  `json:"error,omitempty"`
+}
+
+// Unknown returns whether the message symbol has no message type.
+func (m *InspectedMessage) Unknown() bool {
+	return m.Type == ""
+}
+
+// InspectPacket finds and decodes the framed messages in data.
+// Unlike ParsePacket, it skips bytes outside of messages, and reports unknown and
+// malformed messages instead of failing, so it can be used on captures and partial dumps.
+func InspectPacket(data []byte) []*InspectedMessage {
+	messages := make([]*InspectedMessage, 0)
+	offset := 0
+	for {
+		i := bytes.Index(data[offset:], MessageMarker)
+		if i < 0 {
+			break
+		}
+		offset += i
+
+		if len(data)-offset < messageHeaderLength {
+			messages = append(messages, &InspectedMessage{
+				Offset: offset,
+				Data:   hex.EncodeToString(data[offset+len(MessageMarker):]),
+				Error:  "truncated header",
+			})
+			break
+		}
+
+		sym := Symbol(dUint64(data[offset+8:]))
+		l := dUint64(data[offset+16:])
+		m := &InspectedMessage{
+			Offset: offset,
+			Hash:   sym.HexString(),
+			Symbol: sym,
+			Length: int(min(l, uint64(len(data)))),
+		}
+		messages = append(messages, m)
+		start := offset + messageHeaderLength
+
+		if l > uint64(len(data)-start) {
+			m.Data = hex.EncodeToString(data[start:])
+			m.Error = fmt.Sprintf("truncated message (expected %d bytes, got %d)", l, len(data)-start)
+			break
+		}
+		b := data[start : start+int(l)]
+		offset = start + int(l)
+
+		message := MessageTypeOf(sym)
+		if message == nil {
+			m.Data = hex.EncodeToString(b)
+			continue
+		}
+		m.Type = reflect.TypeOf(message).String()
+		if err := message.Stream(NewEasyStream(DecodeMode, b)); err != nil {
+			m.Data = hex.EncodeToString(b)
+			m.Error = err.Error()
+			continue
+		}
+		m.Message = message
+	}
+	return messages
+}
+
+// MessageTypeByName returns a new message of the named type. The name may be the Go type name,
+// with or without the "*evr." prefix, the symbol token, or the symbol as 0x prefixed hex.
+func MessageTypeByName(name string) Message {
+	for _, typ := range []string{name, "*evr." + strings.TrimPrefix(name, "*evr.")} {
+		if sym, ok := reverseSymbolTypes[typ]; ok {
+			return MessageTypeOf(Symbol(sym))
+		}
+	}
+	return MessageTypeOf(ToSymbol(name))
+}
+
+// EncodeMessage returns the wire bytes of the named message type, with the fields from the JSON data.
+func EncodeMessage(name string, data []byte) ([]byte, error) {
+	message := MessageTypeByName(name)
+	if message == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, name)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, message); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %T: %w", message, err)
+		}
+	}
+	return Marshal(message)
+}
+
+// SymbolInfo describes a symbol, from the symbol cache, the runtime dictionary and the message types.
+type SymbolInfo struct {
+	Hash  string `json:"hash"`
+	Token string `json:"token,omitempty"` // Empty if the symbol is not in the cache.
+	Type  string `json:"type,omitempty"`  // Empty if the symbol has no message type.
+}
+
+// LookupSymbol returns what is known of the symbol.
+func LookupSymbol(s Symbol) SymbolInfo {
+	info := SymbolInfo{Hash: s.HexString()}
+	if t, ok := LookupSymbolToken(s); ok {
+		info.Token = string(t)
+	}
+	if m := MessageTypeOf(s); m != nil {
+		info.Type = reflect.TypeOf(m).String()
+	}
+	return info
+}
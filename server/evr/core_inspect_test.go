@@ -0,0 +1,72 @@
+package evr
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestInspectPacket(t *testing.T) {
+	failure, err := Marshal(NewLoginFailure(EvrId{PlatformCode: 4, AccountId: 1}, "bad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown, _ := WrapBytes(Symbol(0x1122334455667788), []byte{1, 2, 3})
+
+	data := append([]byte("garbage"), testMessage...)
+	data = append(data, unknown...)
+	data = append(data, failure...)
+	data = append(data, failure[:30]...)
+
+	messages := InspectPacket(data)
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(messages))
+	}
+	if messages[0].Offset != 7 || messages[0].Type != "*evr.STcpConnectionUnrequireEvent" {
+		t.Errorf("unexpected message %+v", messages[0])
+	}
+	if !messages[1].Unknown() || messages[1].Data != "010203" || messages[1].Hash != "0x1122334455667788" {
+		t.Errorf("unexpected unknown message %+v", messages[1])
+	}
+	if m, ok := messages[2].Message.(*LoginFailure); !ok || m.ErrorMessage != "bad" {
+		t.Errorf("unexpected message %+v", messages[2])
+	}
+	if messages[3].Error == "" {
+		t.Errorf("expected truncated message error")
+	}
+}
+
+func TestEncodeMessage(t *testing.T) {
+	message := NewLoginFailure(EvrId{PlatformCode: 4, AccountId: 1}, "bad")
+	want, err := Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"LoginFailure", "*evr.LoginFailure", "SNSLoginFailure", LookupSymbol(ToSymbol("SNSLoginFailure")).Hash} {
+		got, err := EncodeMessage(name, data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: got %x, want %x", name, got, want)
+		}
+	}
+
+	if _, err := EncodeMessage("NoSuchMessage", nil); err == nil {
+		t.Error("expected error for unknown message")
+	}
+}
+
+func TestLookupSymbol(t *testing.T) {
+	info := LookupSymbol(ToSymbol("SNSLoginFailure"))
+	if info.Type != "*evr.LoginFailure" {
+		t.Errorf("unexpected type %q", info.Type)
+	}
+	if info := LookupSymbol(ToSymbol("us-east")); info.Token != "us-east" || info.Type != "" {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
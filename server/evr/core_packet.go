@@ -106,7 +106,7 @@ func (s Symbol) HexString() string {
 // or returns the hex string representation of the token.
 // ToSymbol will detect 0x prefixed hex strings.
 func (s Symbol) Token() SymbolToken {
-	t, ok := SymbolCache[s]
+	t, ok := LookupSymbolToken(s)
 	if !ok {
 		// If it's not found, just return the number as a hex string
 		t = SymbolToken(s.HexString())
@@ -266,6 +266,7 @@ func ParsePacket(data []byte) ([]Message, error) {
 		}
 		// Read the message type and data length.
 		sym := dUint64(buf.Next(8))
+		ObserveSymbol(Symbol(sym))
 
 		// Ignore specific messages
 		if slices.Contains(ignoredSymbols, sym) {
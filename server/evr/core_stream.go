@@ -63,7 +63,19 @@ func (s *EasyStream) StreamSymbol(value *Symbol) error {
 func (s *EasyStream) StreamNumber(order binary.ByteOrder, value any) error {
 	switch s.Mode {
 	case DecodeMode:
-		return binary.Read(s.r, order, value)
+		if err := binary.Read(s.r, order, value); err != nil {
+			return err
+		}
+		// Payload symbols (levels, modes, regions...) are as likely to be unknown as the message types.
+		switch v := value.(type) {
+		case *Symbol:
+			ObserveSymbol(*v)
+		case []Symbol:
+			for _, sym := range v {
+				ObserveSymbol(sym)
+			}
+		}
+		return nil
 	case EncodeMode:
 		return binary.Write(s.w, order, value)
 	default:
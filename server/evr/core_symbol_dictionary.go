@@ -0,0 +1,232 @@
+package evr
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxObservedSymbols bounds the unknown symbols tracked, so a misbehaving client can not grow it without limit.
+const maxObservedSymbols = 4096
+
+// The runtime symbol dictionary extends SymbolCache with tokens loaded or resolved while running.
+// It is replaced on write, so lookups do not lock.
+var (
+	symbolDictionaryMu sync.Mutex
+	symbolDictionary   atomic.Pointer[map[Symbol]SymbolToken]
+
+	observedSymbolsMu sync.Mutex
+	observedSymbols   = make(map[Symbol]*ObservedSymbol)
+
+	// The unknown symbols seen by the other nodes, shared through storage.
+	peerObservedSymbols atomic.Pointer[map[Symbol]ObservedSymbol]
+)
+
+// ObservedSymbol is an unknown symbol seen in traffic.
+type ObservedSymbol struct {
+	Symbol    Symbol    `json:"-"`
+	Hash      string    `json:"hash"`
+	Count     int64     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// LookupSymbolToken returns the token of the symbol, from SymbolCache or the runtime dictionary.
+func LookupSymbolToken(s Symbol) (SymbolToken, bool) {
+	if t, ok := SymbolCache[s]; ok {
+		return t, true
+	}
+	if d := symbolDictionary.Load(); d != nil {
+		t, ok := (*d)[s]
+		return t, ok
+	}
+	return "", false
+}
+
+// AddSymbolTokens adds the tokens to the runtime dictionary, and returns how many were not already known.
+func AddSymbolTokens(tokens ...string) int {
+	symbolDictionaryMu.Lock()
+	defer symbolDictionaryMu.Unlock()
+
+	var current map[Symbol]SymbolToken
+	if d := symbolDictionary.Load(); d != nil {
+		current = *d
+	}
+
+	var added map[Symbol]SymbolToken
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		s := Symbol(HashString(token, hashLookupArray))
+		if _, ok := SymbolCache[s]; ok {
+			continue
+		}
+		if _, ok := current[s]; ok {
+			continue
+		}
+		if _, ok := added[s]; ok {
+			continue
+		}
+		if added == nil {
+			added = make(map[Symbol]SymbolToken)
+		}
+		added[s] = SymbolToken(token)
+	}
+	if len(added) == 0 {
+		return 0
+	}
+
+	next := make(map[Symbol]SymbolToken, len(current)+len(added))
+	for s, t := range current {
+		next[s] = t
+	}
+	for s, t := range added {
+		next[s] = t
+	}
+	symbolDictionary.Store(&next)
+
+	observedSymbolsMu.Lock()
+	for s := range added {
+		delete(observedSymbols, s)
+	}
+	observedSymbolsMu.Unlock()
+
+	return len(added)
+}
+
+// ObserveSymbol counts the symbol as seen in traffic, if it has no token.
+func ObserveSymbol(s Symbol) {
+	if s.IsNil() {
+		return
+	}
+	if _, ok := LookupSymbolToken(s); ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	observedSymbolsMu.Lock()
+	defer observedSymbolsMu.Unlock()
+	o, ok := observedSymbols[s]
+	if !ok {
+		if len(observedSymbols) >= maxObservedSymbols {
+			return
+		}
+		o = &ObservedSymbol{Symbol: s, Hash: s.HexString(), FirstSeen: now}
+		observedSymbols[s] = o
+	}
+	o.Count++
+	o.LastSeen = now
+}
+
+// LocalObservedSymbols returns the unknown symbols seen in traffic on this node.
+func LocalObservedSymbols() []ObservedSymbol {
+	observedSymbolsMu.Lock()
+	symbols := make([]ObservedSymbol, 0, len(observedSymbols))
+	for _, o := range observedSymbols {
+		symbols = append(symbols, *o)
+	}
+	observedSymbolsMu.Unlock()
+	sortObservedSymbols(symbols)
+	return symbols
+}
+
+// SetPeerObservedSymbols replaces the unknown symbols seen by the other nodes.
+func SetPeerObservedSymbols(symbols []ObservedSymbol) {
+	peers := make(map[Symbol]ObservedSymbol, len(symbols))
+	for _, o := range symbols {
+		o.Symbol = ToSymbol(o.Hash)
+		if o.Symbol.IsNil() {
+			continue
+		}
+		peers[o.Symbol] = mergeObservedSymbol(peers[o.Symbol], o)
+	}
+	peerObservedSymbols.Store(&peers)
+}
+
+// ObservedSymbols returns the unknown symbols seen in traffic on this node and the other nodes, most seen first.
+func ObservedSymbols() []ObservedSymbol {
+	merged := make(map[Symbol]ObservedSymbol)
+	if peers := peerObservedSymbols.Load(); peers != nil {
+		for s, o := range *peers {
+			merged[s] = o
+		}
+	}
+	observedSymbolsMu.Lock()
+	for s, o := range observedSymbols {
+		merged[s] = mergeObservedSymbol(merged[s], *o)
+	}
+	observedSymbolsMu.Unlock()
+
+	symbols := make([]ObservedSymbol, 0, len(merged))
+	for s, o := range merged {
+		// Peers may still list symbols resolved since.
+		if _, ok := LookupSymbolToken(s); !ok {
+			symbols = append(symbols, o)
+		}
+	}
+	sortObservedSymbols(symbols)
+	return symbols
+}
+
+func mergeObservedSymbol(a, b ObservedSymbol) ObservedSymbol {
+	if a.Count == 0 {
+		return b
+	}
+	a.Count += b.Count
+	if b.FirstSeen.Before(a.FirstSeen) {
+		a.FirstSeen = b.FirstSeen
+	}
+	if b.LastSeen.After(a.LastSeen) {
+		a.LastSeen = b.LastSeen
+	}
+	return a
+}
+
+func sortObservedSymbols(symbols []ObservedSymbol) {
+	slices.SortFunc(symbols, func(a, b ObservedSymbol) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Hash, b.Hash)
+	})
+}
+
+// isObservedSymbol reports whether the symbol was seen in traffic on any node.
+func isObservedSymbol(s Symbol) bool {
+	if peers := peerObservedSymbols.Load(); peers != nil {
+		if _, ok := (*peers)[s]; ok {
+			return true
+		}
+	}
+	observedSymbolsMu.Lock()
+	defer observedSymbolsMu.Unlock()
+	_, ok := observedSymbols[s]
+	return ok
+}
+
+// ResolveSymbolCandidates hashes the candidates, and adds those that resolve an unknown symbol seen
+// in traffic, on any node, to the runtime dictionary. It returns the accepted candidates.
+func ResolveSymbolCandidates(candidates []string) []SymbolInfo {
+	accepted := make([]SymbolInfo, 0)
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		s := Symbol(HashString(candidate, hashLookupArray))
+		if !isObservedSymbol(s) {
+			continue
+		}
+		if AddSymbolTokens(candidate) > 0 {
+			accepted = append(accepted, LookupSymbol(s))
+		}
+	}
+	return accepted
+}
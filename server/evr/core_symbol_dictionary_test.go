@@ -0,0 +1,110 @@
+package evr
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestResolveSymbolCandidates(t *testing.T) {
+	token := "TestResolveSymbolCandidatesToken"
+	s := ToSymbol(token)
+
+	if accepted := ResolveSymbolCandidates([]string{token}); len(accepted) != 0 {
+		t.Fatalf("accepted a candidate that was not seen in traffic: %v", accepted)
+	}
+
+	ObserveSymbol(s)
+	ObserveSymbol(s)
+
+	var observed *ObservedSymbol
+	for _, o := range ObservedSymbols() {
+		if o.Symbol == s {
+			observed = &o
+		}
+	}
+	if observed == nil || observed.Count != 2 {
+		t.Fatalf("expected the symbol to be observed twice, got %+v", observed)
+	}
+
+	accepted := ResolveSymbolCandidates([]string{"not the token", token})
+	if len(accepted) != 1 || accepted[0].Token != token {
+		t.Fatalf("expected %s to be accepted, got %v", token, accepted)
+	}
+	if got := s.Token(); got != SymbolToken(token) {
+		t.Errorf("Token() = %s, want %s", got, token)
+	}
+	for _, o := range ObservedSymbols() {
+		if o.Symbol == s {
+			t.Errorf("resolved symbol is still observed as unknown")
+		}
+	}
+
+	// Known symbols are not observed.
+	ObserveSymbol(s)
+	for _, o := range ObservedSymbols() {
+		if o.Symbol == s {
+			t.Errorf("known symbol was observed as unknown")
+		}
+	}
+}
+
+func TestAddSymbolTokens(t *testing.T) {
+	if n := AddSymbolTokens("TestAddSymbolTokensA", "TestAddSymbolTokensA", " ", "TestAddSymbolTokensB"); n != 2 {
+		t.Errorf("AddSymbolTokens() = %d, want 2", n)
+	}
+	if n := AddSymbolTokens("TestAddSymbolTokensA"); n != 0 {
+		t.Errorf("AddSymbolTokens() = %d for a known token, want 0", n)
+	}
+	if tok, ok := LookupSymbolToken(ToSymbol("testaddsymboltokensb")); !ok || tok != "TestAddSymbolTokensB" {
+		t.Errorf("LookupSymbolToken() = %s, %v", tok, ok)
+	}
+}
+
+func TestObservedSymbols_PayloadAndPeers(t *testing.T) {
+	level := ToSymbol("TestObservedSymbolsLevel")
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(level))
+
+	// Symbols decoded from payloads are observed.
+	var decoded Symbol
+	if err := NewEasyStream(DecodeMode, b).StreamNumber(binary.LittleEndian, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	count := func(s Symbol) int64 {
+		for _, o := range ObservedSymbols() {
+			if o.Symbol == s {
+				return o.Count
+			}
+		}
+		return 0
+	}
+	if got := count(level); got != 1 {
+		t.Fatalf("expected the payload symbol to be observed once, got %d", got)
+	}
+
+	// The observations of the other nodes add up, but aren't reported as this node's.
+	peer := ToSymbol("TestObservedSymbolsPeer")
+	now := time.Now().UTC()
+	SetPeerObservedSymbols([]ObservedSymbol{
+		{Hash: level.HexString(), Count: 2, FirstSeen: now, LastSeen: now},
+		{Hash: peer.HexString(), Count: 1, FirstSeen: now, LastSeen: now},
+	})
+	defer SetPeerObservedSymbols(nil)
+	if got := count(level); got != 3 {
+		t.Errorf("expected the counts to add up to 3, got %d", got)
+	}
+	for _, o := range LocalObservedSymbols() {
+		if o.Symbol == peer {
+			t.Errorf("peer symbol is reported as seen on this node")
+		}
+	}
+
+	// Candidates for symbols only seen by the other nodes are accepted.
+	if accepted := ResolveSymbolCandidates([]string{"TestObservedSymbolsPeer"}); len(accepted) != 1 {
+		t.Fatalf("expected the peer symbol to be resolved, got %v", accepted)
+	}
+	if got := count(peer); got != 0 {
+		t.Errorf("resolved peer symbol is still observed as unknown")
+	}
+}
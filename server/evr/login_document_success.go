@@ -64,6 +64,28 @@ func NewEULADocument(version, versionGa int, language, linkURL, text string) EUL
 	}
 }
 
+var _ = Document(&TextDocument{})
+
+// TextDocument is a document other than the EULA (e.g. news, patch notes, or the code of conduct), one page at a time.
+type TextDocument struct {
+	Type      string `json:"type"`
+	Lang      string `json:"lang"`
+	Version   int64  `json:"version"`
+	Title     string `json:"title,omitempty"`
+	Text      string `json:"text"`
+	Link      string `json:"link,omitempty"`
+	Page      int    `json:"page"`       // 1-based
+	PageCount int    `json:"page_count"` // The number of pages of the text.
+}
+
+func (d TextDocument) Symbol() Symbol {
+	return ToSymbol(d.Type)
+}
+
+func (d TextDocument) String() string {
+	return fmt.Sprintf("%T(lang=%v, type=%v, page=%d/%d)", d, d.Lang, d.Type, d.Page, d.PageCount)
+}
+
 type DocumentSuccess struct {
 	Document Document
 }
@@ -85,7 +107,7 @@ func (m *DocumentSuccess) Stream(s *EasyStream) error {
 				case 0xc8c33e483f6612b1: // eula
 					m.Document = &EULADocument{}
 				default:
-					return fmt.Errorf("unknown document type: `%s`", documentSymbol.Token())
+					m.Document = &TextDocument{}
 				}
 			}
 			return s.StreamJson(m.Document, true, ZstdCompression)
@@ -4,14 +4,27 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"time"
 
 	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
 type AlternateSearchMatch struct {
-	OtherUserID string   `json:"other_user_id"`
-	Items       []string `json:"items"`
+	OtherUserID   string            `json:"other_user_id"`
+	Items         []string          `json:"items"`
+	Signals       []AlternateSignal `json:"signals,omitempty"`        // The kind of each item
+	SeenAt        time.Time         `json:"seen_at,omitempty"`        // When both accounts had last used the items
+	ASN           int               `json:"asn,omitempty"`            // The network of the shared client IP
+	SharedNetwork bool              `json:"shared_network,omitempty"` // The shared client IP is a VPN, or carrier-grade NAT
+}
+
+// Signal returns the kind of the i-th item. Matches stored before the signals were recorded are classified by the item.
+func (m *AlternateSearchMatch) Signal(i int) AlternateSignal {
+	if i < len(m.Signals) {
+		return m.Signals[i]
+	}
+	return alternateSignalOf(m.Items[i])
 }
 
 func LoginAlternateSearch(ctx context.Context, nk runtime.NakamaModule, loginHistory *LoginHistory, skipSelf bool) ([]*AlternateSearchMatch, map[string]*LoginHistory, error) {
@@ -133,8 +146,10 @@ func loginHistoryCompare(a, b *LoginHistory) []*AlternateSearchMatch {
 
 	// Collect the authUserData from both histories.
 	authUserData := make([][][]string, 2)
+	entries := make([][]*LoginHistoryEntry, 2)
 	for i, h := range []map[string]*LoginHistoryEntry{a.History, b.History} {
 		authUserData[i] = make([][]string, 0, len(h))
+		entries[i] = make([]*LoginHistoryEntry, 0, len(h))
 		for _, e := range h {
 			items := []string{
 				e.XPID.String(),
@@ -143,24 +158,38 @@ func loginHistoryCompare(a, b *LoginHistory) []*AlternateSearchMatch {
 				e.LoginData.HMDSerialNumber,
 			}
 			authUserData[i] = append(authUserData[i], items)
+			entries[i] = append(entries[i], e)
 		}
 	}
 	// Compare the entries from both histories.
-	for _, itemsA := range authUserData[0] {
-		for _, itemsB := range authUserData[1] {
+	for j, itemsA := range authUserData[0] {
+		for k, itemsB := range authUserData[1] {
 			matchingItems := make([]string, 0, len(itemsA))
+			signals := make([]AlternateSignal, 0, len(itemsA))
 			for i, item := range itemsA {
 				if item == itemsB[i] && item != "" {
 					// The items match.
 					matchingItems = append(matchingItems, item)
+					signals = append(signals, alternateCompareSignals[i])
 				}
 			}
 			// If there are matching items, create a match entry.
 			if len(matchingItems) > 0 {
-				matches = append(matches, &AlternateSearchMatch{
+				entryA, entryB := entries[0][j], entries[1][k]
+				m := &AlternateSearchMatch{
 					OtherUserID: b.userID,
 					Items:       matchingItems,
-				})
+					Signals:     signals,
+					SeenAt:      entryA.UpdatedAt,
+				}
+				if entryB.UpdatedAt.Before(m.SeenAt) {
+					m.SeenAt = entryB.UpdatedAt
+				}
+				if entryA.ClientIP == entryB.ClientIP {
+					m.ASN = max(entryA.ASN, entryB.ASN)
+					m.SharedNetwork = entryA.IsVPN || entryB.IsVPN || entryA.CarrierNAT || entryB.CarrierNAT
+				}
+				matches = append(matches, m)
 			}
 		}
 	}
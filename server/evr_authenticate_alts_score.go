@@ -0,0 +1,777 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AlternateReviewCollection   = "AlternateReviews"
+	AlternateReviewIndex        = "index_alternate_reviews"
+	AlternateFeedbackCollection = "AlternateFeedback"
+
+	alternateMaxItemWeight     = 0.99
+	alternateMaxFeedbackBoost  = 1.5 // Keeps a confirmed client IP (0.5) under the default enforce threshold
+	alternateWriteAttempts     = 5
+	alternateScoreUpdateMargin = 0.01 // A pending review is rewritten when its score moves more than this
+)
+
+// AlternateSignal is the kind of item that two accounts have in common.
+type AlternateSignal string
+
+const (
+	AlternateSignalXPID          AlternateSignal = "xpid"
+	AlternateSignalClientIP      AlternateSignal = "client_ip"
+	AlternateSignalSystemProfile AlternateSignal = "system_profile"
+	AlternateSignalHMDSerial     AlternateSignal = "hmd_serial"
+)
+
+// alternateCompareSignals are the signals of the items that loginHistoryCompare compares, in order.
+var alternateCompareSignals = [...]AlternateSignal{
+	AlternateSignalXPID,
+	AlternateSignalClientIP,
+	AlternateSignalSystemProfile,
+	AlternateSignalHMDSerial,
+}
+
+var defaultAlternateSignalWeights = map[AlternateSignal]float64{
+	AlternateSignalXPID:          0.95,
+	AlternateSignalHMDSerial:     0.9,
+	AlternateSignalClientIP:      0.5,
+	AlternateSignalSystemProfile: 0.15, // Most headsets of a model report the same system profile.
+}
+
+// carrierGradeNATASNs are mobile networks that put many subscribers behind each public address.
+var carrierGradeNATASNs = []int{
+	20057, // AT&T Mobility
+	21928, // T-Mobile USA
+	22394, // Verizon Wireless
+}
+
+// carrierGradeNATNetworkNames are the ISP and organization names of mobile networks whose ASN is shared with other
+// services. They are matched exactly, ignoring case.
+var carrierGradeNATNetworkNames = []string{
+	"AT&T Mobility LLC",
+	"T-Mobile USA, Inc.",
+	"Verizon Wireless",
+}
+
+// carrierGradeNATPrefix is the shared address space of RFC 6598.
+var carrierGradeNATPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// alternateSignalOf classifies an item of a match that was stored without its signals.
+func alternateSignalOf(item string) AlternateSignal {
+	switch {
+	case net.ParseIP(item) != nil:
+		return AlternateSignalClientIP
+	case strings.Contains(item, "::"):
+		return AlternateSignalSystemProfile
+	}
+	if xpid, err := evr.ParseEvrId(item); err == nil && xpid.PlatformCode != 0 {
+		return AlternateSignalXPID
+	}
+	return AlternateSignalHMDSerial
+}
+
+// isCarrierGradeNAT reports whether the client IP is on a mobile network, whose subscribers share their public
+// addresses. The network's ASN is the best evidence; an address in the shared address space is only seen when the
+// server is inside the carrier's network.
+func isCarrierGradeNAT(clientIP string, ipInfo IPInfo) bool {
+	if addr, err := netip.ParseAddr(clientIP); err == nil && carrierGradeNATPrefix.Contains(addr.Unmap()) {
+		return true
+	}
+	if ipInfo == nil {
+		return false
+	}
+	if slices.Contains(carrierGradeNATASNs, ipInfo.ASN()) {
+		return true
+	}
+	for _, name := range []string{ipInfo.ISP(), ipInfo.Organization()} {
+		if slices.ContainsFunc(carrierGradeNATNetworkNames, func(s string) bool { return strings.EqualFold(s, name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// AlternateScoringSettings weighs the evidence that two accounts belong to the same player.
+type AlternateScoringSettings struct {
+	Weights               map[AlternateSignal]float64 `json:"weights"`                 // The confidence that one shared item of each signal gives on its own (0 to 1)
+	HalfLifeDays          float64                     `json:"half_life_days"`          // The age at which a shared item counts for half (default 90)
+	SharedNetworkDiscount float64                     `json:"shared_network_discount"` // The multiplier of client IPs on VPNs, carrier-grade NAT and the shared ASNs (default 0.2)
+	SharedASNs            []int                       `json:"shared_asns"`             // Networks with many unrelated players behind few addresses (mobile carriers, campuses)
+	ReviewThreshold       float64                     `json:"review_threshold"`        // The score that queues a link for review (default 0.6)
+	EnforceThreshold      float64                     `json:"enforce_threshold"`       // The score at which a disabled alternate counts against the player (default 0.8)
+}
+
+func (s AlternateScoringSettings) WithDefaults() AlternateScoringSettings {
+	weights := make(map[AlternateSignal]float64, len(defaultAlternateSignalWeights))
+	for signal, w := range defaultAlternateSignalWeights {
+		weights[signal] = w
+	}
+	for signal, w := range s.Weights {
+		weights[signal] = min(max(w, 0), alternateMaxItemWeight)
+	}
+	s.Weights = weights
+	if s.HalfLifeDays <= 0 {
+		s.HalfLifeDays = 90
+	}
+	if s.SharedNetworkDiscount <= 0 || s.SharedNetworkDiscount > 1 {
+		s.SharedNetworkDiscount = 0.2
+	}
+	if s.ReviewThreshold <= 0 {
+		s.ReviewThreshold = 0.6
+	}
+	if s.EnforceThreshold <= 0 {
+		s.EnforceThreshold = 0.8
+	}
+	return s
+}
+
+func AlternateScoringSettingsGet() AlternateScoringSettings {
+	var s AlternateScoringSettings
+	if settings := ServiceSettings(); settings != nil {
+		s = settings.AlternateScoring
+	}
+	return s.WithDefaults()
+}
+
+// AlternateScoreReason is one shared item, and how much it counted.
+type AlternateScoreReason struct {
+	Signal AlternateSignal `json:"signal"`
+	Item   string          `json:"item"`
+	SeenAt time.Time       `json:"seen_at,omitempty"`
+	Weight float64         `json:"weight"`          // The item's confidence, after the adjustments
+	Notes  []string        `json:"notes,omitempty"` // The adjustments that were made
+}
+
+// AlternateScore is the confidence that another account belongs to the same player, and why.
+type AlternateScore struct {
+	OtherUserID string                  `json:"other_user_id"`
+	Score       float64                 `json:"score"`             // The evidence, or 1 or 0 once a moderator has confirmed or dismissed the link
+	Evidence    float64                 `json:"evidence"`          // The combined confidence of the shared items
+	Verdict     AlternateReviewStatus   `json:"verdict,omitempty"` // The review of the link, if any
+	Reasons     []*AlternateScoreReason `json:"reasons"`           // Strongest first
+}
+
+// Score combines the items two accounts share. Each distinct item counts once, at its strongest, and the items
+// are treated as independent evidence: the score is the chance that at least one of them is a real link.
+func (s AlternateScoringSettings) Score(otherUserID string, matches []*AlternateSearchMatch, feedback *AlternateFeedback, verdict AlternateReviewStatus, now time.Time) *AlternateScore {
+	best := make(map[string]*AlternateScoreReason)
+	for _, m := range matches {
+		for i, item := range m.Items {
+			r := s.reason(m, m.Signal(i), item, feedback, now)
+			if b, found := best[item]; !found || r.Weight > b.Weight {
+				best[item] = r
+			}
+		}
+	}
+
+	score := &AlternateScore{
+		OtherUserID: otherUserID,
+		Verdict:     verdict,
+		Reasons:     make([]*AlternateScoreReason, 0, len(best)),
+	}
+	remaining := 1.0
+	for _, r := range best {
+		score.Reasons = append(score.Reasons, r)
+		remaining *= 1 - r.Weight
+	}
+	slices.SortFunc(score.Reasons, func(a, b *AlternateScoreReason) int {
+		if a.Weight != b.Weight {
+			return cmp.Compare(b.Weight, a.Weight)
+		}
+		return strings.Compare(a.Item, b.Item)
+	})
+	score.Evidence = 1 - remaining
+
+	switch verdict {
+	case AlternateReviewConfirmed:
+		score.Score = 1
+	case AlternateReviewDismissed:
+		score.Score = 0
+	default:
+		score.Score = score.Evidence
+	}
+	return score
+}
+
+func (s AlternateScoringSettings) reason(m *AlternateSearchMatch, signal AlternateSignal, item string, feedback *AlternateFeedback, now time.Time) *AlternateScoreReason {
+	r := &AlternateScoreReason{
+		Signal: signal,
+		Item:   item,
+		SeenAt: m.SeenAt,
+		Weight: s.Weights[signal],
+	}
+
+	if !m.SeenAt.IsZero() {
+		if days := now.Sub(m.SeenAt).Hours() / 24; days >= 1 {
+			r.Weight *= math.Pow(0.5, days/s.HalfLifeDays)
+			r.Notes = append(r.Notes, fmt.Sprintf("last shared %d days ago", int(days)))
+		}
+	}
+
+	if signal == AlternateSignalClientIP {
+		switch {
+		case m.SharedNetwork:
+			r.Weight *= s.SharedNetworkDiscount
+			r.Notes = append(r.Notes, "shared network (VPN or carrier-grade NAT)")
+		case m.ASN != 0 && slices.Contains(s.SharedASNs, m.ASN):
+			r.Weight *= s.SharedNetworkDiscount
+			r.Notes = append(r.Notes, fmt.Sprintf("shared network (AS%d)", m.ASN))
+		}
+	}
+
+	if v := feedback.Verdicts(item); v.Confirmed > 0 || v.Dismissed > 0 {
+		r.Weight *= v.Factor()
+		r.Notes = append(r.Notes, fmt.Sprintf("confirmed in %d, and dismissed in %d, reviews", v.Confirmed, v.Dismissed))
+	}
+
+	r.Weight = min(max(r.Weight, 0), alternateMaxItemWeight)
+	return r
+}
+
+// AlternateItemVerdicts counts the reviews that an item was part of.
+type AlternateItemVerdicts struct {
+	Confirmed int `json:"confirmed"`
+	Dismissed int `json:"dismissed"`
+}
+
+// Factor scales the item's weight; an item that keeps linking unrelated players (a venue's IP, a shared headset) counts
+// for less. The boost is capped, so that confirmations alone can't carry a weak item past the enforce threshold.
+func (v AlternateItemVerdicts) Factor() float64 {
+	return min(float64(v.Confirmed+1)/float64(v.Dismissed+1), alternateMaxFeedbackBoost)
+}
+
+// alternateFeedbackObject is the stored verdicts of one item.
+type alternateFeedbackObject struct {
+	Item string `json:"item"`
+	AlternateItemVerdicts
+}
+
+// AlternateFeedback holds the moderators' verdicts by item, so that they carry over to links that have not been reviewed.
+// Each item is stored on its own, and only the items that are scored are read.
+type AlternateFeedback struct {
+	Items    map[string]*AlternateItemVerdicts
+	versions map[string]string // By item; the items that were read, or are to be written
+}
+
+func NewAlternateFeedback() *AlternateFeedback {
+	return &AlternateFeedback{
+		Items:    make(map[string]*AlternateItemVerdicts),
+		versions: make(map[string]string),
+	}
+}
+
+// alternateFeedbackKey is the storage key of an item, which may be longer than a key, or contain any character.
+func alternateFeedbackKey(item string) string {
+	sum := sha256.Sum256([]byte(item))
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *AlternateFeedback) Verdicts(item string) AlternateItemVerdicts {
+	if f == nil || f.Items[item] == nil {
+		return AlternateItemVerdicts{}
+	}
+	return *f.Items[item]
+}
+
+// add counts (or, with a negative delta, uncounts) a verdict on the items.
+func (f *AlternateFeedback) add(items []string, verdict AlternateReviewStatus, delta int) {
+	if f.Items == nil {
+		f.Items = make(map[string]*AlternateItemVerdicts)
+	}
+	if f.versions == nil {
+		f.versions = make(map[string]string)
+	}
+	for _, item := range items {
+		v := f.Items[item]
+		if v == nil {
+			v = &AlternateItemVerdicts{}
+			f.Items[item] = v
+		}
+		switch verdict {
+		case AlternateReviewConfirmed:
+			v.Confirmed = max(v.Confirmed+delta, 0)
+		case AlternateReviewDismissed:
+			v.Dismissed = max(v.Dismissed+delta, 0)
+		}
+		if _, found := f.versions[item]; !found {
+			f.versions[item] = "*"
+		}
+		if v.Confirmed == 0 && v.Dismissed == 0 {
+			delete(f.Items, item)
+		}
+	}
+}
+
+// AlternateFeedbackLoad reads the verdicts of the items.
+func AlternateFeedbackLoad(ctx context.Context, nk runtime.NakamaModule, items []string) (*AlternateFeedback, error) {
+	feedback := NewAlternateFeedback()
+	if len(items) == 0 {
+		return feedback, nil
+	}
+	ops := make([]*runtime.StorageRead, 0, len(items))
+	for _, item := range items {
+		feedback.versions[item] = "*"
+		ops = append(ops, &runtime.StorageRead{
+			Collection: AlternateFeedbackCollection,
+			Key:        alternateFeedbackKey(item),
+			UserID:     SystemUserID,
+		})
+	}
+	objs, err := nk.StorageRead(ctx, ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alternate feedback: %w", err)
+	}
+	for _, obj := range objs {
+		o := &alternateFeedbackObject{}
+		if err := json.Unmarshal([]byte(obj.Value), o); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal alternate feedback %s: %w", obj.Key, err)
+		}
+		feedback.versions[o.Item] = obj.Version
+		if o.Confirmed > 0 || o.Dismissed > 0 {
+			feedback.Items[o.Item] = &o.AlternateItemVerdicts
+		}
+	}
+	return feedback, nil
+}
+
+// storageWrites returns the writes of the items that were added to, each conditional on the version that was read.
+func (f *AlternateFeedback) storageWrites(items ...[]string) ([]*runtime.StorageWrite, error) {
+	ops := make([]*runtime.StorageWrite, 0)
+	seen := make(map[string]bool)
+	for _, item := range slices.Concat(items...) {
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		data, err := json.Marshal(alternateFeedbackObject{Item: item, AlternateItemVerdicts: f.Verdicts(item)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal alternate feedback: %w", err)
+		}
+		ops = append(ops, &runtime.StorageWrite{
+			Collection:      AlternateFeedbackCollection,
+			Key:             alternateFeedbackKey(item),
+			UserID:          SystemUserID,
+			Value:           string(data),
+			Version:         f.versions[item],
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		})
+	}
+	return ops, nil
+}
+
+// AlternateFeedbackUpdate moves a link's verdict count from the previous items to the current ones.
+func AlternateFeedbackUpdate(ctx context.Context, nk runtime.NakamaModule, previousItems []string, previousVerdict AlternateReviewStatus, items []string, verdict AlternateReviewStatus) error {
+	var err error
+	for range alternateWriteAttempts {
+		var feedback *AlternateFeedback
+		if feedback, err = AlternateFeedbackLoad(ctx, nk, slices.Concat(previousItems, items)); err != nil {
+			continue
+		}
+		feedback.add(previousItems, previousVerdict, -1)
+		feedback.add(items, verdict, 1)
+		var ops []*runtime.StorageWrite
+		if ops, err = feedback.storageWrites(previousItems, items); err != nil {
+			return err
+		}
+		if _, err = nk.StorageWrite(ctx, ops); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// alternateMatchItems returns the distinct items of the matches.
+func alternateMatchItems(alternates map[string][]*AlternateSearchMatch) []string {
+	items := make([]string, 0)
+	for _, matches := range alternates {
+		for _, m := range matches {
+			items = append(items, m.Items...)
+		}
+	}
+	slices.Sort(items)
+	return slices.Compact(items)
+}
+
+type AlternateReviewStatus string
+
+const (
+	AlternateReviewPending   AlternateReviewStatus = "pending"
+	AlternateReviewConfirmed AlternateReviewStatus = "confirmed"
+	AlternateReviewDismissed AlternateReviewStatus = "dismissed"
+)
+
+var (
+	ErrAlternateReviewNotFound       = errors.New("alternate review not found")
+	ErrAlternateReviewInvalidVerdict = errors.New("verdict must be confirmed or dismissed")
+)
+
+// AlternateReview is a link between two accounts in the moderators' review queue. There is one for each pair of accounts.
+type AlternateReview struct {
+	UserIDs      [2]string               `json:"user_ids"` // Sorted
+	Status       AlternateReviewStatus   `json:"status"`
+	Score        float64                 `json:"score"`   // The evidence, when last updated
+	Reasons      []*AlternateScoreReason `json:"reasons"` // The evidence, when last updated
+	CreateTime   time.Time               `json:"create_time"`
+	UpdateTime   time.Time               `json:"update_time"`
+	ReviewerID   string                  `json:"reviewer_id,omitempty"`
+	ReviewTime   time.Time               `json:"review_time,omitempty"`
+	Notes        string                  `json:"notes,omitempty"`
+	Verdict      AlternateReviewStatus   `json:"verdict,omitempty"`       // The last verdict, which is kept when the link is reopened
+	VerdictItems []string                `json:"verdict_items,omitempty"` // The items that the last verdict was counted on
+	version      string
+}
+
+func NewAlternateReview(userID, otherUserID string) *AlternateReview {
+	userIDs := [2]string{userID, otherUserID}
+	if userIDs[1] < userIDs[0] {
+		userIDs[0], userIDs[1] = userIDs[1], userIDs[0]
+	}
+	return &AlternateReview{
+		UserIDs: userIDs,
+		Status:  AlternateReviewPending,
+		version: "*",
+	}
+}
+
+func alternateReviewKey(userID, otherUserID string) string {
+	if otherUserID < userID {
+		userID, otherUserID = otherUserID, userID
+	}
+	return userID + ":" + otherUserID
+}
+
+func (r *AlternateReview) StorageMeta() StorableMetadata {
+	return StorableMetadata{
+		Collection:      AlternateReviewCollection,
+		Key:             alternateReviewKey(r.UserIDs[0], r.UserIDs[1]),
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		Version:         r.version,
+	}
+}
+
+func (r *AlternateReview) SetStorageMeta(meta StorableMetadata) {
+	r.version = meta.Version
+}
+
+func (r *AlternateReview) StorageIndexes() []StorableIndexMeta {
+	return []StorableIndexMeta{{
+		Name:           AlternateReviewIndex,
+		Collection:     AlternateReviewCollection,
+		Fields:         []string{"status", "score"},
+		SortableFields: []string{"score"},
+		MaxEntries:     1000000,
+	}}
+}
+
+// OtherUserID returns the account in the pair that isn't the given one.
+func (r *AlternateReview) OtherUserID(userID string) string {
+	if r.UserIDs[0] == userID {
+		return r.UserIDs[1]
+	}
+	return r.UserIDs[0]
+}
+
+// update refreshes the evidence, and reopens a dismissed link when it has new items that would queue it on their own.
+func (r *AlternateReview) update(score *AlternateScore, settings AlternateScoringSettings, now time.Time) bool {
+	switch r.Status {
+	case AlternateReviewPending:
+		if r.version != "*" && math.Abs(r.Score-score.Evidence) < alternateScoreUpdateMargin {
+			return false
+		}
+	case AlternateReviewDismissed:
+		remaining := 1.0
+		for _, reason := range score.Reasons {
+			if !slices.Contains(r.VerdictItems, reason.Item) {
+				remaining *= 1 - reason.Weight
+			}
+		}
+		if 1-remaining < settings.ReviewThreshold {
+			return false
+		}
+		r.Status = AlternateReviewPending
+	default:
+		return false
+	}
+	if r.CreateTime.IsZero() {
+		r.CreateTime = now
+	}
+	r.UpdateTime = now
+	r.Score = score.Evidence
+	r.Reasons = score.Reasons
+	return true
+}
+
+// SetVerdict resolves the review on its current items. It returns the previous verdict, and the items it was
+// counted on, which the feedback should no longer count.
+func (r *AlternateReview) SetVerdict(verdict AlternateReviewStatus, reviewerID, notes string, now time.Time) (previous AlternateReviewStatus, previousItems []string, err error) {
+	if verdict != AlternateReviewConfirmed && verdict != AlternateReviewDismissed {
+		return "", nil, ErrAlternateReviewInvalidVerdict
+	}
+	items := make([]string, 0, len(r.Reasons))
+	for _, reason := range r.Reasons {
+		items = append(items, reason.Item)
+	}
+	previous, previousItems = r.Verdict, r.VerdictItems
+
+	r.Status = verdict
+	r.Verdict = verdict
+	r.VerdictItems = items
+	r.ReviewerID = reviewerID
+	r.ReviewTime = now
+	r.Notes = notes
+	return previous, previousItems, nil
+}
+
+// AlternateReviewsLoad reads the reviews of the links between the user and the other accounts, by the other user ID.
+func AlternateReviewsLoad(ctx context.Context, nk runtime.NakamaModule, userID string, otherUserIDs []string) (map[string]*AlternateReview, error) {
+	reviews := make(map[string]*AlternateReview, len(otherUserIDs))
+	if len(otherUserIDs) == 0 {
+		return reviews, nil
+	}
+	ops := make([]*runtime.StorageRead, 0, len(otherUserIDs))
+	for _, otherUserID := range otherUserIDs {
+		ops = append(ops, &runtime.StorageRead{
+			Collection: AlternateReviewCollection,
+			Key:        alternateReviewKey(userID, otherUserID),
+			UserID:     SystemUserID,
+		})
+	}
+	objs, err := nk.StorageRead(ctx, ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alternate reviews: %w", err)
+	}
+	for _, obj := range objs {
+		review := &AlternateReview{}
+		if err := json.Unmarshal([]byte(obj.Value), review); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal alternate review %s: %w", obj.Key, err)
+		}
+		review.version = obj.Version
+		reviews[review.OtherUserID(userID)] = review
+	}
+	return reviews, nil
+}
+
+// AlternateReviewLoad reads the review of the link between two accounts.
+func AlternateReviewLoad(ctx context.Context, nk runtime.NakamaModule, userID, otherUserID string) (*AlternateReview, error) {
+	reviews, err := AlternateReviewsLoad(ctx, nk, userID, []string{otherUserID})
+	if err != nil {
+		return nil, err
+	}
+	if review, found := reviews[otherUserID]; found {
+		return review, nil
+	}
+	return nil, ErrAlternateReviewNotFound
+}
+
+// AlternateReviewsList lists the reviews of a status, highest score first.
+func AlternateReviewsList(ctx context.Context, nk runtime.NakamaModule, status AlternateReviewStatus, limit int, cursor string) ([]*AlternateReview, string, error) {
+	query := fmt.Sprintf("+value.status:%s", Query.QuoteStringValue(string(status)))
+	result, cursor, err := nk.StorageIndexList(ctx, SystemUserID, AlternateReviewIndex, query, limit, []string{"-score"}, cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list alternate reviews: %w", err)
+	}
+	reviews := make([]*AlternateReview, 0, len(result.GetObjects()))
+	for _, obj := range result.GetObjects() {
+		review := &AlternateReview{}
+		if err := json.Unmarshal([]byte(obj.Value), review); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal alternate review %s: %w", obj.Key, err)
+		}
+		review.version = obj.Version
+		reviews = append(reviews, review)
+	}
+	return reviews, cursor, nil
+}
+
+// AlternateScoresLoad scores the user's alternates, with the moderators' verdicts, and returns the reviews that were read.
+// The scores are made without the verdicts on the items if they can't be read.
+func AlternateScoresLoad(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, alternates map[string][]*AlternateSearchMatch, settings AlternateScoringSettings, now time.Time) (map[string]*AlternateScore, map[string]*AlternateReview, error) {
+	feedback, err := AlternateFeedbackLoad(ctx, nk, alternateMatchItems(alternates))
+	if err != nil {
+		logger.WithFields(map[string]any{
+			"uid":   userID,
+			"error": err,
+		}).Warn("Failed to load alternate feedback")
+		feedback = nil
+	}
+	otherUserIDs := make([]string, 0, len(alternates))
+	for otherUserID := range alternates {
+		otherUserIDs = append(otherUserIDs, otherUserID)
+	}
+	reviews, err := AlternateReviewsLoad(ctx, nk, userID, otherUserIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores := make(map[string]*AlternateScore, len(alternates))
+	for otherUserID, matches := range alternates {
+		var verdict AlternateReviewStatus
+		if review, found := reviews[otherUserID]; found {
+			verdict = review.Status
+		}
+		scores[otherUserID] = settings.Score(otherUserID, matches, feedback, verdict, now)
+	}
+	return scores, reviews, nil
+}
+
+// AlternateScoresUpdate scores the user's alternates, and queues the links that are likely enough for review.
+func AlternateScoresUpdate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, alternates map[string][]*AlternateSearchMatch, settings AlternateScoringSettings, now time.Time) (map[string]*AlternateScore, error) {
+	scores, reviews, err := AlternateScoresLoad(ctx, logger, nk, userID, alternates, settings, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for otherUserID, score := range scores {
+		review, found := reviews[otherUserID]
+		if !found && score.Evidence < settings.ReviewThreshold {
+			continue
+		}
+		// Each review is written alone, so that a conflict on one doesn't drop the rest.
+		if err := alternateReviewQueue(ctx, nk, userID, otherUserID, review, score, settings, now); err != nil {
+			// The queue catches up on the next login of either account.
+			logger.WithFields(map[string]any{
+				"uid":       userID,
+				"other_uid": otherUserID,
+				"error":     err,
+			}).Warn("Failed to queue alternate review")
+		}
+	}
+	return scores, nil
+}
+
+// alternateReviewQueue updates the review with the score, and writes it if it changed. A review that was written
+// by another login of either account in the meantime is read again, and updated in turn. The review is nil if
+// there isn't one yet.
+func alternateReviewQueue(ctx context.Context, nk runtime.NakamaModule, userID, otherUserID string, review *AlternateReview, score *AlternateScore, settings AlternateScoringSettings, now time.Time) error {
+	var err error
+	for range alternateWriteAttempts {
+		if review == nil {
+			review = NewAlternateReview(userID, otherUserID)
+		}
+		if !review.update(score, settings, now) {
+			return nil
+		}
+		if review.Status == AlternateReviewPending {
+			score.Verdict = AlternateReviewPending
+			score.Score = score.Evidence
+		}
+		data, marshalErr := json.Marshal(review)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to marshal alternate review: %w", marshalErr)
+		}
+		meta := review.StorageMeta()
+		if _, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+			Collection:      meta.Collection,
+			Key:             meta.Key,
+			UserID:          SystemUserID,
+			Value:           string(data),
+			Version:         meta.Version,
+			PermissionRead:  meta.PermissionRead,
+			PermissionWrite: meta.PermissionWrite,
+		}}); err == nil {
+			return nil
+		} else if !isStorageVersionConflict(err) {
+			return fmt.Errorf("failed to write alternate review: %w", err)
+		}
+		var loadErr error
+		if review, loadErr = AlternateReviewLoad(ctx, nk, userID, otherUserID); errors.Is(loadErr, ErrAlternateReviewNotFound) {
+			review = nil
+		} else if loadErr != nil {
+			return loadErr
+		}
+	}
+	return fmt.Errorf("failed to write alternate review: %w", err)
+}
+
+// AlternateReviewResolve records a moderator's verdict on the link between two accounts. A link that isn't in the
+// queue is reviewed as it is scored now.
+func AlternateReviewResolve(ctx context.Context, nk runtime.NakamaModule, userID, otherUserID string, verdict AlternateReviewStatus, reviewerID, notes string, now time.Time) (*AlternateReview, error) {
+	if verdict != AlternateReviewConfirmed && verdict != AlternateReviewDismissed {
+		return nil, ErrAlternateReviewInvalidVerdict
+	}
+
+	review, err := AlternateReviewLoad(ctx, nk, userID, otherUserID)
+	if errors.Is(err, ErrAlternateReviewNotFound) {
+		history := NewLoginHistory(userID)
+		if err := StorableRead(ctx, nk, userID, history, false); err != nil {
+			return nil, fmt.Errorf("failed to read login history: %w", err)
+		}
+		matches, found := history.AlternateMatches[otherUserID]
+		if !found {
+			return nil, ErrAlternateReviewNotFound
+		}
+		settings := AlternateScoringSettingsGet()
+		feedback, err := AlternateFeedbackLoad(ctx, nk, alternateMatchItems(map[string][]*AlternateSearchMatch{otherUserID: matches}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load alternate feedback: %w", err)
+		}
+		review = NewAlternateReview(userID, otherUserID)
+		review.update(settings.Score(otherUserID, matches, feedback, "", now), settings, now)
+	} else if err != nil {
+		return nil, err
+	}
+
+	// The review is written first, so that a conflicting verdict doesn't count in the feedback.
+	previousVerdict, previousItems, err := review.SetVerdict(verdict, reviewerID, notes, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := StorableWrite(ctx, nk, SystemUserID, review); err != nil {
+		return nil, fmt.Errorf("failed to write alternate review: %w", err)
+	}
+
+	if err := AlternateFeedbackUpdate(ctx, nk, previousItems, previousVerdict, review.VerdictItems, verdict); err != nil {
+		return review, fmt.Errorf("failed to write alternate feedback: %w", err)
+	}
+	if err := alternateScoresSetVerdict(ctx, nk, review); err != nil {
+		return review, fmt.Errorf("failed to update alternate scores: %w", err)
+	}
+	return review, nil
+}
+
+// alternateScoresSetVerdict applies a verdict to the stored scores of both accounts, until their next logins rescore them.
+func alternateScoresSetVerdict(ctx context.Context, nk runtime.NakamaModule, review *AlternateReview) error {
+	for _, userID := range review.UserIDs {
+		history := NewLoginHistory(userID)
+		if err := StorableRead(ctx, nk, userID, history, false); status.Code(err) == codes.NotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		score, found := history.AlternateScores[review.OtherUserID(userID)]
+		if !found {
+			continue
+		}
+		score.Verdict = review.Status
+		switch review.Status {
+		case AlternateReviewConfirmed:
+			score.Score = 1
+		case AlternateReviewDismissed:
+			score.Score = 0
+		}
+		if err := StorableWrite(ctx, nk, userID, history); err != nil {
+			return err
+		}
+	}
+	return nil
+}
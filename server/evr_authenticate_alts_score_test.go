@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlternateSignalOf(t *testing.T) {
+	assert.Equal(t, AlternateSignalClientIP, alternateSignalOf("203.0.113.7"))
+	assert.Equal(t, AlternateSignalXPID, alternateSignalOf("OVR-ORG-3963667097037078"))
+	assert.Equal(t, AlternateSignalSystemProfile, alternateSignalOf("Quest 2::wifi::Adreno 650::Snapdragon::8::8::6000::0"))
+	assert.Equal(t, AlternateSignalHMDSerial, alternateSignalOf("1WMHH812345678"))
+
+	// Matches with their signals don't need classifying.
+	m := &AlternateSearchMatch{Items: []string{"1WMHH812345678"}, Signals: []AlternateSignal{AlternateSignalXPID}}
+	assert.Equal(t, AlternateSignalXPID, m.Signal(0))
+
+}
+
+type testCarrierIPInfo struct {
+	StubIPInfo
+	asn               int
+	isp, organization string
+}
+
+func (r testCarrierIPInfo) ASN() int             { return r.asn }
+func (r testCarrierIPInfo) ISP() string          { return r.isp }
+func (r testCarrierIPInfo) Organization() string { return r.organization }
+
+func TestIsCarrierGradeNAT(t *testing.T) {
+	assert.False(t, isCarrierGradeNAT("203.0.113.7", nil))
+	assert.False(t, isCarrierGradeNAT("203.0.113.7", testCarrierIPInfo{asn: 7922, isp: "Comcast Cable Communications", organization: "Comcast IP Services"}))
+	assert.True(t, isCarrierGradeNAT("203.0.113.7", testCarrierIPInfo{asn: 21928}))
+	assert.True(t, isCarrierGradeNAT("203.0.113.7", testCarrierIPInfo{isp: "verizon wireless"}))
+	assert.True(t, isCarrierGradeNAT("100.72.1.2", nil))
+	assert.True(t, isCarrierGradeNAT("::ffff:100.64.0.1", nil))
+	assert.False(t, isCarrierGradeNAT("100.128.0.1", nil))
+
+	// Names are matched exactly, so that fixed-line networks that mention a wireless service aren't caught.
+	assert.False(t, isCarrierGradeNAT("203.0.113.7", testCarrierIPInfo{organization: "Wireless Broadband Alliance Hotspots"}))
+}
+
+// testReviewStorageNakamaModule stores the objects in memory, checking their versions as the storage engine does.
+type testReviewStorageNakamaModule struct {
+	runtime.NakamaModule
+	objects map[string]*api.StorageObject
+	writes  int
+}
+
+func (m *testReviewStorageNakamaModule) StorageRead(ctx context.Context, reads []*runtime.StorageRead) ([]*api.StorageObject, error) {
+	objs := make([]*api.StorageObject, 0, len(reads))
+	for _, r := range reads {
+		if obj, ok := m.objects[r.Collection+"/"+r.Key]; ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+func (m *testReviewStorageNakamaModule) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	for _, w := range writes {
+		current, ok := m.objects[w.Collection+"/"+w.Key]
+		if w.Version == "*" && ok || w.Version != "" && w.Version != "*" && (!ok || current.Version != w.Version) {
+			return nil, runtime.ErrStorageRejectedVersion
+		}
+	}
+	for _, w := range writes {
+		m.writes++
+		m.objects[w.Collection+"/"+w.Key] = &api.StorageObject{Collection: w.Collection, Key: w.Key, Value: w.Value, Version: time.Now().String() + w.Key}
+	}
+	return nil, nil
+}
+
+func TestAlternateReviewQueue_Conflict(t *testing.T) {
+	now := time.Now().UTC()
+	settings := AlternateScoringSettings{}.WithDefaults()
+	nk := &testReviewStorageNakamaModule{objects: make(map[string]*api.StorageObject)}
+
+	// Another login queued the review after this one read that there was none.
+	other := NewAlternateReview("user1", "user2")
+	other.update(&AlternateScore{OtherUserID: "user2", Evidence: 0.7}, settings, now)
+	data, err := json.Marshal(other)
+	require.NoError(t, err)
+	meta := other.StorageMeta()
+	nk.objects[meta.Collection+"/"+meta.Key] = &api.StorageObject{Collection: meta.Collection, Key: meta.Key, Value: string(data), Version: "v1"}
+
+	score := &AlternateScore{OtherUserID: "user2", Evidence: 0.9}
+	require.NoError(t, alternateReviewQueue(context.Background(), nk, "user1", "user2", nil, score, settings, now))
+
+	review, err := AlternateReviewLoad(context.Background(), nk, "user1", "user2")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, review.Score)
+	assert.Equal(t, 1, nk.writes)
+	assert.Equal(t, AlternateReviewPending, score.Verdict)
+}
+
+func TestLoginHistoryCompare_Signals(t *testing.T) {
+	now := time.Now().UTC()
+	xpid := evr.EvrId{PlatformCode: evr.OVR_ORG, AccountId: 1}
+	entry := func(xpid evr.EvrId, ip, serial string, seen time.Time) *LoginHistoryEntry {
+		return &LoginHistoryEntry{UpdatedAt: seen, XPID: xpid, ClientIP: ip, LoginData: &evr.LoginProfile{HMDSerialNumber: serial}}
+	}
+	a := NewLoginHistory("a")
+	a.History = map[string]*LoginHistoryEntry{"1": entry(xpid, "100.64.0.9", "SERIAL", now)}
+	b := NewLoginHistory("b")
+	b.History = map[string]*LoginHistoryEntry{"1": entry(evr.EvrId{PlatformCode: evr.OVR_ORG, AccountId: 2}, "100.64.0.9", "SERIAL", now.Add(-time.Hour))}
+	b.History["1"].CarrierNAT = true
+
+	matches := loginHistoryCompare(a, b)
+	require.Len(t, matches, 1)
+	m := matches[0]
+	assert.Equal(t, "b", m.OtherUserID)
+	assert.Equal(t, []AlternateSignal{AlternateSignalClientIP, AlternateSignalSystemProfile, AlternateSignalHMDSerial}, m.Signals)
+	assert.Equal(t, now.Add(-time.Hour), m.SeenAt, "the items were last shared when the older entry was last used")
+	assert.True(t, m.SharedNetwork)
+}
+
+func TestAlternateScoringSettings_Score(t *testing.T) {
+	s := AlternateScoringSettings{SharedASNs: []int{21928}}.WithDefaults()
+	now := time.Now().UTC()
+
+	matches := []*AlternateSearchMatch{
+		{Items: []string{"203.0.113.7"}, Signals: []AlternateSignal{AlternateSignalClientIP}, SeenAt: now.AddDate(0, 0, -90)},
+		{Items: []string{"203.0.113.7", "SERIAL"}, Signals: []AlternateSignal{AlternateSignalClientIP, AlternateSignalHMDSerial}, SeenAt: now},
+	}
+	score := s.Score("b", matches, nil, "", now)
+	require.Len(t, score.Reasons, 2, "each item counts once")
+	assert.Equal(t, "SERIAL", score.Reasons[0].Item)
+	assert.InDelta(t, 0.5, score.Reasons[1].Weight, 1e-9, "the IP counts at its freshest")
+	assert.InDelta(t, 1-(1-0.9)*(1-0.5), score.Evidence, 1e-9)
+	assert.Equal(t, score.Evidence, score.Score)
+
+	// Old items decay.
+	old := s.Score("b", matches[:1], nil, "", now)
+	assert.InDelta(t, 0.25, old.Evidence, 1e-9)
+	assert.Equal(t, []string{"last shared 90 days ago"}, old.Reasons[0].Notes)
+
+	// Shared networks are discounted.
+	shared := []*AlternateSearchMatch{{Items: []string{"203.0.113.7"}, Signals: []AlternateSignal{AlternateSignalClientIP}, ASN: 21928, SeenAt: now}}
+	assert.InDelta(t, 0.1, s.Score("b", shared, nil, "", now).Evidence, 1e-9)
+	shared[0].ASN, shared[0].SharedNetwork = 0, true
+	assert.InDelta(t, 0.1, s.Score("b", shared, nil, "", now).Evidence, 1e-9)
+
+	// Verdicts on the items weigh them, and the verdict on the link overrides the evidence.
+	feedback := NewAlternateFeedback()
+	feedback.add([]string{"SERIAL"}, AlternateReviewDismissed, 2)
+	score = s.Score("b", matches[1:], feedback, "", now)
+	assert.InDelta(t, 0.3, score.Reasons[1].Weight, 1e-9)
+	assert.Equal(t, "SERIAL", score.Reasons[1].Item)
+
+	// Confirmations boost an item, but not past the enforce threshold on their own.
+	feedback = NewAlternateFeedback()
+	feedback.add([]string{"203.0.113.7"}, AlternateReviewConfirmed, 1)
+	ip := []*AlternateSearchMatch{{Items: []string{"203.0.113.7"}, Signals: []AlternateSignal{AlternateSignalClientIP}, SeenAt: now}}
+	assert.Less(t, s.Score("b", ip, feedback, "", now).Evidence, s.EnforceThreshold)
+	feedback.add([]string{"203.0.113.7"}, AlternateReviewConfirmed, 5)
+	assert.InDelta(t, 0.5*alternateMaxFeedbackBoost, s.Score("b", ip, feedback, "", now).Evidence, 1e-9)
+	assert.Equal(t, AlternateReviewConfirmed, s.Score("b", matches, nil, AlternateReviewConfirmed, now).Verdict)
+	assert.Equal(t, 1.0, s.Score("b", matches, nil, AlternateReviewConfirmed, now).Score)
+	assert.Equal(t, 0.0, s.Score("b", matches, nil, AlternateReviewDismissed, now).Score)
+}
+
+func TestAlternateReview_Verdicts(t *testing.T) {
+	s := AlternateScoringSettings{}.WithDefaults()
+	now := time.Now().UTC()
+
+	review := NewAlternateReview("b", "a")
+	assert.Equal(t, [2]string{"a", "b"}, review.UserIDs)
+	assert.Equal(t, "a:b", review.StorageMeta().Key)
+	assert.Equal(t, "a", review.OtherUserID("b"))
+
+	ip := []*AlternateSearchMatch{{Items: []string{"203.0.113.7"}, Signals: []AlternateSignal{AlternateSignalClientIP}, SeenAt: now}}
+	require.True(t, review.update(s.Score("a", ip, nil, "", now), s, now))
+	assert.Equal(t, now, review.CreateTime)
+
+	feedback := NewAlternateFeedback()
+	previous, previousItems, err := review.SetVerdict(AlternateReviewDismissed, "mod", "siblings", now)
+	require.NoError(t, err)
+	feedback.add(previousItems, previous, -1)
+	feedback.add(review.VerdictItems, review.Verdict, 1)
+	assert.Equal(t, AlternateItemVerdicts{Dismissed: 1}, feedback.Verdicts("203.0.113.7"))
+
+	// The same evidence doesn't reopen a dismissed link, but a new strong item does.
+	assert.False(t, review.update(s.Score("a", ip, feedback, "", now), s, now))
+	serial := append(ip, &AlternateSearchMatch{Items: []string{"SERIAL"}, Signals: []AlternateSignal{AlternateSignalHMDSerial}, SeenAt: now})
+	require.True(t, review.update(s.Score("a", serial, feedback, "", now), s, now))
+	assert.Equal(t, AlternateReviewPending, review.Status)
+
+	// Changing the verdict moves its count.
+	previous, previousItems, err = review.SetVerdict(AlternateReviewConfirmed, "mod", "", now)
+	require.NoError(t, err)
+	feedback.add(previousItems, previous, -1)
+	feedback.add(review.VerdictItems, review.Verdict, 1)
+	assert.Equal(t, AlternateItemVerdicts{Confirmed: 1}, feedback.Verdicts("203.0.113.7"))
+	assert.Equal(t, AlternateItemVerdicts{Confirmed: 1}, feedback.Verdicts("SERIAL"))
+
+	// Each changed item is written on its own key, including the ones whose count dropped to nothing.
+	ops, err := feedback.storageWrites(previousItems, review.VerdictItems)
+	require.NoError(t, err)
+	require.Len(t, ops, 2)
+	for _, op := range ops {
+		assert.Equal(t, AlternateFeedbackCollection, op.Collection)
+		assert.Len(t, op.Key, 64)
+		assert.Equal(t, "*", op.Version)
+	}
+	assert.NotEqual(t, ops[0].Key, ops[1].Key)
+
+	_, _, err = review.SetVerdict(AlternateReviewPending, "mod", "", now)
+	assert.ErrorIs(t, err, ErrAlternateReviewInvalidVerdict)
+}
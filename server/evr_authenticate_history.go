@@ -56,11 +56,14 @@ func matchIgnoredAltPattern(pattern string) bool {
 }
 
 type LoginHistoryEntry struct {
-	CreatedAt time.Time         `json:"create_time"`
-	UpdatedAt time.Time         `json:"update_time"`
-	XPID      evr.EvrId         `json:"xpi"`
-	ClientIP  string            `json:"client_ip"`
-	LoginData *evr.LoginProfile `json:"login_data"`
+	CreatedAt  time.Time         `json:"create_time"`
+	UpdatedAt  time.Time         `json:"update_time"`
+	XPID       evr.EvrId         `json:"xpi"`
+	ClientIP   string            `json:"client_ip"`
+	LoginData  *evr.LoginProfile `json:"login_data"`
+	ASN        int               `json:"asn,omitempty"`         // The network of the client IP, when it is known
+	IsVPN      bool              `json:"is_vpn,omitempty"`      // The client IP is a VPN or proxy
+	CarrierNAT bool              `json:"carrier_nat,omitempty"` // The client IP is on a mobile network, behind carrier-grade NAT
 }
 
 func (e *LoginHistoryEntry) Key() string {
@@ -108,6 +111,7 @@ type LoginHistory struct {
 	PendingAuthorizations    map[string]*LoginHistoryEntry      `json:"pending_authorizations"`     // map[XPID:ClientIP]LoginHistoryEntry
 	SecondDegreeAlternates   []string                           `json:"second_degree"`              // []userID
 	AlternateMatches         map[string][]*AlternateSearchMatch `json:"alternate_accounts"`         // map of alternate user IDs and what they have in common
+	AlternateScores          map[string]*AlternateScore         `json:"alternate_scores,omitempty"` // map of alternate user IDs and how likely they are the same player
 	GroupNotifications       map[string]map[string]time.Time    `json:"notified_groups"`            // list of groups that have been notified of this alternate login
 	IgnoreDisabledAlternates bool                               `json:"ignore_disabled_alternates"` // Ignore disabled alternates
 	userID                   string                             // user ID
@@ -246,6 +250,15 @@ func (h *LoginHistory) Update(xpid evr.EvrId, ip string, loginData *evr.LoginPro
 	return isNew, allowed
 }
 
+// UpdateNetwork records what is known of the network of a login's client IP, which discounts shared networks when scoring alternates.
+func (h *LoginHistory) UpdateNetwork(xpid evr.EvrId, ip string, asn int, isVPN, carrierNAT bool) {
+	if e, found := h.History[loginHistoryEntryKey(xpid, ip)]; found {
+		e.ASN = asn
+		e.IsVPN = isVPN
+		e.CarrierNAT = carrierNAT
+	}
+}
+
 func (h *LoginHistory) update(entry *LoginHistoryEntry, active bool) {
 	if h.History == nil {
 		h.History = make(map[string]*LoginHistoryEntry)
@@ -434,18 +447,28 @@ func (h *LoginHistory) UpdateAlternates(ctx context.Context, logger runtime.Logg
 		delete(h.AlternateMatches, userID)
 	}
 
-	// Check if the player has disabled alternates
+	// Score the alternates, and queue the likely ones for review
+	settings := AlternateScoringSettingsGet()
+	if h.AlternateScores, err = AlternateScoresUpdate(ctx, logger, nk, h.userID, h.AlternateMatches, settings, time.Now().UTC()); err != nil {
+		return false, fmt.Errorf("error scoring alternates: %w", err)
+	}
+
+	// Check if the player has disabled alternates that are likely to be theirs
 	if !h.IgnoreDisabledAlternates {
 		userIDs := make([]string, 0, len(matches))
-		for userID := range h.AlternateMatches {
-			userIDs = append(userIDs, userID)
+		for userID, score := range h.AlternateScores {
+			if score.Score >= settings.EnforceThreshold {
+				userIDs = append(userIDs, userID)
+			}
 		}
-		if accounts, err := nk.AccountsGetId(ctx, userIDs); err != nil {
-			return false, fmt.Errorf("error getting accounts for user IDs %v: %w", userIDs, err)
-		} else {
-			for _, a := range accounts {
-				if a.GetDisableTime() != nil && !a.GetDisableTime().AsTime().IsZero() {
-					hasDisabledAlts = true
+		if len(userIDs) > 0 {
+			if accounts, err := nk.AccountsGetId(ctx, userIDs); err != nil {
+				return false, fmt.Errorf("error getting accounts for user IDs %v: %w", userIDs, err)
+			} else {
+				for _, a := range accounts {
+					if a.GetDisableTime() != nil && !a.GetDisableTime().AsTime().IsZero() {
+						hasDisabledAlts = true
+					}
 				}
 			}
 		}
@@ -497,6 +520,16 @@ func (h *LoginHistory) UpdateAlternates(ctx context.Context, logger runtime.Logg
 			// Update the alternate's matches to include current user
 			alternateHistory.AlternateMatches[h.userID] = currentUserMatches
 
+			// The score of a pair is the same from either side
+			if score, found := h.AlternateScores[alternateUserID]; found {
+				if alternateHistory.AlternateScores == nil {
+					alternateHistory.AlternateScores = make(map[string]*AlternateScore)
+				}
+				reverse := *score
+				reverse.OtherUserID = h.userID
+				alternateHistory.AlternateScores[h.userID] = &reverse
+			}
+
 			// Save the updated alternate history
 			if err := StorableWrite(ctx, nk, alternateUserID, alternateHistory); err != nil {
 				// Log warning but continue - don't fail the entire operation
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+var ErrEvrReplayDiverged = errors.New("replay diverged from the recording")
+
+const evrCommandUsage = `Usage: nakama evr <command> [arguments]
+
+Commands:
+  decode    Decode hex, raw, pcap or recording dumps into JSON messages
+  encode    Encode a JSON message into wire bytes
+  symbol    Hash strings into symbols, and look up symbols
+  unknown   Report the unknown symbols in dumps
+  replay    Parse a recording, or replay a recorded session against a server
+
+Input files default to stdin. Run "nakama evr <command> -h" for the flags.
+`
+
+// RunEvrCommand runs the "nakama evr" tools.
+func RunEvrCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, evrCommandUsage)
+		return flag.ErrHelp
+	}
+	switch args[0] {
+	case "decode":
+		return evrDecodeCommand(args[1:], stdin, stdout)
+	case "encode":
+		return evrEncodeCommand(args[1:], stdin, stdout)
+	case "symbol":
+		return evrSymbolCommand(args[1:], stdout)
+	case "unknown":
+		return evrUnknownCommand(args[1:], stdin, stdout)
+	case "replay":
+		return evrReplayCommand(args[1:], stdout)
+	default:
+		fmt.Fprint(stdout, evrCommandUsage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func evrReplayCommand(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	sessionFlag := flags.String("session", "", "Only this session ID. Required with -addr if the recording has more than one session.")
+	addr := flags.String("addr", "", "Replay the session's incoming packets against this EVR socket, e.g. ws://127.0.0.1:7350/ws?format=evr")
+	realtime := flags.Bool("realtime", false, "Keep the recorded time between packets when replaying.")
+	idle := flags.Duration("idle", 2*time.Second, "Stop waiting for responses after this long without one.")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: nakama evr replay [flags] <recording>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	var sessionID uuid.UUID
+	if *sessionFlag != "" {
+		var err error
+		if sessionID, err = uuid.FromString(*sessionFlag); err != nil {
+			return fmt.Errorf("invalid session id: %w", err)
+		}
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rr, err := NewEvrRecordingReader(f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "# %s, started %s\n", rr.Label, rr.Start.Format(time.RFC3339))
+
+	type replayPacket struct {
+		*EvrRecordedPacket
+		types []string
+	}
+	packets := make([]replayPacket, 0)
+
+	if err := ReplayEvrRecording(rr, func(p *EvrRecordedPacket, messages []evr.Message, err error) error {
+		if !sessionID.IsNil() && p.SessionID != sessionID {
+			return nil
+		}
+		packets = append(packets, replayPacket{p, evrMessageTypes(messages)})
+		if *addr == "" {
+			line := strings.Join(evrMessageTypes(messages), " ")
+			if err != nil {
+				line = "error: " + err.Error()
+			}
+			fmt.Fprintf(stdout, "%10.3fs %-3s %s %s\n", p.Time.Sub(rr.Start).Seconds(), p.Direction, p.SessionID, line)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if *addr == "" {
+		return nil
+	}
+
+	if sessionID.IsNil() {
+		sessions := rr.Sessions()
+		if len(sessions) != 1 {
+			return fmt.Errorf("the recording has %d sessions, choose one with -session", len(sessions))
+		}
+		sessionID = sessions[0]
+	}
+
+	// Wait at least as long as the longest recorded pause, so slow responses are not cut off.
+	wait := *idle
+	expected := make([]string, 0)
+	for i, p := range packets {
+		if p.Direction == EvrPacketOutgoing {
+			expected = append(expected, p.types...)
+		}
+		if *realtime && i > 0 {
+			wait = max(wait, p.Time.Sub(packets[i-1].Time)+*idle)
+		}
+	}
+
+	client, err := DialEvrReplay(*addr)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var (
+		mu       sync.Mutex
+		received = make([]string, 0)
+		done     = make(chan error, 1)
+	)
+	go func() {
+		done <- client.Receive(wait, func(data []byte) {
+			messages, err := evr.ParsePacket(data)
+			types := evrMessageTypes(messages)
+			if err != nil {
+				types = append(types, "error: "+err.Error())
+			}
+			mu.Lock()
+			received = append(received, types...)
+			mu.Unlock()
+			fmt.Fprintf(stdout, "recv %s\n", strings.Join(types, " "))
+		})
+	}()
+
+	var last time.Time
+	for _, p := range packets {
+		if p.Direction != EvrPacketIncoming {
+			continue
+		}
+		if *realtime && !last.IsZero() {
+			time.Sleep(p.Time.Sub(last))
+		}
+		last = p.Time
+		fmt.Fprintf(stdout, "send %s\n", strings.Join(p.types, " "))
+		if err := client.Send(p.Data); err != nil {
+			return fmt.Errorf("failed to send: %w", err)
+		}
+	}
+
+	if err := <-done; err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range max(len(expected), len(received)) {
+		want, got := "(none)", "(none)"
+		if i < len(expected) {
+			want = expected[i]
+		}
+		if i < len(received) {
+			got = received[i]
+		}
+		if want != got {
+			fmt.Fprintf(stdout, "diverged at response %d: recorded %s, received %s\n", i, want, got)
+			return ErrEvrReplayDiverged
+		}
+	}
+	fmt.Fprintf(stdout, "matched %d responses\n", len(received))
+	return nil
+}
+
+func evrMessageTypes(messages []evr.Message) []string {
+	types := make([]string, 0, len(messages))
+	for _, m := range messages {
+		if m != nil {
+			types = append(types, fmt.Sprintf("%T", m))
+		}
+	}
+	return types
+}
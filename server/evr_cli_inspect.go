@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+// evrDumpPacket is a packet read from a dump.
+type evrDumpPacket struct {
+	Direction string // Only known for recordings.
+	Data      []byte
+}
+
+// readEvrDump returns the packets in the dump. The format is one of auto, hex, raw, pcap or recording.
+func readEvrDump(data []byte, format string) ([]evrDumpPacket, error) {
+	if format == "auto" {
+		switch {
+		case bytes.HasPrefix(data, []byte(evrRecordingMagic)):
+			format = "recording"
+		case isPcap(data):
+			format = "pcap"
+		case isHexDump(data):
+			format = "hex"
+		default:
+			format = "raw"
+		}
+	}
+
+	switch format {
+	case "raw":
+		return []evrDumpPacket{{Data: data}}, nil
+	case "hex":
+		b, err := decodeHexDump(data)
+		if err != nil {
+			return nil, err
+		}
+		return []evrDumpPacket{{Data: b}}, nil
+	case "pcap":
+		payloads, err := pcapTCPPayloads(data)
+		if err != nil {
+			return nil, err
+		}
+		packets := make([]evrDumpPacket, 0, len(payloads))
+		for _, p := range payloads {
+			packets = append(packets, evrDumpPacket{Data: p})
+		}
+		return packets, nil
+	case "recording":
+		rr, err := NewEvrRecordingReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		packets := make([]evrDumpPacket, 0)
+		for {
+			p, err := rr.Next()
+			if err == io.EOF {
+				return packets, nil
+			} else if err != nil {
+				return nil, err
+			}
+			packets = append(packets, evrDumpPacket{Direction: p.Direction.String(), Data: p.Data})
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// isHexDump returns whether the data is only hex digits and separators.
+func isHexDump(data []byte) bool {
+	digits := 0
+	for _, c := range data {
+		switch {
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+			digits++
+		case c == ' ', c == '\t', c == '\r', c == '\n', c == ':', c == ',', c == 'x', c == 'X':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+func decodeHexDump(data []byte) ([]byte, error) {
+	s := strings.NewReplacer("0x", "", "0X", "", ",", "", ":", "", " ", "", "\t", "", "\r", "", "\n", "").Replace(string(data))
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex dump: %w", err)
+	}
+	return b, nil
+}
+
+// readEvrDumpFiles reads the packets of each file, or of stdin if there are none.
+func readEvrDumpFiles(files []string, format string, stdin io.Reader, fn func(source string, packets []evrDumpPacket) error) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, file := range files {
+		var (
+			data []byte
+			err  error
+		)
+		if file == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return err
+		}
+		packets, err := readEvrDump(data, format)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := fn(file, packets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type evrDecodedMessage struct {
+	Source    string `json:"source"`
+	Packet    int    `json:"packet"`
+	Direction string `json:"direction,omitempty"`
+	*evr.InspectedMessage
+}
+
+func evrDecodeCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	format := flags.String("format", "auto", "The input format: auto, hex, raw, pcap or recording.")
+	pretty := flags.Bool("pretty", false, "Indent the JSON output.")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: nakama evr decode [flags] [file ...]")
+		fmt.Fprintln(stdout, "Writes one JSON object per message found in the input.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	if *pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return readEvrDumpFiles(flags.Args(), *format, stdin, func(source string, packets []evrDumpPacket) error {
+		for i, p := range packets {
+			for _, m := range evr.InspectPacket(p.Data) {
+				if err := encoder.Encode(evrDecodedMessage{Source: source, Packet: i, Direction: p.Direction, InspectedMessage: m}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func evrEncodeCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("encode", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	raw := flags.Bool("raw", false, "Write the bytes instead of hex.")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: nakama evr encode [flags] <type> [json]")
+		fmt.Fprintln(stdout, "The type is the message type (e.g. LoginFailure), its symbol token, or its 0x hex symbol.")
+		fmt.Fprintln(stdout, "The JSON fields are read from stdin if they are not given.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	var data []byte
+	if flags.NArg() == 2 {
+		data = []byte(flags.Arg(1))
+	} else {
+		var err error
+		if data, err = io.ReadAll(stdin); err != nil {
+			return err
+		}
+	}
+
+	b, err := evr.EncodeMessage(flags.Arg(0), data)
+	if err != nil {
+		return err
+	}
+	if *raw {
+		_, err = stdout.Write(b)
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, hex.EncodeToString(b))
+	return err
+}
+
+type evrSymbolResult struct {
+	Input string `json:"input"`
+	evr.SymbolInfo
+}
+
+func evrSymbolCommand(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("symbol", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: nakama evr symbol <string|0x hex> ...")
+		fmt.Fprintln(stdout, "Hashes each string into its symbol; 0x prefixed 16 digit hex values are looked up as symbols.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	encoder := json.NewEncoder(stdout)
+	for _, arg := range flags.Args() {
+		if err := encoder.Encode(evrSymbolResult{Input: arg, SymbolInfo: evr.LookupSymbol(evr.ToSymbol(arg))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evrUnknownCommand(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("unknown", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	format := flags.String("format", "auto", "The input format: auto, hex, raw, pcap or recording.")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: nakama evr unknown [flags] [file ...]")
+		fmt.Fprintln(stdout, "Lists the symbols without a message type, and the messages that failed to decode.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	type unknownSymbol struct {
+		info    evr.SymbolInfo
+		count   int
+		lengths []int
+		errors  int
+	}
+	symbols := make(map[string]*unknownSymbol)
+
+	if err := readEvrDumpFiles(flags.Args(), *format, stdin, func(_ string, packets []evrDumpPacket) error {
+		for _, p := range packets {
+			for _, m := range evr.InspectPacket(p.Data) {
+				if !m.Unknown() && m.Error == "" {
+					continue
+				}
+				u, ok := symbols[m.Hash]
+				if !ok {
+					u = &unknownSymbol{info: evr.LookupSymbol(m.Symbol)}
+					symbols[m.Hash] = u
+				}
+				u.count++
+				if m.Error != "" {
+					u.errors++
+				}
+				if !slices.Contains(u.lengths, m.Length) {
+					u.lengths = append(u.lengths, m.Length)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	sorted := make([]*unknownSymbol, 0, len(symbols))
+	for _, u := range symbols {
+		slices.Sort(u.lengths)
+		sorted = append(sorted, u)
+	}
+	slices.SortFunc(sorted, func(a, b *unknownSymbol) int {
+		if a.count != b.count {
+			return b.count - a.count
+		}
+		return strings.Compare(a.info.Hash, b.info.Hash)
+	})
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SYMBOL\tCOUNT\tERRORS\tLENGTHS\tTOKEN\tTYPE")
+	for _, u := range sorted {
+		lengths := make([]string, 0, len(u.lengths))
+		for _, l := range u.lengths {
+			lengths = append(lengths, fmt.Sprint(l))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n", u.info.Hash, u.count, u.errors, strings.Join(lengths, ","), u.info.Token, u.info.Type)
+	}
+	return w.Flush()
+}
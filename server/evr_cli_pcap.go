@@ -0,0 +1,192 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrPcapInvalid = errors.New("invalid pcap capture")
+
+const (
+	pcapLinkTypeNull     = 0
+	pcapLinkTypeEthernet = 1
+	pcapLinkTypeRaw      = 101
+	pcapLinkTypeLinuxSLL = 113
+	pcapLinkTypeLoop     = 108
+	pcapLinkTypeSLL2     = 276
+)
+
+func isPcap(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(data) {
+	case 0xa1b2c3d4, 0xd4c3b2a1, 0xa1b23c4d, 0x4d3cb2a1:
+		return true
+	}
+	return false
+}
+
+// pcapTCPPayloads returns the TCP payloads of a classic pcap capture, unwrapping WebSocket frames.
+// Segments are not reassembled; messages split across segments are reported as truncated.
+func pcapTCPPayloads(data []byte) ([][]byte, error) {
+	if len(data) < 24 {
+		return nil, ErrPcapInvalid
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0xa1b2c3d4, 0xa1b23c4d:
+		order = binary.LittleEndian
+	case 0xd4c3b2a1, 0x4d3cb2a1:
+		order = binary.BigEndian
+	default:
+		if binary.BigEndian.Uint32(data) == 0x0a0d0d0a {
+			return nil, fmt.Errorf("%w: pcapng is not supported, convert it with editcap -F pcap", ErrPcapInvalid)
+		}
+		return nil, ErrPcapInvalid
+	}
+	linkType := order.Uint32(data[20:]) & 0x0fffffff
+
+	payloads := make([][]byte, 0)
+	for offset := 24; offset+16 <= len(data); {
+		capLen := int(order.Uint32(data[offset+8:]))
+		offset += 16
+		if capLen > len(data)-offset {
+			break
+		}
+		frame := data[offset : offset+capLen]
+		offset += capLen
+
+		payload := tcpPayload(linkType, frame)
+		if len(payload) == 0 {
+			continue
+		}
+		if frames, ok := websocketPayloads(payload); ok {
+			payloads = append(payloads, frames...)
+		} else {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads, nil
+}
+
+func tcpPayload(linkType uint32, frame []byte) []byte {
+	var ip []byte
+	switch linkType {
+	case pcapLinkTypeEthernet:
+		if len(frame) < 14 {
+			return nil
+		}
+		etherType, offset := binary.BigEndian.Uint16(frame[12:]), 14
+		if etherType == 0x8100 && len(frame) >= 18 { // VLAN
+			etherType, offset = binary.BigEndian.Uint16(frame[16:]), 18
+		}
+		if etherType != 0x0800 && etherType != 0x86dd {
+			return nil
+		}
+		ip = frame[offset:]
+	case pcapLinkTypeLinuxSLL:
+		if len(frame) < 16 {
+			return nil
+		}
+		ip = frame[16:]
+	case pcapLinkTypeSLL2:
+		if len(frame) < 20 {
+			return nil
+		}
+		ip = frame[20:]
+	case pcapLinkTypeNull, pcapLinkTypeLoop:
+		if len(frame) < 4 {
+			return nil
+		}
+		ip = frame[4:]
+	case pcapLinkTypeRaw:
+		ip = frame
+	default:
+		return nil
+	}
+
+	if len(ip) < 20 {
+		return nil
+	}
+	var tcp []byte
+	switch ip[0] >> 4 {
+	case 4:
+		ihl := int(ip[0]&0x0f) * 4
+		total := int(binary.BigEndian.Uint16(ip[2:]))
+		if ip[9] != 6 || ihl < 20 || total < ihl || total > len(ip) {
+			return nil
+		}
+		tcp = ip[ihl:total]
+	case 6:
+		if len(ip) < 40 || ip[6] != 6 {
+			return nil
+		}
+		tcp = ip[40:min(len(ip), 40+int(binary.BigEndian.Uint16(ip[4:])))]
+	default:
+		return nil
+	}
+
+	if len(tcp) < 20 {
+		return nil
+	}
+	dataOffset := int(tcp[12]>>4) * 4
+	if dataOffset < 20 || dataOffset > len(tcp) {
+		return nil
+	}
+	return tcp[dataOffset:]
+}
+
+// websocketPayloads returns the (unmasked) payloads of the binary WebSocket frames in data,
+// or false if data is not a sequence of complete frames.
+func websocketPayloads(data []byte) ([][]byte, bool) {
+	payloads := make([][]byte, 0, 1)
+	for len(data) > 0 {
+		if len(data) < 2 || data[0]&0x70 != 0 {
+			return nil, false
+		}
+		opcode := data[0] & 0x0f
+		masked := data[1]&0x80 != 0
+		length := uint64(data[1] & 0x7f)
+		offset := 2
+		switch length {
+		case 126:
+			if len(data) < 4 {
+				return nil, false
+			}
+			length, offset = uint64(binary.BigEndian.Uint16(data[2:])), 4
+		case 127:
+			if len(data) < 10 {
+				return nil, false
+			}
+			length, offset = binary.BigEndian.Uint64(data[2:]), 10
+		}
+		var mask []byte
+		if masked {
+			if len(data) < offset+4 {
+				return nil, false
+			}
+			mask, offset = data[offset:offset+4], offset+4
+		}
+		if length > uint64(len(data)-offset) {
+			return nil, false
+		}
+		payload := data[offset : offset+int(length)]
+		data = data[offset+int(length):]
+
+		// Only continuation and binary frames carry messages.
+		if opcode != 0x0 && opcode != 0x2 {
+			continue
+		}
+		if masked {
+			unmasked := make([]byte, len(payload))
+			for i := range payload {
+				unmasked[i] = payload[i] ^ mask[i%4]
+			}
+			payload = unmasked
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, true
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvrCommand_EncodeDecode(t *testing.T) {
+	out := &bytes.Buffer{}
+	require.NoError(t, RunEvrCommand([]string{"encode", "SNSLoginFailure", `{"StatusCode":400,"ErrorMessage":"bad"}`}, nil, out))
+	wire := strings.TrimSpace(out.String())
+
+	out.Reset()
+	require.NoError(t, RunEvrCommand([]string{"decode"}, strings.NewReader(wire), out))
+
+	decoded := struct {
+		Type    string `json:"type"`
+		Symbol  string `json:"symbol"`
+		Message struct {
+			ErrorMessage string
+		} `json:"message"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "*evr.LoginFailure", decoded.Type)
+	assert.Equal(t, "bad", decoded.Message.ErrorMessage)
+}
+
+func TestEvrCommand_Symbol(t *testing.T) {
+	out := &bytes.Buffer{}
+	require.NoError(t, RunEvrCommand([]string{"symbol", "SNSLogInRequestv2", "0xbdb41ea9e67b200a"}, nil, out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		result := evrSymbolResult{}
+		require.NoError(t, json.Unmarshal([]byte(line), &result))
+		assert.Equal(t, "0xbdb41ea9e67b200a", result.Hash)
+		assert.Equal(t, "*evr.LoginRequest", result.Type)
+	}
+}
+
+func TestEvrCommand_Unknown(t *testing.T) {
+	unknown, _ := evr.WrapBytes(evr.Symbol(0x1122334455667788), []byte{1, 2, 3})
+	known, err := evr.Marshal(evr.NewSTcpConnectionUnrequireEvent())
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, RunEvrCommand([]string{"unknown", "-format", "raw"}, bytes.NewReader(append(append(unknown, known...), unknown...)), out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"0x1122334455667788", "2", "0", "3"}, strings.Fields(lines[1]))
+}
+
+func TestReadEvrDump_Pcap(t *testing.T) {
+	packet, err := evr.Marshal(evr.NewSTcpConnectionUnrequireEvent())
+	require.NoError(t, err)
+
+	// A masked client WebSocket frame.
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x82, 0x80 | byte(len(packet))}
+	frame = append(frame, mask...)
+	for i, b := range packet {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	tcp := make([]byte, 20)
+	tcp[12] = 5 << 4
+	ip := make([]byte, 20)
+	ip[0], ip[9] = 0x45, 6
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)+len(frame)))
+	ethernet := make([]byte, 14)
+	binary.BigEndian.PutUint16(ethernet[12:], 0x0800)
+	captured := append(append(append(ethernet, ip...), tcp...), frame...)
+
+	pcap := make([]byte, 24)
+	binary.LittleEndian.PutUint32(pcap, 0xa1b2c3d4)
+	binary.LittleEndian.PutUint32(pcap[20:], pcapLinkTypeEthernet)
+	record := make([]byte, 16)
+	binary.LittleEndian.PutUint32(record[8:], uint32(len(captured)))
+	binary.LittleEndian.PutUint32(record[12:], uint32(len(captured)))
+	pcap = append(append(pcap, record...), captured...)
+
+	packets, err := readEvrDump(pcap, "auto")
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	assert.Equal(t, hex.EncodeToString(packet), hex.EncodeToString(packets[0].Data))
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	CommunityProviderDiscord = "discord"
+	CommunityProviderFile    = "file"
+)
+
+var (
+	ErrCommunityProviderNotFound = errors.New("community provider not found")
+	ErrCommunityIdentityNotFound = errors.New("user has no identity in the community provider")
+	ErrCommunityNamesUnsupported = errors.New("community provider does not manage in-game names")
+)
+
+// CommunityMember is a member of a community, as reported by the community's provider.
+type CommunityMember struct {
+	UserID  string   // The Nakama user ID
+	Roles   []string // Provider-specific role IDs; mapped to permissions by the group's GuildGroupRoles
+	Pending bool     // The member has not completed the community's membership screening
+}
+
+// CommunityProvider is the source of membership, roles and suspension inheritance for guild groups.
+// The lobby, matchmaking and enforcement code only depend on this interface; the provider
+// for a group is selected by the `provider` field of its metadata.
+type CommunityProvider interface {
+	// Name returns the provider name stored in the group metadata.
+	Name() string
+	// Member returns the user's membership in the community, ErrMemberNotFound, or
+	// ErrCommunityIdentityNotFound if the user has no identity that the provider knows.
+	Member(ctx context.Context, gg *GuildGroup, userID string) (*CommunityMember, error)
+	// SuspensionInheritance returns the group IDs that the community inherits suspensions from.
+	SuspensionInheritance(ctx context.Context, gg *GuildGroup) ([]string, error)
+}
+
+// CommunitySyncer is implemented by providers that push their communities into the guild groups,
+// instead of reacting to external events.
+type CommunitySyncer interface {
+	Sync(ctx context.Context, registry *GuildGroupRegistry) error
+}
+
+// CommunityNamer is implemented by providers whose members have a name in the community (a Discord nickname),
+// which is used as their in-game name in the group.
+type CommunityNamer interface {
+	// InGameName returns the member's name in the community.
+	InGameName(ctx context.Context, gg *GuildGroup, userID string) (string, error)
+	// SetInGameName sets the member's name in the community.
+	SetInGameName(ctx context.Context, gg *GuildGroup, userID, name string) error
+}
+
+// CommunityRoleSync updates the group's role cache (and suspended devices) from the member's roles.
+// It returns true if the group state was changed.
+func CommunityRoleSync(gg *GuildGroup, profile *EVRProfile, member *CommunityMember) bool {
+	roles := []string{}
+	if member != nil && !member.Pending {
+		roles = append(roles, member.Roles...)
+	}
+	return gg.RoleCacheUpdate(profile, roles)
+}
+
+var _ = CommunityProvider(&DiscordCommunityProvider{})
+var _ = CommunityNamer(&DiscordCommunityProvider{})
+
+// DiscordCommunityProvider sources communities from Discord guilds.
+type DiscordCommunityProvider struct {
+	integrator *DiscordIntegrator
+}
+
+func NewDiscordCommunityProvider(integrator *DiscordIntegrator) *DiscordCommunityProvider {
+	return &DiscordCommunityProvider{
+		integrator: integrator,
+	}
+}
+
+func (p *DiscordCommunityProvider) Name() string {
+	return CommunityProviderDiscord
+}
+
+func (p *DiscordCommunityProvider) Member(ctx context.Context, gg *GuildGroup, userID string) (*CommunityMember, error) {
+	discordID := p.integrator.UserIDToDiscordID(userID)
+	if discordID == "" {
+		return nil, ErrCommunityIdentityNotFound
+	}
+	member, err := p.integrator.GuildMember(gg.GuildID, discordID)
+	if err != nil {
+		return nil, err
+	} else if member == nil {
+		return nil, ErrMemberNotFound
+	}
+	return &CommunityMember{
+		UserID:  userID,
+		Roles:   member.Roles,
+		Pending: member.Pending,
+	}, nil
+}
+
+func (p *DiscordCommunityProvider) InGameName(ctx context.Context, gg *GuildGroup, userID string) (string, error) {
+	if !p.integrator.Enabled() {
+		return "", ErrDiscordDisabled
+	}
+	discordID := p.integrator.UserIDToDiscordID(userID)
+	if discordID == "" {
+		return "", ErrCommunityIdentityNotFound
+	}
+	member, err := p.integrator.GuildMember(gg.GuildID, discordID)
+	if err != nil {
+		return "", err
+	}
+	return InGameName(member), nil
+}
+
+func (p *DiscordCommunityProvider) SetInGameName(ctx context.Context, gg *GuildGroup, userID, name string) error {
+	if !p.integrator.Enabled() {
+		return ErrDiscordDisabled
+	}
+	discordID := p.integrator.UserIDToDiscordID(userID)
+	if discordID == "" {
+		return ErrCommunityIdentityNotFound
+	}
+	return p.integrator.dg.GuildMemberNickname(gg.GuildID, discordID, name)
+}
+
+// SuspensionInheritance returns the inheritance configured through the bot.
+func (p *DiscordCommunityProvider) SuspensionInheritance(ctx context.Context, gg *GuildGroup) ([]string, error) {
+	return gg.SuspensionInheritanceGroupIDs, nil
+}
+
+// CommunityProvider returns the provider that manages the guild group.
+func (r *GuildGroupRegistry) CommunityProvider(gg *GuildGroup) (CommunityProvider, error) {
+	if gg == nil {
+		return nil, fmt.Errorf("guild group is nil")
+	}
+	provider, ok := r.providers.Load(gg.ProviderName())
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCommunityProviderNotFound, gg.ProviderName())
+	}
+	return provider, nil
+}
+
+// RegisterCommunityProvider adds (or replaces) a community provider.
+func (r *GuildGroupRegistry) RegisterCommunityProvider(provider CommunityProvider) {
+	r.providers.Store(provider.Name(), provider)
+}
+
+// CommunityMember returns the user's membership in the guild group's community.
+func (r *GuildGroupRegistry) CommunityMember(ctx context.Context, gg *GuildGroup, userID string) (*CommunityMember, error) {
+	provider, err := r.CommunityProvider(gg)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Member(ctx, gg, userID)
+}
+
+// CommunityInGameName returns the user's name in the guild group's community, or ErrCommunityNamesUnsupported.
+func (r *GuildGroupRegistry) CommunityInGameName(ctx context.Context, gg *GuildGroup, userID string) (string, error) {
+	provider, err := r.CommunityProvider(gg)
+	if err != nil {
+		return "", err
+	}
+	namer, ok := provider.(CommunityNamer)
+	if !ok {
+		return "", ErrCommunityNamesUnsupported
+	}
+	return namer.InGameName(ctx, gg, userID)
+}
+
+// CommunitySetInGameName sets the user's name in the guild group's community, or returns ErrCommunityNamesUnsupported.
+func (r *GuildGroupRegistry) CommunitySetInGameName(ctx context.Context, gg *GuildGroup, userID, name string) error {
+	provider, err := r.CommunityProvider(gg)
+	if err != nil {
+		return err
+	}
+	namer, ok := provider.(CommunityNamer)
+	if !ok {
+		return ErrCommunityNamesUnsupported
+	}
+	return namer.SetInGameName(ctx, gg, userID, name)
+}
+
+// IsCommunityUnavailable reports whether the error only means that the provider can't answer for the user,
+// because it doesn't manage names, has no identity for them, or is disabled.
+func IsCommunityUnavailable(err error) bool {
+	return errors.Is(err, ErrCommunityNamesUnsupported) || errors.Is(err, ErrCommunityIdentityNotFound) || errors.Is(err, ErrDiscordDisabled)
+}
+
+// CommunityRoleSync updates the user's roles in the guild group from the group's community provider.
+// A user that isn't a member of the community loses their roles. It returns true if the group state was changed.
+func (r *GuildGroupRegistry) CommunityRoleSync(ctx context.Context, gg *GuildGroup, profile *EVRProfile) (bool, error) {
+	member, err := r.CommunityMember(ctx, gg, profile.ID())
+	if err != nil && !errors.Is(err, ErrMemberNotFound) {
+		return false, err
+	}
+	return CommunityRoleSync(gg, profile, member), nil
+}
+
+// SuspensionGroupIDs returns the group's ID, and the IDs of the groups it inherits suspensions from.
+func (r *GuildGroupRegistry) SuspensionGroupIDs(gg *GuildGroup) []string {
+	if r == nil {
+		return append([]string{gg.IDStr()}, gg.SuspensionInheritanceGroupIDs...)
+	}
+	return append([]string{gg.IDStr()}, r.suspensionInheritance(gg)...)
+}
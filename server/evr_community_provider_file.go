@@ -0,0 +1,319 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FileCommunityConfig is the on-disk format of the file community provider.
+type FileCommunityConfig struct {
+	Communities []*FileCommunity `json:"communities"`
+}
+
+type FileCommunity struct {
+	ID                     string              `json:"id"`                       // Unique community ID (stored as the guild ID)
+	Name                   string              `json:"name"`                     // The group name
+	Description            string              `json:"description"`              // The group description
+	OwnerID                string              `json:"owner_id"`                 // The Nakama user ID of the owner
+	Roles                  GuildGroupRoles     `json:"roles"`                    // Maps permissions to the role names used in Members
+	MembersOnlyMatchmaking bool                `json:"members_only_matchmaking"` // Restrict matchmaking to members only
+	SuspensionInheritance  []string            `json:"suspension_inheritance"`   // Community IDs that this community inherits suspensions from
+	Members                map[string][]string `json:"members"`                  // map[userID][]role
+}
+
+func (c *FileCommunityConfig) Community(id string) *FileCommunity {
+	for _, community := range c.Communities {
+		if community.ID == id {
+			return community
+		}
+	}
+	return nil
+}
+
+func (c *FileCommunityConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Communities))
+	for i, community := range c.Communities {
+		if community == nil || community.ID == "" {
+			return fmt.Errorf("community %d: id is required", i)
+		}
+		if community.Name == "" {
+			return fmt.Errorf("community %s: name is required", community.ID)
+		}
+		if seen[community.ID] {
+			return fmt.Errorf("community %s: duplicate id", community.ID)
+		}
+		seen[community.ID] = true
+	}
+	for _, community := range c.Communities {
+		for _, parentID := range community.SuspensionInheritance {
+			if !seen[parentID] {
+				return fmt.Errorf("community %s: unknown suspension inheritance community %s", community.ID, parentID)
+			}
+		}
+	}
+	return nil
+}
+
+var _ = CommunityProvider(&FileCommunityProvider{})
+var _ = CommunitySyncer(&FileCommunityProvider{})
+
+// FileCommunityProvider sources communities, membership and roles from a JSON file.
+// The file is reloaded, and the communities synced, when its modification time changes.
+type FileCommunityProvider struct {
+	sync.Mutex
+	logger runtime.Logger
+	nk     runtime.NakamaModule
+	db     *sql.DB
+	path   string
+
+	config        *FileCommunityConfig
+	modTime       time.Time
+	syncedModTime time.Time // The modification time of the last configuration that was synced without errors
+}
+
+func NewFileCommunityProvider(logger runtime.Logger, nk runtime.NakamaModule, db *sql.DB, path string) (*FileCommunityProvider, error) {
+	p := &FileCommunityProvider{
+		logger: logger.WithField("provider", CommunityProviderFile),
+		nk:     nk,
+		db:     db,
+		path:   path,
+	}
+	if _, err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *FileCommunityProvider) Name() string {
+	return CommunityProviderFile
+}
+
+// load returns the current configuration, reading the file again if it has been modified.
+func (p *FileCommunityProvider) load() (*FileCommunityConfig, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return p.config, fmt.Errorf("failed to stat community file: %w", err)
+	}
+	if p.config != nil && info.ModTime().Equal(p.modTime) {
+		return p.config, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return p.config, fmt.Errorf("failed to read community file: %w", err)
+	}
+	config := &FileCommunityConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return p.config, fmt.Errorf("failed to unmarshal community file: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return p.config, fmt.Errorf("invalid community file: %w", err)
+	}
+
+	p.config = config
+	p.modTime = info.ModTime()
+	return config, nil
+}
+
+func (p *FileCommunityProvider) Member(ctx context.Context, gg *GuildGroup, userID string) (*CommunityMember, error) {
+	p.Lock()
+	config := p.config
+	p.Unlock()
+
+	community := config.Community(gg.GuildID)
+	if community == nil {
+		return nil, fmt.Errorf("community %s not found", gg.GuildID)
+	}
+	roles, ok := community.Members[userID]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+	return &CommunityMember{
+		UserID: userID,
+		Roles:  roles,
+	}, nil
+}
+
+func (p *FileCommunityProvider) SuspensionInheritance(ctx context.Context, gg *GuildGroup) ([]string, error) {
+	p.Lock()
+	config := p.config
+	p.Unlock()
+
+	community := config.Community(gg.GuildID)
+	if community == nil {
+		return nil, fmt.Errorf("community %s not found", gg.GuildID)
+	}
+	groupIDs := make([]string, 0, len(community.SuspensionInheritance))
+	for _, communityID := range community.SuspensionInheritance {
+		groupID, err := GetGroupIDByGuildID(ctx, p.db, communityID)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				// Not synced yet.
+				continue
+			}
+			return nil, err
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+	return groupIDs, nil
+}
+
+// Sync creates the guild groups for the communities, and applies their membership and roles.
+func (p *FileCommunityProvider) Sync(ctx context.Context, registry *GuildGroupRegistry) error {
+	config, err := p.load()
+	if err != nil {
+		if config == nil {
+			return err
+		}
+		// Keep using the last valid configuration.
+		p.logger.WithField("error", err).Warn("Failed to reload community file")
+	}
+
+	p.Lock()
+	modTime, synced := p.modTime, p.modTime.Equal(p.syncedModTime)
+	p.Unlock()
+	if synced {
+		return nil
+	}
+
+	var errs []error
+	for _, community := range config.Communities {
+		if err := p.syncCommunity(ctx, registry, community); err != nil {
+			errs = append(errs, fmt.Errorf("community %s: %w", community.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		// Retry on the next sync.
+		return errors.Join(errs...)
+	}
+
+	p.Lock()
+	p.syncedModTime = modTime
+	p.Unlock()
+	return nil
+}
+
+func (p *FileCommunityProvider) syncCommunity(ctx context.Context, registry *GuildGroupRegistry, community *FileCommunity) error {
+	ownerID := community.OwnerID
+	if ownerID == "" {
+		ownerID = SystemUserID
+	}
+
+	groupID, err := GetGroupIDByGuildID(ctx, p.db, community.ID)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	} else if err != nil {
+		// This is a new community.
+		md := NewGuildGroupMetadata(community.ID)
+		md.Provider = CommunityProviderFile
+		metadataMap, err := md.MarshalToMap()
+		if err != nil {
+			return fmt.Errorf("error marshalling guild group metadata: %w", err)
+		}
+		group, err := p.nk.GroupCreate(ctx, ownerID, community.Name, SystemUserID, GuildGroupLangTag, community.Description, "", false, metadataMap, 100000)
+		if err != nil {
+			return fmt.Errorf("error creating group: %w", err)
+		}
+		groupID = group.Id
+		p.logger.WithFields(map[string]any{
+			"community_id": community.ID,
+			"group_id":     groupID,
+		}).Info("Created guild group for community")
+	}
+
+	gg, err := GuildGroupLoad(ctx, p.nk, groupID)
+	if err != nil {
+		return err
+	}
+
+	if gg.ProviderName() != CommunityProviderFile {
+		return fmt.Errorf("group %s is managed by the %s provider", groupID, gg.ProviderName())
+	}
+
+	gg.OwnerID = ownerID
+	gg.RoleMap = community.Roles
+	gg.EnableMembersOnlyMatchmaking = community.MembersOnlyMatchmaking
+	if gg.SuspensionInheritanceGroupIDs, err = p.SuspensionInheritance(ctx, gg); err != nil {
+		return err
+	}
+
+	// Collect the current members, to remove those that are no longer in the file.
+	currentIDs := make([]string, 0)
+	cursor := ""
+	for {
+		var users []*api.GroupUserList_GroupUser
+		if users, cursor, err = p.nk.GroupUsersList(ctx, groupID, 100, nil, cursor); err != nil {
+			return fmt.Errorf("error listing group users: %w", err)
+		}
+		for _, u := range users {
+			currentIDs = append(currentIDs, u.GetUser().GetId())
+		}
+		if cursor == "" {
+			break
+		}
+	}
+
+	addIDs := make([]string, 0, len(community.Members))
+	for userID := range community.Members {
+		if !slices.Contains(currentIDs, userID) {
+			addIDs = append(addIDs, userID)
+		}
+	}
+	removeIDs := make([]string, 0)
+	for _, userID := range currentIDs {
+		if _, ok := community.Members[userID]; !ok && userID != ownerID && userID != SystemUserID {
+			removeIDs = append(removeIDs, userID)
+		}
+	}
+
+	if len(addIDs) > 0 {
+		if err := p.nk.GroupUsersAdd(ctx, SystemUserID, groupID, addIDs); err != nil {
+			return fmt.Errorf("error adding group users: %w", err)
+		}
+	}
+	if len(removeIDs) > 0 {
+		if err := p.nk.GroupUsersKick(ctx, SystemUserID, groupID, removeIDs); err != nil {
+			return fmt.Errorf("error removing group users: %w", err)
+		}
+	}
+
+	// Update the role cache for all affected users.
+	userIDs := append(slices.Collect(maps.Keys(community.Members)), removeIDs...)
+
+	if len(userIDs) > 0 {
+		accounts, err := p.nk.AccountsGetId(ctx, userIDs)
+		if err != nil {
+			return fmt.Errorf("error getting accounts: %w", err)
+		}
+		for _, account := range accounts {
+			profile, err := BuildEVRProfileFromAccount(account)
+			if err != nil {
+				p.logger.WithField("error", err).Warn("Failed to build profile")
+				continue
+			}
+			var member *CommunityMember
+			if roles, ok := community.Members[profile.ID()]; ok {
+				member = &CommunityMember{UserID: profile.ID(), Roles: roles}
+			}
+			CommunityRoleSync(gg, profile, member)
+		}
+	}
+
+	return GuildGroupStore(ctx, p.nk, registry, gg)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCommunityConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  FileCommunityConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			config: FileCommunityConfig{Communities: []*FileCommunity{
+				{ID: "a", Name: "A"},
+				{ID: "b", Name: "B", SuspensionInheritance: []string{"a"}},
+			}},
+		},
+		{
+			name:    "missing id",
+			config:  FileCommunityConfig{Communities: []*FileCommunity{{Name: "A"}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate id",
+			config: FileCommunityConfig{Communities: []*FileCommunity{
+				{ID: "a", Name: "A"},
+				{ID: "a", Name: "B"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unknown inheritance",
+			config: FileCommunityConfig{Communities: []*FileCommunity{
+				{ID: "a", Name: "A", SuspensionInheritance: []string{"c"}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFileCommunityProvider_Member(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "communities.json")
+	data := `{
+		"communities": [{
+			"id": "staging",
+			"name": "Staging",
+			"roles": {"member": "member", "moderator": "mod", "suspended": "suspended"},
+			"members": {"user1": ["member", "mod"]}
+		}]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	p, err := NewFileCommunityProvider(NewRuntimeGoLogger(loggerForTest(t)), nil, nil, path)
+	require.NoError(t, err)
+
+	gg := &GuildGroup{GroupMetadata: GroupMetadata{GuildID: "staging", Provider: CommunityProviderFile}}
+
+	member, err := p.Member(context.Background(), gg, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member", "mod"}, member.Roles)
+
+	_, err = p.Member(context.Background(), gg, "user2")
+	assert.True(t, errors.Is(err, ErrMemberNotFound))
+
+	// Roles are applied to the group state through the role map.
+	gg.RoleMap = p.config.Community("staging").Roles
+	gg.State = &GuildGroupState{}
+	profile := &EVRProfile{account: &api.Account{User: &api.User{Id: "user1"}}}
+
+	assert.True(t, CommunityRoleSync(gg, profile, member))
+	assert.True(t, gg.IsMember("user1"))
+	assert.True(t, gg.IsEnforcer("user1"))
+	assert.False(t, gg.IsSuspended("user1", nil))
+	assert.Equal(t, []string{"member", "mod"}, member.Roles, "member roles must not be modified")
+
+	// Removing the member clears the roles.
+	assert.True(t, CommunityRoleSync(gg, profile, nil))
+	assert.False(t, gg.IsMember("user1"))
+
+	// An unchanged file isn't synced again.
+	p.syncedModTime = p.modTime
+	assert.NoError(t, p.Sync(context.Background(), nil))
+}
+
+func TestGuildGroupRegistry_CommunityProvider(t *testing.T) {
+	r := &GuildGroupRegistry{providers: &MapOf[string, CommunityProvider]{}}
+	r.RegisterCommunityProvider(NewDiscordCommunityProvider(&DiscordIntegrator{}))
+
+	provider, err := r.CommunityProvider(&GuildGroup{})
+	require.NoError(t, err)
+	assert.Equal(t, CommunityProviderDiscord, provider.Name())
+
+	_, err = r.CommunityProvider(&GuildGroup{GroupMetadata: GroupMetadata{Provider: CommunityProviderFile}})
+	assert.True(t, errors.Is(err, ErrCommunityProviderNotFound))
+
+	// In-game names come from the provider, when it manages them.
+	_, err = r.CommunityInGameName(context.Background(), &GuildGroup{}, "user1")
+	assert.True(t, IsCommunityUnavailable(err), "the Discord provider is disabled without a session")
+	r.RegisterCommunityProvider(&stubCommunityProvider{})
+	_, err = r.CommunityInGameName(context.Background(), &GuildGroup{GroupMetadata: GroupMetadata{Provider: "stub"}}, "user1")
+	assert.True(t, errors.Is(err, ErrCommunityNamesUnsupported))
+}
+
+type stubCommunityProvider struct {
+	members     map[string]*CommunityMember
+	inheritance []string
+}
+
+func (p *stubCommunityProvider) Name() string { return "stub" }
+
+func (p *stubCommunityProvider) Member(ctx context.Context, gg *GuildGroup, userID string) (*CommunityMember, error) {
+	if m, ok := p.members[userID]; ok {
+		return m, nil
+	}
+	return nil, ErrMemberNotFound
+}
+
+func (p *stubCommunityProvider) SuspensionInheritance(ctx context.Context, gg *GuildGroup) ([]string, error) {
+	return p.inheritance, nil
+}
+
+func TestGuildGroupRegistry_CommunityRolesAndInheritance(t *testing.T) {
+	r := &GuildGroupRegistry{ctx: context.Background(), logger: NewRuntimeGoLogger(loggerForTest(t)), providers: &MapOf[string, CommunityProvider]{}}
+	r.RegisterCommunityProvider(&stubCommunityProvider{
+		members:     map[string]*CommunityMember{"user1": {UserID: "user1", Roles: []string{"member"}}},
+		inheritance: []string{"parent"},
+	})
+	gg := &GuildGroup{
+		Group:         &api.Group{Id: "group"},
+		GroupMetadata: GroupMetadata{Provider: "stub", RoleMap: GuildGroupRoles{Member: "member"}, SuspensionInheritanceGroupIDs: []string{"stale"}},
+		State:         &GuildGroupState{},
+	}
+
+	// Inheritance comes from the provider, not the stored metadata.
+	assert.Equal(t, []string{"group", "parent"}, r.SuspensionGroupIDs(gg))
+	assert.Equal(t, []string{"group", "stale"}, (*GuildGroupRegistry)(nil).SuspensionGroupIDs(gg))
+
+	profile := &EVRProfile{account: &api.Account{User: &api.User{Id: "user1"}}}
+	updated, err := r.CommunityRoleSync(context.Background(), gg, profile)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.True(t, gg.IsMember("user1"))
+
+	// A user the provider doesn't know loses their roles.
+	gg.State.RoleCache = map[string]map[string]bool{"member": {"user2": true}}
+	updated, err = r.CommunityRoleSync(context.Background(), gg, &EVRProfile{account: &api.Account{User: &api.User{Id: "user2"}}})
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.False(t, gg.IsMember("user2"))
+}
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const (
+	ConfigResourceStorageCollection       = "ConfigResources"
+	ConfigResourceActiveStorageCollection = "ConfigResourcesActive" // The active version of each type, so that serving it doesn't read the history.
+	configResourceMaxVersions             = 20
+)
+
+var ErrConfigResourceVersionNotFound = errors.New("config resource version not found")
+
+// ConfigResourceTarget selects the clients that a variant is served to. Unset fields match everyone.
+type ConfigResourceTarget struct {
+	GroupIDs       []string        `json:"group_ids,omitempty"`        // The player's active guild group.
+	MinBuildNumber evr.BuildNumber `json:"min_build_number,omitempty"` // Inclusive.
+	MaxBuildNumber evr.BuildNumber `json:"max_build_number,omitempty"` // Inclusive.
+	DeviceTypes    []string        `json:"device_types,omitempty"`     // The normalized headset types (e.g. "Meta Quest 3").
+	RolloutPercent int             `json:"rollout_percent,omitempty"`  // 1-99 for a stable share of the players, by user ID; 0 or 100 for all.
+}
+
+// ConfigResourceVariant is a resource served to the clients that match its target.
+type ConfigResourceVariant struct {
+	Name     string               `json:"name"`
+	Target   ConfigResourceTarget `json:"target"`
+	Resource json.RawMessage      `json:"resource"`
+}
+
+// ConfigResourceSet is one version of a config type's resources. The first matching variant is served,
+// then the default; without a default, the legacy `Config:<type>` object or the built-in resource.
+type ConfigResourceSet struct {
+	Type      string                  `json:"type"`
+	Version   int                     `json:"version"`
+	Default   json.RawMessage         `json:"default,omitempty"`
+	Variants  []ConfigResourceVariant `json:"variants,omitempty"`
+	Note      string                  `json:"note,omitempty"`
+	UpdatedBy string                  `json:"updated_by,omitempty"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+// ConfigResourceHistory is the stored versions of a config type. The latest version is active.
+type ConfigResourceHistory struct {
+	Type     string               `json:"type"`
+	Versions []*ConfigResourceSet `json:"versions"` // Oldest first.
+}
+
+// ConfigResourceClient is what the targeting is matched against.
+type ConfigResourceClient struct {
+	UserID      string
+	GroupID     string
+	BuildNumber evr.BuildNumber
+	DeviceType  string
+}
+
+func NewConfigResourceClient(params *SessionParameters) ConfigResourceClient {
+	c := ConfigResourceClient{
+		UserID:      params.UserID(),
+		BuildNumber: params.BuildNumber(),
+		DeviceType:  params.DeviceType(),
+	}
+	if params.profile != nil {
+		c.GroupID = params.profile.ActiveGroupID
+	}
+	return c
+}
+
+func (t ConfigResourceTarget) Validate() error {
+	if t.RolloutPercent < 0 || t.RolloutPercent > 100 {
+		return errors.New("rollout_percent must be between 0 and 100")
+	}
+	if t.MaxBuildNumber != 0 && t.MaxBuildNumber < t.MinBuildNumber {
+		return errors.New("max_build_number is less than min_build_number")
+	}
+	return nil
+}
+
+// Matches returns true if the client is targeted. The rollout is stable per player and variant.
+func (t ConfigResourceTarget) Matches(c ConfigResourceClient, variantKey string) bool {
+	if len(t.GroupIDs) > 0 && !slices.Contains(t.GroupIDs, c.GroupID) {
+		return false
+	}
+	if t.MinBuildNumber != 0 && c.BuildNumber < t.MinBuildNumber {
+		return false
+	}
+	if t.MaxBuildNumber != 0 && c.BuildNumber > t.MaxBuildNumber {
+		return false
+	}
+	if len(t.DeviceTypes) > 0 && !slices.ContainsFunc(t.DeviceTypes, func(d string) bool { return strings.EqualFold(d, c.DeviceType) }) {
+		return false
+	}
+	if t.RolloutPercent > 0 && t.RolloutPercent < 100 {
+		if c.UserID == "" {
+			return false
+		}
+		h := fnv.New32a()
+		h.Write([]byte(variantKey + ":" + c.UserID))
+		if int(h.Sum32()%100) >= t.RolloutPercent {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the resource for the client, and the name of the variant ("default" for the default, or empty if there is none).
+func (s *ConfigResourceSet) Select(c ConfigResourceClient) (json.RawMessage, string) {
+	for _, v := range s.Variants {
+		if v.Target.Matches(c, s.Type+":"+v.Name) {
+			return v.Resource, v.Name
+		}
+	}
+	if len(s.Default) > 0 {
+		return s.Default, "default"
+	}
+	return nil, ""
+}
+
+// Validate checks the variants, and the resources against the type's schema.
+func (s *ConfigResourceSet) Validate() error {
+	if s.Type == "" {
+		return errors.New("type is required")
+	}
+	if len(s.Default) > 0 {
+		if err := ValidateConfigResource(s.Type, s.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	seen := make(map[string]bool, len(s.Variants))
+	for _, v := range s.Variants {
+		if v.Name == "" || v.Name == "default" {
+			return errors.New("each variant needs a name other than default")
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("duplicate variant %q", v.Name)
+		}
+		seen[v.Name] = true
+		if err := v.Target.Validate(); err != nil {
+			return fmt.Errorf("variant %q: %w", v.Name, err)
+		}
+		if err := ValidateConfigResource(s.Type, v.Resource); err != nil {
+			return fmt.Errorf("variant %q: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// configResourceKind is the JSON kind of a value.
+func configResourceKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+// ConfigResourceSchema returns the fields that a resource of the type requires, and their kinds. It is taken from the
+// built-in resource; fields that are null there may have any kind. Types without a built-in resource only require the type and id.
+func ConfigResourceSchema(_type string) map[string]string {
+	schema := map[string]string{
+		"type": "string",
+		"id":   "string",
+	}
+	builtin := make(map[string]any)
+	if err := json.Unmarshal([]byte(evr.GetDefaultConfigResource(_type, _type)), &builtin); err != nil {
+		return schema
+	}
+	for k, v := range builtin {
+		if v == nil {
+			schema[k] = ""
+		} else {
+			schema[k] = configResourceKind(v)
+		}
+	}
+	return schema
+}
+
+// ValidateConfigResource validates the resource against the type's schema.
+func ValidateConfigResource(_type string, data json.RawMessage) error {
+	resource := make(map[string]any)
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return fmt.Errorf("resource must be a JSON object: %w", err)
+	}
+
+	if resource["type"] != _type {
+		return fmt.Errorf("type must be %q", _type)
+	}
+	if resource["id"] != _type {
+		return fmt.Errorf("id must be %q", _type)
+	}
+
+	problems := make([]string, 0)
+	for field, kind := range ConfigResourceSchema(_type) {
+		v, ok := resource[field]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is required", field))
+		} else if kind != "" && v != nil && configResourceKind(v) != kind {
+			problems = append(problems, fmt.Sprintf("%s must be a %s", field, kind))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Active returns the latest version, or nil.
+func (h *ConfigResourceHistory) Active() *ConfigResourceSet {
+	if len(h.Versions) == 0 {
+		return nil
+	}
+	return h.Versions[len(h.Versions)-1]
+}
+
+// Version returns the stored version.
+func (h *ConfigResourceHistory) Version(version int) (*ConfigResourceSet, bool) {
+	for _, s := range h.Versions {
+		if s.Version == version {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Add stores the set as the next version, and drops the oldest beyond the limit.
+func (h *ConfigResourceHistory) Add(s *ConfigResourceSet) {
+	s.Type = h.Type
+	s.Version = 1
+	if a := h.Active(); a != nil {
+		s.Version = a.Version + 1
+	}
+	h.Versions = append(h.Versions, s)
+	if n := len(h.Versions); n > configResourceMaxVersions {
+		h.Versions = h.Versions[n-configResourceMaxVersions:]
+	}
+}
+
+// Rollback makes a copy of an earlier version the next version.
+func (h *ConfigResourceHistory) Rollback(version int, userID string, now time.Time) (*ConfigResourceSet, error) {
+	prev, ok := h.Version(version)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrConfigResourceVersionNotFound, version)
+	}
+	s := &ConfigResourceSet{
+		Default:   prev.Default,
+		Variants:  slices.Clone(prev.Variants),
+		Note:      fmt.Sprintf("rollback to version %d", version),
+		UpdatedBy: userID,
+		UpdatedAt: now,
+	}
+	h.Add(s)
+	return s, nil
+}
+
+// ConfigResourceHistoryRead returns the history of the type, and its storage version ("*" if it does not exist).
+func ConfigResourceHistoryRead(ctx context.Context, nk runtime.NakamaModule, _type string) (*ConfigResourceHistory, string, error) {
+	history := &ConfigResourceHistory{Type: _type, Versions: make([]*ConfigResourceSet, 0)}
+	objs, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: ConfigResourceStorageCollection,
+		Key:        _type,
+		UserID:     SystemUserID,
+	}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objs) == 0 {
+		return history, "*", nil
+	}
+	if err := json.Unmarshal([]byte(objs[0].Value), history); err != nil {
+		return nil, "", err
+	}
+	return history, objs[0].Version, nil
+}
+
+// ConfigResourceHistoryWrite stores the history, if it has not changed since it was read, and its active version.
+func ConfigResourceHistoryWrite(ctx context.Context, nk runtime.NakamaModule, history *ConfigResourceHistory, version string) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	active, err := json.Marshal(history.Active())
+	if err != nil {
+		return err
+	}
+	// Both are written, or neither.
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      ConfigResourceStorageCollection,
+			Key:             history.Type,
+			UserID:          SystemUserID,
+			Value:           string(data),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+		{
+			Collection:      ConfigResourceActiveStorageCollection,
+			Key:             history.Type,
+			UserID:          SystemUserID,
+			Value:           string(active),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
+
+// ConfigResourceActiveRead returns the active version of the type, or nil. Histories stored before the active
+// version was stored on its own are read whole.
+func ConfigResourceActiveRead(ctx context.Context, nk runtime.NakamaModule, _type string) (*ConfigResourceSet, error) {
+	objs, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: ConfigResourceActiveStorageCollection,
+		Key:        _type,
+		UserID:     SystemUserID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		history, _, err := ConfigResourceHistoryRead(ctx, nk, _type)
+		if err != nil {
+			return nil, err
+		}
+		return history.Active(), nil
+	}
+	var active *ConfigResourceSet
+	if err := json.Unmarshal([]byte(objs[0].Value), &active); err != nil {
+		return nil, err
+	}
+	return active, nil
+}
+
+// ConfigResourceSelect returns the client's resource from the active version of the type, and the variant's name.
+// It returns nil if the type has no versions, or none that apply.
+func ConfigResourceSelect(ctx context.Context, nk runtime.NakamaModule, _type string, c ConfigResourceClient) (json.RawMessage, string, error) {
+	active, err := ConfigResourceActiveRead(ctx, nk, _type)
+	if err != nil {
+		return nil, "", err
+	}
+	if active == nil {
+		return nil, "", nil
+	}
+	resource, variant := active.Select(c)
+	return resource, variant, nil
+}
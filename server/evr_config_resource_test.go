@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigResource(t *testing.T) {
+	for _, typ := range []string{"main_menu", "active_battle_pass_season", "active_store_entry", "active_store_featured_entry"} {
+		assert.NoError(t, ValidateConfigResource(typ, json.RawMessage(evr.GetDefaultConfigResource(typ, typ))), "the built-in %s resource", typ)
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		wantErr  string
+	}{
+		{"not an object", `[1, 2]`, "JSON object"},
+		{"wrong type", `{"type": "active_store_entry", "id": "main_menu"}`, `type must be "main_menu"`},
+		{"wrong id", `{"type": "main_menu", "id": "other"}`, `id must be "main_menu"`},
+		{"missing field", `{"type": "main_menu", "id": "main_menu", "_ts": 0}`, "news is required"},
+		{"wrong kind", strings.Replace(evr.DefaultMainMenuConfigResource, `"splash_version": 1`, `"splash_version": "1"`, 1), "splash_version must be a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfigResource("main_menu", json.RawMessage(tt.resource))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	// Fields that are null in the built-in resource may be set.
+	resource := strings.Replace(evr.DefaultActiveBattlePassSeasonConfigResource, `"learn_more_link": null`, `"learn_more_link": "https://example.com"`, 1)
+	assert.NoError(t, ValidateConfigResource("active_battle_pass_season", json.RawMessage(resource)))
+
+	// Types without a built-in resource only need the type and id.
+	assert.NoError(t, ValidateConfigResource("new_type", json.RawMessage(`{"type": "new_type", "id": "new_type", "x": 1}`)))
+}
+
+func testMainMenu(link string) json.RawMessage {
+	return json.RawMessage(strings.ReplaceAll(evr.DefaultMainMenuConfigResource, "https://en.wikipedia.org/wiki/Lone_Echo", link))
+}
+
+func TestConfigResourceSet_Select(t *testing.T) {
+	set := &ConfigResourceSet{
+		Type:    "main_menu",
+		Default: testMainMenu("default"),
+		Variants: []ConfigResourceVariant{
+			{Name: "guild", Target: ConfigResourceTarget{GroupIDs: []string{"group-a"}}, Resource: testMainMenu("guild")},
+			{Name: "new-build", Target: ConfigResourceTarget{MinBuildNumber: 631000}, Resource: testMainMenu("new-build")},
+			{Name: "quest", Target: ConfigResourceTarget{DeviceTypes: []string{"Meta Quest 3"}, MaxBuildNumber: evr.StandaloneBuildNumber}, Resource: testMainMenu("quest")},
+		},
+	}
+	require.NoError(t, set.Validate())
+
+	tests := []struct {
+		name    string
+		client  ConfigResourceClient
+		variant string
+	}{
+		{"no match", ConfigResourceClient{GroupID: "group-b", BuildNumber: 630000}, "default"},
+		{"guild", ConfigResourceClient{GroupID: "group-a", BuildNumber: 631500}, "guild"},
+		{"build", ConfigResourceClient{GroupID: "group-b", BuildNumber: 631500}, "new-build"},
+		{"device", ConfigResourceClient{BuildNumber: evr.StandaloneBuildNumber, DeviceType: "meta quest 3"}, "quest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, variant := set.Select(tt.client)
+			assert.Equal(t, tt.variant, variant)
+			assert.Contains(t, string(resource), `"link": "`+tt.variant+`"`)
+		})
+	}
+
+	set.Default = nil
+	resource, variant := set.Select(ConfigResourceClient{})
+	assert.Nil(t, resource)
+	assert.Empty(t, variant)
+}
+
+func TestConfigResourceTarget_Rollout(t *testing.T) {
+	target := ConfigResourceTarget{RolloutPercent: 25}
+	matched := 0
+	for i := 0; i < 4000; i++ {
+		c := ConfigResourceClient{UserID: fmt.Sprintf("user-%d", i)}
+		m := target.Matches(c, "main_menu:canary")
+		assert.Equal(t, m, target.Matches(c, "main_menu:canary"), "the rollout must be stable")
+		if m {
+			matched++
+		}
+	}
+	assert.InDelta(t, 1000, matched, 150)
+
+	assert.False(t, target.Matches(ConfigResourceClient{}, "main_menu:canary"), "unauthenticated clients are not in a rollout")
+	assert.True(t, ConfigResourceTarget{RolloutPercent: 100}.Matches(ConfigResourceClient{}, "main_menu:canary"))
+}
+
+func TestConfigResourceSet_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		set  ConfigResourceSet
+	}{
+		{"no type", ConfigResourceSet{}},
+		{"invalid default", ConfigResourceSet{Type: "main_menu", Default: json.RawMessage(`{}`)}},
+		{"unnamed variant", ConfigResourceSet{Type: "main_menu", Variants: []ConfigResourceVariant{{Resource: testMainMenu("a")}}}},
+		{"duplicate variant", ConfigResourceSet{Type: "main_menu", Variants: []ConfigResourceVariant{{Name: "a", Resource: testMainMenu("a")}, {Name: "a", Resource: testMainMenu("b")}}}},
+		{"bad rollout", ConfigResourceSet{Type: "main_menu", Variants: []ConfigResourceVariant{{Name: "a", Target: ConfigResourceTarget{RolloutPercent: 101}, Resource: testMainMenu("a")}}}},
+		{"bad build range", ConfigResourceSet{Type: "main_menu", Variants: []ConfigResourceVariant{{Name: "a", Target: ConfigResourceTarget{MinBuildNumber: 2, MaxBuildNumber: 1}, Resource: testMainMenu("a")}}}},
+		{"invalid variant resource", ConfigResourceSet{Type: "main_menu", Variants: []ConfigResourceVariant{{Name: "a", Resource: json.RawMessage(`{"type": "main_menu"}`)}}}},
+	}
+	for _, tt := range tests {
+		assert.Error(t, tt.set.Validate(), tt.name)
+	}
+}
+
+func TestConfigResourceHistory_Rollback(t *testing.T) {
+	history := &ConfigResourceHistory{Type: "main_menu"}
+	now := time.Now().UTC()
+
+	history.Add(&ConfigResourceSet{Default: testMainMenu("v1")})
+	history.Add(&ConfigResourceSet{Default: testMainMenu("v2")})
+	assert.Equal(t, 2, history.Active().Version)
+
+	set, err := history.Rollback(1, "user-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Version)
+	assert.Equal(t, history.Active(), set)
+	assert.JSONEq(t, string(testMainMenu("v1")), string(set.Default))
+	assert.Equal(t, "main_menu", set.Type)
+
+	_, err = history.Rollback(99, "user-1", now)
+	assert.ErrorIs(t, err, ErrConfigResourceVersionNotFound)
+
+	for i := 0; i < configResourceMaxVersions; i++ {
+		history.Add(&ConfigResourceSet{Default: testMainMenu("v")})
+	}
+	assert.Len(t, history.Versions, configResourceMaxVersions)
+	assert.Equal(t, 3+configResourceMaxVersions, history.Active().Version)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// evrMatchHistory lists the match history of a player or guild for the console, including private matches.
+// Query parameters: user_id, group_id, limit, cursor.
+func (s *ConsoleServer) evrMatchHistory(w http.ResponseWriter, r *http.Request) {
+	if !s.checkConsoleAuth(w, r) {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := MatchHistoryFilter{
+		UserID:         q.Get("user_id"),
+		GroupID:        q.Get("group_id"),
+		IncludePrivate: true,
+		Cursor:         q.Get("cursor"),
+	}
+	if filter.UserID == "" && filter.GroupID == "" {
+		http.Error(w, "user_id or group_id is required.", http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid limit.", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	records, cursor, err := MatchHistoryList(r.Context(), s.db, filter)
+	if errors.Is(err, ErrMatchHistoryInvalidCursor) {
+		http.Error(w, "Invalid cursor.", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		s.logger.Error("Error listing match history", zap.Error(err))
+		http.Error(w, "Error listing match history.", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(MatchHistoryResponse{Records: records, Cursor: cursor}); err != nil {
+		s.logger.Error("Error writing match history response", zap.Error(err))
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"go.uber.org/zap"
+)
+
+// checkConsoleAuth writes an error and returns false if the request does not have a valid console session.
+func (s *ConsoleServer) checkConsoleAuth(w http.ResponseWriter, r *http.Request) bool {
+	auth := r.Header.Get("authorization")
+	if len(auth) == 0 {
+		http.Error(w, "Console authentication required.", http.StatusUnauthorized)
+		return false
+	}
+	if _, ok := checkAuth(r.Context(), s.logger, s.config, auth, s.consoleSessionCache, s.loginAttemptCache); !ok {
+		http.Error(w, "Console authentication invalid.", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// evrSymbolsUnknown lists the unknown symbols seen in traffic on every node.
+func (s *ConsoleServer) evrSymbolsUnknown(w http.ResponseWriter, r *http.Request) {
+	if !s.checkConsoleAuth(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(SymbolUnknownResponse{Symbols: evr.ObservedSymbols()}); err != nil {
+		s.logger.Error("Error writing unknown symbols response", zap.Error(err))
+	}
+}
+
+// evrSymbolsResolve accepts candidate strings for the unknown symbols seen in traffic.
+func (s *ConsoleServer) evrSymbolsResolve(w http.ResponseWriter, r *http.Request) {
+	if !s.checkConsoleAuth(w, r) {
+		return
+	}
+
+	request := &SymbolResolveRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.config.GetConsole().MaxMessageSizeBytes)).Decode(request); err != nil {
+		http.Error(w, "Invalid request.", http.StatusBadRequest)
+		return
+	}
+	if len(request.Candidates) > symbolResolveMaxCandidates {
+		http.Error(w, "Too many candidates.", http.StatusBadRequest)
+		return
+	}
+
+	accepted, err := SymbolDictionaryResolve(r.Context(), s.logger, s.db, s.metrics, s.storageIndex, request.Candidates)
+	if err != nil {
+		s.logger.Error("Error resolving symbols", zap.Error(err))
+		http.Error(w, "Error storing resolved symbols.", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(SymbolResolveResponse{Accepted: accepted}); err != nil {
+		s.logger.Error("Error writing resolve symbols response", zap.Error(err))
+	}
+}
@@ -479,9 +479,16 @@ var (
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "suspension_duration",
-					Description: "Suspension duration (e.g. 1m, 2h, 3d, 4w)",
+					Description: "Suspension duration (e.g. 1m, 2h, 3d, 4w); defaults to the offense's escalation policy",
 					Required:    false,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "offense",
+					Description:  "The offense category of the guild's escalation policy",
+					Required:     false,
+					Autocomplete: true,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "moderator_notes",
@@ -501,6 +508,147 @@ var (
 				},
 			},
 		},
+		{
+			Name:        "appeal",
+			Description: "Appeal your suspension in this guild.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "statement",
+					Description: "Why the suspension should be reviewed",
+					Required:    true,
+					MaxLength:   appealStatementMaxLength,
+				},
+			},
+		},
+		{
+			Name:        "review-appeal",
+			Description: "Review a player's suspension appeal.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionUser,
+					Name:         "user",
+					Description:  "The player that appealed",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "outcome",
+					Description: "Take the appeal for review, or resolve it",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Review", Value: string(AppealStatusUnderReview)},
+						{Name: "Uphold", Value: string(AppealStatusUpheld)},
+						{Name: "Reduce", Value: string(AppealStatusReduced)},
+						{Name: "Overturn", Value: string(AppealStatusOverturned)},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "suspension_duration",
+					Description: "The reduced total suspension duration (e.g. 1h, 2d)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "notes",
+					Description: "Notes for the audit log",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "bracket",
+			Description: "View and manage the guild's tournament brackets.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "view",
+					Description: "Show a bracket's matches and standings.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "bracket",
+							Description: "The bracket ID (defaults to the most recent)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "advance",
+					Description: "Allocate the ready matches, pairing the next Swiss round if needed.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "bracket",
+							Description: "The bracket ID (defaults to the most recent)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "report",
+					Description: "Record or correct the result of a bracket match.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "match",
+							Description: "The bracket match (e.g. W1-2)",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "blue_score",
+							Description: "The blue team's final score",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "orange_score",
+							Description: "The orange team's final score",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "bracket",
+							Description: "The bracket ID (defaults to the most recent)",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "ranked",
+			Description: "View the guild's ranked ladder.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "player",
+					Description: "Show a player's rank, placement and series progress.",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionUser,
+							Name:        "user",
+							Description: "The player (defaults to you)",
+							Required:    false,
+						},
+						rankedModeOption,
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "ladder",
+					Description: "Show the top of this season's ladder.",
+					Options: []*discordgo.ApplicationCommandOption{
+						rankedModeOption,
+					},
+				},
+			},
+		},
 		{
 			Name:        "jersey-number",
 			Description: "Set your in-game jersey number.",
@@ -1896,6 +2044,10 @@ func (d *DiscordAppBot) RegisterSlashCommands() error {
 			return err
 		},
 		"whereami":            d.handleWhereAmI,
+		"appeal":              d.handleAppeal,
+		"review-appeal":       d.handleReviewAppeal,
+		"bracket":             d.handleBracket,
+		"ranked":              d.handleRanked,
 		"report-server-issue": d.handleReportServerIssue,
 		"next-match": func(ctx context.Context, logger runtime.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member, userID string, groupID string) error {
 			if user == nil {
@@ -2385,6 +2537,7 @@ func (d *DiscordAppBot) RegisterSlashCommands() error {
 				notes                  string
 				requireCommunityValues bool
 				duration               string
+				offense                string
 				allowPrivateLobbies    bool
 			)
 
@@ -2408,12 +2561,14 @@ func (d *DiscordAppBot) RegisterSlashCommands() error {
 					requireCommunityValues = o.BoolValue()
 				case "suspension_duration":
 					duration = o.StringValue()
+				case "offense":
+					offense = o.StringValue()
 				case "allow_private_lobbies":
 					allowPrivateLobbies = o.BoolValue()
 				}
 			}
 
-			return d.kickPlayer(logger, i, callerMember, target, duration, userNotice, notes, requireCommunityValues, allowPrivateLobbies)
+			return d.kickPlayer(logger, i, callerMember, target, duration, userNotice, notes, offense, requireCommunityValues, allowPrivateLobbies)
 		},
 		"join-player": func(ctx context.Context, logger runtime.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member, userID string, groupID string) error {
 
@@ -3087,6 +3242,51 @@ func (d *DiscordAppBot) RegisterSlashCommands() error {
 				}); err != nil {
 					logger.Error("Failed to respond to interaction", zap.Error(err))
 				}
+
+			case "kick-player":
+				partial := ""
+				for _, o := range data.Options {
+					if o.Name == "offense" {
+						if !o.Focused {
+							return
+						}
+						partial = strings.ToLower(o.StringValue())
+					}
+				}
+
+				gg := d.guildGroupRegistry.Get(d.cache.GuildIDToGroupID(i.GuildID))
+				if gg == nil || gg.EnforcementEscalation == nil {
+					return
+				}
+				choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(gg.EnforcementEscalation.Categories))
+				for _, c := range gg.EnforcementEscalation.Categories {
+					if !strings.Contains(strings.ToLower(c.Name), partial) {
+						continue
+					}
+					name := c.Name
+					if c.Description != "" {
+						name += " - " + c.Description
+					}
+					if len(name) > 100 {
+						name = name[:100]
+					}
+					choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+						Name:  name,
+						Value: c.Name,
+					})
+					if len(choices) == 25 {
+						break
+					}
+				}
+
+				if err := d.dg.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+					Data: &discordgo.InteractionResponseData{
+						Choices: choices,
+					},
+				}); err != nil {
+					logger.Error("Failed to respond to interaction", zap.Error(err))
+				}
 			}
 
 		case discordgo.InteractionModalSubmit:
@@ -3567,6 +3767,10 @@ func (d *DiscordAppBot) LogServiceUserErrorMessage(message string) error {
 }
 
 func (d *DiscordAppBot) LogAuditMessage(ctx context.Context, groupID string, message string, replaceMentions bool) (*discordgo.Message, error) {
+	if d == nil || d.dg == nil {
+		// Audit channels are disabled without a Discord session.
+		return nil, nil
+	}
 	// replace all <@uuid> mentions with <@discordID>
 
 	gg := d.guildGroupRegistry.Get(groupID)
@@ -3593,8 +3797,9 @@ func (d *DiscordAppBot) LogAuditMessage(ctx context.Context, groupID string, mes
 }
 
 func (d *DiscordAppBot) LogUserErrorMessage(ctx context.Context, groupID string, message string, replaceMentions bool) (*discordgo.Message, error) {
-	if d == nil {
-		return nil, fmt.Errorf("discord session is not initialized")
+	if d == nil || d.dg == nil {
+		// Error channels are disabled without a Discord session.
+		return nil, nil
 	}
 	// replace all <@uuid> mentions with <@discordID>
 	if replaceMentions {
@@ -3690,7 +3895,7 @@ func (d *DiscordAppBot) SendErrorToUser(userID string, userErr error) error {
 
 func SendIPAuthorizationNotification(dg *discordgo.Session, discordID string, ip string) error {
 	if dg == nil {
-		return fmt.Errorf("discord session is not initialized")
+		return ErrDiscordDisabled
 	}
 
 	channel, err := dg.UserChannelCreate(discordID)
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// handleAppeal files the caller's appeal of their latest active suspension in the guild.
+func (d *DiscordAppBot) handleAppeal(ctx context.Context, logger runtime.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member, userID string, groupID string) error {
+	if user == nil {
+		return nil
+	}
+	if groupID == "" {
+		return simpleInteractionResponse(s, i, "Appeals must be filed in the guild that issued the suspension.")
+	}
+
+	var statement string
+	for _, o := range i.ApplicationCommandData().Options {
+		if o.Name == "statement" {
+			statement = o.StringValue()
+		}
+	}
+	if statement == "" {
+		return errors.New("a statement is required")
+	}
+
+	var appeal GuildEnforcementAppeal
+	var record GuildEnforcementRecord
+	_, err := EnforcementAppealUpdate(ctx, d.nk, userID, func(journal *GuildEnforcementJournal) error {
+		var ok bool
+		if record, ok = journal.LatestAppealableRecord(groupID); !ok {
+			return ErrAppealRecordNotFound
+		}
+		var err error
+		appeal, err = journal.SubmitAppeal(groupID, record.ID, statement)
+		return err
+	})
+	switch {
+	case errors.Is(err, ErrAppealRecordNotFound):
+		return simpleInteractionResponse(s, i, "You have no active suspension in this guild.")
+	case errors.Is(err, ErrAppealExists):
+		return simpleInteractionResponse(s, i, "Your suspension has already been appealed.")
+	case err != nil:
+		return err
+	}
+
+	if _, err := d.LogAuditMessage(ctx, groupID, enforcementAppealAuditMessage(appeal, record, user.ID), false); err != nil {
+		logger.WithField("error", err).Warn("Failed to send appeal audit message")
+	}
+
+	return simpleInteractionResponse(s, i, fmt.Sprintf("Your appeal of the suspension expiring <t:%d:R> has been submitted. The guild's moderators will review it.", record.Expiry.Unix()))
+}
+
+// handleReviewAppeal takes a player's open appeal for review, or resolves it.
+func (d *DiscordAppBot) handleReviewAppeal(ctx context.Context, logger runtime.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member, userID string, groupID string) error {
+	if user == nil {
+		return nil
+	}
+
+	var (
+		target   *discordgo.User
+		outcome  GuildEnforcementAppealStatus
+		duration string
+		notes    string
+	)
+	for _, o := range i.ApplicationCommandData().Options {
+		switch o.Name {
+		case "user":
+			target = o.UserValue(s)
+		case "outcome":
+			outcome = GuildEnforcementAppealStatus(o.StringValue())
+		case "suspension_duration":
+			duration = o.StringValue()
+		case "notes":
+			notes = o.StringValue()
+		}
+	}
+	if target == nil {
+		return errors.New("a user is required")
+	}
+	targetUserID := d.cache.DiscordIDToUserID(target.ID)
+	if targetUserID == "" {
+		return errors.New("failed to get target user ID")
+	}
+
+	var appeal GuildEnforcementAppeal
+	var record GuildEnforcementRecord
+	_, err := EnforcementAppealUpdate(ctx, d.nk, targetUserID, func(journal *GuildEnforcementJournal) error {
+		open, ok := journal.LatestOpenAppeal(groupID)
+		if !ok {
+			return ErrAppealNotFound
+		}
+		if record, ok = journal.Record(groupID, open.RecordID); !ok {
+			return ErrAppealRecordNotFound
+		}
+
+		var err error
+		switch outcome {
+		case AppealStatusUnderReview:
+			appeal, err = journal.AssignAppealReviewer(groupID, open.RecordID, userID, user.ID)
+		case AppealStatusReduced:
+			if duration == "" {
+				return errors.New("a reduction needs the new suspension duration")
+			}
+			reduced, perr := parseSuspensionDuration(duration)
+			if perr != nil {
+				return fmt.Errorf("duration parse error: %w", perr)
+			}
+			appeal, err = journal.ResolveAppeal(groupID, open.RecordID, userID, user.ID, outcome, notes, record.CreatedAt.Add(reduced))
+		default:
+			appeal, err = journal.ResolveAppeal(groupID, open.RecordID, userID, user.ID, outcome, notes, record.Expiry)
+		}
+		if err != nil {
+			return err
+		}
+		record, _ = journal.Record(groupID, open.RecordID)
+		return nil
+	})
+	if errors.Is(err, ErrAppealNotFound) || errors.Is(err, ErrAppealRecordNotFound) {
+		return simpleInteractionResponse(s, i, fmt.Sprintf("%s has no open appeal in this guild.", target.Mention()))
+	} else if err != nil {
+		return simpleInteractionResponse(s, i, err.Error())
+	}
+
+	message := enforcementAppealAuditMessage(appeal, record, user.ID)
+	if _, err := d.LogAuditMessage(ctx, groupID, message, false); err != nil {
+		logger.WithField("error", err).Warn("Failed to send appeal audit message")
+	}
+	return simpleInteractionResponse(s, i, message)
+}
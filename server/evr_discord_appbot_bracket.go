@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// handleBracket shows, advances, or records results in, the guild's tournament brackets.
+func (d *DiscordAppBot) handleBracket(ctx context.Context, logger runtime.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member, userID string, groupID string) error {
+	if user == nil {
+		return nil
+	}
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return errors.New("no subcommand provided")
+	}
+	subcommand := options[0]
+
+	var (
+		bracketID   string
+		matchID     string
+		blueScore   int
+		orangeScore int
+	)
+	for _, o := range subcommand.Options {
+		switch o.Name {
+		case "bracket":
+			bracketID = o.StringValue()
+		case "match":
+			matchID = strings.ToUpper(o.StringValue())
+		case "blue_score":
+			blueScore = int(o.IntValue())
+		case "orange_score":
+			orangeScore = int(o.IntValue())
+		}
+	}
+
+	var (
+		bracket *TournamentBracket
+		err     error
+	)
+	if bracketID == "" {
+		// The guild's most recent bracket.
+		brackets, err := TournamentBracketList(ctx, d.nk, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to list brackets: %w", err)
+		}
+		if len(brackets) == 0 {
+			return simpleInteractionResponse(s, i, "This guild has no brackets.")
+		}
+		bracketID = brackets[0].ID
+	}
+	if bracket, _, err = TournamentBracketRead(ctx, d.nk, bracketID); err != nil || bracket.GroupID != groupID {
+		return simpleInteractionResponse(s, i, "Bracket not found.")
+	}
+
+	var allocated []*BracketMatch
+	switch subcommand.Name {
+	case "view":
+	case "advance", "report":
+		gg := d.guildGroupRegistry.Get(groupID)
+		if gg == nil {
+			return simpleInteractionResponse(s, i, "This guild is not registered.")
+		}
+		if isGlobalOperator, err := CheckSystemGroupMembership(ctx, d.db, userID, GroupGlobalOperators); err != nil {
+			return fmt.Errorf("failed to check global operator status: %w", err)
+		} else if !isGlobalOperator && !gg.IsAllocator(userID) {
+			return simpleInteractionResponse(s, i, "You must be a guild allocator to use this command.")
+		}
+
+		if subcommand.Name == "report" {
+			var m *BracketMatch
+			if bracket, m, allocated, err = TournamentBracketReport(ctx, logger, d.nk, bracket.ID, matchID, blueScore, orangeScore); errors.Is(err, errBracketStore) {
+				return err
+			} else if err != nil {
+				return simpleInteractionResponse(s, i, fmt.Sprintf("Failed to report the result: %s", err))
+			}
+			if _, err := AuditLogSendGuild(d.dg, gg, fmt.Sprintf("<@%s> reported %s", user.ID, bracketResultMessage(bracket, m, allocated))); err != nil {
+				logger.WithField("error", err).Warn("Failed to send bracket audit message")
+			}
+		} else {
+			stored, matches, err := TournamentBracketAdvance(ctx, logger, d.nk, bracket.ID)
+			if errors.Is(err, errBracketStore) {
+				return err
+			} else if stored == nil {
+				return simpleInteractionResponse(s, i, fmt.Sprintf("Failed to advance the bracket: %s", err))
+			} else if err != nil && len(matches) == 0 {
+				return simpleInteractionResponse(s, i, fmt.Sprintf("Failed to allocate the bracket's matches: %s", err))
+			}
+			bracket, allocated = stored, matches
+		}
+	default:
+		return fmt.Errorf("unknown subcommand: %s", subcommand.Name)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{bracketEmbed(bracket, allocated)},
+		},
+	})
+}
+
+// bracketEmbed shows the bracket's matches by round, and its standings.
+func bracketEmbed(b *TournamentBracket, allocated []*BracketMatch) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       b.Name,
+		Description: fmt.Sprintf("%s, %d teams (`%s`)", strings.ReplaceAll(string(b.Format), "_", " "), len(b.Teams), b.ID),
+		Color:       0x9656ce,
+		Fields:      make([]*discordgo.MessageEmbedField, 0),
+	}
+
+	rounds := make([]string, 0)
+	lines := make(map[string][]string)
+	for _, m := range b.Matches {
+		key := fmt.Sprintf("%s round %d", strings.ReplaceAll(m.Bracket, "_", " "), m.Round)
+		if _, ok := lines[key]; !ok {
+			rounds = append(rounds, key)
+		}
+		var line string
+		switch {
+		case m.Bye:
+			line = fmt.Sprintf("`%s` %s (bye)", m.ID, b.TeamName(m.Winner))
+		case m.Status == BracketMatchComplete:
+			line = fmt.Sprintf("`%s` %s **%d - %d** %s", m.ID, b.TeamName(m.Blue), m.BlueScore, m.OrangeScore, b.TeamName(m.Orange))
+		case m.Status == BracketMatchPending:
+			line = fmt.Sprintf("`%s` pending", m.ID)
+		default:
+			line = fmt.Sprintf("`%s` %s vs %s (%s)", m.ID, b.TeamName(m.Blue), b.TeamName(m.Orange), m.Status)
+		}
+		lines[key] = append(lines[key], line)
+	}
+	for _, r := range rounds {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   r,
+			Value:  strings.Join(lines[r], "\n"),
+			Inline: false,
+		})
+	}
+
+	standings := make([]string, 0, len(b.Teams))
+	for n, s := range b.Standings() {
+		line := fmt.Sprintf("%d. %s (%d-%d, %+d)", n+1, s.Name, s.Wins, s.Losses, s.PointsFor-s.PointsAgainst)
+		if s.Eliminated {
+			line = "~~" + line + "~~"
+		}
+		standings = append(standings, line)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Standings",
+		Value: strings.Join(standings, "\n"),
+	})
+
+	if len(allocated) > 0 {
+		ids := make([]string, 0, len(allocated))
+		for _, m := range allocated {
+			ids = append(ids, m.ID)
+		}
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "Allocated " + strings.Join(ids, ", ")}
+	}
+	if b.Status == BracketStatusComplete && b.Champion != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: b.TeamName(b.Champion) + " won the bracket"}
+	}
+	return embed
+}
@@ -216,7 +216,7 @@ func (d *DiscordAppBot) handleInteractionApplicationCommand(ctx context.Context,
 			logger.Warn("Failed to log interaction to channel")
 		}
 
-	case "join-player", "igp", "ign", "shutdown-match":
+	case "join-player", "igp", "ign", "shutdown-match", "review-appeal":
 
 		gg := d.guildGroupRegistry.Get(groupID)
 		if gg == nil {
@@ -626,13 +626,14 @@ func (d *DiscordAppBot) handleCreateMatch(ctx context.Context, logger runtime.Lo
 	return label, latencyMillis, nil
 }
 
-func (d *DiscordAppBot) kickPlayer(logger runtime.Logger, i *discordgo.InteractionCreate, caller *discordgo.Member, target *discordgo.User, duration, userNotice, notes string, requireCommunityValues bool, allowPrivateLobbies bool) error {
+func (d *DiscordAppBot) kickPlayer(logger runtime.Logger, i *discordgo.InteractionCreate, caller *discordgo.Member, target *discordgo.User, duration, userNotice, notes, offense string, requireCommunityValues bool, allowPrivateLobbies bool) error {
 	var (
 		ctx                = d.ctx
 		nk                 = d.nk
 		db                 = d.db
 		suspensionExpiry   time.Time
 		suspensionDuration time.Duration
+		escalation         *EnforcementEscalation
 	)
 
 	callerUserID := d.cache.DiscordIDToUserID(caller.User.ID)
@@ -648,6 +649,27 @@ func (d *DiscordAppBot) kickPlayer(logger runtime.Logger, i *discordgo.Interacti
 		return errors.New("failed to get group ID")
 	}
 
+	// The guild's escalation policy suggests the duration for the offense; an explicit duration overrides it.
+	if offense != "" {
+		gg := d.guildGroupRegistry.Get(groupID)
+		if gg == nil {
+			return errors.New("failed to get guild group")
+		}
+		var err error
+		if escalation, err = EnforcementEscalationEvaluate(ctx, nk, d.guildGroupRegistry, gg, targetUserID, offense); err != nil {
+			if i != nil && (errors.Is(err, ErrEscalationPolicyNotSet) || errors.Is(err, ErrEscalationUnknownOffense)) {
+				return simpleInteractionResponse(d.dg, i, err.Error())
+			}
+			return err
+		}
+		offense = escalation.Category
+		if duration == "" {
+			duration = escalation.Duration.String()
+		}
+		requireCommunityValues = requireCommunityValues || escalation.RequireCommunityValues
+		allowPrivateLobbies = allowPrivateLobbies || escalation.AllowPrivateLobbies
+	}
+
 	// Parse duration using shared helper function
 	if duration != "" {
 		var err error
@@ -712,17 +734,18 @@ func (d *DiscordAppBot) kickPlayer(logger runtime.Logger, i *discordgo.Interacti
 			// Add a new record
 			actions = append(actions, fmt.Sprintf("suspension expires <t:%d:R>", suspensionExpiry.UTC().Unix()))
 			record := journal.AddRecord(groupID, callerUserID, caller.User.ID, userNotice, notes, requireCommunityValues, allowPrivateLobbies, suspensionDuration)
+			if escalation != nil {
+				record, _ = journal.SetOffenseCategory(groupID, record.ID, offense)
+				actions = append(actions, "escalation policy: "+escalation.Text())
+			}
 			recordsByGroupID[groupID] = append(recordsByGroupID[groupID], record)
 
 		} else if voidActiveSuspensions {
 
 			currentGroupID := groupID
 
-			groupIDs := []string{currentGroupID}
 			// Add inherited groups
-			if gg.SuspensionInheritanceGroupIDs != nil {
-				groupIDs = append(groupIDs, gg.SuspensionInheritanceGroupIDs...)
-			}
+			groupIDs := d.guildGroupRegistry.SuspensionGroupIDs(gg)
 
 			// Void any active suspensions for this group and any inherited groups
 			for _, gID := range groupIDs {
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const rankedLadderListLimit = 20
+
+var rankedModeOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "mode",
+	Description: "Game mode (defaults to the first ranked mode)",
+	Required:    false,
+	Choices: []*discordgo.ApplicationCommandOptionChoice{
+		{
+			Name:  "Echo Arena Public",
+			Value: "echo_arena",
+		},
+	},
+}
+
+// handleRanked shows a player's standing on the guild's ranked ladder, or the top of the ladder.
+func (d *DiscordAppBot) handleRanked(ctx context.Context, logger runtime.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User, member *discordgo.Member, userID string, groupID string) error {
+	if user == nil {
+		return nil
+	}
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return errors.New("no subcommand provided")
+	}
+	subcommand := options[0]
+
+	settings := RankedLadderSettingsGet()
+	if !settings.Enabled {
+		return simpleInteractionResponse(s, i, "The ranked ladder is not enabled.")
+	}
+
+	mode := settings.Modes[0]
+	target := user
+	for _, o := range subcommand.Options {
+		switch o.Name {
+		case "mode":
+			mode = evr.ToSymbol(o.StringValue())
+		case "user":
+			target = o.UserValue(s)
+		}
+	}
+	if !settings.Applies(mode) {
+		return simpleInteractionResponse(s, i, fmt.Sprintf("`%s` is not a ranked mode.", mode.String()))
+	}
+
+	var embed *discordgo.MessageEmbed
+	switch subcommand.Name {
+	case "player":
+		targetUserID := userID
+		if target.ID != user.ID {
+			var err error
+			if targetUserID, err = GetUserIDByDiscordID(ctx, d.db, target.ID); err != nil || targetUserID == "" {
+				return simpleInteractionResponse(s, i, "That player has no linked account.")
+			}
+		}
+		states, err := RankedLadderLoad(ctx, d.nk, targetUserID, groupID, []evr.Symbol{mode}, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to load ranked ladder standing: %w", err)
+		}
+		embed = rankedStandingEmbed(settings, target, states[0])
+
+	case "ladder":
+		boardID := StatisticBoardID(groupID, mode, RankedLadderBoardStatName(RankedLadderSeason(time.Now().UTC())), evr.ResetScheduleAllTime)
+		records, _, _, _, err := d.nk.LeaderboardRecordsList(ctx, boardID, nil, rankedLadderListLimit, "", 0)
+		if err != nil || len(records) == 0 {
+			return simpleInteractionResponse(s, i, "No one has been placed on the ladder this season.")
+		}
+		lines := make([]string, 0, len(records))
+		for _, r := range records {
+			metadata := make(map[string]string)
+			if r.Metadata != "" {
+				_ = json.Unmarshal([]byte(r.Metadata), &metadata)
+			}
+			name := r.Username.GetValue()
+			if discordID := metadata["discord_id"]; discordID != "" {
+				name = fmt.Sprintf("<@%s>", discordID)
+			}
+			lines = append(lines, fmt.Sprintf("%d. %s — **%s**", r.Rank, name, metadata["rank"]))
+		}
+		embed = &discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("Ranked Ladder: %s", mode.String()),
+			Description: strings.Join(lines, "\n"),
+			Color:       0x9656ce,
+		}
+
+	default:
+		return fmt.Errorf("unknown subcommand: %s", subcommand.Name)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+}
+
+// rankedStandingEmbed shows the player's rank, placement and series progress, and their past seasons.
+func rankedStandingEmbed(settings RankedLadderSettings, target *discordgo.User, state *RankedLadderState) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       state.RankName(),
+		Description: fmt.Sprintf("<@%s> in %s", target.ID, state.Mode.String()),
+		Color:       0x9656ce,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Season Record", Value: fmt.Sprintf("%d W / %d L", state.Wins, state.Losses), Inline: true},
+		},
+	}
+	switch {
+	case !state.Placed:
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Placement",
+			Value:  fmt.Sprintf("%d of %d matches played (%d won)", state.PlacementMatches, settings.PlacementMatches, state.PlacementWins),
+			Inline: true,
+		})
+	case state.Series != nil:
+		kind := "Demotion"
+		if state.Series.Promotion {
+			kind = "Promotion"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   kind + " Series",
+			Value:  fmt.Sprintf("%d - %d (best of %d, %s)", state.Series.Wins, state.Series.Losses, settings.SeriesLength, state.Series.Target.String()),
+			Inline: true,
+		})
+	}
+	if state.Placed {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Season Peak", Value: state.Peak.String(), Inline: true})
+	}
+	if len(state.PreviousSeasons) > 0 {
+		previous := state.PreviousSeasons[0]
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Last Season",
+			Value: fmt.Sprintf("%s (peak %s)", previous.Rank.String(), previous.Peak.String()),
+		})
+	}
+	return embed
+}
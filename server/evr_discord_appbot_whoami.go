@@ -238,6 +238,14 @@ func (w *WhoAmI) createUserAccountDetailsEmbed() *discordgo.MessageEmbed {
 		},
 	}
 
+	if w.opts.IncludeMatchmakingTier && w.earlyQuitConfig != nil && len(w.earlyQuitConfig.RecentDecisions) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Early Quit Decisions",
+			Value:  w.createEarlyQuitDecisionsFieldValue(),
+			Inline: false,
+		})
+	}
+
 	if w.profile.IsDisabled() {
 		embed.Color = 0xCC0000
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
@@ -250,6 +258,16 @@ func (w *WhoAmI) createUserAccountDetailsEmbed() *discordgo.MessageEmbed {
 	return embed
 }
 
+func (w *WhoAmI) createEarlyQuitDecisionsFieldValue() string {
+	lines := make([]string, 0, len(w.earlyQuitConfig.RecentDecisions))
+	// Newest first
+	for i := len(w.earlyQuitConfig.RecentDecisions) - 1; i >= 0; i-- {
+		d := w.earlyQuitConfig.RecentDecisions[i]
+		lines = append(lines, fmt.Sprintf("<t:%d:R> `%s`: %s", d.LeftAt.UTC().Unix(), d.Decision, d.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (w *WhoAmI) createRecentLoginsFieldValue() string {
 
 	loginsByXPID := make(map[evr.EvrId]time.Time, 0)
@@ -568,6 +586,13 @@ func (w *WhoAmI) createAlternatesEmbed() *discordgo.MessageEmbed {
 		if altAccount.GetDisableTime() != nil {
 			state = "disabled"
 		}
+		if score, found := w.loginHistory.AlternateScores[altUserID]; found {
+			confidence := fmt.Sprintf("%.0f%%", score.Score*100)
+			if score.Verdict != "" {
+				confidence += " " + string(score.Verdict)
+			}
+			state = strings.TrimSpace(state + " " + confidence)
+		}
 
 		items := make([]string, 0, len(matches))
 		for _, m := range matches {
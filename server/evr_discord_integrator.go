@@ -20,7 +20,8 @@ import (
 )
 
 var (
-	ErrMemberNotFound = errors.New("member not found")
+	ErrMemberNotFound  = errors.New("member not found")
+	ErrDiscordDisabled = errors.New("discord integration is disabled")
 )
 
 var mentionRegex = regexp.MustCompile(`<@([-0-9A-Fa-f]+?)>`)
@@ -74,10 +75,30 @@ func (c *DiscordIntegrator) Stop() {
 	c.cancelFn()
 }
 
+// Enabled returns true if the integrator has a Discord session.
+// When disabled, only the ID lookups (backed by the database) are available.
+func (c *DiscordIntegrator) Enabled() bool {
+	return c != nil && c.dg != nil
+}
+
+// BotUsername returns the username of the bot, or an empty string if it is not connected.
+func (c *DiscordIntegrator) BotUsername() string {
+	if !c.Enabled() || c.dg.State == nil || c.dg.State.User == nil {
+		return ""
+	}
+	return c.dg.State.User.Username
+}
+
 func (c *DiscordIntegrator) Start() {
 	dg := c.dg
 	logger := c.logger.With(zap.String("module", "discord_integration"))
 
+	if dg == nil {
+		// Role sync and guild pruning require a live session.
+		logger.Warn("Discord session is not set, guild member syncing is disabled")
+		return
+	}
+
 	// Start the cache worker.
 	go func() {
 
@@ -232,6 +253,9 @@ func (c *DiscordIntegrator) Start() {
 
 // Queue a user for caching/updating.
 func (c *DiscordIntegrator) QueueSyncMember(guildID, discordID string, full bool) {
+	if !c.Enabled() {
+		return
+	}
 	metricsTags := map[string]string{
 		"guild_id": guildID,
 		"group_id": c.GuildIDToGroupID(guildID),
@@ -381,7 +405,9 @@ func (c *DiscordIntegrator) syncMember(ctx context.Context, logger *zap.Logger,
 	}
 
 	// Update the group state with the member's roles.
-	if group.RoleCacheUpdate(profile, member.Roles) {
+	if updated, err := c.guildGroupRegistry.CommunityRoleSync(ctx, group, profile); err != nil {
+		return fmt.Errorf("error syncing community roles: %w", err)
+	} else if updated {
 		if err := GuildGroupStore(ctx, c.nk, c.guildGroupRegistry, group); err != nil {
 			return fmt.Errorf("error storing guild group: %w", err)
 		}
@@ -456,6 +482,9 @@ func InGameName(m *discordgo.Member) string {
 
 // Loads/Adds a user to the cache.
 func (c *DiscordIntegrator) GuildMember(guildID, discordID string) (member *discordgo.Member, err error) {
+	if !c.Enabled() {
+		return nil, ErrDiscordDisabled
+	}
 	// Check the cache first.
 	if member, err = c.dg.State.Member(guildID, discordID); err == nil && member != nil {
 		return member, nil
@@ -681,7 +710,9 @@ func (d *DiscordIntegrator) handleMemberUpdate(logger *zap.Logger, s *discordgo.
 	}
 
 	// Update the role cache
-	if updated := group.RoleCacheUpdate(evrAccount, e.Member.Roles); updated {
+	if updated, err := d.guildGroupRegistry.CommunityRoleSync(ctx, group, evrAccount); err != nil {
+		return fmt.Errorf("error syncing community roles: %w", err)
+	} else if updated {
 		if err := GuildGroupStore(ctx, d.nk, d.guildGroupRegistry, group); err != nil {
 			return fmt.Errorf("error storing guild group: %w", err)
 		}
@@ -915,6 +946,9 @@ func (e DisplayNameInUseError) Error() string {
 }
 
 func (d *DiscordIntegrator) CheckUser2FA(ctx context.Context, userID uuid.UUID) (bool, error) {
+	if !d.Enabled() {
+		return false, ErrDiscordDisabled
+	}
 	discordID, err := GetDiscordIDByUserID(ctx, d.db, userID.String())
 	if err != nil {
 		return false, fmt.Errorf("error getting discord id: %w", err)
@@ -946,6 +980,9 @@ func (d *DiscordIntegrator) ReplaceMentions(message string) string {
 func (d *DiscordIntegrator) LogServiceAuditMessage(ctx context.Context, message string, replaceMentions bool) (*discordgo.Message, error) {
 	// replace all <@uuid> mentions with <@discordID>
 
+	if !d.Enabled() {
+		return nil, nil
+	}
 	if settings := ServiceSettings(); settings.ServiceGuildID != "" {
 		if replaceMentions {
 			message = d.ReplaceMentions(message)
@@ -971,6 +1008,9 @@ func HasLoggedIntoEcho(ctx context.Context, nk runtime.NakamaModule, userID stri
 }
 
 func SendUserMessage(ctx context.Context, dg *discordgo.Session, userID, message string) (*discordgo.Message, error) {
+	if dg == nil {
+		return nil, ErrDiscordDisabled
+	}
 	channel, err := dg.UserChannelCreate(userID)
 	if err != nil {
 		return nil, fmt.Errorf("error creating user channel: %w", err)
@@ -981,7 +1021,7 @@ func SendUserMessage(ctx context.Context, dg *discordgo.Session, userID, message
 
 func (d *DiscordIntegrator) GuildGroupName(groupID string) string {
 	guildID := d.GroupIDToGuildID(groupID)
-	if guildID == "" {
+	if guildID == "" || !d.Enabled() {
 		return ""
 	}
 	guild, err := d.dg.Guild(guildID)
@@ -33,6 +33,10 @@ func (d *DiscordIntegrator) pruneGuildGroups(ctx context.Context, logger runtime
 				logger.WithField("error", err).Error("Failed to unmarshal group metadata")
 				continue
 			}
+			if metadata.ProviderName() != CommunityProviderDiscord {
+				// Groups managed by other community providers are never orphaned by Discord.
+				continue
+			}
 			if metadata.GuildID == "" {
 				logger.WithFields(map[string]any{
 					"group_id":   group.GetId(),
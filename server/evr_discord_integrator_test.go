@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDiscordIntegrator_Disabled(t *testing.T) {
+	ctx := context.Background()
+	d := &DiscordIntegrator{}
+
+	if d.Enabled() {
+		t.Fatal("expected integrator without a session to be disabled")
+	}
+	if name := d.BotUsername(); name != "" {
+		t.Errorf("expected empty bot username, got %q", name)
+	}
+	if _, err := d.GuildMember("guild", "member"); !errors.Is(err, ErrDiscordDisabled) {
+		t.Errorf("expected ErrDiscordDisabled, got %v", err)
+	}
+	if _, err := d.LogServiceAuditMessage(ctx, "message", false); err != nil {
+		t.Errorf("expected audit message to be dropped, got %v", err)
+	}
+
+	// Queueing must not block or panic without the sync worker.
+	d.QueueSyncMember("guild", "member", true)
+
+	if _, err := SendUserMessage(ctx, nil, "member", "message"); !errors.Is(err, ErrDiscordDisabled) {
+		t.Errorf("expected ErrDiscordDisabled, got %v", err)
+	}
+	if err := AuditLogSend(nil, "channel", "message"); err != nil {
+		t.Errorf("expected audit log to be dropped, got %v", err)
+	}
+
+	var appBot *DiscordAppBot
+	if _, err := appBot.LogAuditMessage(ctx, "group", "message", true); err != nil {
+		t.Errorf("expected audit message to be dropped, got %v", err)
+	}
+	if _, err := appBot.LogUserErrorMessage(ctx, "group", "message", true); err != nil {
+		t.Errorf("expected error message to be dropped, got %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+)
+
+const (
+	DocumentTypeEULA          = "eula"
+	DocumentTypeNews          = "news"
+	DocumentTypePatchNotes    = "patch_notes"
+	DocumentTypeCodeOfConduct = "code_of_conduct"
+
+	DocumentDefaultLanguage = "en"
+
+	// The client shows 7 lines of 28 characters.
+	documentPageLines     = 7
+	documentPageLineWidth = 28
+)
+
+var (
+	DocumentTypes = []string{DocumentTypeEULA, DocumentTypeNews, DocumentTypePatchNotes, DocumentTypeCodeOfConduct}
+
+	ErrDocumentNotFound    = errors.New("document not found")
+	ErrDocumentUnknownType = errors.New("unknown document type")
+)
+
+// GameDocument is a stored document, in one language. It is compatible with the stored EULA documents.
+type GameDocument struct {
+	Type             string    `json:"type"`
+	Lang             string    `json:"lang"`
+	Version          int64     `json:"version"`              // Bumped when the text changes. The clients re-prompt for the EULA when it does.
+	VersionGameAdmin int64     `json:"version_ga,omitempty"` // Bumped when the game admin text changes (EULA only).
+	Title            string    `json:"title,omitempty"`
+	Text             string    `json:"text"`
+	TextGameAdmin    string    `json:"text_ga,omitempty"` // EULA only
+	Link             string    `json:"link,omitempty"`
+	UpdatedBy        string    `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+}
+
+func documentStorageKey(_type, language string) string {
+	return fmt.Sprintf("%s,%s", _type, language)
+}
+
+// DocumentLanguageChain returns the languages to look for a document in, most specific first (e.g. pt-BR, pt, en).
+func DocumentLanguageChain(language string) []string {
+	chain := make([]string, 0, 3)
+	language = strings.TrimSpace(strings.ReplaceAll(language, "_", "-"))
+	if language != "" {
+		chain = append(chain, language)
+		if base, _, found := strings.Cut(language, "-"); found && base != "" {
+			chain = append(chain, base)
+		}
+	}
+	if !slices.ContainsFunc(chain, func(l string) bool { return strings.EqualFold(l, DocumentDefaultLanguage) }) {
+		chain = append(chain, DocumentDefaultLanguage)
+	}
+	return chain
+}
+
+// PaginateDocument word wraps the text to the client's line width, and splits it into pages of the client's line count.
+func PaginateDocument(text string) []string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	wrapped := wrap.String(wordwrap.String(text, documentPageLineWidth), documentPageLineWidth)
+	lines := strings.Split(wrapped, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	pages := make([]string, 0, len(lines)/documentPageLines+1)
+	for start := 0; start < len(lines); start += documentPageLines {
+		end := min(start+documentPageLines, len(lines))
+		pages = append(pages, strings.Join(lines[start:end], "\n")+"\n")
+	}
+	return pages
+}
+
+// documentEULAText returns the first page of the EULA. The client only shows one page; if there are more, the
+// last line points to the rest.
+func documentEULAText(text, link string) string {
+	pages := PaginateDocument(text)
+	if len(pages) <= 1 {
+		return pages[0]
+	}
+	lines := strings.Split(strings.TrimRight(pages[0], "\n"), "\n")
+	lines[len(lines)-1] = fmt.Sprintf("(page 1 of %d, see link)", len(pages))
+	if link == "" {
+		lines[len(lines)-1] = fmt.Sprintf("(page 1 of %d)", len(pages))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ParseDocumentType splits a requested type into the type and the page (e.g. "patch_notes:2"). The page defaults to 1.
+func ParseDocumentType(requested string) (string, int, error) {
+	_type, pageStr, hasPage := strings.Cut(strings.ToLower(strings.TrimSpace(requested)), ":")
+	if !slices.Contains(DocumentTypes, _type) {
+		return "", 0, fmt.Errorf("%w: %s", ErrDocumentUnknownType, requested)
+	}
+	page := 1
+	if hasPage {
+		if _, err := fmt.Sscanf(pageStr, "%d", &page); err != nil || page < 1 {
+			return "", 0, fmt.Errorf("invalid page: %s", requested)
+		}
+	}
+	return _type, page, nil
+}
+
+// Effective returns the document with its version set; documents stored before versioning use their update time.
+func (d *GameDocument) effective(updateTime time.Time) *GameDocument {
+	if d.Version == 0 {
+		d.Version = updateTime.Unix()
+	}
+	if d.Type == DocumentTypeEULA && d.VersionGameAdmin == 0 {
+		d.VersionGameAdmin = d.Version
+	}
+	return d
+}
+
+// Update sets the content, and bumps the versions if the text has changed.
+func (d *GameDocument) Update(title, text, textGameAdmin, link, userID string, now time.Time) bool {
+	changed := d.Text != text
+	d.Title, d.Link = title, link
+	if changed {
+		d.Text = text
+		d.Version = max(d.Version+1, now.Unix())
+	}
+	if d.Type == DocumentTypeEULA {
+		if textGameAdmin == "" {
+			textGameAdmin = text
+		}
+		if d.TextGameAdmin != textGameAdmin {
+			d.TextGameAdmin = textGameAdmin
+			d.VersionGameAdmin = max(d.VersionGameAdmin+1, now.Unix())
+			changed = true
+		}
+	}
+	d.UpdatedBy = userID
+	d.UpdatedAt = now
+	return changed
+}
+
+// EVRDocument returns the page of the document for the client.
+func (d *GameDocument) EVRDocument(page int) (evr.Document, error) {
+	if d.Type == DocumentTypeEULA {
+		if page != 1 {
+			// The rest of the EULA is served as a text document.
+			return d.textDocument(page)
+		}
+		link := d.Link
+		if link == "" {
+			link = "https://github.com/EchoTools"
+		}
+		document := evr.NewEULADocument(0, 0, d.Lang, link, documentEULAText(d.Text, d.Link))
+		document.TextGameAdmin = documentEULAText(d.TextGameAdmin, d.Link)
+		document.Version = d.Version
+		document.VersionGameAdmin = d.VersionGameAdmin
+		return document, nil
+	}
+	return d.textDocument(page)
+}
+
+func (d *GameDocument) textDocument(page int) (evr.Document, error) {
+	pages := PaginateDocument(d.Text)
+	if page < 1 || page > len(pages) {
+		return nil, fmt.Errorf("page %d of %d: %w", page, len(pages), ErrDocumentNotFound)
+	}
+	return evr.TextDocument{
+		Type:      d.Type,
+		Lang:      d.Lang,
+		Version:   d.Version,
+		Title:     d.Title,
+		Text:      pages[page-1],
+		Link:      d.Link,
+		Page:      page,
+		PageCount: len(pages),
+	}, nil
+}
+
+// defaultGameDocument is served when no language of the chain has the document.
+func defaultGameDocument(_type, language string) (*GameDocument, bool) {
+	if _type != DocumentTypeEULA {
+		return nil, false
+	}
+	eula := evr.DefaultEULADocument(language)
+	return &GameDocument{
+		Type:          _type,
+		Lang:          language,
+		Text:          eula.Text,
+		TextGameAdmin: eula.TextGameAdmin,
+	}, true
+}
+
+// GameDocumentRead returns the document in the first language of the chain that has it, and its storage version ("*" if it does not exist).
+func GameDocumentRead(ctx context.Context, nk runtime.NakamaModule, _type string, languages []string) (*GameDocument, string, error) {
+	reads := make([]*runtime.StorageRead, 0, len(languages))
+	for _, l := range languages {
+		reads = append(reads, &runtime.StorageRead{
+			Collection: DocumentStorageCollection,
+			Key:        documentStorageKey(_type, l),
+			UserID:     SystemUserID,
+		})
+	}
+	objs, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read document: %w", err)
+	}
+	for _, l := range languages {
+		for _, obj := range objs {
+			if obj.Key != documentStorageKey(_type, l) {
+				continue
+			}
+			document := &GameDocument{}
+			if err := json.Unmarshal([]byte(obj.Value), document); err != nil {
+				return nil, "", fmt.Errorf("failed to unmarshal document: %w", err)
+			}
+			document.Type, document.Lang = _type, l
+			return document.effective(obj.UpdateTime.AsTime().UTC()), obj.Version, nil
+		}
+	}
+	if document, ok := defaultGameDocument(_type, languages[0]); ok {
+		return document, "*", nil
+	}
+	return nil, "*", ErrDocumentNotFound
+}
+
+// GameDocumentWrite stores the document, if it has not changed since it was read.
+func GameDocumentWrite(ctx context.Context, nk runtime.NakamaModule, document *GameDocument, version string) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      DocumentStorageCollection,
+		Key:             documentStorageKey(document.Type, document.Lang),
+		UserID:          SystemUserID,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	return err
+}
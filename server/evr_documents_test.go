@@ -0,0 +1,109 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentLanguageChain(t *testing.T) {
+	assert.Equal(t, []string{"pt-BR", "pt", "en"}, DocumentLanguageChain("pt-BR"))
+	assert.Equal(t, []string{"pt-BR", "pt", "en"}, DocumentLanguageChain("pt_BR"))
+	assert.Equal(t, []string{"fr", "en"}, DocumentLanguageChain("fr"))
+	assert.Equal(t, []string{"en"}, DocumentLanguageChain("en"))
+	assert.Equal(t, []string{"en-GB", "en"}, DocumentLanguageChain("en-GB"))
+	assert.Equal(t, []string{"en"}, DocumentLanguageChain(""))
+}
+
+func TestPaginateDocument(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10) + "\nA verylongwordthatdoesnotfitonasingleline."
+	pages := PaginateDocument(text)
+	require.Greater(t, len(pages), 1, "nothing is truncated")
+
+	words := make([]string, 0)
+	for i, page := range pages {
+		lines := strings.Split(strings.TrimSuffix(page, "\n"), "\n")
+		assert.LessOrEqual(t, len(lines), documentPageLines, "page %d", i+1)
+		for _, line := range lines {
+			assert.LessOrEqual(t, len(line), documentPageLineWidth, "line %q", line)
+		}
+		words = append(words, strings.Fields(page)...)
+	}
+	assert.Equal(t, strings.Join(strings.Fields(text), ""), strings.Join(words, ""), "all of the text is paginated")
+
+	assert.Len(t, PaginateDocument("short"), 1)
+}
+
+func TestParseDocumentType(t *testing.T) {
+	_type, page, err := ParseDocumentType("eula")
+	require.NoError(t, err)
+	assert.Equal(t, DocumentTypeEULA, _type)
+	assert.Equal(t, 1, page)
+
+	_type, page, err = ParseDocumentType("patch_notes:3")
+	require.NoError(t, err)
+	assert.Equal(t, DocumentTypePatchNotes, _type)
+	assert.Equal(t, 3, page)
+
+	_, _, err = ParseDocumentType("unknown")
+	assert.ErrorIs(t, err, ErrDocumentUnknownType)
+	_, _, err = ParseDocumentType("news:0")
+	assert.Error(t, err)
+}
+
+func TestGameDocument_Update(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	document := &GameDocument{Type: DocumentTypeEULA, Lang: "en"}
+
+	assert.True(t, document.Update("", "Terms", "", "", "user-1", now))
+	assert.Equal(t, now.Unix(), document.Version)
+	assert.Equal(t, now.Unix(), document.VersionGameAdmin)
+	assert.Equal(t, "Terms", document.TextGameAdmin)
+
+	// Only a change of the text bumps the version, so that players are not asked to accept it again.
+	later := now.Add(time.Hour)
+	assert.False(t, document.Update("Title", "Terms", "", "https://example.com", "user-2", later))
+	assert.Equal(t, now.Unix(), document.Version)
+	assert.Equal(t, "https://example.com", document.Link)
+	assert.Equal(t, "user-2", document.UpdatedBy)
+
+	assert.True(t, document.Update("Title", "New terms", "", "", "user-2", later))
+	assert.Equal(t, later.Unix(), document.Version)
+
+	// The version increases even if the clock does not.
+	assert.True(t, document.Update("Title", "Newer terms", "", "", "user-2", later))
+	assert.Equal(t, later.Unix()+1, document.Version)
+}
+
+func TestGameDocument_EVRDocument(t *testing.T) {
+	document := &GameDocument{
+		Type:          DocumentTypeEULA,
+		Lang:          "en",
+		Version:       10,
+		Text:          strings.Repeat("word ", 100),
+		TextGameAdmin: "Game admin terms",
+	}
+
+	d, err := document.EVRDocument(1)
+	require.NoError(t, err)
+	eula, ok := d.(evr.EULADocument)
+	require.True(t, ok)
+	assert.Equal(t, int64(10), eula.Version)
+	lines := strings.Split(strings.TrimSuffix(eula.Text, "\n"), "\n")
+	assert.Len(t, lines, documentPageLines)
+	assert.Contains(t, lines[len(lines)-1], "page 1 of")
+
+	d, err = document.EVRDocument(2)
+	require.NoError(t, err)
+	text, ok := d.(evr.TextDocument)
+	require.True(t, ok)
+	assert.Equal(t, 2, text.Page)
+	assert.Equal(t, len(PaginateDocument(document.Text)), text.PageCount)
+
+	_, err = document.EVRDocument(100)
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+}
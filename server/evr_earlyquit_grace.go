@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	EarlyQuitDecisionPenalized   = "penalized"
+	EarlyQuitDecisionReconnected = "reconnected"
+	EarlyQuitDecisionServerFault = "server_fault"
+
+	defaultEarlyQuitReconnectGraceSecs  = 120
+	defaultEarlyQuitLobbyDropWindowSecs = 10
+	defaultEarlyQuitLobbyDropFraction   = 0.75
+
+	maxEarlyQuitDecisions = 10
+)
+
+// EarlyQuitDecision is the outcome of a player leaving a match in progress, kept for moderators.
+type EarlyQuitDecision struct {
+	MatchID   MatchID   `json:"match_id"`
+	LeftAt    time.Time `json:"left_at"`
+	DecidedAt time.Time `json:"decided_at"`
+	Decision  string    `json:"decision"` // penalized, reconnected or server_fault
+	Reason    string    `json:"reason"`
+}
+
+// MatchServerFaultReport is sent to the match when a client reports that it lost the game server.
+type MatchServerFaultReport struct {
+	Reason string `json:"reason"`
+}
+
+// earlyQuitDeparture is a player that left a match in progress. Their slot is held, and the
+// penalty is decided when the reconnect window closes.
+type earlyQuitDeparture struct {
+	Presence      *EvrMatchPresence
+	LeftAt        time.Time
+	Deadline      time.Time
+	LeaveReason   string
+	PlayersBefore int // The players in the match when they left, including them.
+}
+
+type earlyQuitGraceSettings struct {
+	ReconnectGrace  time.Duration
+	LobbyDropWindow time.Duration
+	LobbyDropFrac   float64
+}
+
+func currentEarlyQuitGraceSettings() earlyQuitGraceSettings {
+	s := earlyQuitGraceSettings{
+		ReconnectGrace:  defaultEarlyQuitReconnectGraceSecs * time.Second,
+		LobbyDropWindow: defaultEarlyQuitLobbyDropWindowSecs * time.Second,
+		LobbyDropFrac:   defaultEarlyQuitLobbyDropFraction,
+	}
+	if g := ServiceSettings(); g != nil {
+		if g.Matchmaking.EarlyQuitReconnectGraceSecs > 0 {
+			s.ReconnectGrace = time.Duration(g.Matchmaking.EarlyQuitReconnectGraceSecs) * time.Second
+		}
+		if g.Matchmaking.EarlyQuitLobbyDropWindowSecs > 0 {
+			s.LobbyDropWindow = time.Duration(g.Matchmaking.EarlyQuitLobbyDropWindowSecs) * time.Second
+		}
+		if g.Matchmaking.EarlyQuitLobbyDropFraction > 0 {
+			s.LobbyDropFrac = g.Matchmaking.EarlyQuitLobbyDropFraction
+		}
+	}
+	return s
+}
+
+// RecordDecision keeps the decision, and drops the oldest beyond the limit.
+func (s *EarlyQuitConfig) RecordDecision(d EarlyQuitDecision) {
+	s.Lock()
+	defer s.Unlock()
+	s.RecentDecisions = append(s.RecentDecisions, d)
+	if n := len(s.RecentDecisions); n > maxEarlyQuitDecisions {
+		s.RecentDecisions = s.RecentDecisions[n-maxEarlyQuitDecisions:]
+	}
+}
+
+// holdEarlyQuitSlot records the departure of a player from a match in progress, and holds their slot for the reconnect window.
+func (s *MatchLabel) holdEarlyQuitSlot(mp *EvrMatchPresence, leaveReason string, now time.Time, grace time.Duration) {
+	if s.departures == nil {
+		s.departures = make(map[string]*earlyQuitDeparture)
+	}
+	players := 0
+	for _, p := range s.presenceMap {
+		if p.IsPlayer() {
+			players++
+		}
+	}
+	d := &earlyQuitDeparture{
+		Presence:      mp,
+		LeftAt:        now,
+		Deadline:      now.Add(grace),
+		LeaveReason:   leaveReason,
+		PlayersBefore: players,
+	}
+	if prev, ok := s.departures[mp.GetUserId()]; ok {
+		// Keep the first departure; the window does not restart.
+		d.LeftAt, d.Deadline = prev.LeftAt, prev.Deadline
+	}
+	s.departures[mp.GetUserId()] = d
+	s.departureTimes = append(s.departureTimes, now)
+
+	if s.reservationMap != nil {
+		s.reservationMap[mp.GetSessionId()] = &slotReservation{
+			Presence: mp,
+			Expiry:   d.Deadline,
+		}
+		s.rebuildCache()
+	}
+}
+
+// heldEarlyQuitSlot returns the departure of a player whose slot is still held for them.
+func (s *MatchLabel) heldEarlyQuitSlot(userID string) (*earlyQuitDeparture, bool) {
+	d, ok := s.departures[userID]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := s.reservationMap[d.Presence.GetSessionId()]; !ok {
+		return nil, false
+	}
+	return d, true
+}
+
+// releaseEarlyQuitSlot releases the slot held for a player whose rejoin has been accepted. The departure stays
+// pending until the player has joined.
+func (s *MatchLabel) releaseEarlyQuitSlot(userID string) (*earlyQuitDeparture, bool) {
+	d, ok := s.departures[userID]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := s.reservationMap[d.Presence.GetSessionId()]; ok {
+		delete(s.reservationMap, d.Presence.GetSessionId())
+		s.rebuildCache()
+	}
+	return d, true
+}
+
+// rejoinedEarlyQuit returns the pending departure of a player that has rejoined within the reconnect window.
+func (s *MatchLabel) rejoinedEarlyQuit(userID string, now time.Time) (*earlyQuitDeparture, bool) {
+	d, ok := s.departures[userID]
+	if !ok || now.After(d.Deadline) {
+		return nil, false
+	}
+	delete(s.departures, userID)
+	return d, true
+}
+
+// dueEarlyQuits removes and returns the departures whose reconnect window has closed, or all of them.
+func (s *MatchLabel) dueEarlyQuits(now time.Time, all bool) []*earlyQuitDeparture {
+	due := make([]*earlyQuitDeparture, 0)
+	for userID, d := range s.departures {
+		if all || now.After(d.Deadline) {
+			due = append(due, d)
+			delete(s.departures, userID)
+		}
+	}
+	return due
+}
+
+// reportServerFault records a client's report that it lost the game server. On its own, it does not exempt
+// the player; the server has to see the fault too.
+func (s *MatchLabel) reportServerFault(userID, reason string) {
+	if s.serverFaults == nil {
+		s.serverFaults = make(map[string]string)
+	}
+	s.serverFaults[userID] = reason
+}
+
+// observeServerHealth records the game server's failed health checks, from the fleet registry.
+func (s *MatchLabel) observeServerHealth(registry *GameServerRegistry) {
+	if registry == nil || s.GameServer == nil {
+		return
+	}
+	at, ok := registry.LastFailedCheck(s.GameServer.SessionID)
+	if !ok {
+		return
+	}
+	if n := len(s.serverCheckFailures); n > 0 && !at.After(s.serverCheckFailures[n-1]) {
+		return
+	}
+	s.serverCheckFailures = append(s.serverCheckFailures, at)
+}
+
+// earlyQuitServerFault returns why the departure was the server's fault, if it was.
+func (s *MatchLabel) earlyQuitServerFault(d *earlyQuitDeparture, settings earlyQuitGraceSettings) (string, bool) {
+	return s.serverFault(d.Presence.GetUserId(), d.LeftAt, d.PlayersBefore, settings)
+}
+
+// serverFault returns why a player leaving at the time was the server's fault, if it was, from what is known so far.
+// playersBefore is the number of players in the match when they left, including them. Only what the server
+// sees counts; a client's report is only noted.
+func (s *MatchLabel) serverFault(userID string, leftAt time.Time, playersBefore int, settings earlyQuitGraceSettings) (string, bool) {
+	note := ""
+	if reported, ok := s.serverFaults[userID]; ok {
+		note = " (client reported: " + reported + ")"
+	}
+
+	if !s.serverLostAt.IsZero() && !s.serverLostAt.After(leftAt.Add(settings.LobbyDropWindow)) {
+		return "the game server disconnected" + note, true
+	}
+
+	for _, t := range s.serverCheckFailures {
+		if delta := t.Sub(leftAt); delta >= -settings.LobbyDropWindow && delta <= settings.LobbyDropWindow {
+			return "the game server failed a health check" + note, true
+		}
+	}
+
+	// The whole lobby dropping at once is a server problem, not a quit.
+	count := 0
+	for _, t := range s.departureTimes {
+		if delta := t.Sub(leftAt); delta >= -settings.LobbyDropWindow && delta <= settings.LobbyDropWindow {
+			count++
+		}
+	}
+	needed := max(2, int(math.Ceil(float64(playersBefore)*settings.LobbyDropFrac)))
+	if count >= needed {
+		return fmt.Sprintf("lobby drop: %d of %d players left within %s", count, playersBefore, FormatDuration(settings.LobbyDropWindow)) + note, true
+	}
+	return "", false
+}
+
+// decideEarlyQuit decides the penalty of a departure whose reconnect window has closed, or that was followed by a rejoin.
+// A departure whose window is still open when the match ends is penalized unless the game server was lost.
+func (s *MatchLabel) decideEarlyQuit(d *earlyQuitDeparture, rejoinedAt time.Time, settings earlyQuitGraceSettings, now time.Time) EarlyQuitDecision {
+	decision := EarlyQuitDecision{
+		MatchID:   s.ID,
+		LeftAt:    d.LeftAt,
+		DecidedAt: now,
+	}
+	switch reason, isFault := s.earlyQuitServerFault(d, settings); {
+	case !rejoinedAt.IsZero():
+		decision.Decision = EarlyQuitDecisionReconnected
+		decision.Reason = fmt.Sprintf("rejoined after %s (window %s)", FormatDuration(rejoinedAt.Sub(d.LeftAt)), FormatDuration(settings.ReconnectGrace))
+	case isFault:
+		decision.Decision = EarlyQuitDecisionServerFault
+		decision.Reason = reason
+	case now.Before(d.Deadline) && !s.serverLostAt.IsZero():
+		// The match is ending before the player could rejoin.
+		decision.Decision = EarlyQuitDecisionServerFault
+		decision.Reason = fmt.Sprintf("the game server disconnected %s into the reconnect window", FormatDuration(s.serverLostAt.Sub(d.LeftAt)))
+	case now.Before(d.Deadline):
+		// The player did not return before the match ended.
+		decision.Decision = EarlyQuitDecisionPenalized
+		decision.Reason = fmt.Sprintf("left (%s) and did not rejoin before the match ended %s later", d.LeaveReason, FormatDuration(now.Sub(d.LeftAt)))
+	default:
+		decision.Decision = EarlyQuitDecisionPenalized
+		decision.Reason = fmt.Sprintf("left (%s) and did not rejoin within %s", d.LeaveReason, FormatDuration(settings.ReconnectGrace))
+	}
+	if decision.Decision == EarlyQuitDecisionPenalized {
+		if reported, ok := s.serverFaults[d.Presence.GetUserId()]; ok {
+			decision.Reason += fmt.Sprintf("; the client reported %q, which the server did not see", reported)
+		}
+	}
+	return decision
+}
+
+// decideEarlyQuits decides the penalties whose reconnect window has closed, or all of them when the match is ending.
+func (m *EvrMatch) decideEarlyQuits(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, state *MatchLabel, all bool) {
+	now := time.Now().UTC()
+	settings := currentEarlyQuitGraceSettings()
+	for _, d := range state.dueEarlyQuits(now, all) {
+		m.applyEarlyQuitDecision(ctx, logger, db, nk, state, d, state.decideEarlyQuit(d, time.Time{}, settings, now))
+	}
+}
+
+// applyEarlyQuitDecision penalizes the player, if decided, and records the decision in their early quit config.
+func (m *EvrMatch) applyEarlyQuitDecision(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, state *MatchLabel, d *earlyQuitDeparture, decision EarlyQuitDecision) {
+	mp := d.Presence
+	logger = logger.WithFields(map[string]any{
+		"uid":          mp.GetUserId(),
+		"username":     mp.Username,
+		"evrid":        mp.EvrID,
+		"display_name": mp.DisplayName,
+		"decision":     decision.Decision,
+		"reason":       decision.Reason,
+	})
+	logger.Info("Early quit decision.")
+
+	tags := map[string]string{
+		"mode":     state.Mode.String(),
+		"level":    state.Level.String(),
+		"type":     state.LobbyType.String(),
+		"group_id": state.GetGroupID().String(),
+		"decision": decision.Decision,
+	}
+	nk.MetricsCounterAdd("match_entrant_early_quit_decision", tags, 1)
+
+	penalized := decision.Decision == EarlyQuitDecisionPenalized
+	if penalized {
+		nk.MetricsCounterAdd("match_entrant_early_quit", tags, 1)
+		if err := AccumulateLeaderboardStat(ctx, nk, mp.GetUserId(), mp.DisplayName, state.GetGroupID().String(), state.Mode, EarlyQuitStatisticID, 1); err != nil {
+			logger.Warn("Failed to record early quit to leaderboard: %v", err)
+		}
+	} else if p, ok := state.roster[mp.GetUserId()]; ok {
+		p.EarlyQuit = false
+	}
+	if decision.Decision == EarlyQuitDecisionServerFault && state.telemetry != nil {
+		state.telemetry.ServerFault(mp.GetUserId(), d.LeftAt)
+	}
+
+	eqconfig := NewEarlyQuitConfig()
+	if err := StorableRead(ctx, nk, mp.GetUserId(), eqconfig, true); err != nil {
+		logger.WithField("error", err).Warn("Failed to load early quitter config")
+		return
+	}
+
+	eqconfig.RecordDecision(decision)
+
+	var oldTier, newTier int32
+	var tierChanged bool
+	if penalized {
+		eqconfig.IncrementEarlyQuit()
+
+		// Check for tier change after early quit
+		var threshold *int32
+		if s := ServiceSettings(); s != nil {
+			threshold = s.Matchmaking.EarlyQuitTier1Threshold
+		}
+		oldTier, newTier, tierChanged = eqconfig.UpdateTier(threshold)
+
+		logger.WithFields(map[string]any{
+			"old_tier":     oldTier,
+			"new_tier":     newTier,
+			"tier_changed": tierChanged,
+			"eqconfig":     eqconfig,
+		}).Debug("Early quitter tier update.")
+	}
+
+	if err := StorableWrite(ctx, nk, mp.GetUserId(), eqconfig); err != nil {
+		logger.WithField("error", err).Warn("Failed to write early quitter config")
+		return
+	}
+
+	if _nk, ok := nk.(*RuntimeGoNakamaModule); ok {
+		if s := _nk.sessionRegistry.Get(uuid.FromStringOrNil(mp.GetSessionId())); s != nil {
+			if params, ok := LoadParams(s.Context()); ok {
+				params.earlyQuitConfig.Store(eqconfig)
+			}
+		}
+	}
+
+	// Send Discord DM if tier changed
+	if tierChanged {
+		discordID, err := GetDiscordIDByUserID(ctx, db, mp.GetUserId())
+		if err != nil {
+			logger.WithField("error", err).Warn("Failed to get Discord ID for tier notification")
+		} else if appBot := globalAppBot.Load(); appBot != nil && appBot.dg != nil {
+			message := TierRestoredMessage
+			if oldTier < newTier {
+				message = TierDegradedMessage
+			}
+			if _, err := SendUserMessage(ctx, appBot.dg, discordID, message); err != nil {
+				logger.WithField("error", err).Warn("Failed to send tier change DM")
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+func newEarlyQuitGraceTestLabel(players int) (*MatchLabel, []*EvrMatchPresence) {
+	state := &MatchLabel{
+		Mode:           evr.ModeArenaPublic,
+		presenceMap:    make(map[string]*EvrMatchPresence),
+		reservationMap: make(map[string]*slotReservation),
+	}
+	presences := make([]*EvrMatchPresence, 0, players)
+	for i := 0; i < players; i++ {
+		mp := &EvrMatchPresence{
+			SessionID:     uuid.Must(uuid.NewV4()),
+			UserID:        uuid.Must(uuid.NewV4()),
+			RoleAlignment: evr.TeamBlue + i%2,
+		}
+		state.presenceMap[mp.GetSessionId()] = mp
+		presences = append(presences, mp)
+	}
+	return state, presences
+}
+
+func testEarlyQuitGraceSettings() earlyQuitGraceSettings {
+	return earlyQuitGraceSettings{
+		ReconnectGrace:  2 * time.Minute,
+		LobbyDropWindow: 10 * time.Second,
+		LobbyDropFrac:   0.75,
+	}
+}
+
+// leave removes the player from the match, as MatchLeave does.
+func leave(state *MatchLabel, mp *EvrMatchPresence, now time.Time, settings earlyQuitGraceSettings) {
+	state.holdEarlyQuitSlot(mp, "disconnect", now, settings.ReconnectGrace)
+	delete(state.presenceMap, mp.GetSessionId())
+}
+
+func TestMatchLabel_EarlyQuitReconnectWindow(t *testing.T) {
+	settings := testEarlyQuitGraceSettings()
+	state, presences := newEarlyQuitGraceTestLabel(8)
+	now := time.Now().UTC()
+	mp := presences[0]
+
+	leave(state, mp, now, settings)
+	if _, ok := state.reservationMap[mp.GetSessionId()]; !ok {
+		t.Fatal("the player's slot should be held")
+	}
+	if due := state.dueEarlyQuits(now.Add(time.Minute), false); len(due) != 0 {
+		t.Fatalf("dueEarlyQuits() = %d, want none within the window", len(due))
+	}
+
+	// Rejoining releases the slot, and exempts the player.
+	if _, ok := state.heldEarlyQuitSlot(mp.GetUserId()); !ok {
+		t.Fatal("heldEarlyQuitSlot() found no held slot")
+	}
+	if _, ok := state.releaseEarlyQuitSlot(mp.GetUserId()); !ok {
+		t.Fatal("releaseEarlyQuitSlot() found no departure")
+	}
+	if _, ok := state.reservationMap[mp.GetSessionId()]; ok {
+		t.Error("the held slot should be released")
+	}
+	rejoinedAt := now.Add(30 * time.Second)
+	d, ok := state.rejoinedEarlyQuit(mp.GetUserId(), rejoinedAt)
+	if !ok {
+		t.Fatal("rejoinedEarlyQuit() found no departure")
+	}
+	decision := state.decideEarlyQuit(d, rejoinedAt, settings, rejoinedAt)
+	if decision.Decision != EarlyQuitDecisionReconnected || !strings.Contains(decision.Reason, "30s") {
+		t.Errorf("decideEarlyQuit() = %+v, want reconnected after 30s", decision)
+	}
+	if len(state.departures) != 0 {
+		t.Error("the departure should be resolved")
+	}
+
+	// Not rejoining is penalized when the window closes.
+	mp = presences[1]
+	leave(state, mp, now, settings)
+	if _, ok := state.rejoinedEarlyQuit(mp.GetUserId(), now.Add(3*time.Minute)); ok {
+		t.Error("rejoinedEarlyQuit() after the window should not be a reconnect")
+	}
+	due := state.dueEarlyQuits(now.Add(3*time.Minute), false)
+	if len(due) != 1 {
+		t.Fatalf("dueEarlyQuits() = %d, want 1", len(due))
+	}
+	if decision := state.decideEarlyQuit(due[0], time.Time{}, settings, now.Add(3*time.Minute)); decision.Decision != EarlyQuitDecisionPenalized {
+		t.Errorf("decideEarlyQuit() = %+v, want penalized", decision)
+	}
+}
+
+func TestMatchLabel_EarlyQuitServerFault(t *testing.T) {
+	settings := testEarlyQuitGraceSettings()
+	now := time.Now().UTC()
+
+	closed := now.Add(3 * time.Minute)
+
+	t.Run("client reported timeout", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		leave(state, presences[0], now, settings)
+		state.reportServerFault(presences[0].GetUserId(), "disconnected due to timeout")
+		d := state.dueEarlyQuits(closed, false)[0]
+		decision := state.decideEarlyQuit(d, time.Time{}, settings, closed)
+		if decision.Decision != EarlyQuitDecisionPenalized || !strings.Contains(decision.Reason, "disconnected due to timeout") {
+			t.Errorf("decideEarlyQuit() = %+v, want penalized, noting the report", decision)
+		}
+	})
+
+	t.Run("client reported timeout and failed health check", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		leave(state, presences[0], now, settings)
+		state.reportServerFault(presences[0].GetUserId(), "disconnected due to timeout")
+		state.serverCheckFailures = []time.Time{now.Add(3 * time.Second)}
+		d := state.dueEarlyQuits(closed, false)[0]
+		if decision := state.decideEarlyQuit(d, time.Time{}, settings, closed); decision.Decision != EarlyQuitDecisionServerFault {
+			t.Errorf("decideEarlyQuit() = %+v, want server_fault", decision)
+		}
+	})
+
+	t.Run("game server lost", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		leave(state, presences[0], now, settings)
+		state.serverLostAt = now.Add(5 * time.Second)
+		d := state.dueEarlyQuits(now, true)[0]
+		if decision := state.decideEarlyQuit(d, time.Time{}, settings, now); decision.Decision != EarlyQuitDecisionServerFault {
+			t.Errorf("decideEarlyQuit() = %+v, want server_fault", decision)
+		}
+	})
+
+	t.Run("game server lost much later", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		leave(state, presences[0], now, settings)
+		state.serverLostAt = now.Add(3 * time.Minute)
+		d := state.dueEarlyQuits(closed, true)[0]
+		if decision := state.decideEarlyQuit(d, time.Time{}, settings, closed); decision.Decision != EarlyQuitDecisionPenalized {
+			t.Errorf("decideEarlyQuit() = %+v, want penalized", decision)
+		}
+	})
+
+	t.Run("lobby drop", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		for i, mp := range presences[:6] {
+			leave(state, mp, now.Add(time.Duration(i)*time.Second), settings)
+		}
+		for _, d := range state.dueEarlyQuits(now, true) {
+			if decision := state.decideEarlyQuit(d, time.Time{}, settings, now); decision.Decision != EarlyQuitDecisionServerFault {
+				t.Errorf("decideEarlyQuit() = %+v, want server_fault", decision)
+			}
+		}
+	})
+
+	t.Run("two players quitting is not a lobby drop", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		leave(state, presences[0], now, settings)
+		leave(state, presences[1], now.Add(time.Second), settings)
+		for _, d := range state.dueEarlyQuits(closed, false) {
+			if decision := state.decideEarlyQuit(d, time.Time{}, settings, closed); decision.Decision != EarlyQuitDecisionPenalized {
+				t.Errorf("decideEarlyQuit() = %+v, want penalized", decision)
+			}
+		}
+	})
+
+	t.Run("match ending within the reconnect window", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		leave(state, presences[0], now, settings)
+		ended := now.Add(time.Minute)
+		d := state.dueEarlyQuits(ended, true)[0]
+		if decision := state.decideEarlyQuit(d, time.Time{}, settings, ended); decision.Decision != EarlyQuitDecisionPenalized {
+			t.Errorf("decideEarlyQuit() = %+v, want penalized", decision)
+		}
+
+		leave(state, presences[1], now, settings)
+		state.serverLostAt = ended
+		d = state.dueEarlyQuits(ended, true)[0]
+		if decision := state.decideEarlyQuit(d, time.Time{}, settings, ended); decision.Decision != EarlyQuitDecisionServerFault {
+			t.Errorf("decideEarlyQuit() = %+v, want server_fault", decision)
+		}
+	})
+
+	t.Run("quitting in the final window", func(t *testing.T) {
+		state, presences := newEarlyQuitGraceTestLabel(8)
+		ended := now.Add(10 * time.Minute)
+		leave(state, presences[0], ended.Add(-10*time.Second), settings)
+		state.reportServerFault(presences[0].GetUserId(), "disconnected due to timeout")
+		d := state.dueEarlyQuits(ended, true)[0]
+		decision := state.decideEarlyQuit(d, time.Time{}, settings, ended)
+		if decision.Decision != EarlyQuitDecisionPenalized || !strings.Contains(decision.Reason, "disconnected due to timeout") {
+			t.Errorf("decideEarlyQuit() = %+v, want penalized, noting the report", decision)
+		}
+	})
+}
+
+func TestEarlyQuitConfig_RecordDecision(t *testing.T) {
+	config := NewEarlyQuitConfig()
+	for i := 0; i < maxEarlyQuitDecisions+3; i++ {
+		config.RecordDecision(EarlyQuitDecision{Decision: EarlyQuitDecisionPenalized, Reason: strings.Repeat("x", i)})
+	}
+	if len(config.RecentDecisions) != maxEarlyQuitDecisions {
+		t.Fatalf("RecentDecisions = %d, want %d", len(config.RecentDecisions), maxEarlyQuitDecisions)
+	}
+	if got := len(config.RecentDecisions[0].Reason); got != 3 {
+		t.Errorf("oldest kept decision = %d, want the oldest dropped", got)
+	}
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GuildEnforcementAppealStatus string
+
+const (
+	AppealStatusSubmitted   GuildEnforcementAppealStatus = "submitted"
+	AppealStatusUnderReview GuildEnforcementAppealStatus = "under_review"
+	AppealStatusUpheld      GuildEnforcementAppealStatus = "upheld"
+	AppealStatusReduced     GuildEnforcementAppealStatus = "reduced"
+	AppealStatusOverturned  GuildEnforcementAppealStatus = "overturned"
+
+	appealStatementMaxLength = 1000 // In characters, as Discord counts them in the modal.
+)
+
+var (
+	ErrAppealRecordNotFound   = errors.New("enforcement record not found")
+	ErrAppealNotSuspension    = errors.New("only active suspensions can be appealed")
+	ErrAppealExists           = errors.New("the suspension has already been appealed")
+	ErrAppealNotFound         = errors.New("appeal not found")
+	ErrAppealResolved         = errors.New("the appeal has already been resolved")
+	ErrAppealSelfReview       = errors.New("players cannot review their own appeals")
+	ErrAppealInvalidOutcome   = errors.New("the outcome must be upheld, reduced or overturned")
+	ErrAppealInvalidReduction = errors.New("a reduced suspension must end after it started and before it was due to expire")
+)
+
+// IsResolved returns true if the appeal has an outcome.
+func (s GuildEnforcementAppealStatus) IsResolved() bool {
+	return s == AppealStatusUpheld || s == AppealStatusReduced || s == AppealStatusOverturned
+}
+
+// Text returns the status as shown to players.
+func (s GuildEnforcementAppealStatus) Text() string {
+	switch s {
+	case AppealStatusUnderReview:
+		return "under review"
+	default:
+		return string(s)
+	}
+}
+
+// GuildEnforcementAppeal is a player's appeal of a suspension, stored in their journal next to the record.
+type GuildEnforcementAppeal struct {
+	GroupID           string                       `json:"group_id"`
+	RecordID          string                       `json:"record_id"`
+	Status            GuildEnforcementAppealStatus `json:"status"`
+	Statement         string                       `json:"statement"` // The player's statement
+	SubmittedAt       time.Time                    `json:"submitted_at"`
+	ReviewerUserID    string                       `json:"reviewer_user_id,omitempty"`
+	ReviewerDiscordID string                       `json:"reviewer_discord_id,omitempty"`
+	AssignedAt        time.Time                    `json:"assigned_at,omitempty"`
+	ResolvedAt        time.Time                    `json:"resolved_at,omitempty"`
+	ReviewerNotes     string                       `json:"reviewer_notes,omitempty"`
+	OriginalExpiry    time.Time                    `json:"original_expiry,omitempty"` // The expiry before a reduction
+}
+
+func (s *GuildEnforcementJournal) GetAppeal(groupID, recordID string) (appeal GuildEnforcementAppeal, found bool) {
+	if s.AppealsByRecordIDByGroupID == nil {
+		return appeal, false
+	}
+	appeal, found = s.AppealsByRecordIDByGroupID[groupID][recordID]
+	return appeal, found
+}
+
+// Appeals returns the appeals of the journal, by record ID.
+func (s *GuildEnforcementJournal) Appeals() map[string]GuildEnforcementAppeal {
+	appeals := make(map[string]GuildEnforcementAppeal)
+	for _, byRecordID := range s.AppealsByRecordIDByGroupID {
+		for recordID, a := range byRecordID {
+			appeals[recordID] = a
+		}
+	}
+	return appeals
+}
+
+// LatestOpenAppeal returns the most recently submitted appeal in the group that has not been resolved.
+func (s *GuildEnforcementJournal) LatestOpenAppeal(groupID string) (GuildEnforcementAppeal, bool) {
+	var latest GuildEnforcementAppeal
+	for _, a := range s.AppealsByRecordIDByGroupID[groupID] {
+		if !a.Status.IsResolved() && a.SubmittedAt.After(latest.SubmittedAt) {
+			latest = a
+		}
+	}
+	return latest, latest.RecordID != ""
+}
+
+func (s *GuildEnforcementJournal) setAppeal(appeal GuildEnforcementAppeal) {
+	if s.AppealsByRecordIDByGroupID == nil {
+		s.AppealsByRecordIDByGroupID = make(map[string]map[string]GuildEnforcementAppeal)
+	}
+	if s.AppealsByRecordIDByGroupID[appeal.GroupID] == nil {
+		s.AppealsByRecordIDByGroupID[appeal.GroupID] = make(map[string]GuildEnforcementAppeal)
+	}
+	s.AppealsByRecordIDByGroupID[appeal.GroupID][appeal.RecordID] = appeal
+}
+
+func (s *GuildEnforcementJournal) recordIndex(groupID, recordID string) int {
+	for i, r := range s.GroupRecords(groupID) {
+		if r.ID == recordID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Record returns the record with the given ID.
+func (s *GuildEnforcementJournal) Record(groupID, recordID string) (GuildEnforcementRecord, bool) {
+	if idx := s.recordIndex(groupID, recordID); idx >= 0 {
+		return s.RecordsByGroupID[groupID][idx], true
+	}
+	return GuildEnforcementRecord{}, false
+}
+
+// LatestAppealableRecord returns the active suspension in the group that expires last.
+func (s *GuildEnforcementJournal) LatestAppealableRecord(groupID string) (GuildEnforcementRecord, bool) {
+	var latest GuildEnforcementRecord
+	for _, r := range s.GroupRecords(groupID) {
+		if r.IsSuspension() && !r.IsExpired() && !s.IsVoid(groupID, r.ID) && r.Expiry.After(latest.Expiry) {
+			latest = r
+		}
+	}
+	return latest, latest.ID != ""
+}
+
+// SubmitAppeal files an appeal of an active suspension. Each suspension can be appealed once.
+func (s *GuildEnforcementJournal) SubmitAppeal(groupID, recordID, statement string) (GuildEnforcementAppeal, error) {
+	idx := s.recordIndex(groupID, recordID)
+	if idx < 0 {
+		return GuildEnforcementAppeal{}, ErrAppealRecordNotFound
+	}
+	record := s.RecordsByGroupID[groupID][idx]
+	if !record.IsSuspension() || record.IsExpired() || s.IsVoid(groupID, recordID) {
+		return GuildEnforcementAppeal{}, ErrAppealNotSuspension
+	}
+	if _, found := s.GetAppeal(groupID, recordID); found {
+		return GuildEnforcementAppeal{}, ErrAppealExists
+	}
+	if runes := []rune(statement); len(runes) > appealStatementMaxLength {
+		statement = string(runes[:appealStatementMaxLength])
+	}
+	appeal := GuildEnforcementAppeal{
+		GroupID:     groupID,
+		RecordID:    recordID,
+		Status:      AppealStatusSubmitted,
+		Statement:   statement,
+		SubmittedAt: time.Now().UTC(),
+	}
+	s.setAppeal(appeal)
+	return appeal, nil
+}
+
+// AssignAppealReviewer puts an open appeal under review by a moderator.
+func (s *GuildEnforcementJournal) AssignAppealReviewer(groupID, recordID, reviewerUserID, reviewerDiscordID string) (GuildEnforcementAppeal, error) {
+	appeal, found := s.GetAppeal(groupID, recordID)
+	if !found {
+		return appeal, ErrAppealNotFound
+	}
+	if appeal.Status.IsResolved() {
+		return appeal, ErrAppealResolved
+	}
+	if reviewerUserID == s.UserID {
+		return appeal, ErrAppealSelfReview
+	}
+	appeal.Status = AppealStatusUnderReview
+	appeal.ReviewerUserID = reviewerUserID
+	appeal.ReviewerDiscordID = reviewerDiscordID
+	appeal.AssignedAt = time.Now().UTC()
+	s.setAppeal(appeal)
+	return appeal, nil
+}
+
+// ResolveAppeal records the outcome of an appeal. A reduction shortens the suspension to the given expiry,
+// and overturning it voids the record.
+func (s *GuildEnforcementJournal) ResolveAppeal(groupID, recordID, reviewerUserID, reviewerDiscordID string, outcome GuildEnforcementAppealStatus, notes string, reducedExpiry time.Time) (GuildEnforcementAppeal, error) {
+	appeal, found := s.GetAppeal(groupID, recordID)
+	if !found {
+		return appeal, ErrAppealNotFound
+	}
+	if appeal.Status.IsResolved() {
+		return appeal, ErrAppealResolved
+	}
+	if !outcome.IsResolved() {
+		return appeal, ErrAppealInvalidOutcome
+	}
+	if reviewerUserID == s.UserID {
+		return appeal, ErrAppealSelfReview
+	}
+	idx := s.recordIndex(groupID, recordID)
+	if idx < 0 {
+		return appeal, ErrAppealRecordNotFound
+	}
+	record := &s.RecordsByGroupID[groupID][idx]
+	now := time.Now().UTC()
+
+	switch outcome {
+	case AppealStatusReduced:
+		if !reducedExpiry.After(record.CreatedAt) || !reducedExpiry.Before(record.Expiry) {
+			return appeal, ErrAppealInvalidReduction
+		}
+		appeal.OriginalExpiry = record.Expiry
+		record.Expiry = reducedExpiry.UTC()
+		record.UpdatedAt = now
+	case AppealStatusOverturned:
+		s.VoidRecord(groupID, recordID, reviewerUserID, reviewerDiscordID, "appeal overturned: "+notes)
+	}
+
+	if appeal.ReviewerUserID == "" {
+		appeal.ReviewerUserID = reviewerUserID
+		appeal.ReviewerDiscordID = reviewerDiscordID
+		appeal.AssignedAt = now
+	}
+	appeal.Status = outcome
+	appeal.ReviewerNotes = notes
+	appeal.ResolvedAt = now
+	s.setAppeal(appeal)
+	return appeal, nil
+}
+
+// EnforcementAppealUpdate applies fn to a player's journal and writes it back.
+func EnforcementAppealUpdate(ctx context.Context, nk runtime.NakamaModule, userID string, fn func(journal *GuildEnforcementJournal) error) (*GuildEnforcementJournal, error) {
+	journal := NewGuildEnforcementJournal(userID)
+	if err := StorableRead(ctx, nk, userID, journal, false); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrAppealRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to read enforcement journal: %w", err)
+	}
+	if err := fn(journal); err != nil {
+		return nil, err
+	}
+	if err := StorableWrite(ctx, nk, userID, journal); err != nil {
+		return nil, fmt.Errorf("failed to write enforcement journal: %w", err)
+	}
+	return journal, nil
+}
+
+// enforcementAppealAuditMessage describes an appeal's latest change, for the guild's audit log.
+func enforcementAppealAuditMessage(appeal GuildEnforcementAppeal, record GuildEnforcementRecord, actorDiscordID string) string {
+	header := fmt.Sprintf("Appeal of suspension `%s` (by <@!%s>, `%s`)", appeal.RecordID, record.EnforcerDiscordID, record.UserNoticeText)
+	switch appeal.Status {
+	case AppealStatusSubmitted:
+		return fmt.Sprintf("%s submitted by <@!%s>:\n> %s", header, actorDiscordID, appeal.Statement)
+	case AppealStatusUnderReview:
+		return fmt.Sprintf("%s assigned to <@!%s> for review.", header, appeal.ReviewerDiscordID)
+	case AppealStatusReduced:
+		return fmt.Sprintf("%s reduced by <@!%s>: expiry changed from <t:%d:f> to <t:%d:f> (%s). *%s*", header, actorDiscordID, appeal.OriginalExpiry.Unix(), record.Expiry.Unix(), FormatDuration(record.Expiry.Sub(record.CreatedAt)), appeal.ReviewerNotes)
+	default:
+		return fmt.Sprintf("%s %s by <@!%s>. *%s*", header, appeal.Status, actorDiscordID, appeal.ReviewerNotes)
+	}
+}
+
+// suspensionAppealNotice returns the appeal status for the in-game suspension notice, if the suspension was appealed.
+func suspensionAppealNotice(appeals map[string]GuildEnforcementAppeal, recordID string) string {
+	appeal, ok := appeals[recordID]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" [appeal: %s]", appeal.Status.Text())
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func newAppealTestJournal(t *testing.T) (*GuildEnforcementJournal, GuildEnforcementRecord) {
+	t.Helper()
+	journal := NewGuildEnforcementJournal("user-1")
+	record := journal.AddRecord("group-a", "enforcer-1", "enforcer-discord-1", "Toxicity", "notes", false, false, 7*24*time.Hour)
+	return journal, record
+}
+
+func TestGuildEnforcementJournal_AppealLifecycle(t *testing.T) {
+	journal, record := newAppealTestJournal(t)
+
+	appeal, err := journal.SubmitAppeal("group-a", record.ID, "It was a misunderstanding.")
+	if err != nil {
+		t.Fatalf("SubmitAppeal() error = %v", err)
+	}
+	if appeal.Status != AppealStatusSubmitted || appeal.SubmittedAt.IsZero() {
+		t.Errorf("SubmitAppeal() = %+v, want a submitted appeal", appeal)
+	}
+	if _, err := journal.SubmitAppeal("group-a", record.ID, "again"); !errors.Is(err, ErrAppealExists) {
+		t.Errorf("second SubmitAppeal() error = %v, want %v", err, ErrAppealExists)
+	}
+
+	if _, err := journal.AssignAppealReviewer("group-a", record.ID, "user-1", "discord-1"); !errors.Is(err, ErrAppealSelfReview) {
+		t.Errorf("self AssignAppealReviewer() error = %v, want %v", err, ErrAppealSelfReview)
+	}
+	appeal, err = journal.AssignAppealReviewer("group-a", record.ID, "moderator-1", "moderator-discord-1")
+	if err != nil {
+		t.Fatalf("AssignAppealReviewer() error = %v", err)
+	}
+	if appeal.Status != AppealStatusUnderReview || appeal.ReviewerUserID != "moderator-1" || appeal.AssignedAt.IsZero() {
+		t.Errorf("AssignAppealReviewer() = %+v, want an appeal under review by moderator-1", appeal)
+	}
+
+	// A reduction must shorten the suspension.
+	if _, err := journal.ResolveAppeal("group-a", record.ID, "moderator-1", "moderator-discord-1", AppealStatusReduced, "", record.Expiry.Add(time.Hour)); !errors.Is(err, ErrAppealInvalidReduction) {
+		t.Errorf("ResolveAppeal() error = %v, want %v", err, ErrAppealInvalidReduction)
+	}
+
+	reduced := record.CreatedAt.Add(24 * time.Hour)
+	appeal, err = journal.ResolveAppeal("group-a", record.ID, "moderator-1", "moderator-discord-1", AppealStatusReduced, "first offense", reduced)
+	if err != nil {
+		t.Fatalf("ResolveAppeal() error = %v", err)
+	}
+	if appeal.Status != AppealStatusReduced || appeal.ResolvedAt.IsZero() || !appeal.OriginalExpiry.Equal(record.Expiry) {
+		t.Errorf("ResolveAppeal() = %+v, want a reduced appeal that keeps the original expiry", appeal)
+	}
+	if updated, _ := journal.Record("group-a", record.ID); !updated.Expiry.Equal(reduced) {
+		t.Errorf("record expiry = %v, want %v", updated.Expiry, reduced)
+	}
+	if _, err := journal.ResolveAppeal("group-a", record.ID, "moderator-1", "moderator-discord-1", AppealStatusOverturned, "", time.Time{}); !errors.Is(err, ErrAppealResolved) {
+		t.Errorf("second ResolveAppeal() error = %v, want %v", err, ErrAppealResolved)
+	}
+
+	// The appeal is stored with the journal.
+	data, err := json.Marshal(journal)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	decoded := &GuildEnforcementJournal{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if a, ok := decoded.GetAppeal("group-a", record.ID); !ok || a.Status != AppealStatusReduced {
+		t.Errorf("decoded appeal = %+v, %v; want the reduced appeal", a, ok)
+	}
+}
+
+func TestGuildEnforcementJournal_AppealOverturned(t *testing.T) {
+	journal, record := newAppealTestJournal(t)
+
+	if _, err := journal.SubmitAppeal("group-a", record.ID, "statement"); err != nil {
+		t.Fatalf("SubmitAppeal() error = %v", err)
+	}
+	appeal, err := journal.ResolveAppeal("group-a", record.ID, "moderator-1", "moderator-discord-1", AppealStatusOverturned, "wrong player", time.Time{})
+	if err != nil {
+		t.Fatalf("ResolveAppeal() error = %v", err)
+	}
+	if appeal.ReviewerUserID != "moderator-1" {
+		t.Errorf("ReviewerUserID = %q, want the resolving moderator", appeal.ReviewerUserID)
+	}
+	if !journal.IsVoid("group-a", record.ID) {
+		t.Error("an overturned suspension should be voided")
+	}
+	if len(journal.ActiveSuspensions()) != 0 {
+		t.Error("an overturned suspension should not be active")
+	}
+}
+
+func TestGuildEnforcementJournal_SubmitAppealRequiresActiveSuspension(t *testing.T) {
+	journal, record := newAppealTestJournal(t)
+	kick := journal.AddRecord("group-a", "enforcer-1", "enforcer-discord-1", "Kick", "", false, false, 0)
+	// A kick has no expiry.
+	journal.RecordsByGroupID["group-a"][1].Expiry = time.Time{}
+
+	if _, err := journal.SubmitAppeal("group-a", "missing", "statement"); !errors.Is(err, ErrAppealRecordNotFound) {
+		t.Errorf("SubmitAppeal(missing) error = %v, want %v", err, ErrAppealRecordNotFound)
+	}
+	if _, err := journal.SubmitAppeal("group-a", kick.ID, "statement"); !errors.Is(err, ErrAppealNotSuspension) {
+		t.Errorf("SubmitAppeal(kick) error = %v, want %v", err, ErrAppealNotSuspension)
+	}
+	journal.VoidRecord("group-a", record.ID, "enforcer-1", "enforcer-discord-1", "")
+	if _, err := journal.SubmitAppeal("group-a", record.ID, "statement"); !errors.Is(err, ErrAppealNotSuspension) {
+		t.Errorf("SubmitAppeal(voided) error = %v, want %v", err, ErrAppealNotSuspension)
+	}
+}
+
+func TestGuildEnforcementJournal_SubmitAppealTruncatesStatement(t *testing.T) {
+	journal, record := newAppealTestJournal(t)
+	statement := strings.Repeat("é", appealStatementMaxLength+1)
+
+	appeal, err := journal.SubmitAppeal("group-a", record.ID, statement)
+	if err != nil {
+		t.Fatalf("SubmitAppeal() error = %v", err)
+	}
+	if !utf8.ValidString(appeal.Statement) || utf8.RuneCountInString(appeal.Statement) != appealStatementMaxLength {
+		t.Errorf("SubmitAppeal() statement has %d runes (valid: %v), want %d", utf8.RuneCountInString(appeal.Statement), utf8.ValidString(appeal.Statement), appealStatementMaxLength)
+	}
+}
+
+func TestSuspensionAppealNotice(t *testing.T) {
+	appeals := map[string]GuildEnforcementAppeal{
+		"record-1": {RecordID: "record-1", Status: AppealStatusUnderReview},
+	}
+	if got := suspensionAppealNotice(appeals, "record-1"); got != " [appeal: under review]" {
+		t.Errorf("suspensionAppealNotice() = %q", got)
+	}
+	if got := suspensionAppealNotice(appeals, "record-2"); got != "" {
+		t.Errorf("suspensionAppealNotice() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultEscalationLookbackDays = 90
+
+var (
+	ErrEscalationPolicyNotSet   = errors.New("the guild has no escalation policy")
+	ErrEscalationUnknownOffense = errors.New("unknown offense category")
+)
+
+// EnforcementEscalationPolicy is a guild's ladder of suspension durations, by offense category.
+// It is set per guild in the group metadata.
+type EnforcementEscalationPolicy struct {
+	LookbackDays int                          `json:"lookback_days,omitempty"` // Prior offenses older than this are not counted (default: 90).
+	Categories   []EnforcementOffenseCategory `json:"categories"`
+}
+
+// EnforcementOffenseCategory is the ladder of one kind of offense.
+type EnforcementOffenseCategory struct {
+	Name                   string   `json:"name"`
+	Description            string   `json:"description,omitempty"`
+	Durations              []string `json:"durations"`                          // The suspension for the first, second, ... offense (e.g. 1h, 1d, 1w). The last one repeats.
+	LookbackDays           int      `json:"lookback_days,omitempty"`            // Overrides the policy's lookback.
+	RequireCommunityValues bool     `json:"require_community_values,omitempty"` // Require the player to accept the community values.
+	AllowPrivateLobbies    bool     `json:"allow_private_lobbies,omitempty"`    // Limit the suspension to public lobbies.
+}
+
+// EnforcementEscalation is the policy's suggestion for a player's next offense.
+type EnforcementEscalation struct {
+	Category               string        `json:"category"`
+	PriorOffenses          int           `json:"prior_offenses"`   // The non-void records of the category within the lookback.
+	PriorRecordIDs         []string      `json:"prior_record_ids"` // Oldest first.
+	Step                   int           `json:"step"`             // The index into the category's durations.
+	Duration               time.Duration `json:"duration"`
+	DurationText           string        `json:"duration_text"`
+	RequireCommunityValues bool          `json:"require_community_values"`
+	AllowPrivateLobbies    bool          `json:"allow_private_lobbies"`
+}
+
+func (p *EnforcementEscalationPolicy) Validate() error {
+	if p.LookbackDays < 0 {
+		return errors.New("lookback_days must not be negative")
+	}
+	seen := make(map[string]bool, len(p.Categories))
+	for _, c := range p.Categories {
+		name := strings.ToLower(strings.TrimSpace(c.Name))
+		if name == "" {
+			return errors.New("offense categories must have a name")
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate offense category %q", c.Name)
+		}
+		seen[name] = true
+		if c.LookbackDays < 0 {
+			return fmt.Errorf("category %q: lookback_days must not be negative", c.Name)
+		}
+		if len(c.Durations) == 0 {
+			return fmt.Errorf("category %q has no durations", c.Name)
+		}
+		for _, s := range c.Durations {
+			if d, err := parseSuspensionDuration(s); err != nil {
+				return fmt.Errorf("category %q: %w", c.Name, err)
+			} else if d <= 0 {
+				return fmt.Errorf("category %q: durations must be positive", c.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Category returns the offense category with the given name, ignoring case.
+func (p *EnforcementEscalationPolicy) Category(name string) (EnforcementOffenseCategory, bool) {
+	name = strings.TrimSpace(name)
+	for _, c := range p.Categories {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return EnforcementOffenseCategory{}, false
+}
+
+func (p *EnforcementEscalationPolicy) lookback(c EnforcementOffenseCategory) time.Duration {
+	days := c.LookbackDays
+	if days == 0 {
+		days = p.LookbackDays
+	}
+	if days == 0 {
+		days = defaultEscalationLookbackDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Evaluate returns the suspension for the player's next offense of the category. Prior offenses are the
+// records of the category in the groups, that have not been voided in any of them, created within the lookback.
+// The first group is the guild issuing the suspension; the rest are the groups it inherits suspensions from.
+func (p *EnforcementEscalationPolicy) Evaluate(journal *GuildEnforcementJournal, groupIDs []string, category string, now time.Time) (*EnforcementEscalation, error) {
+	c, ok := p.Category(category)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEscalationUnknownOffense, category)
+	}
+
+	since := now.Add(-p.lookback(c))
+	voids := journal.GroupVoids(groupIDs...)
+	prior := make([]GuildEnforcementRecord, 0)
+	for _, groupID := range groupIDs {
+		for _, r := range journal.GroupRecords(groupID) {
+			if _, ok := voids[r.ID]; ok {
+				continue
+			}
+			if !strings.EqualFold(r.OffenseCategory, c.Name) || r.CreatedAt.Before(since) || r.CreatedAt.After(now) {
+				continue
+			}
+			prior = append(prior, r)
+		}
+	}
+	slices.SortFunc(prior, func(a, b GuildEnforcementRecord) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	step := min(len(prior), len(c.Durations)-1)
+	duration, err := parseSuspensionDuration(c.Durations[step])
+	if err != nil {
+		return nil, fmt.Errorf("category %q: %w", c.Name, err)
+	}
+
+	escalation := &EnforcementEscalation{
+		Category:               c.Name,
+		PriorOffenses:          len(prior),
+		PriorRecordIDs:         make([]string, 0, len(prior)),
+		Step:                   step,
+		Duration:               duration,
+		DurationText:           FormatDuration(duration),
+		RequireCommunityValues: c.RequireCommunityValues,
+		AllowPrivateLobbies:    c.AllowPrivateLobbies,
+	}
+	for _, r := range prior {
+		escalation.PriorRecordIDs = append(escalation.PriorRecordIDs, r.ID)
+	}
+	return escalation, nil
+}
+
+// Text describes the suggestion for moderators.
+func (e *EnforcementEscalation) Text() string {
+	s := fmt.Sprintf("`%s` offense #%d (%d prior): %s", e.Category, e.PriorOffenses+1, e.PriorOffenses, e.DurationText)
+	if e.RequireCommunityValues {
+		s += ", community values required"
+	}
+	if e.AllowPrivateLobbies {
+		s += ", private lobbies allowed"
+	}
+	return s
+}
+
+// EnforcementEscalationEvaluate loads the player's journal, and evaluates the guild's policy for the offense.
+func EnforcementEscalationEvaluate(ctx context.Context, nk runtime.NakamaModule, registry *GuildGroupRegistry, gg *GuildGroup, userID, category string) (*EnforcementEscalation, error) {
+	if gg.EnforcementEscalation == nil || len(gg.EnforcementEscalation.Categories) == 0 {
+		return nil, ErrEscalationPolicyNotSet
+	}
+	journal := NewGuildEnforcementJournal(userID)
+	if err := StorableRead(ctx, nk, userID, journal, false); err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("failed to read enforcement journal: %w", err)
+	}
+	return gg.EnforcementEscalation.Evaluate(journal, registry.SuspensionGroupIDs(gg), category, time.Now().UTC())
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testEscalationPolicy() *EnforcementEscalationPolicy {
+	return &EnforcementEscalationPolicy{
+		LookbackDays: 30,
+		Categories: []EnforcementOffenseCategory{
+			{Name: "Toxicity", Durations: []string{"1h", "1d", "1w"}},
+			{Name: "Cheating", Durations: []string{"4w"}, LookbackDays: 365, RequireCommunityValues: true},
+		},
+	}
+}
+
+func addOffense(journal *GuildEnforcementJournal, groupID, category string, age time.Duration) GuildEnforcementRecord {
+	r := journal.AddRecord(groupID, "enforcer-1", "enforcer-discord-1", category, "", false, false, time.Hour)
+	idx := journal.recordIndex(groupID, r.ID)
+	journal.RecordsByGroupID[groupID][idx].CreatedAt = r.CreatedAt.Add(-age)
+	r, _ = journal.SetOffenseCategory(groupID, r.ID, category)
+	return r
+}
+
+func TestEnforcementEscalationPolicy_Evaluate(t *testing.T) {
+	policy := testEscalationPolicy()
+	journal := NewGuildEnforcementJournal("user-1")
+	groupIDs := []string{"group-a", "parent-group"}
+	now := time.Now().UTC().Add(time.Minute)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		setup    func()
+		category string
+		prior    int
+		want     time.Duration
+	}{
+		{"first offense", func() {}, "toxicity", 0, time.Hour},
+		{"second offense", func() { addOffense(journal, "group-a", "Toxicity", day) }, "Toxicity", 1, 24 * time.Hour},
+		{"other categories are not counted", func() { addOffense(journal, "group-a", "Cheating", day) }, "Toxicity", 1, 24 * time.Hour},
+		{"inherited guilds are counted", func() { addOffense(journal, "parent-group", "Toxicity", 2*day) }, "Toxicity", 2, 7 * 24 * time.Hour},
+		{"the last step repeats", func() { addOffense(journal, "group-a", "Toxicity", 3*day) }, "Toxicity", 3, 7 * 24 * time.Hour},
+		{"offenses outside the lookback are not counted", func() { addOffense(journal, "group-a", "Toxicity", 40*day) }, "Toxicity", 3, 7 * 24 * time.Hour},
+		{"other guilds are not counted", func() { addOffense(journal, "group-b", "Toxicity", day) }, "Toxicity", 3, 7 * 24 * time.Hour},
+		{"voided offenses are not counted", func() {
+			r := addOffense(journal, "parent-group", "Toxicity", day)
+			journal.VoidRecord("group-a", r.ID, "enforcer-1", "enforcer-discord-1", "mistake")
+		}, "Toxicity", 3, 7 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		tt.setup()
+		got, err := policy.Evaluate(journal, groupIDs, tt.category, now)
+		if err != nil {
+			t.Fatalf("%s: Evaluate() error = %v", tt.name, err)
+		}
+		if got.PriorOffenses != tt.prior || got.Duration != tt.want || len(got.PriorRecordIDs) != tt.prior {
+			t.Errorf("%s: Evaluate() = %d prior, %v; want %d prior, %v", tt.name, got.PriorOffenses, got.Duration, tt.prior, tt.want)
+		}
+		if got.Category != "Toxicity" {
+			t.Errorf("%s: Category = %q, want the policy's name", tt.name, got.Category)
+		}
+	}
+
+	got, err := policy.Evaluate(journal, groupIDs, "Cheating", now)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if got.PriorOffenses != 1 || got.Duration != 28*day || !got.RequireCommunityValues {
+		t.Errorf("Evaluate(Cheating) = %+v", got)
+	}
+
+	if _, err := policy.Evaluate(journal, groupIDs, "spam", now); !errors.Is(err, ErrEscalationUnknownOffense) {
+		t.Errorf("Evaluate(spam) error = %v, want %v", err, ErrEscalationUnknownOffense)
+	}
+}
+
+func TestEnforcementEscalationPolicy_Validate(t *testing.T) {
+	if err := testEscalationPolicy().Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy EnforcementEscalationPolicy
+	}{
+		{"unnamed category", EnforcementEscalationPolicy{Categories: []EnforcementOffenseCategory{{Durations: []string{"1h"}}}}},
+		{"duplicate category", EnforcementEscalationPolicy{Categories: []EnforcementOffenseCategory{{Name: "a", Durations: []string{"1h"}}, {Name: "A", Durations: []string{"1d"}}}}},
+		{"no durations", EnforcementEscalationPolicy{Categories: []EnforcementOffenseCategory{{Name: "a"}}}},
+		{"bad duration", EnforcementEscalationPolicy{Categories: []EnforcementOffenseCategory{{Name: "a", Durations: []string{"soon"}}}}},
+		{"zero duration", EnforcementEscalationPolicy{Categories: []EnforcementOffenseCategory{{Name: "a", Durations: []string{"0"}}}}},
+		{"negative lookback", EnforcementEscalationPolicy{LookbackDays: -1}},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); err == nil {
+			t.Errorf("%s: Validate() error = nil, want an error", tt.name)
+		}
+	}
+}
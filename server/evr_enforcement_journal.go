@@ -34,6 +34,7 @@ type GuildEnforcementJournal struct {
 	CommunityValuesCompletedAt time.Time                                        `json:"community_values_completed_at"`
 	RecordsByGroupID           map[string][]GuildEnforcementRecord              `json:"records"`
 	VoidsByRecordIDByGroupID   map[string]map[string]GuildEnforcementRecordVoid `json:"voids"`
+	AppealsByRecordIDByGroupID map[string]map[string]GuildEnforcementAppeal     `json:"appeals,omitempty"`
 	UserID                     string                                           `json:"user_id"`
 	version                    string
 }
@@ -204,6 +205,16 @@ func (s *GuildEnforcementJournal) AddRecord(groupID, enforcerUserID, enforcerDis
 	return record
 }
 
+// SetOffenseCategory tags a record with the escalation policy category it was issued under.
+func (s *GuildEnforcementJournal) SetOffenseCategory(groupID, recordID, category string) (GuildEnforcementRecord, bool) {
+	idx := s.recordIndex(groupID, recordID)
+	if idx < 0 {
+		return GuildEnforcementRecord{}, false
+	}
+	s.RecordsByGroupID[groupID][idx].OffenseCategory = category
+	return s.RecordsByGroupID[groupID][idx], true
+}
+
 func (s *GuildEnforcementJournal) VoidRecord(groupID, recordID, authorUserID, authorDiscordID, notes string) GuildEnforcementRecordVoid {
 	if s.VoidsByRecordIDByGroupID == nil {
 		s.VoidsByRecordIDByGroupID = make(map[string]map[string]GuildEnforcementRecordVoid)
@@ -262,6 +273,15 @@ func (l GuildEnforcementJournalList) Latest(groupIDs []string) (string, string,
 	return latest.GroupID, latest.UserID, latest.Record
 }
 
+// Appeals returns the appeals of all the journals, by record ID.
+func (l GuildEnforcementJournalList) Appeals() map[string]GuildEnforcementAppeal {
+	appeals := make(map[string]GuildEnforcementAppeal)
+	for _, journal := range l {
+		maps.Copy(appeals, journal.Appeals())
+	}
+	return appeals
+}
+
 func EnforcementJournalsLoad(ctx context.Context, nk runtime.NakamaModule, userIDs []string) (GuildEnforcementJournalList, error) {
 
 	ops := make([]*runtime.StorageRead, 0, len(userIDs))
@@ -17,6 +17,7 @@ type GuildEnforcementRecord struct {
 	CommunityValuesRequired bool      `json:"community_values_required"`
 	AuditorNotes            string    `json:"notes"`
 	AllowPrivateLobbies     bool      `json:"allow_private_lobbies"`
+	OffenseCategory         string    `json:"offense_category,omitempty"` // The guild's escalation policy category
 }
 
 func (r GuildEnforcementRecord) IsSuspension() bool {
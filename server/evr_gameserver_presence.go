@@ -40,6 +40,7 @@ type GameServerPresence struct {
 	Latitude        float64      `json:"latitude,omitempty"`         // The latitude of the server.
 	Longitude       float64      `json:"longitude,omitempty"`        // The longitude of the server.
 	ASNumber        int          `json:"asn,omitempty"`              // The ASN of the server.
+	Capacity        int          `json:"capacity,omitempty"`         // The player slots of the server, if it has fewer than a full lobby.
 }
 
 func (g GameServerPresence) GetHidden() bool {
@@ -0,0 +1,531 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"go.uber.org/zap"
+)
+
+const (
+	GameServerRegistryStorageCollection = "GameServerRegistry"
+
+	gameServerRTTWindow           = 20               // The number of RTT samples in the rolling window.
+	gameServerHistoryLength       = 50               // The number of registration events kept per server.
+	gameServerDrainFailures       = 3                // Consecutive failed checks before a server is drained.
+	gameServerRecoverSuccesses    = 3                // Consecutive successful checks before a drained server is used again.
+	gameServerRegistrySync        = 30 * time.Second // How often records are written, and the other nodes' records are read.
+	gameServerRemoteRecordMaxAge  = 3 * gameServerRegistrySync
+	gameServerRecordWriteAttempts = 3 // Attempts to update a stored record that another node is updating.
+)
+
+type GameServerHealth string
+
+const (
+	GameServerHealthy  GameServerHealth = "healthy"
+	GameServerDegraded GameServerHealth = "degraded" // Checks are failing, but not enough to drain the server.
+	GameServerDraining GameServerHealth = "draining" // No new matches are allocated to the server.
+	GameServerOffline  GameServerHealth = "offline"  // The server is not registered.
+)
+
+const (
+	GameServerEventRegistered   = "registered"
+	GameServerEventUnregistered = "unregistered"
+	GameServerEventFailed       = "registration_failed"
+	GameServerEventDrained      = "drained"
+	GameServerEventRecovered    = "recovered"
+)
+
+type GameServerEvent struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Node      string    `json:"node"`
+	SessionID uuid.UUID `json:"session_id"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// GameServerRTT summarizes the rolling window of RTT samples, in milliseconds.
+type GameServerRTT struct {
+	Mean    float64 `json:"mean"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Samples int     `json:"samples"`
+}
+
+// GameServerRecord is the fleet registry's view of a game server. It is keyed by the external endpoint,
+// so the registration history follows the server across sessions.
+type GameServerRecord struct {
+	ID            string       `json:"id"` // The external endpoint.
+	SessionID     uuid.UUID    `json:"session_id"`
+	ServerID      uint64       `json:"server_id"`
+	OperatorID    uuid.UUID    `json:"operator_id"`
+	Username      string       `json:"username"`
+	Node          string       `json:"node"`
+	Endpoint      evr.Endpoint `json:"endpoint"`
+	DefaultRegion string       `json:"default_region,omitempty"`
+	RegionCodes   []string     `json:"region_codes,omitempty"`
+	VersionLock   evr.Symbol   `json:"version_lock,omitempty"`
+	NativeVersion string       `json:"native_version,omitempty"`
+	Features      []string     `json:"features,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	Capacity      int          `json:"capacity"` // The player slots of the server.
+	InMatch       bool         `json:"in_match"`
+
+	Health               GameServerHealth `json:"health"`
+	RTT                  GameServerRTT    `json:"rtt"`
+	Checks               int64            `json:"checks"`
+	Failures             int64            `json:"failures"`
+	ConsecutiveFailures  int              `json:"consecutive_failures"`
+	ConsecutiveSuccesses int              `json:"consecutive_successes"`
+	LastCheck            time.Time        `json:"last_check,omitempty"`
+	LastError            string           `json:"last_error,omitempty"`
+
+	RegisteredAt time.Time         `json:"registered_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	History      []GameServerEvent `json:"history"`
+
+	rtts []float64
+}
+
+func gameServerRecordID(endpoint evr.Endpoint) string {
+	return endpoint.ExternalAddress()
+}
+
+// IsAvailable returns whether new matches may be allocated to the server.
+func (r *GameServerRecord) IsAvailable() bool {
+	return r.Health == GameServerHealthy || r.Health == GameServerDegraded
+}
+
+func (r *GameServerRecord) addEvent(e GameServerEvent) {
+	r.History = append(r.History, e)
+	if len(r.History) > gameServerHistoryLength {
+		r.History = r.History[len(r.History)-gameServerHistoryLength:]
+	}
+}
+
+// recordCheck applies a health check result, and returns the event if the server was drained or recovered.
+func (r *GameServerRecord) recordCheck(now time.Time, rtt time.Duration, err error) *GameServerEvent {
+	r.Checks++
+	r.LastCheck = now
+	r.UpdatedAt = now
+
+	if err != nil {
+		r.Failures++
+		r.ConsecutiveFailures++
+		r.ConsecutiveSuccesses = 0
+		r.LastError = err.Error()
+	} else {
+		r.ConsecutiveFailures = 0
+		r.ConsecutiveSuccesses++
+		r.rtts = append(r.rtts, float64(rtt)/float64(time.Millisecond))
+		if len(r.rtts) > gameServerRTTWindow {
+			r.rtts = r.rtts[len(r.rtts)-gameServerRTTWindow:]
+		}
+		r.RTT = GameServerRTT{Mean: 0, Min: r.rtts[0], Max: r.rtts[0], Samples: len(r.rtts)}
+		for _, v := range r.rtts {
+			r.RTT.Mean += v / float64(len(r.rtts))
+			r.RTT.Min = min(r.RTT.Min, v)
+			r.RTT.Max = max(r.RTT.Max, v)
+		}
+	}
+
+	switch {
+	case r.Health == GameServerDraining && r.ConsecutiveSuccesses >= gameServerRecoverSuccesses:
+		r.Health = GameServerHealthy
+		return &GameServerEvent{Time: now, Type: GameServerEventRecovered, Node: r.Node, SessionID: r.SessionID}
+	case r.Health != GameServerDraining && r.ConsecutiveFailures >= gameServerDrainFailures:
+		r.Health = GameServerDraining
+		return &GameServerEvent{Time: now, Type: GameServerEventDrained, Node: r.Node, SessionID: r.SessionID, Detail: r.LastError}
+	case r.Health == GameServerHealthy && r.ConsecutiveFailures > 0:
+		r.Health = GameServerDegraded
+	case r.Health == GameServerDegraded && r.ConsecutiveFailures == 0:
+		r.Health = GameServerHealthy
+	}
+	return nil
+}
+
+func (r *GameServerRecord) clone() *GameServerRecord {
+	c := *r
+	c.RegionCodes = slices.Clone(r.RegionCodes)
+	c.Features = slices.Clone(r.Features)
+	c.Tags = slices.Clone(r.Tags)
+	c.History = slices.Clone(r.History)
+	c.rtts = slices.Clone(r.rtts)
+	return &c
+}
+
+// GameServerRegistry tracks the health of the game server fleet. Each node tracks the servers connected to it,
+// and shares their records through storage, so every node can query, and allocate around, the whole fleet.
+type GameServerRegistry struct {
+	sync.RWMutex
+	logger *zap.Logger
+	nk     runtime.NakamaModule
+	node   string
+
+	local  map[uuid.UUID]*GameServerRecord // The servers connected to this node, by session ID.
+	remote map[uuid.UUID]*GameServerRecord // The servers connected to other nodes, by session ID.
+	dirty  map[uuid.UUID]bool
+	syncCh chan struct{}
+}
+
+func NewGameServerRegistry(ctx context.Context, logger *zap.Logger, nk runtime.NakamaModule, node string) *GameServerRegistry {
+	r := &GameServerRegistry{
+		logger: logger.With(zap.String("module", "gameserver_registry")),
+		nk:     nk,
+		node:   node,
+
+		local:  make(map[uuid.UUID]*GameServerRecord),
+		remote: make(map[uuid.UUID]*GameServerRecord),
+		dirty:  make(map[uuid.UUID]bool),
+		syncCh: make(chan struct{}, 1),
+	}
+
+	go func() {
+		ticker := time.NewTicker(gameServerRegistrySync)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.load(ctx); err != nil {
+					r.logger.Warn("Failed to load game server records", zap.Error(err))
+				}
+			case <-r.syncCh:
+			}
+			if err := r.flush(ctx); err != nil {
+				r.logger.Warn("Failed to store game server records", zap.Error(err))
+			}
+		}
+	}()
+	return r
+}
+
+// requestSync wakes the sync loop, to store a change without waiting for the next tick.
+func (r *GameServerRegistry) requestSync() {
+	select {
+	case r.syncCh <- struct{}{}:
+	default:
+	}
+}
+
+// readRecord returns the stored record of the server, or nil.
+func (r *GameServerRegistry) readRecord(ctx context.Context, id string) (*GameServerRecord, error) {
+	record, _, err := r.readRecordVersion(ctx, id)
+	return record, err
+}
+
+// readRecordVersion returns the stored record of the server, or nil, and its storage version ("*" if there is none).
+func (r *GameServerRegistry) readRecordVersion(ctx context.Context, id string) (*GameServerRecord, string, error) {
+	objs, err := r.nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: GameServerRegistryStorageCollection,
+		Key:        id,
+		UserID:     SystemUserID,
+	}})
+	if err != nil {
+		return nil, "", err
+	} else if len(objs) == 0 {
+		return nil, "*", nil
+	}
+	record := &GameServerRecord{}
+	if err := json.Unmarshal([]byte(objs[0].Value), record); err != nil {
+		return nil, "", err
+	}
+	return record, objs[0].Version, nil
+}
+
+// Register adds a game server that has connected to this node. The stored record's history is kept.
+func (r *GameServerRegistry) Register(ctx context.Context, presence *GameServerPresence) {
+	now := time.Now().UTC()
+	id := gameServerRecordID(presence.Endpoint)
+
+	history := make([]GameServerEvent, 0, 1)
+	if stored, err := r.readRecord(ctx, id); err != nil {
+		r.logger.Warn("Failed to read game server record", zap.String("id", id), zap.Error(err))
+	} else if stored != nil {
+		history = stored.History
+	}
+
+	record := &GameServerRecord{
+		ID:            id,
+		SessionID:     presence.SessionID,
+		ServerID:      presence.ServerID,
+		OperatorID:    presence.OperatorID,
+		Username:      presence.Username,
+		Node:          r.node,
+		Endpoint:      presence.Endpoint,
+		DefaultRegion: presence.DefaultRegion,
+		RegionCodes:   slices.Clone(presence.RegionCodes),
+		VersionLock:   presence.VersionLock,
+		NativeVersion: presence.NativeVersion,
+		Features:      slices.Clone(presence.Features),
+		Tags:          slices.Clone(presence.Tags),
+		Capacity:      cmp.Or(presence.Capacity, MatchLobbyMaxSize),
+		Health:        GameServerHealthy,
+		RegisteredAt:  now,
+		UpdatedAt:     now,
+		History:       history,
+	}
+	record.addEvent(GameServerEvent{Time: now, Type: GameServerEventRegistered, Node: r.node, SessionID: presence.SessionID})
+
+	r.Lock()
+	r.local[presence.SessionID] = record
+	r.dirty[presence.SessionID] = true
+	r.Unlock()
+	r.requestSync()
+}
+
+// RegistrationFailed records a failed registration in the server's history.
+func (r *GameServerRegistry) RegistrationFailed(ctx context.Context, presence *GameServerPresence, reason error) {
+	now := time.Now().UTC()
+	id := gameServerRecordID(presence.Endpoint)
+	var err error
+	for range gameServerRecordWriteAttempts {
+		var (
+			record  *GameServerRecord
+			version string
+		)
+		if record, version, err = r.readRecordVersion(ctx, id); err != nil {
+			r.logger.Warn("Failed to read game server record", zap.String("id", id), zap.Error(err))
+			return
+		}
+		if record == nil {
+			record = &GameServerRecord{
+				ID:         id,
+				ServerID:   presence.ServerID,
+				OperatorID: presence.OperatorID,
+				Username:   presence.Username,
+				Endpoint:   presence.Endpoint,
+				Health:     GameServerOffline,
+			}
+		}
+		record.UpdatedAt = now
+		record.addEvent(GameServerEvent{Time: now, Type: GameServerEventFailed, Node: r.node, SessionID: presence.SessionID, Detail: reason.Error()})
+		if err = r.storeVersion(ctx, record, version); !isStorageVersionConflict(err) {
+			break
+		}
+		// Another probe updated the record; read it again.
+	}
+	if err != nil {
+		r.logger.Warn("Failed to store game server record", zap.String("id", id), zap.Error(err))
+	}
+}
+
+// Unregister removes a game server that has disconnected from this node, and stores it as offline.
+func (r *GameServerRegistry) Unregister(ctx context.Context, sessionID uuid.UUID, reason string) {
+	now := time.Now().UTC()
+
+	r.Lock()
+	record, ok := r.local[sessionID]
+	if ok {
+		delete(r.local, sessionID)
+		delete(r.dirty, sessionID)
+		record.Health = GameServerOffline
+		record.InMatch = false
+		record.UpdatedAt = now
+		record.addEvent(GameServerEvent{Time: now, Type: GameServerEventUnregistered, Node: r.node, SessionID: sessionID, Detail: reason})
+		record = record.clone()
+	}
+	r.Unlock()
+
+	if ok {
+		if err := r.store(ctx, record); err != nil {
+			r.logger.Warn("Failed to store game server record", zap.String("id", record.ID), zap.Error(err))
+		}
+	}
+}
+
+// RecordCheck records the result of a health check of a game server connected to this node.
+func (r *GameServerRegistry) RecordCheck(sessionID uuid.UUID, rtt time.Duration, err error) {
+	r.Lock()
+	record, ok := r.local[sessionID]
+	if !ok {
+		r.Unlock()
+		return
+	}
+	event := record.recordCheck(time.Now().UTC(), rtt, err)
+	if event != nil {
+		record.addEvent(*event)
+	}
+	r.dirty[sessionID] = true
+	r.Unlock()
+
+	if event != nil {
+		r.logger.Info("Game server health changed", zap.String("id", record.ID), zap.String("event", event.Type), zap.String("detail", event.Detail))
+		r.requestSync()
+	}
+}
+
+// SetInMatch records whether the game server is hosting a match.
+func (r *GameServerRegistry) SetInMatch(sessionID uuid.UUID, inMatch bool) {
+	r.Lock()
+	defer r.Unlock()
+	if record, ok := r.local[sessionID]; ok && record.InMatch != inMatch {
+		record.InMatch = inMatch
+		r.dirty[sessionID] = true
+	}
+}
+
+// Get returns a copy of the record of the game server session, from this node or another one.
+func (r *GameServerRegistry) Get(sessionID uuid.UUID) (*GameServerRecord, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	if record, ok := r.local[sessionID]; ok {
+		return record.clone(), true
+	}
+	if record, ok := r.remote[sessionID]; ok {
+		return record.clone(), true
+	}
+	return nil, false
+}
+
+// LastFailedCheck returns the time of the game server session's last health check, if it failed.
+func (r *GameServerRegistry) LastFailedCheck(sessionID uuid.UUID) (time.Time, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	record, ok := r.local[sessionID]
+	if !ok {
+		record, ok = r.remote[sessionID]
+	}
+	if !ok || record.ConsecutiveFailures == 0 {
+		return time.Time{}, false
+	}
+	return record.LastCheck, true
+}
+
+// IsAvailable returns false if the game server session is being drained. Unknown sessions are available.
+func (r *GameServerRegistry) IsAvailable(sessionID uuid.UUID) bool {
+	r.RLock()
+	defer r.RUnlock()
+	if record, ok := r.local[sessionID]; ok {
+		return record.IsAvailable()
+	}
+	if record, ok := r.remote[sessionID]; ok {
+		return record.IsAvailable()
+	}
+	return true
+}
+
+// List returns copies of the records of the registered game servers, on all nodes.
+func (r *GameServerRegistry) List() []*GameServerRecord {
+	r.RLock()
+	records := make([]*GameServerRecord, 0, len(r.local)+len(r.remote))
+	for _, record := range r.local {
+		records = append(records, record.clone())
+	}
+	for _, record := range r.remote {
+		records = append(records, record.clone())
+	}
+	r.RUnlock()
+
+	slices.SortFunc(records, func(a, b *GameServerRecord) int {
+		if a.ID < b.ID {
+			return -1
+		} else if a.ID > b.ID {
+			return 1
+		}
+		return 0
+	})
+	return records
+}
+
+func (r *GameServerRegistry) store(ctx context.Context, record *GameServerRecord) error {
+	return r.storeVersion(ctx, record, "")
+}
+
+// storeVersion stores the record if its stored version is still the given one ("" for any, "*" for none).
+func (r *GameServerRegistry) storeVersion(ctx context.Context, record *GameServerRecord, version string) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = r.nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      GameServerRegistryStorageCollection,
+		Key:             record.ID,
+		UserID:          SystemUserID,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	return err
+}
+
+// flush stores the records that have changed since the last flush.
+func (r *GameServerRegistry) flush(ctx context.Context) error {
+	r.Lock()
+	records := make([]*GameServerRecord, 0, len(r.dirty))
+	for sessionID := range r.dirty {
+		if record, ok := r.local[sessionID]; ok {
+			records = append(records, record.clone())
+		}
+	}
+	clear(r.dirty)
+	r.Unlock()
+
+	var errs []error
+	for _, record := range records {
+		if err := r.store(ctx, record); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", record.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// load reads the records of the servers connected to the other nodes.
+func (r *GameServerRegistry) load(ctx context.Context) error {
+	now := time.Now().UTC()
+	remote := make(map[uuid.UUID]*GameServerRecord)
+
+	cursor := ""
+	for {
+		objs, next, err := r.nk.StorageList(ctx, SystemUserID, SystemUserID, GameServerRegistryStorageCollection, 100, cursor)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			record := &GameServerRecord{}
+			if err := json.Unmarshal([]byte(obj.Value), record); err != nil {
+				r.logger.Warn("Failed to unmarshal game server record", zap.String("key", obj.Key), zap.Error(err))
+				continue
+			}
+			// Skip this node's servers, offline servers, and the records of nodes that have stopped updating them.
+			if record.Node == r.node || record.Health == GameServerOffline || now.Sub(record.UpdatedAt) > gameServerRemoteRecordMaxAge {
+				continue
+			}
+			remote[record.SessionID] = record
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	r.Lock()
+	r.remote = remote
+	// Store the local records periodically, so other nodes know they are current.
+	for sessionID := range maps.Keys(r.local) {
+		r.dirty[sessionID] = true
+	}
+	r.Unlock()
+	return nil
+}
+
+// History returns the stored registration history of the server, by its ID (the external endpoint).
+func (r *GameServerRegistry) History(ctx context.Context, id string) ([]GameServerEvent, error) {
+	record, err := r.readRecord(ctx, id)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return record.History, nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGameServerRecord_RecordCheck(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("ping request timed out")
+
+	t.Run("drains after consecutive failures and recovers", func(t *testing.T) {
+		r := &GameServerRecord{Health: GameServerHealthy}
+
+		assert.Nil(t, r.recordCheck(now, 0, failure))
+		assert.Equal(t, GameServerDegraded, r.Health)
+		assert.True(t, r.IsAvailable())
+
+		assert.Nil(t, r.recordCheck(now, 0, failure))
+		event := r.recordCheck(now, 0, failure)
+		require.NotNil(t, event)
+		assert.Equal(t, GameServerEventDrained, event.Type)
+		assert.Equal(t, failure.Error(), event.Detail)
+		assert.Equal(t, GameServerDraining, r.Health)
+		assert.False(t, r.IsAvailable())
+
+		for range gameServerRecoverSuccesses - 1 {
+			assert.Nil(t, r.recordCheck(now, 20*time.Millisecond, nil))
+			assert.Equal(t, GameServerDraining, r.Health)
+		}
+		event = r.recordCheck(now, 20*time.Millisecond, nil)
+		require.NotNil(t, event)
+		assert.Equal(t, GameServerEventRecovered, event.Type)
+		assert.Equal(t, GameServerHealthy, r.Health)
+
+		assert.EqualValues(t, 6, r.Checks)
+		assert.EqualValues(t, 3, r.Failures)
+	})
+
+	t.Run("degraded server recovers on one success", func(t *testing.T) {
+		r := &GameServerRecord{Health: GameServerHealthy}
+		r.recordCheck(now, 0, failure)
+		r.recordCheck(now, 10*time.Millisecond, nil)
+		assert.Equal(t, GameServerHealthy, r.Health)
+		assert.Zero(t, r.ConsecutiveFailures)
+	})
+
+	t.Run("rolling rtt", func(t *testing.T) {
+		r := &GameServerRecord{Health: GameServerHealthy}
+		for i := range gameServerRTTWindow + 10 {
+			r.recordCheck(now, time.Duration(i+1)*time.Millisecond, nil)
+		}
+		assert.Equal(t, gameServerRTTWindow, r.RTT.Samples)
+		assert.Equal(t, 11.0, r.RTT.Min)
+		assert.Equal(t, 30.0, r.RTT.Max)
+		assert.InDelta(t, 20.5, r.RTT.Mean, 0.001)
+	})
+}
+
+func TestGameServerRecord_HistoryLength(t *testing.T) {
+	r := &GameServerRecord{}
+	for i := range gameServerHistoryLength + 5 {
+		r.addEvent(GameServerEvent{Type: GameServerEventRegistered, Detail: string(rune('a' + i%26))})
+	}
+	assert.Len(t, r.History, gameServerHistoryLength)
+}
+
+func TestGameServerRegistry_IsAvailable(t *testing.T) {
+	local, remote, unknown := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	r := &GameServerRegistry{
+		logger: zap.NewNop(),
+		local:  map[uuid.UUID]*GameServerRecord{local: {ID: "a", SessionID: local, Health: GameServerHealthy}},
+		remote: map[uuid.UUID]*GameServerRecord{remote: {ID: "b", SessionID: remote, Health: GameServerDraining}},
+		dirty:  make(map[uuid.UUID]bool),
+		syncCh: make(chan struct{}, 1),
+	}
+
+	assert.True(t, r.IsAvailable(local))
+	assert.False(t, r.IsAvailable(remote))
+	assert.True(t, r.IsAvailable(unknown))
+
+	for range gameServerDrainFailures {
+		r.RecordCheck(local, 0, errors.New("timeout"))
+	}
+	assert.False(t, r.IsAvailable(local))
+	assert.True(t, r.dirty[local])
+
+	record, ok := r.Get(local)
+	require.True(t, ok)
+	require.NotEmpty(t, record.History)
+	assert.Equal(t, GameServerEventDrained, record.History[len(record.History)-1].Type)
+
+	records := r.List()
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].ID)
+}
@@ -48,6 +48,34 @@ type SkillRatingSettings struct {
 
 	// WinningTeamBonus is added to winning team players' scores before rating calculation
 	WinningTeamBonus float64 `json:"winning_team_bonus"`
+
+	// Engine is the rating engine: "openskill" (the default), "glicko2" or "elo".
+	Engine string `json:"engine"`
+
+	// Engines overrides the engine per guild and/or mode.
+	// Keys are "<group_id>:<mode>", "<group_id>" or "<mode>", the most specific match winning.
+	Engines map[string]string `json:"engines"`
+
+	Glicko2Volatility float64 `json:"glicko2_volatility"` // Glicko-2 volatility (default 0.06)
+	EloKFactor        float64 `json:"elo_k_factor"`       // Elo K-factor of an unrated player (default 32)
+
+	Inactivity RatingInactivitySettings `json:"inactivity"` // Sigma inflation of inactive players
+	Season     RatingSeasonSettings     `json:"season"`     // Seasonal soft resets
+	Ladder     RankedLadderSettings     `json:"ladder"`     // The visible ranked ladder
+}
+
+// RatingInactivitySettings grows the uncertainty of players that have not played a rated match for a while.
+type RatingInactivitySettings struct {
+	GraceDays   int     `json:"grace_days"`    // Days without a rated match before sigma grows; 0 disables inflation
+	SigmaPerDay float64 `json:"sigma_per_day"` // Sigma added for each further inactive day
+	MaxSigma    float64 `json:"max_sigma"`     // The upper bound of inflated sigmas (default: the default sigma)
+}
+
+// RatingSeasonSettings pulls all ratings towards the defaults at the start of each season.
+type RatingSeasonSettings struct {
+	ResetSchedule string  `json:"reset_schedule"` // Cron expression (UTC) of the season starts; empty disables soft resets
+	MuRetention   float64 `json:"mu_retention"`   // Fraction of the distance from the default mu that is kept (0 is a hard reset)
+	SigmaReset    float64 `json:"sigma_reset"`    // Fraction of the distance to the default sigma that is restored
 }
 
 type ServiceSettingsData struct {
@@ -67,13 +95,15 @@ type ServiceSettingsData struct {
 	CommandLogChannelID                   string                    `json:"service_command_log_channel_id"`
 	DiscordBotUserID                      string                    `json:"discord_bot_user_id"`
 	KickPlayersWithDisabledAlternates     bool                      `json:"kick_players_with_disabled_alts"` // Kick players with disabled alts
+	AlternateScoring                      AlternateScoringSettings  `json:"alternate_scoring"`               // How alternates are scored, and when they are reviewed or enforced
 	VRMLEntitlementNotifyChannelID        string                    `json:"vrml_entitlement_notify_channel_id"`
 	EnableContinuousGameserverHealthCheck bool                      `json:"enable_continuous_gameserver_health_check"`
 	DisplayNameInUseNotifications         bool                      `json:"display_name_in_use_notifications"` // Display name in use notifications
 	EnableSessionDebug                    bool                      `json:"enable_session_debug"`
 	version                               string
 	serviceStatusMessage                  string
-	PingServerBeforeJoin                  bool `json:"ping_server_before_join"` // Ping the server before joining to measure latency
+	PingServerBeforeJoin                  bool                `json:"ping_server_before_join"`      // Ping the server before joining to measure latency
+	DerivedStatistics                     []*DerivedStatistic `json:"derived_statistics,omitempty"` // Statistics computed from other statistics, kept in their own leaderboards
 }
 
 type PruneSettings struct {
@@ -83,30 +113,36 @@ type PruneSettings struct {
 }
 
 type GlobalMatchmakingSettings struct {
-	MatchmakingTimeoutSecs         int                     `json:"matchmaking_timeout_secs"`            // The matchmaking timeout
-	FailsafeTimeoutSecs            int                     `json:"failsafe_timeout_secs"`               // The failsafe timeout
-	FallbackTimeoutSecs            int                     `json:"fallback_timeout_secs"`               // The fallback timeout
-	DisableArenaBackfill           bool                    `json:"disable_arena_backfill"`              // Disable backfilling for arena matches
-	ArenaBackfillMaxAgeSecs        int                     `json:"arena_backfill_max_age_secs"`         // Maximum age of arena matches to backfill (default 270s)
-	QueryAddons                    QueryAddons             `json:"query_addons"`                        // Additional queries to add to matchmaking queries
-	MaxServerRTT                   int                     `json:"max_server_rtt"`                      // The maximum RTT to allow
-	EnableSBMM                     bool                    `json:"enable_skill_based_mm"`               // Disable SBMM
-	EnableDivisions                bool                    `json:"enable_divisions"`                    // Enable divisions
-	GreenDivisionMaxAccountAgeDays int                     `json:"green_division_max_account_age_days"` // The maximum account age to be in the green division
-	EnableEarlyQuitPenalty         bool                    `json:"enable_early_quit_penalty"`           // Disable early quit penalty
-	EarlyQuitTier1Threshold        *int32                  `json:"early_quit_tier1_threshold"`          // Penalty level threshold for Tier 1 (good standing). Players with penalty <= threshold stay in Tier 1. Nil means not configured.
-	EarlyQuitTier2Threshold        *int32                  `json:"early_quit_tier2_threshold"`          // Penalty level threshold for Tier 2 (reserved for future Tier 3+ implementation). Nil means not configured.
-	ServerSelection                ServerSelectionSettings `json:"server_selection"`                    // The server selection settings
-	EnableOrdinalRange             bool                    `json:"enable_ordinal_range"`                // Enable ordinal range
-	RatingRange                    float64                 `json:"rating_range"`                        // The rating range
-	MatchmakingTicketsUseMu        bool                    `json:"sbmm_matchmaking_tickets_use_mu"`     // Use Mu instead of Ordinal for matchmaking tickets
-	BackfillQueriesUseMu           bool                    `json:"sbmm_backfill_queries_use_mu"`        // Use Mu instead of Ordinal for backfill queries
-	MatchmakerUseMu                bool                    `json:"sbmm_matchmaker_use_mu"`              // Use Mu instead of Ordinal for matchmaker player MMR values
-	BackfillMinTimeSecs            int                     `json:"backfill_min_time_secs"`              // Minimum time in seconds before backfilling a player to a match
-	SBMMMinPlayerCount             int                     `json:"sbmm_min_player_count"`               // Minimum player count to enable skill-based matchmaking
-	PartySkillBoostPercent         float64                 `json:"party_skill_boost_percent"`           // Boost party effective skill by this percentage (e.g., 0.10 = 10%) to account for coordination advantage
-	EnableRosterVariants           bool                    `json:"enable_roster_variants"`              // Generate multiple roster variants (balanced/stacked) for better match selection
-	UseSnakeDraftTeamFormation     bool                    `json:"use_snake_draft_team_formation"`      // Use snake draft instead of sequential filling for team formation
+	MatchmakingTimeoutSecs         int                        `json:"matchmaking_timeout_secs"`            // The matchmaking timeout
+	FailsafeTimeoutSecs            int                        `json:"failsafe_timeout_secs"`               // The failsafe timeout
+	FallbackTimeoutSecs            int                        `json:"fallback_timeout_secs"`               // The fallback timeout
+	DisableArenaBackfill           bool                       `json:"disable_arena_backfill"`              // Disable backfilling for arena matches
+	ArenaBackfillMaxAgeSecs        int                        `json:"arena_backfill_max_age_secs"`         // Maximum age of arena matches to backfill (default 270s)
+	QueryAddons                    QueryAddons                `json:"query_addons"`                        // Additional queries to add to matchmaking queries
+	MaxServerRTT                   int                        `json:"max_server_rtt"`                      // The maximum RTT to allow
+	EnableSBMM                     bool                       `json:"enable_skill_based_mm"`               // Disable SBMM
+	EnableDivisions                bool                       `json:"enable_divisions"`                    // Enable divisions
+	GreenDivisionMaxAccountAgeDays int                        `json:"green_division_max_account_age_days"` // The maximum account age to be in the green division
+	EnableEarlyQuitPenalty         bool                       `json:"enable_early_quit_penalty"`           // Disable early quit penalty
+	EarlyQuitTier1Threshold        *int32                     `json:"early_quit_tier1_threshold"`          // Penalty level threshold for Tier 1 (good standing). Players with penalty <= threshold stay in Tier 1. Nil means not configured.
+	EarlyQuitTier2Threshold        *int32                     `json:"early_quit_tier2_threshold"`          // Penalty level threshold for Tier 2 (reserved for future Tier 3+ implementation). Nil means not configured.
+	EarlyQuitReconnectGraceSecs    int                        `json:"early_quit_reconnect_grace_secs"`     // A player that rejoins the match within this is not penalized (default 120)
+	EarlyQuitLobbyDropWindowSecs   int                        `json:"early_quit_lobby_drop_window_secs"`   // Departures within this of each other are counted together as a lobby drop (default 10)
+	EarlyQuitLobbyDropFraction     float64                    `json:"early_quit_lobby_drop_fraction"`      // The fraction of the players leaving at once that is a lobby drop, and exempt (default 0.75)
+	ServerSelection                ServerSelectionSettings    `json:"server_selection"`                    // The server selection settings
+	EnableOrdinalRange             bool                       `json:"enable_ordinal_range"`                // Enable ordinal range
+	RatingRange                    float64                    `json:"rating_range"`                        // The rating range
+	MatchmakingTicketsUseMu        bool                       `json:"sbmm_matchmaking_tickets_use_mu"`     // Use Mu instead of Ordinal for matchmaking tickets
+	BackfillQueriesUseMu           bool                       `json:"sbmm_backfill_queries_use_mu"`        // Use Mu instead of Ordinal for backfill queries
+	MatchmakerUseMu                bool                       `json:"sbmm_matchmaker_use_mu"`              // Use Mu instead of Ordinal for matchmaker player MMR values
+	BackfillMinTimeSecs            int                        `json:"backfill_min_time_secs"`              // Minimum time in seconds before backfilling a player to a match
+	SBMMMinPlayerCount             int                        `json:"sbmm_min_player_count"`               // Minimum player count to enable skill-based matchmaking
+	PartySkillBoostPercent         float64                    `json:"party_skill_boost_percent"`           // Boost party effective skill by this percentage (e.g., 0.10 = 10%) to account for coordination advantage
+	EnableRosterVariants           bool                       `json:"enable_roster_variants"`              // Generate multiple roster variants (balanced/stacked) for better match selection
+	UseSnakeDraftTeamFormation     bool                       `json:"use_snake_draft_team_formation"`      // Use snake draft instead of sequential filling for team formation
+	MapRotation                    *MapRotationPolicy         `json:"map_rotation,omitempty"`              // The map rotation policy for guilds without their own
+	CandidateSnapshotIntervalSecs  int                        `json:"candidate_snapshot_interval_secs"`    // Store the matchmaker candidates for the simulator at most this often (0 disables)
+	EarlyQuitTelemetry             EarlyQuitTelemetrySettings `json:"early_quit_telemetry"`                // The grace windows and thresholds of the match telemetry (docs/EARLY_QUIT.md)
 }
 
 type QueryAddons struct {
@@ -190,6 +226,10 @@ var ValidTeamStatFields = map[string]bool{
 
 func FixDefaultServiceSettings(logger runtime.Logger, data *ServiceSettingsData) {
 
+	if err := ValidateDerivedStatistics(data.DerivedStatistics); err != nil && logger != nil {
+		logger.WithField("error", err).Warn("Invalid derived statistics; none will be maintained")
+	}
+
 	// Initialize skill rating defaults
 	if data.SkillRating.Defaults.Z == 0 {
 		data.SkillRating.Defaults.Z = 3
@@ -295,6 +335,18 @@ func FixDefaultServiceSettings(logger runtime.Logger, data *ServiceSettingsData)
 		data.Matchmaking.EarlyQuitTier2Threshold = &tier2Threshold
 	}
 
+	if data.Matchmaking.EarlyQuitReconnectGraceSecs == 0 {
+		data.Matchmaking.EarlyQuitReconnectGraceSecs = defaultEarlyQuitReconnectGraceSecs
+	}
+	if data.Matchmaking.EarlyQuitLobbyDropWindowSecs == 0 {
+		data.Matchmaking.EarlyQuitLobbyDropWindowSecs = defaultEarlyQuitLobbyDropWindowSecs
+	}
+	if data.Matchmaking.EarlyQuitLobbyDropFraction == 0 {
+		data.Matchmaking.EarlyQuitLobbyDropFraction = defaultEarlyQuitLobbyDropFraction
+	}
+
+	data.Matchmaking.EarlyQuitTelemetry.setDefaults()
+
 	if data.Matchmaking.ServerSelection.RTTDelta == nil {
 		data.Matchmaking.ServerSelection.RTTDelta = make(map[string]int)
 	}
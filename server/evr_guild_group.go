@@ -284,7 +284,7 @@ func GuildGroupLoad(ctx context.Context, nk runtime.NakamaModule, groupID string
 		return nil, fmt.Errorf("failed to get group: %v", err)
 	}
 	if len(groups) == 0 {
-		return nil, fmt.Errorf("group not found")
+		return nil, runtime.ErrGroupNotFound
 	}
 
 	state, err := GuildGroupStateLoad(ctx, nk, ServiceSettings().DiscordBotUserID, groupID)
@@ -22,6 +22,7 @@ type GuildGroupRegistry struct {
 	writeMu        sync.Mutex                              // Mutex to protect writes to the guildGroups map
 	guildGroups    *atomic.Pointer[map[string]*GuildGroup] // map[groupID]GuildGroup
 	inheritanceMap *atomic.Pointer[map[string][]string]    // map[srcGroupID][]dstGroupID
+	providers      *MapOf[string, CommunityProvider]       // map[providerName]CommunityProvider
 }
 
 func NewGuildGroupRegistry(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, db *sql.DB) *GuildGroupRegistry {
@@ -34,6 +35,7 @@ func NewGuildGroupRegistry(ctx context.Context, logger runtime.Logger, nk runtim
 
 		guildGroups:    atomic.NewPointer(&map[string]*GuildGroup{}),
 		inheritanceMap: atomic.NewPointer(&map[string][]string{}),
+		providers:      &MapOf[string, CommunityProvider]{},
 	}
 	// Regularly update the guild groups from the database.
 	go func() {
@@ -49,6 +51,9 @@ func NewGuildGroupRegistry(ctx context.Context, logger runtime.Logger, nk runtim
 				// Initial delay before starting the ticker
 				ticker.Reset(time.Minute * 1)
 			}
+			// Push the communities of syncing providers into the guild groups.
+			registry.syncProviders()
+
 			registry.writeMu.Lock()
 			registry.rebuildGuildGroups()
 			registry.writeMu.Unlock()
@@ -109,7 +114,7 @@ func (r *GuildGroupRegistry) rebuildGuildGroups() {
 
 			// Build the inheretence map.
 
-			for _, parentID := range gg.SuspensionInheritanceGroupIDs {
+			for _, parentID := range r.suspensionInheritance(gg) {
 				inheritanceMap[parentID] = append(inheritanceMap[parentID], group.Id)
 			}
 		}
@@ -122,6 +127,38 @@ func (r *GuildGroupRegistry) rebuildGuildGroups() {
 	r.inheritanceMap.Store(&inheritanceMap)
 }
 
+func (r *GuildGroupRegistry) syncProviders() {
+	r.providers.Range(func(name string, provider CommunityProvider) bool {
+		if syncer, ok := provider.(CommunitySyncer); ok {
+			if err := syncer.Sync(r.ctx, r); err != nil {
+				r.logger.WithFields(map[string]any{
+					"provider": name,
+					"error":    err,
+				}).Warn("Error syncing community provider")
+			}
+		}
+		return true
+	})
+}
+
+// suspensionInheritance returns the inherited group IDs from the group's provider, falling back to the metadata.
+func (r *GuildGroupRegistry) suspensionInheritance(gg *GuildGroup) []string {
+	provider, err := r.CommunityProvider(gg)
+	if err != nil {
+		return gg.SuspensionInheritanceGroupIDs
+	}
+	groupIDs, err := provider.SuspensionInheritance(r.ctx, gg)
+	if err != nil {
+		r.logger.WithFields(map[string]any{
+			"group_id": gg.IDStr(),
+			"provider": provider.Name(),
+			"error":    err,
+		}).Warn("Error loading suspension inheritance")
+		return gg.SuspensionInheritanceGroupIDs
+	}
+	return groupIDs
+}
+
 // map[parentGroupID]map[childGroupID]bool
 func (r *GuildGroupRegistry) InheritanceByParentGroupID() map[string][]string {
 	return *r.inheritanceMap.Load()
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mmcloughlin/geohash"
+	"github.com/oschwald/maxminddb-golang"
+	"go.uber.org/zap"
+)
+
+const (
+	mmdbReloadInterval = time.Minute
+
+	IPInfoProviderPriorityPrimary  = "primary"
+	IPInfoProviderPriorityFallback = "fallback"
+)
+
+// MMDBCityRecord is the subset of a GeoIP2/GeoLite2 City record that is used.
+type MMDBCityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+	Traits struct {
+		IsAnonymousProxy bool `maxminddb:"is_anonymous_proxy"` // Deprecated by MaxMind; always false in GeoLite2
+	} `maxminddb:"traits"`
+}
+
+// MMDBASNRecord is a GeoIP2/GeoLite2 ASN record.
+type MMDBASNRecord struct {
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+var _ = IPInfo(&mmdbData{})
+
+type mmdbData struct {
+	CityRecord MMDBCityRecord `json:"city,omitempty"`
+	ASNRecord  MMDBASNRecord  `json:"asn,omitempty"`
+}
+
+func (r *mmdbData) DataProvider() string {
+	return "MaxMind"
+}
+
+// IsVPN is only set by databases that still carry the deprecated is_anonymous_proxy trait; GeoLite2 does not,
+// so it is always false there (and the fraud score 0). Use an HTTP provider as the primary to detect VPNs.
+func (r *mmdbData) IsVPN() bool {
+	return r.CityRecord.Traits.IsAnonymousProxy
+}
+
+func (r *mmdbData) Latitude() float64 {
+	return r.CityRecord.Location.Latitude
+}
+
+func (r *mmdbData) Longitude() float64 {
+	return r.CityRecord.Location.Longitude
+}
+
+func (r *mmdbData) City() string {
+	return r.CityRecord.City.Names["en"]
+}
+
+func (r *mmdbData) Region() string {
+	if len(r.CityRecord.Subdivisions) == 0 {
+		return ""
+	}
+	return r.CityRecord.Subdivisions[0].ISOCode
+}
+
+func (r *mmdbData) CountryCode() string {
+	return r.CityRecord.Country.ISOCode
+}
+
+func (r *mmdbData) GeoHash(geoPrecision uint) string {
+	return geohash.EncodeWithPrecision(r.Latitude(), r.Longitude(), geoPrecision)
+}
+
+func (r *mmdbData) ASN() int {
+	return int(r.ASNRecord.AutonomousSystemNumber)
+}
+
+func (r *mmdbData) FraudScore() int {
+	if r.IsVPN() {
+		return 100
+	}
+	return 0
+}
+
+func (r *mmdbData) ISP() string {
+	return r.ASNRecord.AutonomousSystemOrganization
+}
+
+func (r *mmdbData) Organization() string {
+	return r.ASNRecord.AutonomousSystemOrganization
+}
+
+// mmdbFile is a database file that is reopened when it changes on disk.
+type mmdbFile struct {
+	path    string
+	modTime time.Time
+	reader  *maxminddb.Reader
+}
+
+// reload reopens the database if its modification time has changed. It returns whether it was reopened.
+// The previous reader is returned, to be closed once no lookups are using it.
+func (f *mmdbFile) reload() (bool, *maxminddb.Reader, error) {
+	if f.path == "" {
+		return false, nil, nil
+	}
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, nil, err
+	}
+	if f.reader != nil && info.ModTime().Equal(f.modTime) {
+		return false, nil, nil
+	}
+	// The file is read into memory, rather than mapped, so that it can be replaced in place while it is in use.
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return false, nil, err
+	}
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+	previous := f.reader
+	f.reader, f.modTime = reader, info.ModTime()
+	return true, previous, nil
+}
+
+var _ = IPInfoProvider(&mmdbClient{})
+
+// mmdbClient looks up IP addresses in local MaxMind-format City and ASN databases.
+// It needs no network access, so its results are deterministic and free. An address is only found if it is in the
+// City database; the ASN database adds to its record. VPNs are not detected with GeoLite2 databases, because the
+// is_anonymous_proxy trait is deprecated there, so IsVPN and FraudScore are false and 0 when this is the primary provider.
+type mmdbClient struct {
+	ctx      context.Context
+	cancelFn context.CancelFunc
+
+	logger  *zap.Logger
+	metrics Metrics
+
+	sync.RWMutex
+	city *mmdbFile
+	asn  *mmdbFile
+}
+
+// NewMMDBClient opens the City database, and the optional ASN database, and reloads them when they change.
+func NewMMDBClient(logger *zap.Logger, metrics Metrics, cityPath, asnPath string) (*mmdbClient, error) {
+	if cityPath == "" {
+		return nil, errors.New("no MMDB City database file configured")
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	client := mmdbClient{
+		ctx:      ctx,
+		cancelFn: cancelFn,
+
+		logger:  logger,
+		metrics: metrics,
+
+		city: &mmdbFile{path: cityPath},
+		asn:  &mmdbFile{path: asnPath},
+	}
+
+	if err := client.Reload(); err != nil {
+		cancelFn()
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(mmdbReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := client.Reload(); err != nil {
+					logger.Warn("Failed to reload MMDB databases, keeping the loaded ones.", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return &client, nil
+}
+
+func (s *mmdbClient) Name() string {
+	return "MMDB"
+}
+
+// Reload reopens the databases that have changed on disk.
+func (s *mmdbClient) Reload() error {
+	s.Lock()
+	defer s.Unlock()
+
+	var errs []error
+	for _, f := range []*mmdbFile{s.city, s.asn} {
+		reloaded, previous, err := f.reload()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if previous != nil {
+			// The lock is held, so no lookups are using the previous reader.
+			previous.Close()
+		}
+		if reloaded {
+			s.logger.Info("Loaded MMDB database", zap.String("path", f.path), zap.String("type", f.reader.Metadata.DatabaseType), zap.Time("build", time.Unix(int64(f.reader.Metadata.BuildEpoch), 0).UTC()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *mmdbClient) Get(ctx context.Context, ip string) (IPInfo, error) {
+	if s == nil {
+		return nil, nil
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	// ignore reserved IPs
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsMulticast() || addr.IsPrivate() {
+		return &StubIPInfo{}, nil
+	}
+
+	startTime := time.Now()
+	metricsTags := map[string]string{"result": "found"}
+	defer func() {
+		s.metrics.CustomTimer("mmdb_lookup_duration", metricsTags, time.Since(startTime))
+	}()
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.city.reader == nil {
+		// Closed.
+		metricsTags["result"] = "not_found"
+		return nil, nil
+	}
+	data := &mmdbData{}
+	// Without the location, the record would place the address at 0,0; let the next provider try instead.
+	if _, ok, err := s.city.reader.LookupNetwork(addr, &data.CityRecord); err != nil {
+		metricsTags["result"] = "error"
+		return nil, fmt.Errorf("failed to look up %s: %w", ip, err)
+	} else if !ok {
+		metricsTags["result"] = "not_found"
+		return nil, nil
+	}
+	if s.asn.reader != nil {
+		if _, _, err := s.asn.reader.LookupNetwork(addr, &data.ASNRecord); err != nil {
+			metricsTags["result"] = "error"
+			return nil, fmt.Errorf("failed to look up %s: %w", ip, err)
+		}
+	}
+	return data, nil
+}
+
+func (s *mmdbClient) Close() {
+	s.cancelFn()
+	s.Lock()
+	defer s.Unlock()
+	for _, f := range []*mmdbFile{s.city, s.asn} {
+		if f.reader != nil {
+			f.reader.Close()
+			f.reader = nil
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mmdbEncode encodes a value in the MaxMind DB data section format.
+func mmdbEncode(buf *bytes.Buffer, v any) {
+	control := func(typ, size int) {
+		extra := -1
+		if size >= 29 {
+			size, extra = 29, size-29 // Sizes up to 284 are enough for the tests.
+		}
+		if typ <= 7 {
+			buf.WriteByte(byte(typ<<5 | size))
+		} else {
+			buf.WriteByte(byte(size))
+			buf.WriteByte(byte(typ - 7))
+		}
+		if extra >= 0 {
+			buf.WriteByte(byte(extra))
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		control(2, len(v))
+		buf.WriteString(v)
+	case float64:
+		control(3, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		control(5, 2)
+		binary.Write(buf, binary.BigEndian, v)
+	case uint32:
+		control(6, 4)
+		binary.Write(buf, binary.BigEndian, v)
+	case uint64:
+		control(9, 8)
+		binary.Write(buf, binary.BigEndian, v)
+	case bool:
+		b := 0
+		if v {
+			b = 1
+		}
+		control(14, b)
+	case []any:
+		control(11, len(v))
+		for _, e := range v {
+			mmdbEncode(buf, e)
+		}
+	case map[string]any:
+		control(7, len(v))
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			mmdbEncode(buf, k)
+			mmdbEncode(buf, v[k])
+		}
+	default:
+		panic("unsupported type")
+	}
+}
+
+// writeTestMMDB writes an IPv4 database, with 32 bit records, that maps one network to the record.
+func writeTestMMDB(t *testing.T, path string, databaseType string, network string, record map[string]any) {
+	_, ipNet, err := net.ParseCIDR(network)
+	require.NoError(t, err)
+	ip := ipNet.IP.To4()
+	prefix, _ := ipNet.Mask.Size()
+
+	// One node per bit of the prefix; the last node points to the record, and the other branches to "not found".
+	nodeCount := uint32(prefix)
+	tree := &bytes.Buffer{}
+	for i := range prefix {
+		bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+		next := uint32(i + 1)
+		if i == prefix-1 {
+			next = nodeCount + 16 // The data section offset of the record.
+		}
+		records := [2]uint32{nodeCount, nodeCount}
+		records[bit] = next
+		binary.Write(tree, binary.BigEndian, records)
+	}
+
+	data := &bytes.Buffer{}
+	mmdbEncode(data, record)
+
+	metadata := &bytes.Buffer{}
+	mmdbEncode(metadata, map[string]any{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(time.Now().Unix()),
+		"database_type":               databaseType,
+		"description":                 map[string]any{"en": "test"},
+		"ip_version":                  uint16(4),
+		"languages":                   []any{"en"},
+		"node_count":                  nodeCount,
+		"record_size":                 uint16(32),
+	})
+
+	file := &bytes.Buffer{}
+	file.Write(tree.Bytes())
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	file.Write(metadata.Bytes())
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o644))
+}
+
+func testMMDBCityRecord(city string, subdivision string) map[string]any {
+	return map[string]any{
+		"city":         map[string]any{"names": map[string]any{"en": city}},
+		"country":      map[string]any{"iso_code": "US"},
+		"subdivisions": []any{map[string]any{"iso_code": subdivision}},
+		"location":     map[string]any{"latitude": 47.6062, "longitude": -122.3321},
+	}
+}
+
+func TestMMDBClient_Get(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := filepath.Join(dir, "city.mmdb")
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	writeTestMMDB(t, cityPath, "GeoLite2-City", "81.2.69.0/24", testMMDBCityRecord("Seattle", "WA"))
+	writeTestMMDB(t, asnPath, "GeoLite2-ASN", "81.2.0.0/16", map[string]any{
+		"autonomous_system_number":       uint32(20712),
+		"autonomous_system_organization": "Andrews & Arnold Ltd",
+	})
+
+	client, err := NewMMDBClient(zap.NewNop(), metrics, cityPath, asnPath)
+	require.NoError(t, err)
+	defer client.Close()
+
+	info, err := client.Get(context.Background(), "81.2.69.160")
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "MaxMind", info.DataProvider())
+	assert.Equal(t, "Seattle", info.City())
+	assert.Equal(t, "WA", info.Region())
+	assert.Equal(t, "US", info.CountryCode())
+	assert.InDelta(t, 47.6062, info.Latitude(), 0.0001)
+	assert.Equal(t, "c2", info.GeoHash(2))
+	assert.Equal(t, 20712, info.ASN())
+	assert.Equal(t, "Andrews & Arnold Ltd", info.Organization())
+	assert.False(t, info.IsVPN())
+
+	// Only the ASN database has the address, so there is no location; the next provider is tried.
+	info, err = client.Get(context.Background(), "81.2.1.1")
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	// Neither database has the address, so the next provider is tried.
+	info, err = client.Get(context.Background(), "8.8.8.8")
+	require.NoError(t, err)
+	assert.Nil(t, info)
+
+	info, err = client.Get(context.Background(), "192.168.1.1")
+	require.NoError(t, err)
+	assert.IsType(t, &StubIPInfo{}, info)
+}
+
+func TestNewMMDBClient_RequiresCity(t *testing.T) {
+	asnPath := filepath.Join(t.TempDir(), "asn.mmdb")
+	writeTestMMDB(t, asnPath, "GeoLite2-ASN", "81.2.0.0/16", map[string]any{"autonomous_system_number": uint32(20712)})
+	_, err := NewMMDBClient(zap.NewNop(), metrics, "", asnPath)
+	assert.Error(t, err)
+}
+
+func TestMMDBClient_Reload(t *testing.T) {
+	cityPath := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestMMDB(t, cityPath, "GeoLite2-City", "81.2.69.0/24", testMMDBCityRecord("Seattle", "WA"))
+
+	client, err := NewMMDBClient(zap.NewNop(), metrics, cityPath, "")
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Unchanged files are not reopened.
+	reader := client.city.reader
+	require.NoError(t, client.Reload())
+	assert.Same(t, reader, client.city.reader)
+
+	writeTestMMDB(t, cityPath, "GeoLite2-City", "81.2.69.0/24", testMMDBCityRecord("Portland", "OR"))
+	require.NoError(t, os.Chtimes(cityPath, time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, client.Reload())
+
+	info, err := client.Get(context.Background(), "81.2.69.160")
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "Portland", info.City())
+	assert.Equal(t, "OR", info.Region())
+
+	// A broken file keeps the loaded database.
+	require.NoError(t, os.WriteFile(cityPath, []byte("not a database"), 0o644))
+	require.NoError(t, os.Chtimes(cityPath, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.Error(t, client.Reload())
+	info, err = client.Get(context.Background(), "81.2.69.160")
+	require.NoError(t, err)
+	assert.Equal(t, "Portland", info.City())
+}
+
+func TestIPInfoCache_MMDBFallback(t *testing.T) {
+	cityPath := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestMMDB(t, cityPath, "GeoLite2-City", "81.2.69.0/24", testMMDBCityRecord("Seattle", "WA"))
+
+	client, err := NewMMDBClient(zap.NewNop(), metrics, cityPath, "")
+	require.NoError(t, err)
+	defer client.Close()
+
+	cache, err := NewIPInfoCache(zap.NewNop(), nil, failingIPInfoProvider{}, client)
+	require.NoError(t, err)
+
+	info, err := cache.Get(context.Background(), "81.2.69.160")
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "Seattle", info.City())
+}
+
+type failingIPInfoProvider struct{}
+
+func (failingIPInfoProvider) Name() string { return "failing" }
+
+func (failingIPInfoProvider) Get(ctx context.Context, ip string) (IPInfo, error) {
+	return nil, assert.AnError
+}
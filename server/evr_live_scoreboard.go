@@ -0,0 +1,655 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	LiveScoreboardPath              = "/apievr/live/scoreboard"
+	LiveScoreboardStorageCollection = "LiveScoreboard"
+
+	LiveScoreboardPollInterval     = time.Second
+	LiveScoreboardClockSync        = 5 * time.Second  // How often the scoreboard is sent while nothing changes, to keep overlay clocks in step.
+	LiveScoreboardKeepAlive        = 15 * time.Second // How often an idle stream receives a comment, to keep proxies from closing it.
+	LiveScoreboardMaxDelay         = 10 * time.Minute
+	LiveScoreboardDefaultTTL       = 12 * time.Hour
+	LiveScoreboardMaxTTL           = 7 * 24 * time.Hour
+	LiveScoreboardPruneInterval    = time.Hour
+	liveScoreboardSubscriberBuffer = 64
+
+	LiveScoreboardEventSnapshot   = "snapshot" // The full scoreboard, sent when a stream opens.
+	LiveScoreboardEventScoreboard = "scoreboard"
+	LiveScoreboardEventRoster     = "roster"
+	LiveScoreboardEventGoal       = "goal"
+	LiveScoreboardEventEnd        = "end"
+)
+
+var ErrLiveScoreboardToken = errors.New("invalid or revoked scoreboard token")
+
+// LiveScoreboardPlayer is a player on the roster. It holds nothing that isn't shown in the game.
+type LiveScoreboardPlayer struct {
+	DisplayName string `json:"display_name"`
+	Team        string `json:"team"`
+}
+
+type LiveScoreboardGoal struct {
+	Team           string  `json:"team"`
+	DisplayName    string  `json:"display_name"`
+	AssistName     string  `json:"assist_name,omitempty"`
+	GoalType       string  `json:"goal_type"`
+	Points         int     `json:"points"`
+	RoundClockSecs float64 `json:"round_clock_s"`
+}
+
+// LiveScoreboard is the public view of a match's game state, as sent to stream overlays.
+type LiveScoreboard struct {
+	MatchID       string                 `json:"match_id"`
+	Mode          string                 `json:"mode"`
+	Level         string                 `json:"level"`
+	BlueScore     int                    `json:"blue_score"`
+	OrangeScore   int                    `json:"orange_score"`
+	GameTimeSecs  float64                `json:"game_time_s"`
+	RoundDuration float64                `json:"round_duration_s,omitempty"`
+	Paused        bool                   `json:"paused"`
+	MatchOver     bool                   `json:"match_over"`
+	Roster        []LiveScoreboardPlayer `json:"roster"`
+	Goals         []LiveScoreboardGoal   `json:"goals"`
+	ObservedAt    time.Time              `json:"observed_at"` // When the server saw this state; the feed's delay is applied to it.
+}
+
+// NewLiveScoreboard builds the public view of the match state. It is called from the match loop.
+func NewLiveScoreboard(state *MatchLabel, goals []*evr.MatchGoal, now time.Time) *LiveScoreboard {
+	s := &LiveScoreboard{
+		MatchID:    state.ID.String(),
+		Mode:       state.Mode.String(),
+		Level:      state.Level.String(),
+		Roster:     make([]LiveScoreboardPlayer, 0, len(state.Players)),
+		Goals:      make([]LiveScoreboardGoal, 0, len(goals)),
+		ObservedAt: now,
+	}
+	if gs := state.GameState; gs != nil {
+		s.BlueScore, s.OrangeScore = gs.BlueScore, gs.OrangeScore
+		s.MatchOver = gs.MatchOver
+		if sb := gs.SessionScoreboard; sb != nil {
+			s.GameTimeSecs = sb.Elapsed().Seconds()
+			s.RoundDuration = sb.RoundDuration.Seconds()
+			s.Paused = sb.IsPaused()
+		}
+	}
+	for _, p := range state.Players {
+		if p.Team != BlueTeam && p.Team != OrangeTeam {
+			continue
+		}
+		s.Roster = append(s.Roster, LiveScoreboardPlayer{DisplayName: p.DisplayName, Team: p.Team.String()})
+	}
+	slices.SortStableFunc(s.Roster, func(a, b LiveScoreboardPlayer) int {
+		if a.Team != b.Team {
+			if a.Team == BlueTeam.String() {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	for _, g := range goals {
+		team := BlueTeam
+		if g.TeamID != 0 {
+			team = OrangeTeam
+		}
+		points := g.PointsValue
+		if points == 0 {
+			points = GoalTypeToPoints(g.GoalType)
+		}
+		s.Goals = append(s.Goals, LiveScoreboardGoal{
+			Team:           team.String(),
+			DisplayName:    g.DisplayName,
+			AssistName:     g.PrevPlayerDisplayName,
+			GoalType:       g.GoalType,
+			Points:         points,
+			RoundClockSecs: g.GoalTime,
+		})
+	}
+	return s
+}
+
+// LiveScoreboardEvent is one server-sent event of the feed.
+type LiveScoreboardEvent struct {
+	Name string
+	Data any
+}
+
+// liveScoreboardScore is the part of the scoreboard that is sent on the scoreboard event.
+type liveScoreboardScore struct {
+	BlueScore     int       `json:"blue_score"`
+	OrangeScore   int       `json:"orange_score"`
+	GameTimeSecs  float64   `json:"game_time_s"`
+	RoundDuration float64   `json:"round_duration_s,omitempty"`
+	Paused        bool      `json:"paused"`
+	MatchOver     bool      `json:"match_over"`
+	ObservedAt    time.Time `json:"observed_at"`
+}
+
+func (s *LiveScoreboard) score() liveScoreboardScore {
+	return liveScoreboardScore{
+		BlueScore:     s.BlueScore,
+		OrangeScore:   s.OrangeScore,
+		GameTimeSecs:  s.GameTimeSecs,
+		RoundDuration: s.RoundDuration,
+		Paused:        s.Paused,
+		MatchOver:     s.MatchOver,
+		ObservedAt:    s.ObservedAt,
+	}
+}
+
+// LiveScoreboardEvents returns the events that take a feed from one scoreboard to the next. The game clock
+// alone doesn't make a scoreboard event; the clock sync does.
+func LiveScoreboardEvents(prev, next *LiveScoreboard) []LiveScoreboardEvent {
+	if prev == nil {
+		return []LiveScoreboardEvent{{Name: LiveScoreboardEventSnapshot, Data: next}}
+	}
+	events := make([]LiveScoreboardEvent, 0, 2)
+	if !slices.Equal(prev.Roster, next.Roster) {
+		events = append(events, LiveScoreboardEvent{Name: LiveScoreboardEventRoster, Data: next.Roster})
+	}
+	// Goals are only ever appended.
+	for i := len(prev.Goals); i < len(next.Goals); i++ {
+		events = append(events, LiveScoreboardEvent{Name: LiveScoreboardEventGoal, Data: next.Goals[i]})
+	}
+	if prev.BlueScore != next.BlueScore || prev.OrangeScore != next.OrangeScore || prev.Paused != next.Paused ||
+		prev.MatchOver != next.MatchOver || prev.RoundDuration != next.RoundDuration {
+		events = append(events, LiveScoreboardEvent{Name: LiveScoreboardEventScoreboard, Data: next.score()})
+	}
+	return events
+}
+
+// LiveScoreboardFeed opts a match in to the public scoreboard feed. Reissuing the feed revokes its previous token.
+type LiveScoreboardFeed struct {
+	MatchID    MatchID   `json:"match_id"`
+	GroupID    string    `json:"group_id"`
+	FeedID     string    `json:"feed_id"` // Matches the token's ID.
+	DelaySecs  int       `json:"delay_s"`
+	CreatedBy  string    `json:"created_by"`
+	CreateTime time.Time `json:"create_time"`
+	ExpiryTime time.Time `json:"expiry_time"`
+	version    string
+}
+
+func (f *LiveScoreboardFeed) StorageMeta() StorableMetadata {
+	return StorableMetadata{
+		Collection:      LiveScoreboardStorageCollection,
+		Key:             f.MatchID.UUID.String(),
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		Version:         f.version,
+	}
+}
+
+func (f *LiveScoreboardFeed) SetStorageMeta(meta StorableMetadata) {
+	f.version = meta.Version
+}
+
+func (f *LiveScoreboardFeed) Delay() time.Duration {
+	return time.Duration(f.DelaySecs) * time.Second
+}
+
+var _ = jwt.Claims(&LiveScoreboardTokenClaims{})
+
+// LiveScoreboardTokenClaims scope a token to one match's feed.
+type LiveScoreboardTokenClaims struct {
+	MatchID string `json:"mid"`
+	FeedID  string `json:"fid"`
+	jwt.RegisteredClaims
+}
+
+// LiveScoreboardSigningKey derives the key that feed tokens are signed with from the session encryption key, so
+// that a feed token can never be mistaken for a session token, or the other way round.
+func LiveScoreboardSigningKey(config Config) string {
+	mac := hmac.New(sha256.New, []byte(config.GetSession().EncryptionKey))
+	mac.Write([]byte("live-scoreboard"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewLiveScoreboardToken signs a token for the feed.
+func NewLiveScoreboardToken(signingKey string, feed *LiveScoreboardFeed) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &LiveScoreboardTokenClaims{
+		MatchID: feed.MatchID.String(),
+		FeedID:  feed.FeedID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(feed.CreateTime),
+			ExpiresAt: jwt.NewNumericDate(feed.ExpiryTime),
+		},
+	})
+	return token.SignedString([]byte(signingKey))
+}
+
+// parseLiveScoreboardToken verifies the token's signature and expiry, and returns its claims.
+func parseLiveScoreboardToken(signingKey, tokenString string) (*LiveScoreboardTokenClaims, error) {
+	claims := &LiveScoreboardTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if s, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || s.Hash != crypto.SHA256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(signingKey), nil
+	})
+	if err != nil || !token.Valid || claims.ExpiresAt == nil {
+		return nil, ErrLiveScoreboardToken
+	}
+	return claims, nil
+}
+
+// LiveScoreboardFeedRead reads the match's feed; it is nil if the match hasn't opted in.
+func LiveScoreboardFeedRead(ctx context.Context, nk runtime.NakamaModule, matchID MatchID) (*LiveScoreboardFeed, error) {
+	feed := &LiveScoreboardFeed{MatchID: matchID}
+	if err := StorableRead(ctx, nk, SystemUserID, feed, false); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return feed, nil
+}
+
+// LiveScoreboardAuthenticate returns the feed that the token was issued for, if it has not been reissued or revoked.
+func LiveScoreboardAuthenticate(ctx context.Context, nk runtime.NakamaModule, signingKey, token string, now time.Time) (*LiveScoreboardFeed, error) {
+	claims, err := parseLiveScoreboardToken(signingKey, token)
+	if err != nil {
+		return nil, err
+	}
+	matchID, err := MatchIDFromString(claims.MatchID)
+	if err != nil {
+		return nil, ErrLiveScoreboardToken
+	}
+	feed, err := LiveScoreboardFeedRead(ctx, nk, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil || feed.FeedID != claims.FeedID || !now.Before(feed.ExpiryTime) {
+		return nil, ErrLiveScoreboardToken
+	}
+	return feed, nil
+}
+
+// LiveScoreboardFeedIssue opts the match in to the feed, replacing any previous feed and its token.
+func LiveScoreboardFeedIssue(ctx context.Context, nk runtime.NakamaModule, signingKey string, label *MatchLabel, createdBy string, delay, ttl time.Duration, now time.Time) (*LiveScoreboardFeed, string, error) {
+	feed := &LiveScoreboardFeed{
+		MatchID:    label.ID,
+		GroupID:    label.GetGroupID().String(),
+		FeedID:     uuid.Must(uuid.NewV4()).String(),
+		DelaySecs:  int(delay.Seconds()),
+		CreatedBy:  createdBy,
+		CreateTime: now,
+		ExpiryTime: now.Add(ttl),
+	}
+	if err := StorableWrite(ctx, nk, SystemUserID, feed); err != nil {
+		return nil, "", fmt.Errorf("failed to write scoreboard feed: %w", err)
+	}
+	token, err := NewLiveScoreboardToken(signingKey, feed)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign scoreboard token: %w", err)
+	}
+	return feed, token, nil
+}
+
+// LiveScoreboardFeedRevoke opts the match out of the feed. Open streams end when they next check their token.
+func LiveScoreboardFeedRevoke(ctx context.Context, nk runtime.NakamaModule, matchID MatchID) error {
+	return nk.StorageDelete(ctx, []*runtime.StorageDelete{{
+		Collection: LiveScoreboardStorageCollection,
+		Key:        matchID.UUID.String(),
+		UserID:     SystemUserID,
+	}})
+}
+
+// LiveScoreboardFeedPrune deletes the feeds that have expired, and returns how many it deleted. A feed that is
+// reissued while it is pruned is kept.
+func LiveScoreboardFeedPrune(ctx context.Context, nk runtime.NakamaModule, now time.Time) (int, error) {
+	deletes := make([]*runtime.StorageDelete, 0)
+	cursor := ""
+	for {
+		objs, next, err := nk.StorageList(ctx, SystemUserID, SystemUserID, LiveScoreboardStorageCollection, 100, cursor)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list scoreboard feeds: %w", err)
+		}
+		for _, obj := range objs {
+			feed := &LiveScoreboardFeed{}
+			if err := json.Unmarshal([]byte(obj.GetValue()), feed); err != nil || now.Before(feed.ExpiryTime) {
+				continue
+			}
+			deletes = append(deletes, &runtime.StorageDelete{
+				Collection: LiveScoreboardStorageCollection,
+				Key:        obj.GetKey(),
+				UserID:     SystemUserID,
+				Version:    obj.GetVersion(),
+			})
+		}
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+
+	deleted := 0
+	for _, d := range deletes {
+		// Each is deleted alone, so that one feed that was reissued, or pruned by another node, doesn't keep the
+		// rest. A feed that fails for any other reason is pruned on the next pass.
+		if err := nk.StorageDelete(ctx, []*runtime.StorageDelete{d}); err == nil {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type liveScoreboardPending struct {
+	at         time.Time
+	scoreboard *LiveScoreboard // nil marks the end of the match.
+}
+
+// liveScoreboardPoller polls one match for all of the streams of its feed, and holds the scoreboards back by the delay.
+type liveScoreboardPoller struct {
+	key         string
+	matchID     MatchID
+	delay       time.Duration
+	subscribers map[chan LiveScoreboardEvent]struct{}
+	pending     []liveScoreboardPending // Observed, but not yet past the delay.
+	current     *LiveScoreboard         // The latest scoreboard past the delay.
+	lastScore   time.Time               // When the scoreboard event was last sent.
+	ended       bool
+}
+
+// LiveScoreboardHub shares one poller between the streams of each match's feed.
+type LiveScoreboardHub struct {
+	sync.Mutex
+	ctx     context.Context
+	logger  runtime.Logger
+	nk      runtime.NakamaModule
+	pollers map[string]*liveScoreboardPoller
+	fetch   func(ctx context.Context, matchID MatchID) (*LiveScoreboard, error)
+	exists  func(ctx context.Context, matchID MatchID) (bool, error)
+}
+
+func NewLiveScoreboardHub(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) *LiveScoreboardHub {
+	h := &LiveScoreboardHub{
+		ctx:     ctx,
+		logger:  logger,
+		nk:      nk,
+		pollers: make(map[string]*liveScoreboardPoller),
+	}
+	h.fetch = h.fetchScoreboard
+	h.exists = h.matchExists
+	return h
+}
+
+func (h *LiveScoreboardHub) fetchScoreboard(ctx context.Context, matchID MatchID) (*LiveScoreboard, error) {
+	data, err := SignalMatch(ctx, h.nk, matchID, SignalGetScoreboard, nil)
+	if err != nil {
+		return nil, err
+	}
+	scoreboard := &LiveScoreboard{}
+	if err := json.Unmarshal([]byte(data), scoreboard); err != nil {
+		return nil, err
+	}
+	return scoreboard, nil
+}
+
+// matchExists reports whether the match is still running. A match hosted on another node can't be signaled, or
+// found with MatchGet, from this one, so it is looked up by its label.
+func (h *LiveScoreboardHub) matchExists(ctx context.Context, matchID MatchID) (bool, error) {
+	if match, err := h.nk.MatchGet(ctx, matchID.String()); err != nil {
+		return false, err
+	} else if match != nil {
+		return true, nil
+	}
+	if _nk, ok := h.nk.(*RuntimeGoNakamaModule); ok && matchID.Node == _nk.node {
+		return false, nil
+	}
+	matches, err := h.nk.MatchList(ctx, 1, true, "", nil, nil, fmt.Sprintf("+label.id:%s", Query.QuoteStringValue(matchID.String())))
+	if err != nil {
+		return false, err
+	}
+	return len(matches) > 0, nil
+}
+
+// Subscribe returns the events of the feed. The first is the current snapshot, if the feed has one; the
+// channel is closed when the match ends, or when the subscriber falls too far behind.
+func (h *LiveScoreboardHub) Subscribe(feed *LiveScoreboardFeed) (<-chan LiveScoreboardEvent, func()) {
+	ch := make(chan LiveScoreboardEvent, liveScoreboardSubscriberBuffer)
+	key := fmt.Sprintf("%s/%d", feed.MatchID.String(), feed.DelaySecs)
+
+	h.Lock()
+	p, ok := h.pollers[key]
+	if !ok {
+		p = &liveScoreboardPoller{
+			key:         key,
+			matchID:     feed.MatchID,
+			delay:       feed.Delay(),
+			subscribers: make(map[chan LiveScoreboardEvent]struct{}),
+		}
+		h.pollers[key] = p
+		go h.poll(p)
+	}
+	p.subscribers[ch] = struct{}{}
+	if p.current != nil {
+		ch <- LiveScoreboardEvent{Name: LiveScoreboardEventSnapshot, Data: p.current}
+	}
+	h.Unlock()
+
+	return ch, func() {
+		h.Lock()
+		defer h.Unlock()
+		if _, ok := p.subscribers[ch]; ok {
+			delete(p.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *LiveScoreboardHub) poll(p *liveScoreboardPoller) {
+	ticker := time.NewTicker(LiveScoreboardPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			h.stop(p)
+			return
+		case <-ticker.C:
+		}
+		if !h.step(p, time.Now().UTC()) {
+			return
+		}
+	}
+}
+
+// step polls the match, and publishes what has passed the delay. It returns false once the poller has stopped.
+func (h *LiveScoreboardHub) step(p *liveScoreboardPoller, now time.Time) bool {
+	h.Lock()
+	idle := len(p.subscribers) == 0
+	h.Unlock()
+	if idle {
+		h.stop(p)
+		return false
+	}
+
+	if !p.ended {
+		switch scoreboard, err := h.fetch(h.ctx, p.matchID); {
+		case errors.Is(err, runtime.ErrMatchNotFound):
+			// Only this node's matches can be signaled; the feed ends once the match is gone, and the end is held
+			// back by the delay like the rest of the feed.
+			if exists, err := h.exists(h.ctx, p.matchID); err == nil && !exists {
+				p.ended = true
+				p.pending = append(p.pending, liveScoreboardPending{at: now})
+			}
+		case err != nil:
+			// The match may be busy; it is polled again on the next tick.
+		default:
+			scoreboard.ObservedAt = now
+			p.pending = append(p.pending, liveScoreboardPending{at: now, scoreboard: scoreboard})
+		}
+	}
+
+	// Only this goroutine writes the current scoreboard, but Subscribe reads it under the lock.
+	current := p.current
+	events := make([]LiveScoreboardEvent, 0, 4)
+	ended := false
+	for len(p.pending) > 0 && now.Sub(p.pending[0].at) >= p.delay {
+		next := p.pending[0].scoreboard
+		p.pending = p.pending[1:]
+		if next == nil {
+			ended = true
+			events = append(events, LiveScoreboardEvent{Name: LiveScoreboardEventEnd, Data: map[string]string{"match_id": p.matchID.String()}})
+			break
+		}
+		for _, e := range LiveScoreboardEvents(current, next) {
+			if e.Name == LiveScoreboardEventScoreboard || e.Name == LiveScoreboardEventSnapshot {
+				p.lastScore = now
+			}
+			events = append(events, e)
+		}
+		current = next
+	}
+	if !ended && current != nil && now.Sub(p.lastScore) >= LiveScoreboardClockSync {
+		p.lastScore = now
+		events = append(events, LiveScoreboardEvent{Name: LiveScoreboardEventScoreboard, Data: current.score()})
+	}
+
+	h.Lock()
+	p.current = current
+	for ch := range p.subscribers {
+		for _, e := range events {
+			select {
+			case ch <- e:
+			default:
+				// The subscriber is too far behind to follow the feed.
+				delete(p.subscribers, ch)
+				close(ch)
+			}
+			if _, ok := p.subscribers[ch]; !ok {
+				break
+			}
+		}
+	}
+	h.Unlock()
+
+	if ended {
+		h.stop(p)
+		return false
+	}
+	return true
+}
+
+func (h *LiveScoreboardHub) stop(p *liveScoreboardPoller) {
+	h.Lock()
+	defer h.Unlock()
+	if h.pollers[p.key] == p {
+		delete(h.pollers, p.key)
+	}
+	for ch := range p.subscribers {
+		delete(p.subscribers, ch)
+		close(ch)
+	}
+}
+
+// NewLiveScoreboardHandler serves the feed as server-sent events. It is public: the token, scoped to one
+// match, is the only credential, so that stream overlays can use the URL directly.
+func NewLiveScoreboardHandler(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) func(http.ResponseWriter, *http.Request) {
+	hub := NewLiveScoreboardHub(ctx, logger, nk)
+	signingKey := LiveScoreboardSigningKey(nk.(*RuntimeGoNakamaModule).config)
+
+	go func() {
+		ticker := time.NewTicker(LiveScoreboardPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := LiveScoreboardFeedPrune(ctx, nk, time.Now().UTC()); err != nil {
+					logger.WithField("error", err).Warn("Failed to prune live scoreboard feeds")
+				}
+			}
+		}
+	}()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		feed, err := LiveScoreboardAuthenticate(r.Context(), nk, signingKey, token, time.Now().UTC())
+		if err != nil {
+			if errors.Is(err, ErrLiveScoreboardToken) {
+				_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: err.Error()}, http.StatusUnauthorized)
+			} else {
+				_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Internal Server Error"}, http.StatusInternalServerError)
+			}
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		// The server's write timeout would otherwise cut the stream.
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		events, cancel := hub.Subscribe(feed)
+		defer cancel()
+
+		keepAlive := time.NewTicker(LiveScoreboardKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				// A reissued or revoked token ends the stream.
+				if _, err := LiveScoreboardAuthenticate(r.Context(), nk, signingKey, token, time.Now().UTC()); errors.Is(err, ErrLiveScoreboardToken) {
+					_ = writeServerSentEvent(w, LiveScoreboardEvent{Name: LiveScoreboardEventEnd, Data: map[string]string{"match_id": feed.MatchID.String(), "reason": "revoked"}})
+					_ = rc.Flush()
+					return
+				}
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := writeServerSentEvent(w, e); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, e LiveScoreboardEvent) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Name, data)
+	return err
+}
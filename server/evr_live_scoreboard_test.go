@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLiveScoreboard(t *testing.T) {
+	now := time.Now().UTC()
+	state := &MatchLabel{
+		ID:   MatchID{UUID: uuid.Must(uuid.NewV4()), Node: "node"},
+		Mode: evr.ModeArenaPublic,
+		Players: []PlayerInfo{
+			{DisplayName: "orange1", Team: OrangeTeam, UserID: "u1", ClientIP: "10.0.0.1"},
+			{DisplayName: "spec", Team: Spectator},
+			{DisplayName: "blue1", Team: BlueTeam},
+		},
+		GameState: &GameState{BlueScore: 2, OrangeScore: 3, SessionScoreboard: &SessionScoreboard{RoundDuration: 5 * time.Minute, GameTime: time.Minute, UpdatedAt: now}},
+	}
+	goals := []*evr.MatchGoal{
+		{GoalType: "INSIDE SHOT", DisplayName: "blue1", TeamID: 0, GoalTime: 12},
+		{GoalType: "LONG SHOT", DisplayName: "orange1", TeamID: 1, GoalTime: 40, PrevPlayerDisplayName: "orange2"},
+	}
+
+	s := NewLiveScoreboard(state, goals, now)
+	assert.Equal(t, []LiveScoreboardPlayer{{DisplayName: "blue1", Team: "blue"}, {DisplayName: "orange1", Team: "orange"}}, s.Roster)
+	require.Len(t, s.Goals, 2)
+	assert.Equal(t, LiveScoreboardGoal{Team: "blue", DisplayName: "blue1", GoalType: "INSIDE SHOT", Points: 2, RoundClockSecs: 12}, s.Goals[0])
+	assert.Equal(t, "orange2", s.Goals[1].AssistName)
+	assert.Equal(t, 3, s.Goals[1].Points)
+	assert.Equal(t, 2, s.BlueScore)
+	assert.Equal(t, 300.0, s.RoundDuration)
+	assert.GreaterOrEqual(t, s.GameTimeSecs, 60.0)
+}
+
+func TestLiveScoreboardEvents(t *testing.T) {
+	base := &LiveScoreboard{Roster: []LiveScoreboardPlayer{{DisplayName: "a", Team: "blue"}}, GameTimeSecs: 10}
+
+	events := LiveScoreboardEvents(nil, base)
+	require.Len(t, events, 1)
+	assert.Equal(t, LiveScoreboardEventSnapshot, events[0].Name)
+
+	// The clock alone doesn't make an event.
+	ticked := *base
+	ticked.GameTimeSecs = 11
+	assert.Empty(t, LiveScoreboardEvents(base, &ticked))
+
+	scored := ticked
+	scored.BlueScore = 2
+	scored.Goals = []LiveScoreboardGoal{{Team: "blue", DisplayName: "a", Points: 2}}
+	scored.Roster = append([]LiveScoreboardPlayer{}, base.Roster...)
+	scored.Roster = append(scored.Roster, LiveScoreboardPlayer{DisplayName: "b", Team: "orange"})
+	events = LiveScoreboardEvents(&ticked, &scored)
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{LiveScoreboardEventRoster, LiveScoreboardEventGoal, LiveScoreboardEventScoreboard}, names)
+	assert.Equal(t, scored.Goals[0], events[1].Data)
+}
+
+func TestLiveScoreboardToken(t *testing.T) {
+	now := time.Now().UTC()
+	feed := &LiveScoreboardFeed{
+		MatchID:    MatchID{UUID: uuid.Must(uuid.NewV4()), Node: "node"},
+		FeedID:     uuid.Must(uuid.NewV4()).String(),
+		CreateTime: now,
+		ExpiryTime: now.Add(time.Hour),
+	}
+	token, err := NewLiveScoreboardToken("key", feed)
+	require.NoError(t, err)
+
+	claims, err := parseLiveScoreboardToken("key", token)
+	require.NoError(t, err)
+	assert.Equal(t, feed.MatchID.String(), claims.MatchID)
+	assert.Equal(t, feed.FeedID, claims.FeedID)
+
+	_, err = parseLiveScoreboardToken("otherkey", token)
+	assert.ErrorIs(t, err, ErrLiveScoreboardToken)
+
+	feed.ExpiryTime = now.Add(-time.Minute)
+	expired, err := NewLiveScoreboardToken("key", feed)
+	require.NoError(t, err)
+	_, err = parseLiveScoreboardToken("key", expired)
+	assert.ErrorIs(t, err, ErrLiveScoreboardToken)
+
+	// Feed tokens aren't signed with the session key itself.
+	config := NewConfig(logger)
+	signingKey := LiveScoreboardSigningKey(config)
+	assert.NotEqual(t, config.GetSession().EncryptionKey, signingKey)
+	assert.Equal(t, signingKey, LiveScoreboardSigningKey(config))
+	_, err = parseLiveScoreboardToken(signingKey, token)
+	assert.ErrorIs(t, err, ErrLiveScoreboardToken)
+}
+
+func TestLiveScoreboardHub_Delay(t *testing.T) {
+	feed := &LiveScoreboardFeed{MatchID: MatchID{UUID: uuid.Must(uuid.NewV4()), Node: "node"}, DelaySecs: 10}
+	hub := &LiveScoreboardHub{ctx: context.Background(), pollers: make(map[string]*liveScoreboardPoller)}
+
+	scores := []int{0, 2, 2}
+	polls := 0
+	busy := false
+	hub.fetch = func(ctx context.Context, matchID MatchID) (*LiveScoreboard, error) {
+		if busy {
+			return nil, fmt.Errorf("failed to signal match: %w", runtime.ErrMatchBusy)
+		}
+		if polls >= len(scores) {
+			return nil, fmt.Errorf("failed to signal match: %w", runtime.ErrMatchNotFound)
+		}
+		s := &LiveScoreboard{MatchID: matchID.String(), BlueScore: scores[polls]}
+		polls++
+		return s, nil
+	}
+	exists := false
+	hub.exists = func(ctx context.Context, matchID MatchID) (bool, error) {
+		return exists, nil
+	}
+
+	// Build the poller without its goroutine, and step it by hand.
+	p := &liveScoreboardPoller{key: "k", matchID: feed.MatchID, delay: feed.Delay(), subscribers: make(map[chan LiveScoreboardEvent]struct{})}
+	hub.pollers["k"] = p
+	ch := make(chan LiveScoreboardEvent, liveScoreboardSubscriberBuffer)
+	p.subscribers[ch] = struct{}{}
+
+	start := time.Now().UTC()
+	receive := func() []string {
+		names := make([]string, 0)
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					return append(names, "closed")
+				}
+				names = append(names, e.Name)
+			default:
+				return names
+			}
+		}
+	}
+
+	require.True(t, hub.step(p, start))
+	require.True(t, hub.step(p, start.Add(time.Second)))
+	assert.Empty(t, receive(), "nothing is sent before the delay")
+
+	require.True(t, hub.step(p, start.Add(10*time.Second)))
+	assert.Equal(t, []string{LiveScoreboardEventSnapshot}, receive())
+
+	// Other errors don't end the feed.
+	busy = true
+	require.True(t, hub.step(p, start.Add(10500*time.Millisecond)))
+	assert.False(t, p.ended)
+	busy = false
+
+	// A match that can't be signaled from this node, but is still running, doesn't end the feed.
+	exists = true
+	require.True(t, hub.step(p, start.Add(10600*time.Millisecond)))
+	assert.False(t, p.ended)
+	exists = false
+
+	// The goal seen at 1s is released at 11s, when the match is found to have ended.
+	require.True(t, hub.step(p, start.Add(11*time.Second)))
+	assert.Equal(t, []string{LiveScoreboardEventScoreboard}, receive())
+
+	// The end is held back until 21s.
+	require.True(t, hub.step(p, start.Add(15*time.Second)))
+	assert.Empty(t, receive())
+	assert.False(t, hub.step(p, start.Add(21*time.Second)))
+	assert.Equal(t, []string{LiveScoreboardEventEnd, "closed"}, receive())
+	assert.Empty(t, hub.pollers)
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"math/rand"
 	"slices"
 	"sort"
 	"strings"
@@ -26,29 +25,27 @@ import (
 type LobbyBuilder struct {
 	sync.Mutex
 	logger *zap.Logger
+	db     *sql.DB
 	nk     runtime.NakamaModule
 
 	sessionRegistry SessionRegistry
 	matchRegistry   MatchRegistry
 	tracker         Tracker
 	metrics         Metrics
-
-	mapQueue map[evr.Symbol][]evr.Symbol // map[mode][]level
 }
 
-func NewLobbyBuilder(logger *zap.Logger, nk runtime.NakamaModule, sessionRegistry SessionRegistry, matchRegistry MatchRegistry, tracker Tracker, metrics Metrics) *LobbyBuilder {
+func NewLobbyBuilder(logger *zap.Logger, db *sql.DB, nk runtime.NakamaModule, sessionRegistry SessionRegistry, matchRegistry MatchRegistry, tracker Tracker, metrics Metrics) *LobbyBuilder {
 	logger = logger.With(zap.String("module", "lobby_builder"))
 
 	return &LobbyBuilder{
 		logger: logger,
+		db:     db,
 		nk:     nk,
 
 		sessionRegistry: sessionRegistry,
 		matchRegistry:   matchRegistry,
 		tracker:         tracker,
 		metrics:         metrics,
-
-		mapQueue: make(map[evr.Symbol][]evr.Symbol),
 	}
 }
 
@@ -214,10 +211,12 @@ func (b *LobbyBuilder) buildMatch(logger *zap.Logger, entrants []*MatchmakerEntr
 	}
 
 	mode := evr.ToSymbol(modestr)
+	level, rotation := b.selectNextMap(ctx, logger, groupID, mode)
 
 	settings := &MatchSettings{
 		Mode:                mode,
-		Level:               b.selectNextMap(mode),
+		Level:               level,
+		MapRotation:         rotation,
 		SpawnedBy:           SystemUserID,
 		GroupID:             groupID,
 		Reservations:        entrantPresences,
@@ -378,37 +377,20 @@ func countByExtIP(labels []*MatchLabel) map[string]int {
 	return countByExtIP
 }
 
-func (b *LobbyBuilder) selectNextMap(mode evr.Symbol) evr.Symbol {
-	queue := b.mapQueue[mode]
-
-	if levels, ok := b.mapQueue[mode]; !ok || len(levels) == 0 {
-		return evr.LevelUnspecified
-	} else if len(evr.LevelsByMode[mode]) == 1 {
-		return evr.LevelsByMode[mode][0]
+// selectNextMap picks the level with the guild's map rotation policy. Levels are tracked per guild and mode
+// in storage, so the rotation is shared by all nodes and survives restarts.
+func (b *LobbyBuilder) selectNextMap(ctx context.Context, logger *zap.Logger, groupID uuid.UUID, mode evr.Symbol) (evr.Symbol, *MapRotationSelection) {
+	metadata, err := GroupMetadataLoad(ctx, b.db, groupID.String())
+	if err != nil {
+		logger.Warn("Failed to load guild group metadata for the map rotation", zap.Error(err))
 	}
+	policy, source := MapRotationPolicyFor(metadata)
 
-	if len(queue) <= 1 {
-		// Fill the queue with the available levels and shuffle.
-		queue = append(queue, evr.LevelsByMode[mode]...)
-
-		rand.Shuffle(len(queue), func(i, j int) {
-			// leave the first (next) level in place
-			if i == 0 || j == 0 {
-				return
-			}
-			queue[i], queue[j] = queue[j], queue[i]
-		})
-
-		// If the first two levels are the same, move the first level to the end of the queue.
-		if queue[0] == queue[1] {
-			queue = append(queue[1:], queue[0])
-		}
+	level, playlist, err := MapRotationNext(ctx, b.nk, policy, groupID.String(), mode)
+	if err != nil {
+		logger.Warn("Failed to update the map rotation", zap.Error(err))
 	}
-
-	// Pop the first level from the queue
-	b.mapQueue[mode] = queue[1:]
-
-	return queue[0]
+	return level, &MapRotationSelection{Source: source, Playlist: playlist}
 }
 
 // CompactedFrequencySort sorts a slice of items by frequency and removes duplicates.
@@ -566,8 +548,10 @@ func LobbyGameServerAllocate(ctx context.Context, logger runtime.Logger, nk runt
 		return nil, ErrMatchmakingNoAvailableServers
 	}
 
-	indexes := make([]labelIndex, len(availableServers))
-	for i, label := range availableServers {
+	registry := globalGameServerRegistry.Load()
+
+	indexes := make([]labelIndex, 0, len(availableServers))
+	for _, label := range availableServers {
 		extIP := label.GameServer.Endpoint.ExternalIP.String()
 		hostID := label.GameServer.Endpoint.GetHostID()
 
@@ -575,6 +559,11 @@ func LobbyGameServerAllocate(ctx context.Context, logger runtime.Logger, nk runt
 			continue
 		}
 
+		// Skip servers that the fleet registry is draining for failing their health checks
+		if registry != nil && !registry.IsAvailable(label.GameServer.SessionID) {
+			continue
+		}
+
 		regionMatch := false
 		for _, region := range label.GameServer.RegionCodes {
 			if region == RegionDefault {
@@ -605,7 +594,7 @@ func LobbyGameServerAllocate(ctx context.Context, logger runtime.Logger, nk runt
 			rtt += delta
 		}
 
-		indexes[i] = labelIndex{
+		indexes = append(indexes, labelIndex{
 			Label:             label,
 			RTT:               (rtt + 10) / 20 * 20,
 			IsReachable:       rttsByExternalIP[extIP] != 0,
@@ -614,7 +603,7 @@ func LobbyGameServerAllocate(ctx context.Context, logger runtime.Logger, nk runt
 			ActiveCount:       activeCountByHostID[hostID],
 			IsRegionMatch:     regionMatch,
 			IsHighLatency:     rttsByExternalIP[extIP] > 100,
-		}
+		})
 	}
 
 	sortLabelIndexes(indexes)
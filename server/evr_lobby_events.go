@@ -21,8 +21,21 @@ const (
 	sessionEventCollectionName = "session_events"
 )
 
-// ctxMongoClientKey is the context key for the MongoDB client
-type ctxMongoClientKey struct{}
+// SessionEventsRequest is the payload of session_events/get.
+type SessionEventsRequest struct {
+	MatchUUID string `json:"match_id"`
+}
+
+type SessionEventsResponse struct {
+	MatchUUID string          `json:"match_id"`
+	Count     int             `json:"count"`
+	Events    []*SessionEvent `json:"events"`
+}
+
+type SessionEventStoreResponse struct {
+	Success bool    `json:"success"`
+	MatchID MatchID `json:"match_id"`
+}
 
 // SessionEvent represents a simple session event object
 type SessionEvent struct {
@@ -90,13 +103,14 @@ func RetrieveSessionEventsByMatchID(ctx context.Context, mongoClient *mongo.Clie
 }
 
 // GetSessionEventsRPC is a Nakama RPC endpoint that retrieves session events by match_id
-// Expected query parameter: match_id
 func GetSessionEventsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
-	// Parse the payload as JSON to extract match_id
-	var request struct {
-		MatchUUID string `json:"match_id"`
+	mongoClient := globalMongoClient.Load()
+	if mongoClient == nil {
+		return "", runtime.NewError("session events are not available", StatusUnavailable)
 	}
 
+	// Parse the payload as JSON to extract match_id
+	var request SessionEventsRequest
 	if err := json.Unmarshal([]byte(payload), &request); err != nil {
 		return "", fmt.Errorf("invalid request payload: %w", err)
 	}
@@ -111,15 +125,6 @@ func GetSessionEventsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB,
 		return "", fmt.Errorf("match_id is required in request payload")
 	}
 
-	// Get MongoDB client from the runtime module
-	// Note: You'll need to pass the mongo client to this RPC function
-	// For now, we'll assume it's available through the runtime context or you'll need to modify
-	// the RPC registration to include it
-	mongoClient, ok := ctx.Value(ctxMongoClientKey{}).(*mongo.Client)
-	if !ok || mongoClient == nil {
-		return "", fmt.Errorf("mongodb client not available in context")
-	}
-
 	// Retrieve events from MongoDB
 	events, err := RetrieveSessionEventsByMatchID(ctx, mongoClient, request.MatchUUID)
 	if err != nil {
@@ -128,10 +133,10 @@ func GetSessionEventsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB,
 	}
 
 	// Marshal response
-	response := map[string]interface{}{
-		"match_id": request.MatchUUID,
-		"count":    len(events),
-		"events":   events,
+	response := SessionEventsResponse{
+		MatchUUID: request.MatchUUID,
+		Count:     len(events),
+		Events:    events,
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -143,8 +148,13 @@ func GetSessionEventsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB,
 }
 
 // StoreSessionEventRPC is a Nakama RPC endpoint that stores a session event to MongoDB
-// Expected payload: SessionEvent JSON object
+// Expected payload: LobbySessionStateFrame JSON object
 func StoreSessionEventRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	mongoClient := globalMongoClient.Load()
+	if mongoClient == nil {
+		return "", runtime.NewError("session events are not available", StatusUnavailable)
+	}
+
 	// Parse the payload as SessionEvent
 
 	msg := &rtapi.LobbySessionStateFrame{}
@@ -170,12 +180,6 @@ func StoreSessionEventRPC(ctx context.Context, logger runtime.Logger, db *sql.DB
 		Data:    msg,
 	}
 
-	// Get MongoDB client from the runtime module
-	mongoClient, ok := ctx.Value(ctxMongoClientKey{}).(*mongo.Client)
-	if !ok || mongoClient == nil {
-		return "", fmt.Errorf("mongodb client not available in context")
-	}
-
 	// Store the event to MongoDB
 	if err := StoreSessionEvent(ctx, mongoClient, event); err != nil {
 		logger.Error("Failed to store session event", "error", err, "match_id", event.MatchID)
@@ -183,9 +187,9 @@ func StoreSessionEventRPC(ctx context.Context, logger runtime.Logger, db *sql.DB
 	}
 
 	// Return success response
-	response := map[string]interface{}{
-		"success":  true,
-		"match_id": event.MatchID,
+	response := SessionEventStoreResponse{
+		Success: true,
+		MatchID: event.MatchID,
 	}
 
 	responseJSON, err := json.Marshal(response)
@@ -196,30 +200,3 @@ func StoreSessionEventRPC(ctx context.Context, logger runtime.Logger, db *sql.DB
 	logger.Debug("Stored session event", "match_id", event.MatchID)
 	return string(responseJSON), nil
 }
-
-// RegisterSessionEventRPCs registers the session event RPC endpoints
-func RegisterSessionEventRPCs(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, initializer runtime.Initializer, mongoClient *mongo.Client) error {
-	// Create a wrapper function that injects the mongo client into the context
-	getSessionEventsWrapper := func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
-		// Inject mongo client into context
-		ctx = context.WithValue(ctx, ctxMongoClientKey{}, mongoClient)
-		return GetSessionEventsRPC(ctx, logger, db, nk, payload)
-	}
-
-	storeSessionEventWrapper := func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
-		// Inject mongo client into context
-		ctx = context.WithValue(ctx, ctxMongoClientKey{}, mongoClient)
-		return StoreSessionEventRPC(ctx, logger, db, nk, payload)
-	}
-
-	if err := initializer.RegisterRpc("session_events/get", getSessionEventsWrapper); err != nil {
-		return fmt.Errorf("failed to register session_events/get RPC: %w", err)
-	}
-
-	if err := initializer.RegisterRpc("session_events/store", storeSessionEventWrapper); err != nil {
-		return fmt.Errorf("failed to register session_events/store RPC: %w", err)
-	}
-
-	logger.Info("Registered session event RPC endpoints")
-	return nil
-}
@@ -146,13 +146,13 @@ func (p *EvrPipeline) lobbyFind(ctx context.Context, logger *zap.Logger, session
 
 		// Notify the user that they are an early quitter.
 		message := fmt.Sprintf("Your early quit penalty is active (level %d), your matchmaking has been delayed by %d seconds.", lobbyParams.EarlyQuitPenaltyLevel, int(interval.Seconds()))
-		if _, err := SendUserMessage(ctx, p.appBot.dg, lobbyParams.DiscordID, message); err != nil {
+		if _, err := SendUserMessage(ctx, p.discordCache.dg, lobbyParams.DiscordID, message); err != nil && !errors.Is(err, ErrDiscordDisabled) {
 			logger.Warn("Failed to send message to user", zap.Error(err))
 		}
 		if guildGroup := p.guildGroupRegistry.Get(lobbyParams.GroupID.String()); guildGroup != nil {
 			// Send an audit log message to the guild group.
 			content := fmt.Sprintf("notified early quitter <@!%s> (%s): %s ", lobbyParams.DiscordID, session.Username(), message)
-			if _, err = AuditLogSendGuild(p.appBot.dg, guildGroup, content); err != nil {
+			if _, err = AuditLogSendGuild(p.discordCache.dg, guildGroup, content); err != nil {
 				logger.Warn("Failed to send audit log message", zap.Error(err))
 			}
 		}
@@ -196,6 +196,11 @@ func LobbyJoinEntrants(logger *zap.Logger, matchRegistry MatchRegistry, tracker
 		<-time.After(125 * time.Millisecond)
 	}
 
+	// Follow the entrant into the match's recording, if it is being recorded on this node.
+	if recorders := globalEvrRecorders.Load(); recorders != nil {
+		recorders.Follow(label.ID, e.SessionID)
+	}
+
 	// Send the lobby session success message to the game client.
 	<-time.After(150 * time.Millisecond)
 
@@ -252,12 +257,14 @@ func (p *EvrPipeline) lobbyAuthorize(ctx context.Context, logger *zap.Logger, se
 		if gg.RoleMap.Member != "" && !gg.IsMember(userID) {
 			return joinRejected("not_member", "You are not a member of this private guild.", "not a member of private guild")
 		} else {
-			// If the member role is not set, the user must be a member of the guild.
-			if guildMember, err := p.discordCache.GuildMember(gg.GuildID, lobbyParams.DiscordID); err != nil {
-				if !IsDiscordErrorCode(err, discordgo.ErrCodeUnknownMember) {
+			// If the member role is not set, the user must be a member of the community.
+			if member, err := p.guildGroupRegistry.CommunityMember(ctx, gg, userID); err != nil {
+				if errors.Is(err, ErrMemberNotFound) {
+					return joinRejected("not_member", "You are not a member of this private guild.", "not a member of private guild")
+				} else if !errors.Is(err, ErrDiscordDisabled) && !errors.Is(err, ErrCommunityIdentityNotFound) {
 					p.logger.Warn("Failed to get guild member. failing open.", zap.String("guild_id", gg.GuildID), zap.Error(err))
 				}
-			} else if guildMember == nil || guildMember.Pending {
+			} else if member == nil || member.Pending {
 				return joinRejected("not_member", "You are not a member of this private guild.", "not a member of private guild")
 			}
 		}
@@ -320,7 +327,7 @@ func (p *EvrPipeline) lobbyAuthorize(ctx context.Context, logger *zap.Logger, se
 				metricTag = "limited_access_user"
 			}
 
-			expires := fmt.Sprintf(" [exp: %s]", FormatDuration(time.Until(suspensionRecord.Expiry)))
+			expires := suspensionAppealNotice(params.suspensionAppeals, suspensionRecord.ID) + fmt.Sprintf(" [exp: %s]", FormatDuration(time.Until(suspensionRecord.Expiry)))
 			if len(reason)+len(expires) > maxMessageLength {
 				reason = reason[:maxMessageLength-len(expires)-3] + "..."
 			}
@@ -409,7 +416,9 @@ func (p *EvrPipeline) lobbyAuthorize(ctx context.Context, logger *zap.Logger, se
 			message := &discordgo.MessageSend{
 				Embeds: []*discordgo.MessageEmbed{embed},
 			}
-			if _, err := p.appBot.dg.ChannelMessageSendComplex(gg.AuditChannelID, message); err != nil {
+			if !p.discordCache.Enabled() || gg.AuditChannelID == "" {
+				// Audit channels are disabled without a Discord session.
+			} else if _, err := p.discordCache.dg.ChannelMessageSendComplex(gg.AuditChannelID, message); err != nil {
 				p.logger.Warn("Failed to send audit message", zap.String("channel_id", gg.AuditChannelID), zap.Error(err))
 			}
 
@@ -451,17 +460,15 @@ func (p *EvrPipeline) lobbyAuthorize(ctx context.Context, logger *zap.Logger, se
 	// Force the players name to match in-game
 	if gg.DisplayNameForceNickToIGN {
 		go func() {
-			// Search for them in a match from this guild
-			member, err := p.discordCache.GuildMember(gg.GuildID, params.DiscordID())
-			if err != nil {
-				logger.Warn("Failed to get guild member", zap.Error(err))
-			} else if member != nil {
-				if displayName != InGameName(member) {
-					AuditLogSendGuild(p.discordCache.dg, gg, fmt.Sprintf("Setting display name for `%s` to match in-game name: `%s`", member.User.Username, displayName))
-					// Force the display name to match the in-game name
-					if err := p.discordCache.dg.GuildMemberNickname(gg.GuildID, member.User.ID, displayName); err != nil {
-						logger.Warn("Failed to set display name", zap.Error(err))
-					}
+			if memberNick, err := p.guildGroupRegistry.CommunityInGameName(ctx, gg, userID); err != nil {
+				if !IsCommunityUnavailable(err) && !errors.Is(err, ErrMemberNotFound) {
+					logger.Warn("Failed to get community in-game name", zap.Error(err))
+				}
+			} else if displayName != memberNick {
+				AuditLogSendGuild(p.discordCache.dg, gg, fmt.Sprintf("Setting display name for `%s` to match in-game name: `%s`", params.profile.Username(), displayName))
+				// Force the display name to match the in-game name
+				if err := p.guildGroupRegistry.CommunitySetInGameName(ctx, gg, userID, displayName); err != nil {
+					logger.Warn("Failed to set display name", zap.Error(err))
 				}
 			}
 		}()
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const (
+	MapRotationStorageCollection = "MapRotation"
+
+	MapRotationSourceGuild   = "guild"
+	MapRotationSourceService = "service"
+	MapRotationSourceDefault = "default"
+
+	mapRotationHistoryLength = 20 // The number of recent levels kept per guild and mode.
+	mapRotationWriteAttempts = 3
+)
+
+// The default policy only avoids playing the same level twice in a row.
+var defaultMapRotationPolicy = &MapRotationPolicy{NoRepeat: 1}
+
+// MapRotationPolicy controls which level the lobby builder picks for a matchmade lobby.
+// It is set per guild in the group metadata, with a fallback in the service settings.
+type MapRotationPolicy struct {
+	Pools     map[evr.Symbol][]MapWeight `json:"pools,omitempty"`     // Weighted levels by mode. Modes without a pool use all of their levels, equally weighted.
+	NoRepeat  int                        `json:"no_repeat,omitempty"` // Do not pick a level played in the guild's last N matches of the mode.
+	Disabled  []DisabledMap              `json:"disabled,omitempty"`  // Levels that are not picked.
+	Playlists []MapPlaylist              `json:"playlists,omitempty"` // Scheduled pools that replace Pools while active. The first active playlist with a pool for the mode is used.
+}
+
+type MapWeight struct {
+	Level  evr.Symbol `json:"level"`
+	Weight float64    `json:"weight,omitempty"` // A zero weight counts as 1.
+}
+
+type DisabledMap struct {
+	Level  evr.Symbol `json:"level"`
+	Until  time.Time  `json:"until,omitempty"` // Zero disables the level until it is removed.
+	Reason string     `json:"reason,omitempty"`
+}
+
+// MapPlaylist is a pool that is active during an event, or at a time of day.
+type MapPlaylist struct {
+	Name       string                     `json:"name"`
+	Start      time.Time                  `json:"start,omitempty"`       // Zero for no start.
+	End        time.Time                  `json:"end,omitempty"`         // Zero for no end.
+	DailyStart string                     `json:"daily_start,omitempty"` // "15:04" UTC. The window wraps past midnight if the start is after the end.
+	DailyEnd   string                     `json:"daily_end,omitempty"`   // "15:04" UTC.
+	Pools      map[evr.Symbol][]MapWeight `json:"pools"`
+}
+
+// MapRotationSelection records how the level of a match was picked. It is reported in the match label.
+type MapRotationSelection struct {
+	Source   string `json:"source"`             // The policy used: guild, service or default.
+	Playlist string `json:"playlist,omitempty"` // The active playlist, if any.
+}
+
+func (p *MapPlaylist) Active(now time.Time) bool {
+	if !p.Start.IsZero() && now.Before(p.Start) {
+		return false
+	}
+	if !p.End.IsZero() && !now.Before(p.End) {
+		return false
+	}
+	if p.DailyStart == "" && p.DailyEnd == "" {
+		return true
+	}
+	start, _ := parseTimeOfDay(p.DailyStart)
+	end, err := parseTimeOfDay(p.DailyEnd)
+	if p.DailyEnd == "" || err != nil {
+		end = 24 * time.Hour
+	}
+	now = now.UTC()
+	t := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second
+	if start <= end {
+		return start <= t && t < end
+	}
+	return t >= start || t < end
+}
+
+// parseTimeOfDay parses "15:04" into the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func validateMapPools(pools map[evr.Symbol][]MapWeight) error {
+	for mode, weights := range pools {
+		levels, ok := evr.LevelsByMode[mode]
+		if !ok {
+			return fmt.Errorf("invalid mode: %s", mode)
+		}
+		for _, w := range weights {
+			if !slices.Contains(levels, w.Level) {
+				return fmt.Errorf("invalid level %s for mode %s", w.Level, mode)
+			}
+			if w.Weight < 0 {
+				return fmt.Errorf("negative weight for level %s", w.Level)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *MapRotationPolicy) Validate() error {
+	if p.NoRepeat < 0 || p.NoRepeat > mapRotationHistoryLength {
+		return fmt.Errorf("no_repeat must be between 0 and %d", mapRotationHistoryLength)
+	}
+	if err := validateMapPools(p.Pools); err != nil {
+		return err
+	}
+	for _, d := range p.Disabled {
+		if d.Level.IsNil() {
+			return errors.New("disabled level is required")
+		}
+	}
+	for i, pl := range p.Playlists {
+		if pl.Name == "" {
+			return fmt.Errorf("playlist %d: name is required", i)
+		}
+		if len(pl.Pools) == 0 {
+			return fmt.Errorf("playlist %s: pools are required", pl.Name)
+		}
+		if !pl.Start.IsZero() && !pl.End.IsZero() && !pl.Start.Before(pl.End) {
+			return fmt.Errorf("playlist %s: start must be before end", pl.Name)
+		}
+		for _, s := range []string{pl.DailyStart, pl.DailyEnd} {
+			if _, err := parseTimeOfDay(s); err != nil {
+				return fmt.Errorf("playlist %s: invalid time of day %q", pl.Name, s)
+			}
+		}
+		if err := validateMapPools(pl.Pools); err != nil {
+			return fmt.Errorf("playlist %s: %w", pl.Name, err)
+		}
+	}
+	return nil
+}
+
+// IsDisabled returns whether the level is disabled at the time.
+func (p *MapRotationPolicy) IsDisabled(level evr.Symbol, now time.Time) bool {
+	for _, d := range p.Disabled {
+		if d.Level == level && (d.Until.IsZero() || now.Before(d.Until)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pool returns the enabled, weighted levels for the mode, and the name of the active playlist, if any.
+func (p *MapRotationPolicy) Pool(mode evr.Symbol, now time.Time) ([]MapWeight, string) {
+	var (
+		pool     []MapWeight
+		playlist string
+	)
+	for _, pl := range p.Playlists {
+		if weights, ok := pl.Pools[mode]; ok && len(weights) > 0 && pl.Active(now) {
+			pool, playlist = weights, pl.Name
+			break
+		}
+	}
+	if pool == nil {
+		pool = p.Pools[mode]
+	}
+	if len(pool) == 0 {
+		pool = make([]MapWeight, 0, len(evr.LevelsByMode[mode]))
+		for _, level := range evr.LevelsByMode[mode] {
+			pool = append(pool, MapWeight{Level: level})
+		}
+	}
+
+	enabled := make([]MapWeight, 0, len(pool))
+	for _, w := range pool {
+		if !p.IsDisabled(w.Level, now) {
+			enabled = append(enabled, w)
+		}
+	}
+	return enabled, playlist
+}
+
+// Select picks a weighted level for the mode, avoiding the recent levels (most recent last).
+// If every level was played recently, the least recently played ones are allowed again.
+// It returns evr.LevelUnspecified if the mode has no enabled levels.
+func (p *MapRotationPolicy) Select(mode evr.Symbol, recent []evr.Symbol, now time.Time) (evr.Symbol, string) {
+	pool, playlist := p.Pool(mode, now)
+	if len(pool) == 0 {
+		return evr.LevelUnspecified, playlist
+	}
+
+	candidates := pool
+	for n := min(p.NoRepeat, len(recent)); n > 0; n-- {
+		excluded := recent[len(recent)-n:]
+		filtered := make([]MapWeight, 0, len(pool))
+		for _, w := range pool {
+			if !slices.Contains(excluded, w.Level) {
+				filtered = append(filtered, w)
+			}
+		}
+		if len(filtered) > 0 {
+			candidates = filtered
+			break
+		}
+	}
+
+	total := 0.0
+	for _, w := range candidates {
+		total += mapWeight(w)
+	}
+	r := rand.Float64() * total
+	for _, w := range candidates {
+		if r -= mapWeight(w); r < 0 {
+			return w.Level, playlist
+		}
+	}
+	return candidates[len(candidates)-1].Level, playlist
+}
+
+func mapWeight(w MapWeight) float64 {
+	if w.Weight == 0 {
+		return 1
+	}
+	return w.Weight
+}
+
+// MapRotationHistory is the recent levels of a guild's matches in a mode, shared by all nodes.
+type MapRotationHistory struct {
+	GroupID string       `json:"group_id"`
+	Mode    evr.Symbol   `json:"mode"`
+	Recent  []evr.Symbol `json:"recent"` // Most recent last.
+	version string
+}
+
+func NewMapRotationHistory(groupID string, mode evr.Symbol) *MapRotationHistory {
+	return &MapRotationHistory{
+		GroupID: groupID,
+		Mode:    mode,
+		Recent:  make([]evr.Symbol, 0),
+	}
+}
+
+func (h *MapRotationHistory) StorageMeta() StorableMetadata {
+	return StorableMetadata{
+		Collection:      MapRotationStorageCollection,
+		Key:             h.GroupID + ":" + h.Mode.HexString(),
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		Version:         h.version,
+	}
+}
+
+func (h *MapRotationHistory) SetStorageMeta(meta StorableMetadata) {
+	h.version = meta.Version
+}
+
+func (h *MapRotationHistory) Push(level evr.Symbol) {
+	h.Recent = append(h.Recent, level)
+	if len(h.Recent) > mapRotationHistoryLength {
+		h.Recent = h.Recent[len(h.Recent)-mapRotationHistoryLength:]
+	}
+}
+
+// MapRotationPolicyFor returns the policy for the guild: its own, the service default, or the built-in default.
+func MapRotationPolicyFor(metadata *GroupMetadata) (*MapRotationPolicy, string) {
+	if metadata != nil && metadata.MapRotation != nil && metadata.MapRotation.Validate() == nil {
+		return metadata.MapRotation, MapRotationSourceGuild
+	}
+	if s := ServiceSettings(); s != nil && s.Matchmaking.MapRotation != nil && s.Matchmaking.MapRotation.Validate() == nil {
+		return s.Matchmaking.MapRotation, MapRotationSourceService
+	}
+	return defaultMapRotationPolicy, MapRotationSourceDefault
+}
+
+// MapRotationNext picks the next level for a guild's match, and records it in the shared history.
+// The history is written with its version, so concurrent picks on other nodes are retried against each other.
+func MapRotationNext(ctx context.Context, nk runtime.NakamaModule, policy *MapRotationPolicy, groupID string, mode evr.Symbol) (evr.Symbol, string, error) {
+	var err error
+	for range mapRotationWriteAttempts {
+		history := NewMapRotationHistory(groupID, mode)
+		if err = StorableRead(ctx, nk, SystemUserID, history, true); err != nil {
+			continue
+		}
+		level, playlist := policy.Select(mode, history.Recent, time.Now().UTC())
+		if level == evr.LevelUnspecified {
+			return level, playlist, nil
+		}
+		history.Push(level)
+		if err = StorableWrite(ctx, nk, SystemUserID, history); err == nil {
+			return level, playlist, nil
+		}
+	}
+	// Still pick a level, without the history, rather than failing the match.
+	level, playlist := policy.Select(mode, nil, time.Now().UTC())
+	return level, playlist, fmt.Errorf("failed to update map rotation history: %w", err)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapRotationPolicy_Select(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	levels := evr.LevelsByMode[evr.ModeArenaPublic]
+	require.NotEmpty(t, levels)
+
+	t.Run("no repeat", func(t *testing.T) {
+		policy := &MapRotationPolicy{NoRepeat: len(levels) - 1}
+		recent := make([]evr.Symbol, 0)
+		for range 3 * len(levels) {
+			level, _ := policy.Select(evr.ModeArenaPublic, recent, now)
+			n := min(len(recent), policy.NoRepeat)
+			assert.NotContains(t, recent[len(recent)-n:], level)
+			recent = append(recent, level)
+		}
+	})
+
+	t.Run("no repeat is relaxed when every level was played", func(t *testing.T) {
+		policy := &MapRotationPolicy{NoRepeat: 5, Pools: map[evr.Symbol][]MapWeight{evr.ModeArenaPublic: {{Level: levels[0]}}}}
+		level, _ := policy.Select(evr.ModeArenaPublic, []evr.Symbol{levels[0]}, now)
+		assert.Equal(t, levels[0], level)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		policy := &MapRotationPolicy{}
+		for _, l := range levels[1:] {
+			policy.Disabled = append(policy.Disabled, DisabledMap{Level: l})
+		}
+		policy.Disabled = append(policy.Disabled, DisabledMap{Level: levels[0], Until: now.Add(-time.Minute)})
+		level, _ := policy.Select(evr.ModeArenaPublic, nil, now)
+		assert.Equal(t, levels[0], level)
+
+		policy.Disabled[len(policy.Disabled)-1].Until = now.Add(time.Minute)
+		level, _ = policy.Select(evr.ModeArenaPublic, nil, now)
+		assert.Equal(t, evr.LevelUnspecified, level)
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		if len(levels) < 2 {
+			t.Skip("needs two levels")
+		}
+		policy := &MapRotationPolicy{Pools: map[evr.Symbol][]MapWeight{evr.ModeArenaPublic: {{Level: levels[0], Weight: 1e9}, {Level: levels[1], Weight: 1e-9}}}}
+		level, _ := policy.Select(evr.ModeArenaPublic, nil, now)
+		assert.Equal(t, levels[0], level)
+	})
+
+	t.Run("playlist", func(t *testing.T) {
+		policy := &MapRotationPolicy{
+			Playlists: []MapPlaylist{
+				{Name: "night", DailyStart: "22:00", DailyEnd: "02:00", Pools: map[evr.Symbol][]MapWeight{evr.ModeArenaPublic: {{Level: levels[0]}}}},
+			},
+		}
+		require.NoError(t, policy.Validate())
+
+		_, playlist := policy.Select(evr.ModeArenaPublic, nil, now)
+		assert.Empty(t, playlist)
+
+		level, playlist := policy.Select(evr.ModeArenaPublic, nil, time.Date(2025, 6, 1, 23, 30, 0, 0, time.UTC))
+		assert.Equal(t, "night", playlist)
+		assert.Equal(t, levels[0], level)
+
+		_, playlist = policy.Select(evr.ModeArenaPublic, nil, time.Date(2025, 6, 2, 1, 0, 0, 0, time.UTC))
+		assert.Equal(t, "night", playlist)
+	})
+}
+
+func TestMapRotationPolicy_Validate(t *testing.T) {
+	arena := evr.LevelsByMode[evr.ModeArenaPublic][0]
+	tests := []struct {
+		name    string
+		policy  MapRotationPolicy
+		wantErr bool
+	}{
+		{"empty", MapRotationPolicy{}, false},
+		{"level of another mode", MapRotationPolicy{Pools: map[evr.Symbol][]MapWeight{evr.ModeCombatPublic: {{Level: arena}}}}, true},
+		{"negative weight", MapRotationPolicy{Pools: map[evr.Symbol][]MapWeight{evr.ModeArenaPublic: {{Level: arena, Weight: -1}}}}, true},
+		{"no repeat too long", MapRotationPolicy{NoRepeat: mapRotationHistoryLength + 1}, true},
+		{"playlist without name", MapRotationPolicy{Playlists: []MapPlaylist{{Pools: map[evr.Symbol][]MapWeight{evr.ModeArenaPublic: {{Level: arena}}}}}}, true},
+		{"invalid time of day", MapRotationPolicy{Playlists: []MapPlaylist{{Name: "x", DailyStart: "25:00", Pools: map[evr.Symbol][]MapWeight{evr.ModeArenaPublic: {{Level: arena}}}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestMapRotationPolicy_JSON(t *testing.T) {
+	data := []byte(`{"pools":{"echo_arena":[{"level":"mpl_arena_a","weight":2}]},"no_repeat":2}`)
+	policy := &MapRotationPolicy{}
+	require.NoError(t, json.Unmarshal(data, policy))
+	assert.Equal(t, []MapWeight{{Level: evr.LevelArena, Weight: 2}}, policy.Pools[evr.ModeArenaPublic])
+	assert.NoError(t, policy.Validate())
+}
@@ -67,6 +67,7 @@ const (
 	OpCodeEVRPacketData
 	OpCodeMatchGameStateUpdate
 	OpCodeGameServerLobbyStatus
+	OpCodeMatchServerFault
 )
 
 type MatchStatGroup string
@@ -99,6 +100,8 @@ type MatchSettings struct {
 	TeamAlignments      map[string]int
 	Reservations        []*EvrMatchPresence
 	ReservationLifetime time.Duration
+	MapRotation         *MapRotationSelection
+	Bracket             *BracketMatchRef
 }
 
 // This is the match handler for all matches.
@@ -154,6 +157,7 @@ func (m *EvrMatch) MatchInit(ctx context.Context, logger runtime.Logger, db *sql
 		joinTimestamps:       make(map[string]time.Time, SocialLobbyMaxSize),
 		joinTimeMilliseconds: make(map[string]int64, SocialLobbyMaxSize),
 		disconnectInfos:      make(map[string]*PlayerDisconnectInfo, SocialLobbyMaxSize),
+		roster:               make(map[string]*MatchHistoryPlayer, SocialLobbyMaxSize),
 		emptyTicks:           0,
 		tickRate:             10,
 	}
@@ -254,6 +258,13 @@ func (m *EvrMatch) MatchJoinAttempt(ctx context.Context, logger runtime.Logger,
 		return state, false, fmt.Sprintf("failed to unmarshal metadata: %v", err)
 	}
 
+	// A player rejoining within the reconnect window may take the slot that is held for them.
+	held, isHeld := state.heldEarlyQuitSlot(meta.Presence.GetUserId())
+	heldSlots := 0
+	if isHeld {
+		heldSlots = 1
+	}
+
 	// Check if the match is locked.
 	if !state.Open {
 
@@ -308,7 +319,7 @@ func (m *EvrMatch) MatchJoinAttempt(ctx context.Context, logger runtime.Logger,
 	}
 
 	// Ensure the match has enough slots available
-	if state.OpenSlots() < len(meta.Presences()) {
+	if state.OpenSlots()+heldSlots < len(meta.Presences()) {
 		return state, false, ErrJoinRejectReasonLobbyFull.Error()
 	}
 
@@ -360,8 +371,19 @@ func (m *EvrMatch) MatchJoinAttempt(ctx context.Context, logger runtime.Logger,
 	// check the available slots
 	if slots, err := state.OpenSlotsByRole(meta.Presence.RoleAlignment); err != nil {
 		return state, false, ErrJoinRejectReasonFailedToAssignTeam.Error()
-	} else if slots < len(meta.Presences()) {
-		return state, false, ErrJoinRejectReasonLobbyFull.Error()
+	} else {
+		if isHeld && held.Presence.RoleAlignment == meta.Presence.RoleAlignment {
+			slots++
+		}
+		if slots < len(meta.Presences()) {
+			return state, false, ErrJoinRejectReasonLobbyFull.Error()
+		}
+	}
+
+	// The join is accepted; the held slot is no longer needed.
+	if isHeld {
+		state.releaseEarlyQuitSlot(meta.Presence.GetUserId())
+		logger = logger.WithField("left_at", held.LeftAt)
 	}
 
 	// Add reservations to the reservation map
@@ -461,6 +483,16 @@ func (m *EvrMatch) MatchJoin(ctx context.Context, logger runtime.Logger, db *sql
 			}
 			nk.MetricsCounterAdd("match_entrant_join_count", tags, 1)
 			nk.MetricsTimerRecord("match_player_join_duration", tags, time.Since(state.joinTimestamps[p.GetSessionId()]))
+
+			state.historyJoin(mp)
+
+			if d, ok := state.rejoinedEarlyQuit(mp.GetUserId(), time.Now().UTC()); ok {
+				m.applyEarlyQuitDecision(ctx, logger, db, nk, state, d, state.decideEarlyQuit(d, time.Now().UTC(), currentEarlyQuitGraceSettings(), time.Now().UTC()))
+			}
+
+			if t := state.matchTelemetry(); t != nil {
+				t.Joined(ctx, nk, state, mp, isBackfill)
+			}
 		}
 
 		MatchDataEvent(ctx, nk, state.ID, MatchDataPlayerJoin{
@@ -515,6 +547,11 @@ func (m *EvrMatch) MatchLeave(ctx context.Context, logger runtime.Logger, db *sq
 	if state.Started() && len(state.presenceMap) == 0 {
 		// If the match is empty, and the server has left, then shut down.
 		logger.Debug("Match is empty. Shutting down.")
+		m.decideEarlyQuits(ctx, logger, db, nk, state, true)
+		m.recordMatchHistory(logger, db, state)
+		if state.telemetry != nil {
+			state.telemetry.Ended(ctx, nk, state)
+		}
 		return nil
 	}
 
@@ -523,6 +560,7 @@ func (m *EvrMatch) MatchLeave(ctx context.Context, logger runtime.Logger, db *sq
 		if p.GetSessionId() == state.GameServer.SessionID.String() {
 			logger.Debug("Server left the match. Shutting down.")
 			state.server = nil
+			state.serverLostAt = time.Now().UTC()
 			return m.MatchShutdown(ctx, logger, db, nk, dispatcher, tick, state, 2)
 		}
 	}
@@ -604,79 +642,16 @@ func (m *EvrMatch) MatchLeave(ctx context.Context, logger runtime.Logger, db *sq
 				// If the player has disconnect info, calculate the disconnect duration
 				info.LeaveEvent(state)
 			}
-			// If the round is not over, then add an early quit count to the player.
-			if state.Mode == evr.ModeArenaPublic && time.Now().After(state.StartTime.Add(time.Second*60)) && state.GameState != nil && !state.GameState.MatchOver {
-				for _, p := range presences {
-					if mp, ok := state.presenceMap[p.GetSessionId()]; ok {
-						// Only players
-						if !mp.IsPlayer() {
-							continue
-						}
-
-						nk.MetricsCounterAdd("match_entrant_early_quit", tags, 1)
-
-						logger.WithFields(map[string]interface{}{
-							"uid":          mp.GetUserId(),
-							"username":     mp.Username,
-							"evrid":        mp.EvrID,
-							"display_name": mp.DisplayName,
-						}).Debug("Incrementing early quit for player.")
-
-						if err := AccumulateLeaderboardStat(ctx, nk, mp.GetUserId(), mp.DisplayName, state.GetGroupID().String(), state.Mode, EarlyQuitStatisticID, 1); err != nil {
-							logger.Warn("Failed to record early quit to leaderboard: %v", err)
-						}
-					}
-
-					eqconfig := NewEarlyQuitConfig()
-					_nk := nk.(*RuntimeGoNakamaModule)
-					if err := StorableRead(ctx, nk, mp.GetUserId(), eqconfig, true); err != nil {
-						logger.WithField("error", err).Warn("Failed to load early quitter config")
-					} else {
+			earlyQuit := state.EarlyQuitApplies() && mp.IsPlayer()
+			state.historyLeave(mp, earlyQuit)
 
-						eqconfig.IncrementEarlyQuit()
-
-						// Check for tier change after early quit
-						serviceSettings := ServiceSettings()
-						oldTier, newTier, tierChanged := eqconfig.UpdateTier(serviceSettings.Matchmaking.EarlyQuitTier1Threshold)
-
-						logger.WithFields(map[string]interface{}{
-							"old_tier":     oldTier,
-							"new_tier":     newTier,
-							"tier_changed": tierChanged,
-							"eqconfig":     eqconfig,
-						}).Debug("Early quitter tier update.")
-
-						if err := StorableWrite(ctx, nk, mp.GetUserId(), eqconfig); err != nil {
-							logger.Warn("Failed to write early quitter config", zap.Error(err))
-						} else {
-							if s := _nk.sessionRegistry.Get(uuid.FromStringOrNil(mp.GetSessionId())); s != nil {
-								if params, ok := LoadParams(s.Context()); ok {
-									params.earlyQuitConfig.Store(eqconfig)
-								}
-							}
-
-							// Send Discord DM if tier changed
-							if tierChanged {
-								discordID, err := GetDiscordIDByUserID(ctx, db, mp.GetUserId())
-								if err != nil {
-									logger.Warn("Failed to get Discord ID for tier notification", zap.Error(err))
-								} else if appBot := globalAppBot.Load(); appBot != nil && appBot.dg != nil {
-									var message string
-									if oldTier > newTier {
-										// Degraded to Tier 2+
-										message = TierDegradedMessage
-									} else {
-										// Recovered to Tier 1
-										message = TierRestoredMessage
-									}
-									if _, err := SendUserMessage(ctx, appBot.dg, discordID, message); err != nil {
-										logger.Warn("Failed to send tier change DM", zap.Error(err))
-									}
-								}
-							}
-						}
-					}
-				}
+			// If the round is not over, hold the player's slot. The penalty is decided when the reconnect window closes.
+			if earlyQuit {
+				state.holdEarlyQuitSlot(mp, reason, time.Now().UTC(), currentEarlyQuitGraceSettings().ReconnectGrace)
+			}
+			// After the departure is recorded, so that it counts towards a lobby drop.
+			if state.telemetry != nil && mp.IsPlayer() {
+				state.telemetry.Left(ctx, nk, state, mp, p.GetReason())
 			}
 
 			delete(state.presenceMap, p.GetSessionId())
@@ -760,6 +735,26 @@ func (m *EvrMatch) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql
 				if len(update.Goals) > 0 {
 					state.goals = append(state.goals, update.Goals...)
 				}
+				if state.telemetry != nil {
+					state.telemetry.Goals(ctx, nk, state, state.goals)
+					if update.MatchOver {
+						state.telemetry.Ended(ctx, nk, state)
+					}
+				}
+				if update.MatchOver && state.Bracket != nil && !state.bracketReported {
+					// Advance the bracket with the final score.
+					state.GameState.Update(state.goals)
+					state.bracketReported = true
+					if err := SendEvent(ctx, nk, &EventBracketMatchEnded{
+						BracketID:      state.Bracket.BracketID,
+						BracketMatchID: state.Bracket.MatchID,
+						MatchID:        state.ID.String(),
+						BlueScore:      state.GameState.BlueScore,
+						OrangeScore:    state.GameState.OrangeScore,
+					}); err != nil {
+						logger.Error("Failed to send bracket match result: %v", err)
+					}
+				}
 
 				if state.GameState.SessionScoreboard != nil {
 					if update.CurrentGameClock != 0 {
@@ -772,6 +767,18 @@ func (m *EvrMatch) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql
 				}
 				updateLabel = true
 			}
+		case OpCodeMatchServerFault:
+			report := MatchServerFaultReport{}
+			if err := json.Unmarshal(in.GetData(), &report); err != nil {
+				logger.Error("Failed to unmarshal server fault report: %v", err)
+				continue
+			}
+			logger.WithFields(map[string]any{
+				"uid":    in.GetUserId(),
+				"reason": report.Reason,
+			}).Debug("Received server fault report.")
+			state.reportServerFault(in.GetUserId(), report.Reason)
+
 		case OpCodeGameServerLobbyStatus:
 			/*
 				lobbyStatus := evr.NEVRLobbyStatusV1{}
@@ -904,6 +911,18 @@ func (m *EvrMatch) MatchLoop(ctx context.Context, logger runtime.Logger, db *sql
 		updateLabel = true
 	}
 
+	// Follow the game server's health checks, and decide the early quit penalties whose reconnect window has closed.
+	if state.Started() && state.tickRate > 0 && tick%state.tickRate == 0 {
+		state.observeServerHealth(globalGameServerRegistry.Load())
+		if len(state.departures) > 0 {
+			m.decideEarlyQuits(ctx, logger, db, nk, state, false)
+		}
+	}
+
+	if state.telemetry != nil && tick%(2*state.tickRate) == 0 {
+		state.telemetry.SampleTeams(state, 2*time.Second)
+	}
+
 	// Every 2 seconds, update the disconnect info durations for players that are still connected to the game server.
 	if state.Mode == evr.ModeArenaPublic && tick%(2*state.tickRate) == 0 {
 		delta := 2 * time.Second
@@ -971,6 +990,7 @@ func (m *EvrMatch) MatchTerminate(ctx context.Context, logger runtime.Logger, db
 	state.Open = false
 	logger.Debug("MatchTerminate called.")
 	nk.MetricsCounterAdd("match_terminate_count", state.MetricsTags(), 1)
+	m.recordMatchHistory(logger, db, state)
 	if state.server != nil {
 		// Disconnect the players
 		for _, presence := range state.presenceMap {
@@ -992,6 +1012,23 @@ func (m *EvrMatch) MatchTerminate(ctx context.Context, logger runtime.Logger, db
 	return nil
 }
 
+// recordMatchHistory stores the history of a started public or private match, once.
+func (m *EvrMatch) recordMatchHistory(logger runtime.Logger, db *sql.DB, state *MatchLabel) {
+	if db == nil || state.historyRecorded || !state.Started() || (!state.IsPublic() && !state.IsPrivate()) {
+		return
+	}
+	state.historyRecorded = true
+
+	record := NewMatchHistoryRecord(state, time.Now())
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := MatchHistoryStore(ctx, db, record); err != nil {
+			logger.WithField("error", err).Warn("Failed to store match history.")
+		}
+	}()
+}
+
 func (m *EvrMatch) MatchShutdown(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, dispatcher runtime.MatchDispatcher, tick int64, state_ interface{}, graceSeconds int) interface{} {
 	state, ok := state_.(*MatchLabel)
 	if !ok {
@@ -1000,6 +1037,11 @@ func (m *EvrMatch) MatchShutdown(ctx context.Context, logger runtime.Logger, db
 	}
 	logger.WithField("state", state).Info("MatchShutdown called.")
 	nk.MetricsCounterAdd("match_shutdown_count", state.MetricsTags(), 1)
+	m.decideEarlyQuits(ctx, logger, db, nk, state, true)
+	m.recordMatchHistory(logger, db, state)
+	if state.telemetry != nil {
+		state.telemetry.Ended(ctx, nk, state)
+	}
 
 	nk.MetricsTimerRecord("lobby_session_duration", state.MetricsTags(), time.Since(state.StartTime))
 	if state.server != nil && slices.Contains(ValidLeaderboardModes, state.Mode) {
@@ -1159,6 +1201,14 @@ func (m *EvrMatch) MatchSignal(ctx context.Context, logger runtime.Logger, db *s
 		}
 		return state, SignalResponse{Success: true, Payload: string(jsonData)}.String()
 
+	case SignalGetScoreboard:
+		// Return the public view of the game state, for the live scoreboard feed.
+		jsonData, err := json.Marshal(NewLiveScoreboard(state, state.goals, time.Now().UTC()))
+		if err != nil {
+			return state, SignalResponse{Message: fmt.Sprintf("failed to marshal scoreboard: %v", err)}.String()
+		}
+		return state, SignalResponse{Success: true, Payload: string(jsonData)}.String()
+
 	case SignalPrepareSession:
 
 		// if the match is already started, return an error.
@@ -1201,6 +1251,8 @@ func (m *EvrMatch) MatchSignal(ctx context.Context, logger runtime.Logger, db *s
 
 		state.Mode = settings.Mode
 		state.Level = settings.Level
+		state.MapRotation = settings.MapRotation
+		state.Bracket = settings.Bracket
 		state.RequiredFeatures = settings.RequiredFeatures
 		state.SessionSettings = evr.NewSessionSettings(strconv.FormatUint(PcvrAppId, 10), state.Mode, state.Level, settings.RequiredFeatures)
 		state.GroupID = &settings.GroupID
@@ -1394,6 +1446,9 @@ func (m *EvrMatch) MatchStart(ctx context.Context, logger runtime.Logger, nk run
 	}
 
 	nk.MetricsCounterAdd("match_start_count", state.MetricsTags(), 1)
+	if t := state.matchTelemetry(); t != nil {
+		t.Started(ctx, nk, state)
+	}
 	// Dispatch the message for delivery.
 	if err := m.dispatchMessages(ctx, logger, dispatcher, messages, []runtime.Presence{state.server}, nil); err != nil {
 		return state, fmt.Errorf("failed to dispatch message: %w", err)
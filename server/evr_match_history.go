@@ -0,0 +1,307 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const (
+	MatchHistoryDefaultLimit = 20
+	MatchHistoryMaxLimit     = 100
+)
+
+var ErrMatchHistoryInvalidCursor = errors.New("invalid match history cursor")
+
+// MatchHistoryPlayer is a participant of a completed match.
+type MatchHistoryPlayer struct {
+	UserID      string    `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	EvrID       evr.EvrId `json:"evr_id"`
+	Team        TeamIndex `json:"team"`
+	PartyID     string    `json:"party_id,omitempty"`
+	JoinTime    time.Time `json:"join_time"`
+	LeaveTime   time.Time `json:"leave_time"`
+	EarlyQuit   bool      `json:"early_quit,omitempty"` // Left a public arena match before the round was over.
+}
+
+// MatchHistoryServer is the game server that hosted the match.
+type MatchHistoryServer struct {
+	OperatorID  string `json:"operator_id"`
+	Endpoint    string `json:"endpoint"`
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// MatchHistoryRecord is the persisted outcome of a match.
+type MatchHistoryRecord struct {
+	MatchID      MatchID               `json:"match_id"`
+	GroupID      string                `json:"group_id"`
+	LobbyType    string                `json:"lobby_type"`
+	Mode         evr.Symbol            `json:"mode"`
+	Level        evr.Symbol            `json:"level"`
+	Server       *MatchHistoryServer   `json:"server,omitempty"`
+	StartTime    time.Time             `json:"start_time"`
+	EndTime      time.Time             `json:"end_time"`
+	DurationSecs float64               `json:"duration_secs"`
+	BlueScore    int                   `json:"blue_score"`
+	OrangeScore  int                   `json:"orange_score"`
+	WinningTeam  TeamIndex             `json:"winning_team"` // AnyTeam on a draw, or if there is no score.
+	Players      []*MatchHistoryPlayer `json:"players"`
+	Goals        []*evr.MatchGoal      `json:"goals,omitempty"`
+}
+
+// Participant returns whether the user took part in the match.
+func (r *MatchHistoryRecord) Participant(userID string) bool {
+	return slices.ContainsFunc(r.Players, func(p *MatchHistoryPlayer) bool { return p.UserID == userID })
+}
+
+// historyJoin adds the player to the match roster, or updates them if they are rejoining.
+func (s *MatchLabel) historyJoin(mp *EvrMatchPresence) {
+	if s.roster == nil {
+		s.roster = make(map[string]*MatchHistoryPlayer)
+	}
+	p, ok := s.roster[mp.GetUserId()]
+	if !ok {
+		p = &MatchHistoryPlayer{
+			UserID:   mp.GetUserId(),
+			EvrID:    mp.EvrID,
+			JoinTime: time.Now().UTC(),
+		}
+		s.roster[mp.GetUserId()] = p
+	}
+	p.DisplayName = mp.DisplayName
+	p.Team = TeamIndex(mp.RoleAlignment)
+	if info := s.GetPlayerByUserID(mp.GetUserId()); info != nil {
+		p.Team = info.Team
+	}
+	if !mp.PartyID.IsNil() {
+		p.PartyID = mp.PartyID.String()
+	}
+	p.LeaveTime = time.Time{}
+	p.EarlyQuit = false
+}
+
+// historyLeave marks the player as having left the match.
+func (s *MatchLabel) historyLeave(mp *EvrMatchPresence, earlyQuit bool) {
+	if p, ok := s.roster[mp.GetUserId()]; ok {
+		p.LeaveTime = time.Now().UTC()
+		p.EarlyQuit = earlyQuit && mp.IsPlayer()
+	}
+}
+
+// NewMatchHistoryRecord returns the history record of the match, as it stands at endTime.
+func NewMatchHistoryRecord(s *MatchLabel, endTime time.Time) *MatchHistoryRecord {
+	r := &MatchHistoryRecord{
+		MatchID:     s.ID,
+		GroupID:     s.GetGroupID().String(),
+		LobbyType:   s.LobbyType.String(),
+		Mode:        s.Mode,
+		Level:       s.Level,
+		StartTime:   s.StartTime.UTC(),
+		EndTime:     endTime.UTC(),
+		WinningTeam: AnyTeam,
+		Players:     make([]*MatchHistoryPlayer, 0, len(s.roster)),
+		Goals:       slices.Clone(s.goals),
+	}
+
+	if d := r.EndTime.Sub(r.StartTime); d > 0 {
+		r.DurationSecs = d.Seconds()
+	}
+
+	if s.GameServer != nil {
+		r.Server = &MatchHistoryServer{
+			OperatorID:  s.GameServer.OperatorID.String(),
+			Endpoint:    s.GameServer.Endpoint.String(),
+			City:        s.GameServer.City,
+			Region:      s.GameServer.Region,
+			CountryCode: s.GameServer.CountryCode,
+		}
+	}
+
+	if s.GameState != nil {
+		r.BlueScore = s.GameState.BlueScore
+		r.OrangeScore = s.GameState.OrangeScore
+	}
+	switch {
+	case r.BlueScore > r.OrangeScore:
+		r.WinningTeam = BlueTeam
+	case r.OrangeScore > r.BlueScore:
+		r.WinningTeam = OrangeTeam
+	}
+
+	for _, p := range s.roster {
+		p := *p
+		if p.LeaveTime.IsZero() {
+			p.LeaveTime = r.EndTime
+		}
+		r.Players = append(r.Players, &p)
+	}
+	slices.SortStableFunc(r.Players, func(a, b *MatchHistoryPlayer) int {
+		if a.Team != b.Team {
+			return int(a.Team) - int(b.Team)
+		}
+		return a.JoinTime.Compare(b.JoinTime)
+	})
+
+	return r
+}
+
+// MatchHistoryStore persists the record. Storing the same match twice is a no-op.
+func MatchHistoryStore(ctx context.Context, db *sql.DB, r *MatchHistoryRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal match history: %w", err)
+	}
+
+	groupID := uuid.FromStringOrNil(r.GroupID)
+
+	return ExecuteInTx(ctx, db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO evr_match_history (match_id, group_id, lobby_type, mode, data, start_time, end_time)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (match_id) DO NOTHING`,
+			r.MatchID.String(), groupID, r.LobbyType, r.Mode.String(), data, r.StartTime, r.EndTime)
+		if err != nil {
+			return fmt.Errorf("failed to insert match history: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return nil
+		}
+
+		for _, p := range r.Players {
+			userID := uuid.FromStringOrNil(p.UserID)
+			if userID.IsNil() {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO evr_match_history_player (user_id, match_id, group_id, lobby_type, end_time)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (user_id, match_id) DO NOTHING`,
+				userID, r.MatchID.String(), groupID, r.LobbyType, r.EndTime); err != nil {
+				return fmt.Errorf("failed to insert match history player: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// MatchHistoryFilter selects the records to list. At least one of UserID or GroupID must be set.
+type MatchHistoryFilter struct {
+	UserID         string
+	GroupID        string
+	IncludePrivate bool
+	Limit          int
+	Cursor         string
+}
+
+type matchHistoryCursor struct {
+	EndTime time.Time `json:"t"`
+	MatchID string    `json:"m"`
+}
+
+func (c matchHistoryCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func parseMatchHistoryCursor(s string) (*matchHistoryCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrMatchHistoryInvalidCursor
+	}
+	c := &matchHistoryCursor{}
+	if err := json.Unmarshal(data, c); err != nil || c.MatchID == "" {
+		return nil, ErrMatchHistoryInvalidCursor
+	}
+	return c, nil
+}
+
+// MatchHistoryList returns the records matching the filter, most recent first, and the cursor of the next page.
+func MatchHistoryList(ctx context.Context, db *sql.DB, filter MatchHistoryFilter) ([]*MatchHistoryRecord, string, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = MatchHistoryDefaultLimit
+	}
+	limit = min(limit, MatchHistoryMaxLimit)
+
+	var (
+		from   = "evr_match_history AS f"
+		data   = "f.data"
+		where  []string
+		params []any
+	)
+	addParam := func(v any) string {
+		params = append(params, v)
+		return fmt.Sprintf("$%d", len(params))
+	}
+
+	switch {
+	case filter.UserID != "":
+		// The player table carries the group, lobby type and end time, for the filters and ordering.
+		from = "evr_match_history_player AS f JOIN evr_match_history AS h ON h.match_id = f.match_id"
+		data = "h.data"
+		where = append(where, "f.user_id = "+addParam(uuid.FromStringOrNil(filter.UserID)))
+	case filter.GroupID != "":
+	default:
+		return nil, "", errors.New("user or group id required")
+	}
+	if filter.GroupID != "" {
+		where = append(where, "f.group_id = "+addParam(uuid.FromStringOrNil(filter.GroupID)))
+	}
+	if !filter.IncludePrivate {
+		where = append(where, "f.lobby_type != "+addParam(PrivateLobby.String()))
+	}
+	if filter.Cursor != "" {
+		c, err := parseMatchHistoryCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		where = append(where, fmt.Sprintf("(f.end_time, f.match_id) < (%s, %s)", addParam(c.EndTime), addParam(c.MatchID)))
+	}
+
+	query := "SELECT " + data + " FROM " + from + " WHERE " + where[0]
+	for _, w := range where[1:] {
+		query += " AND " + w
+	}
+	query += " ORDER BY f.end_time DESC, f.match_id DESC LIMIT " + addParam(limit+1)
+
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query match history: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*MatchHistoryRecord, 0, limit)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, "", fmt.Errorf("failed to scan match history: %w", err)
+		}
+		r := &MatchHistoryRecord{}
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal match history: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to read match history: %w", err)
+	}
+
+	cursor := ""
+	if len(records) > limit {
+		records = records[:limit]
+		last := records[limit-1]
+		cursor = matchHistoryCursor{EndTime: last.EndTime, MatchID: last.MatchID.String()}.String()
+	}
+	return records, cursor, nil
+}
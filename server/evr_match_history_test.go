@@ -0,0 +1,74 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMatchHistoryRecord(t *testing.T) {
+	groupID := uuid.Must(uuid.NewV4())
+	start := time.Now().Add(-10 * time.Minute)
+
+	blue := &EvrMatchPresence{UserID: uuid.Must(uuid.NewV4()), DisplayName: "blue", RoleAlignment: int(BlueTeam), EvrID: evr.EvrId{PlatformCode: 4, AccountId: 1}}
+	orange := &EvrMatchPresence{UserID: uuid.Must(uuid.NewV4()), DisplayName: "orange", RoleAlignment: int(OrangeTeam), EvrID: evr.EvrId{PlatformCode: 4, AccountId: 2}}
+
+	state := &MatchLabel{
+		ID:         MatchID{UUID: uuid.Must(uuid.NewV4()), Node: "node"},
+		LobbyType:  PublicLobby,
+		Mode:       evr.ModeArenaPublic,
+		Level:      evr.LevelArena,
+		GroupID:    &groupID,
+		StartTime:  start,
+		GameServer: &GameServerPresence{Region: "us-east"},
+		GameState:  &GameState{BlueScore: 3, OrangeScore: 1},
+		goals:      []*evr.MatchGoal{{DisplayName: "blue", PointsValue: 2}},
+	}
+
+	state.historyJoin(blue)
+	state.historyJoin(orange)
+	state.historyLeave(orange, true)
+
+	end := time.Now()
+	r := NewMatchHistoryRecord(state, end)
+
+	assert.Equal(t, state.ID, r.MatchID)
+	assert.Equal(t, groupID.String(), r.GroupID)
+	assert.Equal(t, "public", r.LobbyType)
+	assert.Equal(t, BlueTeam, r.WinningTeam)
+	assert.InDelta(t, end.Sub(start).Seconds(), r.DurationSecs, 0.001)
+	assert.Equal(t, "us-east", r.Server.Region)
+	assert.Len(t, r.Goals, 1)
+
+	require.Len(t, r.Players, 2)
+	assert.Equal(t, blue.GetUserId(), r.Players[0].UserID)
+	assert.False(t, r.Players[0].EarlyQuit)
+	assert.Equal(t, end.UTC(), r.Players[0].LeaveTime)
+	assert.Equal(t, orange.GetUserId(), r.Players[1].UserID)
+	assert.True(t, r.Players[1].EarlyQuit)
+	assert.True(t, r.Participant(orange.GetUserId()))
+
+	// Rejoining clears the early quit.
+	state.historyJoin(orange)
+	r = NewMatchHistoryRecord(state, end)
+	assert.False(t, r.Players[1].EarlyQuit)
+
+	state.GameState.OrangeScore = 3
+	assert.Equal(t, AnyTeam, NewMatchHistoryRecord(state, end).WinningTeam)
+}
+
+func TestMatchHistoryCursor(t *testing.T) {
+	c := matchHistoryCursor{EndTime: time.Now().UTC().Truncate(time.Microsecond), MatchID: "id.node"}
+
+	parsed, err := parseMatchHistoryCursor(c.String())
+	require.NoError(t, err)
+	assert.True(t, c.EndTime.Equal(parsed.EndTime))
+	assert.Equal(t, c.MatchID, parsed.MatchID)
+
+	_, err = parseMatchHistoryCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrMatchHistoryInvalidCursor)
+}
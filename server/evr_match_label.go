@@ -48,6 +48,8 @@ type MatchLabel struct {
 	GameServer      *GameServerPresence       `json:"broadcaster,omitempty"`      // The broadcaster's data
 	SessionSettings *evr.LobbySessionSettings `json:"session_settings,omitempty"` // The session settings for the match (EVR).
 	TeamAlignments  map[string]int            `json:"team_alignments,omitempty"`  // map[userID]TeamIndex
+	MapRotation     *MapRotationSelection     `json:"map_rotation,omitempty"`     // How the level was picked, for matchmade lobbies.
+	Bracket         *BracketMatchRef          `json:"bracket,omitempty"`          // The tournament bracket match that this match was allocated for.
 
 	server          runtime.Presence                // The broadcaster's presence
 	levelLoaded     bool                            // Whether the server has been sent the start instruction.
@@ -62,6 +64,15 @@ type MatchLabel struct {
 	emptyTicks           int64                            // The number of ticks the match has been empty.
 	terminateTick        int64                            // The tick count at which the match will be shut down.
 	goals                []*evr.MatchGoal                 // The goals scored in the match.
+	roster               map[string]*MatchHistoryPlayer   // Everyone that has joined the match. map[userID]*MatchHistoryPlayer
+	historyRecorded      bool                             // Whether the match history has been stored.
+	telemetry            *MatchTelemetry                  // The early quit telemetry, for public matches.
+	departures           map[string]*earlyQuitDeparture   // The players whose early quit penalty is pending. map[userID]*earlyQuitDeparture
+	departureTimes       []time.Time                      // When players left the match in progress, for detecting lobby drops.
+	serverFaults         map[string]string                // The players that reported losing the game server. map[userID]reason
+	serverLostAt         time.Time                        // When the game server left the match.
+	serverCheckFailures  []time.Time                      // When the game server failed its health checks.
+	bracketReported      bool                             // Whether the bracket match result has been sent.
 }
 
 func (s *MatchLabel) LoadAndDeleteReservation(sessionID string) (*EvrMatchPresence, bool) {
@@ -216,6 +227,11 @@ func (s *MatchLabel) Started() bool {
 	return !s.StartTime.IsZero() && time.Now().After(s.StartTime)
 }
 
+// EarlyQuitApplies returns whether a player leaving now would be an early quit.
+func (s *MatchLabel) EarlyQuitApplies() bool {
+	return s.Mode == evr.ModeArenaPublic && time.Now().After(s.StartTime.Add(time.Second*60)) && s.GameState != nil && !s.GameState.MatchOver
+}
+
 func (s *MatchLabel) GetLabel() string {
 	labelJson, err := json.Marshal(s)
 	if err != nil {
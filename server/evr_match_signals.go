@@ -26,6 +26,7 @@ const (
 	SignalShutdown
 	SignalPlayerUpdate
 	SignalKickEntrants
+	SignalGetScoreboard
 )
 
 type SignalEnvelope struct {
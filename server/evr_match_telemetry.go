@@ -0,0 +1,638 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+// The telemetry events of docs/EARLY_QUIT.md.
+const (
+	EventMatchStarted       = "match_started"
+	EventPlayerConnected    = "player_connected"
+	EventPlayerDisconnected = "player_disconnected"
+	EventPlayerReconnected  = "player_reconnected"
+	EventScoreEvent         = "score_event"
+	EventMatchEnded         = "match_ended"
+
+	DisconnectReasonClientExit = "client_exit"
+	DisconnectReasonNetDrop    = "net_drop"
+	DisconnectReasonKickOther  = "kick_other"
+
+	AbandonPhaseEarly = "early"
+	AbandonPhaseMid   = "mid"
+	AbandonPhaseLate  = "late"
+
+	abandonCascadeWindow = 60 * time.Second
+)
+
+// EarlyQuitTelemetrySettings are the grace windows and thresholds of the early quit telemetry.
+type EarlyQuitTelemetrySettings struct {
+	Disabled              bool `json:"disabled"`               // Do not emit the telemetry events and metrics
+	LeaveGraceSecs        int  `json:"leave_grace_s"`          // A disconnect that is not followed by a reconnect within this is an abandon (default 120)
+	TeamWipeWindowSecs    int  `json:"team_wipe_window_s"`     // Abandons from one team within this are a team wipe (default 30)
+	GoalHazardWindowSecs  int  `json:"goal_hazard_window_s"`   // Abandons within this after conceding a goal are post-goal (default 45)
+	EarlyLeaveTimeSecs    int  `json:"early_leave_time_s"`     // Abandons before this game time are early (default 180)
+	ReconnectValidSecs    int  `json:"reconnect_valid_s"`      // Reconnects after this are not paired with the disconnect (default 300)
+	DisadvantageThreshold int  `json:"disadvantage_threshold"` // The players down that make a team short-handed (default 1)
+}
+
+func (s *EarlyQuitTelemetrySettings) setDefaults() {
+	if s.LeaveGraceSecs == 0 {
+		s.LeaveGraceSecs = 120
+	}
+	if s.TeamWipeWindowSecs == 0 {
+		s.TeamWipeWindowSecs = 30
+	}
+	if s.GoalHazardWindowSecs == 0 {
+		s.GoalHazardWindowSecs = 45
+	}
+	if s.EarlyLeaveTimeSecs == 0 {
+		s.EarlyLeaveTimeSecs = 180
+	}
+	if s.ReconnectValidSecs == 0 {
+		s.ReconnectValidSecs = 300
+	}
+	if s.DisadvantageThreshold == 0 {
+		s.DisadvantageThreshold = 1
+	}
+}
+
+func secs(s int) time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+type matchTelemetryPlayer struct {
+	UserID     string
+	Hash       string
+	Team       TeamIndex
+	JoinedAt   time.Time
+	IsBackfill bool
+}
+
+type matchTelemetryDisconnect struct {
+	Seq           int
+	UserID        string
+	Team          TeamIndex
+	At            time.Time
+	GameTime      time.Duration
+	Reason        string
+	ExplicitQuit  bool
+	ServerFault   bool
+	ReconnectedAt time.Time
+}
+
+type matchTelemetryGoal struct {
+	At          time.Time
+	GameTime    time.Duration
+	ScoringTeam TeamIndex
+}
+
+// MatchTelemetryAbandon is a disconnect that was not followed by a reconnect within the leave grace window.
+type MatchTelemetryAbandon struct {
+	UserID         string
+	Team           TeamIndex
+	At             time.Time
+	GameTime       time.Duration
+	Phase          string // early, mid or late
+	PostGoal       bool   // Within the goal hazard window after the player's team conceded
+	TeamWipeMember bool   // One of enough abandons from the team within the team wipe window
+}
+
+// MatchTelemetry follows a public match for the early quit telemetry. It lives in the match state.
+type MatchTelemetry struct {
+	settings    EarlyQuitTelemetrySettings
+	startedAt   time.Time
+	endedAt     time.Time
+	players     map[string]*matchTelemetryPlayer // map[userID]
+	disconnects []*matchTelemetryDisconnect
+	goals       []matchTelemetryGoal
+	goalsSeen   int // The number of the match's goals that have been processed
+	score       [2]int
+	seq         int
+
+	integrity     time.Duration // Both teams at full strength
+	disadvantage  time.Duration // One team short by the disadvantage threshold, or more
+	disadvantage2 time.Duration // One team short by two or more
+}
+
+func NewMatchTelemetry(settings EarlyQuitTelemetrySettings) *MatchTelemetry {
+	settings.setDefaults()
+	return &MatchTelemetry{
+		settings: settings,
+		players:  make(map[string]*matchTelemetryPlayer),
+	}
+}
+
+// matchTelemetryApplies returns true for the public matches that the telemetry is collected for.
+func matchTelemetryApplies(state *MatchLabel) bool {
+	if state.Mode != evr.ModeArenaPublic && state.Mode != evr.ModeCombatPublic {
+		return false
+	}
+	if s := ServiceSettings(); s != nil && s.Matchmaking.EarlyQuitTelemetry.Disabled {
+		return false
+	}
+	return true
+}
+
+// telemetryHashKey returns the server secret that the player hashes are keyed with.
+func telemetryHashKey(nk runtime.NakamaModule) []byte {
+	if _nk, ok := nk.(*RuntimeGoNakamaModule); ok {
+		return []byte(_nk.config.GetSession().EncryptionKey)
+	}
+	return nil
+}
+
+// telemetryPlayerHash pseudonymizes the user ID. It is keyed, so that the hashes can't be matched against known user IDs.
+func telemetryPlayerHash(key []byte, userID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(userID))
+	return "p_" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// telemetryMMRBucket maps the match's rating onto deciles of the default rating range (D1-D10).
+func telemetryMMRBucket(mu float64) string {
+	if mu <= 0 {
+		return "unrated"
+	}
+	defaultMu := 10.0
+	if s := ServiceSettings(); s != nil && s.SkillRating.Defaults.Mu > 0 {
+		defaultMu = s.SkillRating.Defaults.Mu
+	}
+	d := int(math.Floor(mu/(2*defaultMu)*10)) + 1
+	return fmt.Sprintf("D%d", min(10, max(1, d)))
+}
+
+func telemetryGameTime(state *MatchLabel, now time.Time) time.Duration {
+	if state.GameState != nil && state.GameState.SessionScoreboard != nil {
+		return state.GameState.SessionScoreboard.Elapsed()
+	}
+	if state.StartTime.IsZero() || now.Before(state.StartTime) {
+		return 0
+	}
+	return now.Sub(state.StartTime)
+}
+
+func telemetryTeamSizes(state *MatchLabel) [2]int {
+	var sizes [2]int
+	for _, p := range state.presenceMap {
+		switch TeamIndex(p.RoleAlignment) {
+		case BlueTeam:
+			sizes[0]++
+		case OrangeTeam:
+			sizes[1]++
+		}
+	}
+	return sizes
+}
+
+func telemetryRegion(state *MatchLabel) string {
+	if state.GameServer == nil {
+		return ""
+	}
+	if state.GameServer.DefaultRegion != "" {
+		return state.GameServer.DefaultRegion
+	}
+	return state.GameServer.Region
+}
+
+// metricsTags are the low cardinality dimensions; the events carry the rest.
+func (t *MatchTelemetry) metricsTags(state *MatchLabel) map[string]string {
+	return map[string]string{
+		"mode":          state.Mode.String(),
+		"map":           state.Level.String(),
+		"region":        telemetryRegion(state),
+		"mmr_bucket":    telemetryMMRBucket(state.RatingMu),
+		"team_size_max": strconv.Itoa(state.TeamSize),
+	}
+}
+
+func withTags(tags map[string]string, kv ...string) map[string]string {
+	out := make(map[string]string, len(tags)+len(kv)/2)
+	for k, v := range tags {
+		out[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		out[kv[i]] = kv[i+1]
+	}
+	return out
+}
+
+func (t *MatchTelemetry) emit(ctx context.Context, nk runtime.NakamaModule, state *MatchLabel, name string, properties map[string]string) {
+	if nk == nil {
+		return
+	}
+	properties["match_id"] = state.ID.String()
+	properties["wall_time"] = time.Now().UTC().Format(time.RFC3339)
+	_ = nk.Event(ctx, &api.Event{
+		Name:       name,
+		Properties: properties,
+		External:   true,
+	})
+}
+
+func (t *MatchTelemetry) sampleTeamSizes(nk runtime.NakamaModule, state *MatchLabel) {
+	if nk == nil {
+		return
+	}
+	tags := t.metricsTags(state)
+	sizes := telemetryTeamSizes(state)
+	nk.MetricsGaugeSet("team_size", withTags(tags, "team_id", BlueTeam.String()), float64(sizes[0]))
+	nk.MetricsGaugeSet("team_size", withTags(tags, "team_id", OrangeTeam.String()), float64(sizes[1]))
+}
+
+// Started emits match_started.
+func (t *MatchTelemetry) Started(ctx context.Context, nk runtime.NakamaModule, state *MatchLabel) {
+	if !t.startedAt.IsZero() {
+		return
+	}
+	t.startedAt = time.Now().UTC()
+
+	datacenter, build := "", ""
+	if state.GameServer != nil {
+		datacenter, build = state.GameServer.City, state.GameServer.VersionLock.String()
+	}
+	t.emit(ctx, nk, state, EventMatchStarted, map[string]string{
+		"mode":          state.Mode.String(),
+		"map":           state.Level.String(),
+		"region":        telemetryRegion(state),
+		"datacenter":    datacenter,
+		"build":         build,
+		"is_ranked":     strconv.FormatBool(state.IsPublic()),
+		"team_size_max": strconv.Itoa(state.TeamSize),
+		"team_count":    "2",
+		"mmr_bucket":    telemetryMMRBucket(state.RatingMu),
+		"started_at":    t.startedAt.Format(time.RFC3339),
+	})
+	if nk != nil {
+		nk.MetricsCounterAdd("matches_started", t.metricsTags(state), 1)
+	}
+}
+
+// Joined emits player_connected on the player's first join, and player_reconnected after a disconnect.
+func (t *MatchTelemetry) Joined(ctx context.Context, nk runtime.NakamaModule, state *MatchLabel, mp *EvrMatchPresence, isBackfill bool) {
+	if !mp.IsPlayer() || !t.endedAt.IsZero() {
+		return
+	}
+	now := time.Now().UTC()
+	userID := mp.GetUserId()
+	team := TeamIndex(mp.RoleAlignment)
+	gameTime := telemetryGameTime(state, now)
+	tags := t.metricsTags(state)
+
+	player, ok := t.players[userID]
+	if !ok {
+		player = &matchTelemetryPlayer{
+			UserID:     userID,
+			Hash:       telemetryPlayerHash(telemetryHashKey(nk), userID),
+			Team:       team,
+			JoinedAt:   now,
+			IsBackfill: isBackfill,
+		}
+		t.players[userID] = player
+
+		partySize := 0
+		for _, p := range state.presenceMap {
+			if !mp.PartyID.IsNil() && p.PartyID == mp.PartyID {
+				partySize++
+			}
+		}
+		platform := "standalone"
+		if mp.IsPCVR {
+			platform = "pcvr"
+		}
+		t.emit(ctx, nk, state, EventPlayerConnected, map[string]string{
+			"player_id_hash":     player.Hash,
+			"team_id":            team.String(),
+			"is_backfill":        strconv.FormatBool(isBackfill),
+			"joined_game_time_s": strconv.Itoa(int(gameTime.Seconds())),
+			"joined_at":          now.Format(time.RFC3339),
+			"party_size":         strconv.Itoa(max(1, partySize)),
+			"platform":           platform,
+			"ping_ms":            strconv.Itoa(mp.PingMillis),
+			"ping_band":          string(PingToBand(mp.PingMillis)),
+		})
+		if nk != nil {
+			nk.MetricsCounterAdd("player_match_joins_total", withTags(tags, "is_backfill", strconv.FormatBool(isBackfill)), 1)
+		}
+		t.sampleTeamSizes(nk, state)
+		return
+	}
+
+	player.Team = team
+	var last *matchTelemetryDisconnect
+	for _, d := range t.disconnects {
+		if d.UserID == userID && d.ReconnectedAt.IsZero() {
+			last = d
+		}
+	}
+	if last == nil {
+		return
+	}
+	after := now.Sub(last.At)
+	if after > secs(t.settings.ReconnectValidSecs) {
+		// Too late to pair with the disconnect.
+		return
+	}
+	last.ReconnectedAt = now
+
+	t.emit(ctx, nk, state, EventPlayerReconnected, map[string]string{
+		"player_id_hash":    player.Hash,
+		"team_id":           team.String(),
+		"game_time_s":       strconv.Itoa(int(gameTime.Seconds())),
+		"reconnect_after_s": strconv.Itoa(int(after.Seconds())),
+		"paired_seq_id":     strconv.Itoa(last.Seq),
+	})
+	if nk != nil {
+		nk.MetricsCounterAdd("reconnects_total", tags, 1)
+		nk.MetricsTimerRecord("reconnect_after_seconds", tags, after)
+	}
+	t.sampleTeamSizes(nk, state)
+}
+
+// telemetryDisconnectReason maps the presence's leave reason onto the documented reasons.
+func telemetryDisconnectReason(reason runtime.PresenceReason) (string, bool) {
+	switch reason {
+	case runtime.PresenceReasonLeave:
+		return DisconnectReasonClientExit, true
+	case PresenceReasonKicked:
+		return DisconnectReasonKickOther, false
+	default:
+		return DisconnectReasonNetDrop, false
+	}
+}
+
+// Left emits player_disconnected. Whether it is an abandon is decided when the match ends, and whether
+// it was the server's fault may only be known when the player's early quit is decided (see ServerFault).
+func (t *MatchTelemetry) Left(ctx context.Context, nk runtime.NakamaModule, state *MatchLabel, mp *EvrMatchPresence, presenceReason runtime.PresenceReason) {
+	player, ok := t.players[mp.GetUserId()]
+	if !ok || t.startedAt.IsZero() || !t.endedAt.IsZero() {
+		return
+	}
+	now := time.Now().UTC()
+	reason, explicit := telemetryDisconnectReason(presenceReason)
+	players := 0
+	for _, p := range state.presenceMap {
+		if p.IsPlayer() {
+			players++
+		}
+	}
+	_, serverFault := state.serverFault(mp.GetUserId(), now, players, currentEarlyQuitGraceSettings())
+	t.seq++
+	d := &matchTelemetryDisconnect{
+		Seq:          t.seq,
+		UserID:       player.UserID,
+		Team:         TeamIndex(mp.RoleAlignment),
+		At:           now,
+		GameTime:     telemetryGameTime(state, now),
+		Reason:       reason,
+		ExplicitQuit: explicit,
+		ServerFault:  serverFault,
+	}
+	t.disconnects = append(t.disconnects, d)
+
+	t.emit(ctx, nk, state, EventPlayerDisconnected, map[string]string{
+		"player_id_hash": player.Hash,
+		"team_id":        d.Team.String(),
+		"game_time_s":    strconv.Itoa(int(d.GameTime.Seconds())),
+		"reason":         reason,
+		"explicit_quit":  strconv.FormatBool(explicit),
+		"server_fault":   strconv.FormatBool(serverFault),
+		"seq_id":         strconv.Itoa(d.Seq),
+	})
+	if nk != nil {
+		nk.MetricsCounterAdd("disconnects_total", withTags(t.metricsTags(state), "reason", reason, "explicit_quit", strconv.FormatBool(explicit)), 1)
+	}
+	t.sampleTeamSizes(nk, state)
+}
+
+// ServerFault marks the player's disconnect at the time as the server's fault, once their early quit is decided as such.
+func (t *MatchTelemetry) ServerFault(userID string, at time.Time) {
+	for _, d := range t.disconnects {
+		if d.UserID == userID && !d.At.Before(at) {
+			d.ServerFault = true
+			return
+		}
+	}
+}
+
+// wasServerFault returns true if the game server was lost, or any disconnect was the server's fault.
+func (t *MatchTelemetry) wasServerFault(state *MatchLabel) bool {
+	if !state.serverLostAt.IsZero() {
+		return true
+	}
+	for _, d := range t.disconnects {
+		if d.ServerFault {
+			return true
+		}
+	}
+	return false
+}
+
+// Goals emits a score_event for each goal that has not been seen yet.
+func (t *MatchTelemetry) Goals(ctx context.Context, nk runtime.NakamaModule, state *MatchLabel, goals []*evr.MatchGoal) {
+	if !t.endedAt.IsZero() {
+		return
+	}
+	now := time.Now().UTC()
+	for ; t.goalsSeen < len(goals); t.goalsSeen++ {
+		g := goals[t.goalsSeen]
+		if g == nil {
+			continue
+		}
+		team := TeamIndex(g.TeamID)
+		switch team {
+		case BlueTeam:
+			t.score[0] += GoalTypeToPoints(g.GoalType)
+		case OrangeTeam:
+			t.score[1] += GoalTypeToPoints(g.GoalType)
+		default:
+			continue
+		}
+		gameTime := time.Duration(g.GoalTime * float64(time.Second))
+		t.goals = append(t.goals, matchTelemetryGoal{At: now, GameTime: gameTime, ScoringTeam: team})
+
+		t.emit(ctx, nk, state, EventScoreEvent, map[string]string{
+			"scoring_team_id": team.String(),
+			"new_score_home":  strconv.Itoa(t.score[0]),
+			"new_score_away":  strconv.Itoa(t.score[1]),
+			"game_time_s":     strconv.Itoa(int(gameTime.Seconds())),
+		})
+		if nk != nil {
+			tags := t.metricsTags(state)
+			nk.MetricsCounterAdd("goals_total", withTags(tags, "scoring_team_id", team.String()), 1)
+			nk.MetricsCounterAdd("goal_event_markers_total", tags, 1)
+		}
+	}
+}
+
+// SampleTeams accumulates the integrity and disadvantage time since the last sample.
+func (t *MatchTelemetry) SampleTeams(state *MatchLabel, dt time.Duration) {
+	if t.startedAt.IsZero() || !t.endedAt.IsZero() {
+		return
+	}
+	sizes := telemetryTeamSizes(state)
+	diff := sizes[0] - sizes[1]
+	if diff < 0 {
+		diff = -diff
+	}
+	switch {
+	case state.TeamSize > 0 && sizes[0] == state.TeamSize && sizes[1] == state.TeamSize:
+		t.integrity += dt
+	case diff >= t.settings.DisadvantageThreshold:
+		t.disadvantage += dt
+	}
+	if diff >= 2 {
+		t.disadvantage2 += dt
+	}
+}
+
+// Abandons returns the disconnects that were not followed by a reconnect within the leave grace window.
+func (t *MatchTelemetry) Abandons(duration time.Duration, teamSizeMax int) []MatchTelemetryAbandon {
+	grace := secs(t.settings.LeaveGraceSecs)
+	abandons := make([]MatchTelemetryAbandon, 0)
+	for _, d := range t.disconnects {
+		if !d.ReconnectedAt.IsZero() && d.ReconnectedAt.Sub(d.At) <= grace {
+			continue
+		}
+		phase := AbandonPhaseMid
+		switch {
+		case d.GameTime < secs(t.settings.EarlyLeaveTimeSecs):
+			phase = AbandonPhaseEarly
+		case duration > 0 && d.GameTime >= duration*2/3:
+			phase = AbandonPhaseLate
+		}
+		a := MatchTelemetryAbandon{
+			UserID:   d.UserID,
+			Team:     d.Team,
+			At:       d.At,
+			GameTime: d.GameTime,
+			Phase:    phase,
+		}
+		for _, g := range t.goals {
+			if g.ScoringTeam != d.Team && !d.At.Before(g.At) && d.At.Sub(g.At) <= secs(t.settings.GoalHazardWindowSecs) {
+				a.PostGoal = true
+				break
+			}
+		}
+		abandons = append(abandons, a)
+	}
+
+	// A team wipe is K or more abandons from one team within the window.
+	k := max(2, teamSizeMax-1)
+	window := secs(t.settings.TeamWipeWindowSecs)
+	for i := range abandons {
+		count := 0
+		for j := range abandons {
+			if abandons[j].Team != abandons[i].Team {
+				continue
+			}
+			if delta := abandons[j].At.Sub(abandons[i].At); delta >= -window && delta <= window {
+				count++
+			}
+		}
+		abandons[i].TeamWipeMember = count >= k
+	}
+	return abandons
+}
+
+// Ended emits match_ended, and the abandon metrics of the match. It only runs once.
+func (t *MatchTelemetry) Ended(ctx context.Context, nk runtime.NakamaModule, state *MatchLabel) {
+	if t.startedAt.IsZero() || !t.endedAt.IsZero() {
+		return
+	}
+	t.endedAt = time.Now().UTC()
+	duration := t.endedAt.Sub(t.startedAt)
+	if state.GameState != nil && state.GameState.SessionScoreboard != nil {
+		duration = state.GameState.SessionScoreboard.Elapsed()
+	}
+
+	winner := ""
+	switch {
+	case t.score[0] > t.score[1]:
+		winner = BlueTeam.String()
+	case t.score[1] > t.score[0]:
+		winner = OrangeTeam.String()
+	}
+
+	t.emit(ctx, nk, state, EventMatchEnded, map[string]string{
+		"duration_s":                  strconv.Itoa(int(duration.Seconds())),
+		"winner_team_id":              winner,
+		"final_score_home":            strconv.Itoa(t.score[0]),
+		"final_score_away":            strconv.Itoa(t.score[1]),
+		"ended_at":                    t.endedAt.Format(time.RFC3339),
+		"integrity_minutes":           strconv.FormatFloat(t.integrity.Minutes(), 'f', 2, 64),
+		"disadvantage_minutes":        strconv.FormatFloat(t.disadvantage.Minutes(), 'f', 2, 64),
+		"disadvantage_2_plus_minutes": strconv.FormatFloat(t.disadvantage2.Minutes(), 'f', 2, 64),
+		"was_server_fault":            strconv.FormatBool(t.wasServerFault(state)),
+	})
+	if nk == nil {
+		return
+	}
+
+	tags := t.metricsTags(state)
+	nk.MetricsCounterAdd("matches_ended_total", tags, 1)
+	nk.MetricsTimerRecord("match_duration_seconds", tags, duration)
+	nk.MetricsTimerRecord("integrity_seconds", tags, t.integrity)
+	nk.MetricsTimerRecord("disadvantage_seconds", withTags(tags, "players_down", "1+"), t.disadvantage)
+	nk.MetricsTimerRecord("disadvantage_seconds", withTags(tags, "players_down", "2+"), t.disadvantage2)
+
+	abandons := t.Abandons(duration, state.TeamSize)
+	if len(abandons) == 0 {
+		return
+	}
+	var early, teamWipe, postGoalTeamWipe bool
+	first := abandons[0].At
+	for _, a := range abandons {
+		nk.MetricsCounterAdd("abandon_events_total", withTags(tags,
+			"phase", a.Phase,
+			"post_goal", strconv.FormatBool(a.PostGoal),
+			"team_wipe_member", strconv.FormatBool(a.TeamWipeMember),
+		), 1)
+		early = early || a.Phase == AbandonPhaseEarly
+		teamWipe = teamWipe || a.TeamWipeMember
+		postGoalTeamWipe = postGoalTeamWipe || (a.TeamWipeMember && a.PostGoal)
+		if a.At.Before(first) {
+			first = a.At
+		}
+	}
+	cascade := 0
+	for _, a := range abandons {
+		if a.At.After(first) && a.At.Sub(first) <= abandonCascadeWindow {
+			cascade++
+		}
+	}
+
+	nk.MetricsCounterAdd("matches_with_abandon_total", tags, 1)
+	nk.MetricsCounterAdd("abandon_cascade_total", tags, int64(cascade))
+	if early {
+		nk.MetricsCounterAdd("matches_with_early_abandon_total", tags, 1)
+	}
+	if teamWipe {
+		nk.MetricsCounterAdd("matches_with_team_wipe_abandon_total", tags, 1)
+	}
+	if postGoalTeamWipe {
+		nk.MetricsCounterAdd("matches_with_post_goal_team_wipe_total", tags, 1)
+	}
+}
+
+// matchTelemetry returns the match's telemetry, or nil if it is not collected for the match.
+func (s *MatchLabel) matchTelemetry() *MatchTelemetry {
+	if s.telemetry == nil && matchTelemetryApplies(s) {
+		settings := EarlyQuitTelemetrySettings{}
+		if g := ServiceSettings(); g != nil {
+			settings = g.Matchmaking.EarlyQuitTelemetry
+		}
+		s.telemetry = NewMatchTelemetry(settings)
+	}
+	return s.telemetry
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarlyQuitTelemetrySettings_Defaults(t *testing.T) {
+	s := EarlyQuitTelemetrySettings{TeamWipeWindowSecs: 10}
+	s.setDefaults()
+
+	assert.Equal(t, EarlyQuitTelemetrySettings{
+		LeaveGraceSecs:        120,
+		TeamWipeWindowSecs:    10,
+		GoalHazardWindowSecs:  45,
+		EarlyLeaveTimeSecs:    180,
+		ReconnectValidSecs:    300,
+		DisadvantageThreshold: 1,
+	}, s)
+}
+
+func TestMatchTelemetry_Abandons(t *testing.T) {
+	start := time.Now().UTC()
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	mt := NewMatchTelemetry(EarlyQuitTelemetrySettings{})
+	mt.startedAt = start
+	mt.goals = []matchTelemetryGoal{
+		{At: at(400), GameTime: 400 * time.Second, ScoringTeam: OrangeTeam},
+	}
+	mt.disconnects = []*matchTelemetryDisconnect{
+		// Reconnected within the grace window.
+		{UserID: "a", Team: BlueTeam, At: at(60), GameTime: 60 * time.Second, ReconnectedAt: at(90)},
+		// Early, and not back.
+		{UserID: "b", Team: OrangeTeam, At: at(100), GameTime: 100 * time.Second},
+		// Reconnected after the grace window.
+		{UserID: "c", Team: OrangeTeam, At: at(200), GameTime: 200 * time.Second, ReconnectedAt: at(400)},
+		// Blue concedes, and three leave within the team wipe window.
+		{UserID: "d", Team: BlueTeam, At: at(410), GameTime: 410 * time.Second},
+		{UserID: "e", Team: BlueTeam, At: at(420), GameTime: 420 * time.Second},
+		{UserID: "f", Team: BlueTeam, At: at(435), GameTime: 435 * time.Second},
+		// Blue again, after the goal hazard and team wipe windows.
+		{UserID: "h", Team: BlueTeam, At: at(470), GameTime: 470 * time.Second},
+		// Late in the match.
+		{UserID: "g", Team: OrangeTeam, At: at(500), GameTime: 500 * time.Second},
+	}
+
+	abandons := mt.Abandons(600*time.Second, 4)
+
+	byUser := make(map[string]MatchTelemetryAbandon, len(abandons))
+	for _, a := range abandons {
+		byUser[a.UserID] = a
+	}
+	assert.NotContains(t, byUser, "a")
+	assert.Len(t, abandons, 7)
+
+	assert.Equal(t, AbandonPhaseEarly, byUser["b"].Phase)
+	assert.Equal(t, AbandonPhaseMid, byUser["c"].Phase)
+	assert.Equal(t, AbandonPhaseLate, byUser["g"].Phase)
+
+	assert.True(t, byUser["d"].PostGoal)
+	assert.True(t, byUser["e"].PostGoal)
+	assert.True(t, byUser["f"].PostGoal)
+	assert.False(t, byUser["h"].PostGoal, "outside the goal hazard window")
+	assert.False(t, byUser["g"].PostGoal, "the scoring team")
+
+	// K = max(2, team_size_max-1) = 3
+	assert.True(t, byUser["d"].TeamWipeMember)
+	assert.True(t, byUser["e"].TeamWipeMember)
+	assert.True(t, byUser["f"].TeamWipeMember)
+	assert.False(t, byUser["h"].TeamWipeMember, "outside the team wipe window")
+	assert.False(t, byUser["c"].TeamWipeMember)
+}
+
+func TestMatchTelemetry_SampleTeams(t *testing.T) {
+	state := &MatchLabel{TeamSize: 2, presenceMap: map[string]*EvrMatchPresence{}}
+	add := func(id string, team TeamIndex) {
+		state.presenceMap[id] = &EvrMatchPresence{RoleAlignment: int(team)}
+	}
+	add("1", BlueTeam)
+	add("2", BlueTeam)
+	add("3", OrangeTeam)
+	add("4", OrangeTeam)
+
+	mt := NewMatchTelemetry(EarlyQuitTelemetrySettings{})
+	mt.startedAt = time.Now()
+
+	mt.SampleTeams(state, 2*time.Second)
+	delete(state.presenceMap, "3")
+	mt.SampleTeams(state, 2*time.Second)
+	delete(state.presenceMap, "4")
+	mt.SampleTeams(state, 2*time.Second)
+
+	assert.Equal(t, 2*time.Second, mt.integrity)
+	assert.Equal(t, 4*time.Second, mt.disadvantage)
+	assert.Equal(t, 2*time.Second, mt.disadvantage2)
+}
+
+func TestTelemetryPlayerHash(t *testing.T) {
+	key := []byte("secret")
+	h := telemetryPlayerHash(key, "user-1")
+	assert.Equal(t, h, telemetryPlayerHash(key, "user-1"))
+	assert.NotEqual(t, h, telemetryPlayerHash(key, "user-2"))
+	assert.NotEqual(t, h, telemetryPlayerHash([]byte("other"), "user-1"), "the hash is keyed")
+	assert.NotContains(t, h, "user-1")
+}
+
+func TestMatchTelemetry_ServerFault(t *testing.T) {
+	start := time.Now().UTC()
+	state := &MatchLabel{}
+
+	mt := NewMatchTelemetry(EarlyQuitTelemetrySettings{})
+	mt.disconnects = []*matchTelemetryDisconnect{
+		{UserID: "a", At: start},
+		{UserID: "a", At: start.Add(time.Minute)},
+	}
+	assert.False(t, mt.wasServerFault(state))
+
+	mt.ServerFault("a", start.Add(time.Second))
+	assert.False(t, mt.disconnects[0].ServerFault)
+	assert.True(t, mt.disconnects[1].ServerFault)
+	assert.True(t, mt.wasServerFault(state))
+
+	mt.disconnects[1].ServerFault = false
+	state.serverLostAt = start
+	assert.True(t, mt.wasServerFault(state))
+}
@@ -16,8 +16,9 @@ const (
 )
 
 type SkillBasedMatchmaker struct {
-	latestCandidates *atomic.Value // [][]runtime.MatchmakerEntry
-	latestMatches    *atomic.Value // [][]runtime.MatchmakerEntry
+	latestCandidates *atomic.Value                 // [][]runtime.MatchmakerEntry
+	latestMatches    *atomic.Value                 // [][]runtime.MatchmakerEntry
+	lastSnapshots    *MapOf[string, *atomic.Int64] // When each pool's candidates were last stored for the simulator, in Unix nanoseconds
 }
 
 func (s *SkillBasedMatchmaker) StoreLatestResult(candidates, madeMatches [][]runtime.MatchmakerEntry) {
@@ -44,6 +45,7 @@ func NewSkillBasedMatchmaker() *SkillBasedMatchmaker {
 	sbmm := SkillBasedMatchmaker{
 		latestCandidates: &atomic.Value{},
 		latestMatches:    &atomic.Value{},
+		lastSnapshots:    &MapOf[string, *atomic.Int64]{},
 	}
 
 	sbmm.latestCandidates.Store([][]runtime.MatchmakerEntry{})
@@ -57,9 +59,21 @@ func (m *SkillBasedMatchmaker) EvrMatchmakerFn(ctx context.Context, logger runti
 		logger.Error("No candidates found. Matchmaker cannot run.")
 		return nil
 	}
+	// A simulated run uses the given settings, and has no side effects.
+	var (
+		settings *GlobalMatchmakingSettings
+		ratings  *RatingDefaults
+	)
+	simulation, simulated := ctx.Value(ctxMatchmakerSimulationKey{}).(*matchmakerSimulationSettings)
+	if simulated {
+		settings, ratings = simulation.Matchmaking, simulation.RatingDefaults
+	} else if s := ServiceSettings(); s != nil {
+		settings, ratings = &s.Matchmaking, &s.SkillRating.Defaults
+	}
+
 	startTime := time.Now()
 	defer func() {
-		if nk == nil {
+		if nk == nil || simulated {
 			return
 		}
 		nk.MetricsTimerRecord("matchmaker_process_duration", nil, time.Since(startTime))
@@ -85,7 +99,15 @@ func (m *SkillBasedMatchmaker) EvrMatchmakerFn(ctx context.Context, logger runti
 		originalCount = len(candidates)
 	)
 
-	candidates, matches, filterCounts = m.processPotentialMatches(candidates)
+	if !simulated && nk != nil {
+		m.storeCandidateSnapshot(logger, nk, groupID, modestr, settings, ratings, candidates)
+	}
+
+	candidates, matches, filterCounts = m.processPotentialMatchesWithSettings(candidates, settings, ratings)
+
+	if simulated {
+		return matches
+	}
 
 	// Extract all players from the candidates
 	playerSet := make(map[string]struct{}, 0)
@@ -113,11 +135,13 @@ func (m *SkillBasedMatchmaker) EvrMatchmakerFn(ctx context.Context, logger runti
 		return p, !ok
 	})
 
-	nk.MetricsCounterAdd("matchmaker_candidate_count", nil, int64(len(candidates)))
-	nk.MetricsCounterAdd("matchmaker_match_count", nil, int64(len(matches)))
-	nk.MetricsCounterAdd("matchmaker_ticket_count", nil, int64(len(ticketSet)))
-	nk.MetricsCounterAdd("matchmaker_unmatched_player_count", nil, int64(len(unmatchedPlayers)))
-	nk.MetricsCounterAdd("matchmaker_matched_player_count", nil, int64(len(matchedPlayers)))
+	if nk != nil {
+		nk.MetricsCounterAdd("matchmaker_candidate_count", nil, int64(len(candidates)))
+		nk.MetricsCounterAdd("matchmaker_match_count", nil, int64(len(matches)))
+		nk.MetricsCounterAdd("matchmaker_ticket_count", nil, int64(len(ticketSet)))
+		nk.MetricsCounterAdd("matchmaker_unmatched_player_count", nil, int64(len(unmatchedPlayers)))
+		nk.MetricsCounterAdd("matchmaker_matched_player_count", nil, int64(len(matchedPlayers)))
+	}
 
 	logger.WithFields(map[string]interface{}{
 		"mode":                 modestr,
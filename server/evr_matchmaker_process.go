@@ -11,26 +11,52 @@ import (
 
 // Process potential matches from candidates, applying filters and predictions
 func (m *SkillBasedMatchmaker) processPotentialMatches(candidates [][]runtime.MatchmakerEntry) ([][]runtime.MatchmakerEntry, [][]runtime.MatchmakerEntry, map[string]int) {
+	var (
+		settings *GlobalMatchmakingSettings
+		ratings  *RatingDefaults
+	)
+	if s := ServiceSettings(); s != nil {
+		settings, ratings = &s.Matchmaking, &s.SkillRating.Defaults
+	}
+	return m.processPotentialMatchesWithSettings(candidates, settings, ratings)
+}
 
-	filterCounts := make(map[string]int)
-
-	// Filter out players who are too far away from each other
-	filterCounts["max_rtt"] = m.filterWithinMaxRTT(candidates)
-
+// matchmakerPredictionConfig returns the prediction config for the matchmaking settings and rating defaults.
+// Either may be nil; the live rating defaults are used if the given ones are.
+func matchmakerPredictionConfig(settings *GlobalMatchmakingSettings, ratings *RatingDefaults) PredictionConfig {
 	config := PredictionConfig{}
-	if settings := ServiceSettings(); settings != nil {
-		mu := settings.SkillRating.Defaults.Mu
-		sigma := settings.SkillRating.Defaults.Sigma
-		z := settings.SkillRating.Defaults.Z
-		config.PartyBoostPercent = settings.Matchmaking.PartySkillBoostPercent
-		config.EnableRosterVariants = settings.Matchmaking.EnableRosterVariants
-		config.UseSnakeDraftFormation = settings.Matchmaking.UseSnakeDraftTeamFormation
+	if ratings == nil {
+		if s := ServiceSettings(); s != nil {
+			ratings = &s.SkillRating.Defaults
+		}
+	}
+	if ratings != nil {
+		mu := ratings.Mu
+		sigma := ratings.Sigma
+		z := ratings.Z
 		config.OpenSkillOptions = &types.OpenSkillOptions{
 			Mu:    &mu,
 			Sigma: &sigma,
 			Z:     &z,
 		}
 	}
+	if settings != nil {
+		config.PartyBoostPercent = settings.PartySkillBoostPercent
+		config.EnableRosterVariants = settings.EnableRosterVariants
+		config.UseSnakeDraftFormation = settings.UseSnakeDraftTeamFormation
+	}
+	return config
+}
+
+// processPotentialMatchesWithSettings processes the candidates with the given matchmaking settings and rating defaults.
+func (m *SkillBasedMatchmaker) processPotentialMatchesWithSettings(candidates [][]runtime.MatchmakerEntry, settings *GlobalMatchmakingSettings, ratings *RatingDefaults) ([][]runtime.MatchmakerEntry, [][]runtime.MatchmakerEntry, map[string]int) {
+
+	filterCounts := make(map[string]int)
+
+	// Filter out players who are too far away from each other
+	filterCounts["max_rtt"] = m.filterWithinMaxRTT(candidates)
+
+	config := matchmakerPredictionConfig(settings, ratings)
 
 	// predict the outcome of the matches
 	oldestTicket := ""
@@ -9,7 +9,6 @@ import (
 	"github.com/heroiclabs/nakama/v3/server/evr"
 	"github.com/intinig/go-openskill/rating"
 	"github.com/intinig/go-openskill/types"
-	"go.uber.org/thriftrw/ptr"
 )
 
 type (
@@ -199,6 +198,11 @@ func CalculateNewTeamRatings(playerInfos []PlayerInfo, playerStats map[evr.EvrId
 
 // CalculateNewTeamRatingsWithConfig calculates new team-based player ratings with an optional config override.
 func CalculateNewTeamRatingsWithConfig(playerInfos []PlayerInfo, playerStats map[evr.EvrId]evr.MatchTypeStats, blueWins bool, config *SkillRatingSettings) map[string]types.Rating {
+	return CalculateNewTeamRatingsForMode("", 0, playerInfos, playerStats, blueWins, config)
+}
+
+// CalculateNewTeamRatingsForMode calculates new team-based player ratings with the rating engine of the guild and mode.
+func CalculateNewTeamRatingsForMode(groupID string, mode evr.Symbol, playerInfos []PlayerInfo, playerStats map[evr.EvrId]evr.MatchTypeStats, blueWins bool, config *SkillRatingSettings) map[string]types.Rating {
 	// Get config from service settings if not provided
 	if config == nil {
 		if settings := ServiceSettings(); settings != nil {
@@ -207,15 +211,10 @@ func CalculateNewTeamRatingsWithConfig(playerInfos []PlayerInfo, playerStats map
 	}
 
 	// Get defaults
-	var tau float64 = 0.3
 	var winningTeamBonus float64 = 4.0
 	var multipliers map[string]float64
 
 	if config != nil {
-		tau = config.Defaults.Tau
-		if tau == 0 {
-			tau = 0.3
-		}
 		winningTeamBonus = config.WinningTeamBonus
 		if winningTeamBonus == 0 {
 			winningTeamBonus = 4.0
@@ -277,10 +276,10 @@ func CalculateNewTeamRatingsWithConfig(playerInfos []PlayerInfo, playerStats map
 		return playerScores[b.SessionID] - playerScores[a.SessionID]
 	})
 
-	// Split the roster by teamRatings (all players are separated on their own team)
-	teamRatings := make([]types.Team, len(playerInfos))
+	// Each player is rated on their own
+	ratings := make([]types.Rating, len(playerInfos))
 	for i, p := range playerInfos {
-		teamRatings[i] = types.Team{p.Rating()}
+		ratings[i] = p.Rating()
 	}
 
 	// Create a map of player scores; used to weight the new ratings
@@ -290,15 +289,12 @@ func CalculateNewTeamRatingsWithConfig(playerInfos []PlayerInfo, playerStats map
 	}
 
 	// Calculate the new ratings
-	teamRatings = rating.Rate(teamRatings, &types.OpenSkillOptions{
-		Score: scores,
-		Tau:   ptr.Float64(tau),
-	})
+	newRatings := RatingEngineFor(config, groupID, mode).Rate(ratings, scores, config)
 
 	ratingMap := make(map[string]types.Rating, len(playerInfos))
-	for i, team := range teamRatings {
+	for i, r := range newRatings {
 		sID := playerInfos[i].SessionID
-		ratingMap[sID] = team[0]
+		ratingMap[sID] = r
 	}
 
 	return ratingMap
@@ -321,6 +317,11 @@ func CalculateNewIndividualRatings(playerInfos []PlayerInfo, playerStats map[evr
 
 // CalculateNewIndividualRatingsWithConfig calculates individual player ratings with an optional config override.
 func CalculateNewIndividualRatingsWithConfig(playerInfos []PlayerInfo, playerStats map[evr.EvrId]evr.MatchTypeStats, blueWins bool, config *SkillRatingSettings) map[string]types.Rating {
+	return CalculateNewIndividualRatingsForMode("", 0, playerInfos, playerStats, blueWins, config)
+}
+
+// CalculateNewIndividualRatingsForMode calculates individual player ratings with the rating engine of the guild and mode.
+func CalculateNewIndividualRatingsForMode(groupID string, mode evr.Symbol, playerInfos []PlayerInfo, playerStats map[evr.EvrId]evr.MatchTypeStats, blueWins bool, config *SkillRatingSettings) map[string]types.Rating {
 	// Get config from service settings if not provided
 	if config == nil {
 		if settings := ServiceSettings(); settings != nil {
@@ -329,14 +330,9 @@ func CalculateNewIndividualRatingsWithConfig(playerInfos []PlayerInfo, playerSta
 	}
 
 	// Get defaults
-	var tau float64 = 0.3
 	var multipliers map[string]float64
 
 	if config != nil {
-		tau = config.Defaults.Tau
-		if tau == 0 {
-			tau = 0.3
-		}
 		multipliers = config.PlayerStatMultipliers
 	}
 
@@ -390,10 +386,10 @@ func CalculateNewIndividualRatingsWithConfig(playerInfos []PlayerInfo, playerSta
 		return playerScores[b.SessionID] - playerScores[a.SessionID]
 	})
 
-	// Split the roster by teamRatings (all players are separated on their own team)
-	teamRatings := make([]types.Team, len(playerInfos))
+	// Each player is rated on their own
+	ratings := make([]types.Rating, len(playerInfos))
 	for i, p := range playerInfos {
-		teamRatings[i] = types.Team{p.Rating()}
+		ratings[i] = p.Rating()
 	}
 
 	// Create a map of player scores; used to weight the new ratings
@@ -403,15 +399,12 @@ func CalculateNewIndividualRatingsWithConfig(playerInfos []PlayerInfo, playerSta
 	}
 
 	// Calculate the new ratings
-	teamRatings = rating.Rate(teamRatings, &types.OpenSkillOptions{
-		Score: scores,
-		Tau:   ptr.Float64(tau),
-	})
+	newRatings := RatingEngineFor(config, groupID, mode).Rate(ratings, scores, config)
 
 	ratingMap := make(map[string]types.Rating, len(playerInfos))
-	for i, team := range teamRatings {
+	for i, r := range newRatings {
 		sID := playerInfos[i].SessionID
-		ratingMap[sID] = team[0]
+		ratingMap[sID] = r
 	}
 
 	return ratingMap
@@ -0,0 +1,414 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+	"go.uber.org/atomic"
+)
+
+const (
+	MatchmakerSnapshotStorageCollection = "MatchmakerSnapshots"
+	MatchmakerSnapshotIndexKey          = "index"
+
+	matchmakerSnapshotSlots         = 288 // The number of snapshots kept of each pool; the oldest is replaced.
+	matchmakerSnapshotWriteAttempts = 3
+)
+
+// ctxMatchmakerSimulationKey marks a simulated matchmaker run. Its value is the *matchmakerSimulationSettings to use.
+type ctxMatchmakerSimulationKey struct{}
+
+// matchmakerSimulationSettings replace the live settings in a simulated matchmaker run.
+type matchmakerSimulationSettings struct {
+	Matchmaking    *GlobalMatchmakingSettings
+	RatingDefaults *RatingDefaults
+}
+
+// MatchmakerCandidateSnapshot is a candidate set given to the matchmaker, stored to be replayed by the simulator.
+// Entries are stored once, and the candidates refer to them by index.
+type MatchmakerCandidateSnapshot struct {
+	Key        string                    `json:"key"`
+	Time       time.Time                 `json:"time"`
+	GroupID    string                    `json:"group_id"`
+	Mode       string                    `json:"mode"`
+	Settings   GlobalMatchmakingSettings `json:"settings"`                  // The matchmaking settings in effect.
+	Ratings    *RatingDefaults           `json:"rating_defaults,omitempty"` // The rating defaults in effect; older snapshots don't have them.
+	Entries    []*MatchmakerEntry        `json:"entries"`
+	Candidates [][]int                   `json:"candidates"`
+}
+
+// MatchmakerSnapshotInfo describes a stored snapshot, without its candidates.
+type MatchmakerSnapshotInfo struct {
+	Key        string    `json:"key"`
+	Time       time.Time `json:"time"`
+	GroupID    string    `json:"group_id"`
+	Mode       string    `json:"mode"`
+	Entries    int       `json:"entries"`
+	Candidates int       `json:"candidates"`
+}
+
+// MatchmakerSnapshotIndex lists the stored snapshots, newest first, so they can be listed without reading them.
+type MatchmakerSnapshotIndex struct {
+	Snapshots []MatchmakerSnapshotInfo `json:"snapshots"`
+}
+
+func NewMatchmakerCandidateSnapshot(now time.Time, groupID, mode string, settings *GlobalMatchmakingSettings, ratings *RatingDefaults, candidates [][]runtime.MatchmakerEntry) *MatchmakerCandidateSnapshot {
+	snapshot := &MatchmakerCandidateSnapshot{
+		Time:       now.UTC(),
+		GroupID:    groupID,
+		Mode:       mode,
+		Entries:    make([]*MatchmakerEntry, 0),
+		Candidates: make([][]int, 0, len(candidates)),
+	}
+	if settings != nil {
+		snapshot.Settings = *settings
+	}
+	if ratings != nil {
+		r := *ratings
+		snapshot.Ratings = &r
+	}
+
+	indexes := make(map[string]int)
+	for _, candidate := range candidates {
+		if candidate == nil {
+			continue
+		}
+		c := make([]int, 0, len(candidate))
+		for _, e := range candidate {
+			id := e.GetTicket() + ":" + e.GetPresence().GetSessionId()
+			i, ok := indexes[id]
+			if !ok {
+				i = len(snapshot.Entries)
+				indexes[id] = i
+				snapshot.Entries = append(snapshot.Entries, &MatchmakerEntry{
+					Ticket: e.GetTicket(),
+					Presence: &MatchmakerPresence{
+						UserId:    e.GetPresence().GetUserId(),
+						SessionId: e.GetPresence().GetSessionId(),
+						Username:  e.GetPresence().GetUsername(),
+						Node:      e.GetPresence().GetNodeId(),
+					},
+					Properties: maps.Clone(e.GetProperties()),
+					PartyId:    e.GetPartyId(),
+				})
+			}
+			c = append(c, i)
+		}
+		snapshot.Candidates = append(snapshot.Candidates, c)
+	}
+	return snapshot
+}
+
+func (s *MatchmakerCandidateSnapshot) Info() MatchmakerSnapshotInfo {
+	return MatchmakerSnapshotInfo{
+		Key:        s.Key,
+		Time:       s.Time,
+		GroupID:    s.GroupID,
+		Mode:       s.Mode,
+		Entries:    len(s.Entries),
+		Candidates: len(s.Candidates),
+	}
+}
+
+// Unpack returns new candidate slices, which the matchmaker may reorder and filter.
+// If maxRTT is positive, it replaces the players' max RTT.
+func (s *MatchmakerCandidateSnapshot) Unpack(maxRTT float64) [][]runtime.MatchmakerEntry {
+	entries := make([]runtime.MatchmakerEntry, len(s.Entries))
+	for i, e := range s.Entries {
+		if maxRTT > 0 {
+			c := *e
+			c.Properties = make(map[string]any, len(e.Properties)+1)
+			maps.Copy(c.Properties, e.Properties)
+			c.Properties["max_rtt"] = maxRTT
+			e = &c
+		}
+		entries[i] = e
+	}
+	candidates := make([][]runtime.MatchmakerEntry, len(s.Candidates))
+	for i, c := range s.Candidates {
+		candidates[i] = make([]runtime.MatchmakerEntry, len(c))
+		for j, k := range c {
+			candidates[i][j] = entries[k]
+		}
+	}
+	return candidates
+}
+
+// matchmakerSnapshotPool identifies a matchmaker pool; the matchmaker runs once per guild and mode.
+func matchmakerSnapshotPool(groupID, mode string) string {
+	return groupID + ":" + mode
+}
+
+// matchmakerSnapshotKey returns the storage slot of a pool's snapshot taken at the time. The slots are reused in turn.
+func matchmakerSnapshotKey(pool string, t time.Time, interval time.Duration) string {
+	return fmt.Sprintf("%s:slot-%04d", pool, (t.Unix()/int64(interval.Seconds()))%matchmakerSnapshotSlots)
+}
+
+// storeCandidateSnapshot stores the candidates for the simulator, at most once per the configured interval for each pool.
+func (m *SkillBasedMatchmaker) storeCandidateSnapshot(logger runtime.Logger, nk runtime.NakamaModule, groupID, mode string, settings *GlobalMatchmakingSettings, ratings *RatingDefaults, candidates [][]runtime.MatchmakerEntry) {
+	if settings == nil || settings.CandidateSnapshotIntervalSecs <= 0 {
+		return
+	}
+	interval := time.Duration(settings.CandidateSnapshotIntervalSecs) * time.Second
+	now := time.Now().UTC()
+	pool := matchmakerSnapshotPool(groupID, mode)
+	lastSnapshot, _ := m.lastSnapshots.LoadOrStore(pool, atomic.NewInt64(0))
+	last := lastSnapshot.Load()
+	if now.Sub(time.Unix(0, last)) < interval || !lastSnapshot.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	// Copy the candidates now; the matchmaker filters them in place.
+	snapshot := NewMatchmakerCandidateSnapshot(now, groupID, mode, settings, ratings, candidates)
+	snapshot.Key = matchmakerSnapshotKey(pool, now, interval)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := MatchmakerSnapshotWrite(ctx, nk, snapshot); err != nil {
+			logger.WithField("error", err).Warn("Failed to store matchmaker candidate snapshot")
+		}
+	}()
+}
+
+// MatchmakerSnapshotWrite stores the snapshot in its slot, and updates the index.
+// The index is written with its version, so writes that conflict with another pool's are retried.
+func MatchmakerSnapshotWrite(ctx context.Context, nk runtime.NakamaModule, snapshot *MatchmakerCandidateSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	for range matchmakerSnapshotWriteAttempts {
+		if err = matchmakerSnapshotWrite(ctx, nk, snapshot, data); !isStorageVersionConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func matchmakerSnapshotWrite(ctx context.Context, nk runtime.NakamaModule, snapshot *MatchmakerCandidateSnapshot, data []byte) error {
+	index, version, err := MatchmakerSnapshotIndexRead(ctx, nk)
+	if err != nil {
+		return err
+	}
+	index.Snapshots = slices.DeleteFunc(index.Snapshots, func(s MatchmakerSnapshotInfo) bool { return s.Key == snapshot.Key })
+	index.Snapshots = append([]MatchmakerSnapshotInfo{snapshot.Info()}, index.Snapshots...)
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      MatchmakerSnapshotStorageCollection,
+			Key:             snapshot.Key,
+			UserID:          SystemUserID,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+		{
+			Collection:      MatchmakerSnapshotStorageCollection,
+			Key:             MatchmakerSnapshotIndexKey,
+			UserID:          SystemUserID,
+			Value:           string(indexData),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
+
+// MatchmakerSnapshotIndexRead returns the snapshot index, and its storage version ("*" if it does not exist).
+func MatchmakerSnapshotIndexRead(ctx context.Context, nk runtime.NakamaModule) (*MatchmakerSnapshotIndex, string, error) {
+	index := &MatchmakerSnapshotIndex{Snapshots: make([]MatchmakerSnapshotInfo, 0)}
+	objs, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: MatchmakerSnapshotStorageCollection,
+		Key:        MatchmakerSnapshotIndexKey,
+		UserID:     SystemUserID,
+	}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objs) == 0 {
+		return index, "*", nil
+	}
+	if err := json.Unmarshal([]byte(objs[0].Value), index); err != nil {
+		return nil, "", err
+	}
+	return index, objs[0].Version, nil
+}
+
+// MatchmakerSnapshotsRead reads the snapshots by key.
+func MatchmakerSnapshotsRead(ctx context.Context, nk runtime.NakamaModule, keys []string) ([]*MatchmakerCandidateSnapshot, error) {
+	reads := make([]*runtime.StorageRead, 0, len(keys))
+	for _, key := range keys {
+		reads = append(reads, &runtime.StorageRead{
+			Collection: MatchmakerSnapshotStorageCollection,
+			Key:        key,
+			UserID:     SystemUserID,
+		})
+	}
+	objs, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*MatchmakerCandidateSnapshot, 0, len(objs))
+	for _, obj := range objs {
+		snapshot := &MatchmakerCandidateSnapshot{}
+		if err := json.Unmarshal([]byte(obj.Value), snapshot); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal snapshot %s: %w", obj.Key, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// MatchmakerSimulationVariant is an alternative to the matchmaking settings the snapshots were taken with.
+type MatchmakerSimulationVariant struct {
+	Name     string          `json:"name"`
+	Settings json.RawMessage `json:"settings,omitempty"` // Matchmaking settings that replace the snapshot's, e.g. {"use_snake_draft_team_formation": true}
+	MaxRTT   float64         `json:"max_rtt,omitempty"`  // Replaces the players' max RTT, in milliseconds.
+}
+
+// MatchmakerDistribution summarizes a set of samples.
+type MatchmakerDistribution struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+func NewMatchmakerDistribution(samples []float64) MatchmakerDistribution {
+	if len(samples) == 0 {
+		return MatchmakerDistribution{}
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	percentile := func(p float64) float64 {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
+	d := MatchmakerDistribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		Max:   sorted[len(sorted)-1],
+	}
+	for _, v := range sorted {
+		d.Mean += v / float64(len(sorted))
+	}
+	return d
+}
+
+// MatchmakerSimulationResult is the outcome of a variant, over all of the replayed snapshots.
+type MatchmakerSimulationResult struct {
+	Variant          string                 `json:"variant"`
+	Snapshots        int                    `json:"snapshots"`
+	Matches          int                    `json:"matches"`
+	Players          int                    `json:"players"`
+	MatchedPlayers   int                    `json:"matched_players"`
+	DrawProbability  MatchmakerDistribution `json:"draw_probability"` // Predicted, per match.
+	WaitTimeSecs     MatchmakerDistribution `json:"wait_time_secs"`   // Per matched player, at the time of the snapshot.
+	RTTMs            MatchmakerDistribution `json:"rtt_ms"`           // Per match, the highest player RTT to the best common server.
+	ProcessingTimeMs MatchmakerDistribution `json:"processing_time_ms"`
+	Errors           []string               `json:"errors,omitempty"`
+}
+
+// MatchmakerBaselineVariant is the name of the result of replaying the snapshots with their own settings.
+const MatchmakerBaselineVariant = "baseline"
+
+// RunMatchmakerSimulation replays the snapshots through the matchmaker, with their own settings and with each variant.
+// The baseline result is first, followed by the variants in order.
+func RunMatchmakerSimulation(ctx context.Context, logger runtime.Logger, sbmm *SkillBasedMatchmaker, snapshots []*MatchmakerCandidateSnapshot, variants []MatchmakerSimulationVariant) []*MatchmakerSimulationResult {
+	variants = append([]MatchmakerSimulationVariant{{Name: MatchmakerBaselineVariant}}, variants...)
+	results := make([]*MatchmakerSimulationResult, 0, len(variants))
+
+	for _, variant := range variants {
+		result := &MatchmakerSimulationResult{Variant: variant.Name}
+		var draws, waits, rtts, durations []float64
+
+		for _, snapshot := range snapshots {
+			settings := snapshot.Settings
+			if len(variant.Settings) > 0 {
+				if err := json.Unmarshal(variant.Settings, &settings); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("invalid settings: %v", err))
+					break
+				}
+			}
+			candidates := snapshot.Unpack(variant.MaxRTT)
+
+			startTime := time.Now()
+			simulation := &matchmakerSimulationSettings{Matchmaking: &settings, RatingDefaults: snapshot.Ratings}
+			matches := sbmm.EvrMatchmakerFn(context.WithValue(ctx, ctxMatchmakerSimulationKey{}, simulation), logger, nil, nil, candidates)
+			durations = append(durations, float64(time.Since(startTime).Microseconds())/1000)
+
+			players := make(map[string]struct{})
+			for _, e := range snapshot.Entries {
+				players[e.Presence.UserId] = struct{}{}
+			}
+			result.Snapshots++
+			result.Players += len(players)
+			result.Matches += len(matches)
+
+			opts := matchmakerPredictionConfig(&settings, snapshot.Ratings).OpenSkillOptions
+			for _, match := range matches {
+				result.MatchedPlayers += len(match)
+				blue, orange := MatchmakerEntries(match[:len(match)/2]), MatchmakerEntries(match[len(match)/2:])
+				draws = append(draws, rating.PredictDraw([]types.Team{blue.Ratings(opts), orange.Ratings(opts)}, opts))
+				for _, e := range match {
+					if st, ok := e.GetProperties()["submission_time"].(float64); ok {
+						waits = append(waits, max(0, float64(snapshot.Time.Unix())-st))
+					}
+				}
+				if rtt, ok := matchmakerMatchRTT(match); ok {
+					rtts = append(rtts, rtt)
+				}
+			}
+		}
+
+		result.DrawProbability = NewMatchmakerDistribution(draws)
+		result.WaitTimeSecs = NewMatchmakerDistribution(waits)
+		result.RTTMs = NewMatchmakerDistribution(rtts)
+		result.ProcessingTimeMs = NewMatchmakerDistribution(durations)
+		results = append(results, result)
+	}
+	return results
+}
+
+// matchmakerMatchRTT returns the highest player RTT to the server that is best for all of the players of the match.
+func matchmakerMatchRTT(match []runtime.MatchmakerEntry) (float64, bool) {
+	worst := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, e := range match {
+		for k, v := range e.GetProperties() {
+			rtt, ok := v.(float64)
+			if !ok || !strings.HasPrefix(k, RTTPropertyPrefix) {
+				continue
+			}
+			counts[k]++
+			worst[k] = max(worst[k], rtt)
+		}
+	}
+	best, found := 0.0, false
+	for k, rtt := range worst {
+		if counts[k] == len(match) && (!found || rtt < best) {
+			best, found = rtt, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func testSimulatorCandidates(now time.Time) [][]runtime.MatchmakerEntry {
+	entries := make([]runtime.MatchmakerEntry, 8)
+	for i := range entries {
+		entries[i] = &MatchmakerEntry{
+			Ticket: fmt.Sprintf("ticket-%02d-xxxxxxxx", i),
+			Presence: &MatchmakerPresence{
+				UserId:    fmt.Sprintf("user-%d", i),
+				SessionId: fmt.Sprintf("session-%d", i),
+			},
+			Properties: map[string]any{
+				"group_id":        "group",
+				"game_mode":       "echo_arena",
+				"rating_mu":       float64(20 + i),
+				"rating_sigma":    3.0,
+				"submission_time": float64(now.Add(-time.Duration(i+1) * 10 * time.Second).Unix()),
+				"rtt_10.0.0.1":    float64(40 + i*5),
+				"rtt_10.0.0.2":    90.0,
+			},
+		}
+	}
+	// The same players, in two orders.
+	reversed := make([]runtime.MatchmakerEntry, len(entries))
+	for i, e := range entries {
+		reversed[len(entries)-1-i] = e
+	}
+	return [][]runtime.MatchmakerEntry{entries, reversed}
+}
+
+func TestMatchmakerCandidateSnapshot_RoundTrip(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	candidates := testSimulatorCandidates(now)
+	settings := &GlobalMatchmakingSettings{PartySkillBoostPercent: 0.1}
+
+	snapshot := NewMatchmakerCandidateSnapshot(now, "group", "echo_arena", settings, &RatingDefaults{Mu: 30, Sigma: 8, Z: 3}, candidates)
+	assert.Len(t, snapshot.Entries, 8, "entries are stored once")
+	assert.Len(t, snapshot.Candidates, 2)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	decoded := &MatchmakerCandidateSnapshot{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, 0.1, decoded.Settings.PartySkillBoostPercent)
+	assert.Equal(t, &RatingDefaults{Mu: 30, Sigma: 8, Z: 3}, decoded.Ratings)
+
+	unpacked := decoded.Unpack(0)
+	require.Len(t, unpacked, 2)
+	assert.Equal(t, candidates[1][0].GetTicket(), unpacked[1][0].GetTicket())
+	assert.Same(t, unpacked[0][0], unpacked[1][7], "unpacked candidates share entries")
+
+	// The max RTT override does not change the stored entries.
+	unpacked = decoded.Unpack(25)
+	assert.Equal(t, 25.0, unpacked[0][0].GetProperties()["max_rtt"])
+	assert.NotContains(t, decoded.Entries[0].Properties, "max_rtt")
+
+	// Entries without properties can be overridden too.
+	decoded.Entries[0].Properties = nil
+	assert.Equal(t, 25.0, decoded.Unpack(25)[0][0].GetProperties()["max_rtt"])
+}
+
+func TestRunMatchmakerSimulation(t *testing.T) {
+	now := time.Now().UTC()
+	snapshot := NewMatchmakerCandidateSnapshot(now, "group", "echo_arena", &GlobalMatchmakingSettings{}, &RatingDefaults{Mu: 25, Sigma: 8.333, Z: 3}, testSimulatorCandidates(now))
+
+	results := RunMatchmakerSimulation(context.Background(), NewRuntimeGoLogger(logger), NewSkillBasedMatchmaker(), []*MatchmakerCandidateSnapshot{snapshot}, []MatchmakerSimulationVariant{
+		{Name: "snake", Settings: json.RawMessage(`{"use_snake_draft_team_formation": true}`)},
+		{Name: "low_rtt", MaxRTT: 30},
+		{Name: "invalid", Settings: json.RawMessage(`{"use_snake_draft_team_formation": "yes"}`)},
+	})
+	require.Len(t, results, 4)
+
+	baseline, snake, lowRTT, invalid := results[0], results[1], results[2], results[3]
+	assert.Equal(t, MatchmakerBaselineVariant, baseline.Variant)
+
+	for _, r := range []*MatchmakerSimulationResult{baseline, snake} {
+		assert.Equal(t, 1, r.Snapshots)
+		assert.Equal(t, 1, r.Matches, r.Variant)
+		assert.Equal(t, 8, r.Players)
+		assert.Equal(t, 8, r.MatchedPlayers)
+		assert.Equal(t, 1, r.DrawProbability.Count)
+		assert.Equal(t, 75.0, r.RTTMs.Max, "the best common server is the one with the lowest worst RTT")
+		assert.Equal(t, 8, r.WaitTimeSecs.Count)
+		assert.InDelta(t, 80, r.WaitTimeSecs.Max, 1)
+		assert.Greater(t, r.DrawProbability.Mean, 0.0)
+		assert.LessOrEqual(t, r.DrawProbability.Mean, 1.0)
+	}
+
+	assert.Zero(t, lowRTT.Matches, "no common server within the max RTT")
+	assert.NotEmpty(t, invalid.Errors)
+}
+
+func TestNewMatchmakerDistribution(t *testing.T) {
+	samples := make([]float64, 100)
+	for i := range samples {
+		samples[100-1-i] = float64(i + 1)
+	}
+	d := NewMatchmakerDistribution(samples)
+	assert.Equal(t, 100, d.Count)
+	assert.Equal(t, 1.0, d.Min)
+	assert.Equal(t, 50.0, d.P50)
+	assert.Equal(t, 90.0, d.P90)
+	assert.Equal(t, 99.0, d.P99)
+	assert.Equal(t, 100.0, d.Max)
+	assert.InDelta(t, 50.5, d.Mean, 0.0001)
+
+	assert.Zero(t, NewMatchmakerDistribution(nil).Count)
+}
+
+func TestMatchmakerSnapshotKey_Pools(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	arena := matchmakerSnapshotKey(matchmakerSnapshotPool("group", "echo_arena"), now, 5*time.Minute)
+	combat := matchmakerSnapshotKey(matchmakerSnapshotPool("group", "echo_combat"), now, 5*time.Minute)
+	assert.NotEqual(t, arena, combat, "each pool has its own slots")
+	assert.Equal(t, arena, matchmakerSnapshotKey(matchmakerSnapshotPool("group", "echo_arena"), now.Add(matchmakerSnapshotSlots*5*time.Minute), 5*time.Minute))
+
+	assert.True(t, isStorageVersionConflict(runtime.ErrStorageRejectedVersion))
+	assert.True(t, isStorageVersionConflict(StatusError(codes.InvalidArgument, "Storage write rejected.", runtime.ErrStorageRejectedVersion)))
+	assert.False(t, isStorageVersionConflict(context.DeadlineExceeded))
+	assert.False(t, isStorageVersionConflict(nil))
+}
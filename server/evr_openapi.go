@@ -0,0 +1,269 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const EvrOpenAPIPath = "/apievr/openapi.json"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// OpenAPISchema is the subset of the OpenAPI 3.0 schema object used by the generator.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+}
+
+// openAPISchemaBuilder converts Go types into schemas, following the encoding/json rules.
+// Named struct types are added to the components, and referenced.
+type openAPISchemaBuilder struct {
+	components map[string]*OpenAPISchema
+	names      map[reflect.Type]string
+}
+
+func newOpenAPISchemaBuilder() *openAPISchemaBuilder {
+	return &openAPISchemaBuilder{
+		components: make(map[string]*OpenAPISchema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// componentName returns a unique component name for the named type.
+func (b *openAPISchemaBuilder) componentName(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := b.components[name]; taken {
+		// Another package has a type of the same name.
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	b.names[t] = name
+	return name
+}
+
+func (b *openAPISchemaBuilder) Schema(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &OpenAPISchema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &OpenAPISchema{Type: "string"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom encoding; the shape is unknown.
+		return &OpenAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: b.Schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: b.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := b.componentName(t)
+		if _, ok := b.components[name]; !ok {
+			// Reserve the name before descending, for recursive types.
+			b.components[name] = &OpenAPISchema{}
+			*b.components[name] = *b.structSchema(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	default:
+		// Interfaces and anything else can hold any value.
+		return &OpenAPISchema{}
+	}
+}
+
+func (b *openAPISchemaBuilder) structSchema(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	b.addFields(s, t)
+	return s
+}
+
+func (b *openAPISchemaBuilder) addFields(s *OpenAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// Embedded struct fields are promoted.
+			b.addFields(s, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = b.Schema(f.Type)
+	}
+}
+
+// QueryParameters returns the query parameters described by the struct.
+func (b *openAPISchemaBuilder) QueryParameters(t reflect.Type) []map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	params := make([]map[string]any, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		params = append(params, map[string]any{
+			"name":   name,
+			"in":     "query",
+			"schema": b.Schema(f.Type),
+		})
+	}
+	return params
+}
+
+// EvrOpenAPISpec returns the OpenAPI 3.0 document for the RPCs.
+// RPCs are called on /v2/rpc/{id}?unwrap, with the JSON payload as the POST body;
+// RPCs without a payload are called with GET.
+func EvrOpenAPISpec(rpcs []EvrRPC) map[string]any {
+	b := newOpenAPISchemaBuilder()
+
+	paths := make(map[string]any, len(rpcs))
+	for _, rpc := range rpcs {
+		op := map[string]any{
+			"operationId": rpc.ID,
+			"summary":     rpc.Summary,
+			"tags":        []string{strings.Split(rpc.ID, "/")[0]},
+		}
+
+		params := []map[string]any{{
+			"name":        "unwrap",
+			"in":          "query",
+			"description": "Send and receive the payload without the gRPC gateway wrapper.",
+			"schema":      &OpenAPISchema{Type: "boolean"},
+		}}
+		if rpc.Query != nil {
+			params = append(params, b.QueryParameters(reflect.TypeOf(rpc.Query))...)
+		}
+		op["parameters"] = params
+
+		if rpc.Request != nil {
+			op["requestBody"] = map[string]any{
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": b.Schema(reflect.TypeOf(rpc.Request))},
+				},
+			}
+		}
+
+		response := map[string]any{"description": "Success"}
+		if rpc.Response != nil {
+			response["content"] = map[string]any{
+				"application/json": map[string]any{"schema": b.Schema(reflect.TypeOf(rpc.Response))},
+			}
+		} else {
+			response["content"] = map[string]any{
+				"text/plain": map[string]any{"schema": &OpenAPISchema{Type: "string"}},
+			}
+		}
+		op["responses"] = map[string]any{
+			"200":     response,
+			"default": map[string]any{"$ref": "#/components/responses/Error"},
+		}
+
+		method := "post"
+		if rpc.Request == nil {
+			method = "get"
+		}
+		paths["/v2/rpc/"+rpc.ID] = map[string]any{method: op}
+	}
+
+	b.components["RPCError"] = &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"error":   {},
+			"message": {Type: "string"},
+			"code":    {Type: "integer", Format: "int32", Description: "gRPC status code"},
+		},
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "EVR RPC API",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.components,
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": &OpenAPISchema{Ref: "#/components/schemas/RPCError"}},
+					},
+				},
+			},
+			"securitySchemes": map[string]any{
+				"session": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"httpKey": map[string]any{"type": "apiKey", "in": "query", "name": "http_key"},
+			},
+		},
+		"security": []map[string][]string{{"session": {}}, {"httpKey": {}}},
+	}
+}
+
+// NewEvrOpenAPIHandler serves the OpenAPI document for the RPCs.
+func NewEvrOpenAPIHandler(rpcs []EvrRPC) (func(http.ResponseWriter, *http.Request), error) {
+	data, err := json.Marshal(EvrOpenAPISpec(rpcs))
+	if err != nil {
+		return nil, err
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}, nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvrOpenAPISpec(t *testing.T) {
+	rpcs := EvrRPCs(nil, nil)
+
+	data, err := json.Marshal(EvrOpenAPISpec(rpcs))
+	require.NoError(t, err)
+
+	spec := struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}{}
+	require.NoError(t, json.Unmarshal(data, &spec))
+
+	ids := make(map[string]bool, len(rpcs))
+	for _, rpc := range rpcs {
+		assert.False(t, ids[rpc.ID], "duplicate rpc %s", rpc.ID)
+		ids[rpc.ID] = true
+		assert.NotNil(t, rpc.Fn, "rpc %s has no function", rpc.ID)
+		assert.NotEmpty(t, rpc.Summary, "rpc %s has no summary", rpc.ID)
+		assert.Contains(t, spec.Paths, "/v2/rpc/"+rpc.ID)
+	}
+
+	// Every reference must resolve.
+	for _, ref := range strings.Split(string(data), `"$ref":"`)[1:] {
+		ref = ref[:strings.Index(ref, `"`)]
+		if name, ok := strings.CutPrefix(ref, "#/components/schemas/"); ok {
+			assert.Contains(t, spec.Components.Schemas, name)
+		}
+	}
+}
+
+func TestOpenAPISchemaBuilder(t *testing.T) {
+	type node struct {
+		Name     string          `json:"name"`
+		Children []*node         `json:"children,omitempty"`
+		Ignored  string          `json:"-"`
+		Raw      json.RawMessage `json:"raw"`
+		private  int
+	}
+
+	b := newOpenAPISchemaBuilder()
+	s := b.Schema(reflect.TypeOf(&node{}))
+	assert.Equal(t, "#/components/schemas/node", s.Ref)
+
+	c := b.components["node"]
+	require.NotNil(t, c)
+	assert.Equal(t, "object", c.Type)
+	assert.Equal(t, "string", c.Properties["name"].Type)
+	assert.Equal(t, "#/components/schemas/node", c.Properties["children"].Items.Ref)
+	assert.NotContains(t, c.Properties, "Ignored")
+	assert.NotContains(t, c.Properties, "private")
+
+	// Text marshalers are strings.
+	assert.Equal(t, "string", b.Schema(reflect.TypeOf(MatchID{})).Type)
+	b.Schema(reflect.TypeOf(DisplayNameMatchItem{}))
+	assert.Equal(t, "date-time", b.components["DisplayNameMatchItem"].Properties["updated_at"].Format)
+}
+
+func TestEvrRPCGoTypes(t *testing.T) {
+	// The client types must be regenerated when the RPC schemas change.
+	src, err := EvrRPCGoTypes(EvrRPCs(nil, nil), []any{TournamentBracketListResponse{}}, "evrrpc", "evrclient/evrrpc/internal/gen")
+	require.NoError(t, err)
+	current, err := os.ReadFile("../evrclient/evrrpc/types.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(current), string(src), "run go generate ./evrclient/evrrpc")
+}
+
+func TestGoTypesBuilder(t *testing.T) {
+	type inner struct {
+		Count int `json:"count"`
+	}
+	type node struct {
+		sync.Mutex
+		inner
+		Name     string          `json:"name"`
+		MatchID  MatchID         `json:"match_id"`
+		Children []*node         `json:"children,omitempty"`
+		Ignored  string          `json:"-"`
+		Raw      json.RawMessage `json:"raw"`
+		Callback func()
+		private  int
+	}
+
+	b := newGoTypesBuilder()
+	assert.Equal(t, "*node", b.TypeExpr(reflect.TypeOf(&node{})))
+	decl := b.decls["node"]
+	assert.Contains(t, decl, "\ninner\n", "embedded server types are embedded")
+	assert.Contains(t, b.decls["inner"], "Count int `json:\"count\"`")
+	assert.Contains(t, decl, "MatchID string `json:\"match_id\"`", "text marshalers are strings")
+	assert.Contains(t, decl, "Children []*node `json:\"children,omitempty\"`")
+	assert.Contains(t, decl, "Raw json.RawMessage `json:\"raw\"`")
+	for _, s := range []string{"Mutex", "Ignored", "Callback", "private"} {
+		assert.NotContains(t, decl, s)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -37,6 +39,10 @@ var unrequireMessage = evr.NewSTcpConnectionUnrequireEvent()
 
 var globalMatchmaker = atomic.NewPointer[LocalMatchmaker](nil)
 var globalAppBot = atomic.NewPointer[DiscordAppBot](nil)
+var globalEvrRecorders = atomic.NewPointer[EvrRecorderRegistry](nil)
+var globalGameServerRegistry = atomic.NewPointer[GameServerRegistry](nil)
+var globalGuildGroupRegistry = atomic.NewPointer[GuildGroupRegistry](nil)
+var globalMongoClient = atomic.NewPointer[mongo.Client](nil)
 
 type EvrPipeline struct {
 	sync.RWMutex
@@ -93,6 +99,14 @@ func NewEvrPipeline(logger *zap.Logger, startupLogger *zap.Logger, db *sql.DB, p
 		logger.Fatal("Failed to load global settings", zap.Error(err))
 	}
 
+	// Load the symbol dictionaries, from the file and storage, on top of the built-in symbol cache.
+	symbolDictionary := NewSymbolDictionaryLoader(nk, config.GetName(), vars["EVR_SYMBOL_DICTIONARY_FILE"])
+	if n, err := symbolDictionary.Load(ctx); err != nil {
+		logger.Error("Failed to load symbol dictionary", zap.Error(err))
+	} else {
+		logger.Info("Loaded symbol dictionary", zap.Int("tokens", n))
+	}
+
 	go func() {
 
 		interval := 30 * time.Second
@@ -108,6 +122,14 @@ func NewEvrPipeline(logger *zap.Logger, startupLogger *zap.Logger, db *sql.DB, p
 				if _, err := ServiceSettingsLoad(ctx, NewRuntimeGoLogger(logger), nk); err != nil {
 					logger.Error("Failed to load global settings", zap.Error(err))
 				}
+				if n, err := symbolDictionary.Load(ctx); err != nil {
+					logger.Error("Failed to reload symbol dictionary", zap.Error(err))
+				} else if n > 0 {
+					logger.Info("Reloaded symbol dictionary", zap.Int("tokens", n))
+				}
+				if err := symbolDictionary.ShareObservations(ctx); err != nil {
+					logger.Warn("Failed to share symbol observations", zap.Error(err))
+				}
 			}
 		}
 	}()
@@ -118,19 +140,21 @@ func NewEvrPipeline(logger *zap.Logger, startupLogger *zap.Logger, db *sql.DB, p
 	}
 
 	var err error
-	if botToken != "" {
-		dg, err = discordgo.New("Bot " + botToken)
-		if err != nil {
-			logger.Error("Unable to create bot")
-		}
+	if botToken == "" {
+		dg = nil
+		logger.Warn("Discord bot token is not set, running without Discord.")
+	} else if dg, err = discordgo.New("Bot " + botToken); err != nil {
+		logger.Fatal("Unable to create bot", zap.Error(err))
+	} else {
 		dg.StateEnabled = true
 	}
 
 	runtimeLogger := NewRuntimeGoLogger(logger)
 	guildGroupRegistry := NewGuildGroupRegistry(ctx, runtimeLogger, nk, db)
+	globalGuildGroupRegistry.Store(guildGroupRegistry)
 
 	profileRegistry := NewProfileRegistry(nk, db, runtimeLogger, metrics, sessionRegistry)
-	lobbyBuilder := NewLobbyBuilder(logger, nk, sessionRegistry, matchRegistry, tracker, metrics)
+	lobbyBuilder := NewLobbyBuilder(logger, db, nk, sessionRegistry, matchRegistry, tracker, metrics)
 	matchmaker.OnMatchedEntries(lobbyBuilder.handleMatchedEntries)
 	userRemoteLogJournalRegistry := NewUserRemoteLogJournalRegistry(ctx, logger, nk, sessionRegistry)
 
@@ -160,6 +184,22 @@ func NewEvrPipeline(logger *zap.Logger, startupLogger *zap.Logger, db *sql.DB, p
 		providers = append(providers, ipapiClient)
 
 	}
+
+	// Local MaxMind-format databases, used before or after the HTTP providers.
+	if vars["MMDB_CITY_FILE"] != "" || vars["MMDB_ASN_FILE"] != "" {
+		mmdbClient, err := NewMMDBClient(logger, metrics, vars["MMDB_CITY_FILE"], vars["MMDB_ASN_FILE"])
+		if err != nil {
+			logger.Fatal("Failed to create MMDB client", zap.Error(err))
+		}
+		switch vars["MMDB_PROVIDER_PRIORITY"] {
+		case IPInfoProviderPriorityPrimary:
+			providers = append([]IPInfoProvider{mmdbClient}, providers...)
+		case IPInfoProviderPriorityFallback, "":
+			providers = append(providers, mmdbClient)
+		default:
+			logger.Fatal("Invalid MMDB provider priority", zap.String("priority", vars["MMDB_PROVIDER_PRIORITY"]))
+		}
+	}
 	ipInfoCache, err := NewIPInfoCache(logger, metrics, providers...)
 	if err != nil {
 		logger.Fatal("Failed to create IP info cache", zap.Error(err))
@@ -169,29 +209,53 @@ func NewEvrPipeline(logger *zap.Logger, startupLogger *zap.Logger, db *sql.DB, p
 	discordIntegrator := NewDiscordIntegrator(ctx, logger, config, metrics, nk, db, dg, guildGroupRegistry)
 	discordIntegrator.Start()
 
-	appBot, err = NewDiscordAppBot(ctx, runtimeLogger, nk, db, metrics, pipeline, config, discordIntegrator, profileRegistry, statusRegistry, dg, ipInfoCache, guildGroupRegistry)
-	if err != nil {
-		logger.Error("Failed to create app bot", zap.Error(err))
-
+	// Sessions are only recorded on request; this sets where the recordings are written.
+	recordingDir := vars["EVR_RECORDING_DIR"]
+	if recordingDir == "" {
+		recordingDir = filepath.Join(config.GetDataDir(), "recordings")
+	}
+	globalEvrRecorders.Store(NewEvrRecorderRegistry(recordingDir))
+	globalGameServerRegistry.Store(NewGameServerRegistry(ctx, logger, nk, config.GetName()))
+	StartRatingMaintenance(ctx, runtimeLogger, nk)
+
+	// Register the community providers that back the guild groups.
+	guildGroupRegistry.RegisterCommunityProvider(NewDiscordCommunityProvider(discordIntegrator))
+	if path := vars["COMMUNITY_PROVIDER_FILE"]; path != "" {
+		fileProvider, err := NewFileCommunityProvider(runtimeLogger, nk, db, path)
+		if err != nil {
+			logger.Fatal("Failed to create file community provider", zap.Error(err))
+		}
+		guildGroupRegistry.RegisterCommunityProvider(fileProvider)
 	}
-	// Store the app bot in a global variable for later use
-	globalAppBot.Store(appBot) // TODO: This is a temporary solution, we should refactor this to avoid global state
 
-	// Add a once handler to wait for the bot to connect
-	readyCh := make(chan struct{})
-	dg.AddHandlerOnce(func(s *discordgo.Session, r *discordgo.Ready) {
-		close(readyCh)
-	})
+	if dg != nil {
+		appBot, err = NewDiscordAppBot(ctx, runtimeLogger, nk, db, metrics, pipeline, config, discordIntegrator, profileRegistry, statusRegistry, dg, ipInfoCache, guildGroupRegistry)
+		if err != nil {
+			logger.Error("Failed to create app bot", zap.Error(err))
 
-	if err = dg.Open(); err != nil {
-		logger.Fatal("Failed to open discord bot connection: %w", zap.Error(err))
-	}
+		}
+		// Store the app bot in a global variable for later use
+		globalAppBot.Store(appBot) // TODO: This is a temporary solution, we should refactor this to avoid global state
+
+		// Add a once handler to wait for the bot to connect
+		readyCh := make(chan struct{})
+		dg.AddHandlerOnce(func(s *discordgo.Session, r *discordgo.Ready) {
+			close(readyCh)
+		})
 
-	select {
-	case <-readyCh:
-		logger.Info("Discord bot is ready")
-	case <-time.After(10 * time.Second):
-		logger.Fatal("Discord bot is not ready after 10 seconds")
+		if err = dg.Open(); err != nil {
+			logger.Fatal("Failed to open discord bot connection: %w", zap.Error(err))
+		}
+
+		select {
+		case <-readyCh:
+			logger.Info("Discord bot is ready")
+		case <-time.After(10 * time.Second):
+			logger.Fatal("Discord bot is not ready after 10 seconds")
+		}
+	} else {
+		// Slash commands, audit channels and role sync are unavailable.
+		logger.Warn("Discord app bot is disabled")
 	}
 
 	internalIP, externalIP, err := DetermineServiceIPs(ctx)
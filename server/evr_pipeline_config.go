@@ -14,6 +14,21 @@ import (
 func (p *EvrPipeline) configRequest(ctx context.Context, logger *zap.Logger, session *sessionWS, in evr.Message) error {
 	message := in.(*evr.ConfigRequest)
 
+	// Serve the targeted variant of the active version, if there is one.
+	// Versioned resources are validated with an ID equal to their type, so other IDs fall through.
+	if message.ID == message.Type {
+		client := ConfigResourceClient{}
+		if params, ok := LoadParams(ctx); ok {
+			client = NewConfigResourceClient(&params)
+		}
+		if resource, variant, err := ConfigResourceSelect(ctx, p.nk, message.Type, client); err != nil {
+			logger.Warn("failed to read config resources", zap.Error(err))
+		} else if resource != nil {
+			logger.Debug("serving config resource", zap.String("type", message.Type), zap.String("variant", variant))
+			return p.sendConfigResource(session, message, string(resource))
+		}
+	}
+
 	// Retrieve the requested object.
 	objs, err := StorageReadObjects(ctx, logger, session.pipeline.db, uuid.Nil, []*api.ReadStorageObjectId{
 		{
@@ -44,6 +59,10 @@ func (p *EvrPipeline) configRequest(ctx context.Context, logger *zap.Logger, ses
 		return fmt.Errorf("resource not found: %s", message.ID)
 	}
 
+	return p.sendConfigResource(session, message, jsonResource)
+}
+
+func (p *EvrPipeline) sendConfigResource(session *sessionWS, message *evr.ConfigRequest, jsonResource string) error {
 	// Parse the JSON resource
 	resource := make(map[string]interface{})
 	if err := json.Unmarshal([]byte(jsonResource), &resource); err != nil {
@@ -211,6 +211,7 @@ func (p *EvrPipeline) gameserverRegistrationRequest(logger *zap.Logger, session
 
 	// Create the broadcaster config
 	config := NewGameServerPresence(session.UserID(), session.id, serverID, internalIP, externalIP, externalPort, hostingGroupIDs, defaultRegion, regionCodes, versionLock, params.serverTags, params.supportedFeatures, request.TimeStepUsecs, ipInfo, params.geoHashPrecision, isNative, request.Version)
+	config.Capacity = params.serverCapacity
 
 	logger = logger.With(zap.String("internal_ip", internalIP.String()), zap.String("external_ip", externalIP.String()), zap.Uint16("port", externalPort))
 
@@ -248,6 +249,9 @@ func (p *EvrPipeline) gameserverRegistrationRequest(logger *zap.Logger, session
 			logger.Error("Broadcaster could not be reached", zap.Error(err))
 			errorMessage := fmt.Sprintf("Broadcaster (Endpoint ID: %s, Server ID: %d) could not be reached. Error: %v", config.Endpoint.String(), config.ServerID, err)
 			go sendDiscordError(errors.New(errorMessage), params.DiscordID(), logger, p.discordCache.dg)
+			if registry := globalGameServerRegistry.Load(); registry != nil {
+				registry.RegistrationFailed(ctx, config, fmt.Errorf("could not be reached: %w", err))
+			}
 			return errFailedRegistration(session, logger, errors.New(errorMessage), evr.BroadcasterRegistration_Failure)
 		}
 	} else {
@@ -298,8 +302,21 @@ func (p *EvrPipeline) gameserverRegistrationRequest(logger *zap.Logger, session
 		}
 	}
 
+	// Add the game server to the fleet registry, which drains it from allocation if the monitor's health checks fail.
+	registry := globalGameServerRegistry.Load()
+	if registry != nil {
+		registry.Register(ctx, config)
+	}
+
 	// Monitor the game server and create new parking matches as needed.
 	go func() {
+		if registry != nil {
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				registry.Unregister(ctx, session.ID(), "session closed")
+			}()
+		}
 		// Create the initial parking match for the game server.
 		if _, err = newGameServerParkingMatch(NewRuntimeGoLogger(logger), p.nk, config); err != nil {
 			errFailedRegistration(session, logger, err, evr.BroadcasterRegistration_Failure)
@@ -315,6 +332,10 @@ func (p *EvrPipeline) gameserverRegistrationRequest(logger *zap.Logger, session
 			case <-time.After(5 * time.Second):
 				// Check if the game server is still alive
 				rtts, err := BroadcasterRTTcheck(p.internalIP, config.Endpoint.ExternalIP, int(config.Endpoint.Port), 5, 500*time.Millisecond)
+				if registry != nil {
+					rtt, err := gameServerRTTCheckResult(rtts, err)
+					registry.RecordCheck(session.ID(), rtt, err)
+				}
 				if err != nil || len(rtts) == 0 {
 					logger.Warn("Game server is not responding", zap.Error(err), zap.String("endpoint", config.Endpoint.String()))
 					// Send the discord error
@@ -518,6 +539,9 @@ func HealthCheckStart(ctx context.Context, logger *zap.Logger, nk runtime.Nakama
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
+	// The registry's health checks are recorded by the registration's monitor; these pings only measure the RTT.
+	registry := globalGameServerRegistry.Load()
+
 	for {
 		// Generate a random 8-byte number for the ping request
 		if _, err := rand.Read(request[8:]); err != nil {
@@ -545,6 +569,9 @@ func HealthCheckStart(ctx context.Context, logger *zap.Logger, nk runtime.Nakama
 			if l, err := MatchLabelByID(ctx, nk, matchID); err != nil {
 				logger.Warn("Failed to get match label by ID", zap.Error(err))
 			} else if l != nil {
+				if registry != nil {
+					registry.SetInMatch(sessionID, l.LobbyType != UnassignedLobby)
+				}
 				if l.LobbyType != UnassignedLobby {
 					// match is active. add metadata.
 					maps.Copy(tags, map[string]string{
@@ -697,6 +724,28 @@ func BroadcasterPortScan(lIP net.IP, rIP net.IP, startPort, endPort int, timeout
 	return rtts, errs
 }
 
+// gameServerRTTCheckResult reduces the samples of a BroadcasterRTTcheck to one health check result.
+// The check fails if no sample got a response.
+func gameServerRTTCheckResult(rtts []time.Duration, err error) (time.Duration, error) {
+	if err != nil {
+		return 0, err
+	}
+	var (
+		total time.Duration
+		count int
+	)
+	for _, rtt := range rtts {
+		if rtt >= 0 {
+			total += rtt
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, errors.New("no response to pings")
+	}
+	return total / time.Duration(count), nil
+}
+
 func BroadcasterRTTcheck(lIP net.IP, rIP net.IP, port, count int, timeout time.Duration) (rtts []time.Duration, err error) {
 	// Create a slice to store round trip times (rtts)
 	rtts = make([]time.Duration, count)
@@ -338,7 +338,7 @@ func (p *EvrPipeline) authenticateSession(ctx context.Context, logger *zap.Logge
 
 				return DeviceNotLinkedError{
 					code:        linkTicket.Code,
-					botUsername: p.appBot.dg.State.User.Username,
+					botUsername: p.discordCache.BotUsername(),
 				}
 			}
 		}
@@ -523,16 +523,23 @@ func (p *EvrPipeline) authorizeSession(ctx context.Context, logger *zap.Logger,
 		metricsTags["error"] = "failed_check_suspensions"
 		return fmt.Errorf("failed to check suspensions: %w", err)
 	}
+	params.suspensionAppeals = journals.Appeals()
 
 	metricsTags["error"] = "nil"
 
-	SendEvent(ctx, p.nk, &EventUserAuthenticated{
+	event := &EventUserAuthenticated{
 		UserID:                   params.profile.ID(),
 		XPID:                     params.xpID,
 		ClientIP:                 session.clientIP,
 		LoginPayload:             params.loginPayload,
 		IsWebSocketAuthenticated: params.IsWebsocketAuthenticated,
-	})
+	}
+	if params.ipInfo != nil {
+		event.ASN = params.ipInfo.ASN()
+		event.IsVPN = params.ipInfo.IsVPN()
+	}
+	event.CarrierNAT = isCarrierGradeNAT(session.clientIP, params.ipInfo)
+	SendEvent(ctx, p.nk, event)
 
 	return nil
 }
@@ -649,10 +656,14 @@ func (p *EvrPipeline) initializeSession(ctx context.Context, logger *zap.Logger,
 		}
 
 		if !groupIGN.IsOverride && !groupIGN.IsLocked {
-			// Update the in-game name for the guild.
-			if member, err := p.discordCache.GuildMember(gg.GuildID, params.profile.DiscordID()); err != nil {
-				logger.Warn("Failed to get guild member", zap.String("guild_id", gg.GuildID), zap.String("discord_id", params.profile.DiscordID()), zap.Error(err))
-			} else if memberNick := InGameName(member); memberNick != "" {
+			// Update the in-game name for the guild, from the member's name in the community.
+			if memberNick, err := p.guildGroupRegistry.CommunityInGameName(ctx, gg, params.profile.ID()); err != nil {
+				if IsCommunityUnavailable(err) {
+					logger.Debug("In-game name is not synced from the community", zap.String("guild_id", gg.GuildID), zap.Error(err))
+				} else {
+					logger.Warn("Failed to get community in-game name", zap.String("guild_id", gg.GuildID), zap.Error(err))
+				}
+			} else if memberNick != "" {
 				// If the member is found, use it as their in-game name.
 				groupIGN.DisplayName = memberNick
 			} else if memberNick == "" {
@@ -681,7 +692,7 @@ func (p *EvrPipeline) initializeSession(ctx context.Context, logger *zap.Logger,
 					if strings.EqualFold(gn, dn) {
 						// This display name is owned by someone else.
 						params.profile.DeleteGroupDisplayName(gID)
-						if serviceSettings.DisplayNameInUseNotifications {
+						if serviceSettings.DisplayNameInUseNotifications && p.discordCache.Enabled() {
 							// Notify the player that this display name is in use.
 							ownerDiscordID := p.discordCache.UserIDToDiscordID(ownerIDs[0])
 							go func() {
@@ -888,6 +899,12 @@ func (p *EvrPipeline) loggedInUserProfileRequest(ctx context.Context, logger *za
 		return fmt.Errorf("failed to get server profile: %w", err)
 	}
 
+	if ranks, err := RankedLadderServerRanks(ctx, p.nk, userID, groupID); err != nil {
+		logger.Warn("Failed to load ranked ladder standings", zap.Error(err))
+	} else {
+		serverProfile.Ranks = ranks
+	}
+
 	p.profileCache.Store(session.id, *serverProfile)
 
 	clientProfile := NewClientProfile(ctx, params.profile, serverProfile)
@@ -1003,106 +1020,57 @@ func (p *EvrPipeline) documentRequest(ctx context.Context, logger *zap.Logger, s
 		return errors.New("session parameters not found")
 	}
 
-	var document evr.Document
-	var err error
-	switch request.Type {
-	case "eula":
-
-		if !params.IsVR() {
-
-			eulaVersion := params.profile.LegalConsents.EulaVersion
-			gaVersion := params.profile.LegalConsents.GameAdminVersion
-			document = evr.NewEULADocument(int(eulaVersion), int(gaVersion), request.Language, "https://github.com/EchoTools", "Blank EULA for NoVR clients. You should only see this once.")
-			return session.SendEvrUnrequire(evr.NewDocumentSuccess(document))
-		}
-
-		key := "eula:vr:" + request.Language
-		message := p.MessageCacheLoad(key)
-
-		if message == nil {
-			document, err = p.generateEULA(ctx, logger, request.Language)
-			if err != nil {
-				return fmt.Errorf("failed to get eula document: %w", err)
-			}
-			message = evr.NewDocumentSuccess(document)
-			p.MessageCacheStore(key, message, time.Minute*1)
-		}
-
-		return session.SendEvrUnrequire(message)
+	_type, page, err := ParseDocumentType(request.Type)
+	if err != nil {
+		return fmt.Errorf("unknown document: %s,%s: %w", request.Language, request.Type, err)
+	}
 
-	default:
-		return fmt.Errorf("unknown document: %s,%s", request.Language, request.Type)
+	if _type == DocumentTypeEULA && !params.IsVR() {
+		eulaVersion := params.profile.LegalConsents.EulaVersion
+		gaVersion := params.profile.LegalConsents.GameAdminVersion
+		document := evr.NewEULADocument(int(eulaVersion), int(gaVersion), request.Language, "https://github.com/EchoTools", "Blank EULA for NoVR clients. You should only see this once.")
+		return session.SendEvrUnrequire(evr.NewDocumentSuccess(document))
 	}
-}
 
-func (p *EvrPipeline) generateEULA(ctx context.Context, logger *zap.Logger, language string) (evr.EULADocument, error) {
-	// Retrieve the contents from storage
-	key := fmt.Sprintf("eula,%s", language)
-	document := evr.DefaultEULADocument(language)
-
-	var ts time.Time
-
-	if objs, err := p.nk.StorageRead(ctx, []*runtime.StorageRead{{
-		Collection: DocumentStorageCollection,
-		Key:        key,
-		UserID:     SystemUserID,
-	}}); err != nil {
-		return document, fmt.Errorf("failed to read EULA: %w", err)
-	} else if len(objs) > 0 {
-		if err := json.Unmarshal([]byte(objs[0].Value), &document); err != nil {
-			return document, fmt.Errorf("failed to unmarshal EULA: %w", err)
-		}
-		ts = objs[0].UpdateTime.AsTime().UTC()
-	} else {
-		// If the document doesn't exist, store the object
-		jsonBytes, err := json.Marshal(document)
-		if err != nil {
-			return document, fmt.Errorf("failed to marshal EULA: %w", err)
-		}
+	key := fmt.Sprintf("document:%s:%s:%d", _type, request.Language, page)
+	message := p.MessageCacheLoad(key)
 
-		if _, err = p.nk.StorageWrite(ctx, []*runtime.StorageWrite{{
-			Collection:      DocumentStorageCollection,
-			Key:             key,
-			Value:           string(jsonBytes),
-			PermissionRead:  0,
-			PermissionWrite: 0,
-		}}); err != nil {
-			return document, fmt.Errorf("failed to write EULA: %w", err)
+	if message == nil {
+		document, err := p.loadDocument(ctx, logger, _type, request.Language, page)
+		if err != nil {
+			return fmt.Errorf("failed to get %s document: %w", _type, err)
 		}
-		ts = time.Now().UTC()
+		message = evr.NewDocumentSuccess(document)
+		p.MessageCacheStore(key, message, time.Minute*1)
 	}
 
-	msg := document.Text
-	maxLineCount := 7
-	maxLineLength := 28
-	// Split the message by newlines
-
-	// trim the final newline
-	msg = strings.TrimRight(msg, "\n")
+	return session.SendEvrUnrequire(message)
+}
 
-	// Limit the EULA to 7 lines, and add '...' to the end of any line that is too long.
-	lines := strings.Split(msg, "\n")
-	if len(lines) > maxLineCount {
-		logger.Warn("EULA too long", zap.Int("lineCount", len(lines)))
-		lines = lines[:maxLineCount]
-		lines = append(lines, "...")
+// loadDocument returns the page of the document, in the client's language or the nearest fallback.
+func (p *EvrPipeline) loadDocument(ctx context.Context, logger *zap.Logger, _type, language string, page int) (evr.Document, error) {
+	document, version, err := GameDocumentRead(ctx, p.nk, _type, DocumentLanguageChain(language))
+	if err != nil {
+		return nil, err
 	}
 
-	// Cut lines at 18 characters
-	for i, line := range lines {
-		if len(line) > maxLineLength {
-			logger.Warn("EULA line too long", zap.String("line", line), zap.Int("length", len(line)))
-			lines[i] = line[:maxLineLength-3] + "..."
+	if version == "*" && _type == DocumentTypeEULA {
+		// Store the default EULA, so that it can be edited, and so that its version is stable.
+		document.Lang = DocumentDefaultLanguage
+		document.Version = time.Now().UTC().Unix()
+		document.VersionGameAdmin = document.Version
+		if err := GameDocumentWrite(ctx, p.nk, document, version); err != nil {
+			logger.Warn("Failed to store the default EULA", zap.Error(err))
 		}
 	}
 
-	msg = strings.Join(lines, "\n") + "\n"
-
-	document.Version = ts.Unix()
-	document.VersionGameAdmin = ts.Unix()
+	if document.Lang != language {
+		logger.Debug("Serving document in fallback language", zap.String("type", _type), zap.String("requested", language), zap.String("lang", document.Lang))
+	}
+	// The client expects the language it asked for.
+	document.Lang = language
 
-	document.Text = msg
-	return document, nil
+	return document.EVRDocument(page)
 }
 
 func (p *EvrPipeline) genericMessage(ctx context.Context, logger *zap.Logger, session *sessionWS, in evr.Message) error {
@@ -0,0 +1,479 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RankedLadderStorageCollection = "RankedLadder"
+	RankedLadderStatisticID       = "RankedLadder" // Suffixed with the season start, e.g. RankedLadder20260101.
+
+	rankedLadderWriteAttempts  = 3
+	rankedLadderSeasonsHistory = 10
+)
+
+// RankedLadderSettings configures the visible ranked ladder. Ranks follow the team rating ordinal, but only
+// change through promotion and demotion series, so that a player's rank doesn't flicker with every match.
+type RankedLadderSettings struct {
+	Enabled          bool                 `json:"enabled"`
+	Modes            []evr.Symbol         `json:"modes,omitempty"`              // The ranked modes, of the rated modes (default public arena)
+	PlacementMatches int                  `json:"placement_matches,omitempty"`  // Matches played each season before a player is placed (default 5)
+	DivisionsPerTier int                  `json:"divisions_per_tier,omitempty"` // Divisions in each tier below master (default 3)
+	TierThresholds   map[Division]float64 `json:"tier_thresholds,omitempty"`    // The ordinal at which each tier starts, from bronze to master; bronze is the floor of its divisions
+	SeriesLength     int                  `json:"series_length,omitempty"`      // Matches in a promotion or demotion series (default 3); a majority decides it
+	DemotionMargin   float64              `json:"demotion_margin,omitempty"`    // How far below its division's threshold the ordinal must fall before a demotion series (default 1)
+}
+
+var defaultRankedLadderTierThresholds = map[Division]float64{
+	DivisionBronze:   0,
+	DivisionSilver:   5,
+	DivisionGold:     10,
+	DivisionPlatinum: 15,
+	DivisionDiamond:  20,
+	DivisionMaster:   25,
+}
+
+// rankedLadderRatedModes are the modes whose matches are rated, and so can be ranked.
+var rankedLadderRatedModes = []evr.Symbol{evr.ModeArenaPublic}
+
+// rankedLadderTiers are the tiers of the ladder, lowest first. Green is only used by the matchmaker.
+var rankedLadderTiers = []Division{DivisionBronze, DivisionSilver, DivisionGold, DivisionPlatinum, DivisionDiamond, DivisionMaster}
+
+func (s RankedLadderSettings) WithDefaults() RankedLadderSettings {
+	s.Modes = slices.DeleteFunc(slices.Clone(s.Modes), func(m evr.Symbol) bool {
+		return !slices.Contains(rankedLadderRatedModes, m)
+	})
+	if len(s.Modes) == 0 {
+		s.Modes = slices.Clone(rankedLadderRatedModes)
+	}
+	if s.PlacementMatches <= 0 {
+		s.PlacementMatches = 5
+	}
+	if s.DivisionsPerTier <= 0 || s.DivisionsPerTier > 5 {
+		s.DivisionsPerTier = 3
+	}
+	if s.SeriesLength <= 0 || s.SeriesLength%2 == 0 {
+		s.SeriesLength = 3
+	}
+	if s.DemotionMargin < 0 {
+		s.DemotionMargin = 0
+	} else if s.DemotionMargin == 0 {
+		s.DemotionMargin = 1
+	}
+
+	thresholds := make(map[Division]float64, len(rankedLadderTiers))
+	for _, t := range rankedLadderTiers {
+		v, ok := s.TierThresholds[t]
+		if !ok {
+			v = defaultRankedLadderTierThresholds[t]
+		}
+		thresholds[t] = v
+	}
+	// Thresholds that don't ascend can't be ranked against.
+	for i := 1; i < len(rankedLadderTiers); i++ {
+		if thresholds[rankedLadderTiers[i]] <= thresholds[rankedLadderTiers[i-1]] {
+			thresholds = defaultRankedLadderTierThresholds
+			break
+		}
+	}
+	s.TierThresholds = thresholds
+	return s
+}
+
+func (s RankedLadderSettings) Applies(mode evr.Symbol) bool {
+	return s.Enabled && slices.Contains(s.WithDefaults().Modes, mode)
+}
+
+// RankedLadderSettingsGet returns the ladder settings, with defaults applied.
+func RankedLadderSettingsGet() RankedLadderSettings {
+	var s RankedLadderSettings
+	if settings := ServiceSettings(); settings != nil {
+		s = settings.SkillRating.Ladder
+	}
+	return s.WithDefaults()
+}
+
+// LadderRank is a tier, and a division within it; 1 is the highest division. Master has no divisions.
+type LadderRank struct {
+	Tier     Division `json:"tier"`
+	Division int      `json:"division,omitempty"`
+}
+
+var romanNumerals = []string{"", "I", "II", "III", "IV", "V"}
+
+func (r LadderRank) String() string {
+	name := strings.ToUpper(r.Tier.String()[:1]) + r.Tier.String()[1:]
+	if r.Division > 0 && r.Division < len(romanNumerals) {
+		name += " " + romanNumerals[r.Division]
+	}
+	return name
+}
+
+// LadderRanks returns every rank of the ladder, lowest first.
+func (s RankedLadderSettings) LadderRanks() []LadderRank {
+	ranks := make([]LadderRank, 0, (len(rankedLadderTiers)-1)*s.DivisionsPerTier+1)
+	for _, t := range rankedLadderTiers {
+		if t == DivisionMaster {
+			ranks = append(ranks, LadderRank{Tier: t})
+			continue
+		}
+		for d := s.DivisionsPerTier; d >= 1; d-- {
+			ranks = append(ranks, LadderRank{Tier: t, Division: d})
+		}
+	}
+	return ranks
+}
+
+// RankIndex returns the position of the rank on the ladder, from 0 for the lowest.
+func (s RankedLadderSettings) RankIndex(r LadderRank) int {
+	return max(slices.Index(s.LadderRanks(), r), 0)
+}
+
+// Threshold returns the ordinal at which the rank starts. The divisions of a tier split it evenly.
+func (s RankedLadderSettings) Threshold(r LadderRank) float64 {
+	if r.Tier == DivisionMaster {
+		return s.TierThresholds[DivisionMaster]
+	}
+	i := slices.Index(rankedLadderTiers, r.Tier)
+	if i < 0 {
+		return s.TierThresholds[DivisionBronze]
+	}
+	lower, upper := s.TierThresholds[r.Tier], s.TierThresholds[rankedLadderTiers[i+1]]
+	return lower + float64(s.DivisionsPerTier-r.Division)*(upper-lower)/float64(s.DivisionsPerTier)
+}
+
+// RankForOrdinal returns the highest rank whose threshold the ordinal reaches, or the lowest rank.
+func (s RankedLadderSettings) RankForOrdinal(ordinal float64) LadderRank {
+	ranks := s.LadderRanks()
+	for i := len(ranks) - 1; i > 0; i-- {
+		if ordinal >= s.Threshold(ranks[i]) {
+			return ranks[i]
+		}
+	}
+	return ranks[0]
+}
+
+// RankedLadderBoardStatName returns the stat name of the ladder leaderboard of a season.
+func RankedLadderBoardStatName(season time.Time) string {
+	if season.IsZero() {
+		return RankedLadderStatisticID
+	}
+	return RankedLadderStatisticID + season.UTC().Format("20060102")
+}
+
+// RankedLadderSeason returns the start of the current season, as scheduled for the skill rating soft resets.
+// It is the zero time if there are no seasons.
+func RankedLadderSeason(now time.Time) time.Time {
+	settings := ServiceSettings()
+	if settings == nil {
+		return time.Time{}
+	}
+	season, err := lastSeasonStart(settings.SkillRating.Season, now)
+	if err != nil {
+		return time.Time{}
+	}
+	return season
+}
+
+type RankedLadderChange string
+
+const (
+	RankedLadderNoChange        RankedLadderChange = ""
+	RankedLadderPlaced          RankedLadderChange = "placed"
+	RankedLadderPromotionSeries RankedLadderChange = "promotion_series"
+	RankedLadderDemotionSeries  RankedLadderChange = "demotion_series"
+	RankedLadderPromoted        RankedLadderChange = "promoted"
+	RankedLadderDemoted         RankedLadderChange = "demoted"
+	RankedLadderSeriesFailed    RankedLadderChange = "series_failed"   // The promotion series was lost.
+	RankedLadderSeriesSurvived  RankedLadderChange = "series_survived" // The demotion series was won.
+)
+
+// RankedLadderSeries is a promotion or demotion series in progress.
+type RankedLadderSeries struct {
+	Promotion bool       `json:"promotion"`
+	Target    LadderRank `json:"target"` // The rank if the series succeeds (for a promotion) or fails (for a demotion).
+	Wins      int        `json:"wins"`
+	Losses    int        `json:"losses"`
+}
+
+type RankedLadderSeasonResult struct {
+	Season time.Time  `json:"season"`
+	Rank   LadderRank `json:"rank"`
+	Peak   LadderRank `json:"peak"`
+}
+
+// RankedLadderState is a player's standing on the ladder of a guild and mode.
+type RankedLadderState struct {
+	GroupID          string                     `json:"group_id"`
+	Mode             evr.Symbol                 `json:"mode"`
+	Season           time.Time                  `json:"season"`
+	Placed           bool                       `json:"placed"`
+	PlacementMatches int                        `json:"placement_matches"` // Played this season.
+	PlacementWins    int                        `json:"placement_wins"`
+	Rank             LadderRank                 `json:"rank"`
+	Peak             LadderRank                 `json:"peak"`
+	Series           *RankedLadderSeries        `json:"series,omitempty"`
+	Wins             int                        `json:"wins"`   // This season.
+	Losses           int                        `json:"losses"` // This season.
+	Ordinal          float64                    `json:"ordinal"`
+	PreviousSeasons  []RankedLadderSeasonResult `json:"previous_seasons,omitempty"` // Most recent first.
+	UpdateTime       time.Time                  `json:"update_time"`
+	version          string
+}
+
+func NewRankedLadderState(groupID string, mode evr.Symbol) *RankedLadderState {
+	return &RankedLadderState{
+		GroupID: groupID,
+		Mode:    mode,
+	}
+}
+
+func (s *RankedLadderState) StorageMeta() StorableMetadata {
+	return StorableMetadata{
+		Collection:      RankedLadderStorageCollection,
+		Key:             s.GroupID + ":" + s.Mode.HexString(),
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		Version:         s.version,
+	}
+}
+
+func (s *RankedLadderState) SetStorageMeta(meta StorableMetadata) {
+	s.version = meta.Version
+}
+
+// RankName returns the player's rank, or "Unranked" until they are placed.
+func (s *RankedLadderState) RankName() string {
+	if !s.Placed {
+		return "Unranked"
+	}
+	return s.Rank.String()
+}
+
+// ServerRank returns the standing as it is sent to the game client in the server profile.
+func (s *RankedLadderState) ServerRank(settings RankedLadderSettings) evr.ServerRank {
+	r := evr.ServerRank{Rank: s.RankName()}
+	if !s.Placed {
+		r.PlacementsRemaining = max(settings.PlacementMatches-s.PlacementMatches, 0)
+		return r
+	}
+	r.Tier = s.Rank.Tier.String()
+	r.Division = s.Rank.Division
+	if s.Series != nil {
+		r.Series = "demotion"
+		if s.Series.Promotion {
+			r.Series = "promotion"
+		}
+		r.SeriesWins = s.Series.Wins
+		r.SeriesLosses = s.Series.Losses
+	}
+	return r
+}
+
+// RankedLadderServerRanks returns the player's standings in the ranked modes, keyed by mode, for the server profile.
+func RankedLadderServerRanks(ctx context.Context, nk runtime.NakamaModule, userID, groupID string) (map[string]evr.ServerRank, error) {
+	settings := RankedLadderSettingsGet()
+	if !settings.Enabled {
+		return nil, nil
+	}
+	states, err := RankedLadderLoad(ctx, nk, userID, groupID, settings.Modes, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]evr.ServerRank, len(states))
+	for _, state := range states {
+		ranks[state.Mode.String()] = state.ServerRank(settings)
+	}
+	return ranks, nil
+}
+
+// StartSeason moves the player to the season. A new season requires new placement matches; the rank of the
+// last season is kept in the history.
+func (s *RankedLadderState) StartSeason(season time.Time) {
+	if s.Season.Equal(season) {
+		return
+	}
+	if s.Placed {
+		s.PreviousSeasons = append([]RankedLadderSeasonResult{{Season: s.Season, Rank: s.Rank, Peak: s.Peak}}, s.PreviousSeasons...)
+		if len(s.PreviousSeasons) > rankedLadderSeasonsHistory {
+			s.PreviousSeasons = s.PreviousSeasons[:rankedLadderSeasonsHistory]
+		}
+	}
+	s.Season = season
+	s.Placed = false
+	s.PlacementMatches = 0
+	s.PlacementWins = 0
+	s.Series = nil
+	s.Wins = 0
+	s.Losses = 0
+}
+
+// Record applies the result of a ranked match, with the player's ordinal after it.
+func (s *RankedLadderState) Record(settings RankedLadderSettings, season time.Time, won bool, ordinal float64, now time.Time) RankedLadderChange {
+	s.StartSeason(season)
+	s.Ordinal = ordinal
+	s.UpdateTime = now
+	if won {
+		s.Wins++
+	} else {
+		s.Losses++
+	}
+
+	if !s.Placed {
+		s.PlacementMatches++
+		if won {
+			s.PlacementWins++
+		}
+		if s.PlacementMatches < settings.PlacementMatches {
+			return RankedLadderNoChange
+		}
+		s.Placed = true
+		s.Rank = settings.RankForOrdinal(ordinal)
+		s.Peak = s.Rank
+		return RankedLadderPlaced
+	}
+
+	if s.Series != nil {
+		return s.recordSeries(settings, won)
+	}
+
+	ranks := settings.LadderRanks()
+	i := settings.RankIndex(s.Rank)
+	switch {
+	case i < len(ranks)-1 && ordinal >= settings.Threshold(ranks[i+1]):
+		s.Series = &RankedLadderSeries{Promotion: true, Target: ranks[i+1]}
+		return RankedLadderPromotionSeries
+	case i > 0 && ordinal < settings.Threshold(s.Rank)-settings.DemotionMargin:
+		s.Series = &RankedLadderSeries{Promotion: false, Target: ranks[i-1]}
+		return RankedLadderDemotionSeries
+	}
+	return RankedLadderNoChange
+}
+
+func (s *RankedLadderState) recordSeries(settings RankedLadderSettings, won bool) RankedLadderChange {
+	if won {
+		s.Series.Wins++
+	} else {
+		s.Series.Losses++
+	}
+	needed := settings.SeriesLength/2 + 1
+
+	if s.Series.Promotion {
+		switch {
+		case s.Series.Wins >= needed:
+			s.Rank = s.Series.Target
+			if settings.RankIndex(s.Rank) > settings.RankIndex(s.Peak) {
+				s.Peak = s.Rank
+			}
+			s.Series = nil
+			return RankedLadderPromoted
+		case s.Series.Losses >= needed:
+			s.Series = nil
+			return RankedLadderSeriesFailed
+		}
+		return RankedLadderNoChange
+	}
+
+	switch {
+	case s.Series.Losses >= needed:
+		s.Rank = s.Series.Target
+		s.Series = nil
+		return RankedLadderDemoted
+	case s.Series.Wins >= needed:
+		s.Series = nil
+		return RankedLadderSeriesSurvived
+	}
+	return RankedLadderNoChange
+}
+
+// RankedLadderLoad reads the player's standings in the modes, as of the current season.
+func RankedLadderLoad(ctx context.Context, nk runtime.NakamaModule, userID, groupID string, modes []evr.Symbol, now time.Time) ([]*RankedLadderState, error) {
+	season := RankedLadderSeason(now)
+	states := make([]*RankedLadderState, 0, len(modes))
+	for _, mode := range modes {
+		state := NewRankedLadderState(groupID, mode)
+		if err := StorableRead(ctx, nk, userID, state, false); err != nil && status.Code(err) != codes.NotFound {
+			return nil, fmt.Errorf("failed to read ranked ladder state: %w", err)
+		}
+		state.StartSeason(season)
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// RankedLadderRecordMatch records a ranked match result for the player. The state is written with its version,
+// so results from concurrent matches are retried against each other.
+func RankedLadderRecordMatch(ctx context.Context, nk runtime.NakamaModule, settings RankedLadderSettings, userID, groupID string, mode evr.Symbol, won bool, ordinal float64, now time.Time) (*RankedLadderState, RankedLadderChange, error) {
+	season := RankedLadderSeason(now)
+	var err error
+	for range rankedLadderWriteAttempts {
+		state := NewRankedLadderState(groupID, mode)
+		if err = StorableRead(ctx, nk, userID, state, true); err != nil {
+			continue
+		}
+		change := state.Record(settings, season, won, ordinal, now)
+		if err = StorableWrite(ctx, nk, userID, state); err == nil {
+			return state, change, nil
+		}
+	}
+	return nil, RankedLadderNoChange, fmt.Errorf("failed to write ranked ladder state: %w", err)
+}
+
+// RankedLadderEntry returns the entry of the player on the season's ladder leaderboard, ranked by rank and then ordinal.
+func RankedLadderEntry(settings RankedLadderSettings, state *RankedLadderState, playerInfo *PlayerInfo) (*StatisticsQueueEntry, error) {
+	// The ordinal only breaks ties within a rank, so it is squashed into the fraction.
+	tiebreak := min(max(state.Ordinal+100, 0), 999) / 1000
+	score, subscore, err := Float64ToScore(float64(settings.RankIndex(state.Rank)+1) + tiebreak)
+	if err != nil {
+		return nil, err
+	}
+	return &StatisticsQueueEntry{
+		BoardMeta: LeaderboardMeta{
+			GroupID:       state.GroupID,
+			Mode:          state.Mode,
+			StatName:      RankedLadderBoardStatName(state.Season),
+			Operator:      OperatorSet,
+			ResetSchedule: evr.ResetScheduleAllTime,
+		},
+		UserID:      playerInfo.UserID,
+		DisplayName: playerInfo.DisplayName,
+		Score:       score,
+		Subscore:    subscore,
+		Metadata:    map[string]string{"rank": state.Rank.String(), "discord_id": playerInfo.DiscordID},
+	}, nil
+}
+
+// rankedLadderUpdate records the match on the player's ladder, and returns their ladder leaderboard entry once they are placed.
+func rankedLadderUpdate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, settings RankedLadderSettings, groupID string, mode evr.Symbol, playerInfo *PlayerInfo, won bool, teamRating types.Rating) *StatisticsQueueEntry {
+	state, change, err := RankedLadderRecordMatch(ctx, nk, settings, playerInfo.UserID, groupID, mode, won, rating.Ordinal(teamRating), time.Now().UTC())
+	if err != nil {
+		logger.WithField("error", err).Warn("Failed to update the ranked ladder")
+		return nil
+	}
+	if change != RankedLadderNoChange {
+		logger.WithFields(map[string]any{
+			"change": string(change),
+			"rank":   state.RankName(),
+		}).Debug("Ranked ladder changed")
+	}
+	if !state.Placed {
+		return nil
+	}
+	entry, err := RankedLadderEntry(settings, state, playerInfo)
+	if err != nil {
+		logger.WithField("error", err).Warn("Failed to convert the ranked ladder rank to a score")
+		return nil
+	}
+	return entry
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRankedLadderSettings() RankedLadderSettings {
+	return RankedLadderSettings{Enabled: true, PlacementMatches: 3}.WithDefaults()
+}
+
+func TestRankedLadderSettings_Thresholds(t *testing.T) {
+	s := testRankedLadderSettings()
+
+	ranks := s.LadderRanks()
+	require.Len(t, ranks, 5*3+1)
+	assert.Equal(t, LadderRank{Tier: DivisionBronze, Division: 3}, ranks[0])
+	assert.Equal(t, LadderRank{Tier: DivisionMaster}, ranks[len(ranks)-1])
+
+	assert.InDelta(t, 10, s.Threshold(LadderRank{Tier: DivisionGold, Division: 3}), 1e-9)
+	assert.InDelta(t, 10+5.0/3, s.Threshold(LadderRank{Tier: DivisionGold, Division: 2}), 1e-9)
+	assert.InDelta(t, 25, s.Threshold(LadderRank{Tier: DivisionMaster}), 1e-9)
+
+	assert.Equal(t, LadderRank{Tier: DivisionBronze, Division: 3}, s.RankForOrdinal(-20))
+	assert.Equal(t, LadderRank{Tier: DivisionGold, Division: 1}, s.RankForOrdinal(14.9))
+	assert.Equal(t, LadderRank{Tier: DivisionMaster}, s.RankForOrdinal(40))
+
+	assert.Equal(t, "Gold II", LadderRank{Tier: DivisionGold, Division: 2}.String())
+	assert.Equal(t, "Master", LadderRank{Tier: DivisionMaster}.String())
+
+	// Thresholds that don't ascend fall back to the defaults.
+	bad := RankedLadderSettings{TierThresholds: map[Division]float64{DivisionSilver: 30}}.WithDefaults()
+	assert.Equal(t, defaultRankedLadderTierThresholds, bad.TierThresholds)
+}
+
+func TestRankedLadderSettings_Modes(t *testing.T) {
+	// Only rated modes can be ranked.
+	s := RankedLadderSettings{Enabled: true, Modes: []evr.Symbol{evr.ModeCombatPublic, evr.ModeArenaPublic}}.WithDefaults()
+	assert.Equal(t, []evr.Symbol{evr.ModeArenaPublic}, s.Modes)
+	assert.False(t, s.Applies(evr.ModeCombatPublic))
+
+	s = RankedLadderSettings{Enabled: true, Modes: []evr.Symbol{evr.ModeCombatPublic}}.WithDefaults()
+	assert.Equal(t, []evr.Symbol{evr.ModeArenaPublic}, s.Modes)
+}
+
+func TestRankedLadderState_Placement(t *testing.T) {
+	s := testRankedLadderSettings()
+	now := time.Now().UTC()
+	state := NewRankedLadderState("g", evr.ModeArenaPublic)
+
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, true, 11, now))
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, false, 10.5, now))
+	assert.False(t, state.Placed)
+	assert.Equal(t, "Unranked", state.RankName())
+	assert.Equal(t, 1, state.ServerRank(s).PlacementsRemaining)
+
+	assert.Equal(t, RankedLadderPlaced, state.Record(s, time.Time{}, true, 11, now))
+	assert.True(t, state.Placed)
+	assert.Equal(t, LadderRank{Tier: DivisionGold, Division: 3}, state.Rank)
+	assert.Equal(t, state.Rank, state.Peak)
+	assert.Equal(t, 2, state.PlacementWins)
+}
+
+func TestRankedLadderState_Series(t *testing.T) {
+	s := testRankedLadderSettings()
+	now := time.Now().UTC()
+	gold3 := LadderRank{Tier: DivisionGold, Division: 3}
+	gold2 := LadderRank{Tier: DivisionGold, Division: 2}
+	silver1 := LadderRank{Tier: DivisionSilver, Division: 1}
+	state := &RankedLadderState{Placed: true, Rank: gold3, Peak: gold3}
+
+	// Within the division, and within the demotion margin below it, nothing changes.
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, true, 11, now))
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, false, 9.1, now))
+
+	// Reaching the next division starts a promotion series, which is won on a majority of the following matches.
+	assert.Equal(t, RankedLadderPromotionSeries, state.Record(s, time.Time{}, true, 11.8, now))
+	assert.Equal(t, "promotion", state.ServerRank(s).Series)
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, false, 11.5, now))
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, true, 11.9, now))
+	assert.Equal(t, RankedLadderPromoted, state.Record(s, time.Time{}, true, 12.1, now))
+	assert.Equal(t, gold2, state.Rank)
+	assert.Equal(t, gold2, state.Peak)
+	assert.Nil(t, state.Series)
+
+	// A failed promotion keeps the rank.
+	state.Rank = gold3
+	assert.Equal(t, RankedLadderPromotionSeries, state.Record(s, time.Time{}, true, 11.8, now))
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, false, 11, now))
+	assert.Equal(t, RankedLadderSeriesFailed, state.Record(s, time.Time{}, false, 10.5, now))
+	assert.Equal(t, gold3, state.Rank)
+
+	// Falling below the margin starts a demotion series, which can be survived or lost.
+	assert.Equal(t, RankedLadderDemotionSeries, state.Record(s, time.Time{}, false, 8.9, now))
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, true, 9, now))
+	assert.Equal(t, RankedLadderSeriesSurvived, state.Record(s, time.Time{}, true, 9.2, now))
+	assert.Equal(t, gold3, state.Rank)
+
+	assert.Equal(t, RankedLadderDemotionSeries, state.Record(s, time.Time{}, false, 8.5, now))
+	assert.Equal(t, "demotion", state.ServerRank(s).Series)
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, time.Time{}, false, 8, now))
+	assert.Equal(t, RankedLadderDemoted, state.Record(s, time.Time{}, false, 7.5, now))
+	assert.Equal(t, silver1, state.Rank)
+	assert.Equal(t, gold2, state.Peak)
+}
+
+func TestRankedLadderState_SeasonReset(t *testing.T) {
+	s := testRankedLadderSettings()
+	now := time.Now().UTC()
+	season := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	gold3 := LadderRank{Tier: DivisionGold, Division: 3}
+	state := &RankedLadderState{Season: season, Placed: true, Rank: gold3, Peak: gold3, Wins: 10, Series: &RankedLadderSeries{Promotion: true}}
+
+	next := season.AddDate(0, 3, 0)
+	assert.Equal(t, RankedLadderNoChange, state.Record(s, next, true, 20, now))
+	assert.False(t, state.Placed)
+	assert.Nil(t, state.Series)
+	assert.Equal(t, 1, state.Wins)
+	assert.Equal(t, 1, state.PlacementMatches)
+	require.Len(t, state.PreviousSeasons, 1)
+	assert.Equal(t, RankedLadderSeasonResult{Season: season, Rank: gold3, Peak: gold3}, state.PreviousSeasons[0])
+
+	assert.Equal(t, "RankedLadder20260401", RankedLadderBoardStatName(next))
+	assert.Equal(t, "RankedLadder", RankedLadderBoardStatName(time.Time{}))
+}
+
+func TestRankedLadderState_JSONRoundTrip(t *testing.T) {
+	state := &RankedLadderState{
+		GroupID: "g",
+		Placed:  true,
+		Rank:    LadderRank{Tier: DivisionPlatinum, Division: 1},
+		Series:  &RankedLadderSeries{Promotion: true, Target: LadderRank{Tier: DivisionDiamond, Division: 3}, Wins: 1},
+	}
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"tier":"platinum"`)
+
+	var decoded RankedLadderState
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, state.Rank, decoded.Rank)
+	assert.Equal(t, state.Series, decoded.Series)
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"math"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+	"go.uber.org/thriftrw/ptr"
+)
+
+const (
+	RatingEngineOpenSkill = "openskill"
+	RatingEngineGlicko2   = "glicko2"
+	RatingEngineElo       = "elo"
+
+	// glicko2Scale converts between the Glicko and Glicko-2 scales.
+	glicko2Scale = 173.7178
+	// glickoDefaultRD is the Glicko rating deviation of an unrated player, which the default sigma maps to.
+	glickoDefaultRD = 350.0
+
+	defaultGlicko2Volatility = 0.06
+	defaultEloKFactor        = 32.0
+	// eloSigmaDecay shrinks the uncertainty of an Elo rating after each match, down to tau.
+	eloSigmaDecay = 0.95
+)
+
+// RatingEngine updates the ratings of the players of a match, each of whom is rated on their own.
+//
+// All engines keep ratings on the same Mu/Sigma scale (set by the rating defaults), so that the
+// stored ratings, the matchmaker and the leaderboards do not depend on which engine produced them.
+type RatingEngine interface {
+	Name() string
+	// Rate returns the new ratings, in the order given. A higher score is a better finish, and equal scores are a draw.
+	Rate(ratings []types.Rating, scores []int, config *SkillRatingSettings) []types.Rating
+}
+
+var ratingEngines = map[string]RatingEngine{
+	RatingEngineOpenSkill: openSkillEngine{},
+	RatingEngineGlicko2:   glicko2Engine{},
+	RatingEngineElo:       eloEngine{},
+}
+
+// RatingEngineByName returns the named engine, or nil if there is none.
+func RatingEngineByName(name string) RatingEngine {
+	return ratingEngines[name]
+}
+
+// RatingEngineFor returns the engine used for a guild and mode. The overrides in the settings are checked
+// from the most to the least specific ("<group_id>:<mode>", "<group_id>", "<mode>"), then the default engine.
+// OpenSkill is used if nothing (valid) is configured.
+func RatingEngineFor(config *SkillRatingSettings, groupID string, mode evr.Symbol) RatingEngine {
+	if config == nil {
+		return ratingEngines[RatingEngineOpenSkill]
+	}
+	keys := make([]string, 0, 3)
+	if groupID != "" && mode != 0 {
+		keys = append(keys, groupID+":"+mode.String())
+	}
+	if groupID != "" {
+		keys = append(keys, groupID)
+	}
+	if mode != 0 {
+		keys = append(keys, mode.String())
+	}
+	for _, k := range keys {
+		if e, ok := ratingEngines[config.Engines[k]]; ok {
+			return e
+		}
+	}
+	if e, ok := ratingEngines[config.Engine]; ok {
+		return e
+	}
+	return ratingEngines[RatingEngineOpenSkill]
+}
+
+// ratingOutcome is the result of a pairwise comparison: 1 for a win, 0.5 for a draw and 0 for a loss.
+func ratingOutcome(score, opponentScore int) float64 {
+	switch {
+	case score > opponentScore:
+		return 1
+	case score < opponentScore:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// ratingTau returns the dynamics factor, which keeps sigma from dropping too low.
+func ratingTau(config *SkillRatingSettings) float64 {
+	if config != nil && config.Defaults.Tau != 0 {
+		return config.Defaults.Tau
+	}
+	return 0.3
+}
+
+// openSkillEngine rates the match as a free-for-all with the Plackett-Luce model.
+type openSkillEngine struct{}
+
+func (openSkillEngine) Name() string { return RatingEngineOpenSkill }
+
+func (openSkillEngine) Rate(ratings []types.Rating, scores []int, config *SkillRatingSettings) []types.Rating {
+	teams := make([]types.Team, len(ratings))
+	for i, r := range ratings {
+		teams[i] = types.Team{r}
+	}
+
+	teams = rating.Rate(teams, &types.OpenSkillOptions{
+		Score: scores,
+		Tau:   ptr.Float64(ratingTau(config)),
+	})
+
+	updated := make([]types.Rating, len(teams))
+	for i, team := range teams {
+		updated[i] = team[0]
+	}
+	return updated
+}
+
+// glicko2Engine rates the match as a round robin of pairwise games, each weighted so that the match counts as a single game.
+// The volatility is not stored, so a constant volatility is used.
+type glicko2Engine struct{}
+
+func (glicko2Engine) Name() string { return RatingEngineGlicko2 }
+
+func (glicko2Engine) Rate(ratings []types.Rating, scores []int, config *SkillRatingSettings) []types.Rating {
+	defaults := GetRatingDefaults(config)
+	volatility := defaultGlicko2Volatility
+	if config != nil && config.Glicko2Volatility > 0 {
+		volatility = config.Glicko2Volatility
+	}
+
+	// The default sigma maps to the Glicko deviation of an unrated player.
+	perSigma := glickoDefaultRD / defaults.Sigma / glicko2Scale
+	toGlicko2 := func(r types.Rating) (mu, phi float64) {
+		return (r.Mu - defaults.Mu) * perSigma, r.Sigma * perSigma
+	}
+	g := func(phi float64) float64 {
+		return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+	}
+
+	updated := make([]types.Rating, len(ratings))
+	if len(ratings) < 2 {
+		copy(updated, ratings)
+		return updated
+	}
+	weight := 1 / float64(len(ratings)-1)
+
+	for i, r := range ratings {
+		mu, phi := toGlicko2(r)
+
+		var vInv, delta float64
+		for j, o := range ratings {
+			if i == j {
+				continue
+			}
+			muJ, phiJ := toGlicko2(o)
+			gJ := g(phiJ)
+			e := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+			vInv += weight * gJ * gJ * e * (1 - e)
+			delta += weight * gJ * (ratingOutcome(scores[i], scores[j]) - e)
+		}
+
+		phiStar := math.Sqrt(phi*phi + volatility*volatility)
+		newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+vInv)
+		newMu := mu + newPhi*newPhi*delta
+
+		updated[i] = types.Rating{
+			Z:     r.Z,
+			Mu:    defaults.Mu + newMu/perSigma,
+			Sigma: math.Min(newPhi/perSigma, defaults.Sigma),
+		}
+	}
+	return updated
+}
+
+// eloEngine rates the match as a round robin of pairwise games. The K-factor is scaled by the
+// player's uncertainty, which shrinks with each match, so that new players move faster.
+type eloEngine struct{}
+
+func (eloEngine) Name() string { return RatingEngineElo }
+
+func (eloEngine) Rate(ratings []types.Rating, scores []int, config *SkillRatingSettings) []types.Rating {
+	defaults := GetRatingDefaults(config)
+	k := defaultEloKFactor
+	if config != nil && config.EloKFactor > 0 {
+		k = config.EloKFactor
+	}
+
+	// The default sigma maps to the Glicko deviation of an unrated player, which is on the Elo scale.
+	perSigma := glickoDefaultRD / defaults.Sigma
+
+	updated := make([]types.Rating, len(ratings))
+	if len(ratings) < 2 {
+		copy(updated, ratings)
+		return updated
+	}
+	weight := 1 / float64(len(ratings)-1)
+
+	for i, r := range ratings {
+		delta := 0.0
+		for j, o := range ratings {
+			if i == j {
+				continue
+			}
+			e := 1 / (1 + math.Pow(10, (o.Mu-r.Mu)*perSigma/400))
+			delta += weight * (ratingOutcome(scores[i], scores[j]) - e)
+		}
+		kEff := k * math.Min(r.Sigma/defaults.Sigma, 1)
+
+		updated[i] = types.Rating{
+			Z:     r.Z,
+			Mu:    r.Mu + kEff*delta/perSigma,
+			Sigma: math.Max(r.Sigma*eloSigmaDecay, ratingTau(config)),
+		}
+	}
+	return updated
+}
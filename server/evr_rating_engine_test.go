@@ -0,0 +1,153 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/intinig/go-openskill/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRatingEngineFor(t *testing.T) {
+	arena := evr.ModeArenaPublic
+	config := &SkillRatingSettings{
+		Engine: RatingEngineElo,
+		Engines: map[string]string{
+			"group-a:" + arena.String():   RatingEngineGlicko2,
+			"group-b":                     RatingEngineOpenSkill,
+			evr.ModeCombatPublic.String(): RatingEngineGlicko2,
+			"group-c":                     "unknown",
+		},
+	}
+
+	assert.Equal(t, RatingEngineGlicko2, RatingEngineFor(config, "group-a", arena).Name())
+	assert.Equal(t, RatingEngineOpenSkill, RatingEngineFor(config, "group-b", evr.ModeCombatPublic).Name(), "the guild is more specific than the mode")
+	assert.Equal(t, RatingEngineGlicko2, RatingEngineFor(config, "group-d", evr.ModeCombatPublic).Name())
+	assert.Equal(t, RatingEngineElo, RatingEngineFor(config, "group-c", arena).Name(), "unknown engines fall back to the default")
+	assert.Equal(t, RatingEngineOpenSkill, RatingEngineFor(nil, "group-a", arena).Name())
+	assert.Equal(t, RatingEngineOpenSkill, RatingEngineFor(&SkillRatingSettings{}, "", 0).Name())
+}
+
+func TestRatingEngines_Rate(t *testing.T) {
+	config := &SkillRatingSettings{Defaults: RatingDefaults{Z: 3, Mu: 25, Sigma: 25.0 / 3, Tau: 0.3}}
+
+	for name := range ratingEngines {
+		t.Run(name, func(t *testing.T) {
+			engine := RatingEngineByName(name)
+			ratings := []types.Rating{
+				NewRatingFromConfig(0, 25, 25.0/3, config),
+				NewRatingFromConfig(0, 25, 25.0/3, config),
+				NewRatingFromConfig(0, 25, 25.0/3, config),
+			}
+			updated := engine.Rate(ratings, []int{10, 5, 5}, config)
+			require.Len(t, updated, 3)
+
+			assert.Greater(t, updated[0].Mu, 25.0, "the winner gains")
+			assert.Less(t, updated[1].Mu, 25.0, "the losers lose")
+			assert.InDelta(t, updated[1].Mu, updated[2].Mu, 1e-9, "equal scores are a draw")
+			for _, r := range updated {
+				assert.Less(t, r.Sigma, 25.0/3, "a match reduces the uncertainty")
+				assert.Greater(t, r.Sigma, 0.0)
+			}
+		})
+	}
+}
+
+func TestRatingEngines_Upset(t *testing.T) {
+	config := &SkillRatingSettings{Defaults: RatingDefaults{Z: 3, Mu: 25, Sigma: 25.0 / 3, Tau: 0.3}}
+
+	for _, name := range []string{RatingEngineGlicko2, RatingEngineElo} {
+		t.Run(name, func(t *testing.T) {
+			engine := RatingEngineByName(name)
+			strong := types.Rating{Z: 3, Mu: 35, Sigma: 3}
+			weak := types.Rating{Z: 3, Mu: 15, Sigma: 3}
+
+			expected := engine.Rate([]types.Rating{strong, weak}, []int{1, 0}, config)
+			upset := engine.Rate([]types.Rating{strong, weak}, []int{0, 1}, config)
+
+			assert.Less(t, expected[0].Mu-strong.Mu, upset[1].Mu-weak.Mu, "an upset moves the ratings more than the expected result")
+		})
+	}
+}
+
+func TestInflateRatingSigma(t *testing.T) {
+	defaults := RatingDefaults{Z: 3, Mu: 25, Sigma: 8}
+	settings := RatingInactivitySettings{GraceDays: 7, SigmaPerDay: 0.5}
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	// Within the grace period.
+	assert.Equal(t, 2.0, InflateRatingSigma(2, now.Add(-5*day), time.Time{}, now, settings, defaults))
+
+	// Three days past the grace period, not counted before.
+	assert.InDelta(t, 3.5, InflateRatingSigma(2, now.Add(-10*day), time.Time{}, now, settings, defaults), 1e-9)
+
+	// Only the day since the previous run is added.
+	assert.InDelta(t, 2.5, InflateRatingSigma(2, now.Add(-10*day), now.Add(-day), now, settings, defaults), 1e-9)
+
+	// Capped at the default sigma, or the configured maximum.
+	assert.Equal(t, 8.0, InflateRatingSigma(2, now.Add(-100*day), time.Time{}, now, settings, defaults))
+	settings.MaxSigma = 5
+	assert.Equal(t, 5.0, InflateRatingSigma(2, now.Add(-100*day), time.Time{}, now, settings, defaults))
+
+	// Disabled.
+	assert.Equal(t, 2.0, InflateRatingSigma(2, now.Add(-100*day), time.Time{}, now, RatingInactivitySettings{}, defaults))
+}
+
+func TestSoftResetRating(t *testing.T) {
+	defaults := RatingDefaults{Z: 3, Mu: 25, Sigma: 8}
+	settings := RatingSeasonSettings{MuRetention: 0.75, SigmaReset: 0.5}
+
+	r := SoftResetRating(types.Rating{Z: 3, Mu: 33, Sigma: 2}, settings, defaults)
+	assert.InDelta(t, 31, r.Mu, 1e-9)
+	assert.InDelta(t, 5, r.Sigma, 1e-9)
+
+	r = SoftResetRating(types.Rating{Z: 3, Mu: 17, Sigma: 9}, settings, defaults)
+	assert.InDelta(t, 19, r.Mu, 1e-9, "low ratings are pulled up")
+	assert.Equal(t, 9.0, r.Sigma, "sigma is not reduced")
+}
+
+func TestLastSeasonStart(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	start, err := lastSeasonStart(RatingSeasonSettings{ResetSchedule: "0 0 1 */3 *"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), start)
+
+	start, err = lastSeasonStart(RatingSeasonSettings{ResetSchedule: "0 0 1 */3 *"}, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), start, "a season starting now is included")
+
+	start, err = lastSeasonStart(RatingSeasonSettings{}, now)
+	require.NoError(t, err)
+	assert.True(t, start.IsZero())
+
+	_, err = lastSeasonStart(RatingSeasonSettings{ResetSchedule: "bad"}, now)
+	assert.Error(t, err)
+}
+
+func TestRatingMaintenanceState_Board(t *testing.T) {
+	run := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	state := &RatingMaintenanceState{
+		LastRun:         run,
+		LastSeasonReset: run.AddDate(0, -1, 0),
+		Boards:          map[string]*RatingBoardMaintenance{"a": {LastRun: run.Add(time.Hour), LastSeasonReset: run}},
+	}
+	assert.Equal(t, RatingBoardMaintenance{LastRun: run.Add(time.Hour), LastSeasonReset: run}, state.Board("a"))
+	assert.Equal(t, RatingBoardMaintenance{LastRun: run, LastSeasonReset: run.AddDate(0, -1, 0)}, state.Board("b"), "boards without progress are as far as every board")
+}
+
+func TestRatingLastRated(t *testing.T) {
+	rated := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	updated := rated.AddDate(0, 1, 0)
+
+	record := &api.LeaderboardRecord{UpdateTime: timestamppb.New(updated), Metadata: `{"discord_id":"1","rated_at":"2025-06-01T12:00:00Z"}`}
+	assert.Equal(t, rated, ratingLastRated(record), "a soft reset doesn't restart the inactivity")
+
+	record.Metadata = `{"discord_id":"1"}`
+	assert.Equal(t, updated, ratingLastRated(record))
+}
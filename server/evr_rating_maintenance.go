@@ -0,0 +1,426 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/internal/cronexpr"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/intinig/go-openskill/types"
+)
+
+const (
+	RatingMaintenanceStorageCollection = "SkillRating"
+	RatingMaintenanceStorageKey        = "maintenance"
+
+	ratingMaintenanceInterval = time.Hour
+	ratingMaintenancePageSize = 1000
+
+	// RatingRatedAtMetadataKey is the metadata of a mu record that holds when the player was last rated,
+	// since the record is also written by the maintenance job.
+	RatingRatedAtMetadataKey = "rated_at"
+)
+
+// ratingBoardPairs are the mu statistics of the rating leaderboards, with their sigma statistics.
+var ratingBoardPairs = map[string]string{
+	TeamSkillRatingMuStatisticID:   TeamSkillRatingSigmaStatisticID,
+	PlayerSkillRatingMuStatisticID: PlayerSkillRatingSigmaStatisticID,
+}
+
+// RatingMaintenanceState is the progress of the rating maintenance job, shared by the cluster.
+type RatingMaintenanceState struct {
+	LastRun         time.Time                          `json:"last_run"`                // When every board was last brought up to date
+	LastSeasonReset time.Time                          `json:"last_season_reset"`       // The season start that every board was last soft reset for
+	ClaimedUntil    time.Time                          `json:"claimed_until,omitempty"` // A node is running the job until then
+	Boards          map[string]*RatingBoardMaintenance `json:"boards,omitempty"`        // The progress of each mu leaderboard
+}
+
+// RatingBoardMaintenance is the progress of the rating maintenance job on one mu leaderboard.
+type RatingBoardMaintenance struct {
+	LastRun         time.Time `json:"last_run"`          // When inactivity inflation was last applied
+	LastSeasonReset time.Time `json:"last_season_reset"` // The season start that was last soft reset
+}
+
+// Board returns the progress of the mu leaderboard. Boards without progress of their own are as far as every board.
+func (s *RatingMaintenanceState) Board(boardID string) RatingBoardMaintenance {
+	if b, ok := s.Boards[boardID]; ok {
+		return *b
+	}
+	return RatingBoardMaintenance{LastRun: s.LastRun, LastSeasonReset: s.LastSeasonReset}
+}
+
+// ratingLastRated returns when the owner of the mu record was last rated by a match.
+// Records written before the time was kept use the record's update time.
+func ratingLastRated(record *api.LeaderboardRecord) time.Time {
+	if record.Metadata != "" {
+		var metadata map[string]any
+		if err := json.Unmarshal([]byte(record.Metadata), &metadata); err == nil {
+			if s, ok := metadata[RatingRatedAtMetadataKey].(string); ok {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					return t
+				}
+			}
+		}
+	}
+	return record.GetUpdateTime().AsTime()
+}
+
+// InflateRatingSigma returns the sigma of a player last active at lastActive, after inflating it for the
+// inactive days between the previous run (since) and now.
+func InflateRatingSigma(sigma float64, lastActive, since, now time.Time, settings RatingInactivitySettings, defaults RatingDefaults) float64 {
+	if settings.GraceDays <= 0 || settings.SigmaPerDay <= 0 {
+		return sigma
+	}
+	maxSigma := settings.MaxSigma
+	if maxSigma <= 0 {
+		maxSigma = defaults.Sigma
+	}
+	if sigma >= maxSigma {
+		return sigma
+	}
+
+	// Only the inactive days that have not been counted by a previous run.
+	from := lastActive.Add(time.Duration(settings.GraceDays) * 24 * time.Hour)
+	if since.After(from) {
+		from = since
+	}
+	if !now.After(from) {
+		return sigma
+	}
+	days := now.Sub(from).Hours() / 24
+	return math.Min(sigma+days*settings.SigmaPerDay, maxSigma)
+}
+
+// SoftResetRating pulls a rating towards the defaults for the start of a season.
+func SoftResetRating(r types.Rating, settings RatingSeasonSettings, defaults RatingDefaults) types.Rating {
+	r.Mu = defaults.Mu + (r.Mu-defaults.Mu)*settings.MuRetention
+	if r.Sigma < defaults.Sigma {
+		r.Sigma += (defaults.Sigma - r.Sigma) * settings.SigmaReset
+	}
+	return r
+}
+
+// lastSeasonStart returns the latest scheduled season start at or before now, or the zero time if there is no schedule.
+func lastSeasonStart(settings RatingSeasonSettings, now time.Time) (time.Time, error) {
+	if settings.ResetSchedule == "" {
+		return time.Time{}, nil
+	}
+	expr, err := cronexpr.Parse(settings.ResetSchedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid season reset schedule: %w", err)
+	}
+	// Last is strictly before the given time.
+	return expr.Last(now.UTC().Add(time.Second)), nil
+}
+
+// StartRatingMaintenance periodically applies inactivity sigma inflation and seasonal soft resets to the rating leaderboards.
+// Every node runs the job; the versioned state ensures that each run is applied by a single node.
+func StartRatingMaintenance(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) {
+	go func() {
+		ticker := time.NewTicker(ratingMaintenanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := RatingMaintenanceRun(ctx, logger, nk, time.Now().UTC()); err != nil {
+					logger.WithField("error", err).Error("Failed to run rating maintenance")
+				}
+			}
+		}
+	}()
+}
+
+// RatingMaintenanceRun applies whatever inflation and soft reset is due at now. The run is claimed by one node,
+// and the progress of each board is stored as it's applied, so that a failed board is retried on the next run
+// without applying the others twice. A board whose records partly fail is still marked as done, so that the
+// records that were changed aren't reset or inflated again.
+func RatingMaintenanceRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, now time.Time) error {
+	settings := ServiceSettings()
+	if settings == nil || settings.DisableRatingsUpdates {
+		return nil
+	}
+	config := settings.SkillRating
+	defaults := GetRatingDefaults(&config)
+
+	seasonStart, err := lastSeasonStart(config.Season, now)
+	if err != nil {
+		return err
+	}
+	inflate := config.Inactivity.GraceDays > 0 && config.Inactivity.SigmaPerDay > 0
+	if !inflate && seasonStart.IsZero() {
+		return nil
+	}
+
+	state, version, err := ratingMaintenanceStateRead(ctx, nk)
+	if err != nil {
+		return err
+	}
+	if now.Before(state.ClaimedUntil) {
+		return nil
+	}
+
+	// Claim the run; another node has already done it if this fails.
+	state.ClaimedUntil = now.Add(ratingMaintenanceInterval)
+	if version, err = ratingMaintenanceStateWrite(ctx, nk, state, version); err != nil {
+		logger.WithField("error", err).Debug("Rating maintenance claimed by another node")
+		return nil
+	}
+
+	var boardIDs []string
+	cursor := ""
+	for {
+		list, err := nk.LeaderboardList(100, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to list leaderboards: %w", err)
+		}
+		for _, b := range list.GetLeaderboards() {
+			_, _, statName, resetSchedule, err := ParseStatisticBoardID(b.Id)
+			if err != nil || resetSchedule != string(evr.ResetScheduleAllTime) {
+				continue
+			}
+			if _, ok := ratingBoardPairs[statName]; ok {
+				boardIDs = append(boardIDs, b.Id)
+			}
+		}
+		if cursor = list.GetCursor(); cursor == "" {
+			break
+		}
+	}
+
+	if state.Boards == nil {
+		state.Boards = make(map[string]*RatingBoardMaintenance, len(boardIDs))
+	}
+
+	var errs []error
+	updated, resets, skipped := 0, 0, 0
+	boardsFailed := false
+	for _, muBoardID := range boardIDs {
+		progress := state.Board(muBoardID)
+
+		reset := false
+		if !seasonStart.IsZero() && seasonStart.After(progress.LastSeasonReset) {
+			// The first run only records the current season, rather than resetting it when the schedule is set.
+			reset = !progress.LastSeasonReset.IsZero()
+			progress.LastSeasonReset = seasonStart
+		}
+
+		// On the first run, nothing has been counted, so inactivity is counted from each player's last match.
+		n, recordErrs, err := ratingMaintenanceApply(ctx, nk, muBoardID, progress.LastRun, now, inflate, reset, config, defaults)
+		updated += n
+		if err != nil {
+			// Nothing was applied; the next run retries the board.
+			errs = append(errs, err)
+			boardsFailed = true
+			continue
+		}
+		if len(recordErrs) > 0 {
+			// The progress is still stored, so the records that were changed aren't changed again; the failed
+			// records miss this run's adjustment.
+			errs = append(errs, recordErrs...)
+			skipped += len(recordErrs)
+		}
+		if reset {
+			resets++
+		}
+
+		progress.LastRun = now
+		state.Boards[muBoardID] = &progress
+		state.ClaimedUntil = now.Add(ratingMaintenanceInterval)
+		if version, err = ratingMaintenanceStateWrite(ctx, nk, state, version); err != nil {
+			// The claim has been lost; the next run retries the board.
+			errs = append(errs, fmt.Errorf("failed to store the rating maintenance progress of %s: %w", muBoardID, err))
+			return errors.Join(errs...)
+		}
+	}
+
+	// Release the claim. Once every board is up to date, boards that are new to the job start from here.
+	if !boardsFailed {
+		state.LastRun = now
+		if !seasonStart.IsZero() {
+			state.LastSeasonReset = seasonStart
+		}
+	}
+	state.ClaimedUntil = time.Time{}
+	if _, err := ratingMaintenanceStateWrite(ctx, nk, state, version); err != nil {
+		errs = append(errs, fmt.Errorf("failed to release the rating maintenance claim: %w", err))
+	}
+
+	logger.WithFields(map[string]any{
+		"boards":        len(boardIDs),
+		"updated":       updated,
+		"season_resets": resets,
+		"season_start":  seasonStart,
+		"failed":        len(errs),
+		"skipped":       skipped,
+	}).Info("Applied rating maintenance")
+
+	return errors.Join(errs...)
+}
+
+// ratingMaintenanceApply adjusts the ratings of one mu leaderboard and its sigma leaderboard. It returns the number of
+// ratings changed, and the errors of the records that were skipped. An error means that nothing was changed.
+func ratingMaintenanceApply(ctx context.Context, nk runtime.NakamaModule, muBoardID string, since, now time.Time, inflate, reset bool, config SkillRatingSettings, defaults RatingDefaults) (int, []error, error) {
+	groupID, mode, statName, _, err := ParseStatisticBoardID(muBoardID)
+	if err != nil {
+		return 0, nil, err
+	}
+	sigmaBoardID := StatisticBoardID(groupID, mode, ratingBoardPairs[statName], evr.ResetScheduleAllTime)
+
+	muRecords, err := ratingBoardRecords(ctx, nk, muBoardID)
+	if err != nil {
+		return 0, nil, err
+	}
+	sigmaRecords, err := ratingBoardRecords(ctx, nk, sigmaBoardID)
+	if err != nil && !errors.Is(err, runtime.ErrLeaderboardNotFound) && !errors.Is(err, ErrLeaderboardNotFound) {
+		return 0, nil, err
+	}
+
+	var errs []error
+	updated := 0
+	for ownerID, muRecord := range muRecords {
+		mu, err := ScoreToFloat64(muRecord.Score, muRecord.Subscore)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", muBoardID, ownerID, err))
+			continue
+		}
+		r := types.Rating{Z: defaults.Z, Mu: mu, Sigma: defaults.Sigma}
+		sigmaRecord, ok := sigmaRecords[ownerID]
+		if ok {
+			if r.Sigma, err = ScoreToFloat64(sigmaRecord.Score, sigmaRecord.Subscore); err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w", sigmaBoardID, ownerID, err))
+				continue
+			}
+		}
+		original := r
+
+		if reset {
+			r = SoftResetRating(r, config.Season, defaults)
+		}
+		lastRated := ratingLastRated(muRecord)
+		if inflate {
+			r.Sigma = InflateRatingSigma(r.Sigma, lastRated, since, now, config.Inactivity, defaults)
+		}
+
+		changed := false
+		if r.Mu != original.Mu {
+			// Keep when the player was last rated, so writing the record doesn't restart the inactivity.
+			if err := ratingRecordWrite(ctx, nk, muBoardID, muRecord, r.Mu, lastRated); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			changed = true
+		}
+		if r.Sigma != original.Sigma {
+			if sigmaRecord == nil {
+				sigmaRecord = &api.LeaderboardRecord{OwnerId: ownerID, Username: muRecord.Username, Metadata: muRecord.Metadata}
+			}
+			if err := ratingRecordWrite(ctx, nk, sigmaBoardID, sigmaRecord, r.Sigma, time.Time{}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			changed = true
+		}
+		if changed {
+			updated++
+		}
+	}
+	return updated, errs, nil
+}
+
+// ratingBoardRecords returns all the records of a leaderboard, by owner.
+func ratingBoardRecords(ctx context.Context, nk runtime.NakamaModule, boardID string) (map[string]*api.LeaderboardRecord, error) {
+	records := make(map[string]*api.LeaderboardRecord)
+	cursor := ""
+	for {
+		chunk, _, nextCursor, _, err := nk.LeaderboardRecordsList(ctx, boardID, nil, ratingMaintenancePageSize, cursor, 0)
+		if err != nil {
+			return records, err
+		}
+		for _, r := range chunk {
+			records[r.OwnerId] = r
+		}
+		if nextCursor == "" || len(chunk) == 0 {
+			return records, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// ratingRecordWrite sets the rating of the record. If lastRated is set, it's kept in the metadata, unless it has it already.
+func ratingRecordWrite(ctx context.Context, nk runtime.NakamaModule, boardID string, record *api.LeaderboardRecord, value float64, lastRated time.Time) error {
+	score, subscore, err := Float64ToScore(value)
+	if err != nil {
+		return fmt.Errorf("%s/%s: invalid rating value: %w", boardID, record.OwnerId, err)
+	}
+	var metadata map[string]any
+	if record.Metadata != "" {
+		if err := json.Unmarshal([]byte(record.Metadata), &metadata); err != nil {
+			return fmt.Errorf("%s/%s: failed to unmarshal metadata: %w", boardID, record.OwnerId, err)
+		}
+	}
+	if _, ok := metadata[RatingRatedAtMetadataKey]; !ok && !lastRated.IsZero() {
+		if metadata == nil {
+			metadata = make(map[string]any, 1)
+		}
+		metadata[RatingRatedAtMetadataKey] = lastRated.UTC().Format(time.RFC3339)
+	}
+	op := 2 // SET
+	if _, err := nk.LeaderboardRecordWrite(ctx, boardID, record.OwnerId, record.GetUsername().GetValue(), score, subscore, metadata, &op); err != nil {
+		// The sigma board may not exist yet.
+		if err := nk.LeaderboardCreate(ctx, boardID, true, "desc", "set", ResetScheduleToCron(evr.ResetScheduleAllTime), nil, true); err != nil {
+			return fmt.Errorf("%s: leaderboard create error: %w", boardID, err)
+		} else if _, err := nk.LeaderboardRecordWrite(ctx, boardID, record.OwnerId, record.GetUsername().GetValue(), score, subscore, metadata, &op); err != nil {
+			return fmt.Errorf("%s/%s: leaderboard record write error: %w", boardID, record.OwnerId, err)
+		}
+	}
+	return nil
+}
+
+func ratingMaintenanceStateRead(ctx context.Context, nk runtime.NakamaModule) (*RatingMaintenanceState, string, error) {
+	objs, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: RatingMaintenanceStorageCollection,
+		Key:        RatingMaintenanceStorageKey,
+		UserID:     SystemUserID,
+	}})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read rating maintenance state: %w", err)
+	}
+	state := &RatingMaintenanceState{}
+	if len(objs) == 0 {
+		return state, "*", nil
+	}
+	if err := json.Unmarshal([]byte(objs[0].Value), state); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal rating maintenance state: %w", err)
+	}
+	return state, objs[0].Version, nil
+}
+
+// ratingMaintenanceStateWrite stores the state if it's still at the version, and returns its new version.
+func ratingMaintenanceStateWrite(ctx context.Context, nk runtime.NakamaModule, state *RatingMaintenanceState, version string) (string, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	acks, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      RatingMaintenanceStorageCollection,
+		Key:             RatingMaintenanceStorageKey,
+		UserID:          SystemUserID,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	if err != nil {
+		return "", err
+	}
+	return acks[0].Version, nil
+}
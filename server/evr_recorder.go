@@ -0,0 +1,442 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// EVR recording file format.
+//
+// The header is the magic "EVRREC", a version byte, the uvarint-prefixed label,
+// and the varint start time in Unix microseconds. It is followed by records of:
+//
+//	kind (1 byte), uvarint microseconds since the previous record, uvarint stream, uvarint length, data
+//
+// A stream record declares the session ID (data) of the next stream index. Packet records
+// hold the framed packet exactly as it went over the wire.
+const (
+	evrRecordingMagic   = "EVRREC"
+	evrRecordingVersion = 1
+
+	EvrRecordingMaxBytes        = 64 * 1024 * 1024
+	EvrRecordingDefaultDuration = 15 * time.Minute
+)
+
+type EvrPacketDirection byte
+
+const (
+	EvrPacketIncoming EvrPacketDirection = iota + 1 // From the client
+	EvrPacketOutgoing                               // To the client
+
+	evrRecordStream EvrPacketDirection = 0x10
+)
+
+func (d EvrPacketDirection) String() string {
+	switch d {
+	case EvrPacketIncoming:
+		return "in"
+	case EvrPacketOutgoing:
+		return "out"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	ErrEvrRecordingInvalid   = errors.New("invalid evr recording")
+	ErrEvrRecordingTooLarge  = errors.New("evr recording size limit reached")
+	ErrEvrRecordingNotFound  = errors.New("evr recording not found")
+	ErrEvrRecordingConflicts = errors.New("session is already being recorded")
+)
+
+// EvrRecordedPacket is a packet read from a recording.
+type EvrRecordedPacket struct {
+	SessionID uuid.UUID
+	Direction EvrPacketDirection
+	Time      time.Time
+	Data      []byte
+}
+
+// EvrRecordingWriter writes the recording format. It is not safe for concurrent use.
+type EvrRecordingWriter struct {
+	w       io.Writer
+	last    time.Time
+	streams map[uuid.UUID]uint64
+	size    int64
+	buf     []byte
+}
+
+func NewEvrRecordingWriter(w io.Writer, label string, start time.Time) (*EvrRecordingWriter, error) {
+	rw := &EvrRecordingWriter{
+		w:       w,
+		last:    start,
+		streams: make(map[uuid.UUID]uint64),
+		buf:     make([]byte, 0, 64),
+	}
+	b := append([]byte(evrRecordingMagic), evrRecordingVersion)
+	b = binary.AppendUvarint(b, uint64(len(label)))
+	b = append(b, label...)
+	b = binary.AppendVarint(b, start.UnixMicro())
+	return rw, rw.write(b)
+}
+
+// Size returns the number of bytes written.
+func (rw *EvrRecordingWriter) Size() int64 {
+	return rw.size
+}
+
+func (rw *EvrRecordingWriter) write(b []byte) error {
+	n, err := rw.w.Write(b)
+	rw.size += int64(n)
+	return err
+}
+
+func (rw *EvrRecordingWriter) writeRecord(kind EvrPacketDirection, t time.Time, stream uint64, data []byte) error {
+	delta := t.Sub(rw.last).Microseconds()
+	if delta < 0 {
+		// Keep the timeline monotonic.
+		delta = 0
+	} else {
+		rw.last = t
+	}
+	b := append(rw.buf[:0], byte(kind))
+	b = binary.AppendUvarint(b, uint64(delta))
+	b = binary.AppendUvarint(b, stream)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	rw.buf = b
+	if err := rw.write(b); err != nil {
+		return err
+	}
+	return rw.write(data)
+}
+
+// WritePacket appends the framed packet sent or received by the session.
+func (rw *EvrRecordingWriter) WritePacket(sessionID uuid.UUID, direction EvrPacketDirection, t time.Time, data []byte) error {
+	stream, ok := rw.streams[sessionID]
+	if !ok {
+		stream = uint64(len(rw.streams))
+		if err := rw.writeRecord(evrRecordStream, t, stream, sessionID.Bytes()); err != nil {
+			return err
+		}
+		rw.streams[sessionID] = stream
+	}
+	return rw.writeRecord(direction, t, stream, data)
+}
+
+// EvrRecordingReader reads the recording format.
+type EvrRecordingReader struct {
+	r       *bufio.Reader
+	Label   string
+	Start   time.Time
+	last    time.Time
+	streams []uuid.UUID
+}
+
+func NewEvrRecordingReader(r io.Reader) (*EvrRecordingReader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(evrRecordingMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(evrRecordingMagic)]) != evrRecordingMagic {
+		return nil, ErrEvrRecordingInvalid
+	}
+	if v := header[len(evrRecordingMagic)]; v != evrRecordingVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrEvrRecordingInvalid, v)
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil || n > 1024 {
+		return nil, ErrEvrRecordingInvalid
+	}
+	label := make([]byte, n)
+	if _, err := io.ReadFull(br, label); err != nil {
+		return nil, ErrEvrRecordingInvalid
+	}
+	start, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, ErrEvrRecordingInvalid
+	}
+
+	return &EvrRecordingReader{
+		r:     br,
+		Label: string(label),
+		Start: time.UnixMicro(start).UTC(),
+		last:  time.UnixMicro(start).UTC(),
+	}, nil
+}
+
+// Sessions returns the session IDs declared so far, in order of appearance.
+func (rr *EvrRecordingReader) Sessions() []uuid.UUID {
+	return slices.Clone(rr.streams)
+}
+
+// Next returns the next packet, or io.EOF at the end of the recording.
+// A truncated final record, e.g. from a crash, is also reported as io.EOF.
+func (rr *EvrRecordingReader) Next() (*EvrRecordedPacket, error) {
+	for {
+		kind, err := rr.r.ReadByte()
+		if err != nil {
+			return nil, io.EOF
+		}
+		delta, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, io.EOF
+		}
+		stream, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, io.EOF
+		}
+		n, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, io.EOF
+		}
+		if n > EvrRecordingMaxBytes {
+			return nil, ErrEvrRecordingInvalid
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(rr.r, data); err != nil {
+			return nil, io.EOF
+		}
+		rr.last = rr.last.Add(time.Duration(delta) * time.Microsecond)
+
+		switch EvrPacketDirection(kind) {
+		case evrRecordStream:
+			sessionID, err := uuid.FromBytes(data)
+			if err != nil || stream != uint64(len(rr.streams)) {
+				return nil, ErrEvrRecordingInvalid
+			}
+			rr.streams = append(rr.streams, sessionID)
+		case EvrPacketIncoming, EvrPacketOutgoing:
+			if stream >= uint64(len(rr.streams)) {
+				return nil, ErrEvrRecordingInvalid
+			}
+			return &EvrRecordedPacket{
+				SessionID: rr.streams[stream],
+				Direction: EvrPacketDirection(kind),
+				Time:      rr.last,
+				Data:      data,
+			}, nil
+		default:
+			return nil, fmt.Errorf("%w: unknown record kind %d", ErrEvrRecordingInvalid, kind)
+		}
+	}
+}
+
+// EvrRecorder records the traffic of one or more sessions to a file.
+type EvrRecorder struct {
+	sync.Mutex
+	ID         string      `json:"id"`
+	Label      string      `json:"label"`
+	Path       string      `json:"path"`
+	MatchID    MatchID     `json:"match_id,omitempty"` // Set for match recordings, which follow the players that join later.
+	SessionIDs []uuid.UUID `json:"session_ids"`
+	StartTime  time.Time   `json:"start_time"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+
+	file   *os.File
+	buf    *bufio.Writer
+	writer *EvrRecordingWriter
+	timer  *time.Timer
+	closed bool
+}
+
+// Snapshot returns a copy of the recording's details.
+func (r *EvrRecorder) Snapshot() *EvrRecorder {
+	r.Lock()
+	defer r.Unlock()
+	return &EvrRecorder{
+		ID:         r.ID,
+		Label:      r.Label,
+		Path:       r.Path,
+		MatchID:    r.MatchID,
+		SessionIDs: slices.Clone(r.SessionIDs),
+		StartTime:  r.StartTime,
+		ExpiresAt:  r.ExpiresAt,
+	}
+}
+
+func (r *EvrRecorder) Record(sessionID uuid.UUID, direction EvrPacketDirection, data []byte) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.closed {
+		return nil
+	}
+	if r.writer.Size()+int64(len(data)) > EvrRecordingMaxBytes {
+		return ErrEvrRecordingTooLarge
+	}
+	return r.writer.WritePacket(sessionID, direction, time.Now(), data)
+}
+
+// Close flushes and closes the file. It is safe to call more than once.
+func (r *EvrRecorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	return errors.Join(r.buf.Flush(), r.file.Close())
+}
+
+// EvrRecorderRegistry tracks the active recordings of the sessions on this node.
+// Recording is opt-in: nothing is captured until Start is called for a session.
+// Only the traffic of sessions connected to this node is captured.
+type EvrRecorderRegistry struct {
+	dir        string
+	recorders  MapOf[string, *EvrRecorder]    // map[recordingID]*EvrRecorder
+	bySession  MapOf[uuid.UUID, *EvrRecorder] // map[sessionID]*EvrRecorder
+	byMatch    MapOf[MatchID, *EvrRecorder]   // map[matchID]*EvrRecorder
+	startMutex sync.Mutex
+}
+
+func NewEvrRecorderRegistry(dir string) *EvrRecorderRegistry {
+	return &EvrRecorderRegistry{dir: dir}
+}
+
+// Start records the sessions' traffic into a new file in the recording directory, until stopped or the duration elapses.
+func (r *EvrRecorderRegistry) Start(label string, sessionIDs []uuid.UUID, duration time.Duration) (*EvrRecorder, error) {
+	return r.start(label, MatchID{}, sessionIDs, duration)
+}
+
+// StartMatch records the match's sessions, and those that join it later through this node.
+func (r *EvrRecorderRegistry) StartMatch(matchID MatchID, sessionIDs []uuid.UUID, duration time.Duration) (*EvrRecorder, error) {
+	return r.start("match:"+matchID.String(), matchID, sessionIDs, duration)
+}
+
+func (r *EvrRecorderRegistry) start(label string, matchID MatchID, sessionIDs []uuid.UUID, duration time.Duration) (*EvrRecorder, error) {
+	r.startMutex.Lock()
+	defer r.startMutex.Unlock()
+
+	for _, id := range sessionIDs {
+		if _, ok := r.bySession.Load(id); ok {
+			return nil, fmt.Errorf("%w: %s", ErrEvrRecordingConflicts, id)
+		}
+	}
+	if !matchID.IsNil() {
+		if _, ok := r.byMatch.Load(matchID); ok {
+			return nil, fmt.Errorf("%w: %s", ErrEvrRecordingConflicts, matchID)
+		}
+	}
+	if duration <= 0 {
+		duration = EvrRecordingDefaultDuration
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	id := uuid.Must(uuid.NewV4()).String()
+	path := filepath.Join(r.dir, fmt.Sprintf("%s-%s.evrrec", now.Format("20060102T150405Z"), id))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	buf := bufio.NewWriter(file)
+	writer, err := NewEvrRecordingWriter(buf, label, now)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+
+	recorder := &EvrRecorder{
+		ID:         id,
+		Label:      label,
+		Path:       path,
+		MatchID:    matchID,
+		SessionIDs: sessionIDs,
+		StartTime:  now,
+		ExpiresAt:  now.Add(duration),
+		file:       file,
+		buf:        buf,
+		writer:     writer,
+	}
+	r.recorders.Store(id, recorder)
+	for _, sessionID := range sessionIDs {
+		r.bySession.Store(sessionID, recorder)
+	}
+	if !matchID.IsNil() {
+		r.byMatch.Store(matchID, recorder)
+	}
+	// Close the file when the recording expires, even if the sessions go quiet.
+	recorder.Lock()
+	recorder.timer = time.AfterFunc(duration, func() { _, _ = r.Stop(id) })
+	recorder.Unlock()
+	return recorder.Snapshot(), nil
+}
+
+// Follow adds a session that joined the match to the match's recording, if there is one.
+func (r *EvrRecorderRegistry) Follow(matchID MatchID, sessionID uuid.UUID) {
+	r.startMutex.Lock()
+	defer r.startMutex.Unlock()
+
+	recorder, ok := r.byMatch.Load(matchID)
+	if !ok {
+		return
+	}
+	if _, ok := r.bySession.Load(sessionID); ok {
+		return
+	}
+	recorder.Lock()
+	defer recorder.Unlock()
+	if recorder.closed {
+		return
+	}
+	recorder.SessionIDs = append(slices.Clone(recorder.SessionIDs), sessionID)
+	r.bySession.Store(sessionID, recorder)
+}
+
+// Stop ends the recording and closes its file.
+func (r *EvrRecorderRegistry) Stop(id string) (*EvrRecorder, error) {
+	r.startMutex.Lock()
+	recorder, ok := r.recorders.LoadAndDelete(id)
+	if !ok {
+		r.startMutex.Unlock()
+		return nil, ErrEvrRecordingNotFound
+	}
+	snapshot := recorder.Snapshot()
+	for _, sessionID := range snapshot.SessionIDs {
+		r.bySession.Delete(sessionID)
+	}
+	if !snapshot.MatchID.IsNil() {
+		r.byMatch.Delete(snapshot.MatchID)
+	}
+	r.startMutex.Unlock()
+	return snapshot, recorder.Close()
+}
+
+// List returns the active recordings.
+func (r *EvrRecorderRegistry) List() []*EvrRecorder {
+	recorders := make([]*EvrRecorder, 0)
+	r.recorders.Range(func(_ string, recorder *EvrRecorder) bool {
+		recorders = append(recorders, recorder.Snapshot())
+		return true
+	})
+	slices.SortFunc(recorders, func(a, b *EvrRecorder) int { return a.StartTime.Compare(b.StartTime) })
+	return recorders
+}
+
+// Record captures the packet if the session is being recorded. Expired or full recordings are stopped.
+func (r *EvrRecorderRegistry) Record(sessionID uuid.UUID, direction EvrPacketDirection, data []byte) {
+	recorder, ok := r.bySession.Load(sessionID)
+	if !ok {
+		return
+	}
+	if time.Now().After(recorder.ExpiresAt) {
+		_, _ = r.Stop(recorder.ID)
+		return
+	}
+	if err := recorder.Record(sessionID, direction, data); err != nil {
+		_, _ = r.Stop(recorder.ID)
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/gorilla/websocket"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"go.uber.org/zap"
+)
+
+// EvrReplayFunc is called with each packet of a recording, and the result of parsing it.
+type EvrReplayFunc func(p *EvrRecordedPacket, messages []evr.Message, err error) error
+
+// ReplayEvrRecording parses each packet of the recording with evr.ParsePacket.
+// Parse errors are passed to fn, which decides whether to stop.
+func ReplayEvrRecording(rr *EvrRecordingReader, fn EvrReplayFunc) error {
+	for {
+		p, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		messages, err := evr.ParsePacket(p.Data)
+		if err := fn(p, messages, err); err != nil {
+			return err
+		}
+	}
+}
+
+// ReplayEvrRecording feeds the incoming packets of the recorded session through the pipeline handlers,
+// as if they were received by the given session. It stops at the first message the pipeline rejects.
+func (p *EvrPipeline) ReplayEvrRecording(logger *zap.Logger, session Session, rr *EvrRecordingReader, sessionID uuid.UUID) error {
+	return ReplayEvrRecording(rr, func(packet *EvrRecordedPacket, messages []evr.Message, err error) error {
+		if packet.SessionID != sessionID || packet.Direction != EvrPacketIncoming {
+			return nil
+		}
+		if errors.Is(err, evr.ErrSymbolNotFound) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("packet at %s: %w", packet.Time.Sub(rr.Start), err)
+		}
+		for _, m := range messages {
+			if m == nil {
+				continue
+			}
+			if !p.ProcessRequestEVR(logger, session, m) {
+				return fmt.Errorf("pipeline rejected %T at %s", m, packet.Time.Sub(rr.Start))
+			}
+		}
+		return nil
+	})
+}
+
+// EvrReplayClient replays a recorded session against a server's EVR socket.
+type EvrReplayClient struct {
+	conn *websocket.Conn
+}
+
+// DialEvrReplay connects to the socket URL, e.g. "ws://127.0.0.1:7350/ws?format=evr".
+func DialEvrReplay(url string) (*EvrReplayClient, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return &EvrReplayClient{conn: conn}, nil
+}
+
+func (c *EvrReplayClient) Send(data []byte) error {
+	return c.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// Receive reads packets until the connection is closed, or nothing is received for the idle duration.
+func (c *EvrReplayClient) Receive(idle time.Duration, fn func(data []byte)) error {
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(idle)); err != nil {
+			return err
+		}
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			var netErr interface{ Timeout() bool }
+			if errors.As(err, &netErr) && netErr.Timeout() || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		fn(data)
+	}
+}
+
+func (c *EvrReplayClient) Close() error {
+	_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return c.conn.Close()
+}
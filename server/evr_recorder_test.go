@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/gorilla/websocket"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvrRecordingRoundTrip(t *testing.T) {
+	a, b := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	start := time.UnixMicro(time.Now().UnixMicro()).UTC()
+
+	buf := &bytes.Buffer{}
+	w, err := NewEvrRecordingWriter(buf, "session:test", start)
+	require.NoError(t, err)
+	require.NoError(t, w.WritePacket(a, EvrPacketIncoming, start.Add(time.Millisecond), []byte("one")))
+	require.NoError(t, w.WritePacket(b, EvrPacketOutgoing, start.Add(2*time.Millisecond), []byte("two")))
+	require.NoError(t, w.WritePacket(a, EvrPacketOutgoing, start.Add(3*time.Millisecond), []byte("three")))
+	assert.EqualValues(t, buf.Len(), w.Size())
+
+	// A partial record at the end is ignored.
+	buf.Write([]byte{byte(EvrPacketIncoming), 1})
+
+	rr, err := NewEvrRecordingReader(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "session:test", rr.Label)
+	assert.Equal(t, start, rr.Start)
+
+	var packets []*EvrRecordedPacket
+	for {
+		p, err := rr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		packets = append(packets, p)
+	}
+	require.Len(t, packets, 3)
+	assert.Equal(t, &EvrRecordedPacket{SessionID: a, Direction: EvrPacketIncoming, Time: start.Add(time.Millisecond), Data: []byte("one")}, packets[0])
+	assert.Equal(t, b, packets[1].SessionID)
+	assert.Equal(t, EvrPacketOutgoing, packets[2].Direction)
+	assert.Equal(t, []uuid.UUID{a, b}, rr.Sessions())
+
+	_, err = NewEvrRecordingReader(strings.NewReader("not a recording"))
+	assert.ErrorIs(t, err, ErrEvrRecordingInvalid)
+}
+
+func TestEvrRecorderRegistry(t *testing.T) {
+	registry := NewEvrRecorderRegistry(t.TempDir())
+	sessionID := uuid.Must(uuid.NewV4())
+
+	// Nothing is recorded until requested.
+	registry.Record(sessionID, EvrPacketIncoming, []byte("ignored"))
+
+	recorder, err := registry.Start("session:"+sessionID.String(), []uuid.UUID{sessionID}, time.Minute)
+	require.NoError(t, err)
+	_, err = registry.Start("again", []uuid.UUID{sessionID}, time.Minute)
+	assert.ErrorIs(t, err, ErrEvrRecordingConflicts)
+	assert.Len(t, registry.List(), 1)
+
+	packet, err := evr.Marshal(evr.NewSTcpConnectionUnrequireEvent())
+	require.NoError(t, err)
+	registry.Record(sessionID, EvrPacketOutgoing, packet)
+
+	_, err = registry.Stop(recorder.ID)
+	require.NoError(t, err)
+	_, err = registry.Stop(recorder.ID)
+	assert.ErrorIs(t, err, ErrEvrRecordingNotFound)
+	registry.Record(sessionID, EvrPacketOutgoing, packet)
+
+	f, err := os.Open(recorder.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	rr, err := NewEvrRecordingReader(f)
+	require.NoError(t, err)
+
+	count := 0
+	require.NoError(t, ReplayEvrRecording(rr, func(p *EvrRecordedPacket, messages []evr.Message, err error) error {
+		require.NoError(t, err)
+		assert.Equal(t, []string{"*evr.STcpConnectionUnrequireEvent"}, evrMessageTypes(messages))
+		count++
+		return nil
+	}))
+	assert.Equal(t, 1, count)
+}
+
+func TestEvrReplayCommand(t *testing.T) {
+	sessionID := uuid.Must(uuid.NewV4())
+	packet, err := evr.Marshal(evr.NewSTcpConnectionUnrequireEvent())
+	require.NoError(t, err)
+
+	path := t.TempDir() + "/test.evrrec"
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w, err := NewEvrRecordingWriter(f, "session:test", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, w.WritePacket(sessionID, EvrPacketIncoming, time.Now(), packet))
+	require.NoError(t, w.WritePacket(sessionID, EvrPacketOutgoing, time.Now(), packet))
+	require.NoError(t, f.Close())
+
+	// Offline, the packets are listed.
+	out := &bytes.Buffer{}
+	require.NoError(t, RunEvrCommand([]string{"replay", path}, nil, out))
+	assert.Equal(t, 2, strings.Count(out.String(), "*evr.STcpConnectionUnrequireEvent"))
+
+	// Against a server that echoes, the responses match the recording.
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			_ = conn.WriteMessage(mt, data)
+		}
+	}))
+	defer srv.Close()
+
+	out.Reset()
+	addr := "ws" + strings.TrimPrefix(srv.URL, "http")
+	require.NoError(t, RunEvrCommand([]string{"replay", "-addr", addr, "-idle", "200ms", path}, nil, out))
+	assert.Contains(t, out.String(), "matched 1 responses")
+}
+
+func TestEvrRecorderRegistry_Match(t *testing.T) {
+	registry := NewEvrRecorderRegistry(t.TempDir())
+	matchID := MatchID{UUID: uuid.Must(uuid.NewV4()), Node: "node"}
+	serverID, lateID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	recorder, err := registry.StartMatch(matchID, []uuid.UUID{serverID}, 100*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "match:"+matchID.String(), recorder.Label)
+	_, err = registry.StartMatch(matchID, nil, time.Minute)
+	assert.ErrorIs(t, err, ErrEvrRecordingConflicts)
+
+	// Players that join later are followed.
+	registry.Follow(matchID, lateID)
+	registry.Follow(MatchID{UUID: uuid.Must(uuid.NewV4()), Node: "node"}, uuid.Must(uuid.NewV4()))
+	require.Len(t, registry.List(), 1)
+	assert.Equal(t, []uuid.UUID{serverID, lateID}, registry.List()[0].SessionIDs)
+
+	packet, err := evr.Marshal(evr.NewSTcpConnectionUnrequireEvent())
+	require.NoError(t, err)
+	registry.Record(lateID, EvrPacketIncoming, packet)
+
+	// The recording is closed when it expires, without further traffic.
+	require.Eventually(t, func() bool { return len(registry.List()) == 0 }, 2*time.Second, 10*time.Millisecond)
+	registry.Follow(matchID, uuid.Must(uuid.NewV4()))
+	assert.Empty(t, registry.List())
+
+	f, err := os.Open(recorder.Path)
+	require.NoError(t, err)
+	defer f.Close()
+	rr, err := NewEvrRecordingReader(f)
+	require.NoError(t, err)
+	p, err := rr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, lateID, p.SessionID)
+}
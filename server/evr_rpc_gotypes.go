@@ -0,0 +1,281 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	serverPkgPath = reflect.TypeOf(EvrRPC{}).PkgPath()
+	uuidPkgPath   = reflect.TypeOf(uuid.UUID{}).PkgPath()
+)
+
+// goTypesBuilder declares the server types reachable from the RPC schemas in a standalone package,
+// following the encoding/json rules, so that clients don't have to import the server.
+// Types of other packages are referenced as they are.
+type goTypesBuilder struct {
+	decls   map[string]string
+	names   map[reflect.Type]string
+	imports map[string]string // map[path]name
+	err     error
+}
+
+func newGoTypesBuilder() *goTypesBuilder {
+	return &goTypesBuilder{
+		decls:   make(map[string]string),
+		names:   make(map[reflect.Type]string),
+		imports: make(map[string]string),
+	}
+}
+
+var goTypesVersionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// defaultImportName returns the name of the package, assuming it is the last element of its path.
+func defaultImportName(pkgPath string) string {
+	segments := strings.Split(pkgPath, "/")
+	if len(segments) > 1 && goTypesVersionSegment.MatchString(segments[len(segments)-1]) {
+		segments = segments[:len(segments)-1]
+	}
+	return segments[len(segments)-1]
+}
+
+// importName returns the name that the package is imported as.
+func (b *goTypesBuilder) importName(pkgPath string) string {
+	if name, ok := b.imports[pkgPath]; ok {
+		return name
+	}
+	taken := func(name string) bool {
+		for _, n := range b.imports {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	// Qualify the name with the parent elements of the path until it's unique.
+	name := strings.NewReplacer("-", "", ".", "").Replace(defaultImportName(pkgPath))
+	segments := strings.Split(strings.TrimSuffix(pkgPath, "/"+defaultImportName(pkgPath)), "/")
+	for i := len(segments) - 1; taken(name) && i >= 0; i-- {
+		if !goTypesVersionSegment.MatchString(segments[i]) {
+			name = strings.NewReplacer("-", "", ".", "").Replace(segments[i]) + name
+		}
+	}
+	b.imports[pkgPath] = name
+	return name
+}
+
+// marshalsAsString reports whether the custom JSON encoding of the type is a string.
+func marshalsAsString(t reflect.Type) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	data, err := json.Marshal(reflect.New(t).Interface())
+	return err == nil && len(data) > 0 && data[0] == '"'
+}
+
+func (b *goTypesBuilder) TypeExpr(t reflect.Type) string {
+	if t == rawMessageType {
+		return b.importName("encoding/json") + ".RawMessage"
+	}
+	if t.Kind() == reflect.Interface {
+		return "any"
+	}
+	if t.Name() != "" && t.PkgPath() != "" {
+		switch {
+		case t == timeType || t.PkgPath() == uuidPkgPath:
+			return b.importName(t.PkgPath()) + "." + t.Name()
+		case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+			return "string"
+		case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType), strings.Contains(t.Name(), "["):
+			// Custom encodings, and generic types of other packages.
+			if marshalsAsString(t) {
+				return "string"
+			}
+			return b.importName("encoding/json") + ".RawMessage"
+		case t.PkgPath() == serverPkgPath:
+			return b.declare(t)
+		default:
+			return b.importName(t.PkgPath()) + "." + t.Name()
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
+		return b.unnamedExpr(t)
+	case reflect.Struct:
+		return b.structExpr(t)
+	default:
+		return t.Kind().String()
+	}
+}
+
+// declare adds the declaration of the named server type, and returns its name.
+func (b *goTypesBuilder) declare(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if strings.Contains(name, "[") && b.err == nil {
+		b.err = fmt.Errorf("generic type %s can't be declared", name)
+	}
+	b.names[t] = name
+	// Reserve the name before descending, for recursive types.
+	b.decls[name] = ""
+
+	var underlying string
+	switch t.Kind() {
+	case reflect.Struct:
+		underlying = b.structExpr(t)
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
+		underlying = b.unnamedExpr(t)
+	default:
+		underlying = t.Kind().String()
+	}
+	b.decls[name] = fmt.Sprintf("type %s %s", name, underlying)
+	return name
+}
+
+// unnamedExpr returns the expression of a composite type.
+func (b *goTypesBuilder) unnamedExpr(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + b.TypeExpr(t.Elem())
+	case reflect.Slice:
+		return "[]" + b.TypeExpr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), b.TypeExpr(t.Elem()))
+	case reflect.Map:
+		return "map[" + b.TypeExpr(t.Key()) + "]" + b.TypeExpr(t.Elem())
+	default:
+		return "any"
+	}
+}
+
+func (b *goTypesBuilder) structExpr(t reflect.Type) string {
+	var sb strings.Builder
+	sb.WriteString("struct {\n")
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			// Not encodable.
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		embedded := f.Anonymous && name == "" && ft.Kind() == reflect.Struct
+		if !f.IsExported() && !embedded {
+			continue
+		}
+		if embedded && ft.PkgPath() != serverPkgPath && !hasExportedFields(ft) {
+			// Locks and the like.
+			continue
+		}
+
+		if embedded {
+			sb.WriteString(b.TypeExpr(f.Type))
+		} else {
+			sb.WriteString(f.Name + " " + b.TypeExpr(f.Type))
+		}
+		if hasTag {
+			fmt.Fprintf(&sb, " `json:%q`", tag)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// EvrRPCGoTypes returns the source of a package that declares the request, query and response
+// types of the RPCs, the extra types (for responses that the schema doesn't cover), and every
+// server type that they reach.
+func EvrRPCGoTypes(rpcs []EvrRPC, extra []any, pkg, generator string) ([]byte, error) {
+	b := newGoTypesBuilder()
+	roots := slices.Clone(extra)
+	for _, rpc := range rpcs {
+		roots = append(roots, rpc.Request, rpc.Query, rpc.Response)
+	}
+	for _, v := range roots {
+		if v == nil {
+			continue
+		}
+		t := reflect.TypeOf(v)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Name() == "" || t.PkgPath() == serverPkgPath {
+			b.TypeExpr(t)
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by %s. DO NOT EDIT.\n\n", generator)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+
+	// The standard library first.
+	var std, other []string
+	for p := range b.imports {
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			other = append(other, p)
+		} else {
+			std = append(std, p)
+		}
+	}
+	slices.Sort(std)
+	slices.Sort(other)
+	buf.WriteString("import (\n")
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 && len(other) > 0 {
+			buf.WriteString("\n")
+		}
+		for _, p := range group {
+			if defaultImportName(p) == b.imports[p] {
+				fmt.Fprintf(&buf, "%q\n", p)
+			} else {
+				fmt.Fprintf(&buf, "%s %q\n", b.imports[p], p)
+			}
+		}
+	}
+	buf.WriteString(")\n")
+
+	names := make([]string, 0, len(b.decls))
+	for name := range b.decls {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		buf.WriteString("\n" + b.decls[name] + "\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
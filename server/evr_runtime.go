@@ -73,8 +73,10 @@ func InitializeEvrRuntimeModule(ctx context.Context, logger runtime.Logger, db *
 	ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_ENV, vars) // ignore lint
 	// Initialize the discord bot if the token is set
 	if appBotToken, ok := vars["DISCORD_BOT_TOKEN"]; !ok || appBotToken == "" {
-		logger.Warn("DISCORD_BOT_TOKEN is not set, Discord bot will not be used")
-		panic("Bot token is not set in context.") // TODO: remove bot dependency
+		// Guild groups, enforcement and login are backed by Nakama groups and storage,
+		// so they continue to work; bot-only features (slash commands, audit channels, role sync) are disabled.
+		logger.Warn("DISCORD_BOT_TOKEN is not set, running without Discord.")
+		dg = nil
 	} else {
 		if dg, err = discordgo.New("Bot " + appBotToken); err != nil {
 			logger.Error("Unable to create bot", zap.Error(err))
@@ -85,41 +87,10 @@ func InitializeEvrRuntimeModule(ctx context.Context, logger runtime.Logger, db *
 
 	// Register RPC's for device linking
 	rpcHandler := NewRPCHandler(ctx, db, dg)
-	rpcs := map[string]func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error){
-		"account/search":                AccountSearchRPC,
-		"account/lookup":                rpcHandler.AccountLookupRPC,
-		"account/authenticate/password": AuthenticatePasswordRPC,
-		"leaderboard/haystack":          rpcHandler.LeaderboardHaystackRPC,
-		"leaderboard/records":           rpcHandler.LeaderboardRecordsListRPC,
-		"link/device":                   LinkDeviceRpc,
-		"link/usernamedevice":           LinkUserIdDeviceRpc,
-		"signin/discord":                DiscordSignInRpc,
-		"match/public":                  rpcHandler.MatchListPublicRPC,
-		"match":                         MatchRPC,
-		"match/prepare":                 PrepareMatchRPC,
-		"match/allocate":                AllocateMatchRPC,
-		"match/terminate":               shutdownMatchRpc,
-		"match/build":                   BuildMatchRPC,
-		"player/setnextmatch":           SetNextMatchRPC,
-		"player/statistics":             PlayerStatisticsRPC,
-		"player/kick":                   KickPlayerRPC,
-		"player/profile":                UserServerProfileRPC,
-		"link":                          LinkingAppRpc,
-		"evr/servicestatus":             rpcHandler.ServiceStatusRPC,
-		"importloadouts":                ImportLoadoutsRpc,
-		"matchmaker/stream":             MatchmakerStreamRPC,
-		"matchmaker/state":              MatchmakerStateRPC,
-		"matchmaker/candidates":         MatchmakerCandidatesRPCFactory(sbmm),
-		"stream/join":                   StreamJoinRPC,
-		"server/score":                  ServerScoreRPC,
-		"server/scores":                 ServerScoresRPC,
-		"forcecheck":                    CheckForceUserRPC,
-		"guildgroup":                    GuildGroupGetRPC,
-		//"/v1/storage/game/sourcedb/rad15/json/r14/loading_tips.json": StorageLoadingTipsRPC,
-	}
-	for name, rpc := range rpcs {
-		if err = initializer.RegisterRpc(name, rpc); err != nil {
-			return fmt.Errorf("unable to register %s: %w", name, err)
+	rpcs := EvrRPCs(rpcHandler, sbmm)
+	for _, rpc := range rpcs {
+		if err = initializer.RegisterRpc(rpc.ID, rpc.Fn); err != nil {
+			return fmt.Errorf("unable to register %s: %w", rpc.ID, err)
 		}
 	}
 
@@ -157,6 +128,20 @@ func InitializeEvrRuntimeModule(ctx context.Context, logger runtime.Logger, db *
 		return fmt.Errorf("unable to register /evr/api service: %w", err)
 	}
 
+	// Serve the OpenAPI document for the RPCs
+	openAPIHandler, err := NewEvrOpenAPIHandler(rpcs)
+	if err != nil {
+		return fmt.Errorf("unable to generate OpenAPI document: %w", err)
+	}
+	if err := initializer.RegisterHttp(EvrOpenAPIPath, openAPIHandler, http.MethodGet); err != nil {
+		return fmt.Errorf("unable to register %s: %w", EvrOpenAPIPath, err)
+	}
+
+	// Serve the public live scoreboard feed for stream overlays
+	if err := initializer.RegisterHttp(LiveScoreboardPath, NewLiveScoreboardHandler(ctx, logger, nk), http.MethodGet); err != nil {
+		return fmt.Errorf("unable to register %s: %w", LiveScoreboardPath, err)
+	}
+
 	// The statistics queue handles inserting match statistics into the leaderboard records
 	statisticsQueue := NewStatisticsQueue(logger, db, nk)
 
@@ -215,11 +200,6 @@ func InitializeEvrRuntimeModule(ctx context.Context, logger runtime.Logger, db *
 		logger.Info("Registered /static/ file server for development environment.")
 	}
 
-	// Add telemetry stream RPCs
-	if err := RegisterTelemetryStreamRPCs(ctx, logger, db, nk, initializer); err != nil {
-		return fmt.Errorf("unable to register telemetry stream RPCs: %w", err)
-	}
-
 	// Initialize MongoDB client if configured
 	var mongoClient *mongo.Client
 	if mongoURI, ok := vars["MONGO_URI"]; ok && mongoURI != "" {
@@ -228,11 +208,7 @@ func InitializeEvrRuntimeModule(ctx context.Context, logger runtime.Logger, db *
 			logger.Warn("Failed to connect to MongoDB, session events will not be available.", zap.Error(err))
 		} else {
 			logger.Info("Connected to MongoDB for session events")
-
-			// Register session event RPCs
-			if err := RegisterSessionEventRPCs(ctx, logger, db, nk, initializer, mongoClient); err != nil {
-				return fmt.Errorf("unable to register session event RPCs: %w", err)
-			}
+			globalMongoClient.Store(mongoClient)
 		}
 	} else {
 		logger.Warn("MONGO_URI is not set, session event RPCs will not be available.")
@@ -331,6 +307,7 @@ func RegisterIndexes(initializer runtime.Initializer) error {
 		&MatchmakingSettings{},
 		&VRMLPlayerSummary{},
 		&LoginHistory{},
+		&AlternateReview{},
 	}
 	for _, s := range storables {
 		for _, idx := range s.StorageIndexes() {
@@ -466,6 +443,20 @@ func CheckSystemGroupMembership(ctx context.Context, db *sql.DB, userID, groupNa
 	return CheckGroupMembershipByName(ctx, db, userID, groupName, SystemGroupLangTag)
 }
 
+// checkSystemGroupAccess restricts an RPC to the members of the system group, and server to server calls.
+func checkSystemGroupAccess(ctx context.Context, db *sql.DB, groupName string) error {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return nil
+	}
+	if ok, err := CheckSystemGroupMembership(ctx, db, userID, groupName); err != nil {
+		return runtime.NewError("failed to check access", StatusInternalError)
+	} else if !ok {
+		return runtime.NewError("permission denied", StatusPermissionDenied)
+	}
+	return nil
+}
+
 func CheckGroupMembershipByName(ctx context.Context, db *sql.DB, userID, groupName, groupType string) (bool, error) {
 	query := `
 SELECT ge.state FROM groups g, group_edge ge WHERE g.id = ge.destination_id AND g.lang_tag = $1 AND g.name = $2 
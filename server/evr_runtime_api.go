@@ -4,44 +4,178 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
+	"golang.org/x/time/rate"
 )
 
 const (
 	DEVAPP_CTX_APP_ID = "dev_app_id"
+	DEVAPP_CTX_APP    = "dev_app"
+
+	AppAPIPathPrefix = "/apievr/v1"
+
+	appAPILimiterPruneInterval = 15 * time.Minute
 )
 
+// AppAPIRoute is a handler on the developer API, and the scope that the application requires to use it.
+type AppAPIRoute struct {
+	Scope   string
+	Handler func(context.Context, http.ResponseWriter, *http.Request)
+}
+
 type AppAPI struct {
 	ctx      context.Context
 	logger   runtime.Logger
 	db       *sql.DB
 	nk       runtime.NakamaModule
-	handlers map[string]func(context.Context, http.ResponseWriter, *http.Request)
+	handlers map[string]AppAPIRoute
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rate.Limiter // By application ID
 }
 
 func NewAppAPI(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, initializer runtime.Initializer) *AppAPI {
-	return &AppAPI{
-		ctx:    ctx,
-		logger: logger,
-		db:     db,
-		nk:     nk,
-		handlers: map[string]func(context.Context, http.ResponseWriter, *http.Request){
-			"/": func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-				// Respond to the request
-				w.Write([]byte("Hello, World!"))
-			},
-		},
+	api := &AppAPI{
+		ctx:      ctx,
+		logger:   logger,
+		db:       db,
+		nk:       nk,
+		limiters: make(map[string]*rate.Limiter),
+	}
+	api.handlers = map[string]AppAPIRoute{
+		"/":                   {Handler: api.applicationHandler},
+		"/players":            {Scope: AppScopePlayersRead, Handler: api.playerHandler},
+		"/players/statistics": {Scope: AppScopeStatisticsRead, Handler: api.playerStatisticsHandler},
+		"/matches":            {Scope: AppScopeMatchesRead, Handler: api.matchListHandler},
+		"/guilds":             {Scope: AppScopeGuildsRead, Handler: api.guildHandler},
+	}
+	return api
+}
+
+// Allow reports whether the application is within its rate limit.
+func (api *AppAPI) Allow(app *DeveloperApplication) bool {
+	limit := app.RequestsPerMinute()
+	api.limitersMu.Lock()
+	limiter, found := api.limiters[app.ID.String()]
+	if !found || limiter.Burst() != limit {
+		// New application, or its limit has been changed.
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(limit)), limit)
+		api.limiters[app.ID.String()] = limiter
 	}
+	api.limitersMu.Unlock()
+	return limiter.Allow()
+}
+
+// pruneLimiters drops the limiters of the applications that are no longer stored.
+func (api *AppAPI) pruneLimiters(ctx context.Context) error {
+	stored := make(map[string]bool)
+	cursor := ""
+	for {
+		objs, next, err := api.nk.StorageList(ctx, SystemUserID, "", StorageCollectionDeveloper, 100, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to list developer applications: %w", err)
+		}
+		for _, obj := range objs {
+			if obj.GetKey() != StorageKeyApplications {
+				continue
+			}
+			apps := &DeveloperApplications{}
+			if err := json.Unmarshal([]byte(obj.GetValue()), apps); err != nil {
+				return fmt.Errorf("failed to unmarshal developer applications: %w", err)
+			}
+			for _, app := range apps.Applications {
+				stored[app.ID.String()] = true
+			}
+		}
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+	api.dropLimiters(stored)
+	return nil
+}
+
+// dropLimiters drops the limiters of the applications that aren't in the set.
+func (api *AppAPI) dropLimiters(stored map[string]bool) {
+	api.limitersMu.Lock()
+	defer api.limitersMu.Unlock()
+	for appID := range api.limiters {
+		if !stored[appID] {
+			delete(api.limiters, appID)
+		}
+	}
+}
+
+// Route returns the route for the request path, relative to the API prefix.
+func (api *AppAPI) Route(path string) (AppAPIRoute, bool) {
+	path = strings.TrimPrefix(path, AppAPIPathPrefix)
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		path = "/"
+	}
+	route, ok := api.handlers[path]
+	return route, ok
+}
+
+// authenticate returns the developer application for the token.
+// Application tokens identify the application directly; session tokens must carry the application ID in their vars.
+func (api *AppAPI) authenticate(ctx context.Context, signingKey, token string) (app *DeveloperApplication, userID, username string, vars map[string]string, ok bool) {
+	var ownerID, appID string
+	isAppToken := false
+
+	if aid, uid, usn, vrs, exp, _, _, valid := parseApplicationToken([]byte(signingKey), token); valid && exp >= time.Now().Unix() {
+		isAppToken = true
+		ownerID, appID, userID, username, vars = uid.String(), aid.String(), uid.String(), usn, vrs
+	} else if uid, usn, vrs, exp, _, _, valid := parseToken([]byte(signingKey), token); valid && exp >= time.Now().Unix() {
+		ownerID, appID, userID, username, vars = uid.String(), vrs[DEVAPP_CTX_APP_ID], uid.String(), usn, vrs
+	} else {
+		return nil, "", "", nil, false
+	}
+	if appID == "" {
+		return nil, "", "", nil, false
+	}
+
+	apps := &DeveloperApplications{}
+	if err := StorableRead(ctx, api.nk, ownerID, apps, false); err != nil {
+		return nil, "", "", nil, false
+	}
+	app = apps.Get(appID)
+	if app == nil {
+		return nil, "", "", nil, false
+	}
+	// Reissuing the token revokes the previous one.
+	if isAppToken && app.Token != token {
+		return nil, "", "", nil, false
+	}
+	return app, userID, username, vars, true
 }
 
 func NewAppAPIAcceptor(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, initializer runtime.Initializer) func(http.ResponseWriter, *http.Request) {
 	appAPI := NewAppAPI(ctx, logger, db, nk, initializer)
 
+	go func() {
+		ticker := time.NewTicker(appAPILimiterPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := appAPI.pruneLimiters(ctx); err != nil {
+					logger.WithField("error", err).Warn("Failed to prune application rate limiters")
+				}
+			}
+		}
+	}()
+
 	_nk := nk.(*RuntimeGoNakamaModule)
 	signingKey := _nk.config.GetSession().EncryptionKey
 	config := _nk.config
@@ -77,43 +211,45 @@ func NewAppAPIAcceptor(ctx context.Context, logger runtime.Logger, db *sql.DB, n
 			http.Error(w, authErrorBody, 401)
 			return
 		}
-		userID, username, vars, expiry, _, _, ok := parseToken([]byte(signingKey), token)
-		if !ok || expiry < time.Now().Unix() {
+		app, userID, username, vars, ok := appAPI.authenticate(r.Context(), signingKey, token)
+		if !ok {
 			http.Error(w, authErrorBody, 401)
 			return
 		}
 
+		if !appAPI.Allow(app) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Minute.Seconds())/app.RequestsPerMinute()+1))
+			_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Rate limit exceeded"}, http.StatusTooManyRequests)
+			return
+		}
+
 		// Parse out the IP and port (IP:port) from the RemoteAddr
 		clientIP, clientPort, err := net.SplitHostPort(r.RemoteAddr)
 		if err != nil {
 			clientIP = r.RemoteAddr
 			clientPort = ""
 		}
-		appID, ok := vars[DEVAPP_CTX_APP_ID]
+		ctx := NewDeveloperAppContext(r.Context(), node, "", env, r.Header, r.URL.Query(), userID, username, vars, clientIP, clientPort, app.ID.String())
+		ctx = context.WithValue(ctx, DEVAPP_CTX_APP, app) //nolint:staticcheck
+
+		// Get the route for the api path
+		route, ok := appAPI.Route(r.URL.Path)
 		if !ok {
-			http.Error(w, authErrorBody, 401)
+			_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Not Found"}, http.StatusNotFound)
+			return
 		}
-		ctx := NewDeveloperAppContext(r.Context(), node, "", env, r.Header, r.URL.Query(), userID.String(), username, vars, clientIP, clientPort, appID)
 
-		// Get the api path from the request
-		path := r.URL.Path
+		if r.Method != http.MethodGet {
+			_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Method Not Allowed"}, http.StatusMethodNotAllowed)
+			return
+		}
 
-		// Get the handler function for the api path
-		handlerFn, ok := appAPI.handlers[path]
-		if !ok {
-			http.Error(w, "Not Found", 404)
+		if route.Scope != "" && !app.HasScope(route.Scope) {
+			_ = RESTError(w, APIErrorMessage{Code: ErrCodeMissingAccess, Message: "Application is missing the " + route.Scope + " scope"}, http.StatusForbidden)
 			return
 		}
 
 		// Call the handler function
-		handlerFn(ctx, w, r)
+		route.Handler(ctx, w, r)
 	}
 }
-
-// func(http.ResponseWriter, *http.Request) handler
-func (api *AppAPI) handlerFn(ctx context.Context, w http.ResponseWriter, req *http.Request) {
-
-	// Respond to the request
-	w.Write([]byte("Hello, World!"))
-
-}
@@ -13,3 +13,13 @@ func RESTError(w http.ResponseWriter, message any, responseCode int) error {
 	http.Error(w, string(data), responseCode)
 	return nil
 }
+
+func RESTResponse(w http.ResponseWriter, response any) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(data)
+	return err
+}
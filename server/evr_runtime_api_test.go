@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppAPI_Route(t *testing.T) {
+	api := NewAppAPI(context.Background(), nil, nil, nil, nil)
+
+	tests := []struct {
+		path  string
+		scope string
+		found bool
+	}{
+		{"/apievr/v1", "", true},
+		{"/apievr/v1/", "", true},
+		{"/apievr/v1/players", AppScopePlayersRead, true},
+		{"/apievr/v1/players/", AppScopePlayersRead, true},
+		{"/apievr/v1/players/statistics", AppScopeStatisticsRead, true},
+		{"/apievr/v1/matches", AppScopeMatchesRead, true},
+		{"/apievr/v1/guilds", AppScopeGuildsRead, true},
+		{"/apievr/v1/unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			route, ok := api.Route(tt.path)
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.scope, route.Scope)
+		})
+	}
+}
+
+func TestAppAPI_Allow(t *testing.T) {
+	api := NewAppAPI(context.Background(), nil, nil, nil, nil)
+	app := &DeveloperApplication{ID: uuid.Must(uuid.NewV4()), RateLimit: 3}
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, api.Allow(app), "request %d should be allowed", i)
+	}
+	assert.False(t, api.Allow(app), "the burst should be exhausted")
+
+	// Other applications have their own limit.
+	assert.True(t, api.Allow(&DeveloperApplication{ID: uuid.Must(uuid.NewV4())}))
+
+	// Raising the limit takes effect immediately.
+	app.RateLimit = 10
+	assert.True(t, api.Allow(app))
+
+	// Concurrent first requests share one limiter, so the burst is only granted once.
+	concurrent := &DeveloperApplication{ID: uuid.Must(uuid.NewV4()), RateLimit: 5}
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if api.Allow(concurrent) {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, allowed.Load(), int32(5))
+
+	// The limiters of deleted applications are dropped.
+	api.dropLimiters(map[string]bool{app.ID.String(): true})
+	assert.Len(t, api.limiters, 1)
+	assert.Contains(t, api.limiters, app.ID.String())
+}
+
+func TestDeveloperApplication_Token(t *testing.T) {
+	signingKey := "signingkey"
+	ownerID := uuid.Must(uuid.NewV4())
+	app := DeveloperApplication{
+		ID:            uuid.Must(uuid.NewV4()),
+		TokenIssuedAt: time.Now(),
+		Scopes:        []string{AppScopeMatchesRead},
+	}
+
+	token := NewDeveloperApplicationToken(app, ownerID.String(), signingKey)
+	appID, userID, _, _, _, _, _, ok := parseApplicationToken([]byte(signingKey), token)
+	require.True(t, ok)
+	assert.Equal(t, app.ID, appID)
+	assert.Equal(t, ownerID, userID)
+
+	_, _, _, _, _, _, _, ok = parseApplicationToken([]byte("otherkey"), token)
+	assert.False(t, ok)
+
+	assert.True(t, app.HasScope(AppScopeMatchesRead))
+	assert.False(t, app.HasScope(AppScopePlayersRead))
+	assert.Equal(t, DeveloperApplicationDefaultRateLimit, app.RequestsPerMinute())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -15,12 +16,31 @@ const (
 	StorageCollectionDeveloper     = "Developer"
 	StorageKeyApplications         = "applications"
 	StorageIndexDeveloperAppTokens = "developerApplicationTokens"
+
+	DeveloperApplicationDefaultRateLimit = 60 // requests per minute
+)
+
+// Developer application scopes
+const (
+	AppScopePlayersRead    = "players:read"
+	AppScopeMatchesRead    = "matches:read"
+	AppScopeStatisticsRead = "statistics:read"
+	AppScopeGuildsRead     = "guilds:read"
 )
 
 type DeveloperApplications struct {
 	Applications []DeveloperApplication `json:"Applications"`
 }
 
+func (d *DeveloperApplications) Get(appID string) *DeveloperApplication {
+	for i := range d.Applications {
+		if d.Applications[i].ID.String() == appID {
+			return &d.Applications[i]
+		}
+	}
+	return nil
+}
+
 func (d *DeveloperApplications) StorageMeta() StorableMetadata {
 	return StorableMetadata{
 		Collection:      StorageCollectionDeveloper,
@@ -52,12 +72,27 @@ type DeveloperApplication struct {
 	Description   string    `json:"description"`
 	Token         string    `json:"token"`
 	TokenIssuedAt time.Time `json:"token_issued_at"`
+	Scopes        []string  `json:"scopes,omitempty"`                // The API scopes granted to the application
+	RateLimit     int       `json:"rate_limit_per_minute,omitempty"` // 0 is the default limit
+}
+
+func (a *DeveloperApplication) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
+
+// RequestsPerMinute returns the application's rate limit.
+func (a *DeveloperApplication) RequestsPerMinute() int {
+	if a.RateLimit <= 0 {
+		return DeveloperApplicationDefaultRateLimit
+	}
+	return a.RateLimit
 }
 
-func NewDeveloperApplicationToken(app DeveloperApplication, signingKey string) string {
+// NewDeveloperApplicationToken issues a token for the application, owned by the user that stores it.
+func NewDeveloperApplicationToken(app DeveloperApplication, ownerID, signingKey string) string {
 	tokenID := uuid.Must(uuid.NewV4()).String()
 
-	token, _ := generateDeveloperTokenWithExpiry(signingKey, app.ID.String(), tokenID, app.TokenIssuedAt.Unix(), "", "", nil, time.Now().Add(time.Hour*24*365*10))
+	token, _ := generateDeveloperTokenWithExpiry(signingKey, app.ID.String(), tokenID, app.TokenIssuedAt.Unix(), ownerID, "", nil, time.Now().Add(time.Hour*24*365*10))
 	return token
 }
 
@@ -119,7 +154,8 @@ func (stc *ApplicationTokenClaims) GetIssuedAt() (*jwt.NumericDate, error) {
 
 func (stc *ApplicationTokenClaims) GetNotBefore() (*jwt.NumericDate, error) {
 	if stc.NotBefore == 0 {
-		return nil, jwt.ErrTokenNotValidYet
+		// The claim is optional.
+		return nil, nil
 	}
 	return jwt.NewNumericDate(time.Unix(stc.NotBefore, 0)), nil
 }
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errAppAPIUserNotFound = errors.New("user not found")
+
+type AppAPIApplication struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Scopes            []string `json:"scopes"`
+	RequestsPerMinute int      `json:"requests_per_minute"`
+}
+
+type AppAPIPlayer struct {
+	UserID      string    `json:"user_id"`
+	DiscordID   string    `json:"discord_id,omitempty"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	CreateTime  time.Time `json:"create_time"`
+}
+
+type AppAPIGuild struct {
+	ID                     string `json:"id"`
+	GuildID                string `json:"guild_id"`
+	Provider               string `json:"provider"`
+	Name                   string `json:"name"`
+	Description            string `json:"description"`
+	MemberCount            int    `json:"member_count"`
+	MembersOnlyMatchmaking bool   `json:"members_only_matchmaking"`
+}
+
+type AppAPIMatchList struct {
+	Matches []*MatchLabel `json:"matches"`
+}
+
+// applicationHandler describes the calling application.
+func (api *AppAPI) applicationHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	app, _ := ctx.Value(DEVAPP_CTX_APP).(*DeveloperApplication)
+	if app == nil {
+		_ = RESTError(w, APIErrorMessage{Code: ErrCodeUnknownApplication, Message: "Unknown application"}, http.StatusUnauthorized)
+		return
+	}
+	scopes := app.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	_ = RESTResponse(w, AppAPIApplication{
+		ID:                app.ID.String(),
+		Name:              app.Name,
+		Scopes:            scopes,
+		RequestsPerMinute: app.RequestsPerMinute(),
+	})
+}
+
+// resolveUserID returns the user ID from the user_id, discord_id or xpid query parameter.
+func (api *AppAPI) resolveUserID(ctx context.Context, q url.Values) (string, error) {
+	switch {
+	case q.Get("user_id") != "":
+		userID, err := uuid.FromString(q.Get("user_id"))
+		if err != nil {
+			return "", errAppAPIUserNotFound
+		}
+		return userID.String(), nil
+	case q.Get("discord_id") != "":
+		userID, err := GetUserIDByDiscordID(ctx, api.db, q.Get("discord_id"))
+		if errors.Is(err, ErrAccountNotFound) {
+			return "", errAppAPIUserNotFound
+		}
+		return userID, err
+	case q.Get("xpid") != "":
+		xpid, err := evr.ParseEvrId(q.Get("xpid"))
+		if err != nil {
+			return "", errAppAPIUserNotFound
+		}
+		userID, err := GetUserIDByDeviceID(ctx, api.db, xpid.String())
+		if status.Code(err) == codes.NotFound || (err == nil && userID == "") {
+			return "", errAppAPIUserNotFound
+		}
+		return userID, err
+	}
+	return "", errAppAPIUserNotFound
+}
+
+// resolveGroupID returns the group ID from the group_id or guild_id query parameter.
+func (api *AppAPI) resolveGroupID(ctx context.Context, q url.Values) (string, error) {
+	switch {
+	case q.Get("group_id") != "":
+		groupID, err := uuid.FromString(q.Get("group_id"))
+		if err != nil {
+			return "", runtime.ErrGroupNotFound
+		}
+		return groupID.String(), nil
+	case q.Get("guild_id") != "":
+		groupID, err := GetGroupIDByGuildID(ctx, api.db, q.Get("guild_id"))
+		if status.Code(err) == codes.NotFound {
+			return "", runtime.ErrGroupNotFound
+		}
+		return groupID, err
+	}
+	return "", runtime.ErrGroupNotFound
+}
+
+func (api *AppAPI) userError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errAppAPIUserNotFound) {
+		_ = RESTError(w, APIErrorMessage{Code: ErrCodeUnknownUser, Message: "Unknown user"}, http.StatusNotFound)
+		return
+	}
+	api.logger.WithField("error", err).Warn("Failed to resolve user")
+	_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Internal error"}, http.StatusInternalServerError)
+}
+
+func (api *AppAPI) groupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, runtime.ErrGroupNotFound) {
+		_ = RESTError(w, APIErrorMessage{Code: ErrCodeUnknownGuild, Message: "Unknown guild"}, http.StatusNotFound)
+		return
+	}
+	api.logger.WithField("error", err).Warn("Failed to resolve guild group")
+	_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Internal error"}, http.StatusInternalServerError)
+}
+
+// playerHandler looks up a player by user ID, Discord ID or XPID.
+func (api *AppAPI) playerHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	userID, err := api.resolveUserID(ctx, r.URL.Query())
+	if err != nil {
+		api.userError(w, err)
+		return
+	}
+	account, err := api.nk.AccountGetId(ctx, userID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			err = errAppAPIUserNotFound
+		}
+		api.userError(w, err)
+		return
+	}
+	_ = RESTResponse(w, AppAPIPlayer{
+		UserID:      account.GetUser().GetId(),
+		DiscordID:   account.GetCustomId(),
+		Username:    account.GetUser().GetUsername(),
+		DisplayName: account.GetUser().GetDisplayName(),
+		CreateTime:  account.GetUser().GetCreateTime().AsTime(),
+	})
+}
+
+// playerStatisticsHandler returns the player's statistics in a guild group.
+func (api *AppAPI) playerStatisticsHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, err := api.resolveUserID(ctx, q)
+	if err != nil {
+		api.userError(w, err)
+		return
+	}
+	groupID, err := api.resolveGroupID(ctx, q)
+	if err != nil {
+		api.groupError(w, err)
+		return
+	}
+
+	mode := evr.ModeArenaPublic
+	if s := q.Get("mode"); s != "" {
+		mode = evr.ToSymbol(s)
+	}
+
+	stats, boardMap, err := PlayerStatisticsGetID(ctx, api.db, api.nk, userID, groupID, []evr.Symbol{mode}, mode)
+	if err != nil {
+		api.logger.WithField("error", err).Warn("Failed to get player statistics")
+		_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Failed to get player statistics"}, http.StatusInternalServerError)
+		return
+	}
+	_ = RESTResponse(w, PlayerStatisticsResponse{Stats: stats, Derived: DerivedStatisticValues(DerivedStatistics(), boardMap)})
+}
+
+// matchListHandler lists the public view of the public matches, optionally filtered by group and mode.
+func (api *AppAPI) matchListHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	qparts := make([]string, 0, 2)
+	if q.Get("group_id") != "" || q.Get("guild_id") != "" {
+		groupID, err := api.resolveGroupID(ctx, q)
+		if err != nil {
+			api.groupError(w, err)
+			return
+		}
+		qparts = append(qparts, fmt.Sprintf("+label.group_id:%s", Query.QuoteStringValue(groupID)))
+	}
+	if s := q.Get("mode"); s != "" {
+		qparts = append(qparts, fmt.Sprintf("+label.mode:%s", Query.QuoteStringValue(evr.ToSymbol(s).String())))
+	}
+	query := "*"
+	if len(qparts) > 0 {
+		query = strings.Join(qparts, " ")
+	}
+
+	minSize := 1
+	matches, err := api.nk.MatchList(ctx, 1000, true, "", &minSize, nil, query)
+	if err != nil {
+		api.logger.WithField("error", err).Warn("Failed to list matches")
+		_ = RESTError(w, APIErrorMessage{Code: ErrCodeGeneralError, Message: "Failed to list matches"}, http.StatusInternalServerError)
+		return
+	}
+
+	response := AppAPIMatchList{Matches: make([]*MatchLabel, 0, len(matches))}
+	for _, match := range matches {
+		label := &MatchLabel{}
+		if err := json.Unmarshal([]byte(match.GetLabel().GetValue()), label); err != nil {
+			continue
+		}
+		if !label.IsPublic() {
+			continue
+		}
+		response.Matches = append(response.Matches, label.PublicView())
+	}
+	_ = RESTResponse(w, response)
+}
+
+// guildHandler returns the public information of a guild group.
+func (api *AppAPI) guildHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	groupID, err := api.resolveGroupID(ctx, r.URL.Query())
+	if err != nil {
+		api.groupError(w, err)
+		return
+	}
+	if groups, err := api.nk.GroupsGetId(ctx, []string{groupID}); err != nil {
+		api.groupError(w, err)
+		return
+	} else if len(groups) == 0 || groups[0].GetLangTag() != GuildGroupLangTag {
+		api.groupError(w, runtime.ErrGroupNotFound)
+		return
+	}
+	gg, err := GuildGroupLoad(ctx, api.nk, groupID)
+	if err != nil {
+		api.groupError(w, err)
+		return
+	}
+	_ = RESTResponse(w, AppAPIGuild{
+		ID:                     gg.IDStr(),
+		GuildID:                gg.GuildID,
+		Provider:               gg.ProviderName(),
+		Name:                   gg.Name(),
+		Description:            gg.Description(),
+		MemberCount:            gg.Size(),
+		MembersOnlyMatchmaking: gg.EnableMembersOnlyMatchmaking,
+	})
+}
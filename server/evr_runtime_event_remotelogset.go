@@ -118,6 +118,7 @@ func (s *EventRemoteLogSet) Process(ctx context.Context, logger runtime.Logger,
 
 	// Collect the post-match messages for processing
 	postMatchMessages := make(map[uuid.UUID][]evr.RemoteLog)
+	serverFaults := make(map[uuid.UUID]string)
 
 	for _, e := range entries {
 		logger := logger.WithField("message_type", fmt.Sprintf("%T", e))
@@ -146,6 +147,9 @@ func (s *EventRemoteLogSet) Process(ctx context.Context, logger runtime.Logger,
 				"msg":      msg,
 			}).Warn("Disconnected due to timeout")
 
+			// The early quit penalty does not apply to players that lost the server.
+			serverFaults[msg.SessionUUID()] = fmt.Sprintf("disconnected due to timeout (%.0fs since last received, %.0f%% dropped)", msg.TimeSinceLastReceived, msg.DropPercent)
+
 		case *evr.RemoteLogUserDisconnected:
 
 			if !msg.GameInfoIsArena || msg.GameInfoIsPrivate {
@@ -374,6 +378,8 @@ func (s *EventRemoteLogSet) Process(ctx context.Context, logger runtime.Logger,
 				"msg":      msg,
 			}).Warn("Server connection failed")
 
+			serverFaults[msg.SessionUUID()] = "server connection failed"
+
 			acct, err := nk.AccountGetId(ctx, label.GameServer.OperatorID.String())
 			if err != nil {
 				logger.WithField("error", err).Warn("Failed to get account")
@@ -451,6 +457,14 @@ func (s *EventRemoteLogSet) Process(ctx context.Context, logger runtime.Logger,
 		}
 	}
 
+	for sessionUUID, reason := range serverFaults {
+		report, err := json.Marshal(MatchServerFaultReport{Reason: reason})
+		if err != nil {
+			continue
+		}
+		matchRegistry.SendData(sessionUUID, s.Node, uuid.FromStringOrNil(s.UserID), uuid.FromStringOrNil(s.SessionID), s.Username, s.Node, OpCodeMatchServerFault, report, false, time.Now().Unix())
+	}
+
 	updates.Range(func(key uuid.UUID, value *MatchGameStateUpdate) bool {
 		matchRegistry.SendData(key, s.Node, uuid.FromStringOrNil(s.UserID), uuid.FromStringOrNil(s.SessionID), s.Username, s.Node, OpCodeMatchGameStateUpdate, value.Bytes(), false, time.Now().Unix())
 		return true
@@ -542,6 +556,8 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 	})
 
 	allStatEntries := make([]*StatisticsQueueEntry, 0)
+	ratedAt := time.Now().UTC().Format(time.RFC3339) // Marks the ratings as set by a match
+	ladderSettings := RankedLadderSettingsGet()
 
 	// Load individual player ratings from leaderboards for MMR calculation
 	// This is necessary because label.Players contains matchmaking ratings (aggregate for parties)
@@ -551,8 +567,10 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 	copy(playersWithTeamRatings, label.Players)
 	copy(playersWithPlayerRatings, label.Players)
 
+	// The ratings are kept while the ladder is on, because it follows them.
 	serviceSettings := ServiceSettings()
-	if serviceSettings.UseSkillBasedMatchmaking() {
+	rated := serviceSettings.UseSkillBasedMatchmaking() || ladderSettings.Applies(label.Mode)
+	if rated {
 		for i, p := range playersWithTeamRatings {
 			// Only load ratings for competitors (blue/orange team)
 			if !p.IsCompetitor() {
@@ -600,12 +618,12 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 	// Calculate new ratings once for all players (before the loop to avoid O(n²) complexity)
 	var teamRatings map[string]types.Rating
 	var playerRatings map[string]types.Rating
-	if serviceSettings.UseSkillBasedMatchmaking() {
+	if rated {
 		// Calculate new team-based ratings using individual player ratings loaded from leaderboards
-		teamRatings = CalculateNewTeamRatings(playersWithTeamRatings, statsByPlayer, blueWins)
+		teamRatings = CalculateNewTeamRatingsForMode(groupIDStr, label.Mode, playersWithTeamRatings, statsByPlayer, blueWins, nil)
 
 		// Calculate new individual player ratings using individual player ratings loaded from leaderboards
-		playerRatings = CalculateNewIndividualRatings(playersWithPlayerRatings, statsByPlayer, blueWins)
+		playerRatings = CalculateNewIndividualRatingsForMode(groupIDStr, label.Mode, playersWithPlayerRatings, statsByPlayer, blueWins, nil)
 	}
 
 	for xpid, typeStats := range statsByPlayer {
@@ -634,7 +652,7 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 			logger.WithField("error", err).Warn("Failed to increment completed matches")
 		}
 
-		if serviceSettings.UseSkillBasedMatchmaking() {
+		if rated {
 
 			// Use the pre-calculated team ratings for this player
 			if rating, ok := teamRatings[playerInfo.SessionID]; ok {
@@ -656,7 +674,7 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 						DisplayName: playerInfo.DisplayName,
 						Score:       muScore,
 						Subscore:    muSubscore,
-						Metadata:    map[string]string{"discord_id": playerInfo.DiscordID},
+						Metadata:    map[string]string{"discord_id": playerInfo.DiscordID, RatingRatedAtMetadataKey: ratedAt},
 					})
 				}
 
@@ -680,6 +698,14 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 						Metadata:    map[string]string{"discord_id": playerInfo.DiscordID},
 					})
 				}
+
+				// The visible ladder follows the team rating.
+				if ladderSettings.Applies(label.Mode) {
+					won := (playerInfo.Team == BlueTeam) == blueWins
+					if entry := rankedLadderUpdate(ctx, logger, nk, ladderSettings, groupIDStr, label.Mode, playerInfo, won, rating); entry != nil {
+						allStatEntries = append(allStatEntries, entry)
+					}
+				}
 			} else {
 				logger.WithField("target_sid", playerInfo.SessionID).Warn("No team rating found for player in matchmaking ratings")
 			}
@@ -703,7 +729,7 @@ func (s *EventRemoteLogSet) processPostMatchMessages(ctx context.Context, logger
 						DisplayName: playerInfo.DisplayName,
 						Score:       muScore,
 						Subscore:    muSubscore,
-						Metadata:    map[string]string{"discord_id": playerInfo.DiscordID},
+						Metadata:    map[string]string{"discord_id": playerInfo.DiscordID, RatingRatedAtMetadataKey: ratedAt},
 					})
 				}
 
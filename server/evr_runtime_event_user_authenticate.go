@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"slices"
@@ -21,6 +22,9 @@ type EventUserAuthenticated struct {
 	ClientIP                 string            `json:"client_ip"`
 	LoginPayload             *evr.LoginProfile `json:"login_data"`
 	IsWebSocketAuthenticated bool              `json:"is_websocket_authenticated"`
+	ASN                      int               `json:"asn,omitempty"`         // The network of the client IP
+	IsVPN                    bool              `json:"is_vpn,omitempty"`      // The client IP is a VPN or proxy
+	CarrierNAT               bool              `json:"carrier_nat,omitempty"` // The client IP is on a mobile network
 }
 
 func NewUserAuthenticatedEvent(userID string, xpid evr.EvrId, clientIP string, loginPayload *evr.LoginProfile, isWebSocketAuthenticated bool) *EventUserAuthenticated {
@@ -52,9 +56,10 @@ func (e *EventUserAuthenticated) Process(ctx context.Context, logger runtime.Log
 
 	// Update the last used time for their ip
 	isNew, allowed := loginHistory.Update(e.XPID, e.ClientIP, e.LoginPayload, e.IsWebSocketAuthenticated)
+	loginHistory.UpdateNetwork(e.XPID, e.ClientIP, e.ASN, e.IsVPN, e.CarrierNAT)
 
 	if allowed && isNew {
-		if err := SendIPAuthorizationNotification(dg, userID, e.ClientIP); err != nil {
+		if err := SendIPAuthorizationNotification(dg, userID, e.ClientIP); err != nil && !errors.Is(err, ErrDiscordDisabled) {
 			// Log the error, but don't return it.
 			logger.WithField("error", err).Warn("Failed to send IP authorization notification")
 		}
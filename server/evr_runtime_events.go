@@ -92,6 +92,7 @@ func NewEventDispatch(ctx context.Context, logger runtime.Logger, db *sql.DB, nk
 		&EventVRMLAccountLink{},
 		&EventRemoteLogSet{},
 		&EventServerProfileUpdate{},
+		&EventBracketMatchEnded{},
 	})
 
 	go func() {
@@ -178,6 +179,14 @@ func (h *EventDispatcher) processEvent(ctx context.Context, logger runtime.Logge
 		EventSessionEnd:             h.eventSessionEnd,
 		EventLobbySessionAuthorized: h.handleLobbyAuthorized,
 		EventMatchData:              h.handleMatchEvent,
+
+		// The match telemetry is for the event sinks (e.g. the metrics pipeline).
+		EventMatchStarted:       h.handleMatchTelemetry,
+		EventPlayerConnected:    h.handleMatchTelemetry,
+		EventPlayerDisconnected: h.handleMatchTelemetry,
+		EventPlayerReconnected:  h.handleMatchTelemetry,
+		EventScoreEvent:         h.handleMatchTelemetry,
+		EventMatchEnded:         h.handleMatchTelemetry,
 	}
 
 	if _, ok := eventMap[evt.Name]; !ok {
@@ -220,6 +229,11 @@ func (h *EventDispatcher) eventSessionStart(ctx context.Context, logger runtime.
 	return nil
 }
 
+func (h *EventDispatcher) handleMatchTelemetry(ctx context.Context, logger runtime.Logger, evt *api.Event) error {
+	logger.Debug("process match telemetry event: %+v", evt.Properties)
+	return nil
+}
+
 func (h *EventDispatcher) eventSessionEnd(ctx context.Context, logger runtime.Logger, evt *api.Event) error {
 	h.Lock()
 	defer h.Unlock()
@@ -414,7 +428,8 @@ func AuditLogSendGuild(dg *discordgo.Session, gg *GuildGroup, message string) (*
 }
 
 func AuditLogSend(dg *discordgo.Session, channelID, content string) error {
-	if content == "" {
+	if content == "" || dg == nil {
+		// Audit channels are disabled without a Discord session.
 		return nil
 	}
 	// replace all <@uuid> mentions with <@discordID>
@@ -492,12 +507,7 @@ func ScheduleKick(ctx context.Context, nk runtime.NakamaModule, logger runtime.L
 			}
 		}
 
-		suspendedGroupIDs := []string{
-			guildGroup.Group.Id,
-		}
-		if guildGroup.SuspensionInheritanceGroupIDs != nil {
-			suspendedGroupIDs = append(suspendedGroupIDs, guildGroup.SuspensionInheritanceGroupIDs...)
-		}
+		suspendedGroupIDs := globalGuildGroupRegistry.Load().SuspensionGroupIDs(guildGroup)
 
 		actions := make([]string, 0, len(matchLabels))
 		doDisconnect := false
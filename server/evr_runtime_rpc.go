@@ -61,6 +61,17 @@ func (t *TimeRFC3339) UnmarshalText(data []byte) error {
 	return nil
 }
 
+type MatchListPublicRPCResponse struct {
+	Uptime                      int64                     `json:"uptime_mins"`
+	UpdateTime                  TimeRFC3339               `json:"update_time"`
+	LobbySessionCount           int                       `json:"lobby_session_count"`
+	GameServerCount             int                       `json:"gameserver_count"`
+	PlayerCount                 int                       `json:"player_count"`
+	MatchmakingTicketsByGroupID map[string]map[string]int `json:"active_matchmaking_counts"`
+	Labels                      []*MatchLabel             `json:"labels"`
+	GameServers                 []*GameServerPresence     `json:"gameservers"`
+}
+
 func (h *RPCHandler) MatchListPublicRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	// The public view is cached.
 	cachedResponse, _, found := h.responseCache.Get("match:public")
@@ -155,16 +166,7 @@ func (h *RPCHandler) MatchListPublicRPC(ctx context.Context, logger runtime.Logg
 		return labels[i].StartTime.After(labels[j].StartTime)
 	})
 
-	response := struct {
-		Uptime                      int64                     `json:"uptime_mins"`
-		UpdateTime                  TimeRFC3339               `json:"update_time"`
-		LobbySessionCount           int                       `json:"lobby_session_count"`
-		GameServerCount             int                       `json:"gameserver_count"`
-		PlayerCount                 int                       `json:"player_count"`
-		MatchmakingTicketsByGroupID map[string]map[string]int `json:"active_matchmaking_counts"`
-		Labels                      []*MatchLabel             `json:"labels"`
-		GameServers                 []*GameServerPresence     `json:"gameservers"`
-	}{
+	response := MatchListPublicRPCResponse{
 		Uptime:                      int64(time.Since(nakamaStartTime).Minutes()),
 		UpdateTime:                  TimeRFC3339(time.Now().UTC()),
 		Labels:                      labels,
@@ -332,11 +334,18 @@ func MatchRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime
 	return response.String(), nil
 }
 
+// RPCSuccessResponse is returned by RPCs that only report success.
+type RPCSuccessResponse struct {
+	Success bool `json:"success"`
+}
+
+type KickPlayerRPCRequest struct {
+	UserID string `json:"user_id"`
+}
+
 func KickPlayerRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 
-	request := struct {
-		UserID string `json:"user_id"`
-	}{}
+	request := KickPlayerRPCRequest{}
 
 	if err := json.Unmarshal([]byte(payload), &request); err != nil {
 		return "", err
@@ -1622,6 +1631,11 @@ func StreamJoinRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk ru
 	return response.String(), nil
 }
 
+type MatchmakerCandidatesRPCResponse struct {
+	Candidates [][]runtime.MatchmakerEntry `json:"candidates"`
+	Matches    [][]runtime.MatchmakerEntry `json:"matches"`
+}
+
 func MatchmakerCandidatesRPCFactory(sbmm *SkillBasedMatchmaker) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
@@ -1642,9 +1656,9 @@ func MatchmakerCandidatesRPCFactory(sbmm *SkillBasedMatchmaker) func(ctx context
 			candidates = candidates[:10000]
 
 		}
-		response := map[string][][]runtime.MatchmakerEntry{
-			"candidates": candidates,
-			"matches":    matches,
+		response := MatchmakerCandidatesRPCResponse{
+			Candidates: candidates,
+			Matches:    matches,
 		}
 
 		data, err := json.Marshal(response)
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type AlternateScoresRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type AlternateScoresResponse struct {
+	UserID string            `json:"user_id"`
+	Scores []*AlternateScore `json:"scores"` // Highest first
+}
+
+type AlternateReviewsRequest struct {
+	Status AlternateReviewStatus `json:"status,omitempty"` // Defaults to pending.
+	Limit  int                   `json:"limit,omitempty"`  // Defaults to 50.
+	Cursor string                `json:"cursor,omitempty"`
+}
+
+type AlternateReviewsResponse struct {
+	Reviews []*AlternateReview `json:"reviews"` // Highest score first
+	Cursor  string             `json:"cursor,omitempty"`
+}
+
+type AlternateReviewRequest struct {
+	UserID      string                `json:"user_id"`
+	OtherUserID string                `json:"other_user_id"`
+	Verdict     AlternateReviewStatus `json:"verdict"` // confirmed or dismissed
+	Notes       string                `json:"notes,omitempty"`
+}
+
+type AlternateReviewResponse struct {
+	Review *AlternateReview `json:"review"`
+}
+
+// AlternateScoresRPC explains the scores of a player's alternates, as they would be scored now.
+func AlternateScoresRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalOperators); err != nil {
+		return "", err
+	}
+	request := &AlternateScoresRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if uuid.FromStringOrNil(request.UserID).IsNil() {
+		return "", runtime.NewError("a valid user_id is required", StatusInvalidArgument)
+	}
+
+	history := NewLoginHistory(request.UserID)
+	if err := StorableRead(ctx, nk, request.UserID, history, false); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return "", runtime.NewError("login history not found", StatusNotFound)
+		}
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	scores, _, err := AlternateScoresLoad(ctx, logger, nk, request.UserID, history.AlternateMatches, AlternateScoringSettingsGet(), time.Now().UTC())
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+
+	response := AlternateScoresResponse{
+		UserID: request.UserID,
+		Scores: make([]*AlternateScore, 0, len(scores)),
+	}
+	for _, score := range scores {
+		response.Scores = append(response.Scores, score)
+	}
+	slices.SortFunc(response.Scores, func(a, b *AlternateScore) int {
+		if a.Score != b.Score {
+			return cmp.Compare(b.Score, a.Score)
+		}
+		return cmp.Compare(a.OtherUserID, b.OtherUserID)
+	})
+	return configResourceResponse(response)
+}
+
+// AlternateReviewsRPC lists the review queue of alternate links.
+func AlternateReviewsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalOperators); err != nil {
+		return "", err
+	}
+	request := &AlternateReviewsRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	switch request.Status {
+	case "":
+		request.Status = AlternateReviewPending
+	case AlternateReviewPending, AlternateReviewConfirmed, AlternateReviewDismissed:
+	default:
+		return "", runtime.NewError("status must be pending, confirmed or dismissed", StatusInvalidArgument)
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 50
+	}
+
+	reviews, cursor, err := AlternateReviewsList(ctx, nk, request.Status, request.Limit, request.Cursor)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return configResourceResponse(AlternateReviewsResponse{Reviews: reviews, Cursor: cursor})
+}
+
+// AlternateReviewRPC confirms or dismisses the link between two accounts. The verdict overrides the link's score,
+// and weighs the items it was based on in the scoring of other links.
+func AlternateReviewRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalOperators); err != nil {
+		return "", err
+	}
+	request := &AlternateReviewRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if uuid.FromStringOrNil(request.UserID).IsNil() || uuid.FromStringOrNil(request.OtherUserID).IsNil() || request.UserID == request.OtherUserID {
+		return "", runtime.NewError("two valid user IDs are required", StatusInvalidArgument)
+	}
+
+	reviewerID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	review, err := AlternateReviewResolve(ctx, nk, request.UserID, request.OtherUserID, request.Verdict, reviewerID, request.Notes, time.Now().UTC())
+	switch {
+	case errors.Is(err, ErrAlternateReviewInvalidVerdict):
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	case err != nil && review != nil:
+		// The verdict is recorded; only the feedback, or the stored scores, are behind.
+		logger.WithField("error", err).Warn("Failed to apply alternate review verdict")
+	case errors.Is(err, ErrAlternateReviewNotFound), status.Code(err) == codes.NotFound:
+		return "", runtime.NewError("the accounts are not linked", StatusNotFound)
+	case err != nil:
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+
+	logger.WithFields(map[string]any{
+		"uid":      reviewerID,
+		"user_ids": review.UserIDs,
+		"verdict":  review.Status,
+		"evidence": review.Score,
+	}).Info("Alternate link reviewed")
+
+	return configResourceResponse(AlternateReviewResponse{Review: review})
+}
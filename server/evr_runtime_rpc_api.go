@@ -9,6 +9,10 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
+type GuildGroupRequest struct {
+	IDs string `json:"ids"` // Comma-separated group IDs
+}
+
 type GuildGroupResponse struct {
 	Groups []*GuildGroup `json:"guild_groups,omitempty"`
 }
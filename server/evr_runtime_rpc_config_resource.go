@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+type ConfigResourceRequest struct {
+	Type string `json:"type"`
+}
+
+type ConfigResourceSetRequest struct {
+	Type     string                  `json:"type"`
+	Default  json.RawMessage         `json:"default,omitempty"`
+	Variants []ConfigResourceVariant `json:"variants,omitempty"`
+	Note     string                  `json:"note,omitempty"`
+}
+
+type ConfigResourceRollbackRequest struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+type ConfigResourceResolveRequest struct {
+	Type        string `json:"type"`
+	UserID      string `json:"user_id,omitempty"`
+	GroupID     string `json:"group_id,omitempty"`
+	BuildNumber int64  `json:"build_number,omitempty"`
+	DeviceType  string `json:"device_type,omitempty"`
+}
+
+type ConfigResourceResolveResponse struct {
+	Type     string          `json:"type"`
+	Version  int             `json:"version"`            // 0 if the type has no versions.
+	Variant  string          `json:"variant,omitempty"`  // Empty if the legacy or built-in resource is served.
+	Resource json.RawMessage `json:"resource,omitempty"` // The stored resource, if one applies.
+}
+
+func configResourceResponse(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
+
+// ConfigResourceRPC returns the stored versions of a config type, oldest first.
+func ConfigResourceRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &ConfigResourceRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Type == "" {
+		return "", runtime.NewError("type is required", StatusInvalidArgument)
+	}
+	history, _, err := ConfigResourceHistoryRead(ctx, nk, request.Type)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return configResourceResponse(history)
+}
+
+// ConfigResourceSetRPC validates, and stores, a new version of a config type's resources.
+func ConfigResourceSetRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &ConfigResourceSetRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+
+	set := &ConfigResourceSet{
+		Type:      request.Type,
+		Default:   request.Default,
+		Variants:  request.Variants,
+		Note:      request.Note,
+		UpdatedBy: userID,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := set.Validate(); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+
+	history, version, err := ConfigResourceHistoryRead(ctx, nk, request.Type)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	history.Add(set)
+	if err := ConfigResourceHistoryWrite(ctx, nk, history, version); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{"type": set.Type, "version": set.Version, "variants": len(set.Variants), "uid": userID}).Info("Config resource updated")
+
+	return configResourceResponse(set)
+}
+
+// ConfigResourceRollbackRPC stores a copy of an earlier version as the next version.
+func ConfigResourceRollbackRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &ConfigResourceRollbackRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Type == "" {
+		return "", runtime.NewError("type is required", StatusInvalidArgument)
+	}
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+
+	history, version, err := ConfigResourceHistoryRead(ctx, nk, request.Type)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	set, err := history.Rollback(request.Version, userID, time.Now().UTC())
+	if errors.Is(err, ErrConfigResourceVersionNotFound) {
+		return "", runtime.NewError(err.Error(), StatusNotFound)
+	} else if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	if err := ConfigResourceHistoryWrite(ctx, nk, history, version); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{"type": set.Type, "version": set.Version, "rollback_to": request.Version, "uid": userID}).Info("Config resource rolled back")
+
+	return configResourceResponse(set)
+}
+
+// ConfigResourceResolveRPC returns the resource that a client would be served, for checking the targeting.
+func ConfigResourceResolveRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &ConfigResourceResolveRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Type == "" {
+		return "", runtime.NewError("type is required", StatusInvalidArgument)
+	}
+	history, _, err := ConfigResourceHistoryRead(ctx, nk, request.Type)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+
+	response := ConfigResourceResolveResponse{Type: request.Type}
+	if active := history.Active(); active != nil {
+		response.Version = active.Version
+		response.Resource, response.Variant = active.Select(ConfigResourceClient{
+			UserID:      request.UserID,
+			GroupID:     request.GroupID,
+			BuildNumber: evr.BuildNumber(request.BuildNumber),
+			DeviceType:  request.DeviceType,
+		})
+	}
+	return configResourceResponse(response)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type DocumentRequest struct {
+	Type     string `json:"type"`
+	Language string `json:"lang"`
+}
+
+type DocumentSetRequest struct {
+	Type          string `json:"type"`
+	Language      string `json:"lang"`
+	Title         string `json:"title,omitempty"`
+	Text          string `json:"text"`
+	TextGameAdmin string `json:"text_ga,omitempty"` // EULA only; defaults to the text.
+	Link          string `json:"link,omitempty"`
+}
+
+type DocumentResponse struct {
+	Document *GameDocument `json:"document"`
+	Pages    []string      `json:"pages"`             // The text, as the client shows it.
+	Changed  bool          `json:"changed,omitempty"` // document/set: the text changed, and the version was bumped.
+}
+
+func newDocumentResponse(document *GameDocument, changed bool) (string, error) {
+	return configResourceResponse(DocumentResponse{
+		Document: document,
+		Pages:    PaginateDocument(document.Text),
+		Changed:  changed,
+	})
+}
+
+// DocumentRPC returns a document, in the language or its nearest fallback, with its pages.
+func DocumentRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &DocumentRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if !slices.Contains(DocumentTypes, request.Type) {
+		return "", runtime.NewError(ErrDocumentUnknownType.Error(), StatusInvalidArgument)
+	}
+	document, _, err := GameDocumentRead(ctx, nk, request.Type, DocumentLanguageChain(request.Language))
+	if errors.Is(err, ErrDocumentNotFound) {
+		return "", runtime.NewError(err.Error(), StatusNotFound)
+	} else if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return newDocumentResponse(document, false)
+}
+
+// DocumentSetRPC stores a document in one language. The version is only bumped if the text changes, so that
+// the clients are not prompted to accept an unchanged EULA.
+func DocumentSetRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &DocumentSetRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if !slices.Contains(DocumentTypes, request.Type) {
+		return "", runtime.NewError(ErrDocumentUnknownType.Error(), StatusInvalidArgument)
+	}
+	if request.Language == "" {
+		return "", runtime.NewError("lang is required", StatusInvalidArgument)
+	}
+	if request.Text == "" {
+		return "", runtime.NewError("text is required", StatusInvalidArgument)
+	}
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+
+	// Only the exact language is edited; the fallbacks are left as they are.
+	document, version, err := GameDocumentRead(ctx, nk, request.Type, []string{request.Language})
+	if err != nil && !errors.Is(err, ErrDocumentNotFound) {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	if version == "*" {
+		document = &GameDocument{Type: request.Type, Lang: request.Language}
+	}
+
+	changed := document.Update(request.Title, request.Text, request.TextGameAdmin, request.Link, userID, time.Now().UTC())
+	if err := GameDocumentWrite(ctx, nk, document, version); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{"type": document.Type, "lang": document.Lang, "version": document.Version, "changed": changed, "uid": userID}).Info("Document updated")
+
+	return newDocumentResponse(document, changed)
+}
+
+// DocumentPreviewRPC returns the pages of the text, as the client would show them, without storing it.
+func DocumentPreviewRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &DocumentSetRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if !slices.Contains(DocumentTypes, request.Type) {
+		return "", runtime.NewError(ErrDocumentUnknownType.Error(), StatusInvalidArgument)
+	}
+	document := &GameDocument{
+		Type:          request.Type,
+		Lang:          request.Language,
+		Title:         request.Title,
+		Text:          request.Text,
+		TextGameAdmin: request.TextGameAdmin,
+		Link:          request.Link,
+	}
+	return newDocumentResponse(document, false)
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type EnforcementAppealRequest struct {
+	GroupID   string `json:"group_id"`            // The group ID or guild ID.
+	RecordID  string `json:"record_id,omitempty"` // The suspension; the active one that expires last if empty.
+	Statement string `json:"statement"`
+}
+
+type EnforcementAppealReviewRequest struct {
+	GroupID       string                       `json:"group_id"` // The group ID or guild ID.
+	UserID        string                       `json:"user_id"`  // The appellant.
+	RecordID      string                       `json:"record_id"`
+	Outcome       GuildEnforcementAppealStatus `json:"outcome"`                  // under_review to assign the caller, or upheld, reduced or overturned.
+	Notes         string                       `json:"notes,omitempty"`          // The reviewer's notes.
+	ReducedExpiry time.Time                    `json:"reduced_expiry,omitempty"` // The new expiry, for a reduction.
+}
+
+type EnforcementAppealResponse struct {
+	Appeal GuildEnforcementAppeal `json:"appeal"`
+	Record GuildEnforcementRecord `json:"record"`
+}
+
+// enforcementAppealGroupID resolves a group ID or guild ID.
+func enforcementAppealGroupID(ctx context.Context, db *sql.DB, groupID string) (string, error) {
+	if groupID == "" {
+		return "", runtime.NewError("group_id is required", StatusInvalidArgument)
+	}
+	if uuid.FromStringOrNil(groupID).IsNil() {
+		var err error
+		if groupID, err = GetGroupIDByGuildID(ctx, db, groupID); err != nil {
+			return "", runtime.NewError(err.Error(), StatusInternalError)
+		} else if groupID == "" {
+			return "", runtime.NewError("guild group not found", StatusNotFound)
+		}
+	}
+	return groupID, nil
+}
+
+// enforcerGuildGroup resolves the group, and checks that the caller is one of its enforcers, or a global operator.
+func enforcerGuildGroup(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, callerID, groupID string) (*GuildGroup, error) {
+	groupID, err := enforcementAppealGroupID(ctx, db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	gg, err := GuildGroupLoad(ctx, nk, groupID)
+	if err != nil {
+		return nil, runtime.NewError(err.Error(), StatusNotFound)
+	}
+	if !gg.IsEnforcer(callerID) {
+		if ok, err := CheckSystemGroupMembership(ctx, db, callerID, GroupGlobalOperators); err != nil {
+			return nil, runtime.NewError("failed to check access", StatusInternalError)
+		} else if !ok {
+			return nil, runtime.NewError("user must be an enforcer of the guild", StatusPermissionDenied)
+		}
+	}
+	return gg, nil
+}
+
+// enforcementAppealError maps the appeal errors to RPC errors.
+func enforcementAppealError(err error) error {
+	switch {
+	case errors.Is(err, ErrAppealRecordNotFound), errors.Is(err, ErrAppealNotFound):
+		return runtime.NewError(err.Error(), StatusNotFound)
+	case errors.Is(err, ErrAppealExists), errors.Is(err, ErrAppealResolved):
+		return runtime.NewError(err.Error(), StatusAlreadyExists)
+	case errors.Is(err, ErrAppealSelfReview):
+		return runtime.NewError(err.Error(), StatusPermissionDenied)
+	case errors.Is(err, ErrAppealNotSuspension), errors.Is(err, ErrAppealInvalidOutcome), errors.Is(err, ErrAppealInvalidReduction):
+		return runtime.NewError(err.Error(), StatusInvalidArgument)
+	default:
+		return runtime.NewError(err.Error(), StatusInternalError)
+	}
+}
+
+func enforcementAppealResponse(journal *GuildEnforcementJournal, groupID, recordID string) (string, error) {
+	response := EnforcementAppealResponse{}
+	response.Appeal, _ = journal.GetAppeal(groupID, recordID)
+	response.Record, _ = journal.Record(groupID, recordID)
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
+
+// EnforcementAppealRPC files the caller's appeal of one of their suspensions.
+func EnforcementAppealRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" {
+		return "", runtime.NewError("authentication required", StatusUnauthenticated)
+	}
+	request := &EnforcementAppealRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Statement == "" {
+		return "", runtime.NewError("statement is required", StatusInvalidArgument)
+	}
+	groupID, err := enforcementAppealGroupID(ctx, db, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+
+	var appeal GuildEnforcementAppeal
+	var record GuildEnforcementRecord
+	journal, err := EnforcementAppealUpdate(ctx, nk, userID, func(journal *GuildEnforcementJournal) error {
+		recordID := request.RecordID
+		if recordID == "" {
+			r, ok := journal.LatestAppealableRecord(groupID)
+			if !ok {
+				return ErrAppealRecordNotFound
+			}
+			recordID = r.ID
+		}
+		var err error
+		if appeal, err = journal.SubmitAppeal(groupID, recordID, request.Statement); err != nil {
+			return err
+		}
+		record, _ = journal.Record(groupID, recordID)
+		return nil
+	})
+	if err != nil {
+		return "", enforcementAppealError(err)
+	}
+
+	discordID, _ := GetDiscordIDByUserID(ctx, db, userID)
+	if _, err := globalAppBot.Load().LogAuditMessage(ctx, groupID, enforcementAppealAuditMessage(appeal, record, discordID), false); err != nil {
+		logger.WithField("error", err).Warn("Failed to send appeal audit message")
+	}
+
+	return enforcementAppealResponse(journal, groupID, appeal.RecordID)
+}
+
+// EnforcementAppealReviewRPC assigns the caller to an appeal, or resolves it. Guild enforcers and global operators are allowed.
+func EnforcementAppealReviewRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	callerID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || callerID == "" {
+		return "", runtime.NewError("authentication required", StatusUnauthenticated)
+	}
+	request := &EnforcementAppealReviewRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.UserID == "" || request.RecordID == "" {
+		return "", runtime.NewError("user_id and record_id are required", StatusInvalidArgument)
+	}
+	gg, err := enforcerGuildGroup(ctx, db, nk, callerID, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+	groupID := gg.IDStr()
+
+	callerDiscordID, _ := GetDiscordIDByUserID(ctx, db, callerID)
+
+	var appeal GuildEnforcementAppeal
+	var record GuildEnforcementRecord
+	journal, err := EnforcementAppealUpdate(ctx, nk, request.UserID, func(journal *GuildEnforcementJournal) error {
+		var err error
+		if request.Outcome == AppealStatusUnderReview {
+			appeal, err = journal.AssignAppealReviewer(groupID, request.RecordID, callerID, callerDiscordID)
+		} else {
+			appeal, err = journal.ResolveAppeal(groupID, request.RecordID, callerID, callerDiscordID, request.Outcome, request.Notes, request.ReducedExpiry)
+		}
+		if err != nil {
+			return err
+		}
+		record, _ = journal.Record(groupID, request.RecordID)
+		return nil
+	})
+	if err != nil {
+		return "", enforcementAppealError(err)
+	}
+
+	if _, err := globalAppBot.Load().LogAuditMessage(ctx, groupID, enforcementAppealAuditMessage(appeal, record, callerDiscordID), false); err != nil {
+		logger.WithField("error", err).Warn("Failed to send appeal audit message")
+	}
+
+	return enforcementAppealResponse(journal, groupID, request.RecordID)
+}
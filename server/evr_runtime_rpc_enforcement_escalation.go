@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type EnforcementEscalationRequest struct {
+	GroupID string `json:"group_id"` // The group ID or guild ID.
+}
+
+type EnforcementEscalationResponse struct {
+	GroupID string                       `json:"group_id"`
+	Policy  *EnforcementEscalationPolicy `json:"policy"`
+}
+
+type EnforcementEscalationSetRequest struct {
+	GroupID string                       `json:"group_id"` // The group ID or guild ID.
+	Policy  *EnforcementEscalationPolicy `json:"policy"`   // Null to remove the policy.
+}
+
+type EnforcementEscalationEvaluateRequest struct {
+	GroupID  string `json:"group_id"` // The group ID or guild ID.
+	UserID   string `json:"user_id"`
+	Category string `json:"category"`
+}
+
+func enforcementEscalationResponse(groupID string, policy *EnforcementEscalationPolicy) (string, error) {
+	data, err := json.Marshal(EnforcementEscalationResponse{
+		GroupID: groupID,
+		Policy:  policy,
+	})
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
+
+// EnforcementEscalationRPC returns the escalation policy of a guild. Guild enforcers and global operators are allowed.
+func EnforcementEscalationRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	callerID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || callerID == "" {
+		return "", runtime.NewError("authentication required", StatusUnauthenticated)
+	}
+	request := &EnforcementEscalationRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	gg, err := enforcerGuildGroup(ctx, db, nk, callerID, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+	return enforcementEscalationResponse(gg.IDStr(), gg.EnforcementEscalation)
+}
+
+// EnforcementEscalationSetRPC sets, or removes, the escalation policy of a guild.
+func EnforcementEscalationSetRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &EnforcementEscalationSetRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Policy != nil {
+		if err := request.Policy.Validate(); err != nil {
+			return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+		}
+	}
+	gg, err := managedGuildGroup(ctx, db, nk, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := GroupMetadataLoad(ctx, db, gg.IDStr())
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	metadata.EnforcementEscalation = request.Policy
+	if err := GroupMetadataSave(ctx, db, gg.IDStr(), metadata); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{"group_id": gg.IDStr(), "policy": request.Policy}).Info("Enforcement escalation policy updated")
+
+	return enforcementEscalationResponse(gg.IDStr(), metadata.EnforcementEscalation)
+}
+
+// EnforcementEscalationEvaluateRPC suggests the suspension for a player's next offense, from the guild's escalation policy.
+func EnforcementEscalationEvaluateRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	callerID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || callerID == "" {
+		return "", runtime.NewError("authentication required", StatusUnauthenticated)
+	}
+	request := &EnforcementEscalationEvaluateRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.UserID == "" || request.Category == "" {
+		return "", runtime.NewError("user_id and category are required", StatusInvalidArgument)
+	}
+	gg, err := enforcerGuildGroup(ctx, db, nk, callerID, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+
+	escalation, err := EnforcementEscalationEvaluate(ctx, nk, globalGuildGroupRegistry.Load(), gg, request.UserID, request.Category)
+	switch {
+	case errors.Is(err, ErrEscalationPolicyNotSet), errors.Is(err, ErrEscalationUnknownOffense):
+		return "", runtime.NewError(err.Error(), StatusNotFound)
+	case err != nil:
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+
+	data, err := json.Marshal(escalation)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
@@ -9,6 +9,10 @@ import (
 	"go.uber.org/zap"
 )
 
+type CheckForceUserRequest struct {
+	LoginSessionID string `json:"login_session_id"`
+}
+
 func CheckForceUserRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	queryParameters := ctx.Value(runtime.RUNTIME_CTX_QUERY_PARAMS).(map[string][]string)
 
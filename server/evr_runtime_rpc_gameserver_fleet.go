@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type GameServerFleetRequest struct {
+	OperatorID string `json:"operator_id"` // Only the servers of the operator.
+	Region     string `json:"region"`      // Only the servers hosting the region code.
+	Health     string `json:"health"`      // Only the servers in the health state: healthy, degraded or draining.
+	Node       string `json:"node"`        // Only the servers connected to the node.
+}
+
+type GameServerFleetResponse struct {
+	Servers []*GameServerRecord `json:"servers"`
+}
+
+// GameServerFleetRPC lists the registered game servers on all nodes, with their health and registration history.
+// Global developers and server to server calls see the whole fleet; operators only see their own servers.
+func GameServerFleetRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &GameServerFleetRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+
+	registry := globalGameServerRegistry.Load()
+	if registry == nil {
+		return "", runtime.NewError("game server registry is not available", StatusUnavailable)
+	}
+
+	if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+		if ok, err := CheckSystemGroupMembership(ctx, db, userID, GroupGlobalDevelopers); err != nil {
+			return "", runtime.NewError("failed to check access", StatusInternalError)
+		} else if !ok {
+			if request.OperatorID != "" && request.OperatorID != userID {
+				return "", runtime.NewError("permission denied", StatusPermissionDenied)
+			}
+			request.OperatorID = userID
+		}
+	}
+
+	response := GameServerFleetResponse{Servers: make([]*GameServerRecord, 0)}
+	for _, record := range registry.List() {
+		switch {
+		case request.OperatorID != "" && record.OperatorID.String() != request.OperatorID:
+		case request.Region != "" && !slices.Contains(record.RegionCodes, request.Region):
+		case request.Health != "" && string(record.Health) != request.Health:
+		case request.Node != "" && record.Node != request.Node:
+		default:
+			response.Servers = append(response.Servers, record)
+		}
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
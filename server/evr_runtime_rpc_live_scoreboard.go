@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type LiveScoreboardFeedRequest struct {
+	MatchID   string `json:"match_id"`
+	DelaySecs int    `json:"delay_s,omitempty"`   // Holds the feed back, so that it can't be used to ghost the match (max 600).
+	TTLHours  int    `json:"ttl_hours,omitempty"` // How long the token is valid (default 12, max 168).
+	Revoke    bool   `json:"revoke,omitempty"`    // Opt the match out, ending its open streams.
+}
+
+type LiveScoreboardFeedResponse struct {
+	MatchID    string    `json:"match_id"`
+	Token      string    `json:"token,omitempty"`
+	Path       string    `json:"path,omitempty"` // The server-sent events endpoint, with the token.
+	DelaySecs  int       `json:"delay_s"`
+	ExpiryTime time.Time `json:"expiry_time,omitempty"`
+	Revoked    bool      `json:"revoked,omitempty"`
+}
+
+// LiveScoreboardFeedRPC opts a match in to the public scoreboard feed, and issues its token. Issuing a new
+// token revokes the previous one. The caller must be an allocator of the match's guild.
+func LiveScoreboardFeedRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &LiveScoreboardFeedRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	matchID, err := MatchIDFromString(request.MatchID)
+	if err != nil || matchID.IsNil() {
+		return "", runtime.NewError("a valid match_id is required", StatusInvalidArgument)
+	}
+	delay := time.Duration(request.DelaySecs) * time.Second
+	if delay < 0 || delay > LiveScoreboardMaxDelay {
+		return "", runtime.NewError("delay_s must be between 0 and 600", StatusInvalidArgument)
+	}
+	ttl := time.Duration(request.TTLHours) * time.Hour
+	if ttl == 0 {
+		ttl = LiveScoreboardDefaultTTL
+	} else if ttl < 0 || ttl > LiveScoreboardMaxTTL {
+		return "", runtime.NewError("ttl_hours must be between 1 and 168", StatusInvalidArgument)
+	}
+
+	label, err := MatchLabelByID(ctx, nk, matchID)
+	if err != nil || label == nil {
+		return "", runtime.NewError("match not found", StatusNotFound)
+	}
+	_, callerID, err := allocatorGuildGroup(ctx, db, nk, label.GetGroupID().String())
+	if err != nil {
+		return "", err
+	}
+
+	if request.Revoke {
+		if err := LiveScoreboardFeedRevoke(ctx, nk, matchID); err != nil {
+			return "", runtime.NewError(err.Error(), StatusInternalError)
+		}
+		return configResourceResponse(LiveScoreboardFeedResponse{MatchID: matchID.String(), Revoked: true})
+	}
+
+	signingKey := LiveScoreboardSigningKey(nk.(*RuntimeGoNakamaModule).config)
+	feed, token, err := LiveScoreboardFeedIssue(ctx, nk, signingKey, label, callerID, delay, ttl, time.Now().UTC())
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{
+		"mid":     matchID.String(),
+		"uid":     callerID,
+		"delay_s": feed.DelaySecs,
+	}).Info("Issued live scoreboard feed")
+
+	return configResourceResponse(LiveScoreboardFeedResponse{
+		MatchID:    matchID.String(),
+		Token:      token,
+		Path:       LiveScoreboardPath + "?" + url.Values{"token": {token}}.Encode(),
+		DelaySecs:  feed.DelaySecs,
+		ExpiryTime: feed.ExpiryTime,
+	})
+}
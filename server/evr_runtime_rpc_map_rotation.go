@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MapRotationRequest struct {
+	GroupID string `json:"group_id"` // The group ID or guild ID.
+}
+
+type MapRotationResponse struct {
+	GroupID string                      `json:"group_id"`
+	Source  string                      `json:"source"` // The policy in effect: guild, service or default.
+	Policy  *MapRotationPolicy          `json:"policy"`
+	Recent  map[evr.Symbol][]evr.Symbol `json:"recent"` // The recent levels by mode, most recent last.
+	Pools   map[evr.Symbol][]MapWeight  `json:"pools"`  // The enabled levels by mode, now.
+	Active  map[evr.Symbol]string       `json:"active"` // The active playlist by mode, now.
+}
+
+type MapRotationSetRequest struct {
+	GroupID string             `json:"group_id"` // The group ID or guild ID.
+	Policy  *MapRotationPolicy `json:"policy"`   // Null to use the service default.
+}
+
+// managedGuildGroup resolves the group, and checks that the caller may manage it.
+// Server to server calls, global developers, and the guild's owner and auditors are allowed.
+func managedGuildGroup(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, groupID string) (*GuildGroup, error) {
+	if groupID == "" {
+		return nil, runtime.NewError("group_id is required", StatusInvalidArgument)
+	}
+	if uuid.FromStringOrNil(groupID).IsNil() {
+		var err error
+		if groupID, err = GetGroupIDByGuildID(ctx, db, groupID); status.Code(err) == codes.NotFound {
+			return nil, runtime.NewError("guild group not found", StatusNotFound)
+		} else if err != nil {
+			return nil, runtime.NewError(err.Error(), StatusInternalError)
+		}
+	}
+
+	gg, err := GuildGroupLoad(ctx, nk, groupID)
+	if errors.Is(err, runtime.ErrGroupNotFound) {
+		return nil, runtime.NewError("guild group not found", StatusNotFound)
+	} else if err != nil {
+		return nil, runtime.NewError(err.Error(), StatusInternalError)
+	}
+
+	userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	if !ok || userID == "" || gg.IsOwner(userID) || gg.IsAuditor(userID) {
+		return gg, nil
+	}
+	if ok, err := CheckSystemGroupMembership(ctx, db, userID, GroupGlobalDevelopers); err != nil {
+		return nil, runtime.NewError("failed to check access", StatusInternalError)
+	} else if !ok {
+		return nil, runtime.NewError("user must be the owner or an auditor of the guild", StatusPermissionDenied)
+	}
+	return gg, nil
+}
+
+// MapRotationRPC returns the map rotation policy in effect for a guild, and its recent levels.
+func MapRotationRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &MapRotationRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	gg, err := managedGuildGroup(ctx, db, nk, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(mapRotationResponse(ctx, nk, gg.IDStr(), &gg.GroupMetadata))
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
+
+func mapRotationResponse(ctx context.Context, nk runtime.NakamaModule, groupID string, metadata *GroupMetadata) MapRotationResponse {
+	policy, source := MapRotationPolicyFor(metadata)
+	now := time.Now().UTC()
+	response := MapRotationResponse{
+		GroupID: groupID,
+		Source:  source,
+		Policy:  policy,
+		Recent:  make(map[evr.Symbol][]evr.Symbol),
+		Pools:   make(map[evr.Symbol][]MapWeight),
+		Active:  make(map[evr.Symbol]string),
+	}
+
+	for mode := range evr.LevelsByMode {
+		history := NewMapRotationHistory(groupID, mode)
+		if err := StorableRead(ctx, nk, SystemUserID, history, false); err == nil {
+			response.Recent[mode] = history.Recent
+		}
+		pool, playlist := policy.Pool(mode, now)
+		response.Pools[mode] = pool
+		if playlist != "" {
+			response.Active[mode] = playlist
+		}
+	}
+	return response
+}
+
+// MapRotationSetRPC sets, or clears, the map rotation policy of a guild.
+func MapRotationSetRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &MapRotationSetRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Policy != nil {
+		if err := request.Policy.Validate(); err != nil {
+			return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+		}
+	}
+	gg, err := managedGuildGroup(ctx, db, nk, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := GroupMetadataLoad(ctx, db, gg.IDStr())
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	metadata.MapRotation = request.Policy
+	if err := GroupMetadataSave(ctx, db, gg.IDStr(), metadata); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{"group_id": gg.IDStr(), "policy": request.Policy}).Info("Map rotation policy updated")
+
+	data, err := json.Marshal(mapRotationResponse(ctx, nk, gg.IDStr(), metadata))
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
@@ -199,12 +199,12 @@ func AllocateMatchRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk
 	return string(label.GetLabelIndented()), nil
 }
 
-type shutdownMatchRequest struct {
+type ShutdownMatchRequest struct {
 	MatchID      MatchID `json:"match_id"`
 	GraceSeconds int     `json:"grace_seconds,omitempty"`
 }
 
-type shutdownMatchResponse struct {
+type ShutdownMatchResponse struct {
 	Success  bool   `json:"success"`
 	Response string `json:"response"`
 }
@@ -213,7 +213,7 @@ func shutdownMatchRpc(ctx context.Context, logger runtime.Logger, db *sql.DB, nk
 
 	r := NewRuntimeContext(ctx)
 
-	request := &shutdownMatchRequest{}
+	request := &ShutdownMatchRequest{}
 	if err := json.Unmarshal([]byte(payload), request); err != nil {
 		return "", err
 	}
@@ -241,7 +241,7 @@ func shutdownMatchRpc(ctx context.Context, logger runtime.Logger, db *sql.DB, nk
 		return "", err
 	}
 
-	response := &shutdownMatchResponse{
+	response := &ShutdownMatchResponse{
 		Success:  true,
 		Response: signalResponse,
 	}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type MatchHistoryRequest struct {
+	UserID    string `json:"user_id"`
+	DiscordID string `json:"discord_id"`
+	GroupID   string `json:"group_id"`
+	GuildID   string `json:"guild_id"`
+	Limit     int    `json:"limit"`
+	Cursor    string `json:"cursor"`
+}
+
+type MatchHistoryResponse struct {
+	Records []*MatchHistoryRecord `json:"records"`
+	Cursor  string                `json:"cursor,omitempty"` // Empty on the last page.
+}
+
+// MatchHistoryRPC lists the completed matches of a player and/or a guild, most recent first.
+// Private matches are only included for the player themselves, and for global private data access.
+func MatchHistoryRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &MatchHistoryRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+
+	if request.UserID == "" && request.DiscordID != "" {
+		userID, err := GetUserIDByDiscordID(ctx, db, request.DiscordID)
+		if err != nil {
+			return "", runtime.NewError("user not found", StatusNotFound)
+		}
+		request.UserID = userID
+	}
+
+	if request.GroupID == "" && request.GuildID != "" {
+		groupID, err := GetGroupIDByGuildID(ctx, db, request.GuildID)
+		if err != nil {
+			return "", runtime.NewError("guild group not found", StatusNotFound)
+		}
+		request.GroupID = groupID
+	}
+
+	if request.UserID == "" && request.GroupID == "" {
+		return "", runtime.NewError("user or guild required", StatusInvalidArgument)
+	}
+
+	// Server to server calls have full access.
+	includePrivate := true
+	if callerID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && callerID != "" && callerID != request.UserID {
+		var err error
+		if includePrivate, err = CheckSystemGroupMembership(ctx, db, callerID, GroupGlobalPrivateDataAccess); err != nil {
+			return "", runtime.NewError("failed to check access", StatusInternalError)
+		}
+	}
+
+	records, cursor, err := MatchHistoryList(ctx, db, MatchHistoryFilter{
+		UserID:         request.UserID,
+		GroupID:        request.GroupID,
+		IncludePrivate: includePrivate,
+		Limit:          request.Limit,
+		Cursor:         request.Cursor,
+	})
+	if errors.Is(err, ErrMatchHistoryInvalidCursor) {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	} else if err != nil {
+		logger.WithField("error", err).Error("Failed to list match history.")
+		return "", runtime.NewError("failed to list match history", StatusInternalError)
+	}
+
+	data, err := json.Marshal(MatchHistoryResponse{Records: records, Cursor: cursor})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return string(data), nil
+}
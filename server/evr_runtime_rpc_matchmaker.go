@@ -135,7 +135,7 @@ func BuildMatchRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk ru
 		return "", err
 	}
 
-	lobbyBuilder := NewLobbyBuilder(RuntimeLoggerToZapLogger(logger), nk, _nk.sessionRegistry, _nk.matchRegistry, _nk.tracker, _nk.metrics)
+	lobbyBuilder := NewLobbyBuilder(RuntimeLoggerToZapLogger(logger), db, nk, _nk.sessionRegistry, _nk.matchRegistry, _nk.tracker, _nk.metrics)
 
 	matchID, err := lobbyBuilder.buildMatch(lobbyBuilder.logger, request.Entries)
 	if err != nil {
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	matchmakerSimulationDefaultSnapshots = 10
+	matchmakerSimulationMaxVariants      = 10
+)
+
+type MatchmakerSimulationRequest struct {
+	Snapshots []string                      `json:"snapshots,omitempty"` // The snapshot keys; the latest ones if empty.
+	Limit     int                           `json:"limit,omitempty"`     // The number of latest snapshots, if none are given.
+	Variants  []MatchmakerSimulationVariant `json:"variants"`
+}
+
+type MatchmakerSimulationResponse struct {
+	Snapshots []MatchmakerSnapshotInfo      `json:"snapshots"`
+	Results   []*MatchmakerSimulationResult `json:"results"` // The baseline first, then the variants.
+}
+
+// MatchmakerSnapshotsRPC lists the stored matchmaker candidate snapshots, newest first.
+func MatchmakerSnapshotsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	index, _, err := MatchmakerSnapshotIndexRead(ctx, nk)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
+
+// MatchmakerSimulateRPCFactory returns an RPC that replays stored candidate snapshots with alternative matchmaking settings.
+func MatchmakerSimulateRPCFactory(sbmm *SkillBasedMatchmaker) EvrRPCFunction {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+			return "", err
+		}
+		request := &MatchmakerSimulationRequest{}
+		if err := json.Unmarshal([]byte(payload), request); err != nil {
+			return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+		}
+		if len(request.Variants) > matchmakerSimulationMaxVariants {
+			return "", runtime.NewError("too many variants", StatusInvalidArgument)
+		}
+		for _, v := range request.Variants {
+			if v.Name == "" || v.Name == MatchmakerBaselineVariant {
+				return "", runtime.NewError("each variant needs a name other than "+MatchmakerBaselineVariant, StatusInvalidArgument)
+			}
+		}
+
+		keys := request.Snapshots
+		if len(keys) == 0 {
+			index, _, err := MatchmakerSnapshotIndexRead(ctx, nk)
+			if err != nil {
+				return "", runtime.NewError(err.Error(), StatusInternalError)
+			}
+			limit := request.Limit
+			if limit <= 0 {
+				limit = matchmakerSimulationDefaultSnapshots
+			}
+			for _, s := range index.Snapshots[:min(limit, len(index.Snapshots))] {
+				keys = append(keys, s.Key)
+			}
+		}
+		if len(keys) == 0 {
+			return "", runtime.NewError("no snapshots stored; set matchmaking.candidate_snapshot_interval_secs", StatusNotFound)
+		}
+
+		snapshots, err := MatchmakerSnapshotsRead(ctx, nk, keys)
+		if err != nil {
+			return "", runtime.NewError(err.Error(), StatusInternalError)
+		}
+
+		response := MatchmakerSimulationResponse{
+			Snapshots: make([]MatchmakerSnapshotInfo, 0, len(snapshots)),
+			Results:   RunMatchmakerSimulation(ctx, logger, sbmm, snapshots, request.Variants),
+		}
+		for _, s := range snapshots {
+			response.Snapshots = append(response.Snapshots, s.Info())
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			return "", runtime.NewError(err.Error(), StatusInternalError)
+		}
+		return string(data), nil
+	}
+}
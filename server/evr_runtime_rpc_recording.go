@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type RecordingStartRequest struct {
+	SessionID    string  `json:"session_id,omitempty"` // Record a single EVR session.
+	MatchID      MatchID `json:"match_id,omitempty"`   // Record the game server and the players of the match, and the players that join it later.
+	DurationSecs int     `json:"duration_secs,omitempty"`
+}
+
+type RecordingStopRequest struct {
+	ID string `json:"id"`
+}
+
+type RecordingListResponse struct {
+	Recordings []*EvrRecorder `json:"recordings"`
+}
+
+// RecordingStartRPC starts recording the EVR traffic of a session, or of a match, on this node.
+// Only the sessions connected to this node are captured; the players of a match that connect
+// through other nodes are not.
+func RecordingStartRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	recorders := globalEvrRecorders.Load()
+	if recorders == nil {
+		return "", runtime.NewError("recording is not available", StatusUnavailable)
+	}
+
+	request := &RecordingStartRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+
+	var (
+		label      string
+		sessionIDs []uuid.UUID
+	)
+	switch {
+	case !request.MatchID.IsNil():
+		matchLabel, err := MatchLabelByID(ctx, nk, request.MatchID)
+		if err != nil || matchLabel == nil {
+			return "", runtime.NewError("match not found", StatusNotFound)
+		}
+		if matchLabel.GameServer != nil {
+			sessionIDs = append(sessionIDs, matchLabel.GameServer.SessionID)
+		}
+		for _, p := range matchLabel.Players {
+			if id := uuid.FromStringOrNil(p.SessionID); !id.IsNil() {
+				sessionIDs = append(sessionIDs, id)
+			}
+		}
+	case request.SessionID != "":
+		sessionID := uuid.FromStringOrNil(request.SessionID)
+		if sessionID.IsNil() {
+			return "", runtime.NewError("invalid session id", StatusInvalidArgument)
+		}
+		label = "session:" + sessionID.String()
+		sessionIDs = append(sessionIDs, sessionID)
+	default:
+		return "", runtime.NewError("session_id or match_id required", StatusInvalidArgument)
+	}
+
+	var (
+		recorder *EvrRecorder
+		err      error
+		duration = time.Duration(request.DurationSecs) * time.Second
+	)
+	if !request.MatchID.IsNil() {
+		recorder, err = recorders.StartMatch(request.MatchID, sessionIDs, duration)
+	} else {
+		recorder, err = recorders.Start(label, sessionIDs, duration)
+	}
+	if errors.Is(err, ErrEvrRecordingConflicts) {
+		return "", runtime.NewError(err.Error(), StatusAlreadyExists)
+	} else if err != nil {
+		logger.WithField("error", err).Error("Failed to start recording.")
+		return "", runtime.NewError("failed to start recording", StatusInternalError)
+	}
+
+	logger.WithFields(map[string]any{
+		"id":       recorder.ID,
+		"label":    recorder.Label,
+		"path":     recorder.Path,
+		"sessions": len(sessionIDs),
+	}).Info("Started EVR recording.")
+
+	data, err := json.Marshal(recorder)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func RecordingStopRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	recorders := globalEvrRecorders.Load()
+	if recorders == nil {
+		return "", runtime.NewError("recording is not available", StatusUnavailable)
+	}
+
+	request := &RecordingStopRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+
+	recorder, err := recorders.Stop(request.ID)
+	if errors.Is(err, ErrEvrRecordingNotFound) {
+		return "", runtime.NewError(err.Error(), StatusNotFound)
+	} else if err != nil {
+		logger.WithField("error", err).Warn("Failed to close recording.")
+	}
+
+	data, err := json.Marshal(recorder)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func RecordingListRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	response := RecordingListResponse{Recordings: make([]*EvrRecorder, 0)}
+	if recorders := globalEvrRecorders.Load(); recorders != nil {
+		response.Recordings = recorders.List()
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
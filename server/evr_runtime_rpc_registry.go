@@ -0,0 +1,487 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+
+	nevrapi "github.com/echotools/nevr-common/v3/api"
+	"github.com/echotools/nevr-common/v4/gen/go/rtapi"
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+type EvrRPCFunction func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)
+
+// EvrRPC is an RPC registered by the EVR runtime module, with its declared schema.
+type EvrRPC struct {
+	ID       string
+	Summary  string
+	Request  any // The JSON payload; nil if the RPC does not read the payload
+	Query    any // The query parameters, as a struct of strings; nil if none are read
+	Response any // The JSON response; nil if the response is plain text
+	Fn       EvrRPCFunction
+}
+
+// EvrRPCs returns the RPCs of the EVR runtime module.
+func EvrRPCs(rpcHandler *RPCHandler, sbmm *SkillBasedMatchmaker) []EvrRPC {
+	return []EvrRPC{
+		{
+			ID:       "account/search",
+			Summary:  "Search accounts by display name",
+			Request:  AccountSearchRequest{},
+			Response: AccountSearchResponse{},
+			Fn:       AccountSearchRPC,
+		},
+		{
+			ID:       "account/lookup",
+			Summary:  "Look up an account by user ID, username, Discord ID, XPID or display name",
+			Request:  AccountLookupRequest{},
+			Response: AccountLookupRPCResponse{},
+			Fn:       rpcHandler.AccountLookupRPC,
+		},
+		{
+			ID:       "account/authenticate/password",
+			Summary:  "Authenticate with a password, or refresh a session",
+			Request:  AuthenticatePasswordRequest{},
+			Response: &api.Session{},
+			Fn:       AuthenticatePasswordRPC,
+		},
+		{
+			ID:       "leaderboard/haystack",
+			Summary:  "List leaderboard records around an owner",
+			Request:  LeaderboardHaystackRequest{},
+			Response: LeaderboardHaystackResponse{},
+			Fn:       rpcHandler.LeaderboardHaystackRPC,
+		},
+		{
+			ID:       "leaderboard/records",
+			Summary:  "List leaderboard records",
+			Request:  LeaderboardRecordsListRequest{},
+			Response: LeaderboardRecordsListResponse{},
+			Fn:       rpcHandler.LeaderboardRecordsListRPC,
+		},
+		{
+			ID:      "link/device",
+			Summary: "Link a device to the session's account using a link code",
+			Request: LinkDeviceRpcRequest{},
+			Fn:      LinkDeviceRpc,
+		},
+		{
+			ID:       "link/usernamedevice",
+			Summary:  "Link a device to an account by username using a link code",
+			Request:  LinkUserIdDeviceRpcRequest{},
+			Response: RPCSuccessResponse{},
+			Fn:       LinkUserIdDeviceRpc,
+		},
+		{
+			ID:       "signin/discord",
+			Summary:  "Sign in with a Discord OAuth code",
+			Request:  DiscordSignInRpcRequest{},
+			Response: DiscordSignInRpcResponse{},
+			Fn:       DiscordSignInRpc,
+		},
+		{
+			ID:       "match/public",
+			Summary:  "List the public view of the active matches",
+			Response: MatchListPublicRPCResponse{},
+			Fn:       rpcHandler.MatchListPublicRPC,
+		},
+		{
+			ID:       "match/history",
+			Summary:  "List the completed matches of a player or guild",
+			Request:  MatchHistoryRequest{},
+			Response: MatchHistoryResponse{},
+			Fn:       MatchHistoryRPC,
+		},
+		{
+			ID:       "match",
+			Summary:  "List match labels by ID or query",
+			Request:  MatchRpcRequest{},
+			Response: MatchRpcResponse{},
+			Fn:       MatchRPC,
+		},
+		{
+			ID:       "match/prepare",
+			Summary:  "Prepare a parked match for a guild",
+			Request:  PrepareMatchRPCRequest{},
+			Response: MatchLabel{},
+			Fn:       PrepareMatchRPC,
+		},
+		{
+			ID:       "match/allocate",
+			Summary:  "Allocate a game server for a guild",
+			Request:  &nevrapi.PrepareMatchRequest{},
+			Response: MatchLabel{},
+			Fn:       AllocateMatchRPC,
+		},
+		{
+			ID:       "match/terminate",
+			Summary:  "Shut down a match",
+			Request:  ShutdownMatchRequest{},
+			Response: ShutdownMatchResponse{},
+			Fn:       shutdownMatchRpc,
+		},
+		{
+			ID:       "match/build",
+			Summary:  "Build a match from matchmaker entries",
+			Request:  BuildMatchRequest{},
+			Response: BuildMatchResponse{},
+			Fn:       BuildMatchRPC,
+		},
+		{
+			ID:       "player/setnextmatch",
+			Summary:  "Set the next match of a player",
+			Request:  SetNextMatchRPCRequest{},
+			Response: SetNextMatchRPCResponse{},
+			Fn:       SetNextMatchRPC,
+		},
+		{
+			ID:       "player/statistics",
+			Summary:  "Get the statistics of a player in a guild",
+			Request:  PlayerStatisticsRequest{},
+			Response: PlayerStatisticsResponse{},
+			Fn:       PlayerStatisticsRPC,
+		},
+		{
+			ID:       "player/kick",
+			Summary:  "Kick a player from their match",
+			Request:  KickPlayerRPCRequest{},
+			Response: RPCSuccessResponse{},
+			Fn:       KickPlayerRPC,
+		},
+		{
+			ID:       "enforcement/appeal",
+			Summary:  "Appeal one of the caller's suspensions",
+			Request:  EnforcementAppealRequest{},
+			Response: EnforcementAppealResponse{},
+			Fn:       EnforcementAppealRPC,
+		},
+		{
+			ID:       "enforcement/appeal/review",
+			Summary:  "Review, or resolve, an appeal of a suspension",
+			Request:  EnforcementAppealReviewRequest{},
+			Response: EnforcementAppealResponse{},
+			Fn:       EnforcementAppealReviewRPC,
+		},
+		{
+			ID:       "enforcement/escalation",
+			Summary:  "Get the enforcement escalation policy of a guild",
+			Query:    EnforcementEscalationRequest{},
+			Response: EnforcementEscalationResponse{},
+			Fn:       EnforcementEscalationRPC,
+		},
+		{
+			ID:       "enforcement/escalation/set",
+			Summary:  "Set or remove the enforcement escalation policy of a guild",
+			Request:  EnforcementEscalationSetRequest{},
+			Response: EnforcementEscalationResponse{},
+			Fn:       EnforcementEscalationSetRPC,
+		},
+		{
+			ID:       "enforcement/escalation/evaluate",
+			Summary:  "Suggest the suspension for a player's next offense of a category",
+			Query:    EnforcementEscalationEvaluateRequest{},
+			Response: EnforcementEscalation{},
+			Fn:       EnforcementEscalationEvaluateRPC,
+		},
+		{
+			ID:       "player/profile",
+			Summary:  "Get the server profile of a player",
+			Request:  UserServerProfileRPCRequest{},
+			Response: evr.ServerProfile{},
+			Fn:       UserServerProfileRPC,
+		},
+		{
+			ID:      "link",
+			Summary: "Linking application placeholder",
+			Fn:      LinkingAppRpc,
+		},
+		{
+			ID:       "evr/servicestatus",
+			Summary:  "Get the service status shown in game",
+			Response: []ServiceStatusService{},
+			Fn:       rpcHandler.ServiceStatusRPC,
+		},
+		{
+			ID:       "importloadouts",
+			Summary:  "Import cosmetic loadouts",
+			Request:  ImportLoadoutRpcRequest{},
+			Response: ImportLoadoutRpcResponse{},
+			Fn:       ImportLoadoutsRpc,
+		},
+		{
+			ID:       "matchmaker/stream",
+			Summary:  "Join the matchmaking stream of a guild",
+			Request:  MatchmakerStreamRequest{},
+			Response: MatchmakerStreamResponse{},
+			Fn:       MatchmakerStreamRPC,
+		},
+		{
+			ID:       "matchmaker/state",
+			Summary:  "Get the matchmaker state",
+			Response: MatchmakerStateResponse{},
+			Fn:       MatchmakerStateRPC,
+		},
+		{
+			ID:       "matchmaker/candidates",
+			Summary:  "Get the latest matchmaker candidates and matches",
+			Response: MatchmakerCandidatesRPCResponse{},
+			Fn:       MatchmakerCandidatesRPCFactory(sbmm),
+		},
+		{
+			ID:       "matchmaker/snapshots",
+			Summary:  "List the stored matchmaker candidate snapshots",
+			Response: MatchmakerSnapshotIndex{},
+			Fn:       MatchmakerSnapshotsRPC,
+		},
+		{
+			ID:       "matchmaker/simulate",
+			Summary:  "Replay stored matchmaker candidate snapshots with alternative matchmaking settings",
+			Request:  MatchmakerSimulationRequest{},
+			Response: MatchmakerSimulationResponse{},
+			Fn:       MatchmakerSimulateRPCFactory(sbmm),
+		},
+		{
+			ID:       "stream/join",
+			Summary:  "Join a stream",
+			Request:  StreamJoinRequest{},
+			Response: StreamJoinResponse{},
+			Fn:       StreamJoinRPC,
+		},
+		{
+			ID:       "server/score",
+			Summary:  "Score a server by player round trip times",
+			Request:  ServerScoreRPCRequest{},
+			Response: ServerScoreRPCResponse{},
+			Fn:       ServerScoreRPC,
+		},
+		{
+			ID:       "server/scores",
+			Summary:  "Score the servers for a set of players",
+			Request:  ServerScoresRPCRequest{},
+			Response: ServerScoresRPCResponse{},
+			Fn:       ServerScoresRPC,
+		},
+		{
+			ID:      "forcecheck",
+			Summary: "Check if the user of a login session is a Force User",
+			Query:   CheckForceUserRequest{},
+			Fn:      CheckForceUserRPC,
+		},
+		{
+			ID:       "gameserver/fleet",
+			Summary:  "List the registered game servers on all nodes, with their health and registration history",
+			Query:    GameServerFleetRequest{},
+			Response: GameServerFleetResponse{},
+			Fn:       GameServerFleetRPC,
+		},
+		{
+			ID:       "guildgroup",
+			Summary:  "Get guild groups by ID",
+			Query:    GuildGroupRequest{},
+			Response: GuildGroupResponse{},
+			Fn:       GuildGroupGetRPC,
+		},
+		{
+			ID:       "guildgroup/maprotation",
+			Summary:  "Get the map rotation policy of a guild, and its recent levels",
+			Query:    MapRotationRequest{},
+			Response: MapRotationResponse{},
+			Fn:       MapRotationRPC,
+		},
+		{
+			ID:       "guildgroup/maprotation/set",
+			Summary:  "Set or clear the map rotation policy of a guild",
+			Request:  MapRotationSetRequest{},
+			Response: MapRotationResponse{},
+			Fn:       MapRotationSetRPC,
+		},
+		{
+			ID:       "recording/start",
+			Summary:  "Start recording the EVR traffic of a session or match on this node",
+			Request:  RecordingStartRequest{},
+			Response: EvrRecorder{},
+			Fn:       RecordingStartRPC,
+		},
+		{
+			ID:       "recording/stop",
+			Summary:  "Stop a recording",
+			Request:  RecordingStopRequest{},
+			Response: EvrRecorder{},
+			Fn:       RecordingStopRPC,
+		},
+		{
+			ID:       "recording/list",
+			Summary:  "List the active recordings on this node",
+			Response: RecordingListResponse{},
+			Fn:       RecordingListRPC,
+		},
+		{
+			ID:       "symbol/resolve",
+			Summary:  "Submit candidate strings for the unknown symbols seen in traffic",
+			Request:  SymbolResolveRequest{},
+			Response: SymbolResolveResponse{},
+			Fn:       SymbolResolveRPC,
+		},
+		{
+			ID:       "symbol/unknown",
+			Summary:  "List the unknown symbols seen in traffic on this node",
+			Response: SymbolUnknownResponse{},
+			Fn:       SymbolUnknownRPC,
+		},
+		{
+			ID:       "config/resource",
+			Summary:  "Get the stored versions of a config resource type",
+			Query:    ConfigResourceRequest{},
+			Response: ConfigResourceHistory{},
+			Fn:       ConfigResourceRPC,
+		},
+		{
+			ID:       "config/resource/set",
+			Summary:  "Store a new version of a config resource type, with targeted variants",
+			Request:  ConfigResourceSetRequest{},
+			Response: ConfigResourceSet{},
+			Fn:       ConfigResourceSetRPC,
+		},
+		{
+			ID:       "config/resource/rollback",
+			Summary:  "Restore an earlier version of a config resource type",
+			Request:  ConfigResourceRollbackRequest{},
+			Response: ConfigResourceSet{},
+			Fn:       ConfigResourceRollbackRPC,
+		},
+		{
+			ID:       "config/resource/resolve",
+			Summary:  "Get the config resource that a client would be served",
+			Query:    ConfigResourceResolveRequest{},
+			Response: ConfigResourceResolveResponse{},
+			Fn:       ConfigResourceResolveRPC,
+		},
+		{
+			ID:       "document",
+			Summary:  "Get a game document in a language, or its nearest fallback, with its pages",
+			Query:    DocumentRequest{},
+			Response: DocumentResponse{},
+			Fn:       DocumentRPC,
+		},
+		{
+			ID:       "document/set",
+			Summary:  "Store a game document in a language; the version is bumped if the text changes",
+			Request:  DocumentSetRequest{},
+			Response: DocumentResponse{},
+			Fn:       DocumentSetRPC,
+		},
+		{
+			ID:       "document/preview",
+			Summary:  "Get the pages of a game document's text, as the client would show them",
+			Request:  DocumentSetRequest{},
+			Response: DocumentResponse{},
+			Fn:       DocumentPreviewRPC,
+		},
+		{
+			ID:       "tournament/bracket",
+			Summary:  "Get a tournament bracket with its standings, or list a guild's brackets",
+			Query:    TournamentBracketRequest{},
+			Response: TournamentBracketResponse{},
+			Fn:       TournamentBracketRPC,
+		},
+		{
+			ID:       "tournament/bracket/create",
+			Summary:  "Create a single elimination, double elimination or Swiss bracket for a guild",
+			Request:  TournamentBracketCreateRequest{},
+			Response: TournamentBracketResponse{},
+			Fn:       TournamentBracketCreateRPC,
+		},
+		{
+			ID:       "tournament/bracket/advance",
+			Summary:  "Allocate the ready matches of a bracket, pairing the next Swiss round if needed",
+			Request:  TournamentBracketAdvanceRequest{},
+			Response: TournamentBracketResponse{},
+			Fn:       TournamentBracketAdvanceRPC,
+		},
+		{
+			ID:       "tournament/bracket/report",
+			Summary:  "Record or correct the result of a bracket match",
+			Request:  TournamentBracketReportRequest{},
+			Response: TournamentBracketResponse{},
+			Fn:       TournamentBracketReportRPC,
+		},
+		{
+			ID:       "statistics/deadletter",
+			Summary:  "List the statistics entries that failed too many times to be written",
+			Query:    StatisticsDeadLetterRequest{},
+			Response: StatisticsDeadLetterResponse{},
+			Fn:       StatisticsDeadLetterRPC,
+		},
+		{
+			ID:       "statistics/deadletter/requeue",
+			Summary:  "Move statistics dead letters back to the queue",
+			Request:  StatisticsDeadLetterRequeueRequest{},
+			Response: StatisticsDeadLetterRequeueResponse{},
+			Fn:       StatisticsDeadLetterRequeueRPC,
+		},
+		{
+			ID:       "match/scoreboard/feed",
+			Summary:  "Opt a match in to the public live scoreboard feed and issue its token, or revoke it",
+			Request:  LiveScoreboardFeedRequest{},
+			Response: LiveScoreboardFeedResponse{},
+			Fn:       LiveScoreboardFeedRPC,
+		},
+		{
+			ID:       "alternates/scores",
+			Summary:  "Explain the confidence scores of a player's alternate accounts",
+			Query:    AlternateScoresRequest{},
+			Response: AlternateScoresResponse{},
+			Fn:       AlternateScoresRPC,
+		},
+		{
+			ID:       "alternates/reviews",
+			Summary:  "List the review queue of alternate account links",
+			Query:    AlternateReviewsRequest{},
+			Response: AlternateReviewsResponse{},
+			Fn:       AlternateReviewsRPC,
+		},
+		{
+			ID:       "alternates/review",
+			Summary:  "Confirm or dismiss the link between two accounts",
+			Request:  AlternateReviewRequest{},
+			Response: AlternateReviewResponse{},
+			Fn:       AlternateReviewRPC,
+		},
+		{
+			ID:       "/telemetry/stream/join",
+			Summary:  "Join the telemetry stream of a match, as an API user of its guild (socket only)",
+			Request:  JoinMatchStreamRequest{},
+			Response: StreamResponse{},
+			Fn:       JoinTelemetryStreamRPC,
+		},
+		{
+			ID:       "/telemetry/stream/leave",
+			Summary:  "Leave the telemetry stream of a match (socket only)",
+			Request:  LeaveMatchStreamRequest{},
+			Response: StreamResponse{},
+			Fn:       LeaveTelemetryStreamRPC,
+		},
+		{
+			ID:       "session_events/get",
+			Summary:  "List the stored session events of a match",
+			Request:  SessionEventsRequest{},
+			Response: SessionEventsResponse{},
+			Fn:       GetSessionEventsRPC,
+		},
+		{
+			ID:       "session_events/store",
+			Summary:  "Store a session event of a match",
+			Request:  &rtapi.LobbySessionStateFrame{},
+			Response: SessionEventStoreResponse{},
+			Fn:       StoreSessionEventRPC,
+		},
+		{
+			ID:      "oauth/vrml_redirect",
+			Summary: "Complete the VRML account link, as the OAuth redirect target",
+			Query:   VRMLRedirectRequest{},
+			Fn:      VRMLRedirectRPC,
+		},
+	}
+}
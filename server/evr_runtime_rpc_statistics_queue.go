@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type StatisticsDeadLetterRequest struct {
+	LeaderboardID string `json:"leaderboard_id,omitempty"` // A leaderboard ID, or a prefix of one.
+	UserID        string `json:"user_id,omitempty"`
+	Limit         int    `json:"limit,omitempty"` // Defaults to 100.
+}
+
+type StatisticsDeadLetterResponse struct {
+	DeadLetters []*StatisticsDeadLetter `json:"dead_letters"`
+}
+
+type StatisticsDeadLetterRequeueRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type StatisticsDeadLetterRequeueResponse struct {
+	Requeued int64 `json:"requeued"`
+}
+
+// StatisticsDeadLetterRPC lists the statistics entries that were parked after failing too many times.
+func StatisticsDeadLetterRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &StatisticsDeadLetterRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.Limit <= 0 || request.Limit > 1000 {
+		request.Limit = 100
+	}
+	letters, err := StatisticsDeadLetterList(ctx, db, request.LeaderboardID, request.UserID, request.Limit)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return configResourceResponse(StatisticsDeadLetterResponse{DeadLetters: letters})
+}
+
+// StatisticsDeadLetterRequeueRPC moves dead letters back to the statistics queue, once the cause has been fixed.
+func StatisticsDeadLetterRequeueRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	if err := checkSystemGroupAccess(ctx, db, GroupGlobalDevelopers); err != nil {
+		return "", err
+	}
+	request := &StatisticsDeadLetterRequeueRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if len(request.IDs) == 0 {
+		return "", runtime.NewError("ids is required", StatusInvalidArgument)
+	}
+	n, err := StatisticsDeadLetterRequeue(ctx, db, request.IDs)
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	logger.WithFields(map[string]any{"requested": len(request.IDs), "requeued": n, "uid": userID}).Info("Statistics dead letters requeued")
+
+	return configResourceResponse(StatisticsDeadLetterRequeueResponse{Requeued: n})
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 	"github.com/heroiclabs/nakama/v3/server/evr"
@@ -19,7 +20,9 @@ type PlayerStatisticsRequest struct {
 }
 
 type PlayerStatisticsResponse struct {
-	Stats evr.PlayerStatistics `json:"stats"`
+	Stats   evr.PlayerStatistics     `json:"stats"`
+	Derived []*DerivedStatisticValue `json:"derived,omitempty"`
+	Ranked  []*RankedLadderState     `json:"ranked,omitempty"`
 }
 
 func (r *PlayerStatisticsResponse) String() string {
@@ -80,13 +83,26 @@ func PlayerStatisticsRPC(ctx context.Context, logger runtime.Logger, db *sql.DB,
 	} else {
 		modes = []evr.Symbol{request.Mode}
 	}
-	stats, _, err := PlayerStatisticsGetID(ctx, db, nk, request.UserID, request.GroupID, modes, request.Mode)
+	stats, boardMap, err := PlayerStatisticsGetID(ctx, db, nk, request.UserID, request.GroupID, modes, request.Mode)
 	if err != nil {
 		return "", err
 	}
 
 	response := &PlayerStatisticsResponse{
-		Stats: stats,
+		Stats:   stats,
+		Derived: DerivedStatisticValues(DerivedStatistics(), boardMap),
+	}
+
+	if ladder := RankedLadderSettingsGet(); ladder.Enabled {
+		rankedModes := make([]evr.Symbol, 0, len(modes))
+		for _, mode := range modes {
+			if ladder.Applies(mode) {
+				rankedModes = append(rankedModes, mode)
+			}
+		}
+		if response.Ranked, err = RankedLadderLoad(ctx, nk, request.UserID, request.GroupID, rankedModes, time.Now().UTC()); err != nil {
+			return "", err
+		}
 	}
 
 	return response.String(), nil
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const symbolResolveMaxCandidates = 1000
+
+type SymbolResolveRequest struct {
+	Candidates []string `json:"candidates"`
+}
+
+type SymbolResolveResponse struct {
+	Accepted []evr.SymbolInfo `json:"accepted"`
+}
+
+type SymbolUnknownResponse struct {
+	Symbols []evr.ObservedSymbol `json:"symbols"`
+}
+
+// SymbolResolveRPC accepts candidate strings for the unknown symbols seen in traffic.
+// Candidates are only accepted if their hash matches one, so any user may submit them.
+func SymbolResolveRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &SymbolResolveRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if len(request.Candidates) == 0 {
+		return "", runtime.NewError("candidates are required", StatusInvalidArgument)
+	}
+	if len(request.Candidates) > symbolResolveMaxCandidates {
+		return "", runtime.NewError("too many candidates", StatusInvalidArgument)
+	}
+
+	_nk := nk.(*RuntimeGoNakamaModule)
+	accepted, err := SymbolDictionaryResolve(ctx, _nk.logger, db, _nk.metrics, _nk.storageIndex, request.Candidates)
+	if err != nil {
+		logger.WithField("error", err).Error("Failed to resolve symbols")
+		return "", runtime.NewError("failed to store resolved symbols", StatusInternalError)
+	}
+	if len(accepted) > 0 {
+		logger.WithField("accepted", accepted).Info("Resolved symbols")
+	}
+
+	data, err := json.Marshal(SymbolResolveResponse{Accepted: accepted})
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
+
+// SymbolUnknownRPC lists the unknown symbols seen in traffic on every node, and how often.
+func SymbolUnknownRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	data, err := json.Marshal(SymbolUnknownResponse{Symbols: evr.ObservedSymbols()})
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return string(data), nil
+}
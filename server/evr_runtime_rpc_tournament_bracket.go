@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+type TournamentBracketRequest struct {
+	BracketID string `json:"bracket_id,omitempty"`
+	GroupID   string `json:"group_id,omitempty"` // The group ID or guild ID, to list its brackets.
+}
+
+type TournamentBracketTeamRequest struct {
+	Name      string   `json:"name"`
+	Seed      int      `json:"seed,omitempty"`
+	PlayerIDs []string `json:"player_ids"` // User IDs or Discord IDs.
+}
+
+type TournamentBracketCreateRequest struct {
+	GroupID  string                         `json:"group_id"` // The group ID or guild ID.
+	Name     string                         `json:"name"`
+	Format   BracketFormat                  `json:"format"`
+	Level    string                         `json:"level,omitempty"`     // Defaults to mpl_arena_a.
+	TeamSize int                            `json:"team_size,omitempty"` // Defaults to 4.
+	Region   string                         `json:"region,omitempty"`    // Defaults to default.
+	Teams    []TournamentBracketTeamRequest `json:"teams"`
+	Allocate bool                           `json:"allocate,omitempty"` // Allocate the first matches now.
+}
+
+type TournamentBracketAdvanceRequest struct {
+	BracketID string `json:"bracket_id"`
+}
+
+type TournamentBracketReportRequest struct {
+	BracketID   string `json:"bracket_id"`
+	MatchID     string `json:"match_id"` // The bracket match (e.g. W1-2).
+	BlueScore   int    `json:"blue_score"`
+	OrangeScore int    `json:"orange_score"`
+}
+
+type TournamentBracketResponse struct {
+	Bracket   *TournamentBracket `json:"bracket"`
+	Standings []*BracketStanding `json:"standings"`
+	Allocated []*BracketMatch    `json:"allocated,omitempty"` // The matches allocated by this request.
+}
+
+type TournamentBracketListResponse struct {
+	Brackets []*TournamentBracket `json:"brackets"`
+}
+
+// allocatorGuildGroup resolves the group, and checks that the caller is one of its allocators, or a global operator.
+func allocatorGuildGroup(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, groupID string) (*GuildGroup, string, error) {
+	callerID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	groupID, err := enforcementAppealGroupID(ctx, db, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+	gg, err := GuildGroupLoad(ctx, nk, groupID)
+	if err != nil {
+		return nil, "", runtime.NewError(err.Error(), StatusNotFound)
+	}
+	if callerID != "" && !gg.IsAllocator(callerID) {
+		if ok, err := CheckSystemGroupMembership(ctx, db, callerID, GroupGlobalOperators); err != nil {
+			return nil, "", runtime.NewError("failed to check access", StatusInternalError)
+		} else if !ok {
+			return nil, "", runtime.NewError("user must be an allocator of the guild", StatusPermissionDenied)
+		}
+	}
+	return gg, callerID, nil
+}
+
+// tournamentBracketError maps the bracket errors to RPC errors.
+func tournamentBracketError(err error) error {
+	switch {
+	case errors.Is(err, ErrBracketMatchNotFound):
+		return runtime.NewError(err.Error(), StatusNotFound)
+	case errors.Is(err, ErrBracketMatchNotReady), errors.Is(err, ErrBracketRoundNotOver), errors.Is(err, ErrBracketComplete):
+		return runtime.NewError(err.Error(), StatusFailedPrecondition)
+	case errors.Is(err, errBracketStore):
+		return runtime.NewError(err.Error(), StatusInternalError)
+	default:
+		return runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+}
+
+func tournamentBracketResponse(b *TournamentBracket, allocated []*BracketMatch) (string, error) {
+	return configResourceResponse(TournamentBracketResponse{
+		Bracket:   b,
+		Standings: b.Standings(),
+		Allocated: allocated,
+	})
+}
+
+// memberGuildGroup resolves the group, and checks that the caller is one of its members, or a global operator.
+func memberGuildGroup(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, groupID string) (*GuildGroup, error) {
+	callerID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	groupID, err := enforcementAppealGroupID(ctx, db, groupID)
+	if err != nil {
+		return nil, err
+	}
+	gg, err := GuildGroupLoad(ctx, nk, groupID)
+	if err != nil {
+		return nil, runtime.NewError(err.Error(), StatusNotFound)
+	}
+	if callerID != "" && !gg.IsMember(callerID) {
+		if ok, err := CheckSystemGroupMembership(ctx, db, callerID, GroupGlobalOperators); err != nil {
+			return nil, runtime.NewError("failed to check access", StatusInternalError)
+		} else if !ok {
+			return nil, runtime.NewError("user must be a member of the guild", StatusPermissionDenied)
+		}
+	}
+	return gg, nil
+}
+
+// tournamentBracketCheckAllocator reads the bracket, and checks that the caller is an allocator of its guild.
+func tournamentBracketCheckAllocator(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, bracketID string) error {
+	if bracketID == "" {
+		return runtime.NewError("bracket_id is required", StatusInvalidArgument)
+	}
+	b, _, err := TournamentBracketRead(ctx, nk, bracketID)
+	if err != nil {
+		return runtime.NewError(err.Error(), StatusNotFound)
+	}
+	_, _, err = allocatorGuildGroup(ctx, db, nk, b.GroupID)
+	return err
+}
+
+// TournamentBracketRPC returns a bracket with its standings, or lists a guild's brackets, to the members of its guild.
+func TournamentBracketRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &TournamentBracketRequest{}
+	if err := parseRequest(ctx, payload, request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if request.BracketID != "" {
+		b, _, err := TournamentBracketRead(ctx, nk, request.BracketID)
+		if err != nil {
+			return "", runtime.NewError(err.Error(), StatusNotFound)
+		}
+		if _, err := memberGuildGroup(ctx, db, nk, b.GroupID); err != nil {
+			return "", err
+		}
+		return tournamentBracketResponse(b, nil)
+	}
+	gg, err := memberGuildGroup(ctx, db, nk, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+	brackets, err := TournamentBracketList(ctx, nk, gg.ID().String())
+	if err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	return configResourceResponse(TournamentBracketListResponse{Brackets: brackets})
+}
+
+// TournamentBracketCreateRPC creates a bracket, and the tournament leaderboard that its results are written to.
+func TournamentBracketCreateRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &TournamentBracketCreateRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	gg, callerID, err := allocatorGuildGroup(ctx, db, nk, request.GroupID)
+	if err != nil {
+		return "", err
+	}
+	if request.Name == "" {
+		return "", runtime.NewError("name is required", StatusInvalidArgument)
+	}
+
+	teams := make([]*BracketTeam, 0, len(request.Teams))
+	for _, t := range request.Teams {
+		team := &BracketTeam{Name: t.Name, Seed: t.Seed, PlayerIDs: make([]string, 0, len(t.PlayerIDs))}
+		for _, id := range t.PlayerIDs {
+			if uuid.FromStringOrNil(id).IsNil() {
+				// Assume the ID is a Discord ID.
+				userID, err := GetUserIDByDiscordID(ctx, db, id)
+				if err != nil || userID == "" {
+					return "", runtime.NewError("discord user not found: "+id, StatusNotFound)
+				}
+				id = userID
+			}
+			team.PlayerIDs = append(team.PlayerIDs, id)
+		}
+		teams = append(teams, team)
+	}
+
+	b, err := NewTournamentBracket(uuid.Must(uuid.NewV4()).String(), gg.ID().String(), request.Name, request.Format, teams, callerID, time.Now().UTC())
+	if err != nil {
+		return "", tournamentBracketError(err)
+	}
+	if request.Level != "" {
+		b.Level = evr.ToSymbol(request.Level)
+	}
+	if request.TeamSize > 0 {
+		if request.TeamSize > 5 {
+			return "", runtime.NewError("team_size must be at most 5", StatusInvalidArgument)
+		}
+		b.TeamSize = request.TeamSize
+	}
+	b.Region = request.Region
+
+	if err := TournamentBracketCreateLeaderboard(ctx, nk, b); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	if err := TournamentBracketWrite(ctx, nk, b, "*"); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInternalError)
+	}
+	logger.WithFields(map[string]any{"bracket_id": b.ID, "gid": b.GroupID, "format": b.Format, "teams": len(b.Teams), "uid": callerID}).Info("Tournament bracket created")
+
+	var allocated []*BracketMatch
+	if request.Allocate {
+		stored, matches, err := tournamentBracketAllocateStored(ctx, logger, nk, b.ID)
+		if err != nil {
+			logger.WithField("error", err).Warn("Failed to allocate bracket matches")
+		}
+		if stored != nil {
+			b, allocated = stored, matches
+		}
+	}
+	return tournamentBracketResponse(b, allocated)
+}
+
+// TournamentBracketAdvanceRPC allocates the bracket's ready matches, first pairing the next Swiss round if the current one is over.
+func TournamentBracketAdvanceRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &TournamentBracketAdvanceRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if err := tournamentBracketCheckAllocator(ctx, db, nk, request.BracketID); err != nil {
+		return "", err
+	}
+	b, allocated, err := TournamentBracketAdvance(ctx, logger, nk, request.BracketID)
+	if b == nil {
+		return "", tournamentBracketError(err)
+	} else if err != nil && len(allocated) == 0 {
+		return "", runtime.NewError(err.Error(), StatusUnavailable)
+	}
+	return tournamentBracketResponse(b, allocated)
+}
+
+// TournamentBracketReportRPC records, or corrects, the result of a bracket match.
+func TournamentBracketReportRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	request := &TournamentBracketReportRequest{}
+	if err := json.Unmarshal([]byte(payload), request); err != nil {
+		return "", runtime.NewError(err.Error(), StatusInvalidArgument)
+	}
+	if err := tournamentBracketCheckAllocator(ctx, db, nk, request.BracketID); err != nil {
+		return "", err
+	}
+	b, _, allocated, err := TournamentBracketReport(ctx, logger, nk, request.BracketID, request.MatchID, request.BlueScore, request.OrangeScore)
+	if err != nil {
+		return "", tournamentBracketError(err)
+	}
+	userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+	logger.WithFields(map[string]any{"bracket_id": b.ID, "bracket_match_id": request.MatchID, "blue_score": request.BlueScore, "orange_score": request.OrangeScore, "uid": userID}).Info("Tournament bracket result reported")
+
+	return tournamentBracketResponse(b, allocated)
+}
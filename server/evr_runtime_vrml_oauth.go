@@ -56,8 +56,13 @@ func NewVRMLOAuthFlow(clientID, redirectURL string, timeout time.Duration) (*VRM
 	return oauthData, nil
 }
 
-// RedirectRPC is called by the client after they have authenticated with VRML
-func (v *VRMLScanQueue) RedirectRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+type VRMLRedirectRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// VRMLRedirectRPC is called by the client after they have authenticated with VRML
+func VRMLRedirectRPC(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	envVars, _ := ctx.Value(runtime.RUNTIME_CTX_ENV).(map[string]string)
 
 	if envVars == nil || envVars["VRML_OAUTH_CLIENT_ID"] == "" || envVars["VRML_OAUTH_REDIRECT_URL"] == "" {
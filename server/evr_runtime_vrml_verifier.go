@@ -71,11 +71,6 @@ func NewVRMLScanQueue(ctx context.Context, logger runtime.Logger, db *sql.DB, nk
 		oauthClientID:    oauthCLientID,
 	}
 
-	// Register the RPC function
-	if err := initializer.RegisterRpc("oauth/vrml_redirect", verifier.RedirectRPC); err != nil {
-		return nil, fmt.Errorf("failed to register VRML redirect RPC: %v", err)
-	}
-
 	verifier.Start()
 
 	return verifier, nil
@@ -36,21 +36,23 @@ type SessionParameters struct {
 	relayOutgoing       bool                // The user wants (some) outgoing messages relayed to them via discord
 	enableAllRemoteLogs bool                // The user wants debug information
 	serverTags          []string            // []string of the server tags
+	serverCapacity      int                 // The player slots of the server, or zero for a full lobby
 	serverGuilds        []string            // []string of the server guilds
 	serverRegions       []string            // []string of the server regions
 	defaultRegion       string              // The default region code for the server
 	urlParameters       map[string][]string // The URL parameters
 
-	profile                      *EVRProfile                      // The account
-	matchmakingSettings          *MatchmakingSettings             // The matchmaking settings
-	guildGroups                  map[string]*GuildGroup           // map[string]*GuildGroup
-	earlyQuitConfig              *atomic.Pointer[EarlyQuitConfig] // The early quit config
-	isGoldNameTag                *atomic.Bool                     // If this user should have a gold name tag
-	lastMatchmakingError         *atomic.Error                    // The last matchmaking error
-	latencyHistory               *atomic.Pointer[LatencyHistory]  // The latency history
-	isIGPOpen                    *atomic.Bool                     // The user has IGPU open
-	gameModeSuspensionsByGroupID ActiveGuildEnforcements          // The active suspension records
-	ignoreDisabledAlternates     bool                             // Ignore disabled
+	profile                      *EVRProfile                       // The account
+	matchmakingSettings          *MatchmakingSettings              // The matchmaking settings
+	guildGroups                  map[string]*GuildGroup            // map[string]*GuildGroup
+	earlyQuitConfig              *atomic.Pointer[EarlyQuitConfig]  // The early quit config
+	isGoldNameTag                *atomic.Bool                      // If this user should have a gold name tag
+	lastMatchmakingError         *atomic.Error                     // The last matchmaking error
+	latencyHistory               *atomic.Pointer[LatencyHistory]   // The latency history
+	isIGPOpen                    *atomic.Bool                      // The user has IGPU open
+	gameModeSuspensionsByGroupID ActiveGuildEnforcements           // The active suspension records
+	suspensionAppeals            map[string]GuildEnforcementAppeal // The appeals of the suspension records, by record ID
+	ignoreDisabledAlternates     bool                              // Ignore disabled
 }
 
 func (s SessionParameters) UserID() string {
@@ -340,6 +340,7 @@ func PlayerStatisticsGetID(ctx context.Context, db *sql.DB, nk runtime.NakamaMod
 	}
 
 	playerStatistics := evr.NewStatistics()
+	derivedStatistics := DerivedStatistics()
 
 	boardMap := make(map[string]*evr.StatisticValue)
 	boardIDs := make([]string, 0, len(boardMap))
@@ -392,6 +393,15 @@ func PlayerStatisticsGetID(ctx context.Context, db *sql.DB, nk runtime.NakamaMod
 					}
 				}
 			}
+
+			// Derived statistics are only returned in the board map.
+			for _, d := range derivedStatistics {
+				if d.Applies(m, r) {
+					boardID := StatisticBoardID(groupID, m, d.Name, r)
+					boardIDs = append(boardIDs, boardID)
+					boardMap[boardID] = &evr.StatisticValue{}
+				}
+			}
 		}
 	}
 
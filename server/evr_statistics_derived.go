@@ -0,0 +1,492 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+// DerivedStatistic is a statistic computed from other statistics of the same player, mode and reset schedule,
+// e.g. a win rate of "ArenaWins / (ArenaWins + ArenaLosses)". It is kept in its own ranked leaderboard, with
+// the same board ID scheme as the raw statistics, and is updated whenever one of its inputs is written.
+//
+// Derived statistics are defined in the service settings.
+type DerivedStatistic struct {
+	Name           string              `json:"name"`                      // The stat name of its board. It must not be a raw statistic.
+	Mode           evr.Symbol          `json:"mode"`                      // The mode of the input boards, and of the derived board.
+	ResetSchedules []evr.ResetSchedule `json:"reset_schedules,omitempty"` // Defaults to daily, weekly and alltime.
+	Formula        string              `json:"formula"`                   // +, -, *, / and parentheses over stat names and numbers.
+	Requires       map[string]float64  `json:"requires,omitempty"`        // The minimum value of each stat before the statistic is written, e.g. {"GamesPlayed": 10}.
+	Description    string              `json:"description,omitempty"`
+
+	formula *statisticFormula
+}
+
+// rawStatisticNames are the stat names written by the server, which derived statistics can't replace.
+var rawStatisticNames = func() []string {
+	names := []string{
+		GamesPlayedStatisticID,
+		TeamSkillRatingMuStatisticID,
+		TeamSkillRatingSigmaStatisticID,
+		PlayerSkillRatingMuStatisticID,
+		PlayerSkillRatingSigmaStatisticID,
+		TeamSkillRatingOrdinalStatisticID,
+		PlayerSkillRatingOrdinalStatisticID,
+		LobbyTimeStatisticID,
+		GameServerTimeStatisticsID,
+		EarlyQuitStatisticID,
+		PlayerLoudnessStatisticID,
+	}
+	t := reflect.TypeOf(evr.MatchTypeStats{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]; name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}()
+
+func (d *DerivedStatistic) Validate() error {
+	if !isStatisticName(d.Name) {
+		return fmt.Errorf("invalid name: %q", d.Name)
+	}
+	if slices.Contains(rawStatisticNames, d.Name) {
+		return fmt.Errorf("%s: name is a raw statistic", d.Name)
+	}
+	if !slices.Contains(ValidLeaderboardModes, d.Mode) {
+		return fmt.Errorf("%s: invalid mode: %s", d.Name, d.Mode)
+	}
+	for _, r := range d.ResetSchedules {
+		if r != evr.ResetScheduleDaily && r != evr.ResetScheduleWeekly && r != evr.ResetScheduleAllTime {
+			return fmt.Errorf("%s: invalid reset schedule: %s", d.Name, r)
+		}
+	}
+	f, err := parseStatisticFormula(d.Formula)
+	if err != nil {
+		return fmt.Errorf("%s: %w", d.Name, err)
+	}
+	if slices.Contains(f.inputs, d.Name) {
+		return fmt.Errorf("%s: formula refers to itself", d.Name)
+	}
+	for name := range d.Requires {
+		if !isStatisticName(name) {
+			return fmt.Errorf("%s: invalid required stat: %q", d.Name, name)
+		}
+	}
+	d.formula = f
+	return nil
+}
+
+// Schedules returns the reset schedules that the statistic is kept for.
+func (d *DerivedStatistic) Schedules() []evr.ResetSchedule {
+	if len(d.ResetSchedules) == 0 {
+		return []evr.ResetSchedule{evr.ResetScheduleDaily, evr.ResetScheduleWeekly, evr.ResetScheduleAllTime}
+	}
+	return d.ResetSchedules
+}
+
+// Inputs returns the stats that the formula and its requirements read.
+func (d *DerivedStatistic) Inputs() []string {
+	inputs := slices.Clone(d.formula.inputs)
+	for name := range d.Requires {
+		if !slices.Contains(inputs, name) {
+			inputs = append(inputs, name)
+		}
+	}
+	slices.Sort(inputs)
+	return inputs
+}
+
+func (d *DerivedStatistic) Applies(mode evr.Symbol, resetSchedule evr.ResetSchedule) bool {
+	return d.Mode == mode && slices.Contains(d.Schedules(), resetSchedule)
+}
+
+// Compute evaluates the statistic from the input values; missing inputs are zero.
+// It returns false if a requirement isn't met, or the result is undefined (e.g. a division by zero).
+func (d *DerivedStatistic) Compute(values map[string]float64) (float64, bool) {
+	for name, min := range d.Requires {
+		if values[name] < min {
+			return 0, false
+		}
+	}
+	v, ok := d.formula.eval(values)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// ValidateDerivedStatistics checks each definition, and that no two definitions share a board, or read another's board.
+func ValidateDerivedStatistics(defs []*DerivedStatistic) error {
+	names := make(map[evr.Symbol][]string)
+	for _, d := range defs {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		names[d.Mode] = append(names[d.Mode], d.Name)
+	}
+	for _, d := range defs {
+		if n := len(slices.DeleteFunc(slices.Clone(names[d.Mode]), func(s string) bool { return s != d.Name })); n > 1 {
+			return fmt.Errorf("%s: defined %d times for %s", d.Name, n, d.Mode)
+		}
+		for _, input := range d.Inputs() {
+			if slices.Contains(names[d.Mode], input) {
+				return fmt.Errorf("%s: reads derived statistic %s", d.Name, input)
+			}
+		}
+	}
+	return nil
+}
+
+// DerivedStatistics returns the derived statistics in the service settings, or none if they are invalid.
+func DerivedStatistics() []*DerivedStatistic {
+	s := ServiceSettings()
+	if s == nil || len(s.DerivedStatistics) == 0 {
+		return nil
+	}
+	defs := make([]*DerivedStatistic, 0, len(s.DerivedStatistics))
+	for _, d := range s.DerivedStatistics {
+		c := *d
+		defs = append(defs, &c)
+	}
+	if ValidateDerivedStatistics(defs) != nil {
+		return nil
+	}
+	return defs
+}
+
+// DerivedStatisticEntries computes the player's derived statistics for the mode and reset schedule, from the
+// leaderboard records of their inputs, and returns them as entries to set.
+func DerivedStatisticEntries(ctx context.Context, db *sql.DB, defs []*DerivedStatistic, groupID string, mode evr.Symbol, resetSchedule evr.ResetSchedule, userID, displayName string) ([]*StatisticsQueueEntry, error) {
+	boardIDs := make([]string, 0)
+	for _, d := range defs {
+		if !d.Applies(mode, resetSchedule) {
+			continue
+		}
+		for _, input := range d.Inputs() {
+			if id := StatisticBoardID(groupID, mode, input, resetSchedule); !slices.Contains(boardIDs, id) {
+				boardIDs = append(boardIDs, id)
+			}
+		}
+	}
+	if len(boardIDs) == 0 {
+		return nil, nil
+	}
+
+	records, err := statisticRecordValues(ctx, db, userID, boardIDs)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(records))
+	for id, v := range records {
+		if _, _, statName, _, err := ParseStatisticBoardID(id); err == nil {
+			values[statName] = v
+		}
+	}
+
+	entries := make([]*StatisticsQueueEntry, 0)
+	for _, d := range defs {
+		if !d.Applies(mode, resetSchedule) {
+			continue
+		}
+		v, ok := d.Compute(values)
+		if !ok {
+			continue
+		}
+		score, subscore, err := Float64ToScore(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert derived stat %s: %w", d.Name, err)
+		}
+		entries = append(entries, &StatisticsQueueEntry{
+			BoardMeta: LeaderboardMeta{
+				GroupID:       groupID,
+				Mode:          mode,
+				StatName:      d.Name,
+				Operator:      OperatorSet,
+				ResetSchedule: resetSchedule,
+			},
+			UserID:      userID,
+			DisplayName: displayName,
+			Score:       score,
+			Subscore:    subscore,
+		})
+	}
+	return entries, nil
+}
+
+// statisticRecordValues returns the decoded values of the owner's current records on the boards.
+func statisticRecordValues(ctx context.Context, db *sql.DB, ownerID string, boardIDs []string) (map[string]float64, error) {
+	query := `
+		SELECT
+			lr.leaderboard_id,
+			lr.score,
+			lr.subscore
+		FROM
+			leaderboard_record lr
+		WHERE
+			lr.owner_id = $1
+			AND lr.leaderboard_id = ANY($2)
+			AND (lr.expiry_time > NOW() OR lr.expiry_time = '1970-01-01 00:00:00+00') -- Include "alltime" records
+			`
+	rows, err := db.QueryContext(ctx, query, ownerID, boardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard records: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]float64, len(boardIDs))
+	for rows.Next() {
+		var (
+			id              string
+			score, subscore int64
+		)
+		if err := rows.Scan(&id, &score, &subscore); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard record: %w", err)
+		}
+		if v, err := ScoreToFloat64(score, subscore); err == nil {
+			values[id] = v
+		}
+	}
+	return values, rows.Err()
+}
+
+// DerivedStatisticValue is a player's derived statistic, as returned next to the raw statistics.
+type DerivedStatisticValue struct {
+	Name          string            `json:"name"`
+	Mode          evr.Symbol        `json:"mode"`
+	ResetSchedule evr.ResetSchedule `json:"reset_schedule"`
+	Value         float64           `json:"value"`
+}
+
+// DerivedStatisticValues picks the derived statistics out of the boards returned by PlayerStatisticsGetID.
+func DerivedStatisticValues(defs []*DerivedStatistic, boardMap map[string]*evr.StatisticValue) []*DerivedStatisticValue {
+	values := make([]*DerivedStatisticValue, 0)
+	for id, v := range boardMap {
+		_, mode, statName, resetSchedule, err := ParseStatisticBoardID(id)
+		if err != nil || v.GetCount() == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(defs, func(d *DerivedStatistic) bool {
+			return d.Name == statName && d.Applies(mode, evr.ResetSchedule(resetSchedule))
+		}) {
+			continue
+		}
+		values = append(values, &DerivedStatisticValue{
+			Name:          statName,
+			Mode:          mode,
+			ResetSchedule: evr.ResetSchedule(resetSchedule),
+			Value:         v.GetValue(),
+		})
+	}
+	slices.SortFunc(values, func(a, b *DerivedStatisticValue) int {
+		if c := strings.Compare(a.Mode.String(), b.Mode.String()); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.ResetSchedule), string(b.ResetSchedule))
+	})
+	return values
+}
+
+func isStatisticName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+var ErrStatisticFormulaSyntax = errors.New("invalid formula")
+
+// statisticFormula is a parsed arithmetic expression over stat names.
+type statisticFormula struct {
+	root   statisticFormulaNode
+	inputs []string
+}
+
+func (f *statisticFormula) eval(values map[string]float64) (float64, bool) {
+	return f.root.eval(values)
+}
+
+type statisticFormulaNode interface {
+	eval(values map[string]float64) (float64, bool)
+}
+
+type statisticFormulaNumber float64
+
+func (n statisticFormulaNumber) eval(map[string]float64) (float64, bool) {
+	return float64(n), true
+}
+
+type statisticFormulaStat string
+
+func (s statisticFormulaStat) eval(values map[string]float64) (float64, bool) {
+	return values[string(s)], true
+}
+
+type statisticFormulaNegate struct {
+	x statisticFormulaNode
+}
+
+func (n statisticFormulaNegate) eval(values map[string]float64) (float64, bool) {
+	v, ok := n.x.eval(values)
+	return -v, ok
+}
+
+type statisticFormulaBinary struct {
+	op   byte
+	l, r statisticFormulaNode
+}
+
+func (b statisticFormulaBinary) eval(values map[string]float64) (float64, bool) {
+	l, ok := b.l.eval(values)
+	if !ok {
+		return 0, false
+	}
+	r, ok := b.r.eval(values)
+	if !ok {
+		return 0, false
+	}
+	switch b.op {
+	case '+':
+		return l + r, true
+	case '-':
+		return l - r, true
+	case '*':
+		return l * r, true
+	case '/':
+		if r == 0 {
+			return 0, false
+		}
+		return l / r, true
+	}
+	return 0, false
+}
+
+// parseStatisticFormula parses +, -, *, / (with the usual precedence), unary minus, parentheses, numbers and stat names.
+func parseStatisticFormula(s string) (*statisticFormula, error) {
+	p := &statisticFormulaParser{s: s}
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrStatisticFormulaSyntax, p.s[p.pos], p.pos)
+	}
+	if len(p.inputs) == 0 {
+		return nil, fmt.Errorf("%w: no stats", ErrStatisticFormulaSyntax)
+	}
+	return &statisticFormula{root: root, inputs: p.inputs}, nil
+}
+
+type statisticFormulaParser struct {
+	s      string
+	pos    int
+	inputs []string
+}
+
+func (p *statisticFormulaParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *statisticFormulaParser) peek() byte {
+	if p.skipSpace(); p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *statisticFormulaParser) expr() (statisticFormulaNode, error) {
+	l, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '+' || c == '-'; c = p.peek() {
+		p.pos++
+		r, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		l = statisticFormulaBinary{op: c, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *statisticFormulaParser) term() (statisticFormulaNode, error) {
+	l, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '*' || c == '/'; c = p.peek() {
+		p.pos++
+		r, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		l = statisticFormulaBinary{op: c, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *statisticFormulaParser) factor() (statisticFormulaNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("%w: unexpected end", ErrStatisticFormulaSyntax)
+	case c == '-':
+		p.pos++
+		x, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return statisticFormulaNegate{x: x}, nil
+	case c == '(':
+		p.pos++
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("%w: missing ) at %d", ErrStatisticFormulaSyntax, p.pos)
+		}
+		p.pos++
+		return x, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] == '.' || (p.s[p.pos] >= '0' && p.s[p.pos] <= '9')) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q", ErrStatisticFormulaSyntax, p.s[start:p.pos])
+		}
+		return statisticFormulaNumber(v), nil
+	case c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+		start := p.pos
+		for p.pos < len(p.s) && isStatisticName(p.s[start:p.pos+1]) {
+			p.pos++
+		}
+		name := p.s[start:p.pos]
+		if !slices.Contains(p.inputs, name) {
+			p.inputs = append(p.inputs, name)
+		}
+		return statisticFormulaStat(name), nil
+	}
+	return nil, fmt.Errorf("%w: unexpected %q at %d", ErrStatisticFormulaSyntax, c, p.pos)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+func TestParseStatisticFormula(t *testing.T) {
+	values := map[string]float64{"ArenaWins": 6, "ArenaLosses": 2, "Goals": 9, "GamesPlayed": 3}
+
+	testCases := []struct {
+		formula  string
+		expected float64
+		inputs   []string
+	}{
+		{"ArenaWins / (ArenaWins + ArenaLosses)", 0.75, []string{"ArenaWins", "ArenaLosses"}},
+		{"Goals / GamesPlayed", 3, []string{"Goals", "GamesPlayed"}},
+		{"100 * ArenaWins / GamesPlayed", 200, []string{"ArenaWins", "GamesPlayed"}},
+		{"ArenaWins - ArenaLosses * 2", 2, []string{"ArenaWins", "ArenaLosses"}},
+		{"-ArenaLosses + .5", -1.5, []string{"ArenaLosses"}},
+		{"Missing + 1", 1, []string{"Missing"}},
+		{"  ( ( Goals ) )  ", 9, []string{"Goals"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.formula, func(t *testing.T) {
+			f, err := parseStatisticFormula(tc.formula)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			v, ok := f.eval(values)
+			if !ok || math.Abs(v-tc.expected) > 1e-9 {
+				t.Errorf("expected %f, got %f (%v)", tc.expected, v, ok)
+			}
+			if !slices.Equal(f.inputs, tc.inputs) {
+				t.Errorf("expected inputs %v, got %v", tc.inputs, f.inputs)
+			}
+		})
+	}
+
+	for _, formula := range []string{"", "1 + 2", "Goals +", "(Goals", "Goals)", "Goals % 2", "Goals GamesPlayed", "1.2.3 * Goals"} {
+		if _, err := parseStatisticFormula(formula); !errors.Is(err, ErrStatisticFormulaSyntax) {
+			t.Errorf("%q: expected a syntax error, got %v", formula, err)
+		}
+	}
+}
+
+func TestDerivedStatistic_Compute(t *testing.T) {
+	d := &DerivedStatistic{
+		Name:     "WinRate",
+		Mode:     evr.ModeArenaPublic,
+		Formula:  "ArenaWins / (ArenaWins + ArenaLosses)",
+		Requires: map[string]float64{"GamesPlayed": 5},
+	}
+	if err := d.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := d.Inputs(); !slices.Equal(got, []string{"ArenaLosses", "ArenaWins", "GamesPlayed"}) {
+		t.Errorf("unexpected inputs: %v", got)
+	}
+
+	if _, ok := d.Compute(map[string]float64{"ArenaWins": 1, "ArenaLosses": 1, "GamesPlayed": 2}); ok {
+		t.Error("expected no value below the required games")
+	}
+	if _, ok := d.Compute(map[string]float64{"GamesPlayed": 5}); ok {
+		t.Error("expected no value for a division by zero")
+	}
+	if v, ok := d.Compute(map[string]float64{"ArenaWins": 3, "ArenaLosses": 1, "GamesPlayed": 5}); !ok || v != 0.75 {
+		t.Errorf("expected 0.75, got %f (%v)", v, ok)
+	}
+
+	if !d.Applies(evr.ModeArenaPublic, evr.ResetScheduleWeekly) || d.Applies(evr.ModeCombatPublic, evr.ResetScheduleWeekly) {
+		t.Error("expected the statistic to apply to every schedule of its mode only")
+	}
+}
+
+func TestValidateDerivedStatistics(t *testing.T) {
+	valid := func() *DerivedStatistic {
+		return &DerivedStatistic{Name: "GoalsPerGame2", Mode: evr.ModeArenaPublic, Formula: "Goals / GamesPlayed"}
+	}
+	if err := ValidateDerivedStatistics([]*DerivedStatistic{valid()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		defs func() []*DerivedStatistic
+	}{
+		{"raw statistic name", func() []*DerivedStatistic { d := valid(); d.Name = "Goals"; return []*DerivedStatistic{d} }},
+		{"invalid name", func() []*DerivedStatistic { d := valid(); d.Name = "Goals:Per"; return []*DerivedStatistic{d} }},
+		{"invalid mode", func() []*DerivedStatistic { d := valid(); d.Mode = evr.ToSymbol("nope"); return []*DerivedStatistic{d} }},
+		{"invalid schedule", func() []*DerivedStatistic {
+			d := valid()
+			d.ResetSchedules = []evr.ResetSchedule{"monthly"}
+			return []*DerivedStatistic{d}
+		}},
+		{"self reference", func() []*DerivedStatistic {
+			d := valid()
+			d.Formula = "GoalsPerGame2 + 1"
+			return []*DerivedStatistic{d}
+		}},
+		{"duplicate", func() []*DerivedStatistic { return []*DerivedStatistic{valid(), valid()} }},
+		{"reads a derived statistic", func() []*DerivedStatistic {
+			d := valid()
+			d.Name = "Other"
+			d.Formula = "GoalsPerGame2 * 2"
+			return []*DerivedStatistic{valid(), d}
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateDerivedStatistics(tc.defs()); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	// The same name in another mode is a different board.
+	other := valid()
+	other.Mode = evr.ModeArenaPrivate
+	if err := ValidateDerivedStatistics([]*DerivedStatistic{valid(), other}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDerivedStatisticValues(t *testing.T) {
+	d := &DerivedStatistic{Name: "WinRate", Mode: evr.ModeArenaPublic, Formula: "ArenaWins / GamesPlayed", ResetSchedules: []evr.ResetSchedule{evr.ResetScheduleAllTime}}
+	if err := d.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	boardMap := map[string]*evr.StatisticValue{
+		StatisticBoardID("g", evr.ModeArenaPublic, "WinRate", evr.ResetScheduleAllTime):   {Count: 1, Value: 0.5},
+		StatisticBoardID("g", evr.ModeArenaPublic, "WinRate", evr.ResetScheduleDaily):     {Count: 1, Value: 0.9}, // Not a schedule of the statistic.
+		StatisticBoardID("g", evr.ModeArenaPublic, "ArenaWins", evr.ResetScheduleAllTime): {Count: 1, Value: 4},
+		StatisticBoardID("g", evr.ModeArenaPrivate, "WinRate", evr.ResetScheduleAllTime):  {Count: 0},
+	}
+	values := DerivedStatisticValues([]*DerivedStatistic{d}, boardMap)
+	if len(values) != 1 {
+		t.Fatalf("expected 1 value, got %d", len(values))
+	}
+	if v := values[0]; v.Name != "WinRate" || v.Mode != evr.ModeArenaPublic || v.ResetSchedule != evr.ResetScheduleAllTime || v.Value != 0.5 {
+		t.Errorf("unexpected value: %+v", v)
+	}
+}
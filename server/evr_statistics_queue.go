@@ -3,11 +3,27 @@ package server
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"slices"
+	"strings"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const (
+	StatisticsQueueBatchSize       = 200
+	StatisticsQueueMaxAttempts     = 8
+	StatisticsQueuePollInterval    = 5 * time.Second
+	StatisticsQueueMetricsInterval = 30 * time.Second
+	StatisticsQueueBackoffBase     = 5 * time.Second
+	StatisticsQueueBackoffMax      = 30 * time.Minute
 )
 
 type StatisticsQueueEntry struct {
@@ -37,16 +53,35 @@ func (e StatisticsQueueEntry) Override() *int {
 	return &o
 }
 
+// lockKey identifies the leaderboard record that the entry writes to, for ordering the record locks.
+func (e StatisticsQueueEntry) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(e.BoardMeta.ID()))
+	h.Write([]byte{0})
+	h.Write([]byte(e.UserID))
+	return int64(h.Sum64())
+}
+
+// StatisticsQueue writes leaderboard records through a table, so that queued entries survive restarts.
+//
+// Entries are stored before Add returns, and applied by a worker on each node. The worker claims a batch
+// with SKIP LOCKED, so that nodes never apply the same entry concurrently, and writes each record in the
+// same transaction, locking it with SELECT ... FOR UPDATE, so that increments to the same record are
+// serialized, and each record is written if, and only if, its entry is removed. Entries that fail are
+// retried with exponential backoff, and moved to evr_statistics_dead_letter after StatisticsQueueMaxAttempts.
 type StatisticsQueue struct {
 	logger runtime.Logger
-	ch     chan []*StatisticsQueueEntry
+	db     *sql.DB
+	nk     runtime.NakamaModule
+	wakeCh chan struct{}
 }
 
 func NewStatisticsQueue(logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule) *StatisticsQueue {
-	ch := make(chan []*StatisticsQueueEntry, 8*3*100) // three matches ending at the same time, 100 records per player
 	r := &StatisticsQueue{
 		logger: logger,
-		ch:     ch,
+		db:     db,
+		nk:     nk,
+		wakeCh: make(chan struct{}, 1),
 	}
 
 	go func() {
@@ -54,6 +89,8 @@ func NewStatisticsQueue(logger runtime.Logger, db *sql.DB, nk runtime.NakamaModu
 		ctx := context.Background()
 
 		pruneTicker := time.NewTicker(24 * time.Hour)
+		pollTicker := time.NewTicker(StatisticsQueuePollInterval)
+		metricsTicker := time.NewTicker(StatisticsQueueMetricsInterval)
 
 		for {
 			select {
@@ -64,123 +101,27 @@ func NewStatisticsQueue(logger runtime.Logger, db *sql.DB, nk runtime.NakamaModu
 				if err := pruneExpiredLeaderboardRecords(ctx, db); err != nil {
 					logger.WithField("error", err).Error("Failed to prune expired leaderboard records")
 				}
-			case entries := <-ch:
-				for _, e := range entries {
-					// This expects the score and subscore to already be translated to the correct values.
-					if e.Score < 0 || e.Subscore < 0 {
-						logger.WithFields(map[string]any{
-							"leaderboard_id": e.BoardMeta.ID(),
-							"score":          e.Score,
-							"subscore":       e.Subscore,
-						}).Warn("Negative score")
-						continue
-					} else if e.Score == 0 && e.Subscore == 0 {
-						continue
-					}
-
-					if !slices.Contains(ValidLeaderboardModes, e.BoardMeta.Mode) {
-						continue
-					}
-
-					// If the operator is increment or decrement, read the current value, decode it, add the delta, and write it back.
-					// This is because the leaderboard uses a float64 encoding that is not compatible with simple integer arithmetic.
-					if e.BoardMeta.Operator == OperatorIncrement || e.BoardMeta.Operator == OperatorDecrement {
-						var currentVal float64
-						// Handle the case where the leaderboard might not exist yet.
-						_, ownerRecords, _, _, err := nk.LeaderboardRecordsList(ctx, e.BoardMeta.ID(), []string{e.UserID}, 1, "", 0)
-						if err != nil {
-							if err == ErrLeaderboardNotFound {
-								// Leaderboard doesn't exist, so current value is 0.
-								currentVal = 0
-							} else {
-								logger.WithFields(map[string]any{
-									"error":          err.Error(),
-									"leaderboard_id": e.BoardMeta.ID(),
-								}).Error("Failed to list leaderboard records")
-								continue
-							}
-						} else {
-							if len(ownerRecords) > 0 {
-								currentVal, err = ScoreToFloat64(ownerRecords[0].Score, ownerRecords[0].Subscore)
-								if err != nil {
-									logger.WithFields(map[string]any{
-										"error":          err.Error(),
-										"leaderboard_id": e.BoardMeta.ID(),
-									}).Error("Failed to decode current score")
-									continue
-								}
-							} else {
-								// Record doesn't exist, so current value is 0.
-								currentVal = 0
-							}
-						}
-
-						deltaVal, err := ScoreToFloat64(e.Score, e.Subscore)
-						if err != nil {
-							logger.WithFields(map[string]any{
-								"error":          err.Error(),
-								"leaderboard_id": e.BoardMeta.ID(),
-							}).Error("Failed to decode delta score")
-							continue
-						}
-
-						var newVal float64
-						if e.BoardMeta.Operator == OperatorIncrement {
-							newVal = currentVal + deltaVal
-						} else {
-							newVal = currentVal - deltaVal
-						}
-
-						newScore, newSubscore, err := Float64ToScore(newVal)
-						if err != nil {
-							logger.WithFields(map[string]any{
-								"error":          err.Error(),
-								"leaderboard_id": e.BoardMeta.ID(),
-							}).Error("Failed to encode new score")
-							continue
-						}
-
-						// Update the entry to be a SET operation with the new value
-						e.Score = newScore
-						e.Subscore = newSubscore
-						e.BoardMeta.Operator = OperatorSet
-					}
-
-					var md map[string]any
-					if e.Metadata != nil {
-						md = make(map[string]any)
-						for k, v := range e.Metadata {
-							md[k] = v
-						}
-					}
-
-					if _, err := nk.LeaderboardRecordWrite(ctx, e.BoardMeta.ID(), e.UserID, e.DisplayName, e.Score, e.Subscore, md, e.Override()); err != nil {
-
-						// Try to create the leaderboard
-						if err = nk.LeaderboardCreate(ctx, e.BoardMeta.ID(), true, "desc", string(e.BoardMeta.Operator), ResetScheduleToCron(e.BoardMeta.ResetSchedule), map[string]any{}, true); err != nil {
-
-							logger.WithFields(map[string]any{
-								"leaderboard_id": e.BoardMeta.ID(),
-								"error":          err.Error(),
-							}).Error("Failed to create leaderboard")
-
-						} else {
-							logger.WithFields(map[string]any{
-								"leaderboard_id": e.BoardMeta.ID(),
-							}).Debug("Leaderboard created")
-
-							if _, err := nk.LeaderboardRecordWrite(ctx, e.BoardMeta.ID(), e.UserID, e.DisplayName, e.Score, e.Subscore, md, e.Override()); err != nil {
-								logger.WithFields(map[string]any{
-									"error":          err.Error(),
-									"leaderboard_id": e.BoardMeta.ID(),
-									"user_id":        e.UserID,
-									"score":          e.Score,
-									"subscore":       e.Subscore,
-								}).Error("Failed to write leaderboard record")
-							}
-
-						}
-					}
+				continue
+
+			case <-metricsTicker.C:
+				if err := r.recordMetrics(ctx); err != nil {
+					logger.WithField("error", err).Warn("Failed to record statistics queue metrics")
+				}
+				continue
+
+			case <-pollTicker.C:
+			case <-r.wakeCh:
+			}
+
+			// Drain the queue, one batch at a time.
+			for {
+				n, err := r.processBatch(ctx)
+				if err != nil {
+					logger.WithField("error", err).Error("Failed to process statistics queue")
+					break
+				}
+				if n < StatisticsQueueBatchSize {
+					break
 				}
 			}
 		}
@@ -189,30 +130,523 @@ func NewStatisticsQueue(logger runtime.Logger, db *sql.DB, nk runtime.NakamaModu
 	return r
 }
 
+// statisticsQueueEntryValid reports whether the entry should be written at all.
+func statisticsQueueEntryValid(logger runtime.Logger, e *StatisticsQueueEntry) bool {
+	// This expects the score and subscore to already be translated to the correct values.
+	if e.Score < 0 || e.Subscore < 0 {
+		logger.WithFields(map[string]any{
+			"leaderboard_id": e.BoardMeta.ID(),
+			"score":          e.Score,
+			"subscore":       e.Subscore,
+		}).Warn("Negative score")
+		return false
+	} else if e.Score == 0 && e.Subscore == 0 {
+		return false
+	}
+	if !slices.Contains(ValidLeaderboardModes, e.BoardMeta.Mode) {
+		return false
+	}
+	if uuid.FromStringOrNil(e.UserID).IsNil() {
+		logger.WithFields(map[string]any{
+			"leaderboard_id": e.BoardMeta.ID(),
+			"user_id":        e.UserID,
+		}).Warn("Invalid statistics user ID")
+		return false
+	}
+	return true
+}
+
+// Add stores the entries. They are durable once it returns without an error.
 func (r *StatisticsQueue) Add(entries []*StatisticsQueueEntry) error {
+	ctx := context.Background()
 
-	select {
-	case r.ch <- entries:
+	entries = slices.DeleteFunc(slices.Clone(entries), func(e *StatisticsQueueEntry) bool {
+		return !statisticsQueueEntryValid(r.logger, e)
+	})
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err := ExecuteInTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, e := range entries {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("failed to marshal statistics entry: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO evr_statistics_queue (leaderboard_id, user_id, data)
+				VALUES ($1, $2, $3)`,
+				e.BoardMeta.ID(), e.UserID, data); err != nil {
+				return fmt.Errorf("failed to insert statistics entry: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		r.logger.WithFields(map[string]any{
 			"count": len(entries),
-		}).Debug("Leaderboard record queued")
-		return nil
+			"error": err,
+		}).Warn("Failed to queue leaderboard records")
+		return err
+	}
+
+	r.logger.WithFields(map[string]any{
+		"count": len(entries),
+	}).Debug("Leaderboard records queued")
+
+	select {
+	case r.wakeCh <- struct{}{}:
 	default:
+	}
+	return nil
+}
+
+type statisticsQueueRow struct {
+	id         int64
+	attempts   int
+	createTime time.Time
+	entry      *StatisticsQueueEntry
+}
+
+// processBatch claims the entries that are due, applies them, and removes, reschedules or dead-letters each one.
+func (r *StatisticsQueue) processBatch(ctx context.Context) (int, error) {
+	// The records are written in the transaction, with the removal of their entries.
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, data, attempts, create_time
+		FROM evr_statistics_queue
+		WHERE next_attempt_time <= now()
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, StatisticsQueueBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim statistics entries: %w", err)
+	}
+
+	batch := make([]*statisticsQueueRow, 0, StatisticsQueueBatchSize)
+	malformed := make(map[int64]error)
+	for rows.Next() {
+		var (
+			row  = &statisticsQueueRow{}
+			data []byte
+		)
+		if err := rows.Scan(&row.id, &data, &row.attempts, &row.createTime); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan statistics entry: %w", err)
+		}
+		row.entry = &StatisticsQueueEntry{}
+		if err := json.Unmarshal(data, row.entry); err != nil {
+			// It will never apply; park it straight away.
+			row.attempts = StatisticsQueueMaxAttempts - 1
+			malformed[row.id] = fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+		batch = append(batch, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read statistics entries: %w", err)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	// Lock the records in a consistent order, so that two nodes can't deadlock; entries for the same record keep their order.
+	slices.SortStableFunc(batch, func(a, b *statisticsQueueRow) int {
+		ka, kb := a.entry.lockKey(), b.entry.lockKey()
+		switch {
+		case ka < kb:
+			return -1
+		case ka > kb:
+			return 1
+		}
+		return 0
+	})
+
+	now := time.Now().UTC()
+	var applied, retried, dead int
+	appliedEntries := make([]*StatisticsQueueEntry, 0, len(batch))
+	written := make([]statisticsQueueWrite, 0, len(batch))
+	for _, row := range batch {
+		err, ok := malformed[row.id]
+		if !ok {
+			// An entry that fails in SQL aborts the transaction; rolling back to the savepoint lets its failure be
+			// recorded, and the rest of the batch be applied.
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT statistics_entry"); err != nil {
+				return 0, fmt.Errorf("failed to create savepoint: %w", err)
+			}
+			var w statisticsQueueWrite
+			if w, err = r.apply(ctx, tx, row.entry); err == nil {
+				written = append(written, w)
+				if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT statistics_entry"); err != nil {
+					return 0, fmt.Errorf("failed to release savepoint: %w", err)
+				}
+			} else if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT statistics_entry"); err != nil {
+				return 0, fmt.Errorf("failed to roll back to savepoint: %w", err)
+			}
+		}
+
+		if err == nil {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM evr_statistics_queue WHERE id = $1", row.id); err != nil {
+				return 0, fmt.Errorf("failed to remove statistics entry: %w", err)
+			}
+			r.nk.MetricsTimerRecord("statistics_queue_lag", nil, now.Sub(row.createTime))
+			appliedEntries = append(appliedEntries, row.entry)
+			applied++
+			continue
+		}
+
+		row.attempts++
+		logger := r.logger.WithFields(map[string]any{
+			"id":             row.id,
+			"leaderboard_id": row.entry.BoardMeta.ID(),
+			"user_id":        row.entry.UserID,
+			"attempts":       row.attempts,
+			"error":          err,
+		})
+
+		if row.attempts >= StatisticsQueueMaxAttempts {
+			if _, err := tx.ExecContext(ctx, `
+				WITH dead AS (DELETE FROM evr_statistics_queue WHERE id = $1 RETURNING id, leaderboard_id, user_id, data, create_time)
+				INSERT INTO evr_statistics_dead_letter (id, leaderboard_id, user_id, data, attempts, last_error, create_time)
+				SELECT id, leaderboard_id, user_id, data, $2, $3, create_time FROM dead
+				ON CONFLICT (id) DO NOTHING`,
+				row.id, row.attempts, err.Error()); err != nil {
+				return 0, fmt.Errorf("failed to dead-letter statistics entry: %w", err)
+			}
+			logger.Error("Leaderboard record write failed too many times; moved to the dead letter table")
+			r.nk.MetricsCounterAdd("statistics_queue_dead_lettered", nil, 1)
+			dead++
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE evr_statistics_queue
+			SET attempts = $2, last_error = $3, next_attempt_time = $4
+			WHERE id = $1`,
+			row.id, row.attempts, err.Error(), now.Add(StatisticsQueueBackoff(row.attempts))); err != nil {
+			return 0, fmt.Errorf("failed to reschedule statistics entry: %w", err)
+		}
+		logger.Warn("Failed to write leaderboard record; will retry")
+		retried++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit statistics entries: %w", err)
+	}
+
+	// The ranks are cached in memory, and only once the records are committed.
+	if _nk, ok := r.nk.(*RuntimeGoNakamaModule); ok {
+		for _, w := range written {
+			_nk.leaderboardRankCache.Insert(w.leaderboard.Id, w.leaderboard.SortOrder, w.score, w.subscore, w.numScore, w.expiryTime, w.ownerID, w.leaderboard.EnableRanks)
+		}
+	}
+
+	r.updateDerivedStatistics(ctx, appliedEntries)
+
+	if retried > 0 || dead > 0 {
 		r.logger.WithFields(map[string]any{
-			"count": len(entries),
-		}).Warn("Leaderboard record write queue full, dropping entry")
-		return fmt.Errorf("queue full")
+			"applied": applied,
+			"retried": retried,
+			"dead":    dead,
+		}).Debug("Processed statistics queue batch")
+	}
+	return len(batch), nil
+}
+
+// updateDerivedStatistics recomputes the derived statistics that read the written records, and queues them.
+func (r *StatisticsQueue) updateDerivedStatistics(ctx context.Context, applied []*StatisticsQueueEntry) {
+	defs := DerivedStatistics()
+	if len(defs) == 0 || len(applied) == 0 {
+		return
+	}
+
+	type player struct {
+		groupID       string
+		mode          evr.Symbol
+		resetSchedule evr.ResetSchedule
+		userID        string
 	}
+	displayNames := make(map[player]string)
+	for _, e := range applied {
+		for _, d := range defs {
+			if d.Applies(e.BoardMeta.Mode, e.BoardMeta.ResetSchedule) && slices.Contains(d.Inputs(), e.BoardMeta.StatName) {
+				displayNames[player{e.BoardMeta.GroupID, e.BoardMeta.Mode, e.BoardMeta.ResetSchedule, e.UserID}] = e.DisplayName
+				break
+			}
+		}
+	}
+
+	entries := make([]*StatisticsQueueEntry, 0, len(displayNames))
+	for p, displayName := range displayNames {
+		derived, err := DerivedStatisticEntries(ctx, r.db, defs, p.groupID, p.mode, p.resetSchedule, p.userID, displayName)
+		if err != nil {
+			r.logger.WithFields(map[string]any{
+				"user_id": p.userID,
+				"mode":    p.mode.String(),
+				"error":   err,
+			}).Warn("Failed to compute derived statistics")
+			continue
+		}
+		entries = append(entries, derived...)
+	}
+	if len(entries) > 0 {
+		// They are applied with the next batch.
+		if err := r.Add(entries); err != nil {
+			r.logger.WithField("error", err).Warn("Failed to queue derived statistics")
+		}
+	}
+}
+
+// statisticsQueueWrite is a record written by the batch, for the rank cache.
+type statisticsQueueWrite struct {
+	leaderboard *Leaderboard
+	ownerID     uuid.UUID
+	score       int64
+	subscore    int64
+	numScore    int32
+	expiryTime  int64
+}
+
+// apply writes the entry's leaderboard record in the transaction, creating the leaderboard if it doesn't exist.
+// The record is locked while its new score is computed, because the leaderboard uses a float64 encoding that is
+// not compatible with simple integer arithmetic, so increments and decrements are a read, decode, add and write.
+func (r *StatisticsQueue) apply(ctx context.Context, tx *sql.Tx, e *StatisticsQueueEntry) (statisticsQueueWrite, error) {
+	w := statisticsQueueWrite{ownerID: uuid.FromStringOrNil(e.UserID)}
+	if w.ownerID.IsNil() {
+		return w, fmt.Errorf("invalid user ID: %q", e.UserID)
+	}
+	_nk, ok := r.nk.(*RuntimeGoNakamaModule)
+	if !ok {
+		return w, errors.New("the statistics queue requires the Go runtime module")
+	}
+
+	boardID := e.BoardMeta.ID()
+	if w.leaderboard = _nk.leaderboardCache.Get(boardID); w.leaderboard == nil {
+		if err := r.nk.LeaderboardCreate(ctx, boardID, true, "desc", string(e.BoardMeta.Operator), ResetScheduleToCron(e.BoardMeta.ResetSchedule), map[string]any{}, true); err != nil {
+			return w, fmt.Errorf("failed to create leaderboard: %w", err)
+		}
+		r.logger.WithFields(map[string]any{
+			"leaderboard_id": boardID,
+		}).Debug("Leaderboard created")
+		if w.leaderboard = _nk.leaderboardCache.Get(boardID); w.leaderboard == nil {
+			return w, fmt.Errorf("leaderboard %s not found after creating it", boardID)
+		}
+	}
+	if w.leaderboard.ResetSchedule != nil {
+		w.expiryTime = w.leaderboard.ResetSchedule.Next(time.Now().UTC()).UTC().Unix()
+	}
+	expiry := time.Unix(w.expiryTime, 0).UTC()
+
+	var metadata any
+	if e.Metadata != nil {
+		data, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return w, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadata = string(data)
+	}
+
+	var current *api.LeaderboardRecord
+	var score, subscore int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT score, subscore FROM leaderboard_record
+		WHERE leaderboard_id = $1 AND owner_id = $2 AND expiry_time = $3
+		FOR UPDATE`, boardID, w.ownerID, expiry).Scan(&score, &subscore)
+	switch {
+	case err == nil:
+		current = &api.LeaderboardRecord{Score: score, Subscore: subscore}
+	case !errors.Is(err, sql.ErrNoRows):
+		return w, fmt.Errorf("failed to read leaderboard record: %w", err)
+	}
+
+	switch e.BoardMeta.Operator {
+	case OperatorIncrement, OperatorDecrement:
+		if w.score, w.subscore, err = StatisticsQueueAccumulate(current, e); err != nil {
+			return w, err
+		}
+	case OperatorSet:
+		w.score, w.subscore = e.Score, e.Subscore
+	default:
+		w.score, w.subscore = e.Score, e.Subscore
+		if current != nil {
+			// Each is kept at its best, as the leaderboard's best operator does.
+			better := func(a, b int64) int64 { return max(a, b) }
+			if w.leaderboard.SortOrder == LeaderboardSortOrderAscending {
+				better = func(a, b int64) int64 { return min(a, b) }
+			}
+			w.score, w.subscore = better(current.Score, e.Score), better(current.Subscore, e.Subscore)
+			if w.score == current.Score && w.subscore == current.Subscore {
+				// Not an improvement; the record is unchanged.
+				return w, tx.QueryRowContext(ctx, `
+					SELECT num_score FROM leaderboard_record
+					WHERE leaderboard_id = $1 AND owner_id = $2 AND expiry_time = $3`, boardID, w.ownerID, expiry).Scan(&w.numScore)
+			}
+		}
+	}
+
+	if current != nil {
+		if err := tx.QueryRowContext(ctx, `
+			UPDATE leaderboard_record
+			SET score = $4, subscore = $5, num_score = num_score + 1, username = $6, metadata = COALESCE($7, metadata), update_time = now()
+			WHERE leaderboard_id = $1 AND owner_id = $2 AND expiry_time = $3
+			RETURNING num_score`,
+			boardID, w.ownerID, expiry, w.score, w.subscore, e.DisplayName, metadata).Scan(&w.numScore); err != nil {
+			return w, fmt.Errorf("failed to write leaderboard record: %w", err)
+		}
+		return w, nil
+	}
+
+	// A record created by another writer since the read can't be locked; the entry is retried.
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO leaderboard_record (leaderboard_id, owner_id, username, score, subscore, metadata, expiry_time)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, '{}'::JSONB), $7)
+		ON CONFLICT (owner_id, leaderboard_id, expiry_time) DO NOTHING
+		RETURNING num_score`,
+		boardID, w.ownerID, e.DisplayName, w.score, w.subscore, metadata, expiry).Scan(&w.numScore); errors.Is(err, sql.ErrNoRows) {
+		return w, errors.New("the leaderboard record was created concurrently")
+	} else if err != nil {
+		return w, fmt.Errorf("failed to write leaderboard record: %w", err)
+	}
+	return w, nil
+}
+
+// StatisticsQueueAccumulate returns the encoded score and subscore of the record after an increment or decrement.
+// The current record may be nil, if the user has none yet.
+func StatisticsQueueAccumulate(current *api.LeaderboardRecord, e *StatisticsQueueEntry) (int64, int64, error) {
+	var (
+		currentVal float64
+		err        error
+	)
+	if current != nil {
+		if currentVal, err = ScoreToFloat64(current.Score, current.Subscore); err != nil {
+			return 0, 0, fmt.Errorf("failed to decode current score: %w", err)
+		}
+	}
+
+	deltaVal, err := ScoreToFloat64(e.Score, e.Subscore)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode delta score: %w", err)
+	}
+
+	newVal := currentVal + deltaVal
+	if e.BoardMeta.Operator == OperatorDecrement {
+		newVal = currentVal - deltaVal
+	}
+
+	score, subscore, err := Float64ToScore(newVal)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to encode new score: %w", err)
+	}
+	return score, subscore, nil
+}
+
+// StatisticsQueueBackoff returns the delay before the next attempt, after the given number of failed attempts.
+func StatisticsQueueBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	d := StatisticsQueueBackoffBase
+	for i := 1; i < attempts && d < StatisticsQueueBackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, StatisticsQueueBackoffMax)
+}
+
+// recordMetrics sets the queue depth, the age of the oldest entry, and the number of dead letters.
+func (r *StatisticsQueue) recordMetrics(ctx context.Context) error {
+	var (
+		depth, dead int64
+		lag         float64
+	)
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT count(*), COALESCE(EXTRACT(EPOCH FROM now() - min(create_time)), 0)
+		FROM evr_statistics_queue`).Scan(&depth, &lag); err != nil {
+		return fmt.Errorf("failed to count statistics entries: %w", err)
+	}
+	if err := r.db.QueryRowContext(ctx, "SELECT count(*) FROM evr_statistics_dead_letter").Scan(&dead); err != nil {
+		return fmt.Errorf("failed to count statistics dead letters: %w", err)
+	}
+	r.nk.MetricsGaugeSet("statistics_queue_depth", nil, float64(depth))
+	r.nk.MetricsGaugeSet("statistics_queue_lag_seconds", nil, lag)
+	r.nk.MetricsGaugeSet("statistics_queue_dead_letters", nil, float64(dead))
+	return nil
+}
+
+// StatisticsDeadLetter is an entry that was parked after failing too many times.
+type StatisticsDeadLetter struct {
+	ID            int64                 `json:"id"`
+	LeaderboardID string                `json:"leaderboard_id"`
+	UserID        string                `json:"user_id"`
+	Entry         *StatisticsQueueEntry `json:"entry"`
+	Attempts      int                   `json:"attempts"`
+	LastError     string                `json:"last_error"`
+	CreateTime    time.Time             `json:"create_time"`
+	DeadTime      time.Time             `json:"dead_time"`
+}
+
+// StatisticsDeadLetterList returns the most recent dead letters, optionally for one leaderboard ID prefix or user.
+func StatisticsDeadLetterList(ctx context.Context, db *sql.DB, leaderboardPrefix, userID string, limit int) ([]*StatisticsDeadLetter, error) {
+	query := `
+		SELECT id, leaderboard_id, user_id, data, attempts, last_error, create_time, dead_time
+		FROM evr_statistics_dead_letter
+		WHERE ($1 = '' OR leaderboard_id LIKE $1 || '%')
+		AND ($2 = '' OR user_id::text = $2)
+		ORDER BY dead_time DESC, id DESC
+		LIMIT $3`
+	rows, err := db.QueryContext(ctx, query, strings.NewReplacer("%", `\%`, "_", `\_`).Replace(leaderboardPrefix), userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statistics dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := make([]*StatisticsDeadLetter, 0)
+	for rows.Next() {
+		var (
+			l    = &StatisticsDeadLetter{}
+			data []byte
+		)
+		if err := rows.Scan(&l.ID, &l.LeaderboardID, &l.UserID, &data, &l.Attempts, &l.LastError, &l.CreateTime, &l.DeadTime); err != nil {
+			return nil, fmt.Errorf("failed to scan statistics dead letter: %w", err)
+		}
+		l.Entry = &StatisticsQueueEntry{}
+		if err := json.Unmarshal(data, l.Entry); err != nil {
+			l.Entry = nil
+		}
+		letters = append(letters, l)
+	}
+	return letters, rows.Err()
+}
+
+// StatisticsDeadLetterRequeue moves dead letters back to the queue, to be applied as new entries.
+func StatisticsDeadLetterRequeue(ctx context.Context, db *sql.DB, ids []int64) (int64, error) {
+	var n int64
+	err := ExecuteInTx(ctx, db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			WITH requeued AS (DELETE FROM evr_statistics_dead_letter WHERE id = ANY($1) RETURNING leaderboard_id, user_id, data, create_time)
+			INSERT INTO evr_statistics_queue (leaderboard_id, user_id, data, create_time)
+			SELECT leaderboard_id, user_id, data, create_time FROM requeued`, ids)
+		if err != nil {
+			return fmt.Errorf("failed to requeue statistics dead letters: %w", err)
+		}
+		n, _ = result.RowsAffected()
+		return nil
+	})
+	return n, err
 }
 
 // prune any expired statistics from the database
 func pruneExpiredLeaderboardRecords(ctx context.Context, db *sql.DB) error {
 	// it must have an expiration date
 	query := `
-	DELETE 
-		FROM 
-			leaderboard_record 
-		WHERE 
+	DELETE
+		FROM
+			leaderboard_record
+		WHERE
 			expiry_time != '1970-01-01 00:00:00+00'
 			AND expiry_time < NOW() - INTERVAL '7 day'
 		`
@@ -1,10 +1,15 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/google/go-cmp/cmp"
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama/v3/server/evr"
 )
 
@@ -111,3 +116,156 @@ func operatorFromStatField(t *testing.T, fieldName string) LeaderboardOperator {
 		return OperatorSet
 	}
 }
+
+func TestStatisticsQueueAccumulate(t *testing.T) {
+	encode := func(f float64) (int64, int64) {
+		t.Helper()
+		score, subscore, err := Float64ToScore(f)
+		if err != nil {
+			t.Fatalf("Float64ToScore(%f) returned an error: %v", f, err)
+		}
+		return score, subscore
+	}
+
+	tests := []struct {
+		name     string
+		current  *float64
+		delta    float64
+		operator LeaderboardOperator
+		expected float64
+	}{
+		{"no record", nil, 2.5, OperatorIncrement, 2.5},
+		{"increment", float64Ptr(10.25), 2.5, OperatorIncrement, 12.75},
+		{"fraction carries", float64Ptr(0.75), 0.5, OperatorIncrement, 1.25},
+		{"decrement", float64Ptr(3.0), 1.5, OperatorDecrement, 1.5},
+		{"decrement below zero", float64Ptr(1.0), 3.0, OperatorDecrement, -2.0},
+		{"increment from negative", float64Ptr(-2.0), 5.0, OperatorIncrement, 3.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current *api.LeaderboardRecord
+			if tt.current != nil {
+				score, subscore := encode(*tt.current)
+				current = &api.LeaderboardRecord{Score: score, Subscore: subscore}
+			}
+			score, subscore := encode(tt.delta)
+			e := newStatQueueEntry("group1", evr.ModeArenaPublic, "Stat", tt.operator, evr.ResetScheduleAllTime, "user1", "User One", score, subscore)
+
+			newScore, newSubscore, err := StatisticsQueueAccumulate(current, e)
+			if err != nil {
+				t.Fatalf("StatisticsQueueAccumulate returned an error: %v", err)
+			}
+			got, err := ScoreToFloat64(newScore, newSubscore)
+			if err != nil {
+				t.Fatalf("ScoreToFloat64 returned an error: %v", err)
+			}
+			if diff := got - tt.expected; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("expected %f, got %f", tt.expected, got)
+			}
+		})
+	}
+
+	if _, _, err := StatisticsQueueAccumulate(&api.LeaderboardRecord{Score: -1}, newStatQueueEntry("group1", evr.ModeArenaPublic, "Stat", OperatorIncrement, evr.ResetScheduleAllTime, "user1", "User One", 1, 0)); err == nil {
+		t.Error("expected an error for an invalid current score")
+	}
+}
+
+func TestStatisticsQueueBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, StatisticsQueueBackoffBase},
+		{2, 2 * StatisticsQueueBackoffBase},
+		{4, 8 * StatisticsQueueBackoffBase},
+		{StatisticsQueueMaxAttempts + 100, StatisticsQueueBackoffMax},
+	}
+	for _, tt := range tests {
+		if got := StatisticsQueueBackoff(tt.attempts); got != tt.expected {
+			t.Errorf("StatisticsQueueBackoff(%d) = %s, expected %s", tt.attempts, got, tt.expected)
+		}
+	}
+}
+
+func TestStatisticsQueueEntry_JSONRoundTrip(t *testing.T) {
+	e := newStatQueueEntry("group1", evr.ModeCombatPublic, "Kills", OperatorIncrement, evr.ResetScheduleWeekly, "user1", "User One", 1000000000000003, 0)
+	e.Metadata = map[string]string{"match_id": "m1"}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("failed to marshal entry: %v", err)
+	}
+	got := &StatisticsQueueEntry{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("failed to unmarshal entry: %v", err)
+	}
+	if diff := cmp.Diff(e, got); diff != "" {
+		t.Errorf("entry changed in storage: %s", diff)
+	}
+	if e.lockKey() != got.lockKey() {
+		t.Error("lock key changed in storage")
+	}
+}
+
+func TestStatisticsQueue_ProcessBatchSQLFailure(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB(t)
+	defer db.Close()
+
+	lbCache := NewLocalLeaderboardCache(ctx, logger, logger, db)
+	nk := &RuntimeGoNakamaModule{
+		logger:           logger,
+		db:               db,
+		metrics:          metrics,
+		leaderboardCache: lbCache,
+	}
+	r := &StatisticsQueue{
+		logger: NewRuntimeGoLogger(logger),
+		db:     db,
+		nk:     nk,
+		wakeCh: make(chan struct{}, 1),
+	}
+
+	userID := uuid.Must(uuid.NewV4())
+	InsertUser(t, db, userID)
+	good := newStatQueueEntry(uuid.Must(uuid.NewV4()).String(), evr.ModeCombatPublic, "Kills", OperatorSet, evr.ResetScheduleAllTime, userID.String(), "User One", 5, 0)
+	if _, _, err := lbCache.Create(ctx, good.BoardMeta.ID(), true, LeaderboardSortOrderDescending, LeaderboardOperatorSet, "", "{}", true); err != nil {
+		t.Fatalf("failed to create leaderboard: %v", err)
+	}
+	// The board is only in the cache, so writing its record fails in SQL.
+	poison := newStatQueueEntry(uuid.Must(uuid.NewV4()).String(), evr.ModeCombatPublic, "Kills", OperatorSet, evr.ResetScheduleAllTime, userID.String(), "User One", 5, 0)
+	lbCache.Insert(poison.BoardMeta.ID(), true, LeaderboardSortOrderDescending, LeaderboardOperatorSet, "", "{}", time.Now().Unix(), true)
+
+	if err := r.Add([]*StatisticsQueueEntry{poison, good}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := r.processBatch(ctx); err != nil {
+		t.Fatalf("processBatch() error = %v", err)
+	}
+
+	var attempts int
+	var lastError string
+	if err := db.QueryRowContext(ctx, "SELECT attempts, last_error FROM evr_statistics_queue WHERE leaderboard_id = $1", poison.BoardMeta.ID()).Scan(&attempts, &lastError); err != nil {
+		t.Fatalf("failed to read the failed entry: %v", err)
+	}
+	if attempts != 1 || lastError == "" {
+		t.Errorf("failed entry attempts = %d, last_error = %q, want 1 and the error", attempts, lastError)
+	}
+
+	var queued, records int
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM evr_statistics_queue WHERE leaderboard_id = $1", good.BoardMeta.ID()).Scan(&queued); err != nil {
+		t.Fatalf("failed to count entries: %v", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM leaderboard_record WHERE leaderboard_id = $1", good.BoardMeta.ID()).Scan(&records); err != nil {
+		t.Fatalf("failed to count records: %v", err)
+	}
+	if queued != 0 || records != 1 {
+		t.Errorf("good entry queued = %d, records = %d, want it applied", queued, records)
+	}
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
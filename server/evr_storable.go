@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -121,3 +122,15 @@ func StorableWrite(ctx context.Context, nk runtime.NakamaModule, userID string,
 	}
 	return nil
 }
+
+// isStorageVersionConflict reports whether a storage write was rejected because the object's version had changed.
+func isStorageVersionConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.Code() == codes.InvalidArgument
+	}
+	return errors.Is(err, runtime.ErrStorageRejectedVersion) || status.Code(err) == codes.InvalidArgument
+}
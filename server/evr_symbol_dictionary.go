@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	SymbolDictionaryStorageCollection   = "SymbolDictionary"
+	SymbolObservationsStorageCollection = "SymbolObservations"
+
+	symbolCandidateMaxLength = 256
+)
+
+// SymbolDictionaryData is a storage object holding symbol tokens. Any number of objects, under any key,
+// may be written to the collection by the system user; resolved candidates are stored under their symbol.
+type SymbolDictionaryData struct {
+	Tokens []string `json:"tokens"`
+}
+
+// SymbolObservationsData is a storage object holding the unknown symbols seen in traffic by one node, under its name.
+type SymbolObservationsData struct {
+	Symbols []evr.ObservedSymbol `json:"symbols"`
+}
+
+// SymbolDictionaryLoader adds the tokens from a dictionary file, and from the storage collection, to the
+// runtime symbol dictionary. The dictionary only grows; removed tokens stay known until restart.
+type SymbolDictionaryLoader struct {
+	sync.Mutex
+	nk   runtime.NakamaModule
+	node string
+	path string
+
+	modTime time.Time
+}
+
+func NewSymbolDictionaryLoader(nk runtime.NakamaModule, node, path string) *SymbolDictionaryLoader {
+	return &SymbolDictionaryLoader{
+		nk:   nk,
+		node: node,
+		path: path,
+	}
+}
+
+// Load reads the file, if it has been modified, and the storage objects. It returns the number of new tokens.
+func (l *SymbolDictionaryLoader) Load(ctx context.Context) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	added := 0
+	if l.path != "" {
+		info, err := os.Stat(l.path)
+		if err != nil {
+			return added, fmt.Errorf("failed to stat symbol dictionary file: %w", err)
+		}
+		if !info.ModTime().Equal(l.modTime) {
+			f, err := os.Open(l.path)
+			if err != nil {
+				return added, fmt.Errorf("failed to open symbol dictionary file: %w", err)
+			}
+			tokens, err := readSymbolDictionary(f)
+			f.Close()
+			if err != nil {
+				return added, fmt.Errorf("failed to read symbol dictionary file: %w", err)
+			}
+			added += evr.AddSymbolTokens(tokens...)
+			l.modTime = info.ModTime()
+		}
+	}
+
+	cursor := ""
+	for {
+		objs, next, err := l.nk.StorageList(ctx, SystemUserID, SystemUserID, SymbolDictionaryStorageCollection, 100, cursor)
+		if err != nil {
+			return added, fmt.Errorf("failed to list symbol dictionaries: %w", err)
+		}
+		for _, obj := range objs {
+			data := SymbolDictionaryData{}
+			if err := json.Unmarshal([]byte(obj.Value), &data); err != nil {
+				return added, fmt.Errorf("failed to unmarshal symbol dictionary %s: %w", obj.Key, err)
+			}
+			added += evr.AddSymbolTokens(data.Tokens...)
+		}
+		if next == "" {
+			return added, nil
+		}
+		cursor = next
+	}
+}
+
+// ShareObservations stores the unknown symbols seen by this node, and loads those seen by the other nodes,
+// so that they are listed, and can be resolved, on any node.
+func (l *SymbolDictionaryLoader) ShareObservations(ctx context.Context) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if local := evr.LocalObservedSymbols(); len(local) > 0 {
+		data, err := json.Marshal(SymbolObservationsData{Symbols: local})
+		if err != nil {
+			return err
+		}
+		if _, err := l.nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+			Collection:      SymbolObservationsStorageCollection,
+			Key:             l.node,
+			UserID:          SystemUserID,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		}}); err != nil {
+			return fmt.Errorf("failed to store symbol observations: %w", err)
+		}
+	}
+
+	peers := make([]evr.ObservedSymbol, 0)
+	cursor := ""
+	for {
+		objs, next, err := l.nk.StorageList(ctx, SystemUserID, SystemUserID, SymbolObservationsStorageCollection, 100, cursor)
+		if err != nil {
+			return fmt.Errorf("failed to list symbol observations: %w", err)
+		}
+		for _, obj := range objs {
+			if obj.Key == l.node {
+				continue
+			}
+			data := SymbolObservationsData{}
+			if err := json.Unmarshal([]byte(obj.Value), &data); err != nil {
+				return fmt.Errorf("failed to unmarshal symbol observations %s: %w", obj.Key, err)
+			}
+			peers = append(peers, data.Symbols...)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	evr.SetPeerObservedSymbols(peers)
+	return nil
+}
+
+// readSymbolDictionary reads one token per line. Blank lines and lines starting with # are skipped.
+func readSymbolDictionary(r io.Reader) ([]string, error) {
+	tokens := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	return tokens, scanner.Err()
+}
+
+// SymbolDictionaryResolve accepts the candidates that resolve an unknown symbol seen in traffic,
+// and stores them so they are loaded by the other nodes, and after a restart.
+func SymbolDictionaryResolve(ctx context.Context, logger *zap.Logger, db *sql.DB, metrics Metrics, storageIndex StorageIndex, candidates []string) ([]evr.SymbolInfo, error) {
+	valid := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if len(c) <= symbolCandidateMaxLength {
+			valid = append(valid, c)
+		}
+	}
+
+	accepted := evr.ResolveSymbolCandidates(valid)
+	if len(accepted) == 0 {
+		return accepted, nil
+	}
+
+	ops := make(StorageOpWrites, 0, len(accepted))
+	for _, info := range accepted {
+		data, err := json.Marshal(SymbolDictionaryData{Tokens: []string{info.Token}})
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, &StorageOpWrite{
+			OwnerID: SystemUserID,
+			Object: &api.WriteStorageObject{
+				Collection:      SymbolDictionaryStorageCollection,
+				Key:             info.Hash,
+				Value:           string(data),
+				PermissionRead:  &wrapperspb.Int32Value{Value: runtime.STORAGE_PERMISSION_NO_READ},
+				PermissionWrite: &wrapperspb.Int32Value{Value: runtime.STORAGE_PERMISSION_NO_WRITE},
+			},
+		})
+	}
+	if _, _, err := StorageWriteObjects(ctx, logger, db, metrics, storageIndex, true, ops); err != nil {
+		return accepted, fmt.Errorf("failed to store resolved symbols: %w", err)
+	}
+	return accepted, nil
+}
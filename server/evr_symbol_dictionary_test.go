@@ -0,0 +1,15 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSymbolDictionary(t *testing.T) {
+	tokens, err := readSymbolDictionary(strings.NewReader("# comment\nSNSLogInRequestv2\n\n  padded token  \n#another\nlast"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SNSLogInRequestv2", "padded token", "last"}, tokens)
+}
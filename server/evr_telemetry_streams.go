@@ -120,15 +120,3 @@ func LeaveTelemetryStreamRPC(ctx context.Context, logger runtime.Logger, db *sql
 	responseBytes, _ := json.Marshal(response)
 	return string(responseBytes), nil
 }
-
-func RegisterTelemetryStreamRPCs(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, initializer runtime.Initializer) error {
-	if err := initializer.RegisterRpc("/telemetry/stream/join", JoinTelemetryStreamRPC); err != nil {
-		return err
-	}
-
-	if err := initializer.RegisterRpc("/telemetry/stream/leave", LeaveTelemetryStreamRPC); err != nil {
-		return err
-	}
-
-	return nil
-}
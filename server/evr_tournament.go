@@ -0,0 +1,379 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+const (
+	TournamentBracketStorageCollection = "TournamentBrackets"
+
+	// The Nakama tournament of a bracket accepts results for this long after it is created.
+	tournamentBracketDuration = 90 * 24 * time.Hour
+
+	tournamentBracketWriteAttempts = 3
+)
+
+var (
+	errBracketStore           = errors.New("failed to store bracket")
+	errBracketMatchSuperseded = errors.New("bracket match was reallocated")
+)
+
+// BracketMatchRef ties an EVR match to the bracket match that it was allocated for.
+type BracketMatchRef struct {
+	BracketID string `json:"bracket_id"`
+	MatchID   string `json:"match_id"`
+}
+
+var _ = Event(&EventBracketMatchEnded{})
+
+// EventBracketMatchEnded is sent by a bracket match when its round is over.
+type EventBracketMatchEnded struct {
+	BracketID      string `json:"bracket_id"`
+	BracketMatchID string `json:"bracket_match_id"`
+	MatchID        string `json:"match_id"`
+	BlueScore      int    `json:"blue_score"`
+	OrangeScore    int    `json:"orange_score"`
+}
+
+// Process stores the result with a read-modify-write, retried against concurrent updates of the bracket. The result is
+// only written to the tournament, and the matches that it made ready allocated, once it is stored.
+func (e *EventBracketMatchEnded) Process(ctx context.Context, logger runtime.Logger, dispatcher *EventDispatcher) error {
+	logger = logger.WithFields(map[string]any{"bracket_id": e.BracketID, "bracket_match_id": e.BracketMatchID, "mid": e.MatchID})
+
+	var m *BracketMatch
+	bracket, err := tournamentBracketUpdate(ctx, dispatcher.nk, e.BracketID, func(b *TournamentBracket) error {
+		if bm, ok := b.Match(e.BracketMatchID); !ok || bm.MatchID != e.MatchID {
+			return errBracketMatchSuperseded
+		}
+		var err error
+		m, err = tournamentBracketApplyResult(b, e.BracketMatchID, e.BlueScore, e.OrangeScore, time.Now().UTC())
+		return err
+	})
+	if errors.Is(err, errBracketMatchSuperseded) {
+		// The match was reallocated, or the result was entered by hand.
+		logger.Warn("Bracket match result is for a different match, ignoring")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	bracket, allocated := tournamentBracketResultStored(ctx, logger, dispatcher.nk, bracket, m)
+
+	if gg, err := GuildGroupLoad(ctx, dispatcher.nk, bracket.GroupID); err != nil {
+		logger.WithField("error", err).Warn("Failed to load guild group")
+	} else if _, err := AuditLogSendGuild(dispatcher.dg, gg, bracketResultMessage(bracket, m, allocated)); err != nil {
+		logger.WithField("error", err).Warn("Failed to send bracket audit message")
+	}
+	return nil
+}
+
+// tournamentBracketUpdate applies the update to the stored bracket with a read-modify-write, retried against concurrent
+// updates of the bracket, and returns the bracket as stored. An error from the update is returned as is.
+func tournamentBracketUpdate(ctx context.Context, nk runtime.NakamaModule, id string, update func(b *TournamentBracket) error) (*TournamentBracket, error) {
+	var err error
+	for range tournamentBracketWriteAttempts {
+		var (
+			bracket *TournamentBracket
+			version string
+		)
+		if bracket, version, err = TournamentBracketRead(ctx, nk, id); err != nil {
+			err = fmt.Errorf("%w: failed to read bracket: %w", errBracketStore, err)
+			continue
+		}
+		if err := update(bracket); err != nil {
+			return nil, err
+		}
+		if err = TournamentBracketWrite(ctx, nk, bracket, version); err == nil {
+			return bracket, nil
+		}
+		err = fmt.Errorf("%w: failed to write bracket: %w", errBracketStore, err)
+	}
+	return nil, err
+}
+
+// tournamentBracketApplyResult records the result in the bracket, and pairs the next Swiss round if it is due.
+func tournamentBracketApplyResult(bracket *TournamentBracket, id string, blueScore, orangeScore int, now time.Time) (*BracketMatch, error) {
+	m, err := bracket.ReportResult(id, blueScore, orangeScore, now)
+	if err != nil {
+		return nil, err
+	}
+	if bracket.Format == BracketSwiss && bracket.Status != BracketStatusComplete {
+		if _, err := bracket.NextSwissRound(now); err != nil && !errors.Is(err, ErrBracketRoundNotOver) {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// tournamentBracketResultStored writes the standings of a stored result's teams to the tournament, and allocates the
+// matches that it made ready. It returns the bracket as last stored.
+func tournamentBracketResultStored(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, bracket *TournamentBracket, m *BracketMatch) (*TournamentBracket, []*BracketMatch) {
+	if err := tournamentBracketRecordResult(ctx, nk, bracket, m); err != nil {
+		logger.WithField("error", err).Warn("Failed to write bracket result to the tournament")
+	}
+	stored, allocated, err := tournamentBracketAllocateStored(ctx, logger, nk, bracket.ID)
+	if err != nil {
+		// The result stands; the organizer can allocate the next matches once there are servers.
+		logger.WithField("error", err).Warn("Failed to allocate bracket matches")
+	}
+	if stored != nil {
+		bracket = stored
+	}
+	return bracket, allocated
+}
+
+// TournamentBracketReport stores the result, then writes it to the tournament leaderboard and allocates the matches that
+// it made ready. It returns the bracket as last stored.
+func TournamentBracketReport(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, bracketID, id string, blueScore, orangeScore int) (*TournamentBracket, *BracketMatch, []*BracketMatch, error) {
+	var m *BracketMatch
+	bracket, err := tournamentBracketUpdate(ctx, nk, bracketID, func(b *TournamentBracket) error {
+		var err error
+		m, err = tournamentBracketApplyResult(b, id, blueScore, orangeScore, time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bracket, allocated := tournamentBracketResultStored(ctx, logger, nk, bracket, m)
+	return bracket, m, allocated, nil
+}
+
+// TournamentBracketAdvance stores the next Swiss round, if the current one is over, then allocates the bracket's ready
+// matches. It returns the bracket as last stored.
+func TournamentBracketAdvance(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, bracketID string) (*TournamentBracket, []*BracketMatch, error) {
+	bracket, err := tournamentBracketUpdate(ctx, nk, bracketID, func(b *TournamentBracket) error {
+		if b.Status == BracketStatusComplete {
+			return ErrBracketComplete
+		}
+		if b.Format == BracketSwiss && len(b.Ready()) == 0 {
+			if _, err := b.NextSwissRound(time.Now().UTC()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	stored, allocated, err := tournamentBracketAllocateStored(ctx, logger, nk, bracket.ID)
+	if stored != nil {
+		bracket = stored
+	}
+	return bracket, allocated, err
+}
+
+// MatchSettings returns the settings for a private match between the two teams, with the players assigned to their sides.
+func (b *TournamentBracket) MatchSettings(m *BracketMatch) *MatchSettings {
+	alignments := make(map[string]int)
+	for _, side := range []struct {
+		teamID string
+		role   int
+	}{{m.Blue, evr.TeamBlue}, {m.Orange, evr.TeamOrange}} {
+		if t, ok := b.Team(side.teamID); ok {
+			for _, userID := range t.PlayerIDs {
+				alignments[userID] = side.role
+			}
+		}
+	}
+	return &MatchSettings{
+		Mode:           b.Mode,
+		Level:          b.Level,
+		TeamSize:       b.TeamSize,
+		StartTime:      time.Now().UTC(),
+		SpawnedBy:      b.CreatedBy,
+		GroupID:        uuid.FromStringOrNil(b.GroupID),
+		TeamAlignments: alignments,
+		Bracket:        &BracketMatchRef{BracketID: b.ID, MatchID: m.ID},
+	}
+}
+
+// tournamentBracketAllocateStored allocates the ready matches of the stored bracket, and stores each allocation with a
+// read-modify-write. A match that was allocated elsewhere in the meantime keeps that allocation, and the game server
+// allocated here is shut down. It returns the bracket as last stored.
+func tournamentBracketAllocateStored(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, id string) (*TournamentBracket, []*BracketMatch, error) {
+	bracket, _, err := TournamentBracketRead(ctx, nk, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	allocated := make([]*BracketMatch, 0)
+	for _, ready := range bracket.Ready() {
+		if m, ok := bracket.Match(ready.ID); !ok || m.Status != BracketMatchReady {
+			// Allocated elsewhere since it was read.
+			continue
+		}
+		label, err := tournamentBracketAllocateMatch(ctx, logger, nk, bracket, ready)
+		if err != nil {
+			return bracket, allocated, err
+		}
+		logger := logger.WithFields(map[string]any{"bracket_id": id, "bracket_match_id": ready.ID, "mid": label.ID.String()})
+		stored, err := tournamentBracketUpdate(ctx, nk, id, func(b *TournamentBracket) error {
+			return b.Allocated(ready.ID, label.ID.String(), time.Now().UTC())
+		})
+		if err != nil {
+			logger.WithField("error", err).Warn("Failed to store bracket match allocation")
+			tournamentBracketReleaseMatch(ctx, logger, nk, label)
+			continue
+		}
+		bracket = stored
+		m, _ := bracket.Match(ready.ID)
+		allocated = append(allocated, m)
+		logger.Info("Allocated bracket match")
+	}
+	return bracket, allocated, nil
+}
+
+// tournamentBracketReleaseMatch shuts down a match whose allocation could not be stored, returning its game server.
+func tournamentBracketReleaseMatch(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, label *MatchLabel) {
+	env := NewSignalEnvelope(SystemUserID, SignalShutdown, SignalShutdownPayload{})
+	if _, err := nk.MatchSignal(ctx, label.ID.String(), env.String()); err != nil {
+		logger.WithField("error", err).Warn("Failed to shut down unused bracket match")
+	}
+}
+
+// tournamentBracketAllocateMatch allocates a game server, in the bracket's region, for the match.
+func tournamentBracketAllocateMatch(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, b *TournamentBracket, m *BracketMatch) (*MatchLabel, error) {
+	region := b.Region
+	if region == "" {
+		region = "default"
+	}
+	queryAddon := ""
+	if s := ServiceSettings(); s != nil {
+		queryAddon = s.Matchmaking.QueryAddons.Allocate
+	}
+	label, err := LobbyGameServerAllocate(ctx, logger, nk, []string{b.GroupID}, nil, b.MatchSettings(m), []string{region}, false, true, queryAddon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate %s: %w", m.ID, err)
+	}
+	return label, nil
+}
+
+// TournamentBracketCreateLeaderboard creates the Nakama tournament that the bracket's results are written to. Each
+// player's score is their team's wins, and the subscore the points that it scored.
+func TournamentBracketCreateLeaderboard(ctx context.Context, nk runtime.NakamaModule, b *TournamentBracket) error {
+	metadata := map[string]any{
+		"group_id": b.GroupID,
+		"format":   string(b.Format),
+	}
+	return nk.TournamentCreate(ctx, b.ID, true, "desc", "set", "", metadata, b.Name, fmt.Sprintf("%s bracket", strings.ReplaceAll(string(b.Format), "_", " ")), 0, int(b.CreatedAt.Unix()), 0, int(tournamentBracketDuration.Seconds()), 0, 0, false, true)
+}
+
+// tournamentBracketRecordResult writes the standings of the match's teams to the tournament. The records are set from
+// the bracket, rather than incremented, so that a corrected result replaces the one that it supersedes.
+func tournamentBracketRecordResult(ctx context.Context, nk runtime.NakamaModule, b *TournamentBracket, m *BracketMatch) error {
+	if m.Bye {
+		return nil
+	}
+	standings := make(map[string]*BracketStanding, len(b.Teams))
+	for _, s := range b.Standings() {
+		standings[s.TeamID] = s
+	}
+	op := 2 // SET, for tournaments created to increment.
+	for _, teamID := range []string{m.Blue, m.Orange} {
+		t, ok := b.Team(teamID)
+		if !ok {
+			continue
+		}
+		s, ok := standings[teamID]
+		if !ok {
+			continue
+		}
+		users, err := nk.UsersGetId(ctx, t.PlayerIDs, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get users: %w", err)
+		}
+		for _, u := range users {
+			metadata := map[string]any{"team": t.Name}
+			if _, err := nk.TournamentRecordWrite(ctx, b.ID, u.Id, u.Username, int64(s.Wins), int64(s.PointsFor), metadata, &op); err != nil {
+				return fmt.Errorf("failed to write tournament record: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func bracketResultMessage(b *TournamentBracket, m *BracketMatch, allocated []*BracketMatch) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Bracket `%s` %s: **%s** %d - %d **%s**", b.Name, m.ID, b.TeamName(m.Blue), m.BlueScore, m.OrangeScore, b.TeamName(m.Orange))
+	for _, a := range allocated {
+		fmt.Fprintf(&sb, "\nAllocated %s: %s vs %s (`%s`)", a.ID, b.TeamName(a.Blue), b.TeamName(a.Orange), a.MatchID)
+	}
+	if b.Status == BracketStatusComplete && b.Champion != "" {
+		fmt.Fprintf(&sb, "\n**%s** won the bracket.", b.TeamName(b.Champion))
+	}
+	return sb.String()
+}
+
+// TournamentBracketRead returns the bracket, and its storage version.
+func TournamentBracketRead(ctx context.Context, nk runtime.NakamaModule, id string) (*TournamentBracket, string, error) {
+	objs, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: TournamentBracketStorageCollection,
+		Key:        id,
+		UserID:     SystemUserID,
+	}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objs) == 0 {
+		return nil, "", fmt.Errorf("bracket %s not found", id)
+	}
+	b := &TournamentBracket{}
+	if err := json.Unmarshal([]byte(objs[0].Value), b); err != nil {
+		return nil, "", err
+	}
+	return b, objs[0].Version, nil
+}
+
+// TournamentBracketWrite stores the bracket, if it has not changed since it was read ("*" to create it).
+func TournamentBracketWrite(ctx context.Context, nk runtime.NakamaModule, b *TournamentBracket, version string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      TournamentBracketStorageCollection,
+		Key:             b.ID,
+		UserID:          SystemUserID,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	return err
+}
+
+// TournamentBracketList returns the guild's brackets, most recent first.
+func TournamentBracketList(ctx context.Context, nk runtime.NakamaModule, groupID string) ([]*TournamentBracket, error) {
+	brackets := make([]*TournamentBracket, 0)
+	cursor := ""
+	for {
+		objs, next, err := nk.StorageList(ctx, SystemUserID, SystemUserID, TournamentBracketStorageCollection, 100, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			b := &TournamentBracket{}
+			if err := json.Unmarshal([]byte(obj.Value), b); err != nil {
+				return nil, err
+			}
+			if b.GroupID == groupID {
+				brackets = append(brackets, b)
+			}
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	slices.SortFunc(brackets, func(a, b *TournamentBracket) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return brackets, nil
+}
@@ -0,0 +1,634 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama/v3/server/evr"
+)
+
+type BracketFormat string
+
+const (
+	BracketSingleElimination BracketFormat = "single_elimination"
+	BracketDoubleElimination BracketFormat = "double_elimination"
+	BracketSwiss             BracketFormat = "swiss"
+
+	BracketWinners    = "winners"
+	BracketLosers     = "losers"
+	BracketGrandFinal = "grand_final"
+	BracketSwissRound = "swiss"
+
+	BracketMatchPending   = "pending"   // Waiting for the results of earlier matches.
+	BracketMatchReady     = "ready"     // Both teams are known; it can be allocated.
+	BracketMatchAllocated = "allocated" // A game server has been allocated for it.
+	BracketMatchComplete  = "complete"
+
+	BracketStatusRunning  = "running"
+	BracketStatusComplete = "complete"
+
+	bracketMaxTeams = 64
+)
+
+var (
+	ErrBracketMatchNotFound = errors.New("bracket match not found")
+	ErrBracketMatchNotReady = errors.New("bracket match is not ready")
+	ErrBracketRoundNotOver  = errors.New("the current round is not over")
+	ErrBracketComplete      = errors.New("the bracket is complete")
+)
+
+// BracketTeam is a team entered in a bracket. The players are assigned to the team's side in its matches.
+type BracketTeam struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Seed      int      `json:"seed"` // 1 is the top seed.
+	PlayerIDs []string `json:"player_ids"`
+}
+
+// BracketSource is where a side of a match comes from: the winner, or the loser, of an earlier match.
+type BracketSource struct {
+	MatchID string `json:"match_id"`
+	Loser   bool   `json:"loser,omitempty"`
+}
+
+// BracketMatch is one pairing of the bracket.
+type BracketMatch struct {
+	ID          string         `json:"id"` // e.g. W1-1, L2-1, GF-1, S1-1
+	Bracket     string         `json:"bracket"`
+	Round       int            `json:"round"`
+	Blue        string         `json:"blue,omitempty"`   // The team ID.
+	Orange      string         `json:"orange,omitempty"` // The team ID.
+	BlueFrom    *BracketSource `json:"blue_from,omitempty"`
+	OrangeFrom  *BracketSource `json:"orange_from,omitempty"`
+	Status      string         `json:"status"`
+	MatchID     string         `json:"match_id,omitempty"` // The allocated EVR match.
+	BlueScore   int            `json:"blue_score"`
+	OrangeScore int            `json:"orange_score"`
+	Winner      string         `json:"winner,omitempty"`
+	Loser       string         `json:"loser,omitempty"`
+	Bye         bool           `json:"bye,omitempty"` // Decided without being played.
+	CompletedAt time.Time      `json:"completed_at,omitempty"`
+}
+
+// TournamentBracket is a guild-run tournament. Its results are also written to the Nakama tournament of the same ID.
+type TournamentBracket struct {
+	ID          string          `json:"id"`
+	GroupID     string          `json:"group_id"`
+	Name        string          `json:"name"`
+	Format      BracketFormat   `json:"format"`
+	Mode        evr.Symbol      `json:"mode"`
+	Level       evr.Symbol      `json:"level"`
+	TeamSize    int             `json:"team_size"`
+	Region      string          `json:"region,omitempty"`
+	SwissRounds int             `json:"swiss_rounds,omitempty"`
+	Teams       []*BracketTeam  `json:"teams"`
+	Matches     []*BracketMatch `json:"matches"`
+	Status      string          `json:"status"`
+	Champion    string          `json:"champion,omitempty"` // The team ID of the winner of an elimination bracket.
+	CreatedBy   string          `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// BracketStanding is a team's record in the bracket.
+type BracketStanding struct {
+	TeamID        string `json:"team_id"`
+	Name          string `json:"name"`
+	Wins          int    `json:"wins"`
+	Losses        int    `json:"losses"`
+	PointsFor     int    `json:"points_for"`
+	PointsAgainst int    `json:"points_against"`
+	Eliminated    bool   `json:"eliminated,omitempty"`
+}
+
+// NewTournamentBracket validates the teams, and creates the bracket with its first round of matches.
+func NewTournamentBracket(id, groupID, name string, format BracketFormat, teams []*BracketTeam, createdBy string, now time.Time) (*TournamentBracket, error) {
+	if len(teams) < 2 {
+		return nil, errors.New("at least two teams are required")
+	}
+	if len(teams) > bracketMaxTeams {
+		return nil, fmt.Errorf("at most %d teams are allowed", bracketMaxTeams)
+	}
+
+	seen := make(map[string]string)
+	for i, t := range teams {
+		if t.Name == "" {
+			return nil, fmt.Errorf("team %d has no name", i+1)
+		}
+		if len(t.PlayerIDs) == 0 {
+			return nil, fmt.Errorf("team %s has no players", t.Name)
+		}
+		for _, p := range t.PlayerIDs {
+			if other, ok := seen[p]; ok {
+				return nil, fmt.Errorf("player %s is on both %s and %s", p, other, t.Name)
+			}
+			seen[p] = t.Name
+		}
+	}
+
+	// Teams without a seed are seeded after the seeded teams, in the order given.
+	teams = slices.Clone(teams)
+	sort.SliceStable(teams, func(i, j int) bool {
+		a, b := teams[i].Seed, teams[j].Seed
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+	for i, t := range teams {
+		t.Seed = i + 1
+		t.ID = fmt.Sprintf("t%d", i+1)
+	}
+
+	b := &TournamentBracket{
+		ID:        id,
+		GroupID:   groupID,
+		Name:      name,
+		Format:    format,
+		Mode:      evr.ModeArenaPrivate,
+		Level:     evr.LevelArena,
+		TeamSize:  4,
+		Teams:     teams,
+		Status:    BracketStatusRunning,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	switch format {
+	case BracketSingleElimination:
+		b.Matches = b.eliminationMatches(false)
+	case BracketDoubleElimination:
+		b.Matches = b.eliminationMatches(true)
+	case BracketSwiss:
+		// Enough rounds for one team to be the only one without a loss.
+		b.SwissRounds = bits.Len(uint(len(teams) - 1))
+		b.Matches = b.swissRound(1)
+	default:
+		return nil, fmt.Errorf("unknown bracket format: %s", format)
+	}
+	b.resolve(now)
+	return b, nil
+}
+
+// bracketSeedOrder returns the seeds in the order of the first round, so that the top seeds meet last (e.g. 1, 8, 4, 5, 2, 7, 3, 6).
+func bracketSeedOrder(size int) []int {
+	order := []int{1}
+	for n := 2; n <= size; n *= 2 {
+		next := make([]int, 0, n)
+		for _, s := range order {
+			next = append(next, s, n+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+func winnerOf(id string) *BracketSource { return &BracketSource{MatchID: id} }
+func loserOf(id string) *BracketSource  { return &BracketSource{MatchID: id, Loser: true} }
+
+// eliminationMatches creates the winners bracket, and for double elimination the losers bracket and the grand final.
+// Missing seeds are byes. The grand final is a single match; there is no bracket reset.
+func (b *TournamentBracket) eliminationMatches(double bool) []*BracketMatch {
+	rounds := bits.Len(uint(len(b.Teams) - 1))
+	size := 1 << rounds
+	matches := make([]*BracketMatch, 0, 2*size)
+
+	teamBySeed := func(seed int) string {
+		if seed > len(b.Teams) {
+			return ""
+		}
+		return b.Teams[seed-1].ID
+	}
+
+	order := bracketSeedOrder(size)
+	for i := 0; i < size/2; i++ {
+		matches = append(matches, &BracketMatch{
+			ID:      fmt.Sprintf("W1-%d", i+1),
+			Bracket: BracketWinners,
+			Round:   1,
+			Blue:    teamBySeed(order[2*i]),
+			Orange:  teamBySeed(order[2*i+1]),
+		})
+	}
+	for r := 2; r <= rounds; r++ {
+		for i := 0; i < size>>r; i++ {
+			matches = append(matches, &BracketMatch{
+				ID:         fmt.Sprintf("W%d-%d", r, i+1),
+				Bracket:    BracketWinners,
+				Round:      r,
+				BlueFrom:   winnerOf(fmt.Sprintf("W%d-%d", r-1, 2*i+1)),
+				OrangeFrom: winnerOf(fmt.Sprintf("W%d-%d", r-1, 2*i+2)),
+			})
+		}
+	}
+	if !double {
+		return matches
+	}
+
+	final := &BracketMatch{
+		ID:       "GF-1",
+		Bracket:  BracketGrandFinal,
+		Round:    1,
+		BlueFrom: winnerOf(fmt.Sprintf("W%d-1", rounds)),
+	}
+	if rounds == 1 {
+		// With two teams, the loser of the only match gets a second chance in the final.
+		final.OrangeFrom = loserOf("W1-1")
+		return append(matches, final)
+	}
+
+	// Losers round 1 pairs the losers of winners round 1. Each even round adds the losers of the next winners
+	// round (in reverse, to put off rematches), and each odd round halves the field.
+	for i := 0; i < size/4; i++ {
+		matches = append(matches, &BracketMatch{
+			ID:         fmt.Sprintf("L1-%d", i+1),
+			Bracket:    BracketLosers,
+			Round:      1,
+			BlueFrom:   loserOf(fmt.Sprintf("W1-%d", 2*i+1)),
+			OrangeFrom: loserOf(fmt.Sprintf("W1-%d", 2*i+2)),
+		})
+	}
+	for j := 1; j < rounds; j++ {
+		n := size >> (j + 1)
+		for i := 0; i < n; i++ {
+			w := i + 1
+			if j%2 == 1 {
+				w = n - i
+			}
+			matches = append(matches, &BracketMatch{
+				ID:         fmt.Sprintf("L%d-%d", 2*j, i+1),
+				Bracket:    BracketLosers,
+				Round:      2 * j,
+				BlueFrom:   winnerOf(fmt.Sprintf("L%d-%d", 2*j-1, i+1)),
+				OrangeFrom: loserOf(fmt.Sprintf("W%d-%d", j+1, w)),
+			})
+		}
+		if j == rounds-1 {
+			break
+		}
+		for i := 0; i < n/2; i++ {
+			matches = append(matches, &BracketMatch{
+				ID:         fmt.Sprintf("L%d-%d", 2*j+1, i+1),
+				Bracket:    BracketLosers,
+				Round:      2*j + 1,
+				BlueFrom:   winnerOf(fmt.Sprintf("L%d-%d", 2*j, 2*i+1)),
+				OrangeFrom: winnerOf(fmt.Sprintf("L%d-%d", 2*j, 2*i+2)),
+			})
+		}
+	}
+	final.OrangeFrom = winnerOf(fmt.Sprintf("L%d-1", 2*(rounds-1)))
+	return append(matches, final)
+}
+
+// Match returns the bracket match.
+func (b *TournamentBracket) Match(id string) (*BracketMatch, bool) {
+	for _, m := range b.Matches {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// MatchByMatchID returns the bracket match that was allocated the EVR match.
+func (b *TournamentBracket) MatchByMatchID(matchID string) (*BracketMatch, bool) {
+	for _, m := range b.Matches {
+		if m.MatchID == matchID {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// Team returns the team.
+func (b *TournamentBracket) Team(id string) (*BracketTeam, bool) {
+	for _, t := range b.Teams {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// TeamName returns the team's name, or "BYE" for an empty side.
+func (b *TournamentBracket) TeamName(id string) string {
+	if t, ok := b.Team(id); ok {
+		return t.Name
+	}
+	return "BYE"
+}
+
+func (m *BracketMatch) complete(winner, loser string, bye bool, now time.Time) {
+	m.Winner, m.Loser, m.Bye = winner, loser, bye
+	m.Status = BracketMatchComplete
+	m.CompletedAt = now
+}
+
+// resolve fills the sides of the matches from the completed matches, advances byes, and sets the match and bracket statuses.
+func (b *TournamentBracket) resolve(now time.Time) {
+	byID := make(map[string]*BracketMatch, len(b.Matches))
+	for _, m := range b.Matches {
+		byID[m.ID] = m
+	}
+	side := func(src *BracketSource, team string) (string, bool) {
+		if src == nil {
+			return team, true
+		}
+		from, ok := byID[src.MatchID]
+		if !ok || from.Status != BracketMatchComplete {
+			return "", false
+		}
+		if src.Loser {
+			return from.Loser, true
+		}
+		return from.Winner, true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, m := range b.Matches {
+			if m.Status == BracketMatchComplete || m.Status == BracketMatchAllocated {
+				continue
+			}
+			blue, blueOK := side(m.BlueFrom, m.Blue)
+			orange, orangeOK := side(m.OrangeFrom, m.Orange)
+			m.Blue, m.Orange = blue, orange
+			switch {
+			case !blueOK || !orangeOK:
+				m.Status = BracketMatchPending
+				continue
+			case blue == "" || orange == "":
+				// A bye: the team that is there (if any) advances.
+				m.complete(blue+orange, "", true, now)
+				changed = true
+			default:
+				m.Status = BracketMatchReady
+			}
+		}
+	}
+
+	b.UpdatedAt = now
+	if b.Format == BracketSwiss {
+		if len(b.Matches) > 0 && b.Matches[len(b.Matches)-1].Round >= b.SwissRounds && b.roundOver() {
+			b.Status = BracketStatusComplete
+		}
+		return
+	}
+	last := b.Matches[len(b.Matches)-1]
+	if last.Status == BracketMatchComplete {
+		b.Status = BracketStatusComplete
+		b.Champion = last.Winner
+	}
+}
+
+// Ready returns the matches that can be allocated.
+func (b *TournamentBracket) Ready() []*BracketMatch {
+	ready := make([]*BracketMatch, 0)
+	for _, m := range b.Matches {
+		if m.Status == BracketMatchReady {
+			ready = append(ready, m)
+		}
+	}
+	return ready
+}
+
+// Allocated records the EVR match that was allocated for the bracket match.
+func (b *TournamentBracket) Allocated(id, matchID string, now time.Time) error {
+	m, ok := b.Match(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBracketMatchNotFound, id)
+	}
+	if m.Status != BracketMatchReady {
+		return fmt.Errorf("%w: %s is %s", ErrBracketMatchNotReady, id, m.Status)
+	}
+	m.MatchID = matchID
+	m.Status = BracketMatchAllocated
+	b.UpdatedAt = now
+	return nil
+}
+
+// ReportResult records the final score of a match, and advances the teams. A result may be corrected until
+// a match that depends on it has been allocated.
+func (b *TournamentBracket) ReportResult(id string, blueScore, orangeScore int, now time.Time) (*BracketMatch, error) {
+	m, ok := b.Match(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBracketMatchNotFound, id)
+	}
+	switch m.Status {
+	case BracketMatchReady, BracketMatchAllocated:
+	case BracketMatchComplete:
+		if m.Bye {
+			return nil, fmt.Errorf("%w: %s is a bye", ErrBracketMatchNotReady, id)
+		}
+		for _, next := range b.Matches {
+			if (next.BlueFrom != nil && next.BlueFrom.MatchID == id || next.OrangeFrom != nil && next.OrangeFrom.MatchID == id) &&
+				(next.Status == BracketMatchAllocated || next.Status == BracketMatchComplete && !next.Bye) {
+				return nil, fmt.Errorf("%w: %s has been played", ErrBracketMatchNotReady, next.ID)
+			}
+		}
+		b.reopen(id)
+	default:
+		return nil, fmt.Errorf("%w: %s is %s", ErrBracketMatchNotReady, id, m.Status)
+	}
+	if blueScore == orangeScore {
+		return nil, errors.New("a bracket match needs a winner")
+	}
+
+	m.BlueScore, m.OrangeScore = blueScore, orangeScore
+	if blueScore > orangeScore {
+		m.complete(m.Blue, m.Orange, false, now)
+	} else {
+		m.complete(m.Orange, m.Blue, false, now)
+	}
+	b.Status = BracketStatusRunning
+	b.Champion = ""
+	b.resolve(now)
+	return m, nil
+}
+
+// reopen returns the matches that depend on the match to pending, so that a corrected result is advanced.
+func (b *TournamentBracket) reopen(id string) {
+	for _, next := range b.Matches {
+		if next.BlueFrom != nil && next.BlueFrom.MatchID == id || next.OrangeFrom != nil && next.OrangeFrom.MatchID == id {
+			if next.Status == BracketMatchComplete {
+				next.Winner, next.Loser, next.Bye = "", "", false
+				b.reopen(next.ID)
+			}
+			next.Status = BracketMatchPending
+		}
+	}
+}
+
+func (b *TournamentBracket) roundOver() bool {
+	return !slices.ContainsFunc(b.Matches, func(m *BracketMatch) bool { return m.Status != BracketMatchComplete })
+}
+
+// Standings returns the teams' records, best first.
+func (b *TournamentBracket) Standings() []*BracketStanding {
+	byTeam := make(map[string]*BracketStanding, len(b.Teams))
+	standings := make([]*BracketStanding, 0, len(b.Teams))
+	for _, t := range b.Teams {
+		s := &BracketStanding{TeamID: t.ID, Name: t.Name}
+		byTeam[t.ID] = s
+		standings = append(standings, s)
+	}
+	for _, m := range b.Matches {
+		if m.Status != BracketMatchComplete {
+			continue
+		}
+		if w, ok := byTeam[m.Winner]; ok {
+			w.Wins++
+		}
+		if l, ok := byTeam[m.Loser]; ok {
+			l.Losses++
+		}
+		if m.Bye {
+			continue
+		}
+		if s, ok := byTeam[m.Blue]; ok {
+			s.PointsFor += m.BlueScore
+			s.PointsAgainst += m.OrangeScore
+		}
+		if s, ok := byTeam[m.Orange]; ok {
+			s.PointsFor += m.OrangeScore
+			s.PointsAgainst += m.BlueScore
+		}
+	}
+	for _, s := range standings {
+		switch b.Format {
+		case BracketSingleElimination:
+			s.Eliminated = s.Losses > 0
+		case BracketDoubleElimination:
+			s.Eliminated = s.Losses > 1 || s.Losses > 0 && b.Status == BracketStatusComplete && s.TeamID != b.Champion
+		}
+	}
+	seeds := make(map[string]int, len(b.Teams))
+	for _, t := range b.Teams {
+		seeds[t.ID] = t.Seed
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, c := standings[i], standings[j]
+		if b.Champion != "" && (a.TeamID == b.Champion) != (c.TeamID == b.Champion) {
+			return a.TeamID == b.Champion
+		}
+		if a.Eliminated != c.Eliminated {
+			return !a.Eliminated
+		}
+		if a.Wins != c.Wins {
+			return a.Wins > c.Wins
+		}
+		if da, dc := a.PointsFor-a.PointsAgainst, c.PointsFor-c.PointsAgainst; da != dc {
+			return da > dc
+		}
+		return seeds[a.TeamID] < seeds[c.TeamID]
+	})
+	return standings
+}
+
+// NextSwissRound pairs the next round of a Swiss bracket, once the current round is over.
+func (b *TournamentBracket) NextSwissRound(now time.Time) ([]*BracketMatch, error) {
+	if b.Format != BracketSwiss {
+		return nil, fmt.Errorf("the bracket is %s", b.Format)
+	}
+	if b.Status == BracketStatusComplete {
+		return nil, ErrBracketComplete
+	}
+	if !b.roundOver() {
+		return nil, ErrBracketRoundNotOver
+	}
+	round := 1
+	if len(b.Matches) > 0 {
+		round = b.Matches[len(b.Matches)-1].Round + 1
+	}
+	matches := b.swissRound(round)
+	b.Matches = append(b.Matches, matches...)
+	b.resolve(now)
+	return matches, nil
+}
+
+// swissRound pairs the teams by their standings, avoiding rematches where possible. With an odd number of teams,
+// the lowest ranked team that has not had a bye gets one.
+func (b *TournamentBracket) swissRound(round int) []*BracketMatch {
+	order := make([]string, 0, len(b.Teams))
+	if round == 1 {
+		// The top half plays the bottom half.
+		half := (len(b.Teams) + 1) / 2
+		for i := 0; i < half; i++ {
+			order = append(order, b.Teams[i].ID)
+			if i+half < len(b.Teams) {
+				order = append(order, b.Teams[i+half].ID)
+			}
+		}
+	} else {
+		for _, s := range b.Standings() {
+			order = append(order, s.TeamID)
+		}
+	}
+
+	played := make(map[[2]string]bool)
+	hadBye := make(map[string]bool)
+	for _, m := range b.Matches {
+		if m.Bye {
+			hadBye[m.Winner] = true
+			continue
+		}
+		played[[2]string{m.Blue, m.Orange}] = true
+		played[[2]string{m.Orange, m.Blue}] = true
+	}
+
+	matches := make([]*BracketMatch, 0, len(order)/2+1)
+	if len(order)%2 == 1 {
+		i := len(order) - 1
+		if round > 1 {
+			for i > 0 && hadBye[order[i]] {
+				i--
+			}
+		}
+		matches = append(matches, &BracketMatch{Bracket: BracketSwissRound, Round: round, Blue: order[i]})
+		order = slices.Delete(order, i, i+1)
+	}
+
+	pairs, ok := swissPairs(order, played)
+	if !ok {
+		// Every pairing has a rematch; pair by rank.
+		pairs = make([][2]string, 0, len(order)/2)
+		for i := 0; i+1 < len(order); i += 2 {
+			pairs = append(pairs, [2]string{order[i], order[i+1]})
+		}
+	}
+	paired := make([]*BracketMatch, 0, len(pairs))
+	for _, p := range pairs {
+		paired = append(paired, &BracketMatch{Bracket: BracketSwissRound, Round: round, Blue: p[0], Orange: p[1]})
+	}
+	matches = append(paired, matches...)
+	for i, m := range matches {
+		m.ID = fmt.Sprintf("S%d-%d", round, i+1)
+	}
+	return matches
+}
+
+// swissPairs pairs each team, by rank, with the closest ranked team that it has not played, backtracking
+// when that leaves teams that can only be paired as rematches.
+func swissPairs(order []string, played map[[2]string]bool) ([][2]string, bool) {
+	if len(order) == 0 {
+		return [][2]string{}, true
+	}
+	a := order[0]
+	for k := 1; k < len(order); k++ {
+		if played[[2]string{a, order[k]}] {
+			continue
+		}
+		rest := make([]string, 0, len(order)-2)
+		rest = append(rest, order[1:k]...)
+		rest = append(rest, order[k+1:]...)
+		if pairs, ok := swissPairs(rest, played); ok {
+			return append([][2]string{{a, order[k]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/heroiclabs/nakama/v3/server/evr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBracketTeams(n int) []*BracketTeam {
+	teams := make([]*BracketTeam, 0, n)
+	for i := 0; i < n; i++ {
+		teams = append(teams, &BracketTeam{
+			Name:      fmt.Sprintf("Team %d", i+1),
+			Seed:      i + 1,
+			PlayerIDs: []string{fmt.Sprintf("p%d-a", i+1), fmt.Sprintf("p%d-b", i+1)},
+		})
+	}
+	return teams
+}
+
+// playBracket reports every ready match until the bracket is complete; the better seed wins unless upset says otherwise.
+func playBracket(t *testing.T, b *TournamentBracket, now time.Time, upset func(m *BracketMatch) bool) int {
+	played := 0
+	for b.Status != BracketStatusComplete {
+		ready := b.Ready()
+		if len(ready) == 0 && b.Format == BracketSwiss {
+			_, err := b.NextSwissRound(now)
+			require.NoError(t, err)
+			continue
+		}
+		require.NotEmpty(t, ready, "the bracket is stuck")
+		for _, m := range ready {
+			blue, _ := b.Team(m.Blue)
+			orange, _ := b.Team(m.Orange)
+			blueWins := blue.Seed < orange.Seed
+			if upset != nil && upset(m) {
+				blueWins = !blueWins
+			}
+			blueScore, orangeScore := 2, 1
+			if !blueWins {
+				blueScore, orangeScore = 1, 2
+			}
+			_, err := b.ReportResult(m.ID, blueScore, orangeScore, now)
+			require.NoError(t, err)
+			played++
+		}
+	}
+	return played
+}
+
+func TestBracketSeedOrder(t *testing.T) {
+	assert.Equal(t, []int{1, 2}, bracketSeedOrder(2))
+	assert.Equal(t, []int{1, 4, 2, 3}, bracketSeedOrder(4))
+	assert.Equal(t, []int{1, 8, 4, 5, 2, 7, 3, 6}, bracketSeedOrder(8))
+}
+
+func TestNewTournamentBracket_Validation(t *testing.T) {
+	now := time.Now().UTC()
+	_, err := NewTournamentBracket("b", "g", "Cup", BracketSingleElimination, testBracketTeams(1), "u", now)
+	assert.Error(t, err)
+
+	teams := testBracketTeams(2)
+	teams[1].PlayerIDs = teams[0].PlayerIDs
+	_, err = NewTournamentBracket("b", "g", "Cup", BracketSingleElimination, teams, "u", now)
+	assert.ErrorContains(t, err, "on both")
+
+	_, err = NewTournamentBracket("b", "g", "Cup", "round_robin", testBracketTeams(2), "u", now)
+	assert.Error(t, err)
+
+	// Unseeded teams follow the seeded teams.
+	teams = testBracketTeams(3)
+	teams[0].Seed = 0
+	b, err := NewTournamentBracket("b", "g", "Cup", BracketSingleElimination, teams, "u", now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Team 2", "Team 3", "Team 1"}, []string{b.Teams[0].Name, b.Teams[1].Name, b.Teams[2].Name})
+}
+
+func TestTournamentBracket_SingleElimination(t *testing.T) {
+	now := time.Now().UTC()
+	b, err := NewTournamentBracket("b", "g", "Cup", BracketSingleElimination, testBracketTeams(5), "u", now)
+	require.NoError(t, err)
+	assert.Len(t, b.Matches, 7)
+
+	// The top three seeds have byes.
+	byes := 0
+	for _, m := range b.Matches {
+		if m.Bye {
+			byes++
+		}
+	}
+	assert.Equal(t, 3, byes)
+	ready := b.Ready()
+	require.Len(t, ready, 2)
+	assert.Equal(t, "W1-2", ready[0].ID, "seed 4 plays seed 5")
+	assert.Equal(t, []string{"t2", "t3"}, []string{ready[1].Blue, ready[1].Orange}, "seeds 2 and 3 advance on byes")
+
+	played := playBracket(t, b, now, nil)
+	assert.Equal(t, 4, played)
+	assert.Equal(t, "t1", b.Champion)
+
+	standings := b.Standings()
+	assert.Equal(t, "t1", standings[0].TeamID)
+	assert.Equal(t, 3, standings[0].Wins, "byes count as wins")
+	assert.True(t, standings[len(standings)-1].Eliminated)
+}
+
+func TestTournamentBracket_DoubleElimination(t *testing.T) {
+	now := time.Now().UTC()
+	for _, n := range []int{2, 3, 4, 6, 8} {
+		t.Run(fmt.Sprintf("%d teams", n), func(t *testing.T) {
+			b, err := NewTournamentBracket("b", "g", "Cup", BracketDoubleElimination, testBracketTeams(n), "u", now)
+			require.NoError(t, err)
+			playBracket(t, b, now, nil)
+			assert.Equal(t, "t1", b.Champion)
+			for _, s := range b.Standings() {
+				assert.LessOrEqual(t, s.Losses, 2, s.Name)
+			}
+		})
+	}
+
+	t.Run("champion from the losers bracket", func(t *testing.T) {
+		b, err := NewTournamentBracket("b", "g", "Cup", BracketDoubleElimination, testBracketTeams(4), "u", now)
+		require.NoError(t, err)
+		// Seed 2 beats seed 1 in the winners final; seed 1 comes back through the losers bracket and wins the final.
+		playBracket(t, b, now, func(m *BracketMatch) bool { return m.ID == "W2-1" })
+		assert.Equal(t, "t1", b.Champion)
+		final, _ := b.Match("GF-1")
+		assert.Equal(t, "t2", final.Blue)
+		assert.Equal(t, "t1", final.Orange)
+
+		standings := b.Standings()
+		assert.Equal(t, "t1", standings[0].TeamID)
+		assert.False(t, standings[0].Eliminated)
+		assert.True(t, standings[1].Eliminated)
+	})
+}
+
+func TestTournamentBracket_Swiss(t *testing.T) {
+	now := time.Now().UTC()
+	b, err := NewTournamentBracket("b", "g", "League", BracketSwiss, testBracketTeams(6), "u", now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, b.SwissRounds)
+	require.Len(t, b.Ready(), 3)
+	assert.Equal(t, []string{"t1", "t4"}, []string{b.Matches[0].Blue, b.Matches[0].Orange}, "the top half plays the bottom half")
+
+	_, err = b.NextSwissRound(now)
+	assert.ErrorIs(t, err, ErrBracketRoundNotOver)
+
+	playBracket(t, b, now, nil)
+	assert.Len(t, b.Matches, 9)
+
+	// No team plays another twice.
+	pairs := make(map[[2]string]bool)
+	for _, m := range b.Matches {
+		assert.False(t, pairs[[2]string{m.Blue, m.Orange}], "rematch in %s", m.ID)
+		pairs[[2]string{m.Blue, m.Orange}] = true
+		pairs[[2]string{m.Orange, m.Blue}] = true
+	}
+	assert.Equal(t, "t1", b.Standings()[0].TeamID)
+	assert.Equal(t, 3, b.Standings()[0].Wins)
+
+	_, err = b.NextSwissRound(now)
+	assert.ErrorIs(t, err, ErrBracketComplete)
+
+	t.Run("odd teams", func(t *testing.T) {
+		b, err := NewTournamentBracket("b", "g", "League", BracketSwiss, testBracketTeams(5), "u", now)
+		require.NoError(t, err)
+		playBracket(t, b, now, nil)
+		byes := make(map[string]int)
+		for _, m := range b.Matches {
+			if m.Bye {
+				byes[m.Winner]++
+			}
+		}
+		assert.Len(t, byes, 3, "a different team has the bye each round")
+	})
+}
+
+func TestTournamentBracket_ReportResult(t *testing.T) {
+	now := time.Now().UTC()
+	b, err := NewTournamentBracket("b", "g", "Cup", BracketSingleElimination, testBracketTeams(4), "u", now)
+	require.NoError(t, err)
+
+	_, err = b.ReportResult("W2-1", 1, 0, now)
+	assert.ErrorIs(t, err, ErrBracketMatchNotReady)
+	_, err = b.ReportResult("W9-9", 1, 0, now)
+	assert.ErrorIs(t, err, ErrBracketMatchNotFound)
+	_, err = b.ReportResult("W1-1", 1, 1, now)
+	assert.Error(t, err, "ties are not allowed")
+
+	require.NoError(t, b.Allocated("W1-1", "match-1", now))
+	m, ok := b.MatchByMatchID("match-1")
+	require.True(t, ok)
+	assert.Equal(t, "W1-1", m.ID)
+
+	_, err = b.ReportResult("W1-1", 3, 1, now)
+	require.NoError(t, err)
+	_, err = b.ReportResult("W1-2", 3, 1, now)
+	require.NoError(t, err)
+	final, _ := b.Match("W2-1")
+	assert.Equal(t, BracketMatchReady, final.Status)
+	assert.Equal(t, []string{"t1", "t2"}, []string{final.Blue, final.Orange})
+
+	// A result can be corrected until the next match is allocated.
+	_, err = b.ReportResult("W1-1", 1, 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, "t4", final.Blue)
+
+	require.NoError(t, b.Allocated("W2-1", "match-2", now))
+	_, err = b.ReportResult("W1-1", 3, 1, now)
+	assert.ErrorIs(t, err, ErrBracketMatchNotReady)
+}
+
+type testBracketRecordNakamaModule struct {
+	runtime.NakamaModule
+	records map[string][2]int64
+}
+
+func (m *testBracketRecordNakamaModule) UsersGetId(ctx context.Context, userIDs []string, facebookIDs []string) ([]*api.User, error) {
+	users := make([]*api.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		users = append(users, &api.User{Id: id, Username: id})
+	}
+	return users, nil
+}
+
+func (m *testBracketRecordNakamaModule) TournamentRecordWrite(ctx context.Context, id, ownerID, username string, score, subscore int64, metadata map[string]interface{}, overrideOperator *int) (*api.LeaderboardRecord, error) {
+	if overrideOperator == nil || *overrideOperator != int(api.Operator_SET) {
+		return nil, fmt.Errorf("operator = %v, want set", overrideOperator)
+	}
+	m.records[ownerID] = [2]int64{score, subscore}
+	return &api.LeaderboardRecord{}, nil
+}
+
+func TestTournamentBracket_RecordResultCorrection(t *testing.T) {
+	now := time.Now().UTC()
+	b, err := NewTournamentBracket("b", "g", "Cup", BracketSingleElimination, testBracketTeams(4), "u", now)
+	require.NoError(t, err)
+	nk := &testBracketRecordNakamaModule{records: make(map[string][2]int64)}
+
+	m, err := b.ReportResult("W1-1", 3, 1, now)
+	require.NoError(t, err)
+	require.NoError(t, tournamentBracketRecordResult(context.Background(), nk, b, m))
+	assert.Equal(t, [2]int64{1, 3}, nk.records["p1-a"])
+	assert.Equal(t, [2]int64{0, 1}, nk.records["p4-a"])
+
+	// The corrected result replaces the first, rather than adding to it.
+	m, err = b.ReportResult("W1-1", 1, 3, now)
+	require.NoError(t, err)
+	require.NoError(t, tournamentBracketRecordResult(context.Background(), nk, b, m))
+	assert.Equal(t, [2]int64{0, 1}, nk.records["p1-a"])
+	assert.Equal(t, [2]int64{1, 3}, nk.records["p4-b"])
+}
+
+func TestTournamentBracket_MatchSettings(t *testing.T) {
+	now := time.Now().UTC()
+	b, err := NewTournamentBracket("b", "6f3b2a6e-0000-4000-8000-000000000000", "Cup", BracketSingleElimination, testBracketTeams(2), "u", now)
+	require.NoError(t, err)
+	m := b.Ready()[0]
+
+	settings := b.MatchSettings(m)
+	assert.Equal(t, evr.ModeArenaPrivate, settings.Mode)
+	assert.Equal(t, map[string]int{"p1-a": evr.TeamBlue, "p1-b": evr.TeamBlue, "p2-a": evr.TeamOrange, "p2-b": evr.TeamOrange}, settings.TeamAlignments)
+	assert.Equal(t, &BracketMatchRef{BracketID: "b", MatchID: m.ID}, settings.Bracket)
+	assert.Equal(t, b.GroupID, settings.GroupID.String())
+}
@@ -165,6 +165,16 @@ func NewSessionWS(logger *zap.Logger, config Config, format SessionFormat, sessi
 		}
 	}
 
+	// Parse the game server's capacity, which may only be lower than a full lobby
+	serverCapacity := 0
+	if s := parseUserQueryFunc(&request, "capacity", 2, nil); s != "" {
+		if v, err := strconv.Atoi(s); err != nil || v < 1 {
+			logger.Warn("Failed to parse capacity", zap.Error(err), zap.String("capacity", s))
+		} else {
+			serverCapacity = min(v, MatchLobbyMaxSize)
+		}
+	}
+
 	discordID := parseUserQueryFunc(&request, "discordid", 20, discordIDPattern)
 	if v := parseUserQueryFunc(&request, "discord_id", 20, discordIDPattern); v != "" {
 		discordID = v
@@ -188,6 +198,7 @@ func NewSessionWS(logger *zap.Logger, config Config, format SessionFormat, sessi
 		supportedFeatures:    parseUserQueryCommaDelimited(&request, "features", 32, featurePattern),
 		requiredFeatures:     parseUserQueryCommaDelimited(&request, "requires", 32, featurePattern),
 		serverTags:           parseUserQueryCommaDelimited(&request, "tags", 32, tagsPattern),
+		serverCapacity:       serverCapacity,
 		serverGuilds:         parseUserQueryCommaDelimited(&request, "guilds", 32, guildPattern),
 		serverRegions:        parseUserQueryCommaDelimited(&request, "regions", 32, regionPattern),
 		defaultRegion:        parseUserQueryFunc(&request, "default_region", 32, regionPattern),
@@ -405,6 +416,9 @@ IncomingLoop:
 
 		if s.format == SessionFormatEVR {
 			// EchoVR messages do not map directly onto nakama messages.
+			if recorders := globalEvrRecorders.Load(); recorders != nil {
+				recorders.Record(s.id, EvrPacketIncoming, data)
+			}
 
 			requests, err := evr.ParsePacket(data)
 			if err != nil {
@@ -659,6 +673,12 @@ func (s *sessionWS) Send(envelope *rtapi.Envelope, reliable bool) error {
 }
 
 func (s *sessionWS) SendBytes(payload []byte, reliable bool) error {
+	if s.format == SessionFormatEVR {
+		if recorders := globalEvrRecorders.Load(); recorders != nil {
+			recorders.Record(s.id, EvrPacketOutgoing, payload)
+		}
+	}
+
 	// Attempt to queue messages and observe failures.
 	select {
 	case s.outgoingCh <- payload: